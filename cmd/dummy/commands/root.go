@@ -24,6 +24,7 @@ func init() {
 	RootCmd.Flags().String("proxy-connect", config.ProxyAddr, "IP:Port to connect to Lachesis proxy")
 	RootCmd.Flags().Bool("discard", config.Discard, "discard output to stderr and sdout")
 	RootCmd.Flags().String("log", config.LogLevel, "debug, info, warn, error, fatal, panic")
+	RootCmd.Flags().String("datadir", config.DataDir, "Directory keeping the committed state and snapshots (in memory when empty)")
 }
 
 //RootCmd is the root command for Dummy
@@ -42,7 +43,13 @@ func runDummy(cmd *cobra.Command, args []string) error {
 	name := config.Name
 	address := config.ProxyAddr
 	//Create and run Dummy Socket Client
-	client, err := dummy.NewDummySocketClient(address, logger)
+	var client *dummy.DummyClient
+	var err error
+	if config.DataDir != "" {
+		client, err = dummy.NewPersistentDummySocketClient(address, config.DataDir, logger)
+	} else {
+		client, err = dummy.NewDummySocketClient(address, logger)
+	}
 	if err != nil {
 		return err
 	}
@@ -72,14 +79,18 @@ func loadConfig(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
 	logger = newLogger()
-	logger.Level = logLevel(config.LogLevel)
+	logger.Level = config.Level()
 	logger.WithFields(logrus.Fields{
 		"name":          config.Name,
 		"client-listen": config.ClientAddr,
 		"proxy-connect": config.ProxyAddr,
 		"discard":       config.Discard,
 		"log":           config.LogLevel,
+		"datadir":       config.DataDir,
 	}).Debug("RUN")
 	return nil
 }
@@ -117,21 +128,3 @@ func newLogger() *logrus.Logger {
 	))
 	return logger
 }
-func logLevel(l string) logrus.Level {
-	switch l {
-	case "debug":
-		return logrus.DebugLevel
-	case "info":
-		return logrus.InfoLevel
-	case "warn":
-		return logrus.WarnLevel
-	case "error":
-		return logrus.ErrorLevel
-	case "fatal":
-		return logrus.FatalLevel
-	case "panic":
-		return logrus.PanicLevel
-	default:
-		return logrus.DebugLevel
-	}
-}
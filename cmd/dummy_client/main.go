@@ -50,19 +50,27 @@ func main() {
 }
 
 func run(c *cli.Context) error {
+	config := dummy.NewDefaultConfig()
+	config.Name = c.String(NameFlag.Name)
+	config.ProxyAddr = c.String(ProxyAddressFlag.Name)
+	config.ClientAddr = c.String(ClientAddressFlag.Name)
+	config.LogLevel = c.String(LogLevelFlag.Name)
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
 	logger := newLogger()
-	logger.Level = logLevel(c.String(LogLevelFlag.Name))
+	logger.Level = config.Level()
 
-	name := c.String(NameFlag.Name)
-	address := c.String(ProxyAddressFlag.Name)
+	name := config.Name
 
 	logger.WithFields(logrus.Fields{
 		"name":       name,
-		"proxy_addr": address,
+		"proxy_addr": config.ProxyAddr,
 	}).Debug("RUN")
 
 	//Create and run Dummy Socket Client
-	client, err := dummy.NewDummySocketClient(address, logger)
+	client, err := dummy.NewDummySocketClient(config.ProxyAddr, logger)
 	if err != nil {
 		return err
 	}
@@ -105,22 +113,3 @@ func newLogger() *logrus.Logger {
 	))
 	return logger
 }
-
-func logLevel(l string) logrus.Level {
-	switch l {
-	case "debug":
-		return logrus.DebugLevel
-	case "info":
-		return logrus.InfoLevel
-	case "warn":
-		return logrus.WarnLevel
-	case "error":
-		return logrus.ErrorLevel
-	case "fatal":
-		return logrus.FatalLevel
-	case "panic":
-		return logrus.PanicLevel
-	default:
-		return logrus.DebugLevel
-	}
-}
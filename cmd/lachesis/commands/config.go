@@ -4,20 +4,17 @@ import "github.com/Fantom-foundation/go-lachesis/src/lachesis"
 
 //CLIConfig contains configuration for the Run command
 type CLIConfig struct {
-	Lachesis   lachesis.LachesisConfig `mapstructure:",squash"`
-	ProxyAddr  string                  `mapstructure:"proxy-listen"`
-	ClientAddr string                  `mapstructure:"client-connect"`
-	Standalone bool                    `mapstructure:"standalone"`
-	Log2file   bool                    `mapstructure:"log2file"`
+	Lachesis lachesis.LachesisConfig `mapstructure:",squash"`
 }
 
 //NewDefaultCLIConfig creates a CLIConfig with default values
 func NewDefaultCLIConfig() *CLIConfig {
 	return &CLIConfig{
-		Lachesis:   *lachesis.NewDefaultConfig(),
-		ProxyAddr:  "127.0.0.1:1338",
-		ClientAddr: "127.0.0.1:1339",
-		Standalone: false,
-		Log2file:   false,
+		Lachesis: *lachesis.NewDefaultConfig(),
 	}
 }
+
+// Validate checks the configuration once flags and config file are merged
+func (c *CLIConfig) Validate() error {
+	return c.Lachesis.Validate()
+}
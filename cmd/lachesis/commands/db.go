@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/Fantom-foundation/go-lachesis/src/lachesis"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dbDataDir string
+	dbDryRun  bool
+	dbFrom    int64
+	dbTo      int64
+	dbOut     string
+)
+
+// NewDBCmd produces a DBCmd grouping the commands inspecting and maintaining
+// the badger store of a stopped node
+func NewDBCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Inspect and maintain the badger store of a stopped node",
+	}
+	cmd.PersistentFlags().StringVar(&dbDataDir, "datadir", config.Lachesis.DataDir, "Top-level directory for configuration and data")
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade the store to the schema version of this release, after a backup",
+		Args:  cobra.NoArgs,
+		RunE:  migrateDB,
+	}
+	migrateCmd.Flags().BoolVar(&dbDryRun, "dry-run", false, "Only print the pending migrations")
+	cmd.AddCommand(migrateCmd)
+
+	statsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Count the keys of the store and their size by kind",
+		Args:  cobra.NoArgs,
+		RunE:  dbStats,
+	}
+	cmd.AddCommand(statsCmd)
+
+	getCmd := &cobra.Command{
+		Use:   "get <kind> <key>",
+		Short: "Print in JSON an event or an indexed transaction by hash, a root by participant, or a round, block, frame or checkpoint by index",
+		Args:  cobra.ExactArgs(2),
+		RunE:  dbGet,
+	}
+	cmd.AddCommand(getCmd)
+
+	dumpCmd := &cobra.Command{
+		Use:   "dump <kind>",
+		Short: "Print in JSON, one per line, the events by topological index, or the rounds, blocks, frames or checkpoints of a range",
+		Args:  cobra.ExactArgs(1),
+		RunE:  dbDump,
+	}
+	dumpCmd.Flags().Int64Var(&dbFrom, "from", 0, "First index dumped")
+	dumpCmd.Flags().Int64Var(&dbTo, "to", -1, "Last index dumped, -1 for the last one")
+	dumpCmd.Flags().StringVar(&dbOut, "out", "", "File the dump is written to instead of the standard output")
+	cmd.AddCommand(dumpCmd)
+	return cmd
+}
+
+// openDBInspector opens the badger store of the datadir read-only
+func openDBInspector() (*poset.DBInspector, error) {
+	db, err := poset.OpenDBInspector(filepath.Join(dbDataDir, lachesis.StoreBadger))
+	if err != nil {
+		return nil, fmt.Errorf("opening store: %s", err)
+	}
+	return db, nil
+}
+
+func dbStats(cmd *cobra.Command, args []string) error {
+	db, err := openDBInspector()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	stats, err := db.Stats()
+	if err != nil {
+		return err
+	}
+	var keys, size int64
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tKEYS\tBYTES")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", s.Kind, s.Keys, s.Bytes)
+		keys += s.Keys
+		size += s.Bytes
+	}
+	fmt.Fprintf(w, "total\t%d\t%d\n", keys, size)
+	return w.Flush()
+}
+
+func dbGet(cmd *cobra.Command, args []string) error {
+	db, err := openDBInspector()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	value, err := db.Get(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
+
+func dbDump(cmd *cobra.Command, args []string) error {
+	db, err := openDBInspector()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var w io.Writer = os.Stdout
+	if dbOut != "" {
+		f, err := os.Create(dbOut)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	to := dbTo
+	if to < 0 {
+		to = math.MaxInt64 - 1
+	}
+	enc := json.NewEncoder(w)
+	count := 0
+	err = db.Dump(args[0], dbFrom, to, func(value interface{}) error {
+		count++
+		return enc.Encode(value)
+	})
+	if err != nil {
+		return err
+	}
+	if dbOut != "" {
+		fmt.Printf("Dumped %d %ss to %s\n", count, args[0], dbOut)
+	}
+	return nil
+}
+
+func migrateDB(cmd *cobra.Command, args []string) error {
+	report, err := poset.MigrateBadgerStore(filepath.Join(dbDataDir, lachesis.StoreBadger), dbDryRun)
+	if err != nil {
+		return err
+	}
+	if len(report.Applied) == 0 {
+		fmt.Printf("The store is at schema version %d, nothing to migrate\n", report.From)
+		return nil
+	}
+	for _, m := range report.Applied {
+		fmt.Printf("%d -> %d: %s\n", m.From, m.From+1, m.Description)
+	}
+	if dbDryRun {
+		fmt.Printf("%d migrations pending from schema version %d\n", len(report.Applied), report.From)
+		return nil
+	}
+	fmt.Printf("Migrated the store from schema version %d to %d, backed up to %s\n",
+		report.From, poset.SchemaVersion, report.Backup)
+	return nil
+}
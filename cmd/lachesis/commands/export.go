@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Fantom-foundation/go-lachesis/src/lachesis"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportDataDir   string
+	exportStoreType string
+	exportFormat    string
+	exportOut       string
+	exportFromRound int64
+)
+
+// NewExportCmd produces an ExportCmd grouping the commands exporting the
+// store of a stopped node
+func NewExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the store of a stopped node",
+	}
+	cmd.PersistentFlags().StringVar(&exportDataDir, "datadir", config.Lachesis.DataDir, "Top-level directory for configuration and data")
+	cmd.PersistentFlags().StringVar(&exportStoreType, "store-type", config.Lachesis.StoreType, "Database of the store: badger, leveldb or rocksdb")
+	cmd.PersistentFlags().StringVar(&exportOut, "out", "", "File the export is written to instead of the standard output")
+
+	dagCmd := &cobra.Command{
+		Use:   "dag",
+		Short: "Write the event DAG with its rounds, witnesses, fame and consensus order",
+		Args:  cobra.NoArgs,
+		RunE:  exportDAG,
+	}
+	dagCmd.Flags().StringVar(&exportFormat, "format", poset.DAGFormatDOT, "Format of the DAG: dot, for GraphViz, or json")
+	dagCmd.Flags().Int64Var(&exportFromRound, "from-round", 0, "First round of the events exported")
+	cmd.AddCommand(dagCmd)
+
+	return cmd
+}
+
+func exportDAG(cmd *cobra.Command, args []string) error {
+	if exportFormat != poset.DAGFormatDOT && exportFormat != poset.DAGFormatJSON {
+		return fmt.Errorf("format must be %s or %s, got %q", poset.DAGFormatDOT, poset.DAGFormatJSON, exportFormat)
+	}
+	store, err := loadStore(exportDataDir, exportStoreType)
+	if err != nil {
+		return fmt.Errorf("opening store: %s", err)
+	}
+	defer store.Close()
+
+	dag, err := poset.BuildDAG(store, exportFromRound)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if exportOut != "" {
+		f, err := os.Create(exportOut)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := dag.Write(w, exportFormat); err != nil {
+		return err
+	}
+	if exportOut != "" {
+		fmt.Printf("Exported %d events to %s\n", len(dag.Events), exportOut)
+	}
+	return nil
+}
+
+// loadStore opens the existing store of storeType under datadir
+func loadStore(datadir, storeType string) (poset.Store, error) {
+	path := filepath.Join(datadir, storeType)
+	switch storeType {
+	case lachesis.StoreBadger:
+		return poset.LoadBadgerStore(config.Lachesis.NodeConfig.CacheSize, path)
+	case lachesis.StoreLevelDB:
+		return poset.LoadLevelDBStore(config.Lachesis.NodeConfig.CacheSize, path)
+	case lachesis.StoreRocksDB:
+		return lachesis.LoadRocksDBStore(config.Lachesis.NodeConfig.CacheSize, path)
+	default:
+		return nil, fmt.Errorf("store-type must be %s, %s or %s, got %q", lachesis.StoreBadger, lachesis.StoreLevelDB, lachesis.StoreRocksDB, storeType)
+	}
+}
@@ -13,6 +13,7 @@ import (
 var (
 	privKeyFile           string
 	pubKeyFile            string
+	keyTypeName           string
 	config                = NewDefaultCLIConfig()
 	defaultPrivateKeyFile = fmt.Sprintf("%s/priv_key.pem", config.Lachesis.DataDir)
 	defaultPublicKeyFile  = fmt.Sprintf("%s/key.pub", config.Lachesis.DataDir)
@@ -33,8 +34,14 @@ func NewKeygenCmd() *cobra.Command {
 func AddKeygenFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&privKeyFile, "pem", defaultPrivateKeyFile, "File where the private key will be written")
 	cmd.Flags().StringVar(&pubKeyFile, "pub", defaultPublicKeyFile, "File where the public key will be written")
+	cmd.Flags().StringVar(&keyTypeName, "key-type", config.Lachesis.KeyType, "Type of the key: p256, secp256k1 or ed25519, the key-type of the network")
 }
 func keygen(cmd *cobra.Command, args []string) error {
+	keyType, err := crypto.ParseKeyType(keyTypeName)
+	if err != nil {
+		return err
+	}
+	crypto.SetKeyType(keyType)
 	pemDump, err := crypto.GeneratePemKey()
 	if err != nil {
 		return fmt.Errorf("error generating PemDump")
@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/lachesis"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	keysDataDir         string
+	keysPasswordFile    string
+	keysNewPasswordFile string
+	keysKeyType         string
+	keysOut             string
+)
+
+// NewKeysCmd produces a KeysCmd grouping the commands managing the encrypted
+// keystore of a node
+func NewKeysCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage the keys of the encrypted keystore of a node",
+	}
+	cmd.PersistentFlags().StringVar(&keysDataDir, "datadir", config.Lachesis.DataDir, "Top-level directory for configuration and data")
+	cmd.PersistentFlags().StringVar(&keysPasswordFile, "password-file", "", "File of the passphrase of the key, asked for on the terminal when unset")
+
+	newCmd := &cobra.Command{
+		Use:   "new",
+		Short: "Generate a key encrypted in the keystore",
+		Args:  cobra.NoArgs,
+		RunE:  newKey,
+	}
+	newCmd.Flags().StringVar(&keysKeyType, "key-type", config.Lachesis.KeyType, "Type of the key: p256, secp256k1 or ed25519, the key-type of the network")
+	cmd.AddCommand(newCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "Print the keys of the keystore",
+		Args:  cobra.NoArgs,
+		RunE:  listKeys,
+	})
+
+	exportCmd := &cobra.Command{
+		Use:   "export [pubkey]",
+		Short: "Print the unencrypted PEM private key of a key of the keystore, selected by a prefix of its public key",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  exportKey,
+	}
+	exportCmd.Flags().StringVar(&keysOut, "out", "", "File the PEM private key is written to instead of the standard output")
+	cmd.AddCommand(exportCmd)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "import <pem file>",
+		Short: "Encrypt a PEM private key, such as priv_key.pem, in the keystore",
+		Args:  cobra.ExactArgs(1),
+		RunE:  importKey,
+	})
+
+	changeCmd := &cobra.Command{
+		Use:   "change-password [pubkey]",
+		Short: "Encrypt a key of the keystore with another passphrase",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  changeKeyPassword,
+	}
+	changeCmd.Flags().StringVar(&keysNewPasswordFile, "new-password-file", "", "File of the new passphrase, asked for on the terminal when unset")
+	cmd.AddCommand(changeCmd)
+
+	return cmd
+}
+
+// newKey generates a key in the keystore
+func newKey(cmd *cobra.Command, args []string) error {
+	keyType, err := crypto.ParseKeyType(keysKeyType)
+	if err != nil {
+		return err
+	}
+	key, err := crypto.GenerateKey(keyType)
+	if err != nil {
+		return err
+	}
+	return storeKey(key)
+}
+
+// importKey encrypts a PEM key in the keystore
+func importKey(cmd *cobra.Command, args []string) error {
+	data, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	key, err := (&crypto.PemKey{}).ReadKeyFromBuf(data)
+	if err != nil {
+		return fmt.Errorf("reading %s: %s", args[0], err)
+	}
+	if key == nil {
+		return fmt.Errorf("%s is empty", args[0])
+	}
+	return storeKey(key)
+}
+
+// storeKey encrypts key in the keystore with a new passphrase
+func storeKey(key *ecdsa.PrivateKey) error {
+	passphrase, err := readPassphrase("Passphrase of the key: ", keysPasswordFile, true)
+	if err != nil {
+		return err
+	}
+	ek, err := crypto.NewKeystore(keysDataDir).Store(key, passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Stored the %s key %s in %s\n", ek.KeyType, ek.PublicKey, ek.Path)
+	return nil
+}
+
+// listKeys prints the keys of the keystore
+func listKeys(cmd *cobra.Command, args []string) error {
+	keys, err := crypto.NewKeystore(keysDataDir).List()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PUBKEY\tTYPE\tFILE")
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", k.PublicKey, k.KeyType, k.Path)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("%d keys\n", len(keys))
+	return nil
+}
+
+// exportKey prints the unencrypted PEM private key of a key of the keystore
+func exportKey(cmd *cobra.Command, args []string) error {
+	ek, err := crypto.NewKeystore(keysDataDir).Find(firstArg(args))
+	if err != nil {
+		return err
+	}
+	passphrase, err := readPassphrase("Passphrase of the key: ", keysPasswordFile, false)
+	if err != nil {
+		return err
+	}
+	key, err := ek.Decrypt(passphrase)
+	if err != nil {
+		return err
+	}
+	dump, err := crypto.ToPemKey(key)
+	if err != nil {
+		return err
+	}
+	if keysOut == "" {
+		fmt.Print(dump.PrivateKey)
+		return nil
+	}
+	if err := ioutil.WriteFile(keysOut, []byte(dump.PrivateKey), 0600); err != nil {
+		return err
+	}
+	fmt.Printf("The private key of %s has been saved to: %s\n", ek.PublicKey, keysOut)
+	return nil
+}
+
+// changeKeyPassword encrypts a key of the keystore with a new passphrase
+func changeKeyPassword(cmd *cobra.Command, args []string) error {
+	keystore := crypto.NewKeystore(keysDataDir)
+	ek, err := keystore.Find(firstArg(args))
+	if err != nil {
+		return err
+	}
+	old, err := readPassphrase("Current passphrase of the key: ", keysPasswordFile, false)
+	if err != nil {
+		return err
+	}
+	passphrase, err := readPassphrase("New passphrase of the key: ", keysNewPasswordFile, true)
+	if err != nil {
+		return err
+	}
+	if err := keystore.ChangePassphrase(ek.PublicKey, old, passphrase); err != nil {
+		return err
+	}
+	fmt.Printf("Changed the passphrase of %s\n", ek.PublicKey)
+	return nil
+}
+
+// readPassphrase returns the passphrase of file, or else asks for it on the
+// terminal, twice when confirm is set
+func readPassphrase(prompt, file string, confirm bool) (string, error) {
+	if file != "" {
+		return crypto.ReadPassphraseFile(file)
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no terminal to ask for the passphrase, see --password-file")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat the passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		if string(again) != string(passphrase) {
+			return "", fmt.Errorf("the passphrases do not match")
+		}
+	}
+	return string(passphrase), nil
+}
+
+func firstArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// askNodePassphrase asks for the passphrase of the key of the node on the
+// terminal when it is in the keystore and there is no password-file
+func askNodePassphrase(conf *lachesis.LachesisConfig) error {
+	if conf.Key != nil || conf.PasswordFile != "" || conf.Passphrase != "" || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil
+	}
+	keys, err := crypto.NewKeystore(conf.DataDir).List()
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+	conf.Passphrase, err = readPassphrase("Passphrase of the key of the node: ", "", false)
+	return err
+}
@@ -0,0 +1,313 @@
+package commands
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/lachesis"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/quic-go/quic-go"
+	"github.com/spf13/cobra"
+)
+
+var (
+	peersDataDir  string
+	peerPubKey    string
+	peerAddr      string
+	peerTier      string
+	peerKeyType   string
+	peerStake     uint64
+	peerTimeout   time.Duration
+	peerTransport string
+)
+
+// NewPeersCmd produces a PeersCmd grouping the commands managing peers.json
+func NewPeersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "peers",
+		Short: "Manage the peers.json of a node",
+	}
+	cmd.PersistentFlags().StringVar(&peersDataDir, "datadir", config.Lachesis.DataDir, "Top-level directory for configuration and data")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "sign",
+		Short: "Sign the peer set of peers.json with the key of the node",
+		RunE:  signPeers,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "Print the peers of peers.json",
+		RunE:  listPeers,
+	})
+
+	addCmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a peer to peers.json",
+		RunE:  addPeer,
+	}
+	addCmd.Flags().StringVar(&peerPubKey, "pubkey", "", "Public key of the peer, as in its key.pub")
+	addCmd.Flags().StringVar(&peerAddr, "addr", "", "Address of the peer, host:port")
+	addCmd.Flags().StringVar(&peerTier, "tier", "", "Tier of the peer: validator (default), persistent or ephemeral")
+	addCmd.Flags().StringVar(&peerKeyType, "key-type", "", "Type of the key of the peer, tagging it: p256, secp256k1 or ed25519")
+	addCmd.Flags().Uint64Var(&peerStake, "stake", 0, "Stake of the validator, weighting it in consensus (0 weighs as 1)")
+	cmd.AddCommand(addCmd)
+
+	removeCmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Remove a peer from peers.json",
+		RunE:  removePeer,
+	}
+	removeCmd.Flags().StringVar(&peerPubKey, "pubkey", "", "Public key of the peer")
+	cmd.AddCommand(removeCmd)
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check that the address of every peer of peers.json accepts connections",
+		RunE:  verifyPeers,
+	}
+	verifyCmd.Flags().DurationVar(&peerTimeout, "timeout", 3*time.Second, "Time allowed to connect to a peer")
+	verifyCmd.Flags().StringVar(&peerTransport, "transport", config.Lachesis.Transport, "Transport of the peers: tcp or quic")
+	cmd.AddCommand(verifyCmd)
+	return cmd
+}
+
+// readPeers reads peers.json without verifying its signatures, an empty
+// peer set when it does not exist yet
+func readPeers() (*peers.SignedPeerSet, error) {
+	signed, err := peers.NewJSONPeers(peersDataDir).ReadSigned()
+	if os.IsNotExist(err) {
+		return peers.NewSignedPeerSet(nil), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading peers.json: %s", err)
+	}
+	return signed, nil
+}
+
+// writePeers writes a plain list to peers.json, sorted by public key. The
+// signatures of a signed file would no longer match, so it is not changed.
+func writePeers(signed *peers.SignedPeerSet, list []*peers.Peer) error {
+	if len(signed.Signatures) > 0 {
+		return fmt.Errorf("peers.json is signed by %d validators and cannot be changed", len(signed.Signatures))
+	}
+	sort.Sort(peers.ByPubHex(list))
+	return peers.NewJSONPeers(peersDataDir).SetPeers(list)
+}
+
+// listPeers prints the peers of peers.json, with the hash and signatures of
+// a signed file
+func listPeers(cmd *cobra.Command, args []string) error {
+	signed, err := readPeers()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "PUBKEY\tADDRESS\tTIER\tWEIGHT\tID")
+	for _, p := range signed.Peers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", p.PubKeyHex, p.NetAddr, p.TierOrDefault(), p.Weight(), p.ID)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("%d peers, peer set %s", len(signed.Peers), signed.Hash)
+	if len(signed.Signatures) > 0 {
+		fmt.Printf(", %s", signatureSummary(signed))
+	}
+	fmt.Println()
+	return nil
+}
+
+// signatureSummary describes the signatures of a peer set against the
+// supermajority a node requires to start, by weight when the validators have
+// stakes
+func signatureSummary(signed *peers.SignedPeerSet) string {
+	snapshot := peers.NewPeersFromSlice(signed.Peers).Snapshot()
+	if !snapshot.Weighted() {
+		return fmt.Sprintf("signed by %d of the %d required validators", len(signed.Signatures), snapshot.SuperMajority())
+	}
+	var weight uint64
+	for _, sig := range signed.Signatures {
+		weight += snapshot.Weight(sig.PubKeyHex)
+	}
+	return fmt.Sprintf("signed by %d validators of weight %d, %d required", len(signed.Signatures), weight, snapshot.SuperMajorityWeight())
+}
+
+// addPeer appends a peer to peers.json
+func addPeer(cmd *cobra.Command, args []string) error {
+	pubKey, err := hex2PubKey(peerPubKey, peerKeyType)
+	if err != nil {
+		return err
+	}
+	if _, _, err := net.SplitHostPort(peerAddr); err != nil {
+		return fmt.Errorf("invalid --addr %q: %s", peerAddr, err)
+	}
+	peerAddr, err = peers.NormalizeNetAddr(peerAddr)
+	if err != nil {
+		return err
+	}
+	switch peerTier {
+	case "", peers.TierValidator, peers.TierPersistent, peers.TierEphemeral:
+	default:
+		return fmt.Errorf("invalid --tier %q", peerTier)
+	}
+
+	signed, err := readPeers()
+	if err != nil {
+		return err
+	}
+	for _, p := range signed.Peers {
+		if p.PubKeyHex == pubKey {
+			return fmt.Errorf("%s is already a peer, at %s", pubKey, p.NetAddr)
+		}
+	}
+	peer := peers.NewPeer(pubKey, peerAddr)
+	peer.Tier = peerTier
+	peer.KeyType = peerKeyType
+	peer.Stake = peerStake
+	if err := writePeers(signed, append(signed.Peers, peer)); err != nil {
+		return err
+	}
+	fmt.Printf("Added %s at %s, %d peers\n", pubKey, peerAddr, len(signed.Peers)+1)
+	return nil
+}
+
+// removePeer removes a peer from peers.json
+func removePeer(cmd *cobra.Command, args []string) error {
+	pubKey, err := hex2PubKey(peerPubKey, "")
+	if err != nil {
+		return err
+	}
+	signed, err := readPeers()
+	if err != nil {
+		return err
+	}
+	index, rest := peers.ExcludePeer(signed.Peers, pubKey)
+	if index < 0 {
+		return fmt.Errorf("%s is not a peer", pubKey)
+	}
+	if err := writePeers(signed, rest); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s, %d peers\n", pubKey, len(rest))
+	return nil
+}
+
+// verifyPeers connects to the address of every peer of peers.json, failing
+// when one of them is unreachable
+func verifyPeers(cmd *cobra.Command, args []string) error {
+	signed, err := readPeers()
+	if err != nil {
+		return err
+	}
+	if peerTransport != lachesis.TransportTCP && peerTransport != lachesis.TransportQUIC {
+		return fmt.Errorf("invalid --transport %q", peerTransport)
+	}
+	unreachable := 0
+	for _, p := range signed.Peers {
+		if err := dialPeer(p.NetAddr); err != nil {
+			unreachable++
+			fmt.Printf("FAIL %s %s: %s\n", p.PubKeyHex, p.NetAddr, err)
+			continue
+		}
+		fmt.Printf("OK   %s %s\n", p.PubKeyHex, p.NetAddr)
+	}
+	if unreachable > 0 {
+		return fmt.Errorf("%d of the %d peers are unreachable", unreachable, len(signed.Peers))
+	}
+	return nil
+}
+
+// dialPeer returns nil if a node answers at address
+func dialPeer(address string) error {
+	if peerTransport == lachesis.TransportTCP {
+		conn, err := net.DialTimeout("tcp", address, peerTimeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), peerTimeout)
+	defer cancel()
+	conn, err := quic.DialAddr(ctx, address, &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{"lachesis"},
+	}, &quic.Config{HandshakeIdleTimeout: peerTimeout})
+	if err == nil {
+		return conn.CloseWithError(0, "")
+	}
+	// without the certificate of a peer the handshake is refused, which
+	// still proves that the node answered
+	var transportErr *quic.TransportError
+	if errors.As(err, &transportErr) && transportErr.Remote {
+		return nil
+	}
+	return err
+}
+
+// hex2PubKey checks that s is a public key in the format of key.pub, of the
+// key type keyType or else of any type, and returns it in upper case like the
+// keys of peers.json
+func hex2PubKey(s, keyType string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", fmt.Errorf("--pubkey is required")
+	}
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return "", fmt.Errorf("public key %q does not start with 0x", s)
+	}
+	s = "0x" + strings.ToUpper(s[2:])
+	key, err := (&peers.Peer{PubKeyHex: s}).PubKeyBytes()
+	if err != nil {
+		return "", fmt.Errorf("invalid public key %q: %s", s, err)
+	}
+	keyTypes := []crypto.KeyType{crypto.KeyP256, crypto.KeySecp256k1, crypto.KeyEd25519}
+	if keyType != "" {
+		t, err := crypto.ParseKeyType(keyType)
+		if err != nil {
+			return "", err
+		}
+		keyTypes = []crypto.KeyType{t}
+	}
+	for _, t := range keyTypes {
+		if pub := crypto.ToPublicKey(t, key); pub != nil && pub.X != nil {
+			return s, nil
+		}
+	}
+	if keyType != "" {
+		return "", fmt.Errorf("%q is not a %s public key", s, keyType)
+	}
+	return "", fmt.Errorf("%q is not a p256, secp256k1 or ed25519 public key", s)
+}
+
+// signPeers adds the signature of the node key to peers.json, turning a
+// plain list into a signed peer set
+func signPeers(cmd *cobra.Command, args []string) error {
+	key, err := crypto.NewPemKey(peersDataDir).ReadKey()
+	if err != nil {
+		return fmt.Errorf("reading the key: %s", err)
+	}
+	store := peers.NewJSONPeers(peersDataDir)
+	signed, err := store.ReadSigned()
+	if err != nil {
+		return fmt.Errorf("reading peers.json: %s", err)
+	}
+	if err := signed.Sign(key); err != nil {
+		return err
+	}
+	if err := store.SetSigned(signed); err != nil {
+		return err
+	}
+
+	fmt.Printf("Peer set %s %s\n", signed.Hash, signatureSummary(signed))
+	return nil
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneDataDir string
+	pruneDepth   int64
+)
+
+// NewPruneCmd produces a PruneCmd which prunes the badger store of a stopped
+// node
+func NewPruneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete the events and rounds older than the last anchor block from the store",
+		RunE:  prune,
+	}
+	AddPruneFlags(cmd)
+	return cmd
+}
+
+// AddPruneFlags adds flags to the prune command
+func AddPruneFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&pruneDataDir, "datadir", config.Lachesis.DataDir, "Top-level directory for configuration and data")
+	cmd.Flags().Int64Var(&pruneDepth, "prune_depth", 10, "Rounds of events kept before the last anchor block")
+}
+
+func prune(cmd *cobra.Command, args []string) error {
+	store, err := poset.LoadBadgerStore(config.Lachesis.NodeConfig.CacheSize, filepath.Join(pruneDataDir, "badger"))
+	if err != nil {
+		return fmt.Errorf("opening store: %s", err)
+	}
+	defer store.Close()
+
+	anchor, err := store.LastAnchorBlock()
+	if err != nil {
+		return err
+	}
+	pruned, err := store.Prune(anchor, pruneDepth)
+	if err != nil {
+		return err
+	}
+	info, _, err := store.PruneInfo()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Pruned %d events at block %d, keeping rounds from %d\n", pruned, info.Block, info.Round)
+	return nil
+}
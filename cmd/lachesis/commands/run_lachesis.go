@@ -21,6 +21,9 @@ func runLachesis(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
 
 	return runSingleLachesis(config)
 }
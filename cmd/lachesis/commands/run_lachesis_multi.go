@@ -42,8 +42,11 @@ func runLachesis(cmd *cobra.Command, args []string) error {
 
 		configs[i].Lachesis.BindAddr = fmt.Sprintf("127.0.0.1:%d", 12000 + i + 1)
 		configs[i].Lachesis.ServiceAddr = fmt.Sprintf("127.0.0.1:%d", 8000 + i + 1)
-		configs[i].ProxyAddr = fmt.Sprintf("127.0.0.1:%d", 9000 + i + 1)
+		configs[i].Lachesis.ProxyAddr = fmt.Sprintf("127.0.0.1:%d", 9000 + i + 1)
 		configs[i].Lachesis.DataDir += fmt.Sprintf("/%0*d", digits, i)
+		if err := configs[i].Validate(); err != nil {
+			return err
+		}
 
 		if i > 0 {
 			go runSingleLachesis(configs[i])
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/go-lachesis/src/simulation"
+	"github.com/spf13/cobra"
+)
+
+// NewSimulateCmd returns the command running a deterministic simulation of
+// an N-node network
+func NewSimulateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Run a deterministic network simulation",
+		RunE:  simulate,
+	}
+	AddSimulateFlags(cmd)
+	return cmd
+}
+
+// AddSimulateFlags adds flags to the simulate command
+func AddSimulateFlags(cmd *cobra.Command) {
+	conf := simulation.DefaultConfig()
+	cmd.Flags().Int("nodes", conf.Nodes, "Number of simulated nodes")
+	cmd.Flags().Int64("seed", conf.Seed, "Seed of the simulation; the same seed produces the same blocks")
+	cmd.Flags().Duration("duration", conf.Duration, "Simulated time to run for")
+	cmd.Flags().Duration("heartbeat", conf.Heartbeat, "Mean time between gossips")
+	cmd.Flags().Duration("max-latency", conf.MaxLatency, "Max simulated network latency")
+	cmd.Flags().Duration("tx-interval", conf.TxInterval, "Time between submitted transactions (0 disables)")
+	cmd.Flags().Int64("sync-limit", conf.SyncLimit, "Max number of events for sync")
+}
+
+func simulate(cmd *cobra.Command, args []string) error {
+	conf := simulation.DefaultConfig()
+	flags := cmd.Flags()
+	conf.Nodes, _ = flags.GetInt("nodes")
+	conf.Seed, _ = flags.GetInt64("seed")
+	conf.Duration, _ = flags.GetDuration("duration")
+	conf.Heartbeat, _ = flags.GetDuration("heartbeat")
+	conf.MaxLatency, _ = flags.GetDuration("max-latency")
+	conf.TxInterval, _ = flags.GetDuration("tx-interval")
+	conf.SyncLimit, _ = flags.GetInt64("sync-limit")
+
+	sim, err := simulation.NewSimulation(conf)
+	if err != nil {
+		return err
+	}
+	res, err := sim.Run()
+	if err != nil {
+		return err
+	}
+
+	for i, blocks := range res.Blocks {
+		fmt.Printf("node %d: %d blocks\n", i, len(blocks))
+	}
+	fmt.Printf("seed: %d\ntransactions: %d\ndigest: %s\n", res.Seed, res.Transactions, res.Digest())
+	return res.Check()
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"github.com/Fantom-foundation/go-lachesis/src/lachesis"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// NewSnapshotCmd produces a SnapshotCmd which exports the anchor block of a
+// stopped node to a file, and imports it to create the store of a new node
+func NewSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Export or import a snapshot of the consensus and application state",
+	}
+
+	export := &cobra.Command{
+		Use:   "export",
+		Short: "Write an anchor block, its frame and the application snapshot to a file",
+		RunE:  exportSnapshot,
+	}
+	AddSnapshotFlags(export)
+	export.Flags().Int64("block", -1, "Index of the exported block, -1 for the last anchor block")
+	export.Flags().String("out", "snapshot.json", "File the snapshot is written to")
+
+	imp := &cobra.Command{
+		Use:   "import",
+		Short: "Create the store of a new node from a snapshot file",
+		RunE:  importSnapshot,
+	}
+	AddSnapshotFlags(imp)
+	imp.Flags().String("in", "snapshot.json", "Snapshot file to import")
+
+	cmd.AddCommand(export, imp)
+	return cmd
+}
+
+// AddSnapshotFlags adds the flags locating the store and the application to
+// a snapshot command
+func AddSnapshotFlags(cmd *cobra.Command) {
+	config := NewDefaultCLIConfig()
+
+	cmd.Flags().String("datadir", config.Lachesis.DataDir, "Top-level directory for configuration and data")
+	cmd.Flags().Bool("store", true, "Use badgerDB instead of in-mem DB")
+	cmd.Flags().Bool("standalone", config.Lachesis.Standalone, "Use the dummy app of the node instead of a proxy")
+	cmd.Flags().StringP("proxy-listen", "p", config.Lachesis.ProxyAddr, "Listen IP:Port for lachesis proxy")
+	cmd.Flags().String("abci", config.Lachesis.ABCIAddr, "Address of an ABCI application, tcp://IP:Port or unix:///path")
+	cmd.Flags().String("abci-chain-id", config.Lachesis.ABCIChainID, "Chain ID in the block headers delivered to the ABCI application")
+	cmd.Flags().Int("cache-size", config.Lachesis.NodeConfig.CacheSize, "Number of items in LRU caches")
+}
+
+// loadSnapshotConfig reads the configuration of a snapshot command and
+// connects to the application of the node
+func loadSnapshotConfig(cmd *cobra.Command) (*lachesis.Lachesis, error) {
+	config := NewDefaultCLIConfig()
+	if err := bindFlagsLoadViper(cmd, config); err != nil {
+		return nil, err
+	}
+	if err := viper.Unmarshal(config); err != nil {
+		return nil, err
+	}
+	config.Lachesis.Logger.Level = lachesis.LogLevel(config.Lachesis.LogLevel)
+	config.Lachesis.NodeConfig.Logger = config.Lachesis.Logger
+
+	p, err := newAppProxy(config)
+	if err != nil {
+		return nil, err
+	}
+	config.Lachesis.Proxy = p
+	return lachesis.NewLachesis(&config.Lachesis), nil
+}
+
+func exportSnapshot(cmd *cobra.Command, args []string) error {
+	engine, err := loadSnapshotConfig(cmd)
+	if err != nil {
+		return err
+	}
+	return engine.ExportSnapshot(viper.GetInt64("block"), viper.GetString("out"))
+}
+
+func importSnapshot(cmd *cobra.Command, args []string) error {
+	engine, err := loadSnapshotConfig(cmd)
+	if err != nil {
+		return err
+	}
+	return engine.ImportSnapshot(viper.GetString("in"))
+}
@@ -0,0 +1,198 @@
+package commands
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/dummy"
+	"github.com/Fantom-foundation/go-lachesis/src/lachesis"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// NewTestnetCmd returns the command running a local testnet of N nodes in
+// this process
+func NewTestnetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "testnet",
+		Short: "Run a local testnet of N nodes in this process",
+		Args:  cobra.NoArgs,
+		RunE:  testnet,
+	}
+	AddTestnetFlags(cmd)
+	return cmd
+}
+
+// AddTestnetFlags adds flags to the testnet command
+func AddTestnetFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("nodes", 4, "Number of nodes")
+	cmd.Flags().String("datadir", filepath.Join(config.Lachesis.DataDir, "testnet"), "Directory of the data directories of the nodes, node0 to nodeN-1")
+	cmd.Flags().Int("base-port", 12000, "Port of node 0, node i listening on base-port+i on the loopback")
+	cmd.Flags().Int("service-port", 8000, "HTTP service port of node 0, node i serving on service-port+i; 0 disables the services")
+	cmd.Flags().Duration("heartbeat", config.Lachesis.NodeConfig.HeartbeatTimeout, "Time between gossips")
+	cmd.Flags().Bool("store", false, "Use badger stores, kept in the data directories across runs")
+	cmd.Flags().String("log", "info", "debug, info, warn, error, fatal, panic")
+}
+
+// testnetNode is the data directory, the key and the address of a node of
+// the testnet
+type testnetNode struct {
+	dir  string
+	key  *ecdsa.PrivateKey
+	addr string
+}
+
+func testnet(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	n, _ := flags.GetInt("nodes")
+	dataDir, _ := flags.GetString("datadir")
+	basePort, _ := flags.GetInt("base-port")
+	servicePort, _ := flags.GetInt("service-port")
+	heartbeat, _ := flags.GetDuration("heartbeat")
+	store, _ := flags.GetBool("store")
+	logLevel, _ := flags.GetString("log")
+	if n < 2 {
+		return fmt.Errorf("a testnet needs at least 2 nodes, got %d", n)
+	}
+
+	nodes, err := initTestnet(dataDir, n, basePort)
+	if err != nil {
+		return err
+	}
+
+	out := &testnetLog{out: os.Stdout}
+	var engines []*lachesis.Lachesis
+	shutdown := func() {
+		for _, e := range engines {
+			e.Shutdown()
+		}
+	}
+	for i, node := range nodes {
+		conf := lachesis.NewDefaultConfig()
+		conf.DataDir = node.dir
+		conf.BindAddr = node.addr
+		conf.ServiceAddr = ""
+		if servicePort > 0 {
+			conf.ServiceAddr = fmt.Sprintf("127.0.0.1:%d", servicePort+i)
+		}
+		conf.Key = node.key
+		conf.Store = store
+		conf.LogLevel = logLevel
+		conf.NodeConfig.HeartbeatTimeout = heartbeat
+		conf.Logger = logrus.New()
+		conf.Logger.Out = out.prefixed(fmt.Sprintf("node%d | ", i))
+		conf.Logger.Level = lachesis.LogLevel(logLevel)
+		conf.NodeConfig.Logger = conf.Logger
+		if err := conf.Validate(); err != nil {
+			shutdown()
+			return fmt.Errorf("node%d: %s", i, err)
+		}
+		if store {
+			conf.Proxy, err = dummy.NewPersistentInmemDummyApp(filepath.Join(node.dir, "dummy"), conf.Logger)
+			if err != nil {
+				shutdown()
+				return fmt.Errorf("node%d: %s", i, err)
+			}
+		} else {
+			conf.Proxy = dummy.NewInmemDummyApp(conf.Logger)
+		}
+
+		engine := lachesis.NewLachesis(conf)
+		if err := engine.Init(); err != nil {
+			shutdown()
+			return fmt.Errorf("node%d: %s", i, err)
+		}
+		engines = append(engines, engine)
+	}
+
+	var wg sync.WaitGroup
+	for _, e := range engines {
+		wg.Add(1)
+		go func(e *lachesis.Lachesis) {
+			defer wg.Done()
+			e.Run()
+		}(e)
+	}
+	fmt.Fprintf(out, "testnet | %d nodes running under %s, interrupt to stop\n", n, dataDir)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+	fmt.Fprintf(out, "testnet | shutting down\n")
+	shutdown()
+	wg.Wait()
+	return nil
+}
+
+// initTestnet reads or creates the keys of the nodes in dataDir/nodeI and
+// writes the peers.json of the testnet in every data directory, so that each
+// node can also be run on its own with lachesis run --datadir
+func initTestnet(dataDir string, n, basePort int) ([]testnetNode, error) {
+	nodes := make([]testnetNode, n)
+	peerSlice := make([]*peers.Peer, n)
+	for i := range nodes {
+		dir := filepath.Join(dataDir, fmt.Sprintf("node%d", i))
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+		key, err := crypto.NewPemKey(dir).ReadKey()
+		if err != nil {
+			if key, err = lachesis.Keygen(dir); err != nil {
+				return nil, fmt.Errorf("node%d: %s", i, err)
+			}
+		}
+		nodes[i] = testnetNode{
+			dir:  dir,
+			key:  key,
+			addr: fmt.Sprintf("127.0.0.1:%d", basePort+i),
+		}
+		peerSlice[i] = peers.NewPeer(fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey)), nodes[i].addr)
+	}
+	for i, node := range nodes {
+		if err := peers.NewJSONPeers(node.dir).SetPeers(peerSlice); err != nil {
+			return nil, fmt.Errorf("node%d: %s", i, err)
+		}
+	}
+	return nodes, nil
+}
+
+// testnetLog interleaves the logs of the nodes, each line prefixed with the
+// name of its node
+type testnetLog struct {
+	sync.Mutex
+	out io.Writer
+}
+
+func (l *testnetLog) Write(p []byte) (int, error) {
+	l.Lock()
+	defer l.Unlock()
+	return l.out.Write(p)
+}
+
+// prefixed returns a writer of the log of one node, which writes one entry
+// at a time
+func (l *testnetLog) prefixed(prefix string) io.Writer {
+	return prefixWriter{log: l, prefix: []byte(prefix)}
+}
+
+type prefixWriter struct {
+	log    *testnetLog
+	prefix []byte
+}
+
+func (w prefixWriter) Write(p []byte) (int, error) {
+	line := make([]byte, 0, len(w.prefix)+len(p))
+	line = append(append(line, w.prefix...), p...)
+	if _, err := w.log.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
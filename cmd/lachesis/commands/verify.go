@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Fantom-foundation/go-lachesis/src/lachesis"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyStorePath          string
+	verifyStoreType          string
+	verifyCheckpointInterval int64
+)
+
+// NewVerifyCmd produces a VerifyCmd which replays the events of the store of
+// a stopped node and checks its blocks and frames against the consensus
+func NewVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Replay the events of a store and check its blocks and frames match the recomputed consensus",
+		Args:  cobra.NoArgs,
+		RunE:  verifyStore,
+	}
+	cmd.Flags().StringVar(&verifyStorePath, "store_path", filepath.Join(config.Lachesis.DataDir, lachesis.StoreBadger), "Directory of the store")
+	cmd.Flags().StringVar(&verifyStoreType, "store-type", lachesis.StoreBadger, "Database of the store: badger, leveldb or rocksdb")
+	cmd.Flags().Int64Var(&verifyCheckpointInterval, "checkpoint-interval", config.Lachesis.NodeConfig.CheckpointInterval, "Checkpoint interval the store was written with")
+	return cmd
+}
+
+func verifyStore(cmd *cobra.Command, args []string) error {
+	var (
+		store poset.Store
+		err   error
+	)
+	switch verifyStoreType {
+	case lachesis.StoreBadger:
+		store, err = poset.LoadBadgerStore(config.Lachesis.NodeConfig.CacheSize, verifyStorePath)
+	case lachesis.StoreLevelDB:
+		store, err = poset.LoadLevelDBStore(config.Lachesis.NodeConfig.CacheSize, verifyStorePath)
+	case lachesis.StoreRocksDB:
+		store, err = lachesis.LoadRocksDBStore(config.Lachesis.NodeConfig.CacheSize, verifyStorePath)
+	default:
+		return fmt.Errorf("store-type must be %s, %s or %s, got %q", lachesis.StoreBadger, lachesis.StoreLevelDB, lachesis.StoreRocksDB, verifyStoreType)
+	}
+	if err != nil {
+		return fmt.Errorf("opening store: %s", err)
+	}
+	defer store.Close()
+
+	logger := logrus.New()
+	logger.Level = logrus.WarnLevel
+	report, err := poset.VerifyStore(store, verifyCheckpointInterval, logrus.NewEntry(logger))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Replayed %d events in %d rounds, checked %d blocks and %d frames\n",
+		report.Events, report.Rounds, report.Blocks, report.Frames)
+	if report.Divergence != nil {
+		return fmt.Errorf("first divergence at %s", report.Divergence)
+	}
+	fmt.Println("The stored blocks and frames match the consensus")
+	return nil
+}
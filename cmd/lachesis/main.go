@@ -13,7 +13,16 @@ func main() {
 	rootCmd.AddCommand(
 		cmd.VersionCmd,
 		cmd.NewKeygenCmd(),
-		cmd.NewRunCmd())
+		cmd.NewKeysCmd(),
+		cmd.NewRunCmd(),
+		cmd.NewPruneCmd(),
+		cmd.NewDBCmd(),
+		cmd.NewSnapshotCmd(),
+		cmd.NewExportCmd(),
+		cmd.NewVerifyCmd(),
+		cmd.NewPeersCmd(),
+		cmd.NewSimulateCmd(),
+		cmd.NewTestnetCmd())
 
 	//Do not print usage when error occurs
 	rootCmd.SilenceUsage = true
@@ -1,6 +1,10 @@
 package commands
 
-import "github.com/Fantom-foundation/go-lachesis/src/lachesis"
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/go-lachesis/src/lachesis"
+)
 
 //CLIConfig contains configuration for the Run command
 type CLIConfig struct {
@@ -21,3 +25,14 @@ func NewDefaultCLIConfig() *CLIConfig {
 		Node:     0,
 	}
 }
+
+// Validate checks the network settings and the shared node configuration
+func (c *CLIConfig) Validate() error {
+	if c.NbNodes < 1 {
+		return fmt.Errorf("nodes must be at least 1, got %d", c.NbNodes)
+	}
+	if c.SendTxs < 0 {
+		return fmt.Errorf("send-txs must not be negative, got %d", c.SendTxs)
+	}
+	return c.Lachesis.Validate()
+}
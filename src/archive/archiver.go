@@ -0,0 +1,190 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// DefaultSegmentSize is the number of blocks per segment
+const DefaultSegmentSize = 100
+
+// LastObject is the name of the object holding the index of the last
+// archived block
+const LastObject = "LAST"
+
+// Source provides the blocks and frames to archive, usually the node itself
+type Source interface {
+	GetBlock(index int64) (poset.Block, error)
+	GetFrame(round int64) (poset.Frame, error)
+}
+
+type entry struct {
+	block poset.Block
+	frame poset.Frame
+}
+
+// Archiver writes committed blocks and their frames to a sink in segments of
+// SegmentSize blocks
+type Archiver struct {
+	sink   Sink
+	source Source
+	logger *logrus.Entry
+
+	// SegmentSize is the number of blocks per segment
+	SegmentSize int
+
+	// last is the index of the last archived block, -1 when none
+	last    int64
+	pending []entry
+	lock    sync.Mutex
+}
+
+// New resumes the archive of sink after its last block
+func New(sink Sink, source Source, logger *logrus.Logger) (*Archiver, error) {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	a := &Archiver{
+		sink:        sink,
+		source:      source,
+		logger:      logger.WithField("module", "archive"),
+		SegmentSize: DefaultSegmentSize,
+		last:        -1,
+	}
+	data, err := sink.Get(LastObject)
+	switch {
+	case lerrors.Is(err, lerrors.KeyNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		if a.last, err = strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid %s archive object: %s", LastObject, err)
+		}
+	}
+	return a, nil
+}
+
+// SegmentName returns the name of the segment holding blocks first to last
+func SegmentName(first, last int64) string {
+	return fmt.Sprintf("segment-%012d-%012d.lar", first, last)
+}
+
+// Last returns the index of the last archived block, -1 when none
+func (a *Archiver) Last() int64 {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.last
+}
+
+// Run archives the blocks received on blocks until stop is closed, then
+// writes the pending blocks
+func (a *Archiver) Run(blocks <-chan poset.Block, stop <-chan struct{}) {
+	for {
+		select {
+		case block := <-blocks:
+			if err := a.Archive(block); err != nil {
+				metrics.IncrCounter("archive.errors", 1)
+				a.logger.WithFields(logrus.Fields{
+					"block": block.Index(),
+					"error": err,
+				}).Error("Archiving block")
+			}
+		case <-stop:
+			if err := a.Flush(); err != nil {
+				a.logger.WithError(err).Error("Writing the last segment")
+			}
+			return
+		}
+	}
+}
+
+// Archive queues block, after the blocks missed since the last archived one,
+// and writes a segment when SegmentSize blocks are pending. The frames are
+// read from the source right away, before they leave its retention horizon.
+func (a *Archiver) Archive(block poset.Block) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	next := a.last + int64(len(a.pending)) + 1
+	if block.Index() < next {
+		return nil
+	}
+	for i := next; i < block.Index(); i++ {
+		missed, err := a.source.GetBlock(i)
+		if err != nil {
+			return fmt.Errorf("getting missed block %d: %w", i, err)
+		}
+		if err := a.queue(missed); err != nil {
+			return err
+		}
+	}
+	return a.queue(block)
+}
+
+func (a *Archiver) queue(block poset.Block) error {
+	frame, err := a.source.GetFrame(block.RoundReceived())
+	if err != nil {
+		return fmt.Errorf("getting frame %d of block %d: %w", block.RoundReceived(), block.Index(), err)
+	}
+	a.pending = append(a.pending, entry{block, frame})
+	if len(a.pending) >= a.SegmentSize {
+		return a.flush()
+	}
+	return nil
+}
+
+// Flush writes the pending blocks as a segment
+func (a *Archiver) Flush() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.flush()
+}
+
+func (a *Archiver) flush() error {
+	if len(a.pending) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		return err
+	}
+	for _, e := range a.pending {
+		// pick up the signatures collected since the commit
+		block := e.block
+		if b, err := a.source.GetBlock(block.Index()); err == nil {
+			block = b
+		}
+		if err := w.WriteBlock(block, e.frame); err != nil {
+			return err
+		}
+	}
+
+	first, last := a.pending[0].block.Index(), a.pending[len(a.pending)-1].block.Index()
+	if err := a.sink.Put(SegmentName(first, last), buf.Bytes()); err != nil {
+		return err
+	}
+	if err := a.sink.Put(LastObject, []byte(strconv.FormatInt(last, 10))); err != nil {
+		return err
+	}
+	a.last = last
+	a.pending = a.pending[:0]
+
+	metrics.IncrCounter("archive.segments", 1)
+	metrics.SetGauge("archive.last_block", float64(last))
+	a.logger.WithFields(logrus.Fields{
+		"first": first,
+		"last":  last,
+		"bytes": buf.Len(),
+	}).Debug("Archived segment")
+	return nil
+}
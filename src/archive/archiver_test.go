@@ -0,0 +1,171 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	cm "github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+type fakeSource struct {
+	blocks map[int64]poset.Block
+	frames map[int64]poset.Frame
+}
+
+func (s *fakeSource) GetBlock(index int64) (poset.Block, error) {
+	b, ok := s.blocks[index]
+	if !ok {
+		return poset.Block{}, cm.NewStoreErr("Blocks", cm.KeyNotFound, fmt.Sprint(index))
+	}
+	return b, nil
+}
+
+func (s *fakeSource) GetFrame(round int64) (poset.Frame, error) {
+	f, ok := s.frames[round]
+	if !ok {
+		return poset.Frame{}, cm.NewStoreErr("Frames", cm.KeyNotFound, fmt.Sprint(round))
+	}
+	return f, nil
+}
+
+func newSource(n int64) *fakeSource {
+	s := &fakeSource{
+		blocks: make(map[int64]poset.Block),
+		frames: make(map[int64]poset.Frame),
+	}
+	for i := int64(0); i < n; i++ {
+		txs := [][]byte{[]byte(fmt.Sprintf("tx%d", i))}
+		event := poset.NewEvent(txs, nil, nil, []string{"a", "b"}, []byte{byte(i)}, i, nil)
+		frame := poset.Frame{Round: i + 1, Events: []*poset.EventMessage{&event.Message}}
+		block, _ := poset.NewBlockFromFrame(i, frame)
+		s.blocks[i], s.frames[i+1] = block, frame
+	}
+	return s
+}
+
+// readSegment decodes a segment and returns the indexes of its blocks
+func readSegment(t *testing.T, data []byte, source *fakeSource) []int64 {
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res []int64
+	for {
+		block, frame, err := r.Next()
+		if err == io.EOF {
+			return res
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash, _ := frame.Hash()
+		if !bytes.Equal(hash, block.FrameHash) {
+			t.Fatalf("frame of block %d does not match", block.Index())
+		}
+		if sig := source.blocks[block.Index()].Signatures["0xV"]; block.Signatures["0xV"] != sig {
+			t.Fatalf("block %d was archived without its late signature", block.Index())
+		}
+		res = append(res, block.Index())
+	}
+}
+
+func TestArchiver(t *testing.T) {
+	source := newSource(7)
+	sink, err := NewFileSink(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := New(sink, source, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.SegmentSize = 3
+
+	// blocks 0 and 1 are missed and read from the source
+	if err := a.Archive(source.blocks[2]); err != nil {
+		t.Fatal(err)
+	}
+	if a.Last() != 2 {
+		t.Fatalf("expected a segment up to block 2, got %d", a.Last())
+	}
+
+	if err := a.Archive(source.blocks[3]); err != nil {
+		t.Fatal(err)
+	}
+	// a signature collected after the commit
+	b := source.blocks[3]
+	b.Signatures = map[string]string{"0xV": "sig"}
+	source.blocks[3] = b
+	if err := a.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := sink.Get(SegmentName(0, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readSegment(t, data, source); fmt.Sprint(got) != "[0 1 2]" {
+		t.Fatalf("unexpected first segment %v", got)
+	}
+	data, err = sink.Get(SegmentName(3, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readSegment(t, data, source); fmt.Sprint(got) != "[3]" {
+		t.Fatalf("unexpected second segment %v", got)
+	}
+
+	// a new archiver resumes after the last segment
+	a, err = New(sink, source, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Last() != 3 {
+		t.Fatalf("expected to resume after block 3, got %d", a.Last())
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var lock sync.Mutex
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		name := strings.TrimPrefix(r.URL.Path, "/bucket/")
+		switch r.Method {
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			objects[name] = data
+		case http.MethodGet:
+			data, ok := objects[name]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(data)
+		}
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL + "/bucket/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(sink, newSource(1), nil); err != nil {
+		t.Fatalf("an empty bucket should start a new archive: %v", err)
+	}
+	if err := sink.Put(LastObject, []byte("41")); err != nil {
+		t.Fatal(err)
+	}
+	a, err := New(sink, newSource(1), nil)
+	if err != nil || a.Last() != 41 {
+		t.Fatalf("expected to resume after block 41, got %d, %v", a.Last(), err)
+	}
+}
@@ -0,0 +1,131 @@
+// Package archive streams committed blocks and their consensus events to a
+// file or object-store sink, so that history pruned from the store of a
+// validator remains recoverable.
+//
+// Archives are sequences of segments. A segment starts with Magic and holds
+// records made of a kind byte, the uvarint length of the payload and the
+// payload: the protobuf encoding of a poset.Block or poset.Frame. Each block
+// record is followed by the record of its frame, which holds the consensus
+// events of the block.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// Magic starts every segment
+const Magic = "LACHARC1"
+
+// maxRecord is the largest record accepted by a Reader
+const maxRecord = 1 << 30
+
+const (
+	recordBlock byte = 1
+	recordFrame byte = 2
+)
+
+// Writer encodes blocks and frames in the archive format
+type Writer struct {
+	w   io.Writer
+	buf [binary.MaxVarintLen64]byte
+}
+
+// NewWriter writes the segment header to w
+func NewWriter(w io.Writer) (*Writer, error) {
+	if _, err := io.WriteString(w, Magic); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w}, nil
+}
+
+// WriteBlock writes a block followed by its frame
+func (w *Writer) WriteBlock(block poset.Block, frame poset.Frame) error {
+	data, err := block.ProtoMarshal()
+	if err != nil {
+		return err
+	}
+	if err := w.write(recordBlock, data); err != nil {
+		return err
+	}
+	if data, err = frame.ProtoMarshal(); err != nil {
+		return err
+	}
+	return w.write(recordFrame, data)
+}
+
+func (w *Writer) write(kind byte, data []byte) error {
+	w.buf[0] = kind
+	n := binary.PutUvarint(w.buf[1:], uint64(len(data)))
+	if _, err := w.w.Write(w.buf[:n+1]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(data)
+	return err
+}
+
+// Reader decodes a segment
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader checks the segment header of r
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, []byte(Magic)) {
+		return nil, fmt.Errorf("not a lachesis archive segment")
+	}
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next block and its frame, io.EOF at the end of the segment
+func (r *Reader) Next() (poset.Block, poset.Frame, error) {
+	var block poset.Block
+	var frame poset.Frame
+	data, err := r.read(recordBlock)
+	if err != nil {
+		return block, frame, err
+	}
+	if err := block.ProtoUnmarshal(data); err != nil {
+		return block, frame, err
+	}
+	if data, err = r.read(recordFrame); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return block, frame, err
+	}
+	err = frame.ProtoUnmarshal(data)
+	return block, frame, err
+}
+
+func (r *Reader) read(kind byte) ([]byte, error) {
+	k, err := r.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if k != kind {
+		return nil, fmt.Errorf("unexpected archive record %d, expected %d", k, kind)
+	}
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxRecord {
+		return nil, fmt.Errorf("archive record of %d bytes is too large", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,114 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+)
+
+// Sink stores the objects of an archive
+type Sink interface {
+	// Put stores an object, replacing any previous one of that name
+	Put(name string, data []byte) error
+	// Get returns an object, a KeyNotFound error when missing
+	Get(name string) ([]byte, error)
+}
+
+// NewSink returns an HTTPSink for http(s) URLs and a FileSink otherwise
+func NewSink(location string) (Sink, error) {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return NewHTTPSink(location, 30*time.Second), nil
+	}
+	return NewFileSink(location)
+}
+
+// FileSink stores objects as files of a directory
+type FileSink struct {
+	dir string
+}
+
+// NewFileSink creates dir when missing
+func NewFileSink(dir string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileSink{dir: dir}, nil
+}
+
+// Put implements Sink. Objects are written to a temporary file first, so
+// that readers never see partial segments.
+func (s *FileSink) Put(name string, data []byte) error {
+	path := filepath.Join(s.dir, name)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Get implements Sink
+func (s *FileSink) Get(name string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, name))
+	if os.IsNotExist(err) {
+		return nil, lerrors.New(lerrors.KeyNotFound, "archive object %s not found", name)
+	}
+	return data, err
+}
+
+// HTTPSink stores objects with PUT and GET requests under a base URL, which
+// works with S3 compatible object stores accepting unsigned or pre-signed
+// requests, and with WebDAV servers
+type HTTPSink struct {
+	base   string
+	client *http.Client
+}
+
+// NewHTTPSink returns a sink storing objects under base
+func NewHTTPSink(base string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{
+		base:   strings.TrimSuffix(base, "/"),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Put implements Sink
+func (s *HTTPSink) Put(name string, data []byte) error {
+	req, err := http.NewRequest(http.MethodPut, s.base+"/"+name, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("storing %s: %s: %s", name, resp.Status, msg)
+	}
+	return nil
+}
+
+// Get implements Sink
+func (s *HTTPSink) Get(name string) ([]byte, error) {
+	resp, err := s.client.Get(s.base + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, lerrors.New(lerrors.KeyNotFound, "archive object %s not found", name)
+	case resp.StatusCode/100 != 2:
+		return nil, fmt.Errorf("reading %s: %s", name, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
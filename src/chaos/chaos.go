@@ -0,0 +1,229 @@
+// Package chaos injects faults at named points of a running node so that
+// partitions, slow disks and crashes can be exercised on real binaries.
+// Injection is off unless Enable is called, in which case the faults are
+// managed at runtime, usually through the admin API.
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fault actions
+const (
+	// Drop discards a transport message
+	Drop = "drop"
+	// Delay holds a transport message or a store operation for Delay
+	Delay = "delay"
+	// Duplicate sends an outbound transport message twice
+	Duplicate = "duplicate"
+	// Stall blocks the point for Delay, e.g. to simulate a slow disk
+	Stall = "stall"
+	// Crash terminates the process immediately, skipping any cleanup
+	Crash = "crash"
+)
+
+// Known injection points. Transport points can be narrowed to one RPC type
+// by appending it, e.g. "net.out.sync".
+const (
+	PointNetOut     = "net.out"
+	PointNetIn      = "net.in"
+	PointStoreWrite = "store.write"
+	PointCommit     = "node.commit"
+)
+
+// ErrDropped is returned for the transport messages dropped on purpose
+var ErrDropped = errors.New("message dropped by fault injection")
+
+// Fault describes what happens at an injection point
+type Fault struct {
+	Point  string `json:"point"`
+	Action string `json:"action"`
+	// Probability of the fault triggering at each pass, 0 meaning always
+	Probability float64       `json:"probability,omitempty"`
+	Delay       time.Duration `json:"delay,omitempty"`
+	// Count limits the number of times the fault triggers, 0 meaning no limit
+	Count int `json:"count,omitempty"`
+}
+
+// Validate checks the fault is meaningful
+func (f Fault) Validate() error {
+	if f.Point == "" {
+		return fmt.Errorf("fault point is required")
+	}
+	switch f.Action {
+	case Drop, Duplicate, Crash:
+	case Delay, Stall:
+		if f.Delay <= 0 {
+			return fmt.Errorf("%s fault requires a positive delay", f.Action)
+		}
+	default:
+		return fmt.Errorf("unknown fault action %q", f.Action)
+	}
+	if f.Probability < 0 || f.Probability > 1 {
+		return fmt.Errorf("fault probability must be within [0, 1]")
+	}
+	if f.Count < 0 {
+		return fmt.Errorf("fault count must not be negative")
+	}
+	return nil
+}
+
+// Injector holds the active faults
+type Injector struct {
+	sync.Mutex
+	enabled bool
+	faults  map[string]*Fault
+	rnd     *rand.Rand
+	exit    func(code int)
+}
+
+// NewInjector creates a disabled Injector
+func NewInjector() *Injector {
+	return &Injector{
+		faults: make(map[string]*Fault),
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		exit:   os.Exit,
+	}
+}
+
+// Enable turns injection on
+func (in *Injector) Enable() {
+	in.Lock()
+	in.enabled = true
+	in.Unlock()
+}
+
+// Enabled reports whether injection is on
+func (in *Injector) Enabled() bool {
+	in.Lock()
+	defer in.Unlock()
+	return in.enabled
+}
+
+// Set adds or replaces the fault at f.Point
+func (in *Injector) Set(f Fault) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	in.Lock()
+	defer in.Unlock()
+	if !in.enabled {
+		return fmt.Errorf("fault injection is disabled")
+	}
+	in.faults[f.Point] = &f
+	return nil
+}
+
+// Clear removes the fault at point and reports whether there was one
+func (in *Injector) Clear(point string) bool {
+	in.Lock()
+	defer in.Unlock()
+	_, ok := in.faults[point]
+	delete(in.faults, point)
+	return ok
+}
+
+// Reset removes all the faults
+func (in *Injector) Reset() {
+	in.Lock()
+	in.faults = make(map[string]*Fault)
+	in.Unlock()
+}
+
+// Faults returns the active faults sorted by point
+func (in *Injector) Faults() []Fault {
+	in.Lock()
+	defer in.Unlock()
+	res := make([]Fault, 0, len(in.faults))
+	for _, f := range in.faults {
+		res = append(res, *f)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Point < res[j].Point })
+	return res
+}
+
+// trigger returns the fault to apply at point, if any. A point also matches
+// the faults set on its parents, e.g. "net.out.sync" matches "net.out".
+func (in *Injector) trigger(point string) *Fault {
+	in.Lock()
+	defer in.Unlock()
+	if !in.enabled || len(in.faults) == 0 {
+		return nil
+	}
+
+	for p := point; ; {
+		if f, ok := in.faults[p]; ok {
+			if f.Probability > 0 && in.rnd.Float64() >= f.Probability {
+				return nil
+			}
+			if f.Count > 0 {
+				f.Count--
+				if f.Count == 0 {
+					delete(in.faults, p)
+				}
+			}
+			res := *f
+			return &res
+		}
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			return nil
+		}
+		p = p[:i]
+	}
+}
+
+// Point applies the stall, delay and crash faults of point
+func (in *Injector) Point(point string) {
+	f := in.trigger(point)
+	if f == nil {
+		return
+	}
+	switch f.Action {
+	case Delay, Stall:
+		time.Sleep(f.Delay)
+	case Crash:
+		fmt.Fprintf(os.Stderr, "fault injection: crash at %s\n", point)
+		in.exit(2)
+	}
+}
+
+// Message applies the fault of a transport point. It returns ErrDropped if
+// the message must be dropped and whether it must be sent twice.
+func (in *Injector) Message(point string) (duplicate bool, err error) {
+	f := in.trigger(point)
+	if f == nil {
+		return false, nil
+	}
+	switch f.Action {
+	case Drop:
+		return false, ErrDropped
+	case Duplicate:
+		return true, nil
+	case Delay, Stall:
+		time.Sleep(f.Delay)
+	case Crash:
+		fmt.Fprintf(os.Stderr, "fault injection: crash at %s\n", point)
+		in.exit(2)
+	}
+	return false, nil
+}
+
+var global = NewInjector()
+
+// Global returns the process wide Injector used by the package functions
+func Global() *Injector {
+	return global
+}
+
+// Point applies the faults of point using the global Injector
+func Point(point string) {
+	global.Point(point)
+}
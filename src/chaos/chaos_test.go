@@ -0,0 +1,92 @@
+package chaos
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDisabledInjector(t *testing.T) {
+	in := NewInjector()
+	if err := in.Set(Fault{Point: PointNetOut, Action: Drop}); err == nil {
+		t.Fatal("faults must not be set while injection is disabled")
+	}
+	if _, err := in.Message(PointNetOut + ".sync"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFaultValidation(t *testing.T) {
+	for _, f := range []Fault{
+		{Action: Drop},
+		{Point: PointNetOut, Action: "explode"},
+		{Point: PointStoreWrite, Action: Stall},
+		{Point: PointNetOut, Action: Drop, Probability: 2},
+	} {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("%+v should be invalid", f)
+		}
+	}
+}
+
+func TestMessageFaults(t *testing.T) {
+	in := NewInjector()
+	in.Enable()
+
+	if err := in.Set(Fault{Point: PointNetOut, Action: Drop, Count: 2}); err != nil {
+		t.Fatal(err)
+	}
+	// parent points match
+	for i := 0; i < 2; i++ {
+		if _, err := in.Message(PointNetOut + ".sync"); err != ErrDropped {
+			t.Fatalf("expected ErrDropped, got %v", err)
+		}
+	}
+	// the count is exhausted
+	if _, err := in.Message(PointNetOut + ".sync"); err != nil {
+		t.Fatal(err)
+	}
+	if len(in.Faults()) != 0 {
+		t.Fatal("exhausted fault should be removed")
+	}
+
+	if err := in.Set(Fault{Point: PointNetOut + ".eager_sync", Action: Duplicate}); err != nil {
+		t.Fatal(err)
+	}
+	if dup, _ := in.Message(PointNetOut + ".sync"); dup {
+		t.Fatal("sync should not be duplicated")
+	}
+	if dup, _ := in.Message(PointNetOut + ".eager_sync"); !dup {
+		t.Fatal("eager_sync should be duplicated")
+	}
+
+	if !in.Clear(PointNetOut + ".eager_sync") {
+		t.Fatal("fault should be cleared")
+	}
+	if in.Clear(PointNetOut + ".eager_sync") {
+		t.Fatal("fault is already cleared")
+	}
+}
+
+func TestPointFaults(t *testing.T) {
+	in := NewInjector()
+	in.Enable()
+	code := -1
+	in.exit = func(c int) { code = c }
+
+	if err := in.Set(Fault{Point: PointStoreWrite, Action: Stall, Delay: 20 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	in.Point(PointStoreWrite)
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("store write should stall")
+	}
+
+	if err := in.Set(Fault{Point: PointCommit, Action: Crash}); err != nil {
+		t.Fatal(err)
+	}
+	in.Point(PointCommit)
+	if code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+}
@@ -0,0 +1,117 @@
+// Package clock estimates the drift of the local clock, from NTP queries and
+// the times reported by peers.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMaxDrift is the clock offset above which a node warns
+const DefaultMaxDrift = 2 * time.Second
+
+// Monitor keeps the latest clock offset measured against NTP and every peer.
+// Offsets are positive when the local clock is behind.
+type Monitor struct {
+	// MaxDrift is the offset tolerated before warning
+	MaxDrift time.Duration
+
+	ntp      time.Duration
+	ntpValid bool
+	peers    map[string]time.Duration
+	lock     sync.RWMutex
+}
+
+// NewMonitor returns a monitor tolerating maxDrift, DefaultMaxDrift when 0
+func NewMonitor(maxDrift time.Duration) *Monitor {
+	if maxDrift <= 0 {
+		maxDrift = DefaultMaxDrift
+	}
+	return &Monitor{
+		MaxDrift: maxDrift,
+		peers:    make(map[string]time.Duration),
+	}
+}
+
+// SetNTP records the offset measured against an NTP server
+func (m *Monitor) SetNTP(offset time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.ntp, m.ntpValid = offset, true
+}
+
+// AddPeerSample records the offset of the clock of a peer, estimated from the
+// time it reported and the round trip of the request
+func (m *Monitor) AddPeerSample(peer string, reported, sent, received time.Time) {
+	rtt := received.Sub(sent)
+	offset := reported.Sub(sent.Add(rtt / 2))
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.peers[peer] = offset
+}
+
+// Forget drops the samples of a peer
+func (m *Monitor) Forget(peer string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.peers, peer)
+}
+
+// Offset returns the estimated offset of the local clock: the NTP one when
+// available, otherwise the median of the peer offsets, so that a few skewed
+// peers do not make the node believe its own clock is wrong
+func (m *Monitor) Offset() (offset time.Duration, ok bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.ntpValid {
+		return m.ntp, true
+	}
+	if len(m.peers) == 0 {
+		return 0, false
+	}
+	offsets := make([]time.Duration, 0, len(m.peers))
+	for _, o := range m.peers {
+		offsets = append(offsets, o)
+	}
+	return Median(offsets), true
+}
+
+// Drifting returns true if the local clock is off by more than MaxDrift
+func (m *Monitor) Drifting() bool {
+	offset, ok := m.Offset()
+	return ok && abs(offset) > m.MaxDrift
+}
+
+// SkewedPeers returns the peers whose clock is off by more than MaxDrift
+// from the local clock corrected by Offset
+func (m *Monitor) SkewedPeers() []string {
+	offset, _ := m.Offset()
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	var res []string
+	for peer, o := range m.peers {
+		if abs(o-offset) > m.MaxDrift {
+			res = append(res, peer)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Median returns the median of durations, the lower one for even counts
+func Median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(len(sorted)-1)/2]
+}
+
+func abs(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
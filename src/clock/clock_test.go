@@ -0,0 +1,71 @@
+package clock
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestMonitor(t *testing.T) {
+	m := NewMonitor(time.Second)
+	if _, ok := m.Offset(); ok {
+		t.Fatal("no offset should be known without samples")
+	}
+
+	now := time.Now()
+	// the round trip is 100ms, peers answer 50ms after sending
+	sample := func(peer string, skew time.Duration) {
+		m.AddPeerSample(peer, now.Add(50*time.Millisecond+skew), now, now.Add(100*time.Millisecond))
+	}
+	sample("a", 0)
+	sample("b", 10*time.Millisecond)
+	sample("c", time.Hour)
+
+	offset, _ := m.Offset()
+	if offset != 10*time.Millisecond {
+		t.Fatalf("expected the median offset of 10ms, got %v", offset)
+	}
+	if m.Drifting() {
+		t.Fatal("a single skewed peer should not make the local clock drift")
+	}
+	if skewed := m.SkewedPeers(); len(skewed) != 1 || skewed[0] != "c" {
+		t.Fatalf("expected c to be skewed, got %v", skewed)
+	}
+
+	m.SetNTP(-3 * time.Second)
+	if !m.Drifting() {
+		t.Fatal("the NTP offset should take precedence")
+	}
+}
+
+func TestQueryNTP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// a server 5 seconds ahead
+	go func() {
+		req := make([]byte, 48)
+		_, addr, err := conn.ReadFrom(req)
+		if err != nil {
+			return
+		}
+		resp := make([]byte, 48)
+		resp[0] = 0x24 // version 4, server mode
+		resp[1] = 2    // stratum
+		now := time.Now().Add(5 * time.Second)
+		putNTPTime(resp[32:], now)
+		putNTPTime(resp[40:], now)
+		conn.WriteTo(resp, addr)
+	}()
+
+	offset, err := QueryNTP(conn.LocalAddr().String(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset < 4900*time.Millisecond || offset > 5100*time.Millisecond {
+		t.Fatalf("expected an offset of about 5s, got %v", offset)
+	}
+}
@@ -1,6 +1,10 @@
 package common
 
-import "fmt"
+import (
+	"fmt"
+
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+)
 
 type StoreErrType uint32
 
@@ -50,6 +54,19 @@ func (e StoreErr) Error() string {
 	return fmt.Sprintf("%s, %s, %s", e.dataType, e.key, m)
 }
 
+// Kind maps the store error onto the kinds shared across packages
+func (e StoreErr) Kind() lerrors.Kind {
+	switch e.errType {
+	case KeyNotFound, NoRoot, Empty:
+		return lerrors.KeyNotFound
+	case TooLate:
+		return lerrors.TooFar
+	case UnknownParticipant:
+		return lerrors.UnknownParticipant
+	}
+	return lerrors.Other
+}
+
 func Is(err error, t StoreErrType) bool {
 	storeErr, ok := err.(StoreErr)
 	return ok && storeErr.errType == t
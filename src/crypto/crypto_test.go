@@ -1,12 +1,14 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/x509"
 	"fmt"
 	"io/ioutil"
 	"math/big"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -127,3 +129,94 @@ func TestSignatureEncoding(t *testing.T) {
 	}
 
 }
+
+func TestSignDeterministic(t *testing.T) {
+	key, err := GenerateECDSAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := SHA256([]byte("time for beer"))
+
+	r1, s1, err := SignDeterministic(key, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, s2, err := SignDeterministic(key, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r1.Cmp(r2) != 0 || s1.Cmp(s2) != 0 {
+		t.Fatal("signatures of the same hash should be equal")
+	}
+	if !Verify(&key.PublicKey, hash, r1, s1) {
+		t.Fatal("deterministic signature should verify")
+	}
+
+	r3, _, err := SignDeterministic(key, SHA256([]byte("time for tea")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r1.Cmp(r3) == 0 {
+		t.Fatal("signatures of different hashes should differ")
+	}
+}
+
+func TestKeyTypes(t *testing.T) {
+	hash := SHA256([]byte("time for beer"))
+	for _, keyType := range []KeyType{KeyP256, KeySecp256k1, KeyEd25519} {
+		key, err := GenerateKey(keyType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if TypeOf(&key.PublicKey) != keyType {
+			t.Fatalf("generated a %s key, want %s", TypeOf(&key.PublicKey), keyType)
+		}
+
+		pub := ToPublicKey(keyType, FromECDSAPub(&key.PublicKey))
+		r, s, err := Sign(key, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, s, err = DecodeSignature(EncodeSignature(r, s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(pub, hash, r, s) {
+			t.Fatalf("%s signature should verify", keyType)
+		}
+		if Verify(pub, SHA256([]byte("time for tea")), r, s) {
+			t.Fatalf("%s signature of another hash should not verify", keyType)
+		}
+
+		dump, err := ToPemKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		read, err := NewPemKey("").ReadKeyFromBuf([]byte(dump.PrivateKey))
+		if err != nil {
+			t.Fatalf("reading %s key: %v", keyType, err)
+		}
+		if TypeOf(&read.PublicKey) != keyType || read.D.Cmp(key.D) != 0 ||
+			!bytes.Equal(FromECDSAPub(&read.PublicKey), FromECDSAPub(&key.PublicKey)) {
+			t.Fatalf("%s keys do not match", keyType)
+		}
+	}
+}
+
+func TestKeyTypeTag(t *testing.T) {
+	key, err := GenerateKey(KeyEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump, err := ToPemKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dump.PrivateKey, "Key-Type: ed25519") {
+		t.Fatalf("the key should be tagged:\n%s", dump.PrivateKey)
+	}
+	tagged := strings.Replace(dump.PrivateKey, "Key-Type: ed25519", "Key-Type: secp256k1", 1)
+	if _, err := NewPemKey("").ReadKeyFromBuf([]byte(tagged)); err == nil {
+		t.Fatal("reading a key of another type than its tag should fail")
+	}
+}
@@ -0,0 +1,135 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+)
+
+// deterministic switches Sign to RFC 6979 nonces
+var deterministic int32
+
+// SetDeterministicSignatures makes Sign derive its nonce from the key and
+// the hash (RFC 6979) instead of reading it from crypto/rand, so that the
+// same key signing the same hash always produces the same signature. The
+// simulation runtime relies on it since consensus orders concurrent events
+// by signature.
+func SetDeterministicSignatures(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&deterministic, v)
+}
+
+// DeterministicSignatures tells whether Sign uses RFC 6979 nonces, see
+// SetDeterministicSignatures
+func DeterministicSignatures() bool {
+	return atomic.LoadInt32(&deterministic) == 1
+}
+
+// SignDeterministic signs hash with a nonce generated according to RFC 6979
+// using HMAC-SHA256
+func SignDeterministic(priv *ecdsa.PrivateKey, hash []byte) (r, s *big.Int, err error) {
+	n := priv.Curve.Params().N
+	e := hashToInt(hash, n)
+	nonces := newRFC6979(priv.D, e, n)
+
+	for i := 0; i < 100; i++ {
+		k := nonces.next()
+		x, _ := priv.Curve.ScalarBaseMult(k.Bytes())
+		r = new(big.Int).Mod(x, n)
+		if r.Sign() == 0 {
+			continue
+		}
+		s = new(big.Int).Mul(r, priv.D)
+		s.Add(s, e)
+		s.Mul(s, new(big.Int).ModInverse(k, n))
+		s.Mod(s, n)
+		if s.Sign() != 0 {
+			return r, s, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("no valid nonce found")
+}
+
+// hashToInt converts a hash to an integer the way crypto/ecdsa does: by
+// keeping its leftmost bits up to the order length
+func hashToInt(hash []byte, n *big.Int) *big.Int {
+	orderBits := n.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(hash) > orderBytes {
+		hash = hash[:orderBytes]
+	}
+	ret := new(big.Int).SetBytes(hash)
+	if excess := len(hash)*8 - orderBits; excess > 0 {
+		ret.Rsh(ret, uint(excess))
+	}
+	return ret
+}
+
+// rfc6979 generates the successive candidate nonces of RFC 6979 section 3.2
+type rfc6979 struct {
+	n    *big.Int
+	size int
+	k, v []byte
+}
+
+func newRFC6979(d, e, n *big.Int) *rfc6979 {
+	g := &rfc6979{
+		n:    n,
+		size: (n.BitLen() + 7) / 8,
+		k:    make([]byte, sha256.Size),
+		v:    make([]byte, sha256.Size),
+	}
+	for i := range g.v {
+		g.v[i] = 0x01
+	}
+
+	x := g.octets(d)
+	h := g.octets(new(big.Int).Mod(e, n))
+	g.k = g.mac(g.v, []byte{0x00}, x, h)
+	g.v = g.mac(g.v)
+	g.k = g.mac(g.v, []byte{0x01}, x, h)
+	g.v = g.mac(g.v)
+	return g
+}
+
+// next returns the next nonce in [1, n-1]
+func (g *rfc6979) next() *big.Int {
+	for {
+		var t []byte
+		for len(t) < g.size {
+			g.v = g.mac(g.v)
+			t = append(t, g.v...)
+		}
+		k := hashToInt(t, g.n)
+
+		// prepare the state for the following candidate
+		g.k = g.mac(g.v, []byte{0x00})
+		g.v = g.mac(g.v)
+
+		if k.Sign() > 0 && k.Cmp(g.n) < 0 {
+			return k
+		}
+	}
+}
+
+func (g *rfc6979) octets(i *big.Int) []byte {
+	b := i.Bytes()
+	if len(b) >= g.size {
+		return b[len(b)-g.size:]
+	}
+	return append(make([]byte, g.size-len(b)), b...)
+}
+
+func (g *rfc6979) mac(data ...[]byte) []byte {
+	h := hmac.New(sha256.New, g.k)
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,181 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// KeyType is the signature scheme of the keys of a network, with which the
+// nodes sign their events and blocks. All the nodes of a network use the
+// same one.
+type KeyType string
+
+// Key types
+const (
+	// KeyP256 is ECDSA on the NIST P-256 curve, the default
+	KeyP256 KeyType = "p256"
+	// KeySecp256k1 is ECDSA on the secp256k1 curve
+	KeySecp256k1 KeyType = "secp256k1"
+	// KeyEd25519 is EdDSA on Curve25519
+	KeyEd25519 KeyType = "ed25519"
+)
+
+// The keys of every type are carried by ecdsa.PrivateKey and ecdsa.PublicKey,
+// their Curve telling their type apart. An Ed25519 key holds its seed in D
+// and its public key in X, under the ed25519Curve marker which does not
+// support curve arithmetic. Its 64 bytes signature is split into r and s.
+
+// ed25519Curve marks the Ed25519 keys
+var ed25519Curve elliptic.Curve = &elliptic.CurveParams{
+	Name:    "Ed25519",
+	BitSize: 256,
+	P:       new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19)),
+	N:       ed25519Order(),
+	Gx:      new(big.Int),
+	Gy:      new(big.Int),
+}
+
+func ed25519Order() *big.Int {
+	n, _ := new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+	return n
+}
+
+// keyType is the KeyType of the network, see SetKeyType
+var keyType atomic.Value
+
+func init() {
+	keyType.Store(KeyP256)
+}
+
+// ParseKeyType returns the KeyType named s, KeyP256 when s is empty
+func ParseKeyType(s string) (KeyType, error) {
+	switch t := KeyType(s); t {
+	case "":
+		return KeyP256, nil
+	case KeyP256, KeySecp256k1, KeyEd25519:
+		return t, nil
+	default:
+		return "", fmt.Errorf("unknown key type %q, want %s, %s or %s", s, KeyP256, KeySecp256k1, KeyEd25519)
+	}
+}
+
+// SetKeyType sets the KeyType of the network, that of the keys
+// GenerateECDSAKey generates and ToECDSAPub decodes
+func SetKeyType(t KeyType) {
+	keyType.Store(t)
+}
+
+// CurrentKeyType returns the KeyType of the network
+func CurrentKeyType() KeyType {
+	return keyType.Load().(KeyType)
+}
+
+// TypeOf returns the KeyType of a key
+func TypeOf(pub *ecdsa.PublicKey) KeyType {
+	switch pub.Curve {
+	case ed25519Curve:
+		return KeyEd25519
+	case secp256k1.S256():
+		return KeySecp256k1
+	default:
+		return KeyP256
+	}
+}
+
+// GenerateKey generates a key of type t
+func GenerateKey(t KeyType) (*ecdsa.PrivateKey, error) {
+	switch t {
+	case KeyEd25519:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return FromEd25519(priv), nil
+	case KeySecp256k1:
+		priv, err := secp256k1.GeneratePrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		return priv.ToECDSA(), nil
+	default:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+}
+
+// FromEd25519 returns the key carrying an Ed25519 key
+func FromEd25519(priv ed25519.PrivateKey) *ecdsa.PrivateKey {
+	pub := priv.Public().(ed25519.PublicKey)
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: ed25519Curve,
+			X:     new(big.Int).SetBytes(pub),
+			Y:     new(big.Int),
+		},
+		D: new(big.Int).SetBytes(priv.Seed()),
+	}
+}
+
+// ToEd25519 returns the Ed25519 key carried by an ed25519 key
+func ToEd25519(priv *ecdsa.PrivateKey) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(leftPad(priv.D.Bytes(), ed25519.SeedSize))
+}
+
+// ToEd25519Pub returns the Ed25519 public key carried by an ed25519 key
+func ToEd25519Pub(pub *ecdsa.PublicKey) ed25519.PublicKey {
+	return leftPad(pub.X.Bytes(), ed25519.PublicKeySize)
+}
+
+// ToPublicKey decodes a public key of type t encoded by FromECDSAPub
+func ToPublicKey(t KeyType, pub []byte) *ecdsa.PublicKey {
+	if len(pub) == 0 {
+		return nil
+	}
+	var curve elliptic.Curve
+	switch t {
+	case KeyEd25519:
+		if len(pub) != ed25519.PublicKeySize {
+			return &ecdsa.PublicKey{Curve: ed25519Curve}
+		}
+		return &ecdsa.PublicKey{Curve: ed25519Curve, X: new(big.Int).SetBytes(pub), Y: new(big.Int)}
+	case KeySecp256k1:
+		curve = secp256k1.S256()
+	default:
+		curve = elliptic.P256()
+	}
+	x, y := elliptic.Unmarshal(curve, pub)
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+}
+
+// signEd25519 signs hash with an ed25519 key
+func signEd25519(priv *ecdsa.PrivateKey, hash []byte) (r, s *big.Int) {
+	sig := ed25519.Sign(ToEd25519(priv), hash)
+	half := ed25519.SignatureSize / 2
+	return new(big.Int).SetBytes(sig[:half]), new(big.Int).SetBytes(sig[half:])
+}
+
+// verifyEd25519 checks the signature of hash by an ed25519 key
+func verifyEd25519(pub *ecdsa.PublicKey, hash []byte, r, s *big.Int) bool {
+	half := ed25519.SignatureSize / 2
+	if pub.X == nil || r == nil || s == nil || r.BitLen() > 8*half || s.BitLen() > 8*half {
+		return false
+	}
+	sig := append(leftPad(r.Bytes(), half), leftPad(s.Bytes(), half)...)
+	return ed25519.Verify(ToEd25519Pub(pub), hash, sig)
+}
+
+// leftPad returns b preceded by zeros up to size bytes
+func leftPad(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	res := make([]byte, size)
+	copy(res[size-len(b):], b)
+	return res
+}
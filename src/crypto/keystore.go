@@ -0,0 +1,274 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeystoreDir is the directory of the keystore under the data directory
+const KeystoreDir = "keystore"
+
+// Scrypt cost parameters of the keystores. LightScryptN is meant for tests.
+const (
+	StandardScryptN = 1 << 18
+	LightScryptN    = 1 << 12
+	scryptR         = 8
+	scryptP         = 1
+	scryptKeyLen    = 32
+	scryptSaltLen   = 32
+)
+
+// EncryptedKey is a private key encrypted with a passphrase, as written in a
+// keystore file. The key, in the PEM format of PemKey, is encrypted with
+// AES-256-GCM by a key derived from the passphrase with scrypt. The public
+// key is authenticated with it.
+type EncryptedKey struct {
+	Version    int          `json:"version"`
+	KeyType    KeyType      `json:"key_type"`
+	PublicKey  string       `json:"public_key"`
+	KDF        string       `json:"kdf"`
+	KDFParams  ScryptParams `json:"kdf_params"`
+	Cipher     string       `json:"cipher"`
+	Nonce      string       `json:"nonce"`
+	CipherText string       `json:"ciphertext"`
+
+	// Path is the file of the key in its keystore
+	Path string `json:"-"`
+}
+
+// ScryptParams are the parameters of the scrypt key derivation
+type ScryptParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt string `json:"salt"`
+}
+
+// EncryptKey encrypts key with passphrase, with the scrypt cost scryptN
+func EncryptKey(key *ecdsa.PrivateKey, passphrase string, scryptN int) (*EncryptedKey, error) {
+	dump, err := ToPemKey(key)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, scryptSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params := ScryptParams{N: scryptN, R: scryptR, P: scryptP, Salt: hex.EncodeToString(salt)}
+	aead, err := params.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &EncryptedKey{
+		Version:    1,
+		KeyType:    TypeOf(&key.PublicKey),
+		PublicKey:  dump.PublicKey,
+		KDF:        "scrypt",
+		KDFParams:  params,
+		Cipher:     "aes-256-gcm",
+		Nonce:      hex.EncodeToString(nonce),
+		CipherText: hex.EncodeToString(aead.Seal(nil, nonce, []byte(dump.PrivateKey), []byte(dump.PublicKey))),
+	}, nil
+}
+
+// Decrypt returns the private key, failing when passphrase is wrong
+func (k *EncryptedKey) Decrypt(passphrase string) (*ecdsa.PrivateKey, error) {
+	if k.Version != 1 || k.KDF != "scrypt" || k.Cipher != "aes-256-gcm" {
+		return nil, fmt.Errorf("unsupported keystore key: version %d, %s and %s", k.Version, k.KDF, k.Cipher)
+	}
+	aead, err := k.KDFParams.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(k.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce in keystore key %s", k.PublicKey)
+	}
+	ciphertext, err := hex.DecodeString(k.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext in keystore key %s", k.PublicKey)
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(k.PublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("wrong passphrase for keystore key %s", k.PublicKey)
+	}
+	key, err := (&PemKey{}).ReadKeyFromBuf(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if pub := fmt.Sprintf("0x%X", FromECDSAPub(&key.PublicKey)); pub != k.PublicKey {
+		return nil, fmt.Errorf("keystore key %s holds the key %s", k.PublicKey, pub)
+	}
+	return key, nil
+}
+
+// aead returns the cipher of the key derived from passphrase
+func (p ScryptParams) aead(passphrase string) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(p.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scrypt salt: %s", err)
+	}
+	derived, err := scrypt.Key([]byte(passphrase), salt, p.N, p.R, p.P, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Keystore is a directory of keys encrypted with a passphrase, a file per key
+type Keystore struct {
+	dir string
+	// ScryptN is the scrypt cost of the keys written by the keystore
+	ScryptN int
+}
+
+// NewKeystore returns the keystore of the data directory datadir
+func NewKeystore(datadir string) *Keystore {
+	return &Keystore{
+		dir:     filepath.Join(datadir, KeystoreDir),
+		ScryptN: StandardScryptN,
+	}
+}
+
+// Store encrypts key with passphrase in a new file of the keystore
+func (ks *Keystore) Store(key *ecdsa.PrivateKey, passphrase string) (*EncryptedKey, error) {
+	ek, err := EncryptKey(key, passphrase, ks.ScryptN)
+	if err != nil {
+		return nil, err
+	}
+	ek.Path = filepath.Join(ks.dir, ek.PublicKey+".json")
+	if _, err := os.Stat(ek.Path); err == nil {
+		return nil, fmt.Errorf("the keystore already holds the key %s", ek.PublicKey)
+	}
+	return ek, ks.write(ek)
+}
+
+// List returns the keys of the keystore, sorted by public key
+func (ks *Keystore) List() ([]*EncryptedKey, error) {
+	files, err := ioutil.ReadDir(ks.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var keys []*EncryptedKey
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(ks.dir, f.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		ek := new(EncryptedKey)
+		if err := json.Unmarshal(data, ek); err != nil {
+			return nil, fmt.Errorf("reading keystore file %s: %s", path, err)
+		}
+		ek.Path = path
+		keys = append(keys, ek)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].PublicKey < keys[j].PublicKey })
+	return keys, nil
+}
+
+// Find returns the key whose public key starts with prefix, the only key of
+// the keystore when prefix is empty
+func (ks *Keystore) Find(prefix string) (*EncryptedKey, error) {
+	keys, err := ks.List()
+	if err != nil {
+		return nil, err
+	}
+	var found []*EncryptedKey
+	for _, k := range keys {
+		if strings.HasPrefix(strings.ToUpper(k.PublicKey), strings.ToUpper(prefix)) {
+			found = append(found, k)
+		}
+	}
+	switch {
+	case len(found) == 1:
+		return found[0], nil
+	case len(found) == 0 && prefix == "":
+		return nil, fmt.Errorf("the keystore %s holds no key", ks.dir)
+	case len(found) == 0:
+		return nil, fmt.Errorf("no key %s in the keystore %s", prefix, ks.dir)
+	case prefix == "":
+		return nil, fmt.Errorf("the keystore %s holds %d keys, select one", ks.dir, len(found))
+	default:
+		return nil, fmt.Errorf("%d keys of the keystore %s start with %s", len(found), ks.dir, prefix)
+	}
+}
+
+// Load decrypts the key whose public key starts with prefix, see Find
+func (ks *Keystore) Load(prefix, passphrase string) (*ecdsa.PrivateKey, error) {
+	ek, err := ks.Find(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return ek.Decrypt(passphrase)
+}
+
+// ChangePassphrase encrypts again the key whose public key starts with
+// prefix, with passphrase instead of old
+func (ks *Keystore) ChangePassphrase(prefix, old, passphrase string) error {
+	ek, err := ks.Find(prefix)
+	if err != nil {
+		return err
+	}
+	key, err := ek.Decrypt(old)
+	if err != nil {
+		return err
+	}
+	updated, err := EncryptKey(key, passphrase, ks.ScryptN)
+	if err != nil {
+		return err
+	}
+	updated.Path = ek.Path
+	return ks.write(updated)
+}
+
+// write replaces the file of a key, so that it is never left half written
+func (ks *Keystore) write(ek *EncryptedKey) error {
+	data, err := json.MarshalIndent(ek, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(ks.dir, 0700); err != nil {
+		return err
+	}
+	tmp := ek.Path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, ek.Path)
+}
+
+// ReadPassphraseFile returns the passphrase on the first line of a file
+func ReadPassphraseFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(strings.SplitN(string(data), "\n", 2)[0], "\r"), nil
+}
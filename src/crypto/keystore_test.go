@@ -0,0 +1,74 @@
+package crypto
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestKeystore(t *testing.T) {
+	dir, err := ioutil.TempDir("test_data", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ks := NewKeystore(dir)
+	ks.ScryptN = LightScryptN
+	if _, err := ks.Find(""); err == nil {
+		t.Fatal("an empty keystore should have no key")
+	}
+
+	key, err := GenerateKey(KeyEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek, err := ks.Store(key, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.Store(key, "secret"); err == nil {
+		t.Fatal("storing a key twice should fail")
+	}
+	data, err := ioutil.ReadFile(ek.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pem, _ := ToPemKey(key)
+	if string(data) == pem.PrivateKey || ek.KeyType != KeyEd25519 {
+		t.Fatalf("unexpected keystore file %s", data)
+	}
+
+	if _, err := ks.Load(ek.PublicKey[:10], "wrong"); err == nil {
+		t.Fatal("a wrong passphrase should fail")
+	}
+	loaded, err := ks.Load(ek.PublicKey[:10], "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.D.Cmp(key.D) != 0 {
+		t.Fatal("the loaded key should be the stored one")
+	}
+
+	if err := ks.ChangePassphrase("", "secret", "new secret"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.Load("", "secret"); err == nil {
+		t.Fatal("the old passphrase should fail")
+	}
+	if _, err := ks.Load("", "new secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := GenerateKey(KeyP256)
+	if _, err := ks.Store(other, "other"); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := ks.List()
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("the keystore should list 2 keys, got %d: %v", len(keys), err)
+	}
+	if _, err := ks.Find(""); err == nil {
+		t.Fatal("selecting a key among several needs a prefix")
+	}
+}
@@ -0,0 +1,73 @@
+package crypto
+
+import "bytes"
+
+// SimpleProof proves that a leaf hash is part of the tree built by
+// SimpleHashFromHashes. Aunts are the sibling hashes from the leaf up to the
+// root.
+type SimpleProof struct {
+	Index int
+	Total int
+	Aunts [][]byte
+}
+
+// NewSimpleProof returns the proof of the leaf at index in hashes
+func NewSimpleProof(hashes [][]byte, index int) *SimpleProof {
+	return &SimpleProof{
+		Index: index,
+		Total: len(hashes),
+		Aunts: aunts(hashes, index),
+	}
+}
+
+func aunts(hashes [][]byte, index int) [][]byte {
+	if len(hashes) <= 1 {
+		return nil
+	}
+	k := (len(hashes) + 1) / 2
+	if index < k {
+		return append(aunts(hashes[:k], index), SimpleHashFromHashes(hashes[k:]))
+	}
+	return append(aunts(hashes[k:], index-k), SimpleHashFromHashes(hashes[:k]))
+}
+
+// Root computes the root of the tree from the leaf hash, nil when the proof
+// is malformed
+func (p *SimpleProof) Root(leaf []byte) []byte {
+	return rootFromAunts(p.Index, p.Total, leaf, p.Aunts)
+}
+
+// Verify returns true if leaf is part of the tree of the given root
+func (p *SimpleProof) Verify(root, leaf []byte) bool {
+	res := p.Root(leaf)
+	return res != nil && bytes.Equal(res, root)
+}
+
+func rootFromAunts(index, total int, leaf []byte, aunts [][]byte) []byte {
+	if index < 0 || index >= total {
+		return nil
+	}
+	if total == 1 {
+		if len(aunts) != 0 {
+			return nil
+		}
+		return leaf
+	}
+	if len(aunts) == 0 {
+		return nil
+	}
+	k := (total + 1) / 2
+	sibling, rest := aunts[len(aunts)-1], aunts[:len(aunts)-1]
+	if index < k {
+		left := rootFromAunts(index, k, leaf, rest)
+		if left == nil {
+			return nil
+		}
+		return SimpleHashFromTwoHashes(left, sibling)
+	}
+	right := rootFromAunts(index-k, total-k, leaf, rest)
+	if right == nil {
+		return nil
+	}
+	return SimpleHashFromTwoHashes(sibling, right)
+}
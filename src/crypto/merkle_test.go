@@ -0,0 +1,31 @@
+package crypto
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSimpleProof(t *testing.T) {
+	for total := 1; total <= 9; total++ {
+		var hashes [][]byte
+		for i := 0; i < total; i++ {
+			hashes = append(hashes, SHA256([]byte(fmt.Sprint(i))))
+		}
+		root := SimpleHashFromHashes(hashes)
+		for i := range hashes {
+			proof := NewSimpleProof(hashes, i)
+			if !proof.Verify(root, hashes[i]) {
+				t.Fatalf("proof of leaf %d/%d does not verify", i, total)
+			}
+			if total > 1 && proof.Verify(root, hashes[(i+1)%total]) {
+				t.Fatalf("proof of leaf %d/%d verifies another leaf", i, total)
+			}
+		}
+	}
+
+	proof := NewSimpleProof([][]byte{SHA256([]byte("a")), SHA256([]byte("b"))}, 0)
+	proof.Index = 2
+	if proof.Root(SHA256([]byte("a"))) != nil {
+		t.Fatal("out of range proofs should not compute a root")
+	}
+}
@@ -2,7 +2,6 @@ package crypto
 
 import (
 	"crypto/ecdsa"
-	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
@@ -55,7 +54,7 @@ func (k *PemKey) ReadKeyFromBuf(buf []byte) (*ecdsa.PrivateKey, error) {
 		return nil, fmt.Errorf("error decoding PEM block from data")
 	}
 
-	return x509.ParseECPrivateKey(block.Bytes)
+	return decodePemBlock(block)
 }
 
 func (k *PemKey) WriteKey(key *ecdsa.PrivateKey) error {
@@ -80,6 +79,7 @@ type PemDump struct {
 	PrivateKey string
 }
 
+// GeneratePemKey generates a key of the KeyType of the network
 func GeneratePemKey() (*PemDump, error) {
 	key, err := GenerateECDSAKey()
 	if err != nil {
@@ -92,14 +92,12 @@ func GeneratePemKey() (*PemDump, error) {
 func ToPemKey(priv *ecdsa.PrivateKey) (*PemDump, error) {
 	pub := fmt.Sprintf("0x%X", FromECDSAPub(&priv.PublicKey))
 
-	b, err := x509.MarshalECPrivateKey(priv)
+	pemBlock, err := encodePemBlock(priv)
 
 	if err != nil {
 		return nil, err
 	}
 
-	pemBlock := &pem.Block{Type: "EC PRIVATE KEY", Bytes: b}
-
 	data := pem.EncodeToMemory(pemBlock)
 
 	return &PemDump{
@@ -0,0 +1,114 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// pemKeyTypeHeader is the PEM header tagging the keys with their KeyType.
+// The P-256 keys are not tagged, so that their files are the ones of the
+// previous versions.
+const pemKeyTypeHeader = "Key-Type"
+
+// oidSecp256k1 is the ASN.1 identifier of the secp256k1 curve
+var oidSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+// sec1Key is the SEC 1 structure of an elliptic curve private key, which
+// x509 only writes for the NIST curves
+type sec1Key struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// encodePemBlock encodes a key in a PEM block: a SEC 1 EC PRIVATE KEY for
+// the ECDSA keys and a PKCS #8 PRIVATE KEY for the Ed25519 keys
+func encodePemBlock(priv *ecdsa.PrivateKey) (*pem.Block, error) {
+	t := TypeOf(&priv.PublicKey)
+	switch t {
+	case KeyEd25519:
+		b, err := x509.MarshalPKCS8PrivateKey(ToEd25519(priv))
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{
+			Type:    "PRIVATE KEY",
+			Headers: map[string]string{pemKeyTypeHeader: string(t)},
+			Bytes:   b,
+		}, nil
+	case KeySecp256k1:
+		pub := FromECDSAPub(&priv.PublicKey)
+		b, err := asn1.Marshal(sec1Key{
+			Version:       1,
+			PrivateKey:    leftPad(priv.D.Bytes(), 32),
+			NamedCurveOID: oidSecp256k1,
+			PublicKey:     asn1.BitString{Bytes: pub, BitLength: 8 * len(pub)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{
+			Type:    "EC PRIVATE KEY",
+			Headers: map[string]string{pemKeyTypeHeader: string(t)},
+			Bytes:   b,
+		}, nil
+	default:
+		b, err := x509.MarshalECPrivateKey(priv)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{Type: "EC PRIVATE KEY", Bytes: b}, nil
+	}
+}
+
+// decodePemBlock decodes a key encoded by encodePemBlock, checking it is of
+// the type of its tag
+func decodePemBlock(block *pem.Block) (*ecdsa.PrivateKey, error) {
+	t, err := ParseKeyType(block.Headers[pemKeyTypeHeader])
+	if err != nil {
+		return nil, err
+	}
+
+	var key *ecdsa.PrivateKey
+	switch {
+	case block.Type == "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch k := parsed.(type) {
+		case ed25519.PrivateKey:
+			key = FromEd25519(k)
+		case *ecdsa.PrivateKey:
+			key = k
+		default:
+			return nil, fmt.Errorf("unsupported private key %T", parsed)
+		}
+	case t == KeySecp256k1:
+		var k sec1Key
+		if _, err := asn1.Unmarshal(block.Bytes, &k); err != nil {
+			return nil, err
+		}
+		if !k.NamedCurveOID.Equal(oidSecp256k1) {
+			return nil, fmt.Errorf("the curve of the key is not secp256k1")
+		}
+		key = secp256k1.PrivKeyFromBytes(k.PrivateKey).ToECDSA()
+	default:
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if TypeOf(&key.PublicKey) != t {
+		return nil, fmt.Errorf("the key is tagged %s but is a %s key", t, TypeOf(&key.PublicKey))
+	}
+	return key, nil
+}
@@ -7,32 +7,45 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync/atomic"
 )
 
+// GenerateECDSAKey generates a key of the KeyType of the network
 func GenerateECDSAKey() (*ecdsa.PrivateKey, error) {
-	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	return GenerateKey(CurrentKeyType())
 }
 
+// ToECDSAPub decodes a public key of the KeyType of the network
 func ToECDSAPub(pub []byte) *ecdsa.PublicKey {
-	if len(pub) == 0 {
-		return nil
-	}
-	x, y := elliptic.Unmarshal(elliptic.P256(), pub)
-	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	return ToPublicKey(CurrentKeyType(), pub)
 }
 
 func FromECDSAPub(pub *ecdsa.PublicKey) []byte {
 	if pub == nil || pub.X == nil || pub.Y == nil {
 		return nil
 	}
-	return elliptic.Marshal(elliptic.P256(), pub.X, pub.Y)
+	if TypeOf(pub) == KeyEd25519 {
+		return ToEd25519Pub(pub)
+	}
+	return elliptic.Marshal(pub.Curve, pub.X, pub.Y)
 }
 
 func Sign(priv *ecdsa.PrivateKey, hash []byte) (r, s *big.Int, err error) {
+	if TypeOf(&priv.PublicKey) == KeyEd25519 {
+		// Ed25519 signatures are deterministic
+		r, s = signEd25519(priv, hash)
+		return r, s, nil
+	}
+	if atomic.LoadInt32(&deterministic) == 1 {
+		return SignDeterministic(priv, hash)
+	}
 	return ecdsa.Sign(rand.Reader, priv, hash)
 }
 
 func Verify(pub *ecdsa.PublicKey, hash []byte, r, s *big.Int) bool {
+	if TypeOf(pub) == KeyEd25519 {
+		return verifyEd25519(pub, hash, r, s)
+	}
 	return ecdsa.Verify(pub, hash, r, s)
 }
 
@@ -0,0 +1,80 @@
+// Package daemon implements the systemd notification protocol (sd_notify),
+// so that process managers can tell when a node is ready, stopping or hung.
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Notification states, see sd_notify(3)
+const (
+	// Ready tells the manager the node finished starting
+	Ready = "READY=1"
+	// Stopping tells the manager the node started shutting down
+	Stopping = "STOPPING=1"
+	// Watchdog keeps the watchdog of the manager from restarting the node
+	Watchdog = "WATCHDOG=1"
+)
+
+// Status returns the notification of a free-form status line
+func Status(format string, args ...interface{}) string {
+	return "STATUS=" + fmt.Sprintf(format, args...)
+}
+
+// Notify sends state to the socket of $NOTIFY_SOCKET. It returns false
+// without error when the process is not run by a manager listening to
+// notifications.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// abstract sockets are denoted by a leading @
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the watchdog timeout the manager set for this
+// process, false when it has none
+func WatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+	return time.Duration(usec) * time.Microsecond, true
+}
+
+// RunWatchdog pings the watchdog every half interval while healthy returns
+// true, until stop is closed. A hung or unhealthy node stops pinging and is
+// restarted by the manager.
+func RunWatchdog(interval time.Duration, healthy func() bool, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if healthy() {
+				Notify(Watchdog)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNotify(t *testing.T) {
+	os.Unsetenv("NOTIFY_SOCKET")
+	if sent, err := Notify(Ready); sent || err != nil {
+		t.Fatalf("Notify without socket should be a no-op, got %v, %v", sent, err)
+	}
+
+	dir, err := ioutil.TempDir("", "notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	os.Setenv("NOTIFY_SOCKET", path)
+	defer os.Unsetenv("NOTIFY_SOCKET")
+	if sent, err := Notify(Ready); !sent || err != nil {
+		t.Fatalf("Notify should send, got %v, %v", sent, err)
+	}
+
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:n]) != Ready {
+		t.Fatalf("expected %q, got %q", Ready, buf[:n])
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	os.Setenv("WATCHDOG_USEC", "3000000")
+	defer os.Unsetenv("WATCHDOG_USEC")
+
+	if d, ok := WatchdogInterval(); !ok || d != 3*time.Second {
+		t.Fatalf("expected 3s watchdog, got %v, %v", d, ok)
+	}
+
+	os.Setenv("WATCHDOG_PID", "1")
+	defer os.Unsetenv("WATCHDOG_PID")
+	if _, ok := WatchdogInterval(); ok && os.Getpid() != 1 {
+		t.Fatal("watchdog of another process should be ignored")
+	}
+}
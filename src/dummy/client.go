@@ -19,6 +19,30 @@ func NewInmemDummyApp(logger *logrus.Logger) proxy.AppProxy {
 	return proxy.NewInmemAppProxy(state, logger)
 }
 
+// NewPersistentInmemDummyApp returns an in-memory dummy app keeping its state
+// in dir
+func NewPersistentInmemDummyApp(dir string, logger *logrus.Logger) (proxy.AppProxy, error) {
+	state, err := NewPersistentState(dir, logger)
+	if err != nil {
+		return nil, err
+	}
+	return proxy.NewInmemAppProxy(state, logger), nil
+}
+
+// NewPersistentDummySocketClient returns a dummy socket client committing
+// blocks to a state kept in dir
+func NewPersistentDummySocketClient(addr, dir string, logger *logrus.Logger) (*DummyClient, error) {
+	state, err := NewPersistentState(dir, logger)
+	if err != nil {
+		return nil, err
+	}
+	lachesisProxy, err := proxy.NewGrpcLachesisProxy(addr, logger)
+	if err != nil {
+		return nil, err
+	}
+	return NewDummyClient(lachesisProxy, state, logger)
+}
+
 func NewDummySocketClient(addr string, logger *logrus.Logger) (*DummyClient, error) {
 	lachesisProxy, err := proxy.NewGrpcLachesisProxy(addr, logger)
 	if err != nil {
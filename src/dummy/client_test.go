@@ -1,6 +1,7 @@
 package dummy
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -98,7 +99,10 @@ func TestDummySocketClient(t *testing.T) {
 	snapshot, err := appProxy.GetSnapshot(blocks[0].Index())
 	asserter.NoError(err)
 
-	asserter.Equal(expectedStateHash, snapshot)
+	// snapshots carry the state hash along with the transactions
+	var snap Snapshot
+	asserter.NoError(json.Unmarshal(snapshot, &snap))
+	asserter.Equal(expectedStateHash, snap.StateHash)
 
 	//commit a few more blocks, then attempt to restore back to block 0 state
 	for i := 1; i < 5; i++ {
@@ -0,0 +1,51 @@
+package dummy
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config is the configuration of the dummy socket client, shared by its
+// command line frontends
+type Config struct {
+	Name       string `mapstructure:"name"`
+	ClientAddr string `mapstructure:"client-listen"`
+	ProxyAddr  string `mapstructure:"proxy-connect"`
+	Discard    bool   `mapstructure:"discard"`
+	LogLevel   string `mapstructure:"log"`
+	// DataDir keeps the committed state and snapshots across restarts,
+	// empty for an in-memory state
+	DataDir string `mapstructure:"datadir"`
+}
+
+// NewDefaultConfig creates a Config with default values
+func NewDefaultConfig() *Config {
+	return &Config{
+		Name:       "Dummy",
+		ClientAddr: "127.0.0.1:1339",
+		ProxyAddr:  "127.0.0.1:1338",
+		LogLevel:   "debug",
+	}
+}
+
+// Validate checks the addresses and the log level
+func (c *Config) Validate() error {
+	if _, _, err := net.SplitHostPort(c.ProxyAddr); err != nil {
+		return fmt.Errorf("proxy address must be host:port, got %q", c.ProxyAddr)
+	}
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+		return fmt.Errorf("log level must be one of debug, info, warn, error, fatal, panic, got %q", c.LogLevel)
+	}
+	return nil
+}
+
+// Level returns the log level, debug when it is invalid
+func (c *Config) Level() logrus.Level {
+	level, err := logrus.ParseLevel(c.LogLevel)
+	if err != nil {
+		return logrus.DebugLevel
+	}
+	return level
+}
@@ -0,0 +1,226 @@
+package dummy
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+const (
+	// txLogFile holds the committed transactions, each prefixed with its
+	// uvarint length
+	txLogFile = "txs.log"
+	// stateFile holds the last block, the state hash and the snapshot marks
+	stateFile = "state.json"
+)
+
+// persistedState is the content of stateFile
+type persistedState struct {
+	LastBlock int64
+	TxCount   int
+	StateHash []byte
+	Snapshots map[int64]snapshotMark
+}
+
+// stateStore persists a State to a directory
+type stateStore struct {
+	dir string
+	log *os.File
+}
+
+// NewPersistentState returns a State kept in dir, reloading the state
+// committed before a restart
+func NewPersistentState(dir string, logger *logrus.Logger) (*State, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	s := NewState(logger)
+	s.store = &stateStore{dir: dir}
+
+	var ps persistedState
+	data, err := ioutil.ReadFile(filepath.Join(dir, stateFile))
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &ps); err != nil {
+			return nil, fmt.Errorf("decoding %s: %s", stateFile, err)
+		}
+	}
+
+	txs, err := readTxLog(filepath.Join(dir, txLogFile), ps.TxCount)
+	if err != nil {
+		return nil, err
+	}
+	hash := []byte{}
+	for _, tx := range txs {
+		hash = crypto.SimpleHashFromTwoHashes(hash, crypto.SHA256(tx))
+	}
+	if ps.TxCount > 0 && string(hash) != string(ps.StateHash) {
+		return nil, fmt.Errorf("transactions of %s do not match the state hash", dir)
+	}
+
+	s.committedTxs = txs
+	s.stateHash = hash
+	if data != nil {
+		s.lastBlock = ps.LastBlock
+	}
+	if ps.Snapshots != nil {
+		s.snapshots = ps.Snapshots
+	}
+
+	// drop the transactions appended after the state was last saved
+	if err := os.Truncate(filepath.Join(dir, txLogFile), txLogSize(txs)); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if s.store.log, err = os.OpenFile(filepath.Join(dir, txLogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"dir":          dir,
+		"last_block":   s.lastBlock,
+		"transactions": len(txs),
+	}).Info("Loaded Dummy State")
+	return s, nil
+}
+
+// Close closes the transaction log of a persistent state
+func (s *State) Close() error {
+	if s.store == nil || s.store.log == nil {
+		return nil
+	}
+	return s.store.log.Close()
+}
+
+// readTxLog reads the first count transactions of the log at path
+func readTxLog(path string, count int) ([][]byte, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return [][]byte{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	txs := make([][]byte, 0, count)
+	for len(txs) < count {
+		size, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading transaction %d of %s: %s", len(txs), path, err)
+		}
+		tx := make([]byte, size)
+		if _, err := io.ReadFull(r, tx); err != nil {
+			return nil, fmt.Errorf("reading transaction %d of %s: %s", len(txs), path, err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+func txLogSize(txs [][]byte) int64 {
+	var size int64
+	buf := make([]byte, binary.MaxVarintLen64)
+	for _, tx := range txs {
+		size += int64(binary.PutUvarint(buf, uint64(len(tx))) + len(tx))
+	}
+	return size
+}
+
+func writeTxs(w io.Writer, txs [][]byte) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+	for _, tx := range txs {
+		n := binary.PutUvarint(buf, uint64(len(tx)))
+		if _, err := w.Write(buf[:n]); err != nil {
+			return err
+		}
+		if _, err := w.Write(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// append writes the transactions of a block to the log
+func (st *stateStore) append(txs [][]byte) error {
+	w := bufio.NewWriter(st.log)
+	if err := writeTxs(w, txs); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return st.log.Sync()
+}
+
+// save writes the state file, which commits the appended transactions
+func (st *stateStore) save(s *State) error {
+	snapshots := make(map[int64]snapshotMark, len(s.snapshots))
+	for index, mark := range s.snapshots {
+		snapshots[index] = mark
+	}
+	data, err := json.Marshal(persistedState{
+		LastBlock: s.lastBlock,
+		TxCount:   len(s.committedTxs),
+		StateHash: s.stateHash,
+		Snapshots: snapshots,
+	})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(st.dir, stateFile), data)
+}
+
+// rewrite replaces the log and the state file after a restore
+func (st *stateStore) rewrite(s *State) error {
+	if err := st.log.Close(); err != nil {
+		return err
+	}
+	path := filepath.Join(st.dir, txLogFile)
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = writeTxs(w, s.committedTxs)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	if st.log, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return err
+	}
+	return st.save(s)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
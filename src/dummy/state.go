@@ -1,6 +1,8 @@
 package dummy
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 
 	"github.com/sirupsen/logrus"
@@ -14,16 +16,40 @@ import (
  * applications. Here, we define the dummy's state which doesn't really do
  * anything useful. It saves and logs block transactions. The state hash is
  * computed by cumulatively hashing transactions together as they come in.
- * Snapshots correspond to the state hash resulting from executing a the block's
- * transactions.
+ * Snapshots hold the transactions committed up to a block along with the
+ * resulting state hash. A State created with NewPersistentState keeps its
+ * transactions and snapshots on disk, see persistence.go.
  */
 
+// DefaultSnapshotKeep is the number of block snapshots retained
+const DefaultSnapshotKeep = 100
+
 // State implements ProxyHandler
 type State struct {
 	logger       *logrus.Logger
 	committedTxs [][]byte
 	stateHash    []byte
-	snapshots    map[int64][]byte
+	lastBlock    int64
+	// snapshots mark the state after each of the last blocks
+	snapshots map[int64]snapshotMark
+	keep      int
+
+	// store persists the state, nil for an in-memory state
+	store *stateStore
+}
+
+// snapshotMark locates the snapshot of a block within the committed
+// transactions
+type snapshotMark struct {
+	TxCount   int
+	StateHash []byte
+}
+
+// Snapshot is the encoding of the state after a block
+type Snapshot struct {
+	BlockIndex   int64
+	StateHash    []byte
+	Transactions [][]byte
 }
 
 func NewState(logger *logrus.Logger) *State {
@@ -31,7 +57,9 @@ func NewState(logger *logrus.Logger) *State {
 		logger:       logger,
 		committedTxs: [][]byte{},
 		stateHash:    []byte{},
-		snapshots:    make(map[int64][]byte),
+		lastBlock:    -1,
+		snapshots:    make(map[int64]snapshotMark),
+		keep:         DefaultSnapshotKeep,
 	}
 	logger.Info("Init Dummy State")
 
@@ -49,24 +77,64 @@ func (s *State) CommitHandler(block poset.Block) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	s.logger.WithField("stateHash", s.stateHash).Debug("CommitBlock Answer")
-	return s.stateHash, nil
+	hash := s.stateHash
+	if mark, ok := s.snapshots[block.Index()]; ok {
+		hash = mark.StateHash
+	}
+	s.logger.WithField("stateHash", hash).Debug("CommitBlock Answer")
+	return hash, nil
 }
 
 func (s *State) SnapshotHandler(blockIndex int64) ([]byte, error) {
 	s.logger.WithField("block", blockIndex).Debug("GetSnapshot")
 
-	snapshot, ok := s.snapshots[blockIndex]
+	mark, ok := s.snapshots[blockIndex]
 	if !ok {
 		return nil, fmt.Errorf("snapshot %d not found", blockIndex)
 	}
 
-	return snapshot, nil
+	return json.Marshal(Snapshot{
+		BlockIndex:   blockIndex,
+		StateHash:    mark.StateHash,
+		Transactions: s.committedTxs[:mark.TxCount],
+	})
 }
 
+// RestoreHandler replaces the state with a snapshot, after checking that its
+// transactions hash to its state hash
 func (s *State) RestoreHandler(snapshot []byte) ([]byte, error) {
-	//XXX do something smart here
-	s.stateHash = snapshot
+	var snap Snapshot
+	if err := json.Unmarshal(snapshot, &snap); err != nil {
+		return nil, fmt.Errorf("decoding snapshot: %s", err)
+	}
+	hash := []byte{}
+	for _, tx := range snap.Transactions {
+		hash = crypto.SimpleHashFromTwoHashes(hash, crypto.SHA256(tx))
+	}
+	if !bytes.Equal(hash, snap.StateHash) {
+		return nil, fmt.Errorf("snapshot of block %d does not match its state hash", snap.BlockIndex)
+	}
+
+	s.committedTxs = snap.Transactions
+	s.stateHash = hash
+	s.lastBlock = snap.BlockIndex
+	s.snapshots = map[int64]snapshotMark{
+		snap.BlockIndex: {TxCount: len(snap.Transactions), StateHash: hash},
+	}
+	if s.store != nil {
+		if err := s.store.rewrite(s); err != nil {
+			return nil, err
+		}
+	}
+	s.logger.WithFields(logrus.Fields{
+		"block":        snap.BlockIndex,
+		"transactions": len(snap.Transactions),
+	}).Info("Restored snapshot")
+	return s.stateHash, nil
+}
+
+// StateHashHandler implements proxy.StateHashHandler
+func (s *State) StateHashHandler() ([]byte, error) {
 	return s.stateHash, nil
 }
 
@@ -78,7 +146,21 @@ func (s *State) GetCommittedTransactions() [][]byte {
 	return s.committedTxs
 }
 
+// GetStateHash returns the hash of the committed transactions
+func (s *State) GetStateHash() []byte {
+	return s.stateHash
+}
+
 func (s *State) commit(block poset.Block) error {
+	if block.Index() <= s.lastBlock {
+		// already committed before a restart
+		return nil
+	}
+	if s.store != nil {
+		if err := s.store.append(block.Transactions()); err != nil {
+			return err
+		}
+	}
 	s.committedTxs = append(s.committedTxs, block.Transactions()...)
 	// log tx and update state hash
 	hash := s.stateHash
@@ -86,7 +168,12 @@ func (s *State) commit(block poset.Block) error {
 		s.logger.Info(string(tx))
 		hash = crypto.SimpleHashFromTwoHashes(hash, crypto.SHA256(tx))
 	}
-	s.snapshots[block.Index()] = hash
+	s.snapshots[block.Index()] = snapshotMark{TxCount: len(s.committedTxs), StateHash: hash}
+	delete(s.snapshots, block.Index()-int64(s.keep))
 	s.stateHash = hash
+	s.lastBlock = block.Index()
+	if s.store != nil {
+		return s.store.save(s)
+	}
 	return nil
 }
@@ -1,9 +1,15 @@
 package dummy
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
 	"github.com/Fantom-foundation/go-lachesis/src/proxy"
 )
 
@@ -18,3 +24,78 @@ func TestProxyHandlerImplementation(t *testing.T) {
 		t.Fatal("State does not implement ProxyHandler interface!")
 	}
 }
+
+func TestPersistentState(t *testing.T) {
+	logger := common.NewTestLogger(t)
+	dir, err := ioutil.TempDir("", "dummy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	state, err := NewPersistentState(dir, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(0); i < 3; i++ {
+		block := poset.NewBlock(i, i+1, []byte("frame"), [][]byte{[]byte(fmt.Sprintf("tx %d", i))})
+		if _, err := state.CommitHandler(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshot, err := state.SnapshotHandler(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := state.GetStateHash()
+	if err := state.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the state and its snapshots survive a restart
+	reloaded, err := NewPersistentState(dir, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloaded.Close()
+	if !bytes.Equal(hash, reloaded.GetStateHash()) || len(reloaded.GetCommittedTransactions()) != 3 {
+		t.Fatalf("reloaded state differs: %d transactions", len(reloaded.GetCommittedTransactions()))
+	}
+	if s, err := reloaded.SnapshotHandler(1); err != nil || !bytes.Equal(s, snapshot) {
+		t.Fatalf("reloaded snapshot differs: %v", err)
+	}
+
+	// restoring rewinds the state, also on disk
+	if _, err := reloaded.RestoreHandler(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.GetCommittedTransactions()) != 2 {
+		t.Fatalf("restored state should have 2 transactions, not %d", len(reloaded.GetCommittedTransactions()))
+	}
+	block := poset.NewBlock(2, 3, []byte("frame"), [][]byte{[]byte("tx 2")})
+	if h, err := reloaded.CommitHandler(block); err != nil || !bytes.Equal(h, hash) {
+		t.Fatalf("replaying block 2 should give the same state hash: %v", err)
+	}
+	reloaded.Close()
+
+	again, err := NewPersistentState(dir, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Close()
+	if !bytes.Equal(hash, again.GetStateHash()) {
+		t.Fatal("state after restore and replay should survive a restart")
+	}
+}
+
+func TestRestoreInvalidSnapshot(t *testing.T) {
+	state := NewState(common.NewTestLogger(t))
+	data, _ := json.Marshal(Snapshot{
+		BlockIndex:   1,
+		StateHash:    []byte("wrong"),
+		Transactions: [][]byte{[]byte("tx")},
+	})
+	if _, err := state.RestoreHandler(data); err == nil {
+		t.Fatal("a snapshot not matching its state hash should be refused")
+	}
+}
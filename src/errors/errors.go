@@ -0,0 +1,158 @@
+// Package errors defines the kinds of errors shared by the store, the poset,
+// the node and the proxies, so that callers and RPC clients can branch on the
+// kind of an error instead of matching its message.
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind classifies an error
+type Kind uint32
+
+const (
+	// Other is the kind of errors that were not classified
+	Other Kind = iota
+	// KeyNotFound means the requested item does not exist
+	KeyNotFound
+	// TooFar means the requested item is beyond what is kept, e.g. an
+	// event that was rolled out of a cache
+	TooFar
+	// UnknownParticipant means a participant is not in the peer set
+	UnknownParticipant
+	// MempoolFull means a transaction was refused for lack of room
+	MempoolFull
+	// StoreCorrupt means stored data could not be decoded
+	StoreCorrupt
+	// ProtocolMismatch means a peer speaks another protocol or network
+	ProtocolMismatch
+	// TxTooLarge means a transaction exceeds the size accepted by the node
+	TxTooLarge
+	// InvalidTx means the application rejected a transaction
+	InvalidTx
+)
+
+var kindNames = map[Kind]string{
+	Other:              "Other",
+	KeyNotFound:        "KeyNotFound",
+	TooFar:             "TooFar",
+	UnknownParticipant: "UnknownParticipant",
+	MempoolFull:        "MempoolFull",
+	StoreCorrupt:       "StoreCorrupt",
+	ProtocolMismatch:   "ProtocolMismatch",
+	TxTooLarge:         "TxTooLarge",
+	InvalidTx:          "InvalidTx",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("Kind(%d)", uint32(k))
+}
+
+// Kinder is implemented by errors that carry a Kind without being an *Error,
+// e.g. common.StoreErr
+type Kinder interface {
+	Kind() Kind
+}
+
+// Error is an error of a given Kind, wrapping an optional cause
+type Error struct {
+	Kind Kind
+	// Op is the operation that failed, e.g. "BadgerStore.GetEvent"
+	Op  string
+	Err error
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	if e.Kind != Other {
+		b.WriteString(e.Kind.String())
+		b.WriteString(": ")
+	}
+	if e.Op != "" {
+		b.WriteString(e.Op)
+		if e.Err != nil {
+			b.WriteString(": ")
+		}
+	}
+	if e.Err != nil {
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the cause of the error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New creates an error of kind k with a formatted message
+func New(k Kind, format string, args ...interface{}) error {
+	return &Error{Kind: k, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap annotates err with a kind and the failed operation. It returns nil
+// when err is nil.
+func Wrap(k Kind, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: k, Op: op, Err: err}
+}
+
+// KindOf returns the kind of the first classified error in the chain of err
+func KindOf(err error) Kind {
+	for err != nil {
+		switch e := err.(type) {
+		case *Error:
+			if e.Kind != Other {
+				return e.Kind
+			}
+		case Kinder:
+			if k := e.Kind(); k != Other {
+				return k
+			}
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return Other
+		}
+		err = u.Unwrap()
+	}
+	return Other
+}
+
+// Is reports whether err is of kind k
+func Is(err error, k Kind) bool {
+	return err != nil && KindOf(err) == k
+}
+
+// Format renders err for the wire, prefixing the message with its kind so
+// that Parse can restore it on the other end
+func Format(err error) string {
+	if err == nil {
+		return ""
+	}
+	msg := err.Error()
+	k := KindOf(err)
+	if k == Other || strings.HasPrefix(msg, k.String()+": ") {
+		return msg
+	}
+	return k.String() + ": " + msg
+}
+
+// Parse turns an error message received from the wire back into an error,
+// restoring its kind when the message is prefixed by one
+func Parse(msg string) error {
+	if i := strings.Index(msg, ": "); i > 0 {
+		for k, name := range kindNames {
+			if k != Other && msg[:i] == name {
+				return &Error{Kind: k, Err: fmt.Errorf("%s", msg[i+2:])}
+			}
+		}
+	}
+	return fmt.Errorf("%s", msg)
+}
@@ -0,0 +1,57 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+)
+
+type kinded struct{ k Kind }
+
+func (e kinded) Error() string { return "kinded" }
+func (e kinded) Kind() Kind    { return e.k }
+
+func TestKindOf(t *testing.T) {
+	err := Wrap(StoreCorrupt, "GetEvent", fmt.Errorf("bad proto"))
+	if !Is(err, StoreCorrupt) {
+		t.Fatalf("expected StoreCorrupt, got %v", KindOf(err))
+	}
+	if err.Error() != "StoreCorrupt: GetEvent: bad proto" {
+		t.Fatalf("unexpected message %q", err)
+	}
+
+	wrapped := fmt.Errorf("SetEvent: %w", err)
+	if !Is(wrapped, StoreCorrupt) {
+		t.Fatal("the kind should survive wrapping")
+	}
+	if !Is(fmt.Errorf("sync: %w", kinded{TooFar}), TooFar) {
+		t.Fatal("Kinder errors should be classified")
+	}
+	if KindOf(fmt.Errorf("plain")) != Other || Is(nil, Other) {
+		t.Fatal("unclassified errors should be Other, nil has no kind")
+	}
+	if Wrap(KeyNotFound, "op", nil) != nil {
+		t.Fatal("wrapping nil should return nil")
+	}
+}
+
+func TestFormatParse(t *testing.T) {
+	for _, err := range []error{
+		New(MempoolFull, "pool full"),
+		fmt.Errorf("peer: %w", kinded{UnknownParticipant}),
+		New(ProtocolMismatch, "network %s", "main"),
+	} {
+		msg := Format(err)
+		parsed := Parse(msg)
+		if KindOf(parsed) != KindOf(err) {
+			t.Fatalf("%q: expected %v, got %v", msg, KindOf(err), KindOf(parsed))
+		}
+		if Format(parsed) != msg {
+			t.Fatalf("round trip changed %q into %q", msg, Format(parsed))
+		}
+	}
+
+	plain := Parse("Unknown: something")
+	if KindOf(plain) != Other || plain.Error() != "Unknown: something" {
+		t.Fatalf("unexpected %v", plain)
+	}
+}
@@ -0,0 +1,156 @@
+package genesis
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+const (
+	jsonGenesisPath = "genesis.json"
+)
+
+// Validator is a member of the initial validator set. Weight is its voting
+// weight; zero counts as one.
+type Validator struct {
+	NetAddr   string
+	PubKeyHex string
+	Weight    uint64
+}
+
+// ConsensusParams are network-wide parameters every node must agree on.
+// Zero values keep the node defaults.
+type ConsensusParams struct {
+	HeartbeatTimeout time.Duration
+	SyncLimit        int64
+}
+
+// Genesis defines a network: its identifier, its initial validator set,
+// its consensus parameters and the initial state of the application.
+type Genesis struct {
+	NetworkID    string
+	Validators   []*Validator
+	Consensus    ConsensusParams
+	AppStateHash string
+}
+
+// Path returns the location of the genesis file in a data directory
+func Path(dataDir string) string {
+	return filepath.Join(dataDir, jsonGenesisPath)
+}
+
+// Exists returns true if path points to a file
+func Exists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// Load reads and validates a genesis file
+func Load(path string) (*Genesis, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var g Genesis
+	if err := json.NewDecoder(bytes.NewReader(buf)).Decode(&g); err != nil {
+		return nil, fmt.Errorf("decoding %s: %s", path, err)
+	}
+	if err := g.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid genesis %s: %s", path, err)
+	}
+
+	return &g, nil
+}
+
+// Save writes the genesis file
+func (g *Genesis) Save(path string) error {
+	buf, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf, 0640)
+}
+
+// Validate checks the consistency of the genesis
+func (g *Genesis) Validate() error {
+	if g.NetworkID == "" {
+		return fmt.Errorf("network ID is missing")
+	}
+	if len(g.Validators) < 2 {
+		return fmt.Errorf("at least two validators are required")
+	}
+	seen := make(map[string]bool)
+	for _, v := range g.Validators {
+		if len(v.PubKeyHex) < 3 {
+			return fmt.Errorf("invalid validator public key %q", v.PubKeyHex)
+		}
+		if _, err := hex.DecodeString(v.PubKeyHex[2:]); err != nil {
+			return fmt.Errorf("invalid validator public key %q: %s", v.PubKeyHex, err)
+		}
+		if seen[v.PubKeyHex] {
+			return fmt.Errorf("duplicate validator %s", v.PubKeyHex)
+		}
+		seen[v.PubKeyHex] = true
+	}
+	if err := peers.ValidateWeights(g.Peers().ToPeerSlice()); err != nil {
+		return err
+	}
+	if g.AppStateHash != "" {
+		if _, err := hex.DecodeString(g.AppStateHash); err != nil {
+			return fmt.Errorf("invalid app state hash: %s", err)
+		}
+	}
+	return nil
+}
+
+// Hash returns the digest of the genesis. The validators are hashed in
+// public key order so that reordering the file does not change the network
+// identity.
+func (g *Genesis) Hash() ([]byte, error) {
+	canonical := *g
+	canonical.Validators = make([]*Validator, len(g.Validators))
+	copy(canonical.Validators, g.Validators)
+	sort.Slice(canonical.Validators, func(i, j int) bool {
+		return canonical.Validators[i].PubKeyHex < canonical.Validators[j].PubKeyHex
+	})
+
+	buf, err := json.Marshal(canonical)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.SHA256(buf), nil
+}
+
+// Identity returns the network identity exchanged by nodes during the
+// transport handshake: the network ID followed by the genesis hash.
+func (g *Genesis) Identity() (string, error) {
+	hash, err := g.Hash()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%X", g.NetworkID, hash), nil
+}
+
+// Peers returns the initial peer set
+func (g *Genesis) Peers() *peers.Peers {
+	list := make([]*peers.Peer, 0, len(g.Validators))
+	for _, v := range g.Validators {
+		peer := peers.NewPeer(v.PubKeyHex, v.NetAddr)
+		peer.Stake = v.Weight
+		list = append(list, peer)
+	}
+	return peers.NewPeersFromSlice(list)
+}
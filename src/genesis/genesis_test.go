@@ -0,0 +1,122 @@
+package genesis
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func testGenesis(t *testing.T, n int) *Genesis {
+	g := &Genesis{
+		NetworkID: "testnet",
+	}
+	for i := 0; i < n; i++ {
+		key, err := crypto.GenerateECDSAKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Validators = append(g.Validators, &Validator{
+			NetAddr:   fmt.Sprintf("127.0.0.1:%d", 1337+i),
+			PubKeyHex: fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey)),
+			Weight:    1,
+		})
+	}
+	return g
+}
+
+func TestGenesisSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lachesis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	g := testGenesis(t, 3)
+	g.Consensus.SyncLimit = 500
+	if err := g.Save(Path(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if !Exists(Path(dir)) {
+		t.Fatal("genesis file should exist")
+	}
+
+	loaded, err := Load(Path(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Peers().Len() != 3 || loaded.Consensus.SyncLimit != 500 {
+		t.Fatalf("unexpected genesis %+v", loaded)
+	}
+
+	h1, _ := g.Hash()
+	h2, _ := loaded.Hash()
+	if !bytes.Equal(h1, h2) {
+		t.Fatal("loaded genesis should have the same hash")
+	}
+}
+
+func TestGenesisIdentity(t *testing.T) {
+	g := testGenesis(t, 3)
+	id1, err := g.Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reordering validators keeps the identity
+	g.Validators[0], g.Validators[2] = g.Validators[2], g.Validators[0]
+	id2, _ := g.Identity()
+	if id1 != id2 {
+		t.Fatal("identity should not depend on validators order")
+	}
+
+	// Changing a parameter changes the identity
+	g.Consensus.SyncLimit = 10
+	id3, _ := g.Identity()
+	if id1 == id3 {
+		t.Fatal("identity should depend on consensus parameters")
+	}
+}
+
+func TestGenesisValidate(t *testing.T) {
+	g := testGenesis(t, 1)
+	if err := g.Validate(); err == nil {
+		t.Fatal("a single validator should be refused")
+	}
+
+	g = testGenesis(t, 2)
+	g.Validators[1].PubKeyHex = g.Validators[0].PubKeyHex
+	if err := g.Validate(); err == nil {
+		t.Fatal("duplicate validators should be refused")
+	}
+
+	g = testGenesis(t, 2)
+	g.NetworkID = ""
+	if err := g.Validate(); err == nil {
+		t.Fatal("missing network ID should be refused")
+	}
+
+	g = testGenesis(t, 2)
+	g.Validators[0].Weight = 1 << 63
+	g.Validators[1].Weight = 1 << 63
+	if err := g.Validate(); err == nil {
+		t.Fatal("a total weight overflowing should be refused")
+	}
+}
+
+func TestGenesisPeersWeight(t *testing.T) {
+	g := testGenesis(t, 2)
+	g.Validators[0].Weight = 5
+	g.Validators[1].Weight = 0
+
+	ps := g.Peers()
+	if w := ps.ByPubKey[g.Validators[0].PubKeyHex].Weight(); w != 5 {
+		t.Fatalf("expected the weight of the genesis, got %d", w)
+	}
+	if w := ps.ByPubKey[g.Validators[1].PubKeyHex].Weight(); w != 1 {
+		t.Fatalf("a zero weight should count as one, got %d", w)
+	}
+}
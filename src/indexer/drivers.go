@@ -0,0 +1,7 @@
+package indexer
+
+// The supported database drivers. sqlite3 requires cgo.
+import (
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
@@ -0,0 +1,242 @@
+// Package indexer copies committed blocks, with their transactions, events
+// and signatures, into an SQL database for block explorers.
+package indexer
+
+import (
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// DefaultSignatureLag is the number of past blocks of which the signatures
+// are refreshed after every commit, as they are collected after the block
+const DefaultSignatureLag = 10
+
+// Source provides the blocks and frames the indexer missed, usually the
+// node itself
+type Source interface {
+	GetBlock(index int64) (poset.Block, error)
+	GetFrame(round int64) (poset.Frame, error)
+}
+
+// Indexer writes committed blocks into a SQL database
+type Indexer struct {
+	db      *sql.DB
+	dialect dialect
+	source  Source
+	logger  *logrus.Entry
+
+	// last is the index of the last indexed block, -1 when none
+	last int64
+	// SignatureLag is the number of past blocks of which the signatures
+	// are refreshed
+	SignatureLag int64
+}
+
+// New opens the database, creates the schema when missing and resumes after
+// the last indexed block
+func New(driver, dsn string, source Source, logger *logrus.Logger) (*Indexer, error) {
+	d, err := dialectOf(driver)
+	if err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = logrus.New()
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if driver == "sqlite3" {
+		// sqlite does not support concurrent writers
+		db.SetMaxOpenConns(1)
+	}
+	for _, stmt := range d.schema() {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("creating the indexer schema: %s", err)
+		}
+	}
+
+	ix := &Indexer{
+		db:           db,
+		dialect:      d,
+		source:       source,
+		logger:       logger.WithField("module", "indexer"),
+		SignatureLag: DefaultSignatureLag,
+	}
+	if err := db.QueryRow("SELECT COALESCE(MAX(block_index), -1) FROM blocks").Scan(&ix.last); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return ix, nil
+}
+
+// Last returns the index of the last indexed block, -1 when none
+func (ix *Indexer) Last() int64 {
+	return atomic.LoadInt64(&ix.last)
+}
+
+// DB returns the database, for queries
+func (ix *Indexer) DB() *sql.DB {
+	return ix.db
+}
+
+// Close closes the database
+func (ix *Indexer) Close() error {
+	return ix.db.Close()
+}
+
+// Run indexes the blocks received on blocks until stop is closed
+func (ix *Indexer) Run(blocks <-chan poset.Block, stop <-chan struct{}) {
+	for {
+		select {
+		case block := <-blocks:
+			if err := ix.Index(block); err != nil {
+				metrics.IncrCounter("indexer.errors", 1)
+				ix.logger.WithFields(logrus.Fields{
+					"block": block.Index(),
+					"error": err,
+				}).Error("Indexing block")
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Index writes block, after the blocks missed since the last indexed one,
+// and refreshes the signatures of the previous blocks
+func (ix *Indexer) Index(block poset.Block) error {
+	if block.Index() <= ix.last {
+		return nil
+	}
+	for i := ix.last + 1; i < block.Index(); i++ {
+		missed, err := ix.source.GetBlock(i)
+		if err != nil {
+			return fmt.Errorf("getting missed block %d: %w", i, err)
+		}
+		if err := ix.indexBlock(missed); err != nil {
+			return err
+		}
+	}
+	if err := ix.indexBlock(block); err != nil {
+		return err
+	}
+
+	from := ix.last - ix.SignatureLag
+	if from < 0 {
+		from = 0
+	}
+	for i := from; i < ix.last; i++ {
+		b, err := ix.source.GetBlock(i)
+		if err != nil {
+			if lerrors.Is(err, lerrors.KeyNotFound) || lerrors.Is(err, lerrors.TooFar) {
+				continue
+			}
+			return err
+		}
+		if err := ix.insertSignatures(ix.db, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func (ix *Indexer) exec(db execer, query string, args ...interface{}) error {
+	_, err := db.Exec(ix.dialect.rebind(query), args...)
+	return err
+}
+
+// indexBlock writes a block in a single database transaction
+func (ix *Indexer) indexBlock(block poset.Block) error {
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := ix.writeBlock(tx, block); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("indexing block %d: %s", block.Index(), err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	atomic.StoreInt64(&ix.last, block.Index())
+	metrics.IncrCounter("indexer.blocks", 1)
+	return nil
+}
+
+func (ix *Indexer) writeBlock(tx *sql.Tx, block poset.Block) error {
+	txs := block.Transactions()
+	if err := ix.exec(tx,
+		`INSERT INTO blocks (block_index, round_received, hash, state_hash, frame_hash, tx_count)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		block.Index(), block.RoundReceived(), block.BlockHex(),
+		fmt.Sprintf("0x%X", block.StateHash), fmt.Sprintf("0x%X", block.FrameHash), len(txs)); err != nil {
+		return err
+	}
+
+	for i, t := range txs {
+		if err := ix.exec(tx,
+			`INSERT INTO transactions (block_index, position, hash, size, data) VALUES (?, ?, ?, ?, ?)`,
+			block.Index(), i, fmt.Sprintf("0x%X", crypto.SHA256(t)), len(t), t); err != nil {
+			return err
+		}
+	}
+
+	frame, err := ix.source.GetFrame(block.RoundReceived())
+	switch {
+	case err == nil:
+		for _, msg := range frame.Events {
+			if err := ix.insertEvent(tx, block.Index(), msg); err != nil {
+				return err
+			}
+		}
+	case lerrors.Is(err, lerrors.KeyNotFound), lerrors.Is(err, lerrors.TooFar):
+		ix.logger.WithField("round", block.RoundReceived()).Debug("Frame not available, events not indexed")
+	default:
+		return err
+	}
+
+	return ix.insertSignatures(tx, block)
+}
+
+func (ix *Indexer) insertEvent(tx *sql.Tx, blockIndex int64, msg *poset.EventMessage) error {
+	if msg == nil || msg.Body == nil {
+		return nil
+	}
+	event := poset.Event{Message: *msg}
+	var selfParent, otherParent string
+	if parents := msg.Body.Parents; len(parents) == 2 {
+		selfParent, otherParent = parents[0], parents[1]
+	}
+	return ix.exec(tx,
+		`INSERT INTO events (hash, block_index, creator, creator_index, self_parent, other_parent, lamport, round, tx_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (hash) DO NOTHING`,
+		event.Hex(), blockIndex, event.Creator(), event.Index(), selfParent, otherParent,
+		msg.LamportTimestamp, msg.Round, len(msg.Body.Transactions))
+}
+
+func (ix *Indexer) insertSignatures(db execer, block poset.Block) error {
+	for validator, sig := range block.Signatures {
+		if err := ix.exec(db,
+			`INSERT INTO signatures (block_index, validator, signature) VALUES (?, ?, ?)
+			ON CONFLICT (block_index, validator) DO NOTHING`,
+			block.Index(), validator, sig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
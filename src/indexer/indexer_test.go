@@ -0,0 +1,121 @@
+package indexer
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	cm "github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+type fakeSource struct {
+	blocks map[int64]poset.Block
+	frames map[int64]poset.Frame
+}
+
+func (s *fakeSource) GetBlock(index int64) (poset.Block, error) {
+	b, ok := s.blocks[index]
+	if !ok {
+		return poset.Block{}, cm.NewStoreErr("Blocks", cm.KeyNotFound, fmt.Sprint(index))
+	}
+	return b, nil
+}
+
+func (s *fakeSource) GetFrame(round int64) (poset.Frame, error) {
+	f, ok := s.frames[round]
+	if !ok {
+		return poset.Frame{}, cm.NewStoreErr("Frames", cm.KeyNotFound, fmt.Sprint(round))
+	}
+	return f, nil
+}
+
+func newSource(n int64) *fakeSource {
+	s := &fakeSource{
+		blocks: make(map[int64]poset.Block),
+		frames: make(map[int64]poset.Frame),
+	}
+	for i := int64(0); i < n; i++ {
+		txs := [][]byte{[]byte(fmt.Sprintf("tx%d.a", i)), []byte(fmt.Sprintf("tx%d.b", i))}
+		s.blocks[i] = poset.NewBlock(i, i+1, []byte("frame"), txs)
+		event := poset.NewEvent(txs, nil, nil, []string{"a", "b"}, []byte{byte(i)}, i, nil)
+		s.frames[i+1] = poset.Frame{Round: i + 1, Events: []*poset.EventMessage{&event.Message}}
+	}
+	return s
+}
+
+func count(t *testing.T, ix *Indexer, table string) int {
+	var n int
+	if err := ix.DB().QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestIndexer(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "explorer.db")
+	source := newSource(5)
+
+	ix, err := New("sqlite3", dsn, source, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ix.Last() != -1 {
+		t.Fatalf("empty index should start at -1, got %d", ix.Last())
+	}
+
+	if err := ix.Index(source.blocks[0]); err != nil {
+		t.Fatal(err)
+	}
+	// blocks 1 and 2 were missed and are fetched from the source
+	if err := ix.Index(source.blocks[3]); err != nil {
+		t.Fatal(err)
+	}
+	if ix.Last() != 3 {
+		t.Fatalf("expected last block 3, got %d", ix.Last())
+	}
+	if n := count(t, ix, "blocks"); n != 4 {
+		t.Fatalf("expected 4 blocks, got %d", n)
+	}
+	if n := count(t, ix, "transactions"); n != 8 {
+		t.Fatalf("expected 8 transactions, got %d", n)
+	}
+	if n := count(t, ix, "events"); n != 4 {
+		t.Fatalf("expected 4 events, got %d", n)
+	}
+
+	// signatures collected after the commit are picked up later
+	b := source.blocks[1]
+	b.Signatures = map[string]string{"0xVALIDATOR": "sig"}
+	source.blocks[1] = b
+	if err := ix.Index(source.blocks[4]); err != nil {
+		t.Fatal(err)
+	}
+	var validator string
+	if err := ix.DB().QueryRow("SELECT validator FROM signatures WHERE block_index = 1").Scan(&validator); err != nil {
+		t.Fatal(err)
+	}
+	ix.Close()
+
+	ix, err = New("sqlite3", dsn, source, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ix.Close()
+	if ix.Last() != 4 {
+		t.Fatalf("the indexer should resume after block 4, got %d", ix.Last())
+	}
+	if err := ix.Index(source.blocks[2]); err != nil || count(t, ix, "blocks") != 5 {
+		t.Fatalf("already indexed blocks should be ignored: %v", err)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	d, _ := dialectOf("postgres")
+	if q := d.rebind("VALUES (?, ?)"); q != "VALUES ($1, $2)" {
+		t.Fatalf("unexpected %q", q)
+	}
+	if _, err := dialectOf("mysql"); err == nil {
+		t.Fatal("mysql is not supported")
+	}
+}
@@ -0,0 +1,93 @@
+package indexer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// schema is the explorer schema, see docs/indexer.rst. {{blob}} is replaced
+// by the binary type of the dialect.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS blocks (
+		block_index    BIGINT PRIMARY KEY,
+		round_received BIGINT NOT NULL,
+		hash           TEXT NOT NULL,
+		state_hash     TEXT NOT NULL,
+		frame_hash     TEXT NOT NULL,
+		tx_count       INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS transactions (
+		block_index BIGINT NOT NULL,
+		position    INTEGER NOT NULL,
+		hash        TEXT NOT NULL,
+		size        INTEGER NOT NULL,
+		data        {{blob}} NOT NULL,
+		PRIMARY KEY (block_index, position)
+	)`,
+	`CREATE INDEX IF NOT EXISTS transactions_hash ON transactions (hash)`,
+	`CREATE TABLE IF NOT EXISTS events (
+		hash            TEXT PRIMARY KEY,
+		block_index     BIGINT NOT NULL,
+		creator         TEXT NOT NULL,
+		creator_index   BIGINT NOT NULL,
+		self_parent     TEXT NOT NULL,
+		other_parent    TEXT NOT NULL,
+		lamport         BIGINT NOT NULL,
+		round           BIGINT NOT NULL,
+		tx_count        INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS events_creator ON events (creator, creator_index)`,
+	`CREATE INDEX IF NOT EXISTS events_block ON events (block_index)`,
+	`CREATE TABLE IF NOT EXISTS signatures (
+		block_index BIGINT NOT NULL,
+		validator   TEXT NOT NULL,
+		signature   TEXT NOT NULL,
+		PRIMARY KEY (block_index, validator)
+	)`,
+}
+
+// dialect adapts the statements to a database
+type dialect struct {
+	blob string
+	// numbered placeholders ($1, $2...) instead of ?
+	numbered bool
+}
+
+var dialects = map[string]dialect{
+	"postgres": {blob: "BYTEA", numbered: true},
+	"sqlite3":  {blob: "BLOB"},
+}
+
+func dialectOf(driver string) (dialect, error) {
+	d, ok := dialects[driver]
+	if !ok {
+		return dialect{}, fmt.Errorf("unsupported indexer driver %q, expected postgres or sqlite3", driver)
+	}
+	return d, nil
+}
+
+func (d dialect) schema() []string {
+	res := make([]string, len(schema))
+	for i, stmt := range schema {
+		res[i] = strings.Replace(stmt, "{{blob}}", d.blob, -1)
+	}
+	return res
+}
+
+// rebind replaces the ? placeholders of query by the ones of the dialect
+func (d dialect) rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,136 @@
+package lachesis
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+	"github.com/Fantom-foundation/go-lachesis/src/node"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy"
+)
+
+// ChainConfig describes an additional poset hosted by the node. It has its
+// own store, peer set and proxy, and shares the key, the transport and the
+// service listener of the main poset.
+type ChainConfig struct {
+	ID string `mapstructure:"id"`
+	// DataDir holds the peers.json of the chain and its database. It
+	// defaults to <datadir>/chains/<id>.
+	DataDir   string `mapstructure:"datadir"`
+	Store     bool   `mapstructure:"store"`
+	ProxyAddr string `mapstructure:"proxy-listen"`
+
+	Proxy proxy.AppProxy `mapstructure:"-"`
+}
+
+// Chain is a poset hosted next to the main one
+type Chain struct {
+	Config *ChainConfig
+	Node   *node.Node
+	Store  poset.Store
+	Peers  *peers.Peers
+}
+
+func (c *LachesisConfig) chainDir(chain *ChainConfig) string {
+	if chain.DataDir != "" {
+		return chain.DataDir
+	}
+	return filepath.Join(c.DataDir, "chains", chain.ID)
+}
+
+func (c *LachesisConfig) validateChains() []string {
+	var errs []string
+	seen := make(map[string]bool)
+	for i, chain := range c.Chains {
+		switch {
+		case chain.ID == "":
+			errs = append(errs, fmt.Sprintf("chains[%d] needs an id", i))
+		case seen[chain.ID]:
+			errs = append(errs, fmt.Sprintf("chain %q is defined twice", chain.ID))
+		}
+		seen[chain.ID] = true
+		if !c.Standalone && chain.Proxy == nil {
+			if err := validateAddr(fmt.Sprintf("chain %q proxy-listen", chain.ID), chain.ProxyAddr); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	return errs
+}
+
+// nodeTransport returns the transport of the main poset
+func (l *Lachesis) nodeTransport() net.Transport {
+	if l.mux != nil {
+		return l.mux.Chain("")
+	}
+	return l.Transport
+}
+
+// initChains creates the nodes of the additional chains
+func (l *Lachesis) initChains() error {
+	for i := range l.Config.Chains {
+		conf := &l.Config.Chains[i]
+		dir := l.Config.chainDir(conf)
+		logger := l.Config.Logger.WithField("chain", conf.ID)
+
+		participants, err := peers.NewJSONPeers(dir).Peers()
+		if err != nil {
+			return fmt.Errorf("chain %s: %s", conf.ID, err)
+		}
+		if participants.Len() < 2 {
+			return fmt.Errorf("chain %s: peers.json should define at least two peers", conf.ID)
+		}
+
+		var store poset.Store
+		if conf.Store {
+			store, err = l.openStore(participants, dir)
+			if err != nil {
+				return fmt.Errorf("chain %s: %s", conf.ID, err)
+			}
+		} else {
+			store = poset.NewInmemStore(participants, l.Config.NodeConfig.CacheSize)
+		}
+
+		nodePub := fmt.Sprintf("0x%X", crypto.FromECDSAPub(&l.Config.Key.PublicKey))
+		self, ok := participants.PeerByPubKey(nodePub)
+		if !ok {
+			return fmt.Errorf("chain %s: cannot find self pubkey in peers.json", conf.ID)
+		}
+
+		nodeConf := l.Config.NodeConfig
+		// the events of a chain are not valid on the main poset
+		nodeConf.NetworkID = l.Config.NodeConfig.NetworkID + "/" + conf.ID
+		// the pools of a chain are journaled in its own directory, and the
+		// seeds are nodes of the main poset
+		if nodeConf.PoolJournal != "" {
+			nodeConf.PoolJournal = filepath.Join(dir, filepath.Base(nodeConf.PoolJournal))
+		}
+		nodeConf.Seeds = nil
+		n := node.NewNode(&nodeConf, self.ID, l.Config.Key, participants, store, l.mux.Chain(conf.ID), conf.Proxy)
+		if err := n.Init(); err != nil {
+			return fmt.Errorf("chain %s: failed to initialize node: %s", conf.ID, err)
+		}
+
+		logger.WithFields(logrus.Fields{
+			"id":    self.ID,
+			"peers": participants.Len(),
+			"dir":   dir,
+		}).Info("Hosting chain")
+
+		l.Chains = append(l.Chains, &Chain{
+			Config: conf,
+			Node:   n,
+			Store:  store,
+			Peers:  participants,
+		})
+		if l.Service != nil {
+			l.Service.AddChain(conf.ID, n)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package lachesis
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy"
+)
+
+// embeddedBlocks is the number of committed blocks buffered for Blocks
+const embeddedBlocks = 100
+
+// Embedded is a Lachesis node run in-process by an application, which
+// receives the committed blocks through the handler of an InmemAppProxy
+type Embedded struct {
+	*Lachesis
+	// Proxy is the in-memory proxy between the node and the handler
+	Proxy *proxy.InmemAppProxy
+
+	blocks chan poset.Block
+	done   chan struct{}
+}
+
+// NewEmbedded initializes a node whose blocks are committed to handler. A nil
+// config is the default one without HTTP service. The node signs with key, or
+// the key of the data directory when nil, and its peers are participants, or
+// the ones of the data directory when nil.
+func NewEmbedded(
+	config *LachesisConfig,
+	key *ecdsa.PrivateKey,
+	participants *peers.Peers,
+	handler proxy.ProxyHandler,
+) (*Embedded, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("embedding requires a proxy handler")
+	}
+	if config == nil {
+		config = NewDefaultConfig()
+		config.ServiceAddr = ""
+	}
+	if key != nil {
+		config.Key = key
+	}
+
+	e := &Embedded{
+		Lachesis: NewLachesis(config),
+		Proxy:    proxy.NewInmemAppProxy(handler, config.Logger),
+		blocks:   make(chan poset.Block, embeddedBlocks),
+		done:     make(chan struct{}),
+	}
+	config.Proxy = e.Proxy
+	if participants != nil {
+		config.LoadPeers = false
+		e.Peers = participants
+	}
+
+	if err := e.Init(); err != nil {
+		return nil, err
+	}
+	e.Node.SubscribeCommits(e.blocks)
+	return e, nil
+}
+
+// Start runs the node in the background until Stop
+func (e *Embedded) Start() {
+	go func() {
+		defer close(e.done)
+		e.Run()
+	}()
+}
+
+// Stop shuts the node down and waits for it to stop, returning the error of
+// Shutdown
+func (e *Embedded) Stop() error {
+	err := e.Shutdown()
+	<-e.done
+	return err
+}
+
+// Blocks returns the blocks committed since the node started. Blocks are
+// dropped when it is not drained, see node.SubscribeCommits.
+func (e *Embedded) Blocks() <-chan poset.Block {
+	return e.blocks
+}
+
+// SubmitTx adds a transaction to the pool of the node, returning its error
+// when the node refuses it
+func (e *Embedded) SubmitTx(tx []byte) error {
+	select {
+	case <-e.done:
+		return fmt.Errorf("the node is stopped")
+	default:
+	}
+	return e.Proxy.TrySubmitTx(tx)
+}
+
+// Stats returns the statistics of the node, those of the /stats endpoint
+func (e *Embedded) Stats() map[string]string {
+	return e.Node.GetStats()
+}
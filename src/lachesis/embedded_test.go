@@ -0,0 +1,88 @@
+package lachesis
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/dummy"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+// freeAddr returns a loopback address nothing listens on
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestEmbedded(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lachesis-embedded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var keys []*ecdsa.PrivateKey
+	var addrs []string
+	var list []*peers.Peer
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateECDSAKey()
+		keys = append(keys, key)
+		addrs = append(addrs, freeAddr(t))
+		list = append(list, peers.NewPeer(fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey)), addrs[i]))
+	}
+
+	var nodes []*Embedded
+	for i, key := range keys {
+		conf := NewDefaultConfig()
+		conf.DataDir = dir
+		conf.BindAddr = addrs[i]
+		conf.ServiceAddr = ""
+		conf.Logger = common.NewTestLogger(t)
+		conf.NodeConfig.Logger = conf.Logger
+		conf.NodeConfig.HeartbeatTimeout = 10 * time.Millisecond
+		e, err := NewEmbedded(conf, key, peers.NewPeersFromSlice(list), dummy.NewState(conf.Logger))
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Start()
+		defer e.Stop()
+		nodes = append(nodes, e)
+	}
+
+	if err := nodes[0].SubmitTx([]byte("embedded")); err != nil {
+		t.Fatal(err)
+	}
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case block := <-nodes[1].Blocks():
+			for _, tx := range block.Transactions() {
+				if string(tx) == "embedded" {
+					if stats := nodes[1].Stats(); stats["last_block_index"] == "-1" {
+						t.Fatalf("the stats should count the committed block, got %v", stats)
+					}
+					if err := nodes[2].Stop(); err != nil {
+						t.Fatal(err)
+					}
+					if err := nodes[2].SubmitTx([]byte("late")); err == nil {
+						t.Fatal("a stopped node should refuse transactions")
+					}
+					return
+				}
+			}
+		case <-timeout:
+			t.Fatal("the transaction was not committed")
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package lachesis
+
+import (
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/genesis"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy"
+)
+
+func TestCheckAppState(t *testing.T) {
+	conf := NewDefaultConfig()
+	conf.Logger = common.NewTestLogger(t)
+	handler := &proxy.DefaultHandler{}
+	if _, err := handler.RestoreHandler([]byte{0x01, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+	conf.Proxy = proxy.NewInmemAppProxy(handler, conf.Logger)
+
+	l := NewLachesis(conf)
+	l.Store = poset.NewInmemStore(peers.NewPeers(), conf.NodeConfig.CacheSize)
+	l.Genesis = &genesis.Genesis{AppStateHash: "0102"}
+	if err := l.checkAppState(); err != nil {
+		t.Fatal(err)
+	}
+
+	l.Genesis.AppStateHash = "0103"
+	if err := l.checkAppState(); err == nil {
+		t.Fatal("an app state hash different from the genesis should be refused")
+	}
+
+	l.Genesis.AppStateHash = ""
+	if err := l.checkAppState(); err != nil {
+		t.Fatal("a genesis without an app state hash should not be checked")
+	}
+}
@@ -0,0 +1,50 @@
+package lachesis
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func TestInitKeyFromKeystore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lachesis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keystore := crypto.NewKeystore(dir)
+	keystore.ScryptN = crypto.LightScryptN
+	key, err := crypto.GenerateECDSAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keystore.Store(key, "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := NewDefaultConfig()
+	conf.DataDir = dir
+	conf.Logger = common.NewTestLogger(t)
+	if err := NewLachesis(conf).initKey(); err == nil {
+		t.Fatal("the encrypted key should need a passphrase")
+	}
+
+	conf.PasswordFile = filepath.Join(dir, "password")
+	if err := ioutil.WriteFile(conf.PasswordFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewLachesis(conf).initKey(); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Key == nil || conf.Key.D.Cmp(key.D) != 0 {
+		t.Fatal("the node should use the key of the keystore")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "priv_key.pem")); err == nil {
+		t.Fatal("no unencrypted key should be generated")
+	}
+}
@@ -1,26 +1,60 @@
 package lachesis
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
 
+	"github.com/Fantom-foundation/go-lachesis/src/archive"
+	"github.com/Fantom-foundation/go-lachesis/src/chaos"
 	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/daemon"
+	"github.com/Fantom-foundation/go-lachesis/src/genesis"
+	"github.com/Fantom-foundation/go-lachesis/src/indexer"
 	"github.com/Fantom-foundation/go-lachesis/src/log"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
 	"github.com/Fantom-foundation/go-lachesis/src/net"
 	"github.com/Fantom-foundation/go-lachesis/src/node"
 	"github.com/Fantom-foundation/go-lachesis/src/peers"
 	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/profile"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy"
 	"github.com/Fantom-foundation/go-lachesis/src/service"
 	"github.com/sirupsen/logrus"
 )
 
 type Lachesis struct {
 	Config    *LachesisConfig
+	Genesis   *genesis.Genesis
 	Node      *node.Node
 	Transport net.Transport
 	Store     poset.Store
 	Peers     *peers.Peers
 	Service   *service.Service
+	Indexer   *indexer.Indexer
+	Archiver  *archive.Archiver
+	Chains    []*Chain
+	// PeerSetHash is the hash of a signed peers.json, empty when the file
+	// is not signed
+	PeerSetHash string
+
+	// mux shares the transport between the main poset and the chains
+	mux *net.Mux
+	// portMapping forwards a port of the NAT gateway to the transport
+	portMapping *net.PortMapping
+	// metricsServer serves the metrics on the metrics-addr listener
+	metricsServer *http.Server
+
+	shutdownOnce sync.Once
+	// shutdownErr is the first error of the flushes of Shutdown
+	shutdownErr error
 }
 
 func NewLachesis(config *LachesisConfig) *Lachesis {
@@ -32,23 +66,148 @@ func NewLachesis(config *LachesisConfig) *Lachesis {
 }
 
 func (l *Lachesis) initTransport() error {
-	transport, err := net.NewTCPTransport(
-		l.Config.BindAddr,
-		nil,
-		l.Config.MaxPool,
-		l.Config.NodeConfig.TCPTimeout,
-		l.Config.Logger,
-	)
+	var transport *net.NetworkTransport
+	var err error
+	if l.Config.Transport == TransportQUIC {
+		var config *tls.Config
+		config, err = l.tlsConfig()
+		if err != nil {
+			return err
+		}
+		transport, err = net.NewQUICTransport(
+			l.Config.BindAddr,
+			nil,
+			l.Config.MaxPool,
+			l.Config.NodeConfig.TCPTimeout,
+			config,
+			l.Config.Logger,
+		)
+	} else if l.Config.TLS {
+		var config *tls.Config
+		config, err = l.tlsConfig()
+		if err != nil {
+			return err
+		}
+		transport, err = net.NewTLSTransport(
+			l.Config.BindAddr,
+			nil,
+			l.Config.MaxPool,
+			l.Config.NodeConfig.TCPTimeout,
+			config,
+			l.Config.Logger,
+		)
+	} else {
+		transport, err = net.NewTCPTransport(
+			l.Config.BindAddr,
+			nil,
+			l.Config.MaxPool,
+			l.Config.NodeConfig.TCPTimeout,
+			l.Config.Logger,
+		)
+	}
 
 	if err != nil {
 		return err
 	}
+	if err := transport.SetWireCompression(l.Config.WireCompression); err != nil {
+		transport.Close()
+		return err
+	}
+	if err := transport.SetWireVersion(l.Config.WireVersion); err != nil {
+		transport.Close()
+		return err
+	}
+
+	networkID := l.Config.NodeConfig.NetworkID
+	if l.Genesis != nil {
+		identity, err := l.Genesis.Identity()
+		if err != nil {
+			return err
+		}
+		transport.SetNetworkID(identity)
+	} else if l.PeerSetHash != "" {
+		// only the nodes of the same signed peer set connect
+		if networkID != "" {
+			transport.SetNetworkID(networkID + "/peers/" + l.PeerSetHash)
+		} else {
+			transport.SetNetworkID("peers/" + l.PeerSetHash)
+		}
+	} else if networkID != "" {
+		transport.SetNetworkID(networkID)
+	}
+
+	if err := l.mapPort(transport); err != nil {
+		transport.Close()
+		return err
+	}
 
 	l.Transport = transport
+	if len(l.Config.Chains) > 0 {
+		l.mux = net.NewMux(transport)
+	}
 
 	return nil
 }
 
+// mapPort asks the NAT gateway, see the nat setting, to forward a port to
+// the transport, and advertises its external address unless one is set
+func (l *Lachesis) mapPort(transport *net.NetworkTransport) error {
+	nat, err := net.DiscoverNAT(l.Config.NAT)
+	if err != nil {
+		return fmt.Errorf("nat %s: %s", l.Config.NAT, err)
+	}
+	if nat == nil {
+		return nil
+	}
+	protocol := "TCP"
+	if l.Config.Transport == TransportQUIC {
+		protocol = "UDP"
+	}
+	mapping, err := net.MapPort(nat, protocol, transport.LocalAddr(), l.Config.Logger.WithField(lachesis_log.ModuleField, "net"))
+	if err != nil {
+		return err
+	}
+	l.portMapping = mapping
+	if l.Config.NodeConfig.AdvertiseAddr == "" {
+		l.Config.NodeConfig.AdvertiseAddr = mapping.ExternalAddr()
+	}
+	return nil
+}
+
+// tlsConfig loads the certificates of the TLS transport. Without a
+// certificate, the node presents a self-signed certificate of its key and
+// accepts the ones of the keys of its peers.
+func (l *Lachesis) tlsConfig() (*tls.Config, error) {
+	isPeer := func(pubKey string) bool {
+		for _, p := range l.Peers.ToPeerSlice() {
+			if p.PubKeyHex == pubKey {
+				return true
+			}
+		}
+		return false
+	}
+
+	if l.Config.TLSCert == "" {
+		cert, err := net.NodeCertificate(l.Config.Key)
+		if err != nil {
+			return nil, fmt.Errorf("creating the node certificate: %s", err)
+		}
+		return net.NewTLSConfig(cert, nil, isPeer), nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(l.Config.TLSCert, l.Config.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("loading tls-cert and tls-key: %s", err)
+	}
+	var ca *x509.CertPool
+	if l.Config.TLSCA != "" {
+		if ca, err = net.LoadCertPool(l.Config.TLSCA); err != nil {
+			return nil, fmt.Errorf("loading tls-ca: %s", err)
+		}
+	}
+	return net.NewTLSConfig(cert, ca, isPeer), nil
+}
+
 func (l *Lachesis) initPeers() error {
 	if !l.Config.LoadPeers {
 		if l.Peers == nil {
@@ -58,6 +217,14 @@ func (l *Lachesis) initPeers() error {
 		return nil
 	}
 
+	genesisPath := l.Config.Genesis
+	if genesisPath == "" {
+		genesisPath = genesis.Path(l.Config.DataDir)
+	}
+	if genesis.Exists(genesisPath) {
+		return l.initGenesis(genesisPath)
+	}
+
 	peerStore := peers.NewJSONPeers(l.Config.DataDir)
 
 	participants, err := peerStore.Peers()
@@ -72,38 +239,175 @@ func (l *Lachesis) initPeers() error {
 
 	l.Peers = participants
 
+	// A signed peer set identifies the network and is never rewritten
+	if signed := peerStore.Signed(); signed != nil {
+		l.PeerSetHash = signed.Hash
+		l.Config.Logger.WithFields(logrus.Fields{
+			"hash":       signed.Hash,
+			"signatures": len(signed.Signatures),
+		}).Info("Loaded signed peer set")
+		return nil
+	}
+
+	// Persist membership changes, e.g. peers learned through peer exchange
+	persist := func(*peers.Peer) error {
+		return peerStore.SetPeers(l.Peers.ToPeerSlice())
+	}
+	l.Peers.OnPeerAdded(peers.PriorityService, persist)
+	l.Peers.OnPeerRemoved(peers.PriorityService, persist)
+	l.Peers.OnPeerUpdated(peers.PriorityService, persist)
+
 	return nil
 }
 
-func (l *Lachesis) initStore() error {
-	var dbDir = fmt.Sprintf("%s/badger", l.Config.DataDir)
+// initGenesis loads the initial validator set and the consensus parameters
+// from a genesis file, which takes precedence over peers.json.
+func (l *Lachesis) initGenesis(path string) error {
+	g, err := genesis.Load(path)
+	if err != nil {
+		return err
+	}
+
+	if g.Consensus.HeartbeatTimeout > 0 {
+		l.Config.NodeConfig.HeartbeatTimeout = g.Consensus.HeartbeatTimeout
+	}
+	if g.Consensus.SyncLimit > 0 {
+		l.Config.NodeConfig.SyncLimit = g.Consensus.SyncLimit
+	}
+
+	// the genesis names the network
+	switch l.Config.NodeConfig.NetworkID {
+	case "":
+		l.Config.NodeConfig.NetworkID = g.NetworkID
+	case g.NetworkID:
+	default:
+		return fmt.Errorf("network-id %q does not match the genesis network %q", l.Config.NodeConfig.NetworkID, g.NetworkID)
+	}
 
+	l.Genesis = g
+	l.Peers = g.Peers()
+
+	l.Config.Logger.WithFields(logrus.Fields{
+		"path":       path,
+		"network_id": g.NetworkID,
+		"validators": len(g.Validators),
+	}).Info("Loaded genesis")
+
+	return nil
+}
+
+// checkAppState refuses to start a node without blocks whose application is
+// not in the initial state of the genesis
+func (l *Lachesis) checkAppState() error {
+	if l.Genesis == nil || l.Genesis.AppStateHash == "" || l.Store.LastBlockIndex() >= 0 {
+		return nil
+	}
+
+	want, _ := hex.DecodeString(l.Genesis.AppStateHash)
+	app, ok := l.Config.Proxy.(proxy.StateAppProxy)
+	if !ok {
+		return fmt.Errorf("the genesis sets an app state hash, which the application cannot report")
+	}
+	got, err := app.StateHash()
+	if err != nil {
+		return fmt.Errorf("reading the app state hash: %s", err)
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("the app state hash %X does not match the genesis app state hash %X", got, want)
+	}
+	return nil
+}
+
+func (l *Lachesis) initStore() error {
 	if !l.Config.Store {
 		l.Store = poset.NewInmemStore(l.Peers, l.Config.NodeConfig.CacheSize)
 
 		l.Config.Logger.Debug("created new in-mem store")
+		return nil
+	}
+
+	store, err := l.openStore(l.Peers, l.Config.DataDir)
+	if err != nil {
+		return err
+	}
+	l.Store = store
+	if l.Store.NeedBoostrap() {
+		l.Config.Logger.Debug("loaded store from existing database at ", store.StorePath())
 	} else {
-		var err error
+		l.Config.Logger.Debug("created new store from fresh database at ", store.StorePath())
+	}
+	return nil
+}
 
-		l.Config.Logger.WithField("path", l.Config.BadgerDir()).Debug("Attempting to load or create database")
-		l.Store, err = poset.LoadOrCreateBadgerStore(l.Peers, l.Config.NodeConfig.CacheSize, dbDir)
+// openStore loads or creates the database of StoreType under dir. The
+// hybrid store uses the badger database.
+func (l *Lachesis) openStore(participants *peers.Peers, dir string) (poset.Store, error) {
+	compression, _ := poset.ParseCompression(l.Config.StoreCompression)
+	dbDir := filepath.Join(dir, l.Config.StoreType)
+	if l.Config.StoreType == StoreHybrid {
+		dbDir = filepath.Join(dir, StoreBadger)
+	}
+	l.Config.Logger.WithFields(logrus.Fields{
+		"type": l.Config.StoreType,
+		"path": dbDir,
+	}).Debug("Attempting to load or create database")
 
+	switch l.Config.StoreType {
+	case StoreLevelDB:
+		store, err := poset.LoadOrCreateLevelDBStore(participants, l.Config.NodeConfig.CacheSize, dbDir)
 		if err != nil {
-			return err
+			return nil, err
 		}
-
-		if l.Store.NeedBoostrap() {
-			l.Config.Logger.Debug("loaded badger store from existing database at ", dbDir)
-		} else {
-			l.Config.Logger.Debug("created new badger store from fresh database")
+		store.SetCompression(compression)
+		return store, nil
+	case StoreRocksDB:
+		return LoadOrCreateRocksDBStore(participants, l.Config.NodeConfig.CacheSize, dbDir, compression)
+	case StoreHybrid:
+		store, err := poset.LoadOrCreateHybridStore(participants, l.Config.NodeConfig.CacheSize, dbDir)
+		if err != nil {
+			return nil, err
+		}
+		store.SetCompression(compression)
+		store.StartGC(l.Config.StoreGCInterval, l.Config.StoreGCDiscardRatio, l.Config.Logger.WithField("store", dbDir))
+		return store, nil
+	default:
+		store, err := poset.LoadOrCreateBadgerStore(participants, l.Config.NodeConfig.CacheSize, dbDir)
+		if err != nil {
+			return nil, err
 		}
+		store.SetCompression(compression)
+		store.StartGC(l.Config.StoreGCInterval, l.Config.StoreGCDiscardRatio, l.Config.Logger.WithField("store", dbDir))
+		return store, nil
 	}
+}
 
+// checkKeyTypes checks the keys of the peers are of the key type of the
+// network
+func (l *Lachesis) checkKeyTypes(keyType crypto.KeyType) error {
+	for _, p := range l.Peers.ToPeerSlice() {
+		if err := p.CheckKeyType(keyType); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (l *Lachesis) initKey() error {
 	if l.Config.Key == nil {
+		keystore := crypto.NewKeystore(l.Config.DataDir)
+		keys, err := keystore.List()
+		if err != nil {
+			return fmt.Errorf("reading the keystore: %s", err)
+		}
+		if len(keys) > 0 {
+			key, err := l.loadKeystoreKey(keystore)
+			if err != nil {
+				return err
+			}
+			l.Config.Key = key
+			return nil
+		}
+
 		pemKey := crypto.NewPemKey(l.Config.DataDir)
 
 		privKey, err := pemKey.ReadKey()
@@ -130,11 +434,32 @@ func (l *Lachesis) initKey() error {
 	return nil
 }
 
+// loadKeystoreKey decrypts the key of the node in the keystore with the
+// passphrase of the configuration
+func (l *Lachesis) loadKeystoreKey(keystore *crypto.Keystore) (*ecdsa.PrivateKey, error) {
+	passphrase := l.Config.Passphrase
+	if passphrase == "" {
+		if l.Config.PasswordFile == "" {
+			return nil, fmt.Errorf("the key of the node is encrypted in the keystore, see password-file")
+		}
+		var err error
+		if passphrase, err = crypto.ReadPassphraseFile(l.Config.PasswordFile); err != nil {
+			return nil, fmt.Errorf("reading password-file: %s", err)
+		}
+	}
+	key, err := keystore.Load(l.Config.KeystoreKey, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	l.Config.Logger.WithField("pub", fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey))).Info("Loaded the key from the keystore")
+	return key, nil
+}
+
 func (l *Lachesis) initNode() error {
 	key := l.Config.Key
 
 	nodePub := fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey))
-	n, ok := l.Peers.ByPubKey[nodePub]
+	n, ok := l.Peers.PeerByPubKey(nodePub)
 
 	if !ok {
 		return fmt.Errorf("cannot find self pubkey in peers.json")
@@ -142,6 +467,12 @@ func (l *Lachesis) initNode() error {
 
 	nodeID := n.ID
 
+	// the peers count the events of a validator, and ignore those of an
+	// ephemeral peer: both must agree on the role of the node
+	if l.Config.NodeConfig.Observer != n.IsEphemeral() {
+		return fmt.Errorf("observer is %t but this node has tier %q in the peer set", l.Config.NodeConfig.Observer, n.TierOrDefault())
+	}
+
 	l.Config.Logger.WithFields(logrus.Fields{
 		"participants": l.Peers,
 		"id":           nodeID,
@@ -153,10 +484,56 @@ func (l *Lachesis) initNode() error {
 		key,
 		l.Peers,
 		l.Store,
-		l.Transport,
+		l.nodeTransport(),
 		l.Config.Proxy,
 	)
 
+	if nt, ok := l.Transport.(*net.NetworkTransport); ok {
+		nt.SetBanList(l.Node.BanList())
+		nt.SetRateLimit(l.Config.RPCRate, l.Config.RPCBurst)
+		nt.SetDialBackoff(l.Config.DialBackoff, l.Config.DialBackoffMax, l.Config.DialBanAfter, l.Config.NodeConfig.BanDuration)
+		l.Node.SetConnections(nt)
+
+		// Tell the peers where we are reachable, and learn where they are
+		nt.SetAdvertiseAddr(l.Config.NodeConfig.AdvertiseAddr)
+		nt.OnAdvertisedAddr(l.Node.OfferAdvertisedAddr)
+
+		// Prove our key on every connection and check the keys of the peers
+		nt.SetIdentity(key, func(addr string) string {
+			if p, ok := l.Peers.PeerByAddr(addr); ok {
+				return p.PubKeyHex
+			}
+			return ""
+		})
+		nt.SetKnownPeers(func(pubKey string) bool {
+			_, ok := l.Peers.PeerByPubKey(pubKey)
+			return ok
+		})
+
+		// Drop pooled connections to addresses no peer uses anymore
+		prune := func(*peers.Peer) error {
+			known := make(map[string]bool)
+			for _, p := range l.Peers.Snapshot().ToPeerSlice() {
+				known[p.NetAddr] = true
+			}
+			nt.PrunePool(known)
+			return nil
+		}
+		l.Peers.OnPeerRemoved(peers.PriorityTransport, prune)
+		l.Peers.OnPeerUpdated(peers.PriorityTransport, prune)
+
+		// Only accept connections once the transport is configured
+		nt.Listen()
+	}
+
+	if l.portMapping != nil {
+		l.Node.OnShutdown(func() {
+			if err := l.portMapping.Close(); err != nil {
+				l.Config.Logger.WithError(err).Warn("Deleting port mapping")
+			}
+		})
+	}
+
 	if err := l.Node.Init(); err != nil {
 		return fmt.Errorf("failed to initialize node: %s", err)
 	}
@@ -167,6 +544,86 @@ func (l *Lachesis) initNode() error {
 func (l *Lachesis) initService() error {
 	if l.Config.ServiceAddr != "" {
 		l.Service = service.NewService(l.Config.ServiceAddr, l.Node, l.Config.Logger)
+		l.Service.SetPeerSetHash(l.PeerSetHash)
+		l.Service.SetCORSOrigins(l.Config.ServiceCORSOrigins)
+		l.Service.SetAdminAddress(l.Config.ServiceAdminAddr)
+		if l.Config.ServiceAuth != service.AuthNone {
+			secret, err := crypto.ReadPassphraseFile(l.Config.ServiceSecretFile)
+			if err != nil {
+				return fmt.Errorf("reading service secret: %s", err)
+			}
+			if secret == "" {
+				return fmt.Errorf("service secret file %s is empty", l.Config.ServiceSecretFile)
+			}
+			l.Service.SetAuth(l.Config.ServiceAuth, secret)
+		}
+		if l.Config.JSONRPC {
+			l.Service.EnableJSONRPC()
+		}
+		if l.Config.Profiling {
+			p, err := profile.New(filepath.Join(l.Config.DataDir, "profiles"), l.Config.ProfileKeep)
+			if err != nil {
+				return err
+			}
+			l.Service.SetProfiler(p)
+		}
+		l.Node.OnShutdown(func() {
+			if err := l.Service.Close(serviceCloseTimeout); err != nil {
+				l.Config.Logger.WithError(err).Warn("Closing service")
+			}
+		})
+	}
+	return nil
+}
+
+func (l *Lachesis) initIndexer() error {
+	if l.Config.Indexer == "" {
+		return nil
+	}
+	ix, err := indexer.New(l.Config.Indexer, l.Config.IndexerDSN, l.Node, l.Config.Logger)
+	if err != nil {
+		return err
+	}
+	l.Indexer = ix
+	return nil
+}
+
+func (l *Lachesis) initArchiver() error {
+	if l.Config.Archive == "" {
+		return nil
+	}
+	sink, err := archive.NewSink(l.Config.Archive)
+	if err != nil {
+		return err
+	}
+	a, err := archive.New(sink, l.Node, l.Config.Logger)
+	if err != nil {
+		return err
+	}
+	a.SegmentSize = l.Config.ArchiveSegment
+	l.Archiver = a
+	return nil
+}
+
+func (l *Lachesis) initPlugins() error {
+	for _, path := range l.Config.Plugins {
+		p, err := node.LoadPlugin(path)
+		if err != nil {
+			return err
+		}
+		l.Node.RegisterPlugin(p)
+	}
+	return nil
+}
+
+func (l *Lachesis) initMetrics() error {
+	sink, err := l.Config.Metrics.NewSink()
+	if err != nil {
+		return err
+	}
+	metrics.SetSink(sink)
+	if l.Config.Metrics.Addr != "" {
+		l.metricsServer = metrics.NewServer(l.Config.Metrics.Addr, sink)
 	}
 	return nil
 }
@@ -177,15 +634,36 @@ func (l *Lachesis) Init() error {
 		lachesis_log.NewLocal(l.Config.Logger, l.Config.LogLevel)
 	}
 
+	if err := l.Config.Validate(); err != nil {
+		return err
+	}
+
+	if err := l.initMetrics(); err != nil {
+		return err
+	}
+
+	if l.Config.Chaos {
+		l.Config.Logger.Warn("Fault injection enabled")
+		chaos.Global().Enable()
+	}
+
+	// the keys of the peers and of the node are of the key type
+	keyType, _ := crypto.ParseKeyType(l.Config.KeyType)
+	crypto.SetKeyType(keyType)
+
 	if err := l.initPeers(); err != nil {
 		return err
 	}
 
+	if err := l.checkKeyTypes(keyType); err != nil {
+		return err
+	}
+
 	if err := l.initStore(); err != nil {
 		return err
 	}
 
-	if err := l.initTransport(); err != nil {
+	if err := l.checkAppState(); err != nil {
 		return err
 	}
 
@@ -193,14 +671,39 @@ func (l *Lachesis) Init() error {
 		return err
 	}
 
+	if t := crypto.TypeOf(&l.Config.Key.PublicKey); t != keyType {
+		return fmt.Errorf("the key of the node is a %s key, the network uses %s keys, see key-type", t, keyType)
+	}
+
+	// the TLS certificate of the transport may be made from the key
+	if err := l.initTransport(); err != nil {
+		return err
+	}
+
 	if err := l.initNode(); err != nil {
 		return err
 	}
 
+	if err := l.initIndexer(); err != nil {
+		return err
+	}
+
+	if err := l.initArchiver(); err != nil {
+		return err
+	}
+
+	if err := l.initPlugins(); err != nil {
+		return err
+	}
+
 	if err := l.initService(); err != nil {
 		return err
 	}
 
+	if err := l.initChains(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -208,9 +711,99 @@ func (l *Lachesis) Run() {
 	if l.Service != nil {
 		go l.Service.Serve()
 	}
+	if l.metricsServer != nil {
+		go func() {
+			err := l.metricsServer.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				l.Config.Logger.WithField("error", err).Error("Metrics server failed")
+			}
+		}()
+	}
+	for _, chain := range l.Chains {
+		go chain.Node.Run(true)
+	}
+	if l.Indexer != nil {
+		blocks := make(chan poset.Block, 100)
+		l.Node.SubscribeCommits(blocks)
+		go l.Indexer.Run(blocks, nil)
+	}
+	if l.Archiver != nil {
+		blocks := make(chan poset.Block, 100)
+		l.Node.SubscribeCommits(blocks)
+		stop := make(chan struct{})
+		go l.Archiver.Run(blocks, stop)
+		defer func() {
+			close(stop)
+			if err := l.Archiver.Flush(); err != nil {
+				l.Config.Logger.WithError(err).Error("Writing the last archive segment")
+			}
+		}()
+	}
+	if l.Config.DaemonIntegration {
+		stop := make(chan struct{})
+		defer close(stop)
+		l.notifyDaemon(stop)
+	}
 	l.Node.Run(true)
 }
 
+// serviceCloseTimeout is the time the service is given to answer the pending
+// requests on shutdown
+const serviceCloseTimeout = 5 * time.Second
+
+// notifyDaemon tells the process manager the node is ready and pings its
+// watchdog while the node runs, until stop is closed
+func (l *Lachesis) notifyDaemon(stop <-chan struct{}) {
+	sent, err := daemon.Notify(daemon.Ready + "\n" + daemon.Status("node %d running", l.Node.ID()))
+	if err != nil {
+		l.Config.Logger.WithError(err).Warn("Notifying readiness")
+	}
+	if !sent {
+		return
+	}
+	if interval, ok := daemon.WatchdogInterval(); ok {
+		go daemon.RunWatchdog(interval, func() bool {
+			return l.Node.GetState() != node.Shutdown
+		}, stop)
+	}
+}
+
+// Shutdown stops the node. With daemon integration it first tells the process
+// manager and drains the pending transactions. It then flushes the store and
+// the pool journal, closes the service and closes the transport, in that
+// order. It returns the first error of the flushes, nil after a clean
+// shutdown; concurrent calls return once the first one completed.
+func (l *Lachesis) Shutdown() error {
+	l.shutdownOnce.Do(func() {
+		l.shutdownErr = l.shutdown()
+	})
+	return l.shutdownErr
+}
+
+func (l *Lachesis) shutdown() error {
+	if l.Config.DaemonIntegration {
+		daemon.Notify(daemon.Stopping)
+		if err := l.Node.Drain(l.Config.DrainTimeout); err != nil {
+			l.Config.Logger.WithError(err).Warn("Draining transactions")
+		}
+	}
+	var res error
+	for _, chain := range l.Chains {
+		chain.Node.Shutdown()
+		if err := chain.Node.ShutdownError(); err != nil && res == nil {
+			res = fmt.Errorf("chain %s: %s", chain.Config.ID, err)
+		}
+	}
+	l.Node.Shutdown()
+	if err := l.Node.ShutdownError(); err != nil {
+		res = err
+	}
+	if l.metricsServer != nil {
+		l.metricsServer.Close()
+	}
+	return res
+}
+
 func Keygen(datadir string) (*ecdsa.PrivateKey, error) {
 	pemKey := crypto.NewPemKey(datadir)
 
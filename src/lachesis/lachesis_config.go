@@ -2,55 +2,243 @@ package lachesis
 
 import (
 	"crypto/ecdsa"
+	"fmt"
+	"net"
 	"os"
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
+	"github.com/Fantom-foundation/go-lachesis/src/archive"
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
 	"github.com/Fantom-foundation/go-lachesis/src/log"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	lnet "github.com/Fantom-foundation/go-lachesis/src/net"
 	"github.com/Fantom-foundation/go-lachesis/src/node"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/profile"
 	"github.com/Fantom-foundation/go-lachesis/src/proxy"
+	"github.com/Fantom-foundation/go-lachesis/src/service"
 	"github.com/sirupsen/logrus"
 )
 
+// Transports of LachesisConfig.Transport
+const (
+	TransportTCP  = "tcp"
+	TransportQUIC = "quic"
+)
+
+// Protocols of LachesisConfig.ProxyProtocol
+const (
+	ProxyGRPC = "grpc"
+)
+
+// Store types of LachesisConfig.StoreType
+const (
+	StoreBadger  = "badger"
+	StoreLevelDB = "leveldb"
+	StoreHybrid  = "hybrid"
+	// StoreRocksDB requires a build with the rocksdb tag, see
+	// RocksDBSupported
+	StoreRocksDB = "rocksdb"
+)
+
 type LachesisConfig struct {
-	DataDir     string `mapstructure:"datadir"`
+	DataDir string `mapstructure:"datadir"`
+	// BindAddr is a comma-separated list of listen addresses, IPv6 ones in
+	// brackets, the first one being advertised; QUIC listens on one only
 	BindAddr    string `mapstructure:"listen"`
 	ServiceAddr string `mapstructure:"service-listen"`
-  ServiceOnly bool   `mapstructure:"service-only"`
-	MaxPool     int    `mapstructure:"max-pool"`
-	Store       bool   `mapstructure:"store"`
-	LogLevel    string `mapstructure:"log"`
+	ServiceOnly bool   `mapstructure:"service-only"`
+	// ServiceAdminAddr, when set, serves the admin endpoints of the service
+	// and its requests changing the node, ServiceAddr only serving the
+	// read-only ones
+	ServiceAdminAddr string `mapstructure:"service-admin-listen"`
+	// ServiceAuth is the authentication of the requests of the service
+	// changing the node: none, bearer or hmac, with the secret read from
+	// ServiceSecretFile
+	ServiceAuth       string `mapstructure:"service-auth"`
+	ServiceSecretFile string `mapstructure:"service-secret-file"`
+	// ServiceCORSOrigins are the origins of the browsers allowed to call
+	// the service, "*" allowing any
+	ServiceCORSOrigins []string `mapstructure:"service-cors-origins"`
+	MaxPool            int      `mapstructure:"max-pool"`
+	// RPCRate bounds the inbound RPCs per second of every peer, told apart
+	// by their verified key, with bursts of RPCBurst. 0 disables the limit.
+	RPCRate  float64 `mapstructure:"rpc-rate"`
+	RPCBurst int     `mapstructure:"rpc-burst"`
+	// DialBackoff is the wait before connecting again to a peer address
+	// whose connection failed, doubled at every consecutive failure up to
+	// DialBackoffMax; 0 disables it. After DialBanAfter consecutive failures
+	// the address is banned for the ban-duration, 0 never bans it.
+	DialBackoff    time.Duration `mapstructure:"dial-backoff"`
+	DialBackoffMax time.Duration `mapstructure:"dial-backoff-max"`
+	DialBanAfter   int           `mapstructure:"dial-ban-after"`
+	// TLS encrypts the connections between nodes. A node presents the
+	// certificate of TLSCert and TLSKey, or else a self-signed certificate of
+	// its key, and accepts the certificates issued by TLSCA, or else the
+	// self-signed certificates of the keys of its peers.
+	TLS     bool   `mapstructure:"tls"`
+	TLSCert string `mapstructure:"tls-cert"`
+	TLSKey  string `mapstructure:"tls-key"`
+	TLSCA   string `mapstructure:"tls-ca"`
+	// Transport carries the RPCs between nodes: tcp, or quic whose streams
+	// are always encrypted like with TLS
+	Transport string `mapstructure:"transport"`
+	// NAT asks the gateway of a node behind NAT to forward the port of
+	// BindAddr: none, upnp, pmp, pmp:<gateway IP> or any. The external
+	// address of the gateway is advertised unless advertise_addr is set.
+	NAT string `mapstructure:"nat"`
+	// WireCompression is the codec of the RPC payloads asked for on the
+	// connections to peers: none, gzip or snappy
+	WireCompression string `mapstructure:"wire-compression"`
+	// WireVersion is the newest wire format asked for on the connections to
+	// peers, see lnet.SetWireVersion: 1 for JSON, 2 for protobuf events
+	WireVersion int `mapstructure:"wire-version"`
+
+	Store bool `mapstructure:"store"`
+	// StoreType is the database of the store enabled by Store: badger,
+	// leveldb or rocksdb, under DataDir/badger, DataDir/leveldb or
+	// DataDir/rocksdb, or hybrid, a badger database under DataDir/badger
+	// written when items leave the caches
+	StoreType string `mapstructure:"store-type"`
+	// StoreCompression is the codec of the events, blocks and frames written
+	// to the store: none, snappy or zstd
+	StoreCompression string `mapstructure:"store-compression"`
+	// StoreGCInterval is the period of the value log GC of a badger or
+	// hybrid store, 0 disabling it
+	StoreGCInterval time.Duration `mapstructure:"store-gc-interval"`
+	// StoreGCDiscardRatio is the share of stale values a value log file must
+	// hold to be rewritten by the GC
+	StoreGCDiscardRatio float64 `mapstructure:"store-gc-discard-ratio"`
+
+	// PasswordFile holds the passphrase of the key of the node when it is in
+	// the keystore of DataDir, see crypto.Keystore. KeystoreKey selects it by
+	// a prefix of its public key when the keystore holds several keys.
+	PasswordFile string `mapstructure:"password-file"`
+	KeystoreKey  string `mapstructure:"keystore-key"`
+	// Passphrase of the keystore key, read from PasswordFile when empty
+	Passphrase string
+
+	// KeyType is the signature scheme of the keys of the network: p256,
+	// secp256k1 or ed25519. All the nodes of a network use the same one.
+	KeyType string `mapstructure:"key-type"`
+
+	LogLevel string `mapstructure:"log"`
+	Genesis  string `mapstructure:"genesis"`
+	Chaos    bool   `mapstructure:"chaos"`
 
-	NodeConfig node.Config `mapstructure:",squash"`
+	// App proxy
+	ProxyAddr  string `mapstructure:"proxy-listen"`
+	ClientAddr string `mapstructure:"client-connect"`
+	Standalone bool   `mapstructure:"standalone"`
+	Log2file   bool   `mapstructure:"log2file"`
+	// ProxyProtocol is the protocol of the app proxy listening on
+	// ProxyAddr: grpc, the only one since the JSON-RPC socket proxy was
+	// removed
+	ProxyProtocol string `mapstructure:"proxy_protocol"`
+	// ABCIAddr is the address of an ABCI application, tcp://host:port or
+	// unix:///path, run instead of the gRPC app proxy when set. ABCIChainID
+	// names the chain in the block headers delivered to it.
+	ABCIAddr    string `mapstructure:"abci"`
+	ABCIChainID string `mapstructure:"abci-chain-id"`
+
+	// Indexer is the SQL driver of the block indexer, postgres or sqlite3,
+	// empty to disable it
+	Indexer    string `mapstructure:"indexer"`
+	IndexerDSN string `mapstructure:"indexer-dsn"`
+
+	// Archive is the directory or http(s) URL where committed blocks and
+	// their events are archived, empty to disable the archiver
+	Archive        string `mapstructure:"archive"`
+	ArchiveSegment int    `mapstructure:"archive-segment"`
+
+	// Plugins are the paths of Go plugins receiving the consensus events of
+	// the node, see node.LoadPlugin
+	Plugins []string `mapstructure:"plugins"`
+
+	// DaemonIntegration notifies the process manager of readiness and
+	// liveness (sd_notify) and shuts down in order on SIGTERM, draining the
+	// pending transactions for up to DrainTimeout first
+	DaemonIntegration bool          `mapstructure:"daemon-integration"`
+	DrainTimeout      time.Duration `mapstructure:"drain-timeout"`
+
+	// JSONRPC enables the /rpc endpoint of the service, answering Ethereum
+	// style block queries, see service.JSONRPC
+	JSONRPC bool `mapstructure:"jsonrpc"`
+
+	// Profiling enables the /profiles endpoints of the service, capturing
+	// runtime profiles under datadir/profiles and keeping ProfileKeep of them
+	Profiling   bool `mapstructure:"profiling"`
+	ProfileKeep int  `mapstructure:"profile-keep"`
+
+	// Chains are the additional posets hosted by the node, read from the
+	// chains section of the config file
+	Chains []ChainConfig `mapstructure:"chains"`
+
+	NodeConfig node.Config         `mapstructure:",squash"`
+	Log        lachesis_log.Config `mapstructure:",squash"`
+	Metrics    metrics.Config      `mapstructure:",squash"`
 
 	LoadPeers bool
 	Proxy     proxy.AppProxy
 	Key       *ecdsa.PrivateKey
 	Logger    *logrus.Logger
 
-	Test  bool   `mapstructure:"test"`
-	TestN uint64 `mapstructure:"test_n"`
+	Test      bool   `mapstructure:"test"`
+	TestN     uint64 `mapstructure:"test_n"`
 	TestDelay uint64 `mapstructure:"test_delay"`
+	// Load generation parameters of the test mode
+	TestRate    float64       `mapstructure:"test_rate"`
+	TestRampUp  time.Duration `mapstructure:"test_ramp_up"`
+	TestPayload string        `mapstructure:"test_payload"`
+	TestTargets string        `mapstructure:"test_targets"`
 }
 
 func NewDefaultConfig() *LachesisConfig {
 	config := &LachesisConfig{
-		DataDir:     DefaultDataDir(),
-		BindAddr:    ":1337",
-		ServiceAddr: ":8000",
-		ServiceOnly: false,
-		MaxPool:     2,
-		NodeConfig:  *node.DefaultConfig(),
-		Store:       false,
-		LogLevel:    "info",
-		Proxy:       nil,
-		Logger:      logrus.New(),
-		LoadPeers:   true,
-		Key:         nil,
-		Test:        false,
-		TestN:       ^uint64(0),
-	        TestDelay:   1,
+		DataDir:             DefaultDataDir(),
+		BindAddr:            ":1337",
+		ServiceAddr:         ":8000",
+		ServiceOnly:         false,
+		ServiceAuth:         service.AuthNone,
+		ServiceCORSOrigins:  []string{"*"},
+		MaxPool:             2,
+		RPCBurst:            100,
+		DialBackoff:         lnet.DefaultDialBackoff,
+		DialBackoffMax:      lnet.DefaultDialBackoffMax,
+		DialBanAfter:        lnet.DefaultDialBanAfter,
+		ProxyAddr:           "127.0.0.1:1338",
+		ProxyProtocol:       ProxyGRPC,
+		ClientAddr:          "127.0.0.1:1339",
+		NodeConfig:          *node.DefaultConfig(),
+		Log:                 lachesis_log.DefaultConfig(),
+		Metrics:             metrics.DefaultConfig(),
+		Store:               false,
+		Transport:           TransportTCP,
+		NAT:                 lnet.NATNone,
+		KeyType:             string(crypto.KeyP256),
+		StoreType:           StoreBadger,
+		StoreCompression:    string(poset.CompressionNone),
+		StoreGCInterval:     poset.DefaultGCInterval,
+		StoreGCDiscardRatio: poset.DefaultGCDiscardRatio,
+		WireCompression:     lnet.WireNone,
+		WireVersion:         lnet.WireVersion,
+		LogLevel:            "info",
+		ArchiveSegment:      archive.DefaultSegmentSize,
+		DrainTimeout:        10 * time.Second,
+		ProfileKeep:         profile.DefaultKeep,
+		Proxy:               nil,
+		Logger:              logrus.New(),
+		LoadPeers:           true,
+		Key:                 nil,
+		Test:                false,
+		TestN:               ^uint64(0),
+		TestDelay:           1,
+		TestRate:            100,
+		TestPayload:         "fixed:120",
 	}
 
 	config.Logger.Level = LogLevel(config.LogLevel)
@@ -63,6 +251,170 @@ func NewDefaultConfig() *LachesisConfig {
 	return config
 }
 
+// Validate checks the whole configuration and reports every invalid setting
+func (c *LachesisConfig) Validate() error {
+	var errs []string
+	check := func(err error) {
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if c.DataDir == "" {
+		errs = append(errs, "datadir is required")
+	}
+	listenAddrs := lnet.ListenAddrs(c.BindAddr)
+	if len(listenAddrs) == 0 {
+		errs = append(errs, "listen is required")
+	}
+	for _, addr := range listenAddrs {
+		check(validateAddr("listen", addr))
+	}
+	if len(listenAddrs) > 1 && c.Transport == TransportQUIC {
+		errs = append(errs, fmt.Sprintf("transport %s listens on a single address, got %q", TransportQUIC, c.BindAddr))
+	}
+	if c.NodeConfig.AdvertiseAddr != "" {
+		check(validateAddr("advertise_addr", c.NodeConfig.AdvertiseAddr))
+	}
+	if _, _, err := lnet.ParseNAT(c.NAT); err != nil {
+		errs = append(errs, fmt.Sprintf("nat: %s", err))
+	}
+	// the HTTP service is disabled without address
+	if c.ServiceAddr != "" {
+		check(validateAddr("service-listen", c.ServiceAddr))
+	}
+	if !c.Standalone {
+		check(validateAddr("proxy-listen", c.ProxyAddr))
+		if c.ProxyProtocol != ProxyGRPC {
+			errs = append(errs, fmt.Sprintf("proxy_protocol must be %s, got %q", ProxyGRPC, c.ProxyProtocol))
+		}
+	}
+	if c.ServiceAdminAddr != "" {
+		if c.ServiceAddr == "" {
+			errs = append(errs, "service-admin-listen requires the HTTP service, see service-listen")
+		}
+		check(validateAddr("service-admin-listen", c.ServiceAdminAddr))
+		if c.ServiceAdminAddr == c.ServiceAddr {
+			errs = append(errs, "service-admin-listen must differ from service-listen")
+		}
+	}
+	switch c.ServiceAuth {
+	case service.AuthNone:
+	case service.AuthBearer, service.AuthHMAC:
+		if c.ServiceSecretFile == "" {
+			errs = append(errs, fmt.Sprintf("service-auth %s requires service-secret-file", c.ServiceAuth))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("service-auth must be %s, %s or %s, got %q", service.AuthNone, service.AuthBearer, service.AuthHMAC, c.ServiceAuth))
+	}
+	if c.JSONRPC && c.ServiceAddr == "" {
+		errs = append(errs, "jsonrpc requires the HTTP service, see service-listen")
+	}
+	if c.MaxPool < 1 {
+		errs = append(errs, fmt.Sprintf("max-pool must be at least 1, got %d", c.MaxPool))
+	}
+	if c.RPCRate < 0 {
+		errs = append(errs, fmt.Sprintf("rpc-rate must not be negative, got %g", c.RPCRate))
+	}
+	if c.RPCRate > 0 && c.RPCBurst < 1 {
+		errs = append(errs, fmt.Sprintf("rpc-burst must be at least 1, got %d", c.RPCBurst))
+	}
+	if c.DialBackoff < 0 || c.DialBackoffMax < 0 {
+		errs = append(errs, fmt.Sprintf("dial-backoff and dial-backoff-max must not be negative, got %v and %v", c.DialBackoff, c.DialBackoffMax))
+	}
+	if c.DialBanAfter < 0 {
+		errs = append(errs, fmt.Sprintf("dial-ban-after must not be negative, got %d", c.DialBanAfter))
+	}
+	if _, err := crypto.ParseKeyType(c.KeyType); err != nil {
+		errs = append(errs, fmt.Sprintf("key-type: %s", err))
+	}
+	if c.Transport != TransportTCP && c.Transport != TransportQUIC {
+		errs = append(errs, fmt.Sprintf("transport must be %s or %s, got %q", TransportTCP, TransportQUIC, c.Transport))
+	}
+	if !c.TLS && c.Transport != TransportQUIC && (c.TLSCert != "" || c.TLSKey != "" || c.TLSCA != "") {
+		errs = append(errs, "tls-cert, tls-key and tls-ca require tls or the quic transport")
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		errs = append(errs, "tls-cert and tls-key must be set together")
+	}
+	if c.TLSCA != "" && c.TLSCert == "" {
+		errs = append(errs, "tls-ca requires a certificate issued by it, see tls-cert")
+	}
+	if _, err := lnet.ParseWireCompression(c.WireCompression); err != nil {
+		errs = append(errs, "wire-compression "+strings.TrimPrefix(err.Error(), "wire compression "))
+	}
+	if c.WireVersion < lnet.WireVersionJSON || c.WireVersion > lnet.WireVersion {
+		errs = append(errs, fmt.Sprintf("wire-version must be between %d and %d, got %d", lnet.WireVersionJSON, lnet.WireVersion, c.WireVersion))
+	}
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+		errs = append(errs, fmt.Sprintf("log must be one of debug, info, warn, error, fatal, panic, got %q", c.LogLevel))
+	}
+	switch c.Indexer {
+	case "":
+	case "postgres", "sqlite3":
+		if c.IndexerDSN == "" {
+			errs = append(errs, "indexer-dsn is required by the indexer")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("indexer must be postgres or sqlite3, got %q", c.Indexer))
+	}
+	switch c.StoreType {
+	case StoreBadger, StoreLevelDB, StoreHybrid:
+	case StoreRocksDB:
+		if !RocksDBSupported {
+			errs = append(errs, "store-type rocksdb requires a build with -tags rocksdb")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("store-type must be %s, %s, %s or %s, got %q", StoreBadger, StoreLevelDB, StoreRocksDB, StoreHybrid, c.StoreType))
+	}
+	if c.Store && (c.StoreType == StoreLevelDB || c.StoreType == StoreRocksDB) && c.NodeConfig.PruneDepth > 0 {
+		errs = append(errs, "prune_depth requires the badger or hybrid store")
+	}
+	if _, err := poset.ParseCompression(c.StoreCompression); err != nil {
+		errs = append(errs, "store-compression "+strings.TrimPrefix(err.Error(), "compression "))
+	}
+	if c.StoreGCInterval < 0 {
+		errs = append(errs, fmt.Sprintf("store-gc-interval must not be negative, got %s", c.StoreGCInterval))
+	}
+	if c.StoreGCDiscardRatio <= 0 || c.StoreGCDiscardRatio >= 1 {
+		errs = append(errs, fmt.Sprintf("store-gc-discard-ratio must be between 0 and 1, got %v", c.StoreGCDiscardRatio))
+	}
+	if c.Profiling && c.ProfileKeep < 1 {
+		errs = append(errs, fmt.Sprintf("profile-keep must be at least 1, got %d", c.ProfileKeep))
+	}
+	if c.DrainTimeout < 0 {
+		errs = append(errs, fmt.Sprintf("drain-timeout must not be negative, got %s", c.DrainTimeout))
+	}
+	if c.Archive != "" && c.ArchiveSegment < 1 {
+		errs = append(errs, fmt.Sprintf("archive-segment must be at least 1, got %d", c.ArchiveSegment))
+	}
+	errs = append(errs, c.validateChains()...)
+	check(c.NodeConfig.Validate())
+	check(c.Log.Validate())
+	check(c.Metrics.Validate())
+	if c.Test {
+		if c.TestRate <= 0 {
+			errs = append(errs, fmt.Sprintf("test_rate must be positive, got %g", c.TestRate))
+		}
+		if c.TestRampUp < 0 {
+			errs = append(errs, fmt.Sprintf("test_ramp_up must not be negative, got %v", c.TestRampUp))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// validateAddr checks that addr is a [host]:port address
+func validateAddr(name, addr string) error {
+	if _, port, err := net.SplitHostPort(addr); err != nil || port == "" {
+		return fmt.Errorf("%s must be a [host]:port address, got %q", name, addr)
+	}
+	return nil
+}
+
 func DefaultBadgerDir() string {
 	dataDir := DefaultDataDir()
 	if dataDir != "" {
@@ -0,0 +1,91 @@
+package lachesis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	conf := NewDefaultConfig()
+	if err := conf.Validate(); err != nil {
+		t.Fatalf("the default configuration should be valid: %v", err)
+	}
+
+	conf.BindAddr = "1337"
+	conf.MaxPool = 0
+	conf.NodeConfig.HeartbeatTimeout = 0
+	conf.Metrics.Sinks = "graphite"
+	conf.WireVersion = 3
+	conf.StoreType = "bolt"
+	conf.Transport = "sctp"
+	conf.KeyType = "rsa"
+	conf.StoreGCDiscardRatio = 1
+	err := conf.Validate()
+	if err == nil {
+		t.Fatal("expected an invalid configuration")
+	}
+	for _, name := range []string{"listen", "max-pool", "heartbeat", "metrics", "wire-version", "store-type", "transport", "key-type", "store-gc-discard-ratio"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Fatalf("%q should be reported in %q", name, err)
+		}
+	}
+
+	conf = NewDefaultConfig()
+	conf.Standalone = true
+	conf.ProxyAddr = ""
+	if err := conf.Validate(); err != nil {
+		t.Fatalf("standalone nodes need no proxy address: %v", err)
+	}
+
+	conf = NewDefaultConfig()
+	conf.ProxyProtocol = "jsonrpc"
+	if err := conf.Validate(); err == nil || !strings.Contains(err.Error(), "proxy_protocol") {
+		t.Fatalf("the unknown proxy protocol should be reported, got %v", err)
+	}
+}
+
+func TestConfigValidateListen(t *testing.T) {
+	conf := NewDefaultConfig()
+	conf.BindAddr = "127.0.0.1:1337, [::1]:1337"
+	if err := conf.Validate(); err != nil {
+		t.Fatalf("several listen addresses should be valid: %v", err)
+	}
+
+	conf.BindAddr = "127.0.0.1:1337,::1:1337"
+	if err := conf.Validate(); err == nil || !strings.Contains(err.Error(), `"::1:1337"`) {
+		t.Fatalf("an IPv6 address without brackets should be refused, got %v", err)
+	}
+
+	conf.BindAddr = "127.0.0.1:1337,[::1]:1337"
+	conf.Transport = TransportQUIC
+	if err := conf.Validate(); err == nil || !strings.Contains(err.Error(), "single address") {
+		t.Fatalf("QUIC should listen on a single address, got %v", err)
+	}
+}
+
+func TestConfigValidateChains(t *testing.T) {
+	conf := NewDefaultConfig()
+	conf.Chains = []ChainConfig{
+		{ID: "a", ProxyAddr: "127.0.0.1:2338"},
+		{ID: "a", ProxyAddr: "127.0.0.1:2339"},
+		{ID: "b"},
+		{ProxyAddr: "127.0.0.1:2340"},
+	}
+	err := conf.Validate()
+	if err == nil {
+		t.Fatal("expected invalid chains")
+	}
+	for _, msg := range []string{`chain "a" is defined twice`, `chain "b" proxy-listen`, "chains[3] needs an id"} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Fatalf("%q should be reported in %q", msg, err)
+		}
+	}
+
+	conf.Chains = conf.Chains[:1]
+	if err := conf.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if dir := conf.chainDir(&conf.Chains[0]); !strings.HasSuffix(dir, "chains/a") {
+		t.Fatalf("unexpected chain directory %s", dir)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !rocksdb
+// +build !rocksdb
+
+package lachesis
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// RocksDBSupported tells whether the binary was built with the rocksdb tag,
+// which the rocksdb store type requires
+const RocksDBSupported = false
+
+var errNoRocksDB = fmt.Errorf("the %s store requires a build with -tags rocksdb", StoreRocksDB)
+
+// LoadOrCreateRocksDBStore fails, the binary was built without RocksDB
+func LoadOrCreateRocksDBStore(participants *peers.Peers, cacheSize int, path string, compression poset.Compression) (poset.Store, error) {
+	return nil, errNoRocksDB
+}
+
+// LoadRocksDBStore fails, the binary was built without RocksDB
+func LoadRocksDBStore(cacheSize int, path string) (poset.Store, error) {
+	return nil, errNoRocksDB
+}
@@ -0,0 +1,33 @@
+//go:build rocksdb
+// +build rocksdb
+
+package lachesis
+
+import (
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// RocksDBSupported tells whether the binary was built with the rocksdb tag,
+// which the rocksdb store type requires
+const RocksDBSupported = true
+
+// LoadOrCreateRocksDBStore loads the RocksDB store at path, or creates it if
+// there is none
+func LoadOrCreateRocksDBStore(participants *peers.Peers, cacheSize int, path string, compression poset.Compression) (poset.Store, error) {
+	store, err := poset.LoadOrCreateRocksDBStore(participants, cacheSize, path)
+	if err != nil {
+		return nil, err
+	}
+	store.SetCompression(compression)
+	return store, nil
+}
+
+// LoadRocksDBStore loads the existing RocksDB store at path
+func LoadRocksDBStore(cacheSize int, path string) (poset.Store, error) {
+	store, err := poset.LoadRocksDBStore(cacheSize, path)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
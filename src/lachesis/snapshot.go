@@ -0,0 +1,189 @@
+package lachesis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// snapshotVersion is the version of the snapshot file format
+const snapshotVersion = 1
+
+// SnapshotFile is the content of a snapshot file: an anchor Block, the Frame
+// of its round and the state of the application after it. A node importing
+// it starts from that block instead of replaying the whole history.
+type SnapshotFile struct {
+	Version int
+	// Block and Frame are protobuf encoded
+	Block    []byte
+	Frame    []byte
+	Snapshot []byte
+}
+
+// WriteSnapshotFile writes a snapshot file to path
+func WriteSnapshotFile(path string, block poset.Block, frame poset.Frame, snapshot []byte) error {
+	b, err := block.ProtoMarshal()
+	if err != nil {
+		return err
+	}
+	f, err := frame.ProtoMarshal()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(SnapshotFile{
+		Version:  snapshotVersion,
+		Block:    b,
+		Frame:    f,
+		Snapshot: snapshot,
+	})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// ReadSnapshotFile reads the snapshot file at path
+func ReadSnapshotFile(path string) (poset.Block, poset.Frame, []byte, error) {
+	var block poset.Block
+	var frame poset.Frame
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return block, frame, nil, err
+	}
+	var sf SnapshotFile
+	if err := json.Unmarshal(data, &sf); err != nil {
+		return block, frame, nil, fmt.Errorf("decoding %s: %s", path, err)
+	}
+	if sf.Version != snapshotVersion {
+		return block, frame, nil, fmt.Errorf("unsupported snapshot version %d, expected %d", sf.Version, snapshotVersion)
+	}
+	if err := block.ProtoUnmarshal(sf.Block); err != nil {
+		return block, frame, nil, fmt.Errorf("decoding block: %s", err)
+	}
+	if err := frame.ProtoUnmarshal(sf.Frame); err != nil {
+		return block, frame, nil, fmt.Errorf("decoding frame: %s", err)
+	}
+	return block, frame, sf.Snapshot, nil
+}
+
+// ExportSnapshot writes to path the block blockIndex of the badger store of a
+// stopped node, -1 for the last anchor block, with its frame and the
+// snapshot the application returns for it
+func (l *Lachesis) ExportSnapshot(blockIndex int64, path string) error {
+	store, err := poset.LoadBadgerStore(l.Config.NodeConfig.CacheSize, filepath.Join(l.Config.DataDir, "badger"))
+	if err != nil {
+		return fmt.Errorf("opening store: %s", err)
+	}
+	defer store.Close()
+
+	var block poset.Block
+	if blockIndex < 0 {
+		block, err = store.LastAnchorBlock()
+	} else {
+		block, err = store.GetBlock(blockIndex)
+	}
+	if err != nil {
+		return err
+	}
+	participants, err := store.Participants()
+	if err != nil {
+		return err
+	}
+	if err := poset.NewPoset(participants, store, nil, logrus.NewEntry(l.Config.Logger)).CheckBlock(block); err != nil {
+		return fmt.Errorf("block %d cannot anchor a snapshot: %s", block.Index(), err)
+	}
+	frame, err := store.GetFrame(block.RoundReceived())
+	if err != nil {
+		return err
+	}
+	snapshot, err := l.Config.Proxy.GetSnapshot(block.Index())
+	if err != nil {
+		return fmt.Errorf("getting the snapshot of block %d: %s", block.Index(), err)
+	}
+	if err := WriteSnapshotFile(path, block, frame, snapshot); err != nil {
+		return err
+	}
+
+	l.Config.Logger.WithFields(logrus.Fields{
+		"block": block.Index(),
+		"round": block.RoundReceived(),
+		"path":  path,
+	}).Info("Exported snapshot")
+	return nil
+}
+
+// ImportSnapshot creates the badger store of a new node from the snapshot
+// file at path and restores the application from it. The block must be
+// signed by enough validators of the peers of the node. On the next run,
+// Bootstrap resets the poset from that block and its frame.
+func (l *Lachesis) ImportSnapshot(path string) error {
+	if !l.Config.Store || (l.Config.StoreType != StoreBadger && l.Config.StoreType != StoreHybrid) {
+		return fmt.Errorf("importing a snapshot requires a badger store, see --store")
+	}
+	dbDir := filepath.Join(l.Config.DataDir, "badger")
+	if _, err := os.Stat(dbDir); err == nil {
+		return fmt.Errorf("store %s already exists", dbDir)
+	}
+
+	block, frame, snapshot, err := ReadSnapshotFile(path)
+	if err != nil {
+		return err
+	}
+	if err := l.initPeers(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(l.Config.DataDir, 0700); err != nil {
+		return err
+	}
+	store, err := poset.NewBadgerStore(l.Peers, l.Config.NodeConfig.CacheSize, dbDir)
+	if err != nil {
+		return err
+	}
+	if err := l.importSnapshot(store, block, frame, snapshot); err != nil {
+		store.Close()
+		os.RemoveAll(dbDir)
+		return err
+	}
+	if err := store.Close(); err != nil {
+		return err
+	}
+
+	l.Config.Logger.WithFields(logrus.Fields{
+		"block": block.Index(),
+		"round": block.RoundReceived(),
+		"path":  path,
+	}).Info("Imported snapshot")
+	return nil
+}
+
+func (l *Lachesis) importSnapshot(store *poset.BadgerStore, block poset.Block, frame poset.Frame, snapshot []byte) error {
+	if err := poset.NewPoset(l.Peers, store, nil, logrus.NewEntry(l.Config.Logger)).CheckBlock(block); err != nil {
+		return err
+	}
+	frameHash, err := frame.Hash()
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(block.GetFrameHash(), frameHash) {
+		return fmt.Errorf("invalid Frame Hash")
+	}
+
+	if err := l.Config.Proxy.Restore(snapshot); err != nil {
+		return fmt.Errorf("restoring the application: %s", err)
+	}
+	if err := store.SetBlock(block); err != nil {
+		return err
+	}
+	if err := store.SetFrame(frame); err != nil {
+		return err
+	}
+	return store.SetPruneInfo(poset.PruneInfo{Block: block.Index(), Round: block.RoundReceived()})
+}
@@ -0,0 +1,135 @@
+package lachesis
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/dummy"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// snapshotNode returns an engine storing its badger database in dir
+func snapshotNode(t *testing.T, dir string, ps *peers.Peers) *Lachesis {
+	conf := NewDefaultConfig()
+	conf.DataDir = dir
+	conf.Store = true
+	conf.LoadPeers = false
+	conf.Logger = common.NewTestLogger(t)
+	conf.Proxy = dummy.NewInmemDummyApp(conf.Logger)
+	l := NewLachesis(conf)
+	l.Peers = ps
+	return l
+}
+
+func TestSnapshotExportImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lachesis-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var keys []*ecdsa.PrivateKey
+	ps := peers.NewPeers()
+	for i := 0; i < 4; i++ {
+		key, _ := crypto.GenerateECDSAKey()
+		keys = append(keys, key)
+		ps.AddPeer(peers.NewPeer(fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey)), ""))
+	}
+	frame := poset.Frame{Round: 3}
+	for _, p := range ps.ToPeerSlice() {
+		root := poset.NewBaseRoot(p.ID)
+		frame.Roots = append(frame.Roots, &root)
+	}
+	block, err := poset.NewBlockFromFrame(0, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys[:3] {
+		sig, err := block.Sign(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block.SetSignature(sig)
+	}
+
+	// a node which committed the block
+	source := snapshotNode(t, filepath.Join(dir, "source"), ps)
+	if err := os.MkdirAll(filepath.Join(dir, "source"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	store, err := poset.NewBadgerStore(ps, 100, filepath.Join(dir, "source", "badger"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetBlock(block); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetFrame(frame); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	if _, err := source.Config.Proxy.CommitBlock(block); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(dir, "snapshot.json")
+	if err := source.ExportSnapshot(-1, file); err != nil {
+		t.Fatal(err)
+	}
+
+	target := snapshotNode(t, filepath.Join(dir, "target"), ps)
+	if err := target.ImportSnapshot(file); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := target.Config.Proxy.GetSnapshot(block.Index()); err != nil {
+		t.Fatalf("the application should be restored at block %d: %s", block.Index(), err)
+	}
+	imported, err := poset.LoadBadgerStore(100, filepath.Join(dir, "target", "badger"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, pruned, err := imported.PruneInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pruned || info.Block != block.Index() || info.Round != frame.Round {
+		t.Fatalf("the store should start at block %d round %d, got %v %+v", block.Index(), frame.Round, pruned, info)
+	}
+	// the poset of the new node starts from the block
+	p := poset.NewPoset(ps, imported, nil, nil)
+	if err := p.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	if p.LastConsensusRound == nil || *p.LastConsensusRound != frame.Round {
+		t.Fatalf("the poset should start at round %d, got %v", frame.Round, p.LastConsensusRound)
+	}
+	imported.Close()
+
+	// an existing store is not overwritten
+	if err := target.ImportSnapshot(file); err == nil {
+		t.Fatal("importing into an existing store should fail")
+	}
+
+	// a block without enough signatures is rejected
+	unsigned, err := poset.NewBlockFromFrame(0, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSnapshotFile(file, unsigned, frame, nil); err != nil {
+		t.Fatal(err)
+	}
+	other := snapshotNode(t, filepath.Join(dir, "other"), ps)
+	if err := other.ImportSnapshot(file); err == nil {
+		t.Fatal("a block without enough signatures should be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other", "badger")); !os.IsNotExist(err) {
+		t.Fatal("a failed import should not leave a store")
+	}
+}
@@ -0,0 +1,242 @@
+// Package lightclient follows a Lachesis chain without a store or taking part
+// in gossip. It verifies the signatures of committed blocks against the
+// validator set, tracks the membership changes committed in their frames and
+// checks Merkle proofs of transactions against the verified blocks.
+package lightclient
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// DefaultKeep is the number of verified headers kept by a Client
+const DefaultKeep = 1000
+
+// Header is what the client keeps of a verified block
+type Header struct {
+	Index         int64
+	RoundReceived int64
+	// BodyHash is the hash signed by the validators
+	BodyHash  []byte
+	FrameHash []byte
+	StateHash []byte
+	// TxRoot is the Merkle root of the transactions of the block
+	TxRoot  []byte
+	TxCount int
+}
+
+// Client verifies a chain of blocks
+type Client struct {
+	validators *peers.Peers
+	headers    map[int64]Header
+	last       int64
+	lock       sync.RWMutex
+	logger     *logrus.Entry
+
+	// Keep is the number of headers kept for proof verification
+	Keep int
+}
+
+// New returns a client trusting the given validator set, usually the
+// peers.json of the chain or the genesis participants
+func New(validators *peers.Peers, logger *logrus.Logger) *Client {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &Client{
+		validators: validators,
+		headers:    make(map[int64]Header),
+		last:       -1,
+		logger:     logger.WithField("module", "lightclient"),
+		Keep:       DefaultKeep,
+	}
+}
+
+// Validators returns the current validator set
+func (c *Client) Validators() *peers.Snapshot {
+	return c.validators.Snapshot()
+}
+
+// Last returns the index of the last verified block, -1 when none
+func (c *Client) Last() int64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.last
+}
+
+// Header returns the header of a verified block
+func (c *Client) Header(index int64) (Header, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	h, ok := c.headers[index]
+	if !ok {
+		return Header{}, lerrors.New(lerrors.KeyNotFound, "block %d not verified", index)
+	}
+	return h, nil
+}
+
+// VerifyBlock checks that block is signed by more than a third of the current
+// validators, by weight, as poset.CheckBlock does
+func (c *Client) VerifyBlock(block poset.Block) error {
+	validators := c.validators.Snapshot()
+	var valid uint64
+	for _, sig := range block.GetBlockSignatures() {
+		if !validators.IsValidator(sig.ValidatorHex()) {
+			continue
+		}
+		if ok, _ := block.Verify(sig); ok {
+			valid += validators.Weight(sig.ValidatorHex())
+		}
+	}
+	if valid <= validators.TrustWeight() {
+		return fmt.Errorf("block %d: not enough valid signatures: got weight %d, need %d",
+			block.Index(), valid, validators.TrustWeight()+1)
+	}
+	return nil
+}
+
+// Commit verifies block and its frame, applies the membership changes
+// committed in the frame and keeps the header of the block. Blocks must be
+// committed in order, as skipping one would miss its membership changes; the
+// first one is the trust anchor.
+func (c *Client) Commit(block poset.Block, frame poset.Frame) (Header, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.last >= 0 && block.Index() != c.last+1 {
+		if block.Index() <= c.last {
+			return Header{}, fmt.Errorf("block %d already verified", block.Index())
+		}
+		return Header{}, lerrors.New(lerrors.TooFar, "expected block %d, got %d", c.last+1, block.Index())
+	}
+	if err := c.VerifyBlock(block); err != nil {
+		return Header{}, err
+	}
+
+	frameHash, err := frame.Hash()
+	if err != nil {
+		return Header{}, err
+	}
+	if !bytes.Equal(frameHash, block.FrameHash) {
+		return Header{}, fmt.Errorf("block %d: invalid frame hash", block.Index())
+	}
+
+	txRoot := poset.TxRoot(block.Transactions())
+	if len(block.TxRoot()) > 0 && !bytes.Equal(txRoot, block.TxRoot()) {
+		return Header{}, fmt.Errorf("block %d: invalid transactions root", block.Index())
+	}
+
+	bodyHash, err := block.Body.Hash()
+	if err != nil {
+		return Header{}, err
+	}
+	h := Header{
+		Index:         block.Index(),
+		RoundReceived: block.RoundReceived(),
+		BodyHash:      bodyHash,
+		FrameHash:     block.FrameHash,
+		StateHash:     block.StateHash,
+		TxRoot:        txRoot,
+		TxCount:       len(block.Transactions()),
+	}
+
+	if err := c.applyMembership(frame); err != nil {
+		return Header{}, err
+	}
+
+	c.headers[h.Index] = h
+	delete(c.headers, h.Index-int64(c.Keep))
+	c.last = h.Index
+	return h, nil
+}
+
+// applyMembership applies the PEER_ADD and PEER_REMOVE internal transactions
+// of the frame events
+func (c *Client) applyMembership(frame poset.Frame) error {
+	for _, e := range frame.Events {
+		if e == nil || e.Body == nil {
+			continue
+		}
+		for _, tx := range e.Body.InternalTransactions {
+			if tx == nil || tx.Peer == nil {
+				continue
+			}
+			var err error
+			switch tx.Type {
+			case poset.TransactionType_PEER_ADD:
+				peer := peers.NewPeer(tx.Peer.PubKeyHex, tx.Peer.NetAddr)
+				peer.Tier = tx.Peer.Tier
+				peer.Stake = tx.Peer.Stake
+				if err = peers.ValidateWeights(append(c.validators.ToPeerSlice(), peer)); err == nil {
+					err = c.validators.AddPeer(peer)
+				}
+			case poset.TransactionType_PEER_REMOVE:
+				err = c.validators.RemovePeerByPubKey(tx.Peer.PubKeyHex)
+			}
+			if err != nil {
+				return fmt.Errorf("applying %s of %s: %s", tx.Type, tx.Peer.PubKeyHex, err)
+			}
+			c.logger.WithFields(logrus.Fields{
+				"type": tx.Type.String(),
+				"peer": tx.Peer.PubKeyHex,
+			}).Info("Validator set changed")
+		}
+	}
+	return nil
+}
+
+// VerifyTx checks that tx is part of the verified block of the given index
+func (c *Client) VerifyTx(index int64, tx []byte, proof *crypto.SimpleProof) error {
+	h, err := c.Header(index)
+	if err != nil {
+		return err
+	}
+	if proof == nil || proof.Total != h.TxCount || !proof.Verify(h.TxRoot, crypto.SHA256(tx)) {
+		return fmt.Errorf("invalid proof of transaction in block %d", index)
+	}
+	return nil
+}
+
+// VerifyTxProof checks a proof served by the /proof/tx endpoint of a node
+// against the verified block it names
+func (c *Client) VerifyTxProof(tx []byte, proof *poset.TxProof) error {
+	if proof == nil {
+		return fmt.Errorf("no proof")
+	}
+	h, err := c.Header(proof.Block)
+	if err != nil {
+		return err
+	}
+	return poset.VerifyTxProof(h.TxRoot, h.TxCount, tx, proof)
+}
+
+// TxRoot returns the Merkle root of transactions
+func TxRoot(txs [][]byte) []byte {
+	return poset.TxRoot(txs)
+}
+
+// ProveTx returns the Merkle proof of the transaction at position i of block,
+// for full nodes serving light clients
+func ProveTx(block poset.Block, i int) (*crypto.SimpleProof, error) {
+	txs := block.Transactions()
+	if i < 0 || i >= len(txs) {
+		return nil, fmt.Errorf("block %d has %d transactions", block.Index(), len(txs))
+	}
+	return crypto.NewSimpleProof(txHashes(txs), i), nil
+}
+
+func txHashes(txs [][]byte) [][]byte {
+	hashes := make([][]byte, len(txs))
+	for i, tx := range txs {
+		hashes[i] = crypto.SHA256(tx)
+	}
+	return hashes
+}
@@ -0,0 +1,124 @@
+package lightclient
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+func pubKeyHex(key *ecdsa.PrivateKey) string {
+	return fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey))
+}
+
+func newKeys(t *testing.T, n int) []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		key, err := crypto.GenerateECDSAKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// newBlock returns a block of the frame of round index+1, signed by signers
+func newBlock(t *testing.T, index int64, txs [][]byte, itxs []poset.InternalTransaction, signers []*ecdsa.PrivateKey) (poset.Block, poset.Frame) {
+	event := poset.NewEvent(txs, itxs, nil, []string{"", ""}, []byte{byte(index)}, index, nil)
+	frame := poset.Frame{Round: index + 1, Events: []*poset.EventMessage{&event.Message}}
+	block, err := poset.NewBlockFromFrame(index, frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range signers {
+		sig, err := block.Sign(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block.SetSignature(sig)
+	}
+	return block, frame
+}
+
+func TestClient(t *testing.T) {
+	keys := newKeys(t, 4)
+	validators := peers.NewPeers()
+	for _, key := range keys[:3] {
+		validators.AddPeer(peers.NewPeer(pubKeyHex(key), ""))
+	}
+	c := New(validators, nil)
+
+	// one signature out of three validators is not enough
+	block, frame := newBlock(t, 0, [][]byte{[]byte("tx")}, nil, keys[:1])
+	if _, err := c.Commit(block, frame); err == nil {
+		t.Fatal("block with too few signatures should be refused")
+	}
+
+	// block 0 adds the fourth validator
+	join := poset.NewInternalTransaction(poset.TransactionType_PEER_ADD, *peers.NewPeer(pubKeyHex(keys[3]), ""))
+	block, frame = newBlock(t, 0, [][]byte{[]byte("tx")}, []poset.InternalTransaction{join}, keys[:2])
+	if _, err := c.Commit(block, frame); err != nil {
+		t.Fatal(err)
+	}
+	if c.Validators().Validators() != 4 {
+		t.Fatalf("expected 4 validators, got %d", c.Validators().Validators())
+	}
+
+	// the new validator counts for block 1
+	txs := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+	block, frame = newBlock(t, 1, txs, nil, []*ecdsa.PrivateKey{keys[0], keys[1], keys[3]})
+	if _, err := c.Commit(block, frame); err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := ProveTx(block, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.VerifyTx(1, []byte("c"), proof); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.VerifyTx(1, []byte("d"), proof); err == nil {
+		t.Fatal("proof of another transaction should be refused")
+	}
+
+	// gaps and forged frames are refused
+	block, frame = newBlock(t, 3, nil, nil, keys[:2])
+	if _, err := c.Commit(block, frame); !lerrors.Is(err, lerrors.TooFar) {
+		t.Fatalf("expected a gap error, got %v", err)
+	}
+	block, _ = newBlock(t, 2, nil, nil, keys[:2])
+	_, frame = newBlock(t, 2, [][]byte{[]byte("forged")}, nil, nil)
+	if _, err := c.Commit(block, frame); err == nil {
+		t.Fatal("frame not matching the block should be refused")
+	}
+}
+
+func TestVerifyBlockWeights(t *testing.T) {
+	keys := newKeys(t, 4)
+	validators := peers.NewPeers()
+	for i, key := range keys {
+		peer := peers.NewPeer(pubKeyHex(key), "")
+		if i == 0 {
+			peer.Stake = 6
+		}
+		validators.AddPeer(peer)
+	}
+	c := New(validators, nil)
+
+	// the validator holding 6 of the 9 stakes is trusted on its own
+	block, _ := newBlock(t, 0, nil, nil, keys[:1])
+	if err := c.VerifyBlock(block); err != nil {
+		t.Fatal(err)
+	}
+	// three validators holding 3 of the 9 stakes are not
+	block, _ = newBlock(t, 0, nil, nil, keys[1:])
+	if err := c.VerifyBlock(block); err == nil {
+		t.Fatal("block signed by a third of the stakes should be refused")
+	}
+}
@@ -0,0 +1,88 @@
+package lightclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// Source provides the blocks and frames to verify
+type Source interface {
+	GetBlock(index int64) (poset.Block, error)
+	GetFrame(round int64) (poset.Frame, error)
+}
+
+// Sync verifies the blocks of source following the last verified one, until
+// the source has no more, and returns the index of the last verified block.
+// from is the first block to verify when none was verified yet.
+func (c *Client) Sync(source Source, from int64) (int64, error) {
+	next := c.Last() + 1
+	if next == 0 {
+		next = from
+	}
+	for ; ; next++ {
+		block, err := source.GetBlock(next)
+		if lerrors.Is(err, lerrors.KeyNotFound) {
+			return c.Last(), nil
+		}
+		if err != nil {
+			return c.Last(), err
+		}
+		frame, err := source.GetFrame(block.RoundReceived())
+		if err != nil {
+			return c.Last(), err
+		}
+		if _, err := c.Commit(block, frame); err != nil {
+			return c.Last(), err
+		}
+	}
+}
+
+// HTTPSource reads blocks and frames from the HTTP service of a node
+type HTTPSource struct {
+	addr   string
+	client *http.Client
+}
+
+// NewHTTPSource returns a source reading the service at addr (host:port)
+func NewHTTPSource(addr string, timeout time.Duration) *HTTPSource {
+	return &HTTPSource{
+		addr:   addr,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetBlock implements Source
+func (s *HTTPSource) GetBlock(index int64) (poset.Block, error) {
+	var block poset.Block
+	err := s.get(fmt.Sprintf("/block/%d", index), &block)
+	return block, err
+}
+
+// GetFrame implements Source
+func (s *HTTPSource) GetFrame(round int64) (poset.Frame, error) {
+	var frame poset.Frame
+	err := s.get(fmt.Sprintf("/frame/%d", round), &frame)
+	return frame, err
+}
+
+func (s *HTTPSource) get(path string, out interface{}) error {
+	resp, err := s.client.Get("http://" + s.addr + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return json.NewDecoder(resp.Body).Decode(out)
+	case http.StatusNotFound:
+		return lerrors.New(lerrors.KeyNotFound, "%s not found", path)
+	}
+	msg, _ := ioutil.ReadAll(resp.Body)
+	return fmt.Errorf("%s: %s: %s", path, resp.Status, msg)
+}
@@ -0,0 +1,205 @@
+package lachesis_log
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ModuleField is the entry field naming the module which emitted the entry.
+// It selects the per-module level.
+const ModuleField = "module"
+
+// Config is the logging configuration block
+type Config struct {
+	// Format is either "text" or "json"
+	Format string `mapstructure:"log-format"`
+	// File duplicates the output into a rotated file
+	File       string        `mapstructure:"log-file"`
+	MaxSize    int64         `mapstructure:"log-max-size"`
+	MaxAge     time.Duration `mapstructure:"log-max-age"`
+	MaxBackups int           `mapstructure:"log-max-backups"`
+	// Modules overrides the level per module, e.g. "poset=info,node=debug"
+	Modules string `mapstructure:"log-modules"`
+	// SampleRate keeps one of every SampleRate debug entries with the same
+	// message. 0 or 1 keeps them all.
+	SampleRate uint64 `mapstructure:"log-sample-rate"`
+}
+
+// DefaultConfig returns the configuration of plain text logs on the
+// standard output
+func DefaultConfig() Config {
+	return Config{
+		Format:     "text",
+		MaxSize:    100 * 1024 * 1024,
+		MaxAge:     24 * time.Hour,
+		MaxBackups: 7,
+	}
+}
+
+// Validate checks the format, the rotation limits and the module levels
+func (c Config) Validate() error {
+	switch c.Format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("log-format must be text or json, got %q", c.Format)
+	}
+	if c.MaxSize < 0 || c.MaxAge < 0 || c.MaxBackups < 0 {
+		return fmt.Errorf("log-max-size, log-max-age and log-max-backups must not be negative")
+	}
+	if _, err := ParseModules(c.Modules); err != nil {
+		return fmt.Errorf("log-modules: %s", err)
+	}
+	return nil
+}
+
+// ParseModules parses a "module=level,module=level" list
+func ParseModules(modules string) (map[string]logrus.Level, error) {
+	res := make(map[string]logrus.Level)
+	for _, item := range strings.Split(modules, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid module level %q, expected module=level", item)
+		}
+		level, err := logrus.ParseLevel(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, err
+		}
+		res[strings.TrimSpace(kv[0])] = level
+	}
+	return res, nil
+}
+
+// Apply configures the formatter, the output and the levels of logger.
+// The current level of logger is the default level of the modules which
+// are not listed in Modules.
+func (c Config) Apply(logger *logrus.Logger) (io.Closer, error) {
+	modules, err := ParseModules(c.Modules)
+	if err != nil {
+		return nil, err
+	}
+
+	var base logrus.Formatter
+	switch c.Format {
+	case "", "text":
+		base = &logrus.TextFormatter{}
+	case "json":
+		base = &logrus.JSONFormatter{}
+	default:
+		return nil, fmt.Errorf("unknown log format %q", c.Format)
+	}
+
+	var closer io.Closer
+	if c.File != "" {
+		file, err := NewRotatingFile(c.File, c.MaxSize, c.MaxAge, c.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		logger.Out = io.MultiWriter(logger.Out, file)
+		closer = file
+	}
+
+	// Entries must reach the formatter to be filtered per module, so the
+	// logger itself lets the most verbose level through
+	level := logger.Level
+	for _, l := range modules {
+		if l > logger.Level {
+			logger.Level = l
+		}
+	}
+
+	logger.Formatter = &FilterFormatter{
+		Formatter:  base,
+		Level:      level,
+		Modules:    modules,
+		SampleRate: c.SampleRate,
+	}
+
+	return closer, nil
+}
+
+// FilterFormatter drops the entries above the level of their module and
+// samples debug entries before handing the others to Formatter
+type FilterFormatter struct {
+	logrus.Formatter
+	Level      logrus.Level
+	Modules    map[string]logrus.Level
+	SampleRate uint64
+
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// Format implements logrus.Formatter
+func (f *FilterFormatter) Format(e *logrus.Entry) ([]byte, error) {
+	if !f.enabled(e) || !f.sampled(e) {
+		return nil, nil
+	}
+	return f.Formatter.Format(e)
+}
+
+// SetLevel changes the default level of logger. With per-module levels, the
+// level of the modules which are not listed changes, and the logger lets the
+// most verbose of the levels through.
+func SetLevel(logger *logrus.Logger, level logrus.Level) {
+	f, ok := logger.Formatter.(*FilterFormatter)
+	if !ok {
+		logger.SetLevel(level)
+		return
+	}
+	f.mu.Lock()
+	f.Level = level
+	for _, l := range f.Modules {
+		if l > level {
+			level = l
+		}
+	}
+	f.mu.Unlock()
+	logger.SetLevel(level)
+}
+
+// GetLevel returns the default level of logger, the level of the modules
+// which are not listed in the per-module levels
+func GetLevel(logger *logrus.Logger) logrus.Level {
+	if f, ok := logger.Formatter.(*FilterFormatter); ok {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return f.Level
+	}
+	return logger.GetLevel()
+}
+
+func (f *FilterFormatter) enabled(e *logrus.Entry) bool {
+	f.mu.Lock()
+	level := f.Level
+	f.mu.Unlock()
+	if module, ok := e.Data[ModuleField].(string); ok {
+		if l, ok := f.Modules[module]; ok {
+			level = l
+		}
+	}
+	return e.Level <= level
+}
+
+func (f *FilterFormatter) sampled(e *logrus.Entry) bool {
+	if f.SampleRate <= 1 || e.Level < logrus.DebugLevel {
+		return true
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.counts == nil {
+		f.counts = make(map[string]uint64)
+	}
+	n := f.counts[e.Message]
+	f.counts[e.Message] = n + 1
+	return n%f.SampleRate == 0
+}
@@ -0,0 +1,130 @@
+package lachesis_log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestParseModules(t *testing.T) {
+	modules, err := ParseModules("poset=info, node=debug,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modules) != 2 || modules["poset"] != logrus.InfoLevel || modules["node"] != logrus.DebugLevel {
+		t.Fatalf("unexpected modules %v", modules)
+	}
+
+	if _, err := ParseModules("poset"); err == nil {
+		t.Fatal("missing level should fail")
+	}
+	if _, err := ParseModules("poset=loud"); err == nil {
+		t.Fatal("unknown level should fail")
+	}
+}
+
+func TestModuleLevelsAndSampling(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &out
+	logger.Level = logrus.InfoLevel
+
+	conf := DefaultConfig()
+	conf.Format = "json"
+	conf.Modules = "node=debug"
+	conf.SampleRate = 2
+	if _, err := conf.Apply(logger); err != nil {
+		t.Fatal(err)
+	}
+
+	node := logger.WithField(ModuleField, "node")
+	poset := logger.WithField(ModuleField, "poset")
+	for i := 0; i < 4; i++ {
+		node.Debug("node debug")
+	}
+	poset.Debug("poset debug")
+	poset.Info("poset info")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), out.String())
+	}
+	if strings.Count(out.String(), "node debug") != 2 {
+		t.Fatal("debug entries of node should be sampled 1 in 2")
+	}
+	if strings.Contains(out.String(), "poset debug") {
+		t.Fatal("debug entries of poset should be filtered")
+	}
+	if !strings.HasPrefix(lines[0], "{") {
+		t.Fatalf("expected json output, got %q", lines[0])
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lachesis_log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "lachesis.log")
+	r, err := NewRotatingFile(path, 10, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for i := 0; i < 5; i++ {
+		if _, err := r.Write([]byte("0123456789")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %d", len(backups))
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "0123456789" {
+		t.Fatalf("unexpected current file %q", data)
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &out
+	logger.Level = logrus.WarnLevel
+
+	conf := DefaultConfig()
+	conf.Modules = "net=debug"
+	if _, err := conf.Apply(logger); err != nil {
+		t.Fatal(err)
+	}
+
+	SetLevel(logger, logrus.InfoLevel)
+	if l := GetLevel(logger); l != logrus.InfoLevel {
+		t.Fatalf("expected the default level info, got %s", l)
+	}
+	logger.WithField(ModuleField, "net").Debug("net debug")
+	logger.WithField(ModuleField, "poset").Info("poset info")
+	logger.WithField(ModuleField, "poset").Debug("poset debug")
+
+	if !strings.Contains(out.String(), "net debug") {
+		t.Fatal("the level of net should not change")
+	}
+	if !strings.Contains(out.String(), "poset info") || strings.Contains(out.String(), "poset debug") {
+		t.Fatalf("poset should log from info, got %q", out.String())
+	}
+}
@@ -0,0 +1,145 @@
+// Package memory shares a memory budget between the components of a node
+// (caches, mempool, sync buffers). Components estimate their own usage; when
+// the total goes over the budget they are asked to shrink, lowest priority
+// first, and they grow back once there is room again.
+package memory
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Component is a memory consumer managed by a Budget
+type Component interface {
+	// Usage returns the estimated number of bytes held
+	Usage() int64
+	// Shrink tries to release memory down to target bytes
+	Shrink(target int64)
+	// Grow lets the component use more memory again, up to its configured
+	// capacity
+	Grow()
+}
+
+// Usage is the reported allocation of a component
+type Usage struct {
+	Name     string
+	Priority int
+	Bytes    int64
+}
+
+type entry struct {
+	name      string
+	priority  int
+	component Component
+}
+
+// Budget enforces a memory limit over its components
+type Budget struct {
+	sync.Mutex
+	limit      int64
+	components []entry
+	exceeded   int32
+}
+
+// NewBudget creates a Budget of limit bytes. A limit of 0 disables shedding
+// but still reports the usage.
+func NewBudget(limit int64) *Budget {
+	return &Budget{limit: limit}
+}
+
+// Limit returns the budget in bytes
+func (b *Budget) Limit() int64 {
+	return b.limit
+}
+
+// Register adds a component. Components with a lower priority are shrunk
+// first and grown back last.
+func (b *Budget) Register(name string, priority int, c Component) {
+	b.Lock()
+	defer b.Unlock()
+	b.components = append(b.components, entry{
+		name:      name,
+		priority:  priority,
+		component: c,
+	})
+	sort.SliceStable(b.components, func(i, j int) bool {
+		return b.components[i].priority < b.components[j].priority
+	})
+}
+
+// Report returns the usage of every component, in shedding order
+func (b *Budget) Report() []Usage {
+	b.Lock()
+	defer b.Unlock()
+	res := make([]Usage, len(b.components))
+	for i, e := range b.components {
+		res[i] = Usage{
+			Name:     e.name,
+			Priority: e.priority,
+			Bytes:    e.component.Usage(),
+		}
+	}
+	return res
+}
+
+// Total returns the sum of the usage of the components
+func (b *Budget) Total() int64 {
+	var total int64
+	for _, u := range b.Report() {
+		total += u.Bytes
+	}
+	return total
+}
+
+// Exceeded reports whether the budget was still exceeded after the last
+// Rebalance, in which case new allocations should be refused
+func (b *Budget) Exceeded() bool {
+	return atomic.LoadInt32(&b.exceeded) == 1
+}
+
+// Rebalance shrinks the components while the total is over the limit, or
+// grows them back when the total is under 3/4 of the limit. It returns the
+// total after rebalancing.
+func (b *Budget) Rebalance() int64 {
+	b.Lock()
+	defer b.Unlock()
+
+	usage := make([]int64, len(b.components))
+	var total int64
+	for i, e := range b.components {
+		usage[i] = e.component.Usage()
+		total += usage[i]
+	}
+	if b.limit <= 0 {
+		return total
+	}
+
+	if total > b.limit {
+		for i, e := range b.components {
+			excess := total - b.limit
+			if excess <= 0 {
+				break
+			}
+			target := usage[i] - excess
+			if target < 0 {
+				target = 0
+			}
+			e.component.Shrink(target)
+			after := e.component.Usage()
+			total -= usage[i] - after
+			usage[i] = after
+		}
+	} else if total < b.limit*3/4 {
+		for i := len(b.components) - 1; i >= 0; i-- {
+			b.components[i].component.Grow()
+		}
+	}
+
+	var exceeded int32
+	if total > b.limit {
+		exceeded = 1
+	}
+	atomic.StoreInt32(&b.exceeded, exceeded)
+	return total
+}
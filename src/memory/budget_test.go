@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/hashicorp/golang-lru"
+)
+
+func newCache(t *testing.T, size int) *lru.Cache {
+	c, err := lru.New(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < size; i++ {
+		c.Add(i, i)
+	}
+	return c
+}
+
+func TestBudgetShedsInPriorityOrder(t *testing.T) {
+	ancestors := newCache(t, 100)
+	events := newCache(t, 100)
+	pool := int64(500)
+
+	b := NewBudget(1500)
+	b.Register("events", 2, NewLRU(func() *lru.Cache { return events }, 10, 10, 100))
+	b.Register("ancestors", 1, NewLRU(func() *lru.Cache { return ancestors }, 10, 10, 100))
+	b.Register("mempool", 3, Gauge(func() int64 { return pool }))
+
+	if total := b.Total(); total != 2500 {
+		t.Fatalf("expected 2500 bytes, got %d", total)
+	}
+
+	// ancestors give up 1000 bytes but can't go under 10 items
+	if total := b.Rebalance(); total != 1500 {
+		t.Fatalf("expected 1500 bytes after rebalance, got %d", total)
+	}
+	if ancestors.Len() != 10 || events.Len() != 90 {
+		t.Fatalf("unexpected cache lengths %d %d", ancestors.Len(), events.Len())
+	}
+	if b.Exceeded() {
+		t.Fatal("budget should be met")
+	}
+
+	report := b.Report()
+	if report[0].Name != "ancestors" || report[2].Name != "mempool" {
+		t.Fatalf("unexpected report order %v", report)
+	}
+
+	// the mempool can't shrink
+	pool = 5000
+	b.Rebalance()
+	if !b.Exceeded() {
+		t.Fatal("budget should be exceeded")
+	}
+
+	// caches grow back once there is room
+	pool = 0
+	b.Rebalance()
+	l := b.components[0].component.(*LRU)
+	if l.Size() != 20 {
+		t.Fatalf("expected ancestors to double to 20, got %d", l.Size())
+	}
+}
+
+func TestLRUReplacedCache(t *testing.T) {
+	cache := newCache(t, 100)
+	l := NewLRU(func() *lru.Cache { return cache }, 10, 1, 100)
+	l.Shrink(100)
+	if l.Size() != 10 {
+		t.Fatalf("expected 10, got %d", l.Size())
+	}
+
+	cache = newCache(t, 100)
+	if l.Size() != 100 {
+		t.Fatalf("a replaced cache should be assumed full size, got %d", l.Size())
+	}
+}
+
+func TestLimit(t *testing.T) {
+	l := NewLimit(10, 5, 100)
+	l.Shrink(200)
+	if l.Value() != 20 {
+		t.Fatalf("expected 20, got %d", l.Value())
+	}
+	l.Shrink(0)
+	if l.Value() != 5 {
+		t.Fatalf("expected min 5, got %d", l.Value())
+	}
+	for i := 0; i < 10; i++ {
+		l.Grow()
+	}
+	if l.Value() != 100 {
+		t.Fatalf("expected max 100, got %d", l.Value())
+	}
+
+	l.SetMax(50)
+	if l.Value() != 50 {
+		t.Fatalf("expected new max 50, got %d", l.Value())
+	}
+	l.SetMax(200)
+	if l.Value() != 200 {
+		t.Fatalf("expected raised max 200, got %d", l.Value())
+	}
+}
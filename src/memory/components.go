@@ -0,0 +1,193 @@
+package memory
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/golang-lru"
+)
+
+// LRU manages the size of an LRU cache whose items weigh about ItemSize
+// bytes. It never shrinks the cache under MinItems entries. The cache is
+// obtained through a function as its owner may replace it; a new cache is
+// assumed to have MaxItems capacity.
+type LRU struct {
+	ItemSize int64
+	MinItems int
+	MaxItems int
+
+	sync.Mutex
+	cache   func() *lru.Cache
+	current *lru.Cache
+	size    int
+}
+
+// NewLRU creates an LRU component over the cache returned by cache
+func NewLRU(cache func() *lru.Cache, itemSize int64, minItems, maxItems int) *LRU {
+	return &LRU{
+		ItemSize: itemSize,
+		MinItems: minItems,
+		MaxItems: maxItems,
+		cache:    cache,
+	}
+}
+
+// get returns the current cache, tracking its replacement
+func (l *LRU) get() *lru.Cache {
+	c := l.cache()
+	if c != l.current {
+		l.current = c
+		l.size = l.MaxItems
+	}
+	return c
+}
+
+// Usage implements Component
+func (l *LRU) Usage() int64 {
+	l.Lock()
+	defer l.Unlock()
+	c := l.get()
+	if c == nil {
+		return 0
+	}
+	return int64(c.Len()) * l.ItemSize
+}
+
+// Size returns the current capacity of the cache
+func (l *LRU) Size() int {
+	l.Lock()
+	defer l.Unlock()
+	l.get()
+	return l.size
+}
+
+// Shrink implements Component
+func (l *LRU) Shrink(target int64) {
+	l.Lock()
+	defer l.Unlock()
+	size := int(target / l.ItemSize)
+	if size < l.MinItems {
+		size = l.MinItems
+	}
+	if size < 1 {
+		size = 1
+	}
+	if c := l.get(); c != nil && size < l.size {
+		c.Resize(size)
+		l.size = size
+	}
+}
+
+// Grow implements Component, doubling the capacity up to MaxItems
+func (l *LRU) Grow() {
+	l.Lock()
+	defer l.Unlock()
+	c := l.get()
+	size := l.size * 2
+	if size > l.MaxItems {
+		size = l.MaxItems
+	}
+	if c != nil && size > l.size {
+		c.Resize(size)
+		l.size = size
+	}
+}
+
+// MaxSize returns the capacity the cache grows back to
+func (l *LRU) MaxSize() int {
+	l.Lock()
+	defer l.Unlock()
+	return l.MaxItems
+}
+
+// SetMaxItems changes the capacity of the cache. It is resized at once to
+// max, the budget shrinking it again when needed.
+func (l *LRU) SetMaxItems(max int) {
+	l.Lock()
+	defer l.Unlock()
+	l.MaxItems = max
+	if l.MinItems > max {
+		l.MinItems = max
+	}
+	if c := l.get(); c != nil {
+		c.Resize(max)
+		l.size = max
+	}
+}
+
+// Limit manages a numeric limit, e.g. the number of events per sync, whose
+// units weigh about UnitSize bytes. Usage is the worst case of the current
+// limit.
+type Limit struct {
+	UnitSize int64
+	Min      int64
+	Max      int64
+
+	value int64
+}
+
+// NewLimit creates a Limit starting at max
+func NewLimit(unitSize, min, max int64) *Limit {
+	return &Limit{
+		UnitSize: unitSize,
+		Min:      min,
+		Max:      max,
+		value:    max,
+	}
+}
+
+// Value returns the current limit
+func (l *Limit) Value() int64 {
+	return atomic.LoadInt64(&l.value)
+}
+
+// Usage implements Component
+func (l *Limit) Usage() int64 {
+	return l.Value() * l.UnitSize
+}
+
+// Shrink implements Component
+func (l *Limit) Shrink(target int64) {
+	v := target / l.UnitSize
+	if v < l.Min {
+		v = l.Min
+	}
+	if v < l.Value() {
+		atomic.StoreInt64(&l.value, v)
+	}
+}
+
+// Grow implements Component, doubling the limit up to Max
+func (l *Limit) Grow() {
+	v := l.Value() * 2
+	if max := atomic.LoadInt64(&l.Max); v > max {
+		v = max
+	}
+	atomic.StoreInt64(&l.value, v)
+}
+
+// SetMax changes the maximum of the limit. The current value follows it,
+// unless the budget shrank it below the former maximum.
+func (l *Limit) SetMax(max int64) {
+	old := atomic.SwapInt64(&l.Max, max)
+	if v := l.Value(); v > max || v == old {
+		atomic.StoreInt64(&l.value, max)
+	}
+}
+
+// Gauge reports the usage of a component which cannot release memory on
+// demand, such as a mempool. It still counts against the budget, so the
+// other components shrink to make room, and Budget.Exceeded tells its owner
+// to refuse new allocations.
+type Gauge func() int64
+
+// Usage implements Component
+func (g Gauge) Usage() int64 {
+	return g()
+}
+
+// Shrink implements Component
+func (g Gauge) Shrink(int64) {}
+
+// Grow implements Component
+func (g Gauge) Grow() {}
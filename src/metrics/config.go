@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Config selects the metrics sinks
+type Config struct {
+	// Sinks is a comma separated list of prometheus, statsd and expvar
+	Sinks      string `mapstructure:"metrics"`
+	StatsdAddr string `mapstructure:"metrics-statsd-addr"`
+	Prefix     string `mapstructure:"metrics-prefix"`
+	// Addr, if set, serves the scraped sinks on their own listener, apart
+	// from the HTTP service. It enables the prometheus sink.
+	Addr string `mapstructure:"metrics-addr"`
+}
+
+// DefaultConfig returns a configuration without any sink
+func DefaultConfig() Config {
+	return Config{
+		StatsdAddr: "127.0.0.1:8125",
+		Prefix:     "lachesis",
+	}
+}
+
+// Validate checks the sink names and the statsd address
+func (c Config) Validate() error {
+	for _, name := range strings.Split(c.Sinks, ",") {
+		switch strings.TrimSpace(name) {
+		case "", "prometheus", "expvar":
+		case "statsd":
+			if _, _, err := net.SplitHostPort(c.StatsdAddr); err != nil {
+				return fmt.Errorf("metrics-statsd-addr must be a host:port address, got %q", c.StatsdAddr)
+			}
+		default:
+			return fmt.Errorf("metrics must list prometheus, statsd or expvar, got %q", name)
+		}
+	}
+	if c.Addr != "" {
+		if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+			return fmt.Errorf("metrics-addr must be a host:port address, got %q", c.Addr)
+		}
+	}
+	return nil
+}
+
+// NewSink builds the configured sinks. It returns Discard when none is
+// configured.
+func (c Config) NewSink() (Sink, error) {
+	var sinks Fanout
+	prometheus := false
+	for _, name := range strings.Split(c.Sinks, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "prometheus":
+			prometheus = true
+			sinks = append(sinks, NewPrometheusSink(c.Prefix))
+		case "statsd":
+			s, err := NewStatsdSink(c.StatsdAddr, c.Prefix)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, s)
+		case "expvar":
+			sinks = append(sinks, NewExpvarSink(c.Prefix))
+		default:
+			return nil, fmt.Errorf("unknown metrics sink %q", name)
+		}
+	}
+	if c.Addr != "" && !prometheus {
+		sinks = append(sinks, NewPrometheusSink(c.Prefix))
+	}
+
+	switch len(sinks) {
+	case 0:
+		return Discard{}, nil
+	case 1:
+		return sinks[0], nil
+	}
+	return sinks, nil
+}
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"expvar"
+	"net/http"
+)
+
+// ExpvarSink publishes the measurements as an expvar map. Samples are
+// published as <key>.count and <key>.sum.
+type ExpvarSink struct {
+	vars *expvar.Map
+}
+
+// NewExpvarSink publishes the map under name. expvar names are global, so
+// an existing map with the same name is reused.
+func NewExpvarSink(name string) *ExpvarSink {
+	if v, ok := expvar.Get(name).(*expvar.Map); ok {
+		return &ExpvarSink{vars: v}
+	}
+	return &ExpvarSink{vars: expvar.NewMap(name)}
+}
+
+// IncrCounter implements Sink
+func (e *ExpvarSink) IncrCounter(key string, delta int64) {
+	e.vars.Add(key, delta)
+}
+
+// SetGauge implements Sink
+func (e *ExpvarSink) SetGauge(key string, value float64) {
+	v := new(expvar.Float)
+	v.Set(value)
+	e.vars.Set(key, v)
+}
+
+// AddSample implements Sink
+func (e *ExpvarSink) AddSample(key string, value float64) {
+	e.vars.Add(key+".count", 1)
+	e.vars.AddFloat(key+".sum", value)
+}
+
+// Path implements HTTPSink
+func (e *ExpvarSink) Path() string {
+	return "/debug/vars"
+}
+
+// ServeHTTP implements http.Handler
+func (e *ExpvarSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	expvar.Handler().ServeHTTP(w, r)
+}
@@ -0,0 +1,135 @@
+// Package metrics is a small instrumentation facade. Node, poset, store and
+// net report through the package level functions, which forward to the
+// configured Sink (a no-op by default).
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Sink receives the measurements
+type Sink interface {
+	// IncrCounter adds delta to a monotonic counter
+	IncrCounter(key string, delta int64)
+	// SetGauge records the current value of a gauge
+	SetGauge(key string, value float64)
+	// AddSample records one observation of a distribution, e.g. a duration
+	// in milliseconds
+	AddSample(key string, value float64)
+}
+
+// HTTPSink is a Sink which is scraped over HTTP rather than pushing its
+// measurements
+type HTTPSink interface {
+	Sink
+	http.Handler
+	// Path is the URL path the sink is served at
+	Path() string
+}
+
+// Discard is a Sink ignoring everything
+type Discard struct{}
+
+// IncrCounter implements Sink
+func (Discard) IncrCounter(string, int64) {}
+
+// SetGauge implements Sink
+func (Discard) SetGauge(string, float64) {}
+
+// AddSample implements Sink
+func (Discard) AddSample(string, float64) {}
+
+// Fanout forwards every measurement to all its sinks
+type Fanout []Sink
+
+// IncrCounter implements Sink
+func (f Fanout) IncrCounter(key string, delta int64) {
+	for _, s := range f {
+		s.IncrCounter(key, delta)
+	}
+}
+
+// SetGauge implements Sink
+func (f Fanout) SetGauge(key string, value float64) {
+	for _, s := range f {
+		s.SetGauge(key, value)
+	}
+}
+
+// AddSample implements Sink
+func (f Fanout) AddSample(key string, value float64) {
+	for _, s := range f {
+		s.AddSample(key, value)
+	}
+}
+
+var (
+	globalLock sync.RWMutex
+	global     Sink = Discard{}
+)
+
+// SetSink replaces the sink used by the package level functions
+func SetSink(s Sink) {
+	if s == nil {
+		s = Discard{}
+	}
+	globalLock.Lock()
+	global = s
+	globalLock.Unlock()
+}
+
+// Global returns the sink used by the package level functions
+func Global() Sink {
+	globalLock.RLock()
+	defer globalLock.RUnlock()
+	return global
+}
+
+// Handlers returns the HTTP handlers of the scraped sinks, by path
+func Handlers(s Sink) map[string]http.Handler {
+	res := make(map[string]http.Handler)
+	var walk func(Sink)
+	walk = func(s Sink) {
+		switch t := s.(type) {
+		case Fanout:
+			for _, child := range t {
+				walk(child)
+			}
+		case HTTPSink:
+			res[t.Path()] = t
+		}
+	}
+	walk(s)
+	return res
+}
+
+// NewServer returns a server exposing the scraped sinks of s on addr
+func NewServer(addr string, s Sink) *http.Server {
+	mux := http.NewServeMux()
+	for path, h := range Handlers(s) {
+		mux.Handle(path, h)
+	}
+	return &http.Server{Addr: addr, Handler: mux}
+}
+
+// IncrCounter adds delta to a counter of the global sink
+func IncrCounter(key string, delta int64) {
+	Global().IncrCounter(key, delta)
+}
+
+// SetGauge sets a gauge of the global sink
+func SetGauge(key string, value float64) {
+	Global().SetGauge(key, value)
+}
+
+// AddSample adds a sample to the global sink
+func AddSample(key string, value float64) {
+	Global().AddSample(key, value)
+}
+
+// MeasureSince adds the milliseconds elapsed since start as a sample
+func MeasureSince(key string, start time.Time) {
+	Global().AddSample(key, float64(time.Since(start))/float64(time.Millisecond))
+}
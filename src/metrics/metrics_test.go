@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusSink(t *testing.T) {
+	p := NewPrometheusSink("lachesis")
+	p.IncrCounter("node.sync.errors", 2)
+	p.IncrCounter("node.sync.errors", 1)
+	p.SetGauge("poset.rounds.last_consensus", 42)
+	p.AddSample("node.commit", 1.5)
+	p.AddSample("node.commit", 2.5)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	out := w.Body.String()
+
+	for _, line := range []string{
+		"# TYPE lachesis_node_sync_errors counter",
+		"lachesis_node_sync_errors 3",
+		"lachesis_poset_rounds_last_consensus 42",
+		"lachesis_node_commit_sum 4",
+		"lachesis_node_commit_count 2",
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Fatalf("missing %q in\n%s", line, out)
+		}
+	}
+}
+
+func TestStatsdSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	s, err := NewStatsdSink(conn.LocalAddr().String(), "lachesis")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.IncrCounter("node.sync.errors", 1)
+	s.SetGauge("poset.rounds.last_consensus", 7)
+
+	buf := make([]byte, 512)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	for _, expected := range []string{
+		"lachesis.node.sync.errors:1|c",
+		"lachesis.poset.rounds.last_consensus:7|g",
+	} {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf[:n]) != expected {
+			t.Fatalf("expected %q, got %q", expected, buf[:n])
+		}
+	}
+}
+
+func TestConfigNewSink(t *testing.T) {
+	conf := DefaultConfig()
+	sink, err := conf.NewSink()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sink.(Discard); !ok {
+		t.Fatalf("expected Discard, got %T", sink)
+	}
+
+	conf.Sinks = "prometheus, expvar"
+	sink, err = conf.NewSink()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handlers := Handlers(sink)
+	if _, ok := handlers["/metrics"]; !ok {
+		t.Fatal("prometheus handler not found")
+	}
+	if _, ok := handlers["/debug/vars"]; !ok {
+		t.Fatal("expvar handler not found")
+	}
+
+	conf.Sinks = "graphite"
+	if _, err := conf.NewSink(); err == nil {
+		t.Fatal("unknown sink should fail")
+	}
+}
+
+func TestConfigAddr(t *testing.T) {
+	conf := DefaultConfig()
+	conf.Addr = "localhost"
+	if err := conf.Validate(); err == nil {
+		t.Fatal("metrics-addr without port should fail")
+	}
+
+	// a metrics address enables the prometheus sink
+	conf.Addr = "127.0.0.1:0"
+	if err := conf.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	sink, err := conf.NewSink()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.IncrCounter("poset.rounds.decided", 1)
+
+	w := httptest.NewRecorder()
+	NewServer(conf.Addr, sink).Handler.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.Contains(w.Body.String(), "lachesis_poset_rounds_decided 1\n") {
+		t.Fatalf("missing counter in\n%s", w.Body.String())
+	}
+}
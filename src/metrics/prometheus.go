@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type summary struct {
+	count uint64
+	sum   float64
+}
+
+// PrometheusSink keeps the measurements in memory and renders them in the
+// Prometheus text exposition format. Samples are exposed as summaries
+// without quantiles (_count and _sum).
+type PrometheusSink struct {
+	sync.RWMutex
+	prefix    string
+	counters  map[string]int64
+	gauges    map[string]float64
+	summaries map[string]*summary
+}
+
+// NewPrometheusSink creates a PrometheusSink naming the metrics prefix_key
+func NewPrometheusSink(prefix string) *PrometheusSink {
+	return &PrometheusSink{
+		prefix:    prefix,
+		counters:  make(map[string]int64),
+		gauges:    make(map[string]float64),
+		summaries: make(map[string]*summary),
+	}
+}
+
+// IncrCounter implements Sink
+func (p *PrometheusSink) IncrCounter(key string, delta int64) {
+	p.Lock()
+	p.counters[key] += delta
+	p.Unlock()
+}
+
+// SetGauge implements Sink
+func (p *PrometheusSink) SetGauge(key string, value float64) {
+	p.Lock()
+	p.gauges[key] = value
+	p.Unlock()
+}
+
+// AddSample implements Sink
+func (p *PrometheusSink) AddSample(key string, value float64) {
+	p.Lock()
+	s, ok := p.summaries[key]
+	if !ok {
+		s = &summary{}
+		p.summaries[key] = s
+	}
+	s.count++
+	s.sum += value
+	p.Unlock()
+}
+
+// Path implements HTTPSink
+func (p *PrometheusSink) Path() string {
+	return "/metrics"
+}
+
+// ServeHTTP implements http.Handler
+func (p *PrometheusSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprint(w, p.String())
+}
+
+// String renders the metrics in the text exposition format
+func (p *PrometheusSink) String() string {
+	p.RLock()
+	defer p.RUnlock()
+
+	var b strings.Builder
+	for _, key := range sortedKeys(p.counters) {
+		name := p.name(key)
+		fmt.Fprintf(&b, "# TYPE %s counter\n%s %d\n", name, name, p.counters[key])
+	}
+	for _, key := range sortedKeys(p.gauges) {
+		name := p.name(key)
+		fmt.Fprintf(&b, "# TYPE %s gauge\n%s %g\n", name, name, p.gauges[key])
+	}
+	for _, key := range sortedKeys(p.summaries) {
+		name := p.name(key)
+		s := p.summaries[key]
+		fmt.Fprintf(&b, "# TYPE %s summary\n%s_sum %g\n%s_count %d\n", name, name, s.sum, name, s.count)
+	}
+	return b.String()
+}
+
+// name converts a dotted key into a valid Prometheus metric name
+func (p *PrometheusSink) name(key string) string {
+	if p.prefix != "" {
+		key = p.prefix + "_" + key
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == ':':
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch t := m.(type) {
+	case map[string]int64:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]float64:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	case map[string]*summary:
+		for k := range t {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+)
+
+// StatsdSink pushes every measurement as a statsd datagram over UDP.
+// Sending is best effort: errors are dropped like the datagrams themselves.
+type StatsdSink struct {
+	prefix string
+	conn   net.Conn
+}
+
+// NewStatsdSink creates a StatsdSink sending to addr (host:port)
+func NewStatsdSink(addr, prefix string) (*StatsdSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("statsd %s: %v", addr, err)
+	}
+	return &StatsdSink{
+		prefix: prefix,
+		conn:   conn,
+	}, nil
+}
+
+// IncrCounter implements Sink
+func (s *StatsdSink) IncrCounter(key string, delta int64) {
+	s.send(key, fmt.Sprintf("%d|c", delta))
+}
+
+// SetGauge implements Sink
+func (s *StatsdSink) SetGauge(key string, value float64) {
+	s.send(key, fmt.Sprintf("%g|g", value))
+}
+
+// AddSample implements Sink
+func (s *StatsdSink) AddSample(key string, value float64) {
+	s.send(key, fmt.Sprintf("%g|ms", value))
+}
+
+// Close closes the UDP socket
+func (s *StatsdSink) Close() error {
+	return s.conn.Close()
+}
+
+func (s *StatsdSink) send(key, value string) {
+	if s.prefix != "" {
+		key = s.prefix + "." + key
+	}
+	s.conn.Write([]byte(key + ":" + value))
+}
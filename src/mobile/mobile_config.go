@@ -1,12 +1,18 @@
 package mobile
 
+import (
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/lachesis"
+)
+
 type MobileConfig struct {
 	Heartbeat  int    //heartbeat timeout in milliseconds
 	TCPTimeout int    //TCP timeout in milliseconds
 	MaxPool    int    //Max number of pooled connections
 	CacheSize  int    //Number of items in LRU cache
 	SyncLimit  int    //Max Events per sync
-	StoreType  string //inmem or badger
+	StoreType  string //inmem, badger, leveldb or hybrid
 	StorePath  string //File containing the Store DB
 }
 
@@ -40,3 +46,21 @@ func DefaultMobileConfig() *MobileConfig {
 		StorePath:  "",
 	}
 }
+
+// apply copies the settings onto the configuration of the engine, which
+// validates them on Init
+func (c *MobileConfig) apply(conf *lachesis.LachesisConfig) {
+	conf.NodeConfig.HeartbeatTimeout = time.Duration(c.Heartbeat) * time.Millisecond
+	conf.NodeConfig.TCPTimeout = time.Duration(c.TCPTimeout) * time.Millisecond
+	conf.NodeConfig.CacheSize = c.CacheSize
+	conf.NodeConfig.SyncLimit = int64(c.SyncLimit)
+	conf.MaxPool = c.MaxPool
+	conf.Store = c.StoreType == lachesis.StoreBadger || c.StoreType == lachesis.StoreLevelDB ||
+		c.StoreType == lachesis.StoreHybrid
+	if conf.Store {
+		conf.StoreType = c.StoreType
+	}
+	if c.StorePath != "" {
+		conf.DataDir = c.StorePath
+	}
+}
@@ -27,6 +27,13 @@ func New(privKey string,
 	config *MobileConfig) *Node {
 
 	lachesisConfig := lachesis.NewDefaultConfig()
+	if config == nil {
+		config = DefaultMobileConfig()
+	}
+	config.apply(lachesisConfig)
+	lachesisConfig.BindAddr = nodeAddr
+	// the app is embedded, there is no proxy to listen on
+	lachesisConfig.Standalone = true
 
 	lachesisConfig.Logger.WithFields(logrus.Fields{
 		"nodeAddr": nodeAddr,
@@ -1,10 +1,18 @@
 package net
 
-import "github.com/Fantom-foundation/go-lachesis/src/poset"
+import (
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
 
 type SyncRequest struct {
 	FromID int64
 	Known  map[int64]int64
+	// Chain is the poset the request is for, empty for the main one
+	Chain string `json:",omitempty"`
+	// Chunked asks for the first sync-limit events of a larger diff,
+	// instead of a response with SyncLimit set
+	Chunked bool `json:",omitempty"`
 }
 
 type SyncResponse struct {
@@ -12,6 +20,13 @@ type SyncResponse struct {
 	SyncLimit bool
 	Events    []poset.WireEvent
 	Known     map[int64]int64
+	Peers     *peers.PeerExchange
+	// Time is the clock of the responder, in Unix nanoseconds, used to
+	// detect clock drift
+	Time int64 `json:",omitempty"`
+	// More is set when Events is only the first chunk of the diff, the
+	// next request acknowledging it with its Known
+	More bool `json:",omitempty"`
 }
 
 //++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
@@ -19,6 +34,9 @@ type SyncResponse struct {
 type EagerSyncRequest struct {
 	FromID int64
 	Events []poset.WireEvent
+	Chain  string `json:",omitempty"`
+	// More is set on the chunks of a push but the last one
+	More bool `json:",omitempty"`
 }
 
 type EagerSyncResponse struct {
@@ -30,6 +48,7 @@ type EagerSyncResponse struct {
 
 type FastForwardRequest struct {
 	FromID int64
+	Chain  string `json:",omitempty"`
 }
 
 type FastForwardResponse struct {
@@ -37,4 +56,119 @@ type FastForwardResponse struct {
 	Block    poset.Block
 	Frame    poset.Frame
 	Snapshot []byte
+	// SnapshotHash is the SHA256 of the application snapshot of Block.
+	// Snapshots larger than a chunk are left out of the response and
+	// fetched with SnapshotChunk requests, each checked against its hash in
+	// SnapshotChunks.
+	SnapshotHash   []byte   `json:",omitempty"`
+	SnapshotChunks [][]byte `json:",omitempty"`
+	// Params is the governance state of the responder, which the requester
+	// adopts as it cannot replay the rounds before Block
+	Params *poset.NetworkParams `json:",omitempty"`
+}
+
+//++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+
+type SnapshotChunkRequest struct {
+	FromID     int64
+	BlockIndex int64
+	Chunk      int
+	Chain      string `json:",omitempty"`
+}
+
+type SnapshotChunkResponse struct {
+	FromID int64
+	Chunk  int
+	Data   []byte
+}
+
+//++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+
+type BlocksRequest struct {
+	FromID int64
+	// From is the index of the first block requested, and Limit the maximum
+	// number of blocks returned
+	From  int64
+	Limit int
+	Chain string `json:",omitempty"`
+}
+
+type BlocksResponse struct {
+	FromID int64
+	// Blocks are consecutive from the requested index, stopping before the
+	// first block the responder does not have
+	Blocks []poset.Block
+}
+
+//++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+
+type PeersRequest struct {
+	FromID int64
+	// Nonce is signed in the response, proving the key of the responder
+	Nonce []byte
+	Chain string `json:",omitempty"`
+}
+
+type PeersResponse struct {
+	FromID int64
+	// PubKeyHex is the key of the responder, which signed Peers
+	PubKeyHex string
+	Peers     *peers.PeerExchange
+}
+
+//++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+
+type SignatureGossipRequest struct {
+	FromID int64
+	// Signatures are block signatures pushed outside of the events
+	Signatures []poset.BlockSignature
+	Chain      string `json:",omitempty"`
+}
+
+type SignatureGossipResponse struct {
+	FromID int64
+	// Accepted is the number of signatures added to the sig pool
+	Accepted int
+}
+
+//++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+
+type HandshakeRequest struct {
+	NetworkID string
+	// PubKey is the key the dialer claims, and Nonce the challenge the
+	// listener signs to prove its own key
+	PubKey string `json:",omitempty"`
+	Nonce  []byte `json:",omitempty"`
+	// Compression is the codec the dialer asks for, see SetWireCompression
+	Compression string `json:",omitempty"`
+	// WireVersion is the newest wire format the dialer asks for, see
+	// SetWireVersion
+	WireVersion int `json:",omitempty"`
+	// AdvertiseAddr is the address the dialer is reachable at, see
+	// SetAdvertiseAddr
+	AdvertiseAddr string `json:",omitempty"`
+}
+
+type HandshakeResponse struct {
+	NetworkID string
+	PubKey    string `json:",omitempty"`
+	// Nonce is the challenge the dialer signs in its IdentityProof
+	Nonce     []byte `json:",omitempty"`
+	Signature string `json:",omitempty"`
+	// Compression is the codec accepted by the listener, none when empty
+	Compression string `json:",omitempty"`
+	// WireVersion is the wire format of the connection, WireVersionJSON when
+	// 0
+	WireVersion int `json:",omitempty"`
+	// AdvertiseAddr is the address the listener is reachable at
+	AdvertiseAddr string `json:",omitempty"`
+}
+
+// IdentityProof completes the handshake of a dialer with the signature of
+// the nonce of the listener
+type IdentityProof struct {
+	Signature string
+}
+
+type IdentityProofResponse struct {
 }
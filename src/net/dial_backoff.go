@@ -0,0 +1,237 @@
+package net
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// Default settings of SetDialBackoff
+const (
+	DefaultDialBackoff    = time.Second
+	DefaultDialBackoffMax = time.Minute
+	DefaultDialBanAfter   = 10
+)
+
+// ConnState is the state of the outbound connections of a transport to an
+// address. Failures are the consecutive RPCs which failed to connect or
+// broke their connection, and no connection is attempted before RetryAt.
+type ConnState struct {
+	Target        string
+	Pooled        int
+	Failures      int
+	TotalFailures int
+	RetryAt       time.Time `json:",omitempty"`
+	LastError     string    `json:",omitempty"`
+	Banned        bool
+}
+
+// Connections is implemented by the transports tracking the failures of
+// their outbound connections
+type Connections interface {
+	// ConnStates returns the state of the connections by address
+	ConnStates() []ConnState
+	// ResetConn forgets the failures of target and lifts its ban, it
+	// returns false when there was neither
+	ResetConn(target string) bool
+}
+
+// dialState is the failure record of an address
+type dialState struct {
+	failures int
+	total    int
+	retryAt  time.Time
+	lastErr  string
+}
+
+// dialBackoff delays the connections to the addresses which keep failing,
+// exponentially from base to max, and bans them after banAfter consecutive
+// failures
+type dialBackoff struct {
+	base        time.Duration
+	max         time.Duration
+	banAfter    int
+	banDuration time.Duration
+
+	states map[string]*dialState
+	lock   sync.Mutex
+}
+
+// SetDialBackoff makes the transport wait before connecting again to an
+// address whose connection failed, base after the first failure and twice
+// longer after every other up to max, and ban the address from the ban list
+// for banDuration after banAfter consecutive failures. A base of 0 disables
+// the backoff, a banAfter of 0 the bans.
+func (n *NetworkTransport) SetDialBackoff(base, max time.Duration, banAfter int, banDuration time.Duration) {
+	if base <= 0 {
+		n.dials = nil
+		return
+	}
+	if max < base {
+		max = base
+	}
+	n.dials = &dialBackoff{
+		base:        base,
+		max:         max,
+		banAfter:    banAfter,
+		banDuration: banDuration,
+		states:      make(map[string]*dialState),
+	}
+}
+
+// checkDial refuses the connections to the addresses which are banned or
+// backing off
+func (n *NetworkTransport) checkDial(target string) error {
+	if n.bans != nil && n.bans.IsBanned(target) {
+		return fmt.Errorf("%s is banned", target)
+	}
+	if n.dials == nil {
+		return nil
+	}
+	n.dials.lock.Lock()
+	defer n.dials.lock.Unlock()
+	if s, ok := n.dials.states[target]; ok && time.Now().Before(s.retryAt) {
+		metrics.IncrCounter("net.dial.backoff", 1)
+		return fmt.Errorf("%s is backing off until %s after %d failures: %s",
+			target, s.retryAt.Format(time.RFC3339), s.failures, s.lastErr)
+	}
+	return nil
+}
+
+// dialSucceeded resets the failures of target
+func (n *NetworkTransport) dialSucceeded(target string) {
+	if n.dials == nil {
+		return
+	}
+	n.dials.lock.Lock()
+	defer n.dials.lock.Unlock()
+	if s, ok := n.dials.states[target]; ok {
+		s.failures = 0
+		s.retryAt = time.Time{}
+	}
+}
+
+// dialFailed records a failure of target, delays its next connection and
+// bans it after too many
+func (n *NetworkTransport) dialFailed(target string, err error) {
+	metrics.IncrCounter("net.dial.failures", 1)
+	if n.dials == nil {
+		return
+	}
+	d := n.dials
+	d.lock.Lock()
+	s, ok := d.states[target]
+	if !ok {
+		s = &dialState{}
+		d.states[target] = s
+	}
+	s.failures++
+	s.total++
+	s.lastErr = err.Error()
+	backoff := d.base << uint(s.failures-1)
+	if backoff > d.max || backoff <= 0 {
+		backoff = d.max
+	}
+	s.retryAt = time.Now().Add(backoff)
+	failures := s.failures
+	ban := d.banAfter > 0 && failures >= d.banAfter && n.bans != nil
+	if ban {
+		// the ban takes over the backoff
+		s.failures = 0
+		s.retryAt = time.Time{}
+	}
+	d.lock.Unlock()
+
+	if !ban {
+		n.logger.WithFields(logrus.Fields{
+			"target":   target,
+			"failures": failures,
+			"backoff":  backoff,
+			"error":    err,
+		}).Debug("Connection failed, backing off")
+		return
+	}
+	n.bans.Ban(target, d.banDuration, fmt.Sprintf("%d consecutive connection failures", failures))
+	n.releasePool(target)
+	metrics.IncrCounter("net.dial.bans", 1)
+	n.logger.WithFields(logrus.Fields{
+		"target":   target,
+		"failures": failures,
+		"duration": d.banDuration,
+		"error":    err,
+	}).Warn("Banned unreachable peer address")
+}
+
+// releasePool releases the pooled connections to target
+func (n *NetworkTransport) releasePool(target string) {
+	n.connPoolLock.Lock()
+	defer n.connPoolLock.Unlock()
+	for _, conn := range n.connPool[target] {
+		conn.Release()
+	}
+	delete(n.connPool, target)
+}
+
+// ConnStates implements Connections
+func (n *NetworkTransport) ConnStates() []ConnState {
+	states := make(map[string]*ConnState)
+	get := func(target string) *ConnState {
+		s, ok := states[target]
+		if !ok {
+			s = &ConnState{Target: target}
+			states[target] = s
+		}
+		return s
+	}
+
+	n.connPoolLock.Lock()
+	for target, conns := range n.connPool {
+		get(target).Pooled = len(conns)
+	}
+	n.connPoolLock.Unlock()
+
+	if n.dials != nil {
+		now := time.Now()
+		n.dials.lock.Lock()
+		for target, d := range n.dials.states {
+			s := get(target)
+			s.Failures = d.failures
+			s.TotalFailures = d.total
+			s.LastError = d.lastErr
+			if now.Before(d.retryAt) {
+				s.RetryAt = d.retryAt
+			}
+		}
+		n.dials.lock.Unlock()
+	}
+
+	res := make([]ConnState, 0, len(states))
+	for _, s := range states {
+		s.Banned = n.bans != nil && n.bans.IsBanned(s.Target)
+		res = append(res, *s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Target < res[j].Target })
+	return res
+}
+
+// ResetConn implements Connections
+func (n *NetworkTransport) ResetConn(target string) bool {
+	found := false
+	if n.dials != nil {
+		n.dials.lock.Lock()
+		if _, ok := n.dials.states[target]; ok {
+			delete(n.dials.states, target)
+			found = true
+		}
+		n.dials.lock.Unlock()
+	}
+	if n.bans != nil && n.bans.Unban(target) {
+		found = true
+	}
+	return found
+}
@@ -0,0 +1,103 @@
+package net
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+func TestDialBackoff(t *testing.T) {
+	logger := common.NewTestLogger(t)
+
+	// an address nobody listens on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dead := l.Addr().String()
+	l.Close()
+
+	live, err := NewTCPTransport("127.0.0.1:0", nil, 2, time.Second, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer live.Close()
+	live.Listen()
+	go func() {
+		for rpc := range live.Consumer() {
+			rpc.Respond(&SyncResponse{FromID: 1}, nil)
+		}
+	}()
+
+	trans, err := NewTCPTransport("127.0.0.1:0", nil, 2, time.Second, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trans.Close()
+	bans := peers.NewBanList()
+	trans.SetBanList(bans)
+	trans.SetDialBackoff(50*time.Millisecond, 100*time.Millisecond, 3, time.Minute)
+
+	state := func(target string) ConnState {
+		for _, s := range trans.ConnStates() {
+			if s.Target == target {
+				return s
+			}
+		}
+		return ConnState{}
+	}
+	var resp SyncResponse
+
+	if err := trans.Sync(dead, &SyncRequest{}, &resp); err == nil {
+		t.Fatal("the sync with a dead address should fail")
+	}
+	if s := state(dead); s.Failures != 1 || s.RetryAt.IsZero() {
+		t.Fatalf("a failure should delay the next connection: %+v", s)
+	}
+	err = trans.Sync(dead, &SyncRequest{}, &resp)
+	if err == nil || !strings.Contains(err.Error(), "backing off") {
+		t.Fatalf("the address should be backing off, got %v", err)
+	}
+	if s := state(dead); s.Failures != 1 {
+		t.Fatalf("a connection refused by the backoff should not be a failure: %+v", s)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	trans.Sync(dead, &SyncRequest{}, &resp)
+	if s := state(dead); s.Failures != 2 {
+		t.Fatalf("the failures should be counted after the backoff: %+v", s)
+	}
+	time.Sleep(110 * time.Millisecond)
+	trans.Sync(dead, &SyncRequest{}, &resp)
+	if s := state(dead); !s.Banned || s.Failures != 0 || s.TotalFailures != 3 {
+		t.Fatalf("the address should be banned after 3 failures: %+v", s)
+	}
+	if !bans.IsBanned(dead) {
+		t.Fatal("the ban should be in the ban list")
+	}
+	err = trans.Sync(dead, &SyncRequest{}, &resp)
+	if err == nil || !strings.Contains(err.Error(), "banned") {
+		t.Fatalf("the banned address should not be dialed, got %v", err)
+	}
+
+	if !trans.ResetConn(dead) {
+		t.Fatal("the state of the address should be reset")
+	}
+	if bans.IsBanned(dead) || state(dead).Target != "" {
+		t.Fatalf("the reset should lift the ban: %+v", trans.ConnStates())
+	}
+	if trans.ResetConn(dead) {
+		t.Fatal("a second reset should find nothing")
+	}
+
+	if err := trans.Sync(live.LocalAddr(), &SyncRequest{}, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if s := state(live.LocalAddr()); s.Pooled != 1 || s.Failures != 0 || s.Banned {
+		t.Fatalf("the live address should have a pooled connection: %+v", s)
+	}
+}
@@ -0,0 +1,127 @@
+package net
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+)
+
+// nonceSize is the size of the handshake challenges
+const nonceSize = 32
+
+// SetIdentity makes the transport prove it controls key on every connection
+// and require the same from the peers connecting to it. expected returns the
+// key of the peer listening at an address, empty when unknown; dialing an
+// address answered by another key fails. It must be called before Listen.
+func (n *NetworkTransport) SetIdentity(key *ecdsa.PrivateKey, expected func(addr string) string) {
+	n.key = key
+	n.pubKey = fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey))
+	n.expected = expected
+}
+
+// SetKnownPeers makes the transport tell the peers known reports, by key,
+// apart from the other remotes in its metrics and rate limits. The others
+// are free to take new keys and IPs. It must be called before Listen.
+func (n *NetworkTransport) SetKnownPeers(known func(pubKey string) bool) {
+	n.known = known
+}
+
+// connState is the handshake state of an inbound connection
+type connState struct {
+	remote     string
+	identified bool
+	// identity is the verified key of the remote, empty when the transport
+	// has no identity
+	identity string
+	// claimed key and the nonce it must sign
+	claimed string
+	nonce   []byte
+	// advertised is the address the remote is reachable at, reported once
+	// its key is proven
+	advertised string
+	// wire is the encoding of the RPC payloads, negotiated by the handshake
+	wire wireMode
+}
+
+// knownRemote tells whether the verified identity of the remote is the key
+// of a known peer
+func (n *NetworkTransport) knownRemote(s *connState) bool {
+	return s.identity != "" && n.known != nil && n.known(s.identity)
+}
+
+// remoteTag is the name of the remote in metrics: the short key of a known
+// peer, "unknown" for the others, so that the metric names are bounded by the
+// peer set
+func (n *NetworkTransport) remoteTag(s *connState) string {
+	if n.knownRemote(s) {
+		return shortKey(s.identity)
+	}
+	return "unknown"
+}
+
+// limitKey is the key of the remote in rate limits: the key of a known peer,
+// the IP of the others, which would get a new bucket with every new key
+func (n *NetworkTransport) limitKey(s *connState) string {
+	if n.knownRemote(s) {
+		return s.identity
+	}
+	if host, _, err := net.SplitHostPort(s.remote); err == nil {
+		return host
+	}
+	return s.remote
+}
+
+// shortKey abbreviates a public key for metric names
+func shortKey(pubKey string) string {
+	k := strings.TrimPrefix(pubKey, "0x04")
+	if len(k) > 16 {
+		k = k[:16]
+	}
+	return k
+}
+
+func newNonce() ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	_, err := rand.Read(nonce)
+	return nonce, err
+}
+
+// identityDigest is what a node signs to prove it controls pubKey, bound to
+// the network and to the challenge of the other side
+func identityDigest(networkID string, nonce []byte, pubKey string) []byte {
+	data := append([]byte("lachesis-handshake:"+networkID+":"+pubKey+":"), nonce...)
+	return crypto.SHA256(data)
+}
+
+func signIdentity(key *ecdsa.PrivateKey, networkID string, nonce []byte, pubKey string) (string, error) {
+	r, s, err := crypto.Sign(key, identityDigest(networkID, nonce, pubKey))
+	if err != nil {
+		return "", err
+	}
+	return crypto.EncodeSignature(r, s), nil
+}
+
+func verifyIdentity(networkID string, nonce []byte, pubKey, signature string) error {
+	if !strings.HasPrefix(pubKey, "0x") {
+		return lerrors.New(lerrors.ProtocolMismatch, "invalid public key %q", pubKey)
+	}
+	pub, err := hex.DecodeString(pubKey[2:])
+	if err != nil {
+		return lerrors.New(lerrors.ProtocolMismatch, "invalid public key %q", pubKey)
+	}
+	r, s, err := crypto.DecodeSignature(signature)
+	if err != nil {
+		return lerrors.New(lerrors.ProtocolMismatch, "invalid identity signature: %s", err)
+	}
+	key := crypto.ToECDSAPub(pub)
+	if key == nil || key.X == nil || !crypto.Verify(key, identityDigest(networkID, nonce, pubKey), r, s) {
+		return lerrors.New(lerrors.ProtocolMismatch, "identity of %s not proven", pubKey)
+	}
+	return nil
+}
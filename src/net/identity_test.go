@@ -0,0 +1,124 @@
+package net
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func newIdentityTransport(t *testing.T, expected func(string) string) (*NetworkTransport, *ecdsa.PrivateKey) {
+	trans, err := NewTCPTransport("127.0.0.1:0", nil, 2, time.Second, common.NewTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := crypto.GenerateECDSAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trans.SetIdentity(key, expected)
+	return trans, key
+}
+
+func TestNetworkTransportIdentity(t *testing.T) {
+	server, serverKey := newIdentityTransport(t, nil)
+	defer server.Close()
+	server.Listen()
+
+	identities := make(chan string, 10)
+	go func() {
+		for rpc := range server.Consumer() {
+			identities <- rpc.Identity
+			rpc.Respond(&SyncResponse{FromID: 1}, nil)
+		}
+	}()
+
+	serverPub := fmt.Sprintf("0x%X", crypto.FromECDSAPub(&serverKey.PublicKey))
+
+	// A dialer expecting the right key is identified by its own key
+	client, clientKey := newIdentityTransport(t, func(addr string) string {
+		return serverPub
+	})
+	defer client.Close()
+
+	var resp SyncResponse
+	if assert.NoError(t, client.Sync(server.LocalAddr(), &SyncRequest{}, &resp)) {
+		assert.EqualValues(t, 1, resp.FromID)
+		assert.Equal(t, fmt.Sprintf("0x%X", crypto.FromECDSAPub(&clientKey.PublicKey)), <-identities)
+	}
+
+	// A dialer expecting another key refuses the listener
+	other, otherKey := newIdentityTransport(t, nil)
+	defer other.Close()
+	wrong, _ := newIdentityTransport(t, func(addr string) string {
+		return fmt.Sprintf("0x%X", crypto.FromECDSAPub(&otherKey.PublicKey))
+	})
+	defer wrong.Close()
+	assert.Error(t, wrong.Sync(server.LocalAddr(), &SyncRequest{}, &resp))
+
+	// A dialer without identity is refused
+	anonymous, err := NewTCPTransport("127.0.0.1:0", nil, 2, time.Second, common.NewTestLogger(t))
+	assert.NoError(t, err)
+	defer anonymous.Close()
+	assert.Error(t, anonymous.Sync(server.LocalAddr(), &SyncRequest{}, &resp))
+}
+
+func TestIdentitySignature(t *testing.T) {
+	key, err := crypto.GenerateECDSAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey))
+	nonce, err := newNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := signIdentity(key, "net-a", nonce, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, verifyIdentity("net-a", nonce, pub, sig))
+	// the signature is bound to the network, the nonce and the key
+	assert.Error(t, verifyIdentity("net-b", nonce, pub, sig))
+	other, _ := newNonce()
+	assert.Error(t, verifyIdentity("net-a", other, pub, sig))
+	otherKey, _ := crypto.GenerateECDSAKey()
+	assert.Error(t, verifyIdentity("net-a", nonce, fmt.Sprintf("0x%X", crypto.FromECDSAPub(&otherKey.PublicKey)), sig))
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(1, 3)
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.Allow("a"), "burst %d", i)
+	}
+	assert.False(t, limiter.Allow("a"))
+	// buckets are per remote
+	assert.True(t, limiter.Allow("b"))
+}
+
+func TestRemoteTags(t *testing.T) {
+	trans := &NetworkTransport{}
+	known := &connState{remote: "10.0.0.1:52011", identity: "0x04AABBCCDDEEFF00112233445566778899"}
+	stranger := &connState{remote: "10.0.0.2:52012", identity: "0x04FFEEDDCCBBAA00998877665544332211"}
+	anonymous := &connState{remote: "10.0.0.3:52013"}
+
+	// without peers, every remote is unknown
+	assert.Equal(t, "unknown", trans.remoteTag(known))
+	assert.Equal(t, "10.0.0.1", trans.limitKey(known))
+
+	trans.SetKnownPeers(func(pubKey string) bool { return pubKey == known.identity })
+	assert.Equal(t, shortKey(known.identity), trans.remoteTag(known))
+	assert.Equal(t, known.identity, trans.limitKey(known))
+	// the others are free to take new keys, not new IPs
+	for _, s := range []*connState{stranger, anonymous} {
+		assert.Equal(t, "unknown", trans.remoteTag(s))
+	}
+	assert.Equal(t, "10.0.0.2", trans.limitKey(stranger))
+	assert.Equal(t, "10.0.0.3", trans.limitKey(anonymous))
+}
@@ -96,6 +96,58 @@ func (i *InmemTransport) FastForward(target string, args *FastForwardRequest, re
 	return nil
 }
 
+// SnapshotChunk implements the Transport interface.
+func (i *InmemTransport) SnapshotChunk(target string, args *SnapshotChunkRequest, resp *SnapshotChunkResponse) error {
+	rpcResp, err := i.makeRPC(target, args, nil, i.timeout)
+	if err != nil {
+		return err
+	}
+
+	// Copy the result back
+	out := rpcResp.Response.(*SnapshotChunkResponse)
+	*resp = *out
+	return nil
+}
+
+// Blocks implements the Transport interface.
+func (i *InmemTransport) Blocks(target string, args *BlocksRequest, resp *BlocksResponse) error {
+	rpcResp, err := i.makeRPC(target, args, nil, i.timeout)
+	if err != nil {
+		return err
+	}
+
+	// Copy the result back
+	out := rpcResp.Response.(*BlocksResponse)
+	*resp = *out
+	return nil
+}
+
+// Peers implements the Transport interface.
+func (i *InmemTransport) Peers(target string, args *PeersRequest, resp *PeersResponse) error {
+	rpcResp, err := i.makeRPC(target, args, nil, i.timeout)
+	if err != nil {
+		return err
+	}
+
+	// Copy the result back
+	out := rpcResp.Response.(*PeersResponse)
+	*resp = *out
+	return nil
+}
+
+// SignatureGossip implements the Transport interface.
+func (i *InmemTransport) SignatureGossip(target string, args *SignatureGossipRequest, resp *SignatureGossipResponse) error {
+	rpcResp, err := i.makeRPC(target, args, nil, i.timeout)
+	if err != nil {
+		return err
+	}
+
+	// Copy the result back
+	out := rpcResp.Response.(*SignatureGossipResponse)
+	*resp = *out
+	return nil
+}
+
 func (i *InmemTransport) makeRPC(target string, args interface{}, r io.Reader, timeout time.Duration) (rpcResp RPCResponse, err error) {
 	inmemMediumSync.RLock()
 	peer, ok := inmemMedium[target]
@@ -0,0 +1,183 @@
+package net
+
+import (
+	"sync"
+
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+)
+
+// chainBuffer is the number of inbound RPCs queued per chain
+const chainBuffer = 64
+
+// Mux shares a transport between several posets hosted by the same node.
+// Outbound requests are tagged with the chain of the sender and inbound
+// ones are routed to the consumer of their chain.
+type Mux struct {
+	trans Transport
+
+	chains     map[string]chan RPC
+	chainsLock sync.RWMutex
+
+	shutdownCh   chan struct{}
+	shutdownOnce sync.Once
+}
+
+// NewMux starts routing the RPCs received by trans
+func NewMux(trans Transport) *Mux {
+	m := &Mux{
+		trans:      trans,
+		chains:     make(map[string]chan RPC),
+		shutdownCh: make(chan struct{}),
+	}
+	go m.route()
+	return m
+}
+
+// Chain returns the transport of a chain, the main one being "". It must be
+// called before RPCs of that chain arrive, which are refused otherwise.
+func (m *Mux) Chain(id string) Transport {
+	m.chainsLock.Lock()
+	defer m.chainsLock.Unlock()
+	ch, ok := m.chains[id]
+	if !ok {
+		ch = make(chan RPC, chainBuffer)
+		m.chains[id] = ch
+	}
+	return &chainTransport{mux: m, id: id, consumer: ch}
+}
+
+// Chains returns the ids of the registered chains
+func (m *Mux) Chains() []string {
+	m.chainsLock.RLock()
+	defer m.chainsLock.RUnlock()
+	res := make([]string, 0, len(m.chains))
+	for id := range m.chains {
+		res = append(res, id)
+	}
+	return res
+}
+
+// Close stops routing and closes the shared transport
+func (m *Mux) Close() error {
+	m.shutdownOnce.Do(func() { close(m.shutdownCh) })
+	return m.trans.Close()
+}
+
+func (m *Mux) route() {
+	for {
+		select {
+		case rpc := <-m.trans.Consumer():
+			id := chainOf(rpc.Command)
+			m.chainsLock.RLock()
+			ch, ok := m.chains[id]
+			m.chainsLock.RUnlock()
+			if !ok {
+				rpc.Respond(nil, lerrors.New(lerrors.ProtocolMismatch, "chain %q is not hosted here", id))
+				continue
+			}
+			select {
+			case ch <- rpc:
+			case <-m.shutdownCh:
+				return
+			}
+		case <-m.shutdownCh:
+			return
+		}
+	}
+}
+
+func (m *Mux) remove(id string) {
+	m.chainsLock.Lock()
+	defer m.chainsLock.Unlock()
+	delete(m.chains, id)
+}
+
+// chainOf returns the chain a command is for
+func chainOf(cmd interface{}) string {
+	switch c := cmd.(type) {
+	case *SyncRequest:
+		return c.Chain
+	case *EagerSyncRequest:
+		return c.Chain
+	case *FastForwardRequest:
+		return c.Chain
+	case *SnapshotChunkRequest:
+		return c.Chain
+	case *BlocksRequest:
+		return c.Chain
+	case *PeersRequest:
+		return c.Chain
+	case *SignatureGossipRequest:
+		return c.Chain
+	}
+	return ""
+}
+
+// chainTransport is the Transport of one chain of a Mux
+type chainTransport struct {
+	mux      *Mux
+	id       string
+	consumer chan RPC
+}
+
+// Consumer implements the Transport interface.
+func (c *chainTransport) Consumer() <-chan RPC {
+	return c.consumer
+}
+
+// LocalAddr implements the Transport interface.
+func (c *chainTransport) LocalAddr() string {
+	return c.mux.trans.LocalAddr()
+}
+
+// Sync implements the Transport interface.
+func (c *chainTransport) Sync(target string, args *SyncRequest, resp *SyncResponse) error {
+	args.Chain = c.id
+	return c.mux.trans.Sync(target, args, resp)
+}
+
+// EagerSync implements the Transport interface.
+func (c *chainTransport) EagerSync(target string, args *EagerSyncRequest, resp *EagerSyncResponse) error {
+	args.Chain = c.id
+	return c.mux.trans.EagerSync(target, args, resp)
+}
+
+// FastForward implements the Transport interface.
+func (c *chainTransport) FastForward(target string, args *FastForwardRequest, resp *FastForwardResponse) error {
+	args.Chain = c.id
+	return c.mux.trans.FastForward(target, args, resp)
+}
+
+// SnapshotChunk implements the Transport interface.
+func (c *chainTransport) SnapshotChunk(target string, args *SnapshotChunkRequest, resp *SnapshotChunkResponse) error {
+	args.Chain = c.id
+	return c.mux.trans.SnapshotChunk(target, args, resp)
+}
+
+// Blocks implements the Transport interface.
+func (c *chainTransport) Blocks(target string, args *BlocksRequest, resp *BlocksResponse) error {
+	args.Chain = c.id
+	return c.mux.trans.Blocks(target, args, resp)
+}
+
+// Peers implements the Transport interface.
+func (c *chainTransport) Peers(target string, args *PeersRequest, resp *PeersResponse) error {
+	args.Chain = c.id
+	return c.mux.trans.Peers(target, args, resp)
+}
+
+// SignatureGossip implements the Transport interface.
+func (c *chainTransport) SignatureGossip(target string, args *SignatureGossipRequest, resp *SignatureGossipResponse) error {
+	args.Chain = c.id
+	return c.mux.trans.SignatureGossip(target, args, resp)
+}
+
+// Close unregisters the chain. Closing the main chain closes the shared
+// transport.
+func (c *chainTransport) Close() error {
+	if c.id == "" {
+		return c.mux.Close()
+	}
+	c.mux.remove(c.id)
+	return nil
+}
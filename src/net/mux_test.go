@@ -0,0 +1,48 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+)
+
+func TestMux(t *testing.T) {
+	_, trans1 := NewInmemTransport("")
+	_, trans2 := NewInmemTransport("")
+	mux1, mux2 := NewMux(trans1), NewMux(trans2)
+	defer mux1.Close()
+	defer mux2.Close()
+
+	main1, shard1 := mux1.Chain(""), mux1.Chain("shard")
+	shard2 := mux2.Chain("shard")
+	other2 := mux2.Chain("other")
+
+	go func() {
+		for {
+			select {
+			case rpc := <-shard1.Consumer():
+				rpc.Respond(&SyncResponse{FromID: 1}, nil)
+			case rpc := <-main1.Consumer():
+				t.Errorf("unexpected RPC on the main chain: %#v", rpc.Command)
+				rpc.Respond(nil, nil)
+			case <-time.After(time.Second):
+				return
+			}
+		}
+	}()
+
+	args := &SyncRequest{FromID: 2}
+	var resp SyncResponse
+	if err := shard2.Sync(trans1.LocalAddr(), args, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if args.Chain != "shard" || resp.FromID != 1 {
+		t.Fatalf("unexpected request %#v or response %#v", args, resp)
+	}
+
+	err := other2.Sync(trans1.LocalAddr(), &SyncRequest{}, &resp)
+	if !lerrors.Is(err, lerrors.ProtocolMismatch) {
+		t.Fatalf("chains not hosted by the peer should be refused, got %v", err)
+	}
+}
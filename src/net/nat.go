@@ -0,0 +1,299 @@
+package net
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huin/goupnp/dcps/internetgateway2"
+	"github.com/jackpal/gateway"
+	natpmp "github.com/jackpal/go-nat-pmp"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// A node behind NAT is not reachable at the address it listens on. With a
+// NAT setting, it asks its gateway, with UPnP or NAT-PMP, to forward a port
+// to its listener, and advertises the external address of the gateway to
+// its peers, see SetAdvertiseAddr.
+
+// NAT settings of ParseNAT
+const (
+	NATNone = "none"
+	NATUPnP = "upnp"
+	NATPMP  = "pmp"
+	// NATAny tries UPnP, then NAT-PMP
+	NATAny = "any"
+)
+
+// natLifetime is the lease of the port mappings, renewed at half of it
+const natLifetime = 20 * time.Minute
+
+// NAT is a gateway forwarding ports to the node
+type NAT interface {
+	// ExternalIP returns the address of the gateway on the internet
+	ExternalIP() (net.IP, error)
+	// AddMapping forwards the external port of the gateway to the internal
+	// port of the node for lifetime, and returns the external port mapped,
+	// which may differ from the requested one
+	AddMapping(protocol string, extPort, intPort int, name string, lifetime time.Duration) (int, error)
+	DeleteMapping(protocol string, extPort, intPort int) error
+	String() string
+}
+
+// ParseNAT checks a NAT setting: none, upnp, pmp, pmp:<gateway IP> or any.
+// It returns the kind of the setting and the gateway given with pmp.
+func ParseNAT(spec string) (string, net.IP, error) {
+	kind, addr := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		kind, addr = spec[:i], spec[i+1:]
+	}
+	switch kind {
+	case "", NATNone:
+		return NATNone, nil, nil
+	case NATUPnP, NATAny:
+		if addr == "" {
+			return kind, nil, nil
+		}
+	case NATPMP:
+		if addr == "" {
+			return kind, nil, nil
+		}
+		if ip := net.ParseIP(addr); ip != nil {
+			return kind, ip, nil
+		}
+	}
+	return "", nil, fmt.Errorf("invalid NAT setting %q, expected none, upnp, pmp, pmp:<gateway IP> or any", spec)
+}
+
+// DiscoverNAT finds the gateway of a NAT setting, nil for none
+func DiscoverNAT(spec string) (NAT, error) {
+	kind, gw, err := ParseNAT(spec)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case NATUPnP:
+		return discoverUPnP()
+	case NATPMP:
+		return discoverPMP(gw)
+	case NATAny:
+		nat, err := discoverUPnP()
+		if err == nil {
+			return nat, nil
+		}
+		if nat, pmpErr := discoverPMP(nil); pmpErr == nil {
+			return nat, nil
+		}
+		return nil, fmt.Errorf("no UPnP or NAT-PMP gateway: %s", err)
+	}
+	return nil, nil
+}
+
+// PortMapping is a port of the gateway forwarded to the node, renewed until
+// Close
+type PortMapping struct {
+	nat      NAT
+	protocol string
+	intPort  int
+	extPort  int
+	extIP    net.IP
+	logger   *logrus.Entry
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+	doneCh    chan struct{}
+}
+
+// MapPort asks nat to forward the same port of the gateway, or another one
+// when it is taken, to the port of the listen address addr, over protocol
+// TCP or UDP
+func MapPort(nat NAT, protocol, addr string, logger *logrus.Entry) (*PortMapping, error) {
+	_, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port in %q", addr)
+	}
+	m := &PortMapping{
+		nat:      nat,
+		protocol: protocol,
+		intPort:  port,
+		extPort:  port,
+		logger:   logger.WithField("nat", nat.String()),
+		closeCh:  make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	if err := m.renew(); err != nil {
+		return nil, err
+	}
+	ip, err := nat.ExternalIP()
+	if err != nil {
+		nat.DeleteMapping(protocol, m.extPort, port)
+		return nil, fmt.Errorf("external address of the gateway: %s", err)
+	}
+	m.extIP = ip
+	m.logger.WithField("external", m.ExternalAddr()).Info("Mapped port")
+	go m.run(natLifetime / 2)
+	return m, nil
+}
+
+// ExternalAddr returns the address of the mapped port on the internet
+func (m *PortMapping) ExternalAddr() string {
+	return net.JoinHostPort(m.extIP.String(), strconv.Itoa(m.extPort))
+}
+
+// Close stops renewing the mapping and deletes it
+func (m *PortMapping) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.closeCh)
+		<-m.doneCh
+		err = m.nat.DeleteMapping(m.protocol, m.extPort, m.intPort)
+	})
+	return err
+}
+
+func (m *PortMapping) renew() error {
+	port, err := m.nat.AddMapping(m.protocol, m.extPort, m.intPort, "lachesis", natLifetime)
+	if err != nil {
+		return fmt.Errorf("mapping port %d with %s: %s", m.intPort, m.nat, err)
+	}
+	m.extPort = port
+	return nil
+}
+
+// run renews the mapping every interval
+func (m *PortMapping) run(interval time.Duration) {
+	defer close(m.doneCh)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			previous := m.extPort
+			if err := m.renew(); err != nil {
+				metrics.IncrCounter("net.nat.errors", 1)
+				m.logger.WithError(err).Warn("Renewing port mapping")
+				continue
+			}
+			if m.extPort != previous {
+				m.logger.WithField("external", m.ExternalAddr()).Warn("Port mapping moved, the advertised address is stale")
+			}
+		case <-m.closeCh:
+			return
+		}
+	}
+}
+
+// upnpClient is the WAN connection service of an internet gateway device
+type upnpClient interface {
+	GetExternalIPAddress() (string, error)
+	AddPortMapping(remoteHost string, extPort uint16, protocol string, intPort uint16,
+		intClient string, enabled bool, description string, lease uint32) error
+	DeletePortMapping(remoteHost string, extPort uint16, protocol string) error
+	LocalAddr() net.IP
+}
+
+// upnpNAT is a UPnP internet gateway device
+type upnpNAT struct {
+	client upnpClient
+}
+
+func discoverUPnP() (NAT, error) {
+	if clients, _, err := internetgateway2.NewWANIPConnection2Clients(); err == nil && len(clients) > 0 {
+		return &upnpNAT{clients[0]}, nil
+	}
+	if clients, _, err := internetgateway2.NewWANIPConnection1Clients(); err == nil && len(clients) > 0 {
+		return &upnpNAT{clients[0]}, nil
+	}
+	clients, _, err := internetgateway2.NewWANPPPConnection1Clients()
+	if err != nil {
+		return nil, err
+	}
+	if len(clients) == 0 {
+		return nil, fmt.Errorf("no UPnP gateway found")
+	}
+	return &upnpNAT{clients[0]}, nil
+}
+
+func (u *upnpNAT) ExternalIP() (net.IP, error) {
+	addr, err := u.client.GetExternalIPAddress()
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid external address %q", addr)
+	}
+	return ip, nil
+}
+
+func (u *upnpNAT) AddMapping(protocol string, extPort, intPort int, name string, lifetime time.Duration) (int, error) {
+	err := u.client.AddPortMapping("", uint16(extPort), strings.ToUpper(protocol), uint16(intPort),
+		u.client.LocalAddr().String(), true, name, uint32(lifetime/time.Second))
+	return extPort, err
+}
+
+func (u *upnpNAT) DeleteMapping(protocol string, extPort, intPort int) error {
+	return u.client.DeletePortMapping("", uint16(extPort), strings.ToUpper(protocol))
+}
+
+func (u *upnpNAT) String() string {
+	return "upnp"
+}
+
+// pmpNAT is a NAT-PMP gateway
+type pmpNAT struct {
+	gateway net.IP
+	client  *natpmp.Client
+}
+
+// discoverPMP returns the NAT-PMP client of gw, or of the default gateway
+func discoverPMP(gw net.IP) (NAT, error) {
+	if gw == nil {
+		var err error
+		if gw, err = gateway.DiscoverGateway(); err != nil {
+			return nil, fmt.Errorf("default gateway: %s", err)
+		}
+	}
+	nat := &pmpNAT{gateway: gw, client: natpmp.NewClient(gw)}
+	// a gateway without NAT-PMP does not answer
+	if _, err := nat.client.GetExternalAddress(); err != nil {
+		return nil, fmt.Errorf("no NAT-PMP gateway at %s: %s", gw, err)
+	}
+	return nat, nil
+}
+
+func (p *pmpNAT) ExternalIP() (net.IP, error) {
+	res, err := p.client.GetExternalAddress()
+	if err != nil {
+		return nil, err
+	}
+	ip := res.ExternalIPAddress
+	return net.IPv4(ip[0], ip[1], ip[2], ip[3]), nil
+}
+
+func (p *pmpNAT) AddMapping(protocol string, extPort, intPort int, name string, lifetime time.Duration) (int, error) {
+	res, err := p.client.AddPortMapping(strings.ToLower(protocol), intPort, extPort, int(lifetime/time.Second))
+	if err != nil {
+		return 0, err
+	}
+	return int(res.MappedExternalPort), nil
+}
+
+func (p *pmpNAT) DeleteMapping(protocol string, extPort, intPort int) error {
+	// a mapping of lifetime 0 is deleted
+	_, err := p.client.AddPortMapping(strings.ToLower(protocol), intPort, 0, 0)
+	return err
+}
+
+func (p *pmpNAT) String() string {
+	return "pmp:" + p.gateway.String()
+}
@@ -0,0 +1,124 @@
+package net
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func TestParseNAT(t *testing.T) {
+	for spec, kind := range map[string]string{
+		"":             NATNone,
+		"none":         NATNone,
+		"upnp":         NATUPnP,
+		"pmp":          NATPMP,
+		"pmp:10.0.0.1": NATPMP,
+		"any":          NATAny,
+	} {
+		k, _, err := ParseNAT(spec)
+		if assert.NoError(t, err, spec) {
+			assert.Equal(t, kind, k, spec)
+		}
+	}
+	_, gw, _ := ParseNAT("pmp:10.0.0.1")
+	assert.Equal(t, "10.0.0.1", gw.String())
+
+	for _, spec := range []string{"stun", "pmp:gateway", "upnp:10.0.0.1"} {
+		_, _, err := ParseNAT(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+// fakeNAT maps the ports below 2000 to themselves, and the others to 2000
+type fakeNAT struct {
+	sync.Mutex
+	mappings map[int]int
+}
+
+func (f *fakeNAT) ExternalIP() (net.IP, error) {
+	return net.ParseIP("203.0.113.7"), nil
+}
+
+func (f *fakeNAT) AddMapping(protocol string, extPort, intPort int, name string, lifetime time.Duration) (int, error) {
+	f.Lock()
+	defer f.Unlock()
+	if extPort >= 2000 {
+		extPort = 2000
+	}
+	f.mappings[extPort] = intPort
+	return extPort, nil
+}
+
+func (f *fakeNAT) DeleteMapping(protocol string, extPort, intPort int) error {
+	f.Lock()
+	defer f.Unlock()
+	if f.mappings[extPort] != intPort {
+		return fmt.Errorf("no mapping of %d", extPort)
+	}
+	delete(f.mappings, extPort)
+	return nil
+}
+
+func (f *fakeNAT) String() string {
+	return "fake"
+}
+
+func TestMapPort(t *testing.T) {
+	nat := &fakeNAT{mappings: make(map[int]int)}
+	logger := logrus.NewEntry(logrus.New())
+
+	m, err := MapPort(nat, "TCP", ":1337", logger)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "203.0.113.7:1337", m.ExternalAddr())
+	taken, err := MapPort(nat, "TCP", "127.0.0.1:4000", logger)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "203.0.113.7:2000", taken.ExternalAddr())
+
+	assert.NoError(t, m.Close())
+	assert.NoError(t, taken.Close())
+	assert.NoError(t, m.Close())
+	assert.Empty(t, nat.mappings)
+
+	_, err = MapPort(nat, "TCP", "1337", logger)
+	assert.Error(t, err)
+}
+
+func TestNetworkTransportAdvertise(t *testing.T) {
+	server, serverKey := newIdentityTransport(t, nil)
+	defer server.Close()
+	client, clientKey := newIdentityTransport(t, nil)
+	defer client.Close()
+
+	type advertised struct{ pubKey, addr string }
+	serverSeen := make(chan advertised, 1)
+	clientSeen := make(chan advertised, 1)
+	server.SetAdvertiseAddr("203.0.113.7:1337")
+	server.OnAdvertisedAddr(func(pubKey, addr string) { serverSeen <- advertised{pubKey, addr} })
+	client.SetAdvertiseAddr("198.51.100.3:1337")
+	client.OnAdvertisedAddr(func(pubKey, addr string) { clientSeen <- advertised{pubKey, addr} })
+	server.Listen()
+
+	go func() {
+		for rpc := range server.Consumer() {
+			rpc.Respond(&SyncResponse{FromID: 1}, nil)
+		}
+	}()
+
+	var resp SyncResponse
+	if !assert.NoError(t, client.Sync(server.LocalAddr(), &SyncRequest{}, &resp)) {
+		return
+	}
+	assert.Equal(t, advertised{fmt.Sprintf("0x%X", crypto.FromECDSAPub(&serverKey.PublicKey)), "203.0.113.7:1337"}, <-clientSeen)
+	assert.Equal(t, advertised{fmt.Sprintf("0x%X", crypto.FromECDSAPub(&clientKey.PublicKey)), "198.51.100.3:1337"}, <-serverSeen)
+}
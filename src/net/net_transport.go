@@ -2,6 +2,7 @@ package net
 
 import (
 	"bufio"
+	"crypto/ecdsa"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,7 +11,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Fantom-foundation/go-lachesis/src/chaos"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
 	"github.com/Fantom-foundation/go-lachesis/src/log"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
 	"github.com/sirupsen/logrus"
 )
 
@@ -22,12 +27,35 @@ const (
 	rpcSync uint8 = iota
 	rpcEagerSync
 	rpcFastForward
+	rpcHandshake
+	rpcSnapshotChunk
+	rpcIdentityProof
+	rpcBlocks
+	rpcPeers
+	rpcSignatureGossip
 )
 
+// rpcNames names the RPC types in metrics
+var rpcNames = map[uint8]string{
+	rpcSync:            "sync",
+	rpcEagerSync:       "eager_sync",
+	rpcFastForward:     "fast_forward",
+	rpcHandshake:       "handshake",
+	rpcSnapshotChunk:   "snapshot_chunk",
+	rpcIdentityProof:   "identity_proof",
+	rpcBlocks:          "blocks",
+	rpcPeers:           "peers",
+	rpcSignatureGossip: "signature_gossip",
+}
+
 var (
 	// ErrTransportShutdown is returned when operations on a transport are
 	// invoked after it's been terminated.
 	ErrTransportShutdown = errors.New("transport shutdown")
+
+	// ErrHandshakeRequired is returned when a connection sends commands
+	// before identifying its network.
+	ErrHandshakeRequired = errors.New("handshake required")
 )
 
 /*
@@ -45,7 +73,7 @@ The response is an error string followed by the response object,
 both are encoded using msgpack
 */
 type NetworkTransport struct {
-	logger *logrus.Logger
+	logger *logrus.Entry
 
 	connPool     map[string][]*netConn
 	connPoolLock sync.Mutex
@@ -57,9 +85,42 @@ type NetworkTransport struct {
 	shutdownCh   chan struct{}
 	shutdownLock sync.Mutex
 
+	// listenOnce starts the listener, see Listen
+	listenOnce sync.Once
+
 	stream StreamLayer
 
 	timeout time.Duration
+
+	bans *peers.BanList
+	// dials delays the connections to failing addresses, see
+	// SetDialBackoff
+	dials *dialBackoff
+
+	networkID string
+
+	// identity of the node, see SetIdentity
+	key      *ecdsa.PrivateKey
+	pubKey   string
+	expected func(addr string) string
+	// known tells the keys of the peers, see SetKnownPeers
+	known func(pubKey string) bool
+
+	limiter *rateLimiter
+
+	// wireCodec is asked for on outbound connections, see
+	// SetWireCompression
+	wireCodec string
+	// wireVersion is asked for on outbound connections, see SetWireVersion
+	wireVersion int
+
+	// advertiseAddr is sent in the handshakes, see SetAdvertiseAddr
+	advertiseAddr string
+	// onAdvertised is called with the addresses advertised by the peers
+	onAdvertised func(pubKey, addr string)
+
+	// traffic of the connections, see Traffic
+	traffic *traffic
 }
 
 // StreamLayer is used with the NetworkTransport to provide
@@ -78,6 +139,8 @@ type netConn struct {
 	w      *bufio.Writer
 	dec    *json.Decoder
 	enc    *json.Encoder
+	// wire is the encoding of the RPC payloads, negotiated by the handshake
+	wire wireMode
 }
 
 func (n *netConn) Release() error {
@@ -86,7 +149,8 @@ func (n *netConn) Release() error {
 
 // NewNetworkTransport creates a new network transport with the given dialer
 // and listener. The maxPool controls how many connections we will pool (per
-// target). The is used to apply I/O deadlines.
+// target). The is used to apply I/O deadlines. The transport accepts no
+// connection until Listen is called.
 func NewNetworkTransport(
 	stream StreamLayer,
 	maxPool int,
@@ -99,18 +163,28 @@ func NewNetworkTransport(
 		lachesis_log.NewLocal(logger, logger.Level.String())
 	}
 	trans := &NetworkTransport{
-		connPool:   make(map[string][]*netConn),
-		consumeCh:  make(chan RPC),
-		logger:     logger,
-		maxPool:    maxPool,
-		shutdownCh: make(chan struct{}),
-		stream:     stream,
-		timeout:    timeout,
-	}
-	go trans.listen()
+		connPool:    make(map[string][]*netConn),
+		consumeCh:   make(chan RPC),
+		logger:      logger.WithField(lachesis_log.ModuleField, "net"),
+		maxPool:     maxPool,
+		shutdownCh:  make(chan struct{}),
+		stream:      stream,
+		timeout:     timeout,
+		wireVersion: WireVersionJSON,
+		traffic:     &traffic{},
+	}
 	return trans
 }
 
+// Listen starts accepting inbound connections. The transport must be
+// configured before, see SetIdentity and the other setters, which are not
+// safe to call once it listens.
+func (n *NetworkTransport) Listen() {
+	n.listenOnce.Do(func() {
+		go n.listen()
+	})
+}
+
 // Close is used to stop the network transport.
 func (n *NetworkTransport) Close() error {
 	n.shutdownLock.Lock()
@@ -124,6 +198,40 @@ func (n *NetworkTransport) Close() error {
 	return nil
 }
 
+// SetBanList makes the transport refuse inbound connections from banned
+// addresses.
+func (n *NetworkTransport) SetBanList(bans *peers.BanList) {
+	n.bans = bans
+}
+
+// SetNetworkID makes the transport identify its network on every new
+// connection, and refuse connections which don't identify the same network.
+func (n *NetworkTransport) SetNetworkID(networkID string) {
+	n.networkID = networkID
+}
+
+// SetAdvertiseAddr makes the transport tell its peers, in the handshakes, that
+// it is reachable at addr, e.g. the external address of its NAT gateway.
+// It must be called before the transport is used.
+func (n *NetworkTransport) SetAdvertiseAddr(addr string) {
+	n.advertiseAddr = addr
+}
+
+// OnAdvertisedAddr calls fn with the address advertised by a peer in a
+// handshake, once the peer proved its key, see SetIdentity. The address is
+// not proven to be the one of the peer. It must be called before the
+// transport is used.
+func (n *NetworkTransport) OnAdvertisedAddr(fn func(pubKey, addr string)) {
+	n.onAdvertised = fn
+}
+
+// advertised reports the address advertised by the peer of key pubKey
+func (n *NetworkTransport) advertised(pubKey, addr string) {
+	if n.onAdvertised != nil && pubKey != "" && addr != "" {
+		n.onAdvertised(pubKey, addr)
+	}
+}
+
 // Consumer implements the Transport interface.
 func (n *NetworkTransport) Consumer() <-chan RPC {
 	return n.consumeCh
@@ -144,6 +252,23 @@ func (n *NetworkTransport) IsShutdown() bool {
 	}
 }
 
+// PrunePool releases the pooled connections to the targets which are not
+// in known.
+func (n *NetworkTransport) PrunePool(known map[string]bool) {
+	n.connPoolLock.Lock()
+	defer n.connPoolLock.Unlock()
+
+	for target, conns := range n.connPool {
+		if known[target] {
+			continue
+		}
+		for _, conn := range conns {
+			conn.Release()
+		}
+		delete(n.connPool, target)
+	}
+}
+
 // getPooledConn is used to grab a pooled connection.
 func (n *NetworkTransport) getPooledConn(target string) *netConn {
 	n.connPoolLock.Lock()
@@ -178,6 +303,7 @@ func (n *NetworkTransport) getConn(target string, timeout time.Duration) (*netCo
 	if err != nil {
 		return nil, err
 	}
+	conn = &countingConn{Conn: conn, traffic: n.traffic}
 
 	// Wrap the conn
 	netConn := &netConn{
@@ -185,15 +311,106 @@ func (n *NetworkTransport) getConn(target string, timeout time.Duration) (*netCo
 		conn:   conn,
 		r:      bufio.NewReader(conn),
 		w:      bufio.NewWriter(conn),
+		wire:   wireMode{version: WireVersionJSON},
 	}
 	// Setup encoder/decoders
 	netConn.dec = json.NewDecoder(netConn.r)
 	netConn.enc = json.NewEncoder(netConn.w)
 
+	// Identify our network and ourselves
+	if n.networkID != "" || n.key != nil || n.wireCodec != "" || n.wireVersion > WireVersionJSON {
+		if err := n.handshake(netConn, timeout); err != nil {
+			return nil, err
+		}
+	}
+
 	// Done
 	return netConn, nil
 }
 
+// handshake exchanges network identities on a new outbound connection.
+// With an identity, both sides then prove they control the key they claim by
+// signing the nonce of the other.
+func (n *NetworkTransport) handshake(conn *netConn, timeout time.Duration) error {
+	if timeout > 0 {
+		conn.conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	args := HandshakeRequest{
+		NetworkID:     n.networkID,
+		Compression:   n.wireCodec,
+		WireVersion:   n.wireVersion,
+		AdvertiseAddr: n.advertiseAddr,
+	}
+	if n.key != nil {
+		nonce, err := newNonce()
+		if err != nil {
+			conn.Release()
+			return err
+		}
+		args.PubKey, args.Nonce = n.pubKey, nonce
+	}
+	if err := sendRPC(conn, rpcHandshake, &args); err != nil {
+		return err
+	}
+
+	var resp HandshakeResponse
+	if canReturn, err := decodeResponse(conn, &resp); err != nil {
+		if canReturn {
+			conn.Release()
+		}
+		return fmt.Errorf("handshake with %s failed: %w", conn.target, err)
+	}
+	if resp.NetworkID != n.networkID {
+		conn.Release()
+		return lerrors.New(lerrors.ProtocolMismatch, "%s belongs to network %s", conn.target, resp.NetworkID)
+	}
+	if n.key != nil {
+		if err := n.proveIdentity(conn, &args, &resp); err != nil {
+			return err
+		}
+		n.advertised(resp.PubKey, resp.AdvertiseAddr)
+	}
+	// the handshake itself is never compressed
+	if resp.Compression == n.wireCodec {
+		conn.wire.codec = resp.Compression
+	}
+	conn.wire.version = negotiateWireVersion(n.wireVersion, resp.WireVersion)
+	return nil
+}
+
+// proveIdentity checks the identity of the listener and proves ours
+func (n *NetworkTransport) proveIdentity(conn *netConn, args *HandshakeRequest, resp *HandshakeResponse) error {
+	if err := verifyIdentity(n.networkID, args.Nonce, resp.PubKey, resp.Signature); err != nil {
+		conn.Release()
+		return fmt.Errorf("handshake with %s failed: %w", conn.target, err)
+	}
+	if n.expected != nil {
+		if expected := n.expected(conn.target); expected != "" && expected != resp.PubKey {
+			conn.Release()
+			metrics.IncrCounter("net.identity.mismatch", 1)
+			return lerrors.New(lerrors.ProtocolMismatch, "%s is not the peer listening at %s", resp.PubKey, conn.target)
+		}
+	}
+
+	sig, err := signIdentity(n.key, n.networkID, resp.Nonce, n.pubKey)
+	if err != nil {
+		conn.Release()
+		return err
+	}
+	if err := sendRPC(conn, rpcIdentityProof, &IdentityProof{Signature: sig}); err != nil {
+		return err
+	}
+	var ack IdentityProofResponse
+	if canReturn, err := decodeResponse(conn, &ack); err != nil {
+		if canReturn {
+			conn.Release()
+		}
+		return fmt.Errorf("handshake with %s failed: %w", conn.target, err)
+	}
+	return nil
+}
+
 // returnConn returns a connection back to the pool.
 func (n *NetworkTransport) returnConn(conn *netConn) {
 	n.connPoolLock.Lock()
@@ -224,11 +441,56 @@ func (n *NetworkTransport) FastForward(target string, args *FastForwardRequest,
 	return n.genericRPC(target, rpcFastForward, args, resp)
 }
 
+// SnapshotChunk implements the Transport interface.
+func (n *NetworkTransport) SnapshotChunk(target string, args *SnapshotChunkRequest, resp *SnapshotChunkResponse) error {
+	return n.genericRPC(target, rpcSnapshotChunk, args, resp)
+}
+
+// Blocks implements the Transport interface.
+func (n *NetworkTransport) Blocks(target string, args *BlocksRequest, resp *BlocksResponse) error {
+	return n.genericRPC(target, rpcBlocks, args, resp)
+}
+
+// Peers implements the Transport interface.
+func (n *NetworkTransport) Peers(target string, args *PeersRequest, resp *PeersResponse) error {
+	return n.genericRPC(target, rpcPeers, args, resp)
+}
+
+// SignatureGossip implements the Transport interface.
+func (n *NetworkTransport) SignatureGossip(target string, args *SignatureGossipRequest, resp *SignatureGossipResponse) error {
+	return n.genericRPC(target, rpcSignatureGossip, args, resp)
+}
+
 // genericRPC handles a simple request/response RPC.
-func (n *NetworkTransport) genericRPC(target string, rpcType uint8, args interface{}, resp interface{}) error {
+func (n *NetworkTransport) genericRPC(target string, rpcType uint8, args interface{}, resp interface{}) (err error) {
+	key := "net.rpc.out." + rpcNames[rpcType]
+	defer func(start time.Time) {
+		metrics.MeasureSince(key, start)
+		if err != nil {
+			metrics.IncrCounter(key+".errors", 1)
+		}
+	}(time.Now())
+
+	duplicate, err := chaos.Global().Message(chaos.PointNetOut + "." + rpcNames[rpcType])
+	if err != nil {
+		return err
+	}
+	if duplicate {
+		n.doRPC(target, rpcType, args, resp)
+	}
+	return n.doRPC(target, rpcType, args, resp)
+}
+
+// doRPC sends one RPC and waits for its response
+func (n *NetworkTransport) doRPC(target string, rpcType uint8, args interface{}, resp interface{}) error {
+	if err := n.checkDial(target); err != nil {
+		return err
+	}
+
 	// Get a conn
 	conn, err := n.getConn(target, n.timeout)
 	if err != nil {
+		n.dialFailed(target, err)
 		return err
 	}
 
@@ -239,6 +501,7 @@ func (n *NetworkTransport) genericRPC(target string, rpcType uint8, args interfa
 
 	// Send the RPC
 	if err = sendRPC(conn, rpcType, args); err != nil {
+		n.dialFailed(target, err)
 		return err
 	}
 
@@ -246,6 +509,9 @@ func (n *NetworkTransport) genericRPC(target string, rpcType uint8, args interfa
 	canReturn, err := decodeResponse(conn, resp)
 	if canReturn {
 		n.returnConn(conn)
+		n.dialSucceeded(target)
+	} else {
+		n.dialFailed(target, err)
 	}
 	return err
 }
@@ -259,7 +525,7 @@ func sendRPC(conn *netConn, rpcType uint8, args interface{}) error {
 	}
 
 	// Send the request
-	if err := conn.enc.Encode(args); err != nil {
+	if err := encodePayload(conn.enc, conn.wire, args); err != nil {
 		conn.Release()
 		return err
 	}
@@ -283,14 +549,14 @@ func decodeResponse(conn *netConn, resp interface{}) (bool, error) {
 	}
 
 	// Decode the response
-	if err := conn.dec.Decode(resp); err != nil {
+	if err := decodePayload(conn.dec, conn.wire, resp); err != nil {
 		conn.Release()
 		return false, err
 	}
 
 	// Format an error if any
 	if rpcError != "" {
-		return true, fmt.Errorf(rpcError)
+		return true, lerrors.Parse(rpcError)
 	}
 	return true, nil
 }
@@ -311,13 +577,24 @@ func (n *NetworkTransport) listen() {
 			n.logger.WithField("error", err).Error("Failed to accept connection")
 			continue
 		}
+		// the port of an inbound connection is not the one the remote
+		// listens on, bans apply to its host
+		host := conn.RemoteAddr().String()
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if n.bans != nil && n.bans.IsBanned(host) {
+			n.logger.WithField("from", conn.RemoteAddr()).Warn("refused connection from banned host")
+			conn.Close()
+			continue
+		}
 		n.logger.WithFields(logrus.Fields{
 			"node": conn.LocalAddr(),
 			"from": conn.RemoteAddr(),
 		}).Info("accepted connection")
 
 		// Handle the connection in dedicated routine
-		go n.handleConn(conn)
+		go n.handleConn(&countingConn{Conn: conn, traffic: n.traffic})
 	}
 }
 
@@ -329,8 +606,12 @@ func (n *NetworkTransport) handleConn(conn net.Conn) {
 	dec := json.NewDecoder(r)
 	enc := json.NewEncoder(w)
 
+	state := &connState{
+		remote:     conn.RemoteAddr().String(),
+		identified: n.networkID == "" && n.key == nil,
+	}
 	for {
-		if err := n.handleCommand(r, dec, enc); err != nil {
+		if err := n.handleCommand(r, dec, enc, state); err != nil {
 			//FIXIT: should we check for ErrTransportShutdown here as well?
 			if err != io.EOF && err != ErrTransportShutdown {
 				n.logger.WithField("error", err).Error("Failed to decode incoming command")
@@ -345,16 +626,28 @@ func (n *NetworkTransport) handleConn(conn net.Conn) {
 }
 
 // handleCommand is used to decode and dispatch a single command.
-func (n *NetworkTransport) handleCommand(r *bufio.Reader, dec *json.Decoder, enc *json.Encoder) error {
+func (n *NetworkTransport) handleCommand(r *bufio.Reader, dec *json.Decoder, enc *json.Encoder, state *connState) error {
 	// Get the rpc type
 	rpcType, err := r.ReadByte()
 	if err != nil {
 		return err
 	}
 
+	// The handshake is answered by the transport itself
+	switch rpcType {
+	case rpcHandshake:
+		return n.handleHandshake(dec, enc, state)
+	case rpcIdentityProof:
+		return n.handleIdentityProof(dec, enc, state)
+	}
+	if !state.identified {
+		return ErrHandshakeRequired
+	}
+
 	// Create the RPC object
 	respCh := make(chan RPCResponse, 1)
 	rpc := RPC{
+		Identity: state.identity,
 		RespChan: respCh,
 	}
 
@@ -362,25 +655,62 @@ func (n *NetworkTransport) handleCommand(r *bufio.Reader, dec *json.Decoder, enc
 	switch rpcType {
 	case rpcSync:
 		var req SyncRequest
-		if err := dec.Decode(&req); err != nil {
+		if err := decodePayload(dec, state.wire, &req); err != nil {
 			return err
 		}
 		rpc.Command = &req
 	case rpcEagerSync:
 		var req EagerSyncRequest
-		if err := dec.Decode(&req); err != nil {
+		if err := decodePayload(dec, state.wire, &req); err != nil {
 			return err
 		}
 		rpc.Command = &req
 	case rpcFastForward:
 		var req FastForwardRequest
-		if err := dec.Decode(&req); err != nil {
+		if err := decodePayload(dec, state.wire, &req); err != nil {
+			return err
+		}
+		rpc.Command = &req
+	case rpcSnapshotChunk:
+		var req SnapshotChunkRequest
+		if err := decodePayload(dec, state.wire, &req); err != nil {
+			return err
+		}
+		rpc.Command = &req
+	case rpcBlocks:
+		var req BlocksRequest
+		if err := decodePayload(dec, state.wire, &req); err != nil {
+			return err
+		}
+		rpc.Command = &req
+	case rpcPeers:
+		var req PeersRequest
+		if err := decodePayload(dec, state.wire, &req); err != nil {
+			return err
+		}
+		rpc.Command = &req
+	case rpcSignatureGossip:
+		var req SignatureGossipRequest
+		if err := decodePayload(dec, state.wire, &req); err != nil {
 			return err
 		}
 		rpc.Command = &req
 	default:
 		return fmt.Errorf("unknown rpc type %d", rpcType)
 	}
+	metrics.IncrCounter("net.rpc.in."+rpcNames[rpcType], 1)
+	tag := n.remoteTag(state)
+	metrics.IncrCounter("net.remote."+tag+".rpc.in", 1)
+
+	if n.limiter != nil && !n.limiter.Allow(n.limitKey(state)) {
+		metrics.IncrCounter("net.remote."+tag+".rate_limited", 1)
+		return n.respondError(enc, state.wire, fmt.Errorf("rate limit exceeded"))
+	}
+
+	// Dropping an inbound message closes the connection
+	if _, err := chaos.Global().Message(chaos.PointNetIn + "." + rpcNames[rpcType]); err != nil {
+		return err
+	}
 
 	// Dispatch the RPC
 	select {
@@ -395,14 +725,14 @@ func (n *NetworkTransport) handleCommand(r *bufio.Reader, dec *json.Decoder, enc
 		// Send the error first
 		respErr := ""
 		if resp.Error != nil {
-			respErr = resp.Error.Error()
+			respErr = lerrors.Format(resp.Error)
 		}
 		if err := enc.Encode(respErr); err != nil {
 			return err
 		}
 
 		// Send the response
-		if err := enc.Encode(resp.Response); err != nil {
+		if err := encodePayload(enc, state.wire, resp.Response); err != nil {
 			return err
 		}
 	case <-n.shutdownCh:
@@ -410,3 +740,103 @@ func (n *NetworkTransport) handleCommand(r *bufio.Reader, dec *json.Decoder, enc
 	}
 	return nil
 }
+
+// respondError answers an RPC with an error only
+func (n *NetworkTransport) respondError(enc *json.Encoder, wire wireMode, err error) error {
+	if err := enc.Encode(lerrors.Format(err)); err != nil {
+		return err
+	}
+	return encodePayload(enc, wire, nil)
+}
+
+// handleHandshake checks the network identity of an inbound connection and,
+// with an identity, proves ours and challenges the dialer.
+func (n *NetworkTransport) handleHandshake(dec *json.Decoder, enc *json.Encoder, state *connState) error {
+	var req HandshakeRequest
+	if err := dec.Decode(&req); err != nil {
+		return err
+	}
+
+	var respErr error
+	if n.networkID != "" && req.NetworkID != n.networkID {
+		respErr = lerrors.New(lerrors.ProtocolMismatch, "network mismatch: expected %s, got %s", n.networkID, req.NetworkID)
+	}
+	resp := HandshakeResponse{NetworkID: n.networkID, AdvertiseAddr: n.advertiseAddr}
+	if codec, err := ParseWireCompression(req.Compression); err == nil {
+		resp.Compression = codec
+	}
+	if req.WireVersion != 0 {
+		resp.WireVersion = negotiateWireVersion(WireVersion, req.WireVersion)
+	}
+	if respErr == nil && n.key != nil {
+		if req.PubKey == "" || len(req.Nonce) < nonceSize {
+			respErr = lerrors.New(lerrors.ProtocolMismatch, "identity required")
+		} else {
+			resp.PubKey = n.pubKey
+			if resp.Signature, respErr = signIdentity(n.key, n.networkID, req.Nonce, n.pubKey); respErr == nil {
+				resp.Nonce, respErr = newNonce()
+			}
+			state.claimed, state.nonce = req.PubKey, resp.Nonce
+			state.advertised = req.AdvertiseAddr
+		}
+	}
+
+	if respErr != nil {
+		if err := enc.Encode(lerrors.Format(respErr)); err != nil {
+			return err
+		}
+		if err := enc.Encode(&resp); err != nil {
+			return err
+		}
+		return respErr
+	}
+	if err := enc.Encode(""); err != nil {
+		return err
+	}
+	if err := enc.Encode(&resp); err != nil {
+		return err
+	}
+
+	// With an identity, the connection is identified by the proof
+	state.identified = n.key == nil
+	state.wire = wireMode{
+		codec:   resp.Compression,
+		version: negotiateWireVersion(WireVersion, req.WireVersion),
+	}
+	return nil
+}
+
+// handleIdentityProof checks that the dialer signed our nonce with the key it
+// claimed in the handshake
+func (n *NetworkTransport) handleIdentityProof(dec *json.Decoder, enc *json.Encoder, state *connState) error {
+	var req IdentityProof
+	if err := dec.Decode(&req); err != nil {
+		return err
+	}
+	err := lerrors.New(lerrors.ProtocolMismatch, "unexpected identity proof")
+	if state.nonce != nil {
+		err = verifyIdentity(n.networkID, state.nonce, state.claimed, req.Signature)
+	}
+	if err == nil && n.bans != nil && n.bans.IsBanned(state.claimed) {
+		err = fmt.Errorf("%s is banned", state.claimed)
+	}
+	state.nonce = nil
+	if err != nil {
+		metrics.IncrCounter("net.identity.refused", 1)
+		n.logger.WithFields(logrus.Fields{
+			"from":  state.remote,
+			"error": err,
+		}).Warn("Refused connection identity")
+		if encErr := n.respondError(enc, wireMode{}, err); encErr != nil {
+			return encErr
+		}
+		return err
+	}
+
+	state.identity, state.identified = state.claimed, true
+	n.advertised(state.identity, state.advertised)
+	if err := enc.Encode(""); err != nil {
+		return err
+	}
+	return enc.Encode(&IdentityProofResponse{})
+}
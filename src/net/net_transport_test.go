@@ -21,6 +21,7 @@ func TestNetworkTransport(t *testing.T) {
 	trans1, err := NewTCPTransport("127.0.0.1:0", nil, 2, time.Second, logger)
 	assert.NoError(t, err)
 	defer trans1.Close()
+	trans1.Listen()
 
 	rpcCh := trans1.Consumer()
 
@@ -162,6 +163,73 @@ func TestNetworkTransport(t *testing.T) {
 		}
 	})
 
+	t.Run("Blocks", func(t *testing.T) {
+		assert := assert.New(t)
+
+		expectedReq := &BlocksRequest{
+			FromID: 0,
+			From:   5,
+			Limit:  2,
+		}
+
+		expectedResp := &BlocksResponse{
+			FromID: 1,
+			Blocks: []poset.Block{
+				poset.NewBlock(5, 6, []byte("frame"), [][]byte{[]byte("tx")}),
+			},
+		}
+
+		go func() {
+			select {
+			case rpc := <-rpcCh:
+				req := rpc.Command.(*BlocksRequest)
+				assert.EqualValues(expectedReq, req)
+				rpc.Respond(expectedResp, nil)
+			case <-time.After(timeout):
+				assert.Fail("timeout")
+			}
+		}()
+
+		var resp = new(BlocksResponse)
+		err := trans2.Blocks(trans1.LocalAddr(), expectedReq, resp)
+		if assert.NoError(err) && assert.Len(resp.Blocks, 1) {
+			assert.True(resp.Blocks[0].Body.Equals(expectedResp.Blocks[0].Body))
+		}
+	})
+
+	t.Run("SignatureGossip", func(t *testing.T) {
+		assert := assert.New(t)
+
+		expectedReq := &SignatureGossipRequest{
+			FromID: 0,
+			Signatures: []poset.BlockSignature{
+				{Validator: []byte("validator"), Index: 5, Signature: "signature"},
+			},
+		}
+
+		expectedResp := &SignatureGossipResponse{
+			FromID:   1,
+			Accepted: 1,
+		}
+
+		go func() {
+			select {
+			case rpc := <-rpcCh:
+				req := rpc.Command.(*SignatureGossipRequest)
+				assert.EqualValues(expectedReq, req)
+				rpc.Respond(expectedResp, nil)
+			case <-time.After(timeout):
+				assert.Fail("timeout")
+			}
+		}()
+
+		var resp = new(SignatureGossipResponse)
+		err := trans2.SignatureGossip(trans1.LocalAddr(), expectedReq, resp)
+		if assert.NoError(err) {
+			assert.EqualValues(expectedResp, resp)
+		}
+	})
+
 	t.Run("PooledConn", func(t *testing.T) {
 		assert := assert.New(t)
 
@@ -231,3 +299,42 @@ func TestNetworkTransport(t *testing.T) {
 		assert.Equal(maxPool, len(trans2.connPool[addr]))
 	})
 }
+
+func TestNetworkTransportHandshake(t *testing.T) {
+	logger := common.NewTestLogger(t)
+
+	trans1, err := NewTCPTransport("127.0.0.1:0", nil, 2, time.Second, logger)
+	assert.NoError(t, err)
+	defer trans1.Close()
+	trans1.SetNetworkID("net-a")
+	trans1.Listen()
+
+	go func() {
+		for rpc := range trans1.Consumer() {
+			rpc.Respond(&SyncResponse{FromID: 1}, nil)
+		}
+	}()
+
+	same, err := NewTCPTransport("127.0.0.1:0", nil, 2, time.Second, logger)
+	assert.NoError(t, err)
+	defer same.Close()
+	same.SetNetworkID("net-a")
+
+	var resp SyncResponse
+	if assert.NoError(t, same.Sync(trans1.LocalAddr(), &SyncRequest{}, &resp)) {
+		assert.EqualValues(t, 1, resp.FromID)
+	}
+
+	other, err := NewTCPTransport("127.0.0.1:0", nil, 2, time.Second, logger)
+	assert.NoError(t, err)
+	defer other.Close()
+	other.SetNetworkID("net-b")
+
+	assert.Error(t, other.Sync(trans1.LocalAddr(), &SyncRequest{}, &resp))
+
+	anonymous, err := NewTCPTransport("127.0.0.1:0", nil, 2, time.Second, logger)
+	assert.NoError(t, err)
+	defer anonymous.Close()
+
+	assert.Error(t, anonymous.Sync(trans1.LocalAddr(), &SyncRequest{}, &resp))
+}
@@ -0,0 +1,262 @@
+package net
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// quicALPN is the application protocol negotiated by the QUIC transport
+const quicALPN = "lachesis"
+
+// quicMaxStreams is the number of concurrent streams a peer may open on a
+// QUIC connection, one per pooled connection of its NetworkTransport
+const quicMaxStreams = 1000
+
+var errQUICClosed = errors.New("quic stream layer closed")
+
+// QUICStreamLayer implements StreamLayer over QUIC. The connections of the
+// NetworkTransport to a peer are streams of a single QUIC connection, which
+// survives a change of the address of the peer, and a lost connection is
+// reopened with 0-RTT, sending its first RPC with the handshake.
+type QUICStreamLayer struct {
+	advertise net.Addr
+	udp       *net.UDPConn
+	transport *quic.Transport
+	listener  *quic.EarlyListener
+	// dialTLS is the client side TLS configuration, with a session cache
+	// for the 0-RTT reconnections
+	dialTLS *tls.Config
+	config  *quic.Config
+
+	connsLock sync.Mutex
+	conns     map[string]*quic.Conn
+
+	acceptCh   chan net.Conn
+	shutdownCh chan struct{}
+	closeOnce  sync.Once
+}
+
+// quicStreamConn is a QUIC stream used as a net.Conn
+type quicStreamConn struct {
+	*quic.Stream
+	conn *quic.Conn
+}
+
+// LocalAddr implements the net.Conn interface.
+func (c *quicStreamConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// RemoteAddr implements the net.Conn interface.
+func (c *quicStreamConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// Close implements the net.Conn interface, closing both directions of the
+// stream. The QUIC connection stays open for the other streams.
+func (c *quicStreamConn) Close() error {
+	c.Stream.CancelRead(0)
+	return c.Stream.Close()
+}
+
+// Dial implements the StreamLayer interface, opening a stream on the QUIC
+// connection to address
+func (q *QUICStreamLayer) Dial(address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := q.connection(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		// the connection may have died since it was last used
+		q.forget(address, conn)
+		if conn, err = q.connection(ctx, address); err != nil {
+			return nil, err
+		}
+		if stream, err = conn.OpenStreamSync(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return &quicStreamConn{Stream: stream, conn: conn}, nil
+}
+
+// connection returns the live QUIC connection to address, dialing it when
+// there is none
+func (q *QUICStreamLayer) connection(ctx context.Context, address string) (*quic.Conn, error) {
+	q.connsLock.Lock()
+	conn, ok := q.conns[address]
+	q.connsLock.Unlock()
+	if ok && conn.Context().Err() == nil {
+		return conn, nil
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err = q.transport.DialEarly(ctx, addr, q.dialTLS, q.config)
+	if err != nil {
+		return nil, err
+	}
+	if conn.ConnectionState().Used0RTT {
+		metrics.IncrCounter("net.quic.0rtt", 1)
+	}
+	metrics.IncrCounter("net.quic.dial", 1)
+
+	q.connsLock.Lock()
+	defer q.connsLock.Unlock()
+	if other, ok := q.conns[address]; ok && other.Context().Err() == nil {
+		// a concurrent dial won
+		conn.CloseWithError(0, "")
+		return other, nil
+	}
+	q.conns[address] = conn
+	return conn, nil
+}
+
+// forget drops conn from the connections, if it is still the one to address
+func (q *QUICStreamLayer) forget(address string, conn *quic.Conn) {
+	q.connsLock.Lock()
+	defer q.connsLock.Unlock()
+	if q.conns[address] == conn {
+		delete(q.conns, address)
+	}
+	conn.CloseWithError(0, "")
+}
+
+// Accept implements the net.Listener interface, returning the streams opened
+// by the peers
+func (q *QUICStreamLayer) Accept() (net.Conn, error) {
+	select {
+	case conn := <-q.acceptCh:
+		return conn, nil
+	case <-q.shutdownCh:
+		return nil, errQUICClosed
+	}
+}
+
+// acceptConns accepts the QUIC connections of the peers until the layer is
+// closed
+func (q *QUICStreamLayer) acceptConns() {
+	for {
+		conn, err := q.listener.Accept(context.Background())
+		if err != nil {
+			return
+		}
+		go q.acceptStreams(conn)
+	}
+}
+
+// acceptStreams hands the streams of conn to Accept until it is closed
+func (q *QUICStreamLayer) acceptStreams(conn *quic.Conn) {
+	for {
+		stream, err := conn.AcceptStream(conn.Context())
+		if err != nil {
+			return
+		}
+		select {
+		case q.acceptCh <- &quicStreamConn{Stream: stream, conn: conn}:
+		case <-q.shutdownCh:
+			return
+		}
+	}
+}
+
+// Close implements the net.Listener interface, closing the QUIC connections
+// and the UDP socket
+func (q *QUICStreamLayer) Close() error {
+	var err error
+	q.closeOnce.Do(func() {
+		close(q.shutdownCh)
+		q.listener.Close()
+		q.connsLock.Lock()
+		for address, conn := range q.conns {
+			conn.CloseWithError(0, "")
+			delete(q.conns, address)
+		}
+		q.connsLock.Unlock()
+		q.transport.Close()
+		err = q.udp.Close()
+	})
+	return err
+}
+
+// Addr implements the net.Listener interface.
+func (q *QUICStreamLayer) Addr() net.Addr {
+	// Use an advertise addr if provided
+	if q.advertise != nil {
+		return q.advertise
+	}
+	return q.udp.LocalAddr()
+}
+
+// NewQUICTransport returns a NetworkTransport built on top of QUIC streams,
+// whose connections are encrypted and authenticated with config, see
+// NewTLSConfig
+func NewQUICTransport(
+	bindAddr string,
+	advertise net.Addr,
+	maxPool int,
+	timeout time.Duration,
+	config *tls.Config,
+	logger *logrus.Logger,
+) (*NetworkTransport, error) {
+	addr, err := net.ResolveUDPAddr("udp", bindAddr)
+	if err != nil {
+		return nil, err
+	}
+	udp, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	serverTLS := config.Clone()
+	serverTLS.MinVersion = tls.VersionTLS13
+	serverTLS.NextProtos = []string{quicALPN}
+	dialTLS := serverTLS.Clone()
+	dialTLS.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+
+	stream := &QUICStreamLayer{
+		advertise: advertise,
+		udp:       udp,
+		transport: &quic.Transport{Conn: udp},
+		dialTLS:   dialTLS,
+		config: &quic.Config{
+			HandshakeIdleTimeout: timeout,
+			MaxIdleTimeout:       timeout,
+			KeepAlivePeriod:      timeout / 2,
+			MaxIncomingStreams:   quicMaxStreams,
+			Allow0RTT:            true,
+		},
+		conns:      make(map[string]*quic.Conn),
+		acceptCh:   make(chan net.Conn),
+		shutdownCh: make(chan struct{}),
+	}
+
+	// Verify that we have a usable advertise address
+	if udpAddr, ok := stream.Addr().(*net.UDPAddr); ok && udpAddr.IP.IsUnspecified() {
+		udp.Close()
+		return nil, errNotAdvertisable
+	}
+
+	stream.listener, err = stream.transport.ListenEarly(serverTLS, stream.config)
+	if err != nil {
+		udp.Close()
+		return nil, err
+	}
+	go stream.acceptConns()
+
+	return NewNetworkTransport(stream, maxPool, timeout, logger), nil
+}
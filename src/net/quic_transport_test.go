@@ -0,0 +1,94 @@
+package net
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func newQUICTransport(t *testing.T, key *ecdsa.PrivateKey, peers ...*ecdsa.PrivateKey) *NetworkTransport {
+	cert, err := NodeCertificate(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	isPeer := func(pubKey string) bool {
+		for _, p := range peers {
+			if pubKeyHex(&p.PublicKey) == pubKey {
+				return true
+			}
+		}
+		return false
+	}
+	trans, err := NewQUICTransport("127.0.0.1:0", nil, 2, time.Second, NewTLSConfig(cert, nil, isPeer), common.NewTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trans.Listen()
+	return trans
+}
+
+func TestQUICTransport(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		key, err := crypto.GenerateECDSAKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+	}
+
+	server := newQUICTransport(t, keys[0], keys[1])
+	defer server.Close()
+	go func() {
+		for rpc := range server.Consumer() {
+			switch rpc.Command.(type) {
+			case *SyncRequest:
+				rpc.Respond(&SyncResponse{FromID: 1}, nil)
+			case *EagerSyncRequest:
+				rpc.Respond(&EagerSyncResponse{FromID: 1, Success: true}, nil)
+			default:
+				rpc.Respond(nil, nil)
+			}
+		}
+	}()
+
+	// a peer with the certificate of its key is accepted, and its RPCs
+	// share a QUIC connection
+	client := newQUICTransport(t, keys[1], keys[0])
+	defer client.Close()
+	for i := 0; i < 3; i++ {
+		var resp SyncResponse
+		if assert.NoError(t, client.Sync(server.LocalAddr(), &SyncRequest{}, &resp)) {
+			assert.EqualValues(t, 1, resp.FromID)
+		}
+	}
+	var eager EagerSyncResponse
+	if assert.NoError(t, client.EagerSync(server.LocalAddr(), &EagerSyncRequest{}, &eager)) {
+		assert.True(t, eager.Success)
+	}
+	layer := client.stream.(*QUICStreamLayer)
+	assert.Len(t, layer.conns, 1)
+
+	// a lost connection is dialed again
+	for address, conn := range layer.conns {
+		layer.forget(address, conn)
+	}
+	client.PrunePool(map[string]bool{})
+	var resp SyncResponse
+	assert.NoError(t, client.Sync(server.LocalAddr(), &SyncRequest{}, &resp))
+
+	// the key of a stranger is refused by the listener
+	stranger := newQUICTransport(t, keys[2], keys[0])
+	defer stranger.Close()
+	assert.Error(t, stranger.Sync(server.LocalAddr(), &SyncRequest{}, &resp))
+
+	// and a dialer refuses a listener whose key is not a peer
+	wary := newQUICTransport(t, keys[1], keys[2])
+	defer wary.Close()
+	assert.Error(t, wary.Sync(server.LocalAddr(), &SyncRequest{}, &resp))
+}
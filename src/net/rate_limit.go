@@ -0,0 +1,81 @@
+package net
+
+import (
+	"sync"
+	"time"
+)
+
+// maxBuckets bounds the number of remotes tracked by a rateLimiter
+const maxBuckets = 4096
+
+// rateLimiter is a token bucket per remote
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	buckets map[string]*bucket
+	lock    sync.Mutex
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the bucket of key
+func (l *rateLimiter) Allow(key string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.prune(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// prune forgets the buckets which are full again
+func (l *rateLimiter) prune(now time.Time) {
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// SetRateLimit bounds the inbound RPCs of every remote to rate per second,
+// with bursts of burst RPCs. Known peers are told apart by their verified
+// identity, see SetIdentity and SetKnownPeers, the other remotes by their IP.
+func (n *NetworkTransport) SetRateLimit(rate float64, burst int) {
+	if rate <= 0 {
+		n.limiter = nil
+		return
+	}
+	n.limiter = newRateLimiter(rate, burst)
+}
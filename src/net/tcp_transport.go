@@ -3,6 +3,8 @@ package net
 import (
 	"errors"
 	"net"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -11,27 +13,106 @@ import (
 var (
 	errNotAdvertisable = errors.New("local bind address is not advertisable")
 	errNotTCP          = errors.New("local address is not a TCP address")
+	errTCPClosed       = errors.New("tcp stream layer closed")
 )
 
 // TCPStreamLayer implements StreamLayer interface for plain TCP.
 type TCPStreamLayer struct {
 	advertise net.Addr
-	listener  *net.TCPListener
+	// listeners are bound to the listen addresses, the first one being
+	// advertised
+	listeners []*net.TCPListener
+
+	// acceptCh merges the connections of the listeners when there are
+	// several
+	acceptCh   chan net.Conn
+	shutdownCh chan struct{}
+	closeOnce  sync.Once
 }
 
 // Dial implements the StreamLayer interface.
 func (t *TCPStreamLayer) Dial(address string, timeout time.Duration) (net.Conn, error) {
-	return net.DialTimeout("tcp", address, timeout)
+	return t.dialer(address, timeout).Dial("tcp", address)
+}
+
+// dialer returns the dialer of address. A node listening on several
+// addresses dials a peer at an IP literal from the listen address of its
+// family, when it is bound to a specific IP, so that a dual-stack node is
+// seen at the address it listens on.
+func (t *TCPStreamLayer) dialer(address string, timeout time.Duration) *net.Dialer {
+	dialer := &net.Dialer{Timeout: timeout}
+	if len(t.listeners) < 2 {
+		return dialer
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return dialer
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return dialer
+	}
+	for _, l := range t.listeners {
+		local := l.Addr().(*net.TCPAddr)
+		if local.IP.IsUnspecified() || local.IP.IsLoopback() != ip.IsLoopback() {
+			continue
+		}
+		if (local.IP.To4() == nil) == (ip.To4() == nil) {
+			dialer.LocalAddr = &net.TCPAddr{IP: local.IP, Zone: local.Zone}
+			break
+		}
+	}
+	return dialer
 }
 
 // Accept implements the net.Listener interface.
 func (t *TCPStreamLayer) Accept() (c net.Conn, err error) {
-	return t.listener.Accept()
+	if len(t.listeners) == 1 {
+		return t.listeners[0].Accept()
+	}
+	select {
+	case conn := <-t.acceptCh:
+		return conn, nil
+	case <-t.shutdownCh:
+		return nil, errTCPClosed
+	}
+}
+
+// acceptConns hands the connections of a listener to Accept until the layer
+// is closed
+func (t *TCPStreamLayer) acceptConns(l *net.TCPListener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			select {
+			case <-t.shutdownCh:
+				return
+			default:
+			}
+			// a temporary error, e.g. too many open files
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		select {
+		case t.acceptCh <- conn:
+		case <-t.shutdownCh:
+			conn.Close()
+			return
+		}
+	}
 }
 
 // Close implements the net.Listener interface.
 func (t *TCPStreamLayer) Close() (err error) {
-	return t.listener.Close()
+	t.closeOnce.Do(func() {
+		close(t.shutdownCh)
+		for _, l := range t.listeners {
+			if lerr := l.Close(); err == nil {
+				err = lerr
+			}
+		}
+	})
+	return err
 }
 
 // Addr implements the net.Listener interface.
@@ -40,11 +121,25 @@ func (t *TCPStreamLayer) Addr() net.Addr {
 	if t.advertise != nil {
 		return t.advertise
 	}
-	return t.listener.Addr()
+	return t.listeners[0].Addr()
+}
+
+// ListenAddrs returns the addresses of a comma-separated list, e.g.
+// "10.0.0.1:1337,[2001:db8::1]:1337"
+func ListenAddrs(bindAddr string) []string {
+	var addrs []string
+	for _, addr := range strings.Split(bindAddr, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
 }
 
 // NewTCPTransport returns a NetworkTransport that is built on top of
-// a TCP streaming transport layer, with log output going to the supplied Logger
+// a TCP streaming transport layer, with log output going to the supplied Logger.
+// bindAddr is a comma-separated list of listen addresses, the first one
+// being advertised.
 func NewTCPTransport(
 	bindAddr string,
 	advertise net.Addr,
@@ -62,29 +157,43 @@ func newTCPTransport(bindAddr string,
 	maxPool int,
 	timeout time.Duration,
 	transportCreator func(stream StreamLayer) *NetworkTransport) (*NetworkTransport, error) {
-	// Try to bind
-	list, err := net.Listen("tcp", bindAddr)
-	if err != nil {
-		return nil, err
-	}
-
 	// Create stream
 	stream := &TCPStreamLayer{
-		advertise: advertise,
-		listener:  list.(*net.TCPListener),
+		advertise:  advertise,
+		acceptCh:   make(chan net.Conn),
+		shutdownCh: make(chan struct{}),
+	}
+
+	// Try to bind
+	for _, addr := range ListenAddrs(bindAddr) {
+		list, err := net.Listen("tcp", addr)
+		if err != nil {
+			stream.Close()
+			return nil, err
+		}
+		stream.listeners = append(stream.listeners, list.(*net.TCPListener))
+	}
+	if len(stream.listeners) == 0 {
+		return nil, errNotAdvertisable
 	}
 
 	// Verify that we have a usable advertise address
 	addr, ok := stream.Addr().(*net.TCPAddr)
 	if !ok {
-		list.Close()
+		stream.Close()
 		return nil, errNotTCP
 	}
 	if addr.IP.IsUnspecified() {
-		list.Close()
+		stream.Close()
 		return nil, errNotAdvertisable
 	}
 
+	if len(stream.listeners) > 1 {
+		for _, l := range stream.listeners {
+			go stream.acceptConns(l)
+		}
+	}
+
 	// Create the network transport
 	trans := transportCreator(stream)
 	return trans, nil
@@ -24,3 +24,64 @@ func TestTCPTransport_WithAdvertise(t *testing.T) {
 		t.Fatalf("bad: %v", trans.LocalAddr())
 	}
 }
+
+func TestTCPTransport_MultipleListenAddrs(t *testing.T) {
+	trans, err := NewTCPTransport("127.0.0.1:0, 127.0.0.2:0", nil, 1, 0, common.NewTestLogger(t))
+	if err != nil {
+		t.Skipf("listening on 127.0.0.2: %v", err)
+	}
+	defer trans.Close()
+	stream := trans.stream.(*TCPStreamLayer)
+	if len(stream.listeners) != 2 {
+		t.Fatalf("there should be 2 listeners, not %d", len(stream.listeners))
+	}
+	if trans.LocalAddr() != stream.listeners[0].Addr().String() {
+		t.Fatalf("the first listen address should be advertised, not %s", trans.LocalAddr())
+	}
+
+	// the connections of both listeners are accepted
+	for _, l := range stream.listeners {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}
+}
+
+func TestTCPStreamLayer_Dialer(t *testing.T) {
+	l4, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l4.Close()
+	l6, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skipf("no IPv6: %v", err)
+	}
+	defer l6.Close()
+	stream := &TCPStreamLayer{listeners: []*net.TCPListener{l4.(*net.TCPListener), l6.(*net.TCPListener)}}
+
+	for address, expected := range map[string]string{
+		"127.0.0.1:1337":   "127.0.0.1",
+		"[::1]:1337":       "::1",
+		"localhost:1337":   "",
+		"[2001:db8::1]:80": "",
+	} {
+		dialer := stream.dialer(address, 0)
+		local := ""
+		if dialer.LocalAddr != nil {
+			local = dialer.LocalAddr.(*net.TCPAddr).IP.String()
+		}
+		if local != expected {
+			t.Fatalf("%s should be dialed from %q, not %q", address, expected, local)
+		}
+	}
+}
+
+func TestListenAddrs(t *testing.T) {
+	addrs := ListenAddrs("10.0.0.1:1337, [2001:db8::1]:1337,")
+	if len(addrs) != 2 || addrs[0] != "10.0.0.1:1337" || addrs[1] != "[2001:db8::1]:1337" {
+		t.Fatalf("unexpected listen addresses %v", addrs)
+	}
+}
@@ -0,0 +1,187 @@
+package net
+
+import (
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// nodeCertificateLifetime is the validity of the certificates made by
+// NodeCertificate
+const nodeCertificateLifetime = 10 * 365 * 24 * time.Hour
+
+// TLSStreamLayer implements StreamLayer over TLS on top of TCP
+type TLSStreamLayer struct {
+	*TCPStreamLayer
+	config *tls.Config
+}
+
+// Dial implements the StreamLayer interface.
+func (t *TLSStreamLayer) Dial(address string, timeout time.Duration) (net.Conn, error) {
+	return tls.DialWithDialer(t.dialer(address, timeout), "tcp", address, t.config)
+}
+
+// Accept implements the net.Listener interface. The handshake happens on the
+// first read or write of the connection.
+func (t *TLSStreamLayer) Accept() (net.Conn, error) {
+	conn, err := t.TCPStreamLayer.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return tls.Server(conn, t.config), nil
+}
+
+// NewTLSTransport returns a NetworkTransport whose connections are encrypted
+// and authenticated with config, see NewTLSConfig
+func NewTLSTransport(
+	bindAddr string,
+	advertise net.Addr,
+	maxPool int,
+	timeout time.Duration,
+	config *tls.Config,
+	logger *logrus.Logger,
+) (*NetworkTransport, error) {
+	return newTCPTransport(bindAddr, advertise, maxPool, timeout, func(stream StreamLayer) *NetworkTransport {
+		tlsStream := &TLSStreamLayer{
+			TCPStreamLayer: stream.(*TCPStreamLayer),
+			config:         config,
+		}
+		return NewNetworkTransport(tlsStream, maxPool, timeout, logger)
+	})
+}
+
+// NodeCertificate returns a self-signed certificate of the identity key of a
+// node, so that its peers authenticate it by the key listed in peers.json.
+// X.509 does not support the secp256k1 keys, whose nodes need tls-cert.
+func NodeCertificate(key *ecdsa.PrivateKey) (tls.Certificate, error) {
+	var signer gocrypto.Signer = key
+	switch crypto.TypeOf(&key.PublicKey) {
+	case crypto.KeyEd25519:
+		signer = crypto.ToEd25519(key)
+	case crypto.KeySecp256k1:
+		return tls.Certificate{}, fmt.Errorf("no self-signed certificate of a %s key, see tls-cert", crypto.KeySecp256k1)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "lachesis " + shortKey(pubKeyHex(&key.PublicKey))},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(nodeCertificateLifetime),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  signer,
+		Leaf:        leaf,
+	}, nil
+}
+
+// LoadCertPool reads the PEM certificates of a CA file
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificate found in %s", path)
+	}
+	return pool, nil
+}
+
+// NewTLSConfig returns the configuration of a TLS transport presenting cert.
+// Both sides of a connection present a certificate. With a ca, the
+// certificates of the peers must be issued by it. Without, they must be
+// certificates of a key accepted by isPeer, the identity key of a peer as
+// made by NodeCertificate.
+func NewTLSConfig(cert tls.Certificate, ca *x509.CertPool, isPeer func(pubKey string) bool) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		ClientAuth:   tls.RequireAnyClientCert,
+		// peers are dialed by address rather than by name, their
+		// certificates are checked by verifyPeerCertificate instead
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(raw [][]byte, _ [][]*x509.Certificate) error {
+			err := verifyPeerCertificate(raw, ca, isPeer)
+			if err != nil {
+				metrics.IncrCounter("net.tls.refused", 1)
+			}
+			return err
+		},
+	}
+}
+
+// verifyPeerCertificate checks the certificate chain presented by a peer
+func verifyPeerCertificate(raw [][]byte, ca *x509.CertPool, isPeer func(pubKey string) bool) error {
+	if len(raw) == 0 {
+		return fmt.Errorf("no peer certificate")
+	}
+	certs := make([]*x509.Certificate, len(raw))
+	for i, der := range raw {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("parsing peer certificate: %s", err)
+		}
+		certs[i] = cert
+	}
+
+	if ca != nil {
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         ca,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		return err
+	}
+
+	var pubKey string
+	switch key := certs[0].PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		pubKey = pubKeyHex(key)
+	case ed25519.PublicKey:
+		pubKey = fmt.Sprintf("0x%X", []byte(key))
+	default:
+		return fmt.Errorf("peer certificate key is not an ECDSA or Ed25519 key")
+	}
+	if isPeer == nil || !isPeer(pubKey) {
+		return fmt.Errorf("certificate key %s is not the key of a peer", shortKey(pubKey))
+	}
+	return nil
+}
+
+func pubKeyHex(key *ecdsa.PublicKey) string {
+	return fmt.Sprintf("0x%X", crypto.FromECDSAPub(key))
+}
@@ -0,0 +1,110 @@
+package net
+
+import (
+	"crypto/ecdsa"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func newTLSTransport(t *testing.T, key *ecdsa.PrivateKey, peers ...*ecdsa.PrivateKey) *NetworkTransport {
+	cert, err := NodeCertificate(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	isPeer := func(pubKey string) bool {
+		for _, p := range peers {
+			if pubKeyHex(&p.PublicKey) == pubKey {
+				return true
+			}
+		}
+		return false
+	}
+	trans, err := NewTLSTransport("127.0.0.1:0", nil, 2, time.Second, NewTLSConfig(cert, nil, isPeer), common.NewTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trans.Listen()
+	return trans
+}
+
+func TestTLSTransport(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		key, err := crypto.GenerateECDSAKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+	}
+
+	server := newTLSTransport(t, keys[0], keys[1])
+	defer server.Close()
+	go func() {
+		for rpc := range server.Consumer() {
+			rpc.Respond(&SyncResponse{FromID: 1}, nil)
+		}
+	}()
+
+	// a peer with the certificate of its key is accepted
+	client := newTLSTransport(t, keys[1], keys[0])
+	defer client.Close()
+	var resp SyncResponse
+	if assert.NoError(t, client.Sync(server.LocalAddr(), &SyncRequest{}, &resp)) {
+		assert.EqualValues(t, 1, resp.FromID)
+	}
+
+	// the key of a stranger is refused by the listener
+	stranger := newTLSTransport(t, keys[2], keys[0])
+	defer stranger.Close()
+	assert.Error(t, stranger.Sync(server.LocalAddr(), &SyncRequest{}, &resp))
+
+	// and a dialer refuses a listener whose key is not a peer
+	wary := newTLSTransport(t, keys[1], keys[2])
+	defer wary.Close()
+	assert.Error(t, wary.Sync(server.LocalAddr(), &SyncRequest{}, &resp))
+
+	// a plaintext dialer cannot talk to a TLS listener
+	plain, err := NewTCPTransport("127.0.0.1:0", nil, 2, time.Second, common.NewTestLogger(t))
+	assert.NoError(t, err)
+	defer plain.Close()
+	assert.Error(t, plain.Sync(server.LocalAddr(), &SyncRequest{}, &resp))
+}
+
+func TestTLSTransportEd25519(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 2)
+	for i := range keys {
+		key, err := crypto.GenerateKey(crypto.KeyEd25519)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+	}
+
+	server := newTLSTransport(t, keys[0], keys[1])
+	defer server.Close()
+	go func() {
+		for rpc := range server.Consumer() {
+			rpc.Respond(&SyncResponse{FromID: 1}, nil)
+		}
+	}()
+
+	client := newTLSTransport(t, keys[1], keys[0])
+	defer client.Close()
+	var resp SyncResponse
+	if assert.NoError(t, client.Sync(server.LocalAddr(), &SyncRequest{}, &resp)) {
+		assert.EqualValues(t, 1, resp.FromID)
+	}
+
+	// secp256k1 keys have no self-signed certificate
+	key, err := crypto.GenerateKey(crypto.KeySecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NodeCertificate(key)
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package net
+
+import (
+	"net"
+	"sync/atomic"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// traffic counts the bytes sent and received on the connections of a
+// transport, both inbound and outbound. It is allocated apart from the
+// transport for the 64-bit alignment of its counters.
+type traffic struct {
+	sent     uint64
+	received uint64
+}
+
+// countingConn is a connection counting its bytes in the traffic of its
+// transport
+type countingConn struct {
+	net.Conn
+	traffic *traffic
+}
+
+// Read implements the net.Conn interface.
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		atomic.AddUint64(&c.traffic.received, uint64(n))
+		metrics.IncrCounter("net.bytes.received", int64(n))
+	}
+	return n, err
+}
+
+// Write implements the net.Conn interface.
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		atomic.AddUint64(&c.traffic.sent, uint64(n))
+		metrics.IncrCounter("net.bytes.sent", int64(n))
+	}
+	return n, err
+}
+
+// Traffic returns the number of bytes the transport sent and received since
+// it started
+func (n *NetworkTransport) Traffic() (sent, received uint64) {
+	return atomic.LoadUint64(&n.traffic.sent), atomic.LoadUint64(&n.traffic.received)
+}
@@ -0,0 +1,31 @@
+package net
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+func TestCountingConn(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	tr := &traffic{}
+	conn := &countingConn{Conn: client, traffic: tr}
+	defer conn.Close()
+
+	go func() {
+		buf := make([]byte, 5)
+		io.ReadFull(server, buf)
+		server.Write([]byte("ok"))
+	}()
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	if tr.sent != 5 || tr.received != 2 {
+		t.Fatalf("5 bytes should be sent and 2 received, got %d and %d", tr.sent, tr.received)
+	}
+}
@@ -10,8 +10,11 @@ type RPCResponse struct {
 
 // RPC has a command, and provides a response mechanism.
 type RPC struct {
-	Command  interface{}
-	Reader   io.Reader
+	Command interface{}
+	Reader  io.Reader
+	// Identity is the public key the remote proved to control, empty when
+	// the transport does not verify identities
+	Identity string
 	RespChan chan<- RPCResponse
 }
 
@@ -37,6 +40,18 @@ type Transport interface {
 
 	FastForward(target string, args *FastForwardRequest, resp *FastForwardResponse) error
 
+	// SnapshotChunk fetches a chunk of the application snapshot of a block.
+	SnapshotChunk(target string, args *SnapshotChunkRequest, resp *SnapshotChunkResponse) error
+
+	// Blocks fetches a range of committed blocks, to backfill history.
+	Blocks(target string, args *BlocksRequest, resp *BlocksResponse) error
+
+	// Peers fetches the signed peer records of a node, to discover peers.
+	Peers(target string, args *PeersRequest, resp *PeersResponse) error
+
+	// SignatureGossip pushes block signatures to the sig pool of a node.
+	SignatureGossip(target string, args *SignatureGossipRequest, resp *SignatureGossipResponse) error
+
 	// Close permanently closes a transport, stopping
 	// any associated goroutines and freeing other resources.
 	Close() error
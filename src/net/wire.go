@@ -0,0 +1,274 @@
+package net
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/golang/snappy"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// Wire compression codecs of the RPC payloads
+const (
+	WireNone   = "none"
+	WireGzip   = "gzip"
+	WireSnappy = "snappy"
+)
+
+// wireCompressMin is the size of the JSON payloads under which they are sent
+// uncompressed even on a compressed connection
+const wireCompressMin = 1024
+
+// Versions of the wire format of the RPC payloads
+const (
+	// WireVersionJSON sends the payloads as JSON, the format of the nodes
+	// predating the negotiation of versions
+	WireVersionJSON = 1
+	// WireVersionProto sends the events, blocks and frames of the payloads
+	// as deterministic protobuf, the rest staying JSON
+	WireVersionProto = 2
+	// WireVersion is the newest version known to this node
+	WireVersion = WireVersionProto
+)
+
+// wireMode is the encoding of the RPC payloads of a connection, negotiated
+// by the handshake
+type wireMode struct {
+	codec   string
+	version int
+}
+
+// framed reports whether the payloads travel in wireFrames
+func (m wireMode) framed() bool {
+	return m.codec != "" || m.version >= WireVersionProto
+}
+
+// negotiateWireVersion returns the version of a connection with a peer
+// knowing versions up to remote, 0 for the peers predating the negotiation
+func negotiateWireVersion(local, remote int) int {
+	switch {
+	case remote < WireVersionJSON:
+		return WireVersionJSON
+	case remote < local:
+		return remote
+	}
+	return local
+}
+
+// ParseWireCompression validates the name of a wire codec, empty meaning
+// none
+func ParseWireCompression(name string) (string, error) {
+	switch name {
+	case "", WireNone:
+		return "", nil
+	case WireGzip, WireSnappy:
+		return name, nil
+	}
+	return "", fmt.Errorf("wire compression must be none, gzip or snappy, got %q", name)
+}
+
+// SetWireCompression makes the transport ask for codec on the connections it
+// opens. The listener answers with the codec it accepts, so peers without
+// compression keep talking plain JSON. A listener accepts every codec it
+// knows, whatever its own setting.
+func (n *NetworkTransport) SetWireCompression(codec string) error {
+	codec, err := ParseWireCompression(codec)
+	if err != nil {
+		return err
+	}
+	n.wireCodec = codec
+	return nil
+}
+
+// SetWireVersion makes the transport ask for a wire format version on the
+// connections it opens. Both sides then use the oldest version they know, so
+// that mixed-version networks interoperate. A listener accepts every version
+// it knows, whatever its own setting.
+func (n *NetworkTransport) SetWireVersion(version int) error {
+	if version < WireVersionJSON || version > WireVersion {
+		return fmt.Errorf("wire version must be between %d and %d, got %d", WireVersionJSON, WireVersion, version)
+	}
+	n.wireVersion = version
+	return nil
+}
+
+// protoPayload is implemented by the RPC payloads whose events, blocks or
+// frame travel as protobuf in the WireVersionProto format
+type protoPayload interface {
+	// splitProto returns a copy of the payload without them, and them
+	splitProto() (interface{}, *poset.WirePayload)
+	// joinProto puts them back into the payload
+	joinProto(*poset.WirePayload)
+}
+
+func (r *SyncResponse) splitProto() (interface{}, *poset.WirePayload) {
+	c := *r
+	c.Events = nil
+	return &c, poset.NewWirePayload(r.Events, nil, nil)
+}
+
+func (r *SyncResponse) joinProto(p *poset.WirePayload) {
+	r.Events = p.WireEvents()
+}
+
+func (r *EagerSyncRequest) splitProto() (interface{}, *poset.WirePayload) {
+	c := *r
+	c.Events = nil
+	return &c, poset.NewWirePayload(r.Events, nil, nil)
+}
+
+func (r *EagerSyncRequest) joinProto(p *poset.WirePayload) {
+	r.Events = p.WireEvents()
+}
+
+func (r *FastForwardResponse) splitProto() (interface{}, *poset.WirePayload) {
+	c := *r
+	c.Block, c.Frame = poset.Block{}, poset.Frame{}
+	frame := r.Frame
+	return &c, poset.NewWirePayload(nil, []poset.Block{r.Block}, &frame)
+}
+
+func (r *FastForwardResponse) joinProto(p *poset.WirePayload) {
+	if len(p.Blocks) > 0 {
+		r.Block = *p.Blocks[0]
+	}
+	if p.Frame != nil {
+		r.Frame = *p.Frame
+	}
+}
+
+func (r *BlocksResponse) splitProto() (interface{}, *poset.WirePayload) {
+	c := *r
+	c.Blocks = nil
+	return &c, poset.NewWirePayload(nil, r.Blocks, nil)
+}
+
+func (r *BlocksResponse) joinProto(p *poset.WirePayload) {
+	r.Blocks = p.BlockList()
+}
+
+// wireFrame carries an RPC payload on a compressed or protobuf connection:
+// either the JSON Payload as is, when it is small, or its compressed Data,
+// and the protobuf part of a protoPayload in Proto, compressed alike. All
+// the events of a sync travel in a single frame.
+type wireFrame struct {
+	Codec   string          `json:",omitempty"`
+	Payload json.RawMessage `json:",omitempty"`
+	Data    []byte          `json:",omitempty"`
+	Proto   []byte          `json:",omitempty"`
+}
+
+// encodePayload writes v, in a wireFrame when the mode is framed
+func encodePayload(enc *json.Encoder, mode wireMode, v interface{}) error {
+	if !mode.framed() {
+		return enc.Encode(v)
+	}
+	var pb []byte
+	if p, ok := v.(protoPayload); ok && mode.version >= WireVersionProto {
+		var payload *poset.WirePayload
+		v, payload = p.splitProto()
+		var err error
+		if pb, err = payload.ProtoMarshal(); err != nil {
+			return err
+		}
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if mode.codec == "" || len(raw)+len(pb) < wireCompressMin {
+		return enc.Encode(&wireFrame{Payload: raw, Proto: pb})
+	}
+	frame := wireFrame{Codec: mode.codec}
+	if frame.Data, err = compressWire(mode.codec, raw); err != nil {
+		return err
+	}
+	if len(pb) > 0 {
+		if frame.Proto, err = compressWire(mode.codec, pb); err != nil {
+			return err
+		}
+	}
+	metrics.IncrCounter("net.wire.raw_bytes", int64(len(raw)+len(pb)))
+	metrics.IncrCounter("net.wire.compressed_bytes", int64(len(frame.Data)+len(frame.Proto)))
+	return enc.Encode(&frame)
+}
+
+// decodePayload reads v, from a wireFrame when the mode is framed
+func decodePayload(dec *json.Decoder, mode wireMode, v interface{}) error {
+	if !mode.framed() {
+		return dec.Decode(v)
+	}
+	var frame wireFrame
+	if err := dec.Decode(&frame); err != nil {
+		return err
+	}
+	raw, pb := []byte(frame.Payload), frame.Proto
+	if frame.Codec != "" {
+		var err error
+		if raw, err = decompressWire(frame.Codec, frame.Data); err != nil {
+			return err
+		}
+		if len(pb) > 0 {
+			if pb, err = decompressWire(frame.Codec, pb); err != nil {
+				return err
+			}
+		}
+	}
+	if len(raw) == 0 {
+		return fmt.Errorf("empty wire frame")
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return err
+	}
+	if len(pb) == 0 {
+		return nil
+	}
+	p, ok := v.(protoPayload)
+	if !ok {
+		return fmt.Errorf("unexpected protobuf in wire frame of %T", v)
+	}
+	var payload poset.WirePayload
+	if err := payload.ProtoUnmarshal(pb); err != nil {
+		return err
+	}
+	p.joinProto(&payload)
+	return nil
+}
+
+func compressWire(codec string, raw []byte) ([]byte, error) {
+	switch codec {
+	case WireSnappy:
+		return snappy.Encode(nil, raw), nil
+	case WireGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(raw); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown wire compression %q", codec)
+}
+
+func decompressWire(codec string, data []byte) ([]byte, error) {
+	switch codec {
+	case WireSnappy:
+		return snappy.Decode(nil, data)
+	case WireGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	}
+	return nil, fmt.Errorf("unknown wire compression %q", codec)
+}
@@ -0,0 +1,138 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+func TestWireCompression(t *testing.T) {
+	known := make(map[int64]int64)
+	for i := int64(0); i < 200; i++ {
+		known[i] = i * 1000
+	}
+
+	newTransport := func(codec string) *NetworkTransport {
+		trans, err := NewTCPTransport("127.0.0.1:0", nil, 2, time.Second, common.NewTestLogger(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := trans.SetWireCompression(codec); err != nil {
+			t.Fatal(err)
+		}
+		trans.Listen()
+		return trans
+	}
+
+	server := newTransport(WireNone)
+	defer server.Close()
+	go func() {
+		for rpc := range server.Consumer() {
+			req := rpc.Command.(*SyncRequest)
+			rpc.Respond(&SyncResponse{FromID: req.FromID, Known: known}, nil)
+		}
+	}()
+
+	// every codec, and none, reaches a listener without compression of its
+	// own, with small requests and large responses
+	for _, codec := range []string{WireNone, WireGzip, WireSnappy} {
+		client := newTransport(codec)
+		defer client.Close()
+		for i := int64(0); i < 2; i++ {
+			var resp SyncResponse
+			if assert.NoError(t, client.Sync(server.LocalAddr(), &SyncRequest{FromID: i, Known: known}, &resp), codec) {
+				assert.Equal(t, i, resp.FromID, codec)
+				assert.Equal(t, known, resp.Known, codec)
+			}
+		}
+		// the codec was negotiated on the pooled connection
+		if conn := client.getPooledConn(server.LocalAddr()); assert.NotNil(t, conn) {
+			expected, _ := ParseWireCompression(codec)
+			assert.Equal(t, expected, conn.wire.codec)
+		}
+	}
+
+	assert.Error(t, server.SetWireCompression("lz4"))
+}
+
+func TestWireVersion(t *testing.T) {
+	events := []poset.WireEvent{{
+		Body: poset.WireBody{
+			Transactions: [][]byte{[]byte("tx1"), []byte("tx2")},
+			InternalTransactions: []poset.InternalTransaction{{
+				Type:  poset.TransactionType_PARAM_CHANGE,
+				Param: &poset.ParamChange{Name: "sync_limit", Value: "10", ActivationRound: 5},
+			}},
+			BlockSignatures:      []poset.WireBlockSignature{{Index: 3, Signature: "sig"}},
+			SelfParentIndex:      1,
+			OtherParentCreatorID: 2,
+			OtherParentIndex:     3,
+			CreatorID:            4,
+			Index:                2,
+		},
+		Signature:    "event-sig",
+		FlagTable:    []byte{1, 2, 3},
+		WitnessProof: []string{"0xA"},
+	}}
+	known := map[int64]int64{0: 1, 1: 2}
+
+	newTransport := func(version int, codec string) *NetworkTransport {
+		trans, err := NewTCPTransport("127.0.0.1:0", nil, 2, time.Second, common.NewTestLogger(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := trans.SetWireVersion(version); err != nil {
+			t.Fatal(err)
+		}
+		if err := trans.SetWireCompression(codec); err != nil {
+			t.Fatal(err)
+		}
+		trans.Listen()
+		return trans
+	}
+
+	server := newTransport(WireVersionJSON, WireNone)
+	defer server.Close()
+	go func() {
+		for rpc := range server.Consumer() {
+			switch cmd := rpc.Command.(type) {
+			case *SyncRequest:
+				rpc.Respond(&SyncResponse{FromID: cmd.FromID, Events: events, Known: known}, nil)
+			case *EagerSyncRequest:
+				rpc.Respond(&EagerSyncResponse{FromID: cmd.FromID, Success: assert.ObjectsAreEqual(events, cmd.Events)}, nil)
+			}
+		}
+	}()
+
+	// every version reaches a listener whatever its own setting, with and
+	// without compression
+	for _, version := range []int{WireVersionJSON, WireVersionProto} {
+		for _, codec := range []string{WireNone, WireSnappy} {
+			client := newTransport(version, codec)
+			defer client.Close()
+
+			var resp SyncResponse
+			if assert.NoError(t, client.Sync(server.LocalAddr(), &SyncRequest{FromID: 1, Known: known}, &resp)) {
+				assert.Equal(t, events, resp.Events, "version %d, %s", version, codec)
+				assert.Equal(t, known, resp.Known)
+			}
+			var eagerResp EagerSyncResponse
+			if assert.NoError(t, client.EagerSync(server.LocalAddr(), &EagerSyncRequest{FromID: 1, Events: events}, &eagerResp)) {
+				assert.True(t, eagerResp.Success, "version %d, %s", version, codec)
+			}
+			if conn := client.getPooledConn(server.LocalAddr()); assert.NotNil(t, conn) {
+				assert.Equal(t, version, conn.wire.version)
+			}
+		}
+	}
+
+	// nodes predating the negotiation speak JSON
+	assert.Equal(t, WireVersionJSON, negotiateWireVersion(WireVersionProto, 0))
+	assert.Equal(t, WireVersionJSON, negotiateWireVersion(WireVersionJSON, WireVersionProto))
+	assert.Equal(t, WireVersionProto, negotiateWireVersion(WireVersionProto, WireVersionProto+1))
+	assert.Error(t, server.SetWireVersion(WireVersion+1))
+}
@@ -0,0 +1,84 @@
+package node
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+// Attestation is a stats document signed by the key of a validator, so that
+// monitoring aggregators can trust the health data collected from operators
+type Attestation struct {
+	Stats     map[string]string `json:"stats"`
+	Validator string            `json:"validator"`
+	// Timestamp is the signing time in Unix nanoseconds
+	Timestamp int64 `json:"timestamp"`
+	// Nonce is chosen by the requester to prove freshness, optional
+	Nonce     string `json:"nonce,omitempty"`
+	Signature string `json:"signature"`
+}
+
+// Hash returns the hash signed by the validator. Stats are encoded as a JSON
+// object, whose keys are sorted.
+func (a *Attestation) Hash() ([]byte, error) {
+	stats, err := json.Marshal(a.Stats)
+	if err != nil {
+		return nil, err
+	}
+	data := fmt.Sprintf("lachesis-stats:%s:%d:%s:", a.Validator, a.Timestamp, a.Nonce)
+	return crypto.SHA256(append([]byte(data), stats...)), nil
+}
+
+// SignAttestation signs stats with key
+func SignAttestation(stats map[string]string, key *ecdsa.PrivateKey, ts time.Time, nonce string) (Attestation, error) {
+	a := Attestation{
+		Stats:     stats,
+		Validator: fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey)),
+		Timestamp: ts.UnixNano(),
+		Nonce:     nonce,
+	}
+	hash, err := a.Hash()
+	if err != nil {
+		return a, err
+	}
+	r, s, err := crypto.Sign(key, hash)
+	if err != nil {
+		return a, err
+	}
+	a.Signature = crypto.EncodeSignature(r, s)
+	return a, nil
+}
+
+// Verify checks the signature of the validator
+func (a *Attestation) Verify() error {
+	pub, err := hex.DecodeString(strings.TrimPrefix(a.Validator, "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid validator %q: %s", a.Validator, err)
+	}
+	key := crypto.ToECDSAPub(pub)
+	if key == nil || key.X == nil {
+		return fmt.Errorf("invalid validator %q", a.Validator)
+	}
+	r, s, err := crypto.DecodeSignature(a.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %s", err)
+	}
+	hash, err := a.Hash()
+	if err != nil {
+		return err
+	}
+	if !crypto.Verify(key, hash, r, s) {
+		return fmt.Errorf("signature of %s does not match the stats", a.Validator)
+	}
+	return nil
+}
+
+// AttestStats returns the current stats signed by the key of the node
+func (n *Node) AttestStats(nonce string) (Attestation, error) {
+	return SignAttestation(n.GetStats(), n.core.key, time.Now(), nonce)
+}
@@ -0,0 +1,47 @@
+package node
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func TestAttestation(t *testing.T) {
+	key, err := crypto.GenerateECDSAKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := map[string]string{"state": "Gossiping", "last_block_index": "12"}
+
+	a, err := SignAttestation(stats, key, time.Now(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Verify(); err != nil {
+		t.Fatalf("attestation should verify: %s", err)
+	}
+
+	// an attestation survives its JSON encoding
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Attestation
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.Verify(); err != nil {
+		t.Fatalf("decoded attestation should verify: %s", err)
+	}
+
+	decoded.Stats["last_block_index"] = "13"
+	if err := decoded.Verify(); err == nil {
+		t.Fatal("tampered stats should not verify")
+	}
+	a.Nonce = "abd"
+	if err := a.Verify(); err == nil {
+		t.Fatal("another nonce should not verify")
+	}
+}
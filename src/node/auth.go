@@ -0,0 +1,37 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+)
+
+// authenticate checks the caller of an RPC may be answered, see
+// Config.AuthenticateRPC. The transport verified the caller controls the
+// key of rpc.Identity with a signed challenge when the connection opened,
+// so that its requests need not be signed one by one.
+func (n *Node) authenticate(rpc net.RPC) error {
+	if !n.conf.AuthenticateRPC {
+		return nil
+	}
+	switch rpc.Command.(type) {
+	case *net.PeersRequest:
+		// the peer discovery probes the nodes before they know each other
+		return nil
+	case *net.SyncRequest:
+		if n.conf.AllowAnonymousSync {
+			return nil
+		}
+	}
+	if rpc.Identity == "" {
+		return fmt.Errorf("unauthenticated connection")
+	}
+	participants := n.peerSelector.Peers()
+	participants.RLock()
+	_, ok := participants.ByPubKey[rpc.Identity]
+	participants.RUnlock()
+	if !ok {
+		return fmt.Errorf("%s is not a known peer", rpc.Identity)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+)
+
+func TestAuthenticate(t *testing.T) {
+	logger := common.NewTestLogger(t)
+
+	keys, ps := initPeers(2)
+	nodes := initNodes(keys, ps, 1000, 1000, "inmem", logger, t)
+	defer shutdownNodes(nodes)
+	node := nodes[0]
+
+	known := fmt.Sprintf("0x%X", crypto.FromECDSAPub(&keys[1].PublicKey))
+	stranger, _ := crypto.GenerateECDSAKey()
+	unknown := fmt.Sprintf("0x%X", crypto.FromECDSAPub(&stranger.PublicKey))
+
+	cases := []struct {
+		name     string
+		anonSync bool
+		command  interface{}
+		identity string
+		accepted bool
+	}{
+		{"known peer", false, &net.EagerSyncRequest{}, known, true},
+		{"unknown key", false, &net.EagerSyncRequest{}, unknown, false},
+		{"no identity", false, &net.EagerSyncRequest{}, "", false},
+		{"discovery probe", false, &net.PeersRequest{}, unknown, true},
+		{"anonymous sync refused", false, &net.SyncRequest{}, unknown, false},
+		{"anonymous sync allowed", true, &net.SyncRequest{}, unknown, true},
+		{"anonymous push refused", true, &net.EagerSyncRequest{}, "", false},
+	}
+
+	node.conf.AuthenticateRPC = true
+	for _, c := range cases {
+		node.conf.AllowAnonymousSync = c.anonSync
+		err := node.authenticate(net.RPC{Command: c.command, Identity: c.identity})
+		if (err == nil) != c.accepted {
+			t.Errorf("%s: accepted %v, error %v", c.name, c.accepted, err)
+		}
+	}
+
+	node.conf.AuthenticateRPC = false
+	if err := node.authenticate(net.RPC{Command: &net.EagerSyncRequest{}}); err != nil {
+		t.Fatalf("without authentication every RPC should be answered: %v", err)
+	}
+}
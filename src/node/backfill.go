@@ -0,0 +1,203 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	cm "github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// DefaultBackfillInterval is the time between two attempts to fill a gap of
+// the local block history
+const DefaultBackfillInterval = 30 * time.Second
+
+// backfillBatch is the maximum number of blocks requested, or served, at once
+const backfillBatch = 100
+
+// runBackfill periodically fetches the blocks missing from the local store,
+// such as the history preceding the anchor block of a fast-forward. Only
+// persistent stores are backfilled: the in-memory one keeps the last blocks
+// only.
+func (n *Node) runBackfill() {
+	if n.conf.BackfillInterval <= 0 || n.core.poset.Store.StorePath() == "" {
+		return
+	}
+	ticker := time.NewTicker(n.conf.BackfillInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if n.getState() == Gossiping {
+				n.backfill()
+			}
+		case <-n.shutdownCh:
+			return
+		}
+	}
+}
+
+// backfill requests the first gap of the local block history from the
+// peers in turn, until one serves valid blocks
+func (n *Node) backfill() {
+	from, to, ok := n.nextBlockGap()
+	if !ok {
+		return
+	}
+	logger := n.logger.WithFields(logrus.Fields{
+		"from": from,
+		"to":   to,
+	})
+	logger.Debug("Backfilling blocks")
+
+	_, sources := peers.ExcludePeer(n.peerSelector.Peers().ToPeerSlice(), n.localAddr)
+	for _, peer := range sources {
+		args := net.BlocksRequest{
+			FromID: n.id,
+			From:   from,
+			Limit:  int(to - from),
+		}
+		var out net.BlocksResponse
+		if err := n.trans.Blocks(peer.NetAddr, &args, &out); err != nil {
+			logger.WithFields(logrus.Fields{
+				"peer":  peer.NetAddr,
+				"error": err,
+			}).Debug("Requesting blocks")
+			continue
+		}
+		if len(out.Blocks) == 0 {
+			continue
+		}
+		if len(out.Blocks) > int(to-from) {
+			out.Blocks = out.Blocks[:to-from]
+		}
+		if err := n.storeBackfill(from, out.Blocks); err != nil {
+			metrics.IncrCounter("node.backfill.blocks.invalid", int64(len(out.Blocks)))
+			logger.WithFields(logrus.Fields{
+				"peer":  peer.NetAddr,
+				"error": err,
+			}).Warn("Peer served invalid blocks")
+			n.recordBehaviour(peer.PubKeyHex, ProtocolViolation)
+			continue
+		}
+		metrics.IncrCounter("node.backfill.blocks.fetched", int64(len(out.Blocks)))
+		logger.WithFields(logrus.Fields{
+			"peer":   peer.NetAddr,
+			"blocks": len(out.Blocks),
+		}).Info("Backfilled blocks")
+		return
+	}
+}
+
+// nextBlockGap returns the first range [from, to) of missing blocks, at most
+// backfillBatch long. The blocks before n.backfilled are known to be stored.
+func (n *Node) nextBlockGap() (from, to int64, ok bool) {
+	store := n.core.poset.Store
+	last := store.LastBlockIndex()
+
+	from = n.backfilled
+	for ; from <= last; from++ {
+		if _, err := store.GetBlock(from); err != nil {
+			if !cm.Is(err, cm.KeyNotFound) {
+				n.logger.WithFields(logrus.Fields{
+					"block": from,
+					"error": err,
+				}).Error("Reading block for backfill")
+				return 0, 0, false
+			}
+			break
+		}
+	}
+	n.backfilled = from
+	if from > last {
+		return 0, 0, false
+	}
+
+	to = from + 1
+	for ; to <= last && to-from < backfillBatch; to++ {
+		if _, err := store.GetBlock(to); err == nil {
+			break
+		}
+	}
+	return from, to, true
+}
+
+// storeBackfill checks that blocks are consecutive from index from, signed
+// by a quorum of the validators and chained with the neighbouring blocks,
+// then stores them. Blocks are chained when their rounds received increase
+// with their indexes and when they match the locally known frames.
+func (n *Node) storeBackfill(from int64, blocks []poset.Block) error {
+	n.coreLock.Lock()
+	defer n.coreLock.Unlock()
+
+	store := n.core.poset.Store
+	prevRound := int64(-1)
+	if from > 0 {
+		if prev, err := store.GetBlock(from - 1); err == nil {
+			prevRound = prev.RoundReceived()
+		}
+	}
+	for i, block := range blocks {
+		if block.Body == nil || block.Index() != from+int64(i) {
+			return fmt.Errorf("expected block %d", from+int64(i))
+		}
+		if block.RoundReceived() <= prevRound {
+			return fmt.Errorf("block %d received in round %d, not after round %d", block.Index(), block.RoundReceived(), prevRound)
+		}
+		prevRound = block.RoundReceived()
+		if err := n.core.poset.CheckBlock(block); err != nil {
+			return fmt.Errorf("block %d: %s", block.Index(), err)
+		}
+		if frame, err := store.GetFrame(block.RoundReceived()); err == nil {
+			hash, err := frame.Hash()
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(hash, block.GetFrameHash()) {
+				return fmt.Errorf("block %d does not match the frame of round %d", block.Index(), block.RoundReceived())
+			}
+		}
+	}
+	next := from + int64(len(blocks))
+	if block, err := store.GetBlock(next); err == nil && block.RoundReceived() <= prevRound {
+		return fmt.Errorf("block %d received in round %d, not after round %d", next, block.RoundReceived(), prevRound)
+	}
+
+	for _, block := range blocks {
+		if err := store.SetBlock(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *Node) processBlocksRequest(rpc net.RPC, cmd *net.BlocksRequest) {
+	n.logger.WithFields(logrus.Fields{
+		"from_id": cmd.FromID,
+		"from":    cmd.From,
+		"limit":   cmd.Limit,
+	}).Debug("processBlocksRequest(rpc net.RPC, cmd *net.BlocksRequest)")
+
+	limit := cmd.Limit
+	if limit <= 0 || limit > backfillBatch {
+		limit = backfillBatch
+	}
+	resp := &net.BlocksResponse{
+		FromID: n.id,
+	}
+	for i := cmd.From; i < cmd.From+int64(limit); i++ {
+		block, err := n.core.poset.Store.GetBlock(i)
+		if err != nil {
+			break
+		}
+		resp.Blocks = append(resp.Blocks, block)
+	}
+	metrics.IncrCounter("node.backfill.blocks.served", int64(len(resp.Blocks)))
+	rpc.Respond(resp, nil)
+}
@@ -0,0 +1,141 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// serveBlocks answers Blocks requests with the requested range of blocks
+func serveBlocks(trans net.Transport, blocks []poset.Block, stop chan struct{}) {
+	for {
+		select {
+		case rpc := <-trans.Consumer():
+			cmd := rpc.Command.(*net.BlocksRequest)
+			resp := &net.BlocksResponse{}
+			for i := cmd.From; i < cmd.From+int64(cmd.Limit) && i < int64(len(blocks)); i++ {
+				resp.Blocks = append(resp.Blocks, blocks[i])
+			}
+			rpc.Respond(resp, nil)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	cores, keys, _ := initCores(3, t)
+	store := cores[0].poset.Store
+
+	signed := make([]poset.Block, 4)
+	unsigned := make([]poset.Block, 4)
+	for i := range signed {
+		txs := [][]byte{[]byte(fmt.Sprintf("tx%d", i))}
+		signed[i] = poset.NewBlock(int64(i), int64(i+1), []byte("frame"), txs)
+		unsigned[i] = poset.NewBlock(int64(i), int64(i+1), []byte("frame"), txs)
+		for _, key := range keys {
+			sig, err := signed[i].Sign(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signed[i].SetSignature(sig)
+		}
+	}
+	// blocks 1 and 2 are missing, as before the anchor of a fast-forward
+	for _, i := range []int{0, 3} {
+		if err := store.SetBlock(signed[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	localAddr, local := net.NewInmemTransport("")
+	badAddr, bad := net.NewInmemTransport("")
+	goodAddr, good := net.NewInmemTransport("")
+	defer local.Close()
+	defer bad.Close()
+	defer good.Close()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go serveBlocks(bad, unsigned, stop)
+	go serveBlocks(good, signed, stop)
+
+	participants := peers.NewPeers()
+	participants.AddPeer(peers.NewPeer("0xLOCAL", localAddr))
+	participants.AddPeer(peers.NewPeer("0xBAD", badAddr))
+
+	n := &Node{
+		conf:         TestConfig(t),
+		logger:       common.NewTestLogger(t).WithField("this_id", 0),
+		core:         cores[0],
+		localAddr:    localAddr,
+		trans:        local,
+		peerSelector: NewRandomPeerSelector(participants, localAddr),
+		reputation:   NewReputation(),
+		bans:         peers.NewBanList(),
+	}
+
+	from, to, ok := n.nextBlockGap()
+	if !ok || from != 1 || to != 3 {
+		t.Fatalf("expected gap [1, 3), got [%d, %d) %v", from, to, ok)
+	}
+
+	// unsigned blocks are refused
+	n.backfill()
+	if _, err := store.GetBlock(1); err == nil {
+		t.Fatal("unsigned blocks should not be stored")
+	}
+	if n.reputation.Score("0xBAD") >= 0 {
+		t.Fatal("serving invalid blocks should lower the reputation")
+	}
+
+	participants.AddPeer(peers.NewPeer("0xGOOD", goodAddr))
+	n.backfill()
+	for i := int64(1); i < 3; i++ {
+		block, err := store.GetBlock(i)
+		if err != nil {
+			t.Fatalf("block %d not backfilled: %v", i, err)
+		}
+		if !block.Body.Equals(signed[i].Body) {
+			t.Fatalf("block %d differs", i)
+		}
+	}
+	if _, _, ok := n.nextBlockGap(); ok {
+		t.Fatal("no gap should remain")
+	}
+}
+
+func TestStoreBackfillChain(t *testing.T) {
+	cores, keys, _ := initCores(3, t)
+	n := &Node{core: cores[0]}
+
+	block := func(index, round int64) poset.Block {
+		b := poset.NewBlock(index, round, []byte("frame"), nil)
+		for _, key := range keys {
+			sig, _ := b.Sign(key)
+			b.SetSignature(sig)
+		}
+		return b
+	}
+	if err := cores[0].poset.Store.SetBlock(block(3, 10)); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, blocks := range map[string][]poset.Block{
+		"gap in indexes":    {block(0, 1), block(2, 2)},
+		"decreasing rounds": {block(0, 5), block(1, 4)},
+		"after next block":  {block(0, 1), block(1, 2), block(2, 10)},
+		"unexpected first":  {block(1, 1)},
+	} {
+		if err := n.storeBackfill(0, blocks); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if err := n.storeBackfill(0, []poset.Block{block(0, 1), block(1, 2), block(2, 3)}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,49 @@
+package node
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+)
+
+func TestCheckpoints(t *testing.T) {
+	logger := common.NewTestLogger(t)
+
+	keys, ps := initPeers(4)
+	nodes := initNodes(keys, ps, 1000, 1000, "inmem", logger, t)
+	for _, n := range nodes {
+		n.core.poset.SetCheckpointInterval(2)
+	}
+
+	if err := gossip(nodes, 10, true, 6*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint, err := nodes[0].GetCheckpoint(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.FromRound != 0 || checkpoint.ToRound != 1 || len(checkpoint.Root) == 0 {
+		t.Fatalf("checkpoint 0 should cover rounds 0 to 1 with a root, got %+v", checkpoint)
+	}
+	block, err := nodes[0].GetBlock(checkpoint.Block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !block.IsCheckpoint() || !bytes.Equal(block.CheckpointRoot(), checkpoint.Root) {
+		t.Fatalf("block %d should carry the root of checkpoint 0", checkpoint.Block)
+	}
+
+	// the checkpoints are part of the chain of every node
+	for _, n := range nodes[1:] {
+		other, err := n.GetCheckpoint(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other.Block != checkpoint.Block || !bytes.Equal(other.Root, checkpoint.Root) {
+			t.Fatalf("node %d checkpoint 0 should be %+v, not %+v", n.ID(), checkpoint, other)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package node
+
+import (
+	"fmt"
+
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy"
+)
+
+// checkTx asks the application whether tx is valid, when its proxy
+// validates transactions. A rejected transaction gets an InvalidTx error.
+func (n *Node) checkTx(tx []byte) error {
+	p, ok := n.proxy.(proxy.CheckingAppProxy)
+	if !ok {
+		return nil
+	}
+	if err := p.CheckTx(tx); err != nil {
+		return lerrors.New(lerrors.InvalidTx, "transaction rejected by the application: %s", err)
+	}
+	return nil
+}
+
+// checkEventTxs validates the transactions of the events received from a
+// peer, see Config.CheckSyncedTxs
+func (n *Node) checkEventTxs(events []poset.WireEvent) error {
+	for _, we := range events {
+		for _, tx := range we.Body.Transactions {
+			if err := n.checkTx(tx); err != nil {
+				metrics.IncrCounter("node.sync.invalid_txs", 1)
+				return fmt.Errorf("event %d of creator %d: %s", we.Body.Index, we.Body.CreatorID, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/memory"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy"
+)
+
+// checkingHandler rejects the transactions starting with "bad"
+type checkingHandler struct {
+	proxy.ProxyHandler
+}
+
+func (h checkingHandler) CheckTxHandler(tx []byte) error {
+	if bytes.HasPrefix(tx, []byte("bad")) {
+		return fmt.Errorf("bad transaction")
+	}
+	return nil
+}
+
+func TestCheckTx(t *testing.T) {
+	cores, _, _ := initCores(1, t)
+	conf := TestConfig(t)
+	n := &Node{
+		conf:   conf,
+		logger: common.NewTestLogger(t).WithField("this_id", 0),
+		core:   cores[0],
+		proxy:  proxy.NewInmemAppProxy(checkingHandler{}, common.NewTestLogger(t)),
+		budget: memory.NewBudget(0),
+		txs:    newTxTracker(),
+	}
+
+	if err := n.addTransaction([]byte("bad tx")); !lerrors.Is(err, lerrors.InvalidTx) {
+		t.Fatalf("expected an InvalidTx error, got %v", err)
+	}
+	if err := n.addTransaction([]byte("good tx")); err != nil {
+		t.Fatal(err)
+	}
+	if l := len(n.core.transactionPool); l != 1 {
+		t.Fatalf("expected 1 transaction in the pool, got %d", l)
+	}
+
+	events := []poset.WireEvent{{Body: poset.WireBody{
+		Transactions: [][]byte{[]byte("good tx"), []byte("bad tx")},
+	}}}
+	if err := n.checkEventTxs(events); err == nil {
+		t.Fatal("an event with an invalid transaction should be refused")
+	}
+	conf.CheckSyncedTxs = true
+	if err := n.sync(events); err == nil {
+		t.Fatal("the sync of an event with an invalid transaction should fail")
+	}
+}
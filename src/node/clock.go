@@ -0,0 +1,66 @@
+package node
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/clock"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// clockCheckInterval is the period of the clock drift checks
+const clockCheckInterval = time.Minute
+
+// ntpTimeout bounds NTP queries
+const ntpTimeout = 5 * time.Second
+
+func (n *Node) runClockCheck() {
+	ticker := time.NewTicker(clockCheckInterval)
+	defer ticker.Stop()
+	for {
+		n.checkClock()
+		select {
+		case <-ticker.C:
+		case <-n.shutdownCh:
+			return
+		}
+	}
+}
+
+// checkClock refreshes the NTP offset and warns about the local clock and
+// the peers drifting by more than MaxClockDrift
+func (n *Node) checkClock() {
+	if n.conf.NTPServer != "" {
+		offset, err := clock.QueryNTP(n.conf.NTPServer, ntpTimeout)
+		if err != nil {
+			n.logger.WithFields(logrus.Fields{
+				"server": n.conf.NTPServer,
+				"error":  err,
+			}).Debug("Querying NTP server")
+		} else {
+			n.clock.SetNTP(offset)
+		}
+	}
+
+	offset, ok := n.clock.Offset()
+	if !ok {
+		return
+	}
+	metrics.SetGauge("clock.offset_ms", float64(offset/time.Millisecond))
+	if n.clock.Drifting() {
+		n.logger.WithFields(logrus.Fields{
+			"offset":    offset,
+			"max_drift": n.clock.MaxDrift,
+		}).Warn("LOCAL CLOCK IS DRIFTING, synchronize it with NTP: the events of this node carry skewed timestamps")
+	}
+	if skewed := n.clock.SkewedPeers(); len(skewed) > 0 {
+		n.logger.WithField("peers", skewed).Warn("Peers with skewed clocks")
+	}
+}
+
+// GetClockOffset returns the estimated offset of the local clock, false when
+// nothing was measured yet
+func (n *Node) GetClockOffset() (time.Duration, bool) {
+	return n.clock.Offset()
+}
@@ -0,0 +1,181 @@
+package node
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// DefaultCommitBuffer is the default of Config.CommitBuffer
+const DefaultCommitBuffer = 400
+
+// commitQueue holds the decided blocks until the application committed them,
+// so that a slow application does not stall the consensus and the gossip.
+// Past the high-water mark the blocks, which the poset stored before deciding
+// them, are spooled: only their index is queued and they are read back from
+// the store when their turn comes.
+type commitQueue struct {
+	sync.Mutex
+	highWater int
+	blocks    []poset.Block
+	// spooled are the indexes of the blocks queued after blocks, in order
+	spooled []int64
+	// readyCh is signalled when a block is queued
+	readyCh chan struct{}
+}
+
+func newCommitQueue(highWater int) *commitQueue {
+	return &commitQueue{
+		highWater: highWater,
+		readyCh:   make(chan struct{}, 1),
+	}
+}
+
+// push queues block and returns the number of spooled blocks. A block is
+// spooled at the high-water mark, or when blocks before it are, to keep the
+// order.
+func (q *commitQueue) push(block poset.Block) int {
+	q.Lock()
+	if len(q.spooled) > 0 || len(q.blocks) >= q.highWater {
+		q.spooled = append(q.spooled, block.Index())
+	} else {
+		q.blocks = append(q.blocks, block)
+	}
+	spooled := len(q.spooled)
+	q.Unlock()
+
+	select {
+	case q.readyCh <- struct{}{}:
+	default:
+	}
+	return spooled
+}
+
+// next removes the first block of the queue, read with load when it was
+// spooled. ok is false when the queue is empty.
+func (q *commitQueue) next(load func(int64) (poset.Block, error)) (block poset.Block, ok bool, err error) {
+	q.Lock()
+	if len(q.blocks) > 0 {
+		block = q.blocks[0]
+		q.blocks[0] = poset.Block{}
+		q.blocks = q.blocks[1:]
+		q.Unlock()
+		return block, true, nil
+	}
+	if len(q.spooled) == 0 {
+		q.Unlock()
+		return block, false, nil
+	}
+	index := q.spooled[0]
+	q.spooled = q.spooled[1:]
+	q.Unlock()
+
+	block, err = load(index)
+	return block, true, err
+}
+
+// depth returns the number of queued blocks and how many of them are
+// spooled
+func (q *commitQueue) depth() (int, int) {
+	if q == nil {
+		return 0, 0
+	}
+	q.Lock()
+	defer q.Unlock()
+	return len(q.blocks) + len(q.spooled), len(q.spooled)
+}
+
+// queueCommits moves the blocks the poset decides from the commit channel
+// to the commit queue, so that the poset never waits for the application
+func (n *Node) queueCommits() {
+	for {
+		select {
+		case block := <-n.commitCh:
+			if spooled := n.commits.push(block); spooled > 0 {
+				metrics.IncrCounter("node.commit_queue.spooled", 1)
+				if spooled == 1 {
+					n.logger.WithField("block", block.Index()).Warn("Application behind, spooling the committed blocks to the store")
+				}
+			}
+			n.reportCommitQueue()
+		case <-n.shutdownCh:
+			return
+		}
+	}
+}
+
+// deliverCommits delivers the queued blocks to the application in order,
+// and the blocks asked for by Redeliver
+func (n *Node) deliverCommits() {
+	// the blocks of the store the application did not acknowledge before
+	// a restart
+	if err := n.deliverPending(n.core.GetLastBlockIndex()); err != nil {
+		n.logger.WithError(err).Warn("Delivering pending blocks")
+	}
+	for {
+		select {
+		case <-n.commits.readyCh:
+			n.commitQueued()
+		case from := <-n.redeliverCh:
+			n.redeliver(from)
+		case <-n.shutdownCh:
+			return
+		}
+	}
+}
+
+// commitQueued commits the queued blocks until the queue is empty
+func (n *Node) commitQueued() {
+	for {
+		select {
+		case <-n.shutdownCh:
+			return
+		default:
+		}
+		block, ok, err := n.commits.next(n.loadBlock)
+		if !ok {
+			return
+		}
+		n.reportCommitQueue()
+		if err != nil {
+			// the block is delivered with the next one, see deliver
+			metrics.IncrCounter("node.commit_queue.errors", 1)
+			n.logger.WithError(err).Error("Reading a spooled block")
+			continue
+		}
+		n.logger.WithFields(logrus.Fields{
+			"index":          block.Index(),
+			"round_received": block.RoundReceived(),
+			"transactions":   len(block.Transactions()),
+		}).Debug("Adding EventBlock")
+		if err := n.commit(block); err != nil {
+			n.logger.WithField("error", err).Error("Adding EventBlock")
+		}
+	}
+}
+
+// loadBlock reads a spooled block from the store
+func (n *Node) loadBlock(index int64) (poset.Block, error) {
+	n.coreLock.Lock()
+	defer n.coreLock.Unlock()
+	return n.core.poset.Store.GetBlock(index)
+}
+
+func (n *Node) reportCommitQueue() {
+	queued, spooled := n.commits.depth()
+	metrics.SetGauge("node.commit_queue.depth", float64(queued))
+	metrics.SetGauge("node.commit_queue.spooled_depth", float64(spooled))
+}
+
+// commitQueueStats returns the depth of the commit queue for GetStats
+func (n *Node) commitQueueStats() map[string]string {
+	queued, spooled := n.commits.depth()
+	return map[string]string{
+		"commit_queue":   strconv.Itoa(queued),
+		"commit_spooled": strconv.Itoa(spooled),
+	}
+}
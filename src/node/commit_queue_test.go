@@ -0,0 +1,59 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+func TestCommitQueueSpooling(t *testing.T) {
+	q := newCommitQueue(2)
+	stored := make(map[int64]poset.Block)
+	for i := int64(0); i < 4; i++ {
+		block := poset.NewBlock(i, i, nil, [][]byte{[]byte(fmt.Sprintf("tx%d", i))})
+		stored[i] = block
+		spooled := q.push(block)
+		if expected := int(i) - 1; i >= 2 && spooled != expected {
+			t.Fatalf("block %d: expected %d spooled blocks, got %d", i, expected, spooled)
+		}
+	}
+	if queued, spooled := q.depth(); queued != 4 || spooled != 2 {
+		t.Fatalf("expected 4 queued blocks, 2 of them spooled, got %d and %d", queued, spooled)
+	}
+
+	loaded := 0
+	load := func(index int64) (poset.Block, error) {
+		loaded++
+		return stored[index], nil
+	}
+	for i := int64(0); i < 4; i++ {
+		block, ok, err := q.next(load)
+		if !ok || err != nil {
+			t.Fatalf("block %d: ok %v, error %v", i, ok, err)
+		}
+		if block.Index() != i {
+			t.Fatalf("expected block %d, got %d", i, block.Index())
+		}
+		if i == 2 {
+			// a block decided while blocks are spooled is spooled after them
+			q.push(poset.NewBlock(4, 4, nil, nil))
+			stored[4] = poset.NewBlock(4, 4, nil, nil)
+		}
+	}
+	if block, ok, _ := q.next(load); !ok || block.Index() != 4 {
+		t.Fatal("expected block 4 last")
+	}
+	if loaded != 3 {
+		t.Fatalf("expected 3 blocks read from the store, got %d", loaded)
+	}
+	if _, ok, _ := q.next(load); ok {
+		t.Fatal("the queue should be empty")
+	}
+
+	// once the spool is drained the blocks are held in memory again
+	q.push(poset.NewBlock(5, 5, nil, nil))
+	if _, spooled := q.depth(); spooled != 0 {
+		t.Fatalf("expected no spooled block, got %d", spooled)
+	}
+}
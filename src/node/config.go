@@ -1,21 +1,180 @@
 package node
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/Fantom-foundation/go-lachesis/src/clock"
 	"github.com/Fantom-foundation/go-lachesis/src/common"
 	"github.com/Fantom-foundation/go-lachesis/src/log"
 	"github.com/sirupsen/logrus"
 )
 
+// DefaultBanDuration is how long a peer stays banned after repeated
+// protocol violations
+const DefaultBanDuration = 10 * time.Minute
+
+// DefaultSnapshotChunkSize is the size of the application snapshot chunks
+// served to fast-forwarding peers
+const DefaultSnapshotChunkSize = 1 << 20
+
+// DefaultMaxTxSize is the size above which submitted transactions are
+// refused
+const DefaultMaxTxSize = 1 << 20
+
+// DefaultMaxEventTxs is the maximum number of transactions in a self-event:
+// transactions of 120 bytes fit the 4MB gRPC message limit
+const DefaultMaxEventTxs = 16384
+
+// DefaultMaxEventBytes is the maximum size of the transactions of a
+// self-event, below the 4MB gRPC message limit
+const DefaultMaxEventBytes = 2 << 20
+
+// DefaultCommitRetries is the number of retries of a block the application
+// failed to commit
+const DefaultCommitRetries = 3
+
 type Config struct {
 	HeartbeatTimeout time.Duration `mapstructure:"heartbeat"`
 	TCPTimeout       time.Duration `mapstructure:"timeout"`
 	CacheSize        int           `mapstructure:"cache-size"`
 	SyncLimit        int64         `mapstructure:"sync-limit"`
-	Logger           *logrus.Logger
-	TestDelay uint64 `mapstructure:"test_delay"`
+	BanDuration      time.Duration `mapstructure:"ban-duration"`
+	Observer         bool          `mapstructure:"observer"`
+	MemoryBudget     int64         `mapstructure:"memory-budget"`
+	// MinHeartbeat is the time between gossips while the node has
+	// transactions, block signatures or loaded events to gossip, the
+	// heartbeat when 0
+	MinHeartbeat time.Duration `mapstructure:"min-heartbeat"`
+	// MaxHeartbeat is the time between gossips the heartbeat backs off to
+	// while the node is idle and receives no new events, the heartbeat when
+	// below it
+	MaxHeartbeat time.Duration `mapstructure:"max-heartbeat"`
+	// SnapshotChunkSize is the size of the snapshot chunks served to
+	// fast-forwarding peers, DefaultSnapshotChunkSize when 0
+	SnapshotChunkSize int `mapstructure:"snapshot-chunk-size"`
+	// MaxClockDrift is the clock offset, against NTP or the peers, above
+	// which the node warns
+	MaxClockDrift time.Duration `mapstructure:"max-clock-drift"`
+	// NTPServer is queried to measure the drift of the local clock, only
+	// the times reported by peers are used when empty
+	NTPServer string `mapstructure:"ntp-server"`
+	// BackfillInterval is the time between two requests for blocks missing
+	// from a persistent store, backfill is disabled when 0
+	BackfillInterval time.Duration `mapstructure:"backfill-interval"`
+	// PoolJournal is the file where the pending transactions and block
+	// signatures are journaled, to survive a crash; disabled when empty
+	PoolJournal string `mapstructure:"pool-journal"`
+	// PruneDepth is the number of rounds of events kept before the last
+	// anchor block in a badger store, older ones are pruned; 0 keeps all
+	PruneDepth int64 `mapstructure:"prune_depth"`
+	// CheckpointInterval is the number of consensus rounds between two
+	// checkpoint blocks, 0 disables them; it must be the same on all nodes
+	CheckpointInterval int64 `mapstructure:"checkpoint-interval"`
+	// CommitRetries is the number of times a block the application failed
+	// to commit is retried before it is left for the next delivery
+	CommitRetries int `mapstructure:"commit-retries"`
+	// RedeliverFrom is the index of the block from which the blocks are
+	// delivered again to the application when the node starts, -1 to only
+	// deliver those it did not acknowledge
+	RedeliverFrom int64 `mapstructure:"redeliver-from"`
+	// CommitBuffer is the number of decided blocks held in memory until the
+	// application committed them. The blocks decided past it are read back
+	// from the store when the application is ready for them.
+	CommitBuffer int `mapstructure:"commit-buffer"`
+	// TxIndex makes the store index the committed transactions by hash,
+	// with their block, position and event, for GetTxLocation
+	TxIndex bool `mapstructure:"tx-index"`
+	// Seeds are addresses probed for their key and peer records when the
+	// node starts, to discover the network
+	Seeds []string `mapstructure:"seeds"`
+	// AdvertiseAddr is the address the peers reach the node at, announced
+	// in its peer record and in the handshakes, when it differs from the
+	// one it listens on, e.g. behind NAT
+	AdvertiseAddr string `mapstructure:"advertise_addr"`
+	// MaxPeers is the size above which discovered peers are no longer
+	// added to the peer set, 0 for no limit
+	MaxPeers int `mapstructure:"max_peers"`
+	// DiscoveryInterval is the time between two rounds of probes of the
+	// discovered peer addresses, discovery is disabled when 0
+	DiscoveryInterval time.Duration `mapstructure:"discovery-interval"`
+	// ConsensusWorkers is the number of goroutines evaluating the
+	// strongly-see relations of the fame votes, 1 for none
+	ConsensusWorkers int `mapstructure:"consensus_workers"`
+	// GossipFanout is the number of peers gossiped with concurrently at
+	// every heartbeat, their responses being synced at once
+	GossipFanout int `mapstructure:"gossip_fanout"`
+	// GossipPeerInterval is the minimum time between two gossips of a
+	// fan-out with the same peer, the heartbeat when 0
+	GossipPeerInterval time.Duration `mapstructure:"gossip-peer-interval"`
+	// PeerSelector is the name of the peer selector of the gossip: smart,
+	// random, fair-round-robin or one registered with RegisterPeerSelector
+	PeerSelector string `mapstructure:"peer_selector"`
+	// MaxTxSize is the size above which submitted transactions are refused
+	// with a TxTooLarge error, 0 for no limit
+	MaxTxSize int `mapstructure:"max-tx-size"`
+	// MaxPoolBytes is the size of the transaction pool above which submitted
+	// transactions are refused with a MempoolFull error, 0 for no limit
+	MaxPoolBytes int64 `mapstructure:"max-pool-bytes"`
+	// MaxEventTxs and MaxEventBytes bound the transactions of a self-event,
+	// a larger pool is split across several self-events
+	MaxEventTxs   int `mapstructure:"max-event-txs"`
+	MaxEventBytes int `mapstructure:"max-event-bytes"`
+	// CheckSyncedTxs makes the node also validate with the application the
+	// transactions of the events it receives, refusing the events with an
+	// invalid one. The application validates the submitted transactions in
+	// any case, when the proxy supports it.
+	CheckSyncedTxs bool `mapstructure:"check-synced-txs"`
+	// SyncChunked exchanges the diffs larger than the sync limit in chunks
+	// of sync-limit events, each acknowledged before the next is sent,
+	// instead of making the node catch up. Peers without it answer with
+	// SyncLimit as before.
+	SyncChunked bool `mapstructure:"sync-chunked"`
+	// SignatureGossip pushes the signature of every block the node signs to
+	// all its peers at once, besides adding it to its next event, so that
+	// the blocks gather enough signatures even while no event is created.
+	// Every peer must support the SignatureGossip RPC.
+	SignatureGossip bool `mapstructure:"signature-gossip"`
+	// AuthenticateRPC only answers the connections which proved, in their
+	// handshake, the key of a peer of the peer set. The probes of the peer
+	// discovery are still answered, and the syncs too with
+	// AllowAnonymousSync, which only read the DAG, for monitoring tools.
+	AuthenticateRPC    bool `mapstructure:"authenticate-rpc"`
+	AllowAnonymousSync bool `mapstructure:"allow-anonymous-sync"`
+	// ReadyWindow is the time within which the node must have decided a
+	// consensus round, and synced with the peers it counts as connected,
+	// to be ready; 0 disables these checks
+	ReadyWindow time.Duration `mapstructure:"ready-window"`
+	// NetworkID is signed with the events and blocks and checked in the
+	// handshake with the peers, so that those of another network sharing
+	// keys are refused. It must be the same on all nodes.
+	NetworkID string `mapstructure:"network-id"`
+	// DedupWindow is the number of the last transactions remembered by
+	// hash, a transaction submitted again within them being dropped; 0
+	// disables the deduplication
+	DedupWindow int `mapstructure:"dedup-window"`
+	// HighPriorityPrefix puts the transactions starting with it in the high
+	// lane of the pool, see PrefixPolicy
+	HighPriorityPrefix string `mapstructure:"high-priority-prefix"`
+	// LatencyWindow is the number of the last committed transactions over
+	// which the percentiles of the commit latency are computed,
+	// DefaultLatencyWindow when 0
+	LatencyWindow int `mapstructure:"latency-window"`
+	// SlowTxThreshold is the commit latency above which a transaction is
+	// logged, 0 disabling the log
+	SlowTxThreshold time.Duration `mapstructure:"slow-tx-threshold"`
+	// SuspectMisses and DownMisses are the numbers of syncs in a row a peer
+	// must miss to be suspect and down, 0 disabling the state. Down peers
+	// are not selected for gossip but once every DownProbeInterval.
+	SuspectMisses     int           `mapstructure:"suspect-misses"`
+	DownMisses        int           `mapstructure:"down-misses"`
+	DownProbeInterval time.Duration `mapstructure:"down-probe-interval"`
+	// OrderingPolicy orders the pool of an embedded node, taking precedence
+	// over HighPriorityPrefix; the pool is FIFO when both are unset
+	OrderingPolicy OrderingPolicy
+	Logger        *logrus.Logger
+	TestDelay     uint64 `mapstructure:"test_delay"`
 }
 
 func NewConfig(heartbeat time.Duration,
@@ -25,11 +184,30 @@ func NewConfig(heartbeat time.Duration,
 	logger *logrus.Logger) *Config {
 
 	return &Config{
-		HeartbeatTimeout: heartbeat,
-		TCPTimeout:       timeout,
-		CacheSize:        cacheSize,
-		SyncLimit:        syncLimit,
-		Logger:           logger,
+		HeartbeatTimeout:  heartbeat,
+		TCPTimeout:        timeout,
+		CacheSize:         cacheSize,
+		SyncLimit:         syncLimit,
+		MaxHeartbeat:      DefaultMaxHeartbeat,
+		BanDuration:       DefaultBanDuration,
+		SnapshotChunkSize: DefaultSnapshotChunkSize,
+		MaxClockDrift:     clock.DefaultMaxDrift,
+		BackfillInterval:  DefaultBackfillInterval,
+		DiscoveryInterval: DefaultDiscoveryInterval,
+		ConsensusWorkers:  1,
+		GossipFanout:      1,
+		PeerSelector:      PeerSelectorSmart,
+		MaxTxSize:         DefaultMaxTxSize,
+		MaxEventTxs:       DefaultMaxEventTxs,
+		MaxEventBytes:     DefaultMaxEventBytes,
+		CommitRetries:     DefaultCommitRetries,
+		RedeliverFrom:     -1,
+		CommitBuffer:      DefaultCommitBuffer,
+		ReadyWindow:       DefaultReadyWindow,
+		SuspectMisses:     DefaultSuspectMisses,
+		DownMisses:        DefaultDownMisses,
+		DownProbeInterval: DefaultDownProbeInterval,
+		Logger:            logger,
 	}
 }
 
@@ -39,13 +217,109 @@ func DefaultConfig() *Config {
 	lachesis_log.NewLocal(logger, logger.Level.String())
 
 	return &Config{
-		HeartbeatTimeout: 10 * time.Millisecond,
-		TCPTimeout:       180 * 1000 * time.Millisecond,
-		CacheSize:        500,
-		SyncLimit:        100,
-		Logger:           logger,
-		TestDelay:        1,
+		HeartbeatTimeout:  10 * time.Millisecond,
+		TCPTimeout:        180 * 1000 * time.Millisecond,
+		CacheSize:         500,
+		SyncLimit:         100,
+		MaxHeartbeat:      DefaultMaxHeartbeat,
+		BanDuration:       DefaultBanDuration,
+		SnapshotChunkSize: DefaultSnapshotChunkSize,
+		MaxClockDrift:     clock.DefaultMaxDrift,
+		BackfillInterval:  DefaultBackfillInterval,
+		DiscoveryInterval: DefaultDiscoveryInterval,
+		ConsensusWorkers:  1,
+		GossipFanout:      1,
+		PeerSelector:      PeerSelectorSmart,
+		MaxTxSize:         DefaultMaxTxSize,
+		MaxEventTxs:       DefaultMaxEventTxs,
+		MaxEventBytes:     DefaultMaxEventBytes,
+		CommitRetries:     DefaultCommitRetries,
+		RedeliverFrom:     -1,
+		CommitBuffer:      DefaultCommitBuffer,
+		ReadyWindow:       DefaultReadyWindow,
+		SuspectMisses:     DefaultSuspectMisses,
+		DownMisses:        DefaultDownMisses,
+		DownProbeInterval: DefaultDownProbeInterval,
+		Logger:            logger,
+		TestDelay:         1,
+	}
+}
+
+// Validate checks the ranges of the settings
+func (c *Config) Validate() error {
+	switch {
+	case c.HeartbeatTimeout <= 0:
+		return fmt.Errorf("heartbeat must be positive, got %v", c.HeartbeatTimeout)
+	case c.MinHeartbeat < 0:
+		return fmt.Errorf("min-heartbeat must not be negative, got %v", c.MinHeartbeat)
+	case c.MaxHeartbeat < 0:
+		return fmt.Errorf("max-heartbeat must not be negative, got %v", c.MaxHeartbeat)
+	case c.TCPTimeout <= 0:
+		return fmt.Errorf("timeout must be positive, got %v", c.TCPTimeout)
+	case c.CacheSize < 1:
+		return fmt.Errorf("cache-size must be at least 1, got %d", c.CacheSize)
+	case c.SyncLimit < 1:
+		return fmt.Errorf("sync-limit must be at least 1, got %d", c.SyncLimit)
+	case c.BanDuration < 0:
+		return fmt.Errorf("ban-duration must not be negative, got %v", c.BanDuration)
+	case c.MemoryBudget < 0:
+		return fmt.Errorf("memory-budget must not be negative, got %d", c.MemoryBudget)
+	case c.SnapshotChunkSize < 0:
+		return fmt.Errorf("snapshot-chunk-size must not be negative, got %d", c.SnapshotChunkSize)
+	case c.MaxClockDrift < 0:
+		return fmt.Errorf("max-clock-drift must not be negative, got %v", c.MaxClockDrift)
+	case c.BackfillInterval < 0:
+		return fmt.Errorf("backfill-interval must not be negative, got %v", c.BackfillInterval)
+	case c.PruneDepth < 0:
+		return fmt.Errorf("prune_depth must not be negative, got %d", c.PruneDepth)
+	case c.CommitRetries < 0:
+		return fmt.Errorf("commit-retries must not be negative, got %d", c.CommitRetries)
+	case c.RedeliverFrom < -1:
+		return fmt.Errorf("redeliver-from must be a block index or -1, got %d", c.RedeliverFrom)
+	case c.CommitBuffer < 0:
+		return fmt.Errorf("commit-buffer must not be negative, got %d", c.CommitBuffer)
+	case c.CheckpointInterval < 0:
+		return fmt.Errorf("checkpoint-interval must not be negative, got %d", c.CheckpointInterval)
+	case c.MaxPeers < 0:
+		return fmt.Errorf("max_peers must not be negative, got %d", c.MaxPeers)
+	case c.ConsensusWorkers < 1:
+		return fmt.Errorf("consensus_workers must be at least 1, got %d", c.ConsensusWorkers)
+	case c.GossipFanout < 1:
+		return fmt.Errorf("gossip_fanout must be at least 1, got %d", c.GossipFanout)
+	case c.PeerSelector != "" && !isPeerSelector(c.PeerSelector):
+		return fmt.Errorf("peer_selector must be one of %v, got %q", PeerSelectors(), c.PeerSelector)
+	case c.GossipPeerInterval < 0:
+		return fmt.Errorf("gossip-peer-interval must not be negative, got %v", c.GossipPeerInterval)
+	case c.DiscoveryInterval < 0:
+		return fmt.Errorf("discovery-interval must not be negative, got %v", c.DiscoveryInterval)
+	case c.MaxTxSize < 0:
+		return fmt.Errorf("max-tx-size must not be negative, got %d", c.MaxTxSize)
+	case c.MaxPoolBytes < 0:
+		return fmt.Errorf("max-pool-bytes must not be negative, got %d", c.MaxPoolBytes)
+	case c.MaxEventTxs < 1:
+		return fmt.Errorf("max-event-txs must be at least 1, got %d", c.MaxEventTxs)
+	case c.MaxEventBytes < 1:
+		return fmt.Errorf("max-event-bytes must be at least 1, got %d", c.MaxEventBytes)
+	case c.DedupWindow < 0:
+		return fmt.Errorf("dedup-window must not be negative, got %d", c.DedupWindow)
+	case c.ReadyWindow < 0:
+		return fmt.Errorf("ready-window must not be negative, got %v", c.ReadyWindow)
+	case c.LatencyWindow < 0:
+		return fmt.Errorf("latency-window must not be negative, got %d", c.LatencyWindow)
+	case c.SlowTxThreshold < 0:
+		return fmt.Errorf("slow-tx-threshold must not be negative, got %v", c.SlowTxThreshold)
+	case c.SuspectMisses < 0:
+		return fmt.Errorf("suspect-misses must not be negative, got %d", c.SuspectMisses)
+	case c.DownMisses < 0:
+		return fmt.Errorf("down-misses must not be negative, got %d", c.DownMisses)
+	case c.DownMisses > 0 && c.DownMisses < c.SuspectMisses:
+		return fmt.Errorf("down-misses %d must not be below suspect-misses %d", c.DownMisses, c.SuspectMisses)
+	case c.DownProbeInterval < 0:
+		return fmt.Errorf("down-probe-interval must not be negative, got %v", c.DownProbeInterval)
+	case c.MaxTxSize > c.MaxEventBytes:
+		return fmt.Errorf("max-tx-size %d must not exceed max-event-bytes %d", c.MaxTxSize, c.MaxEventBytes)
 	}
+	return nil
 }
 
 func TestConfig(t *testing.T) *Config {
@@ -2,6 +2,7 @@ package node
 
 import (
 	"math/rand"
+	"sync/atomic"
 	"time"
 )
 
@@ -13,7 +14,7 @@ type ControlTimer struct {
 	resetCh      chan time.Duration //receives instruction to reset the heartbeatTimer
 	stopCh       chan struct{} //receives instruction to stop the heartbeatTimer
 	shutdownCh   chan struct{} //receives instruction to exit Run loop
-	set          bool
+	set          int32 //1 while the timer runs, accessed atomically
 }
 
 func NewControlTimer(timerFactory timerFactory) *ControlTimer {
@@ -41,7 +42,7 @@ func NewRandomControlTimer() *ControlTimer {
 func (c *ControlTimer) Run(init time.Duration) {
 
 	setTimer := func(t time.Duration) <-chan time.Time {
-		c.set = true
+		atomic.StoreInt32(&c.set, 1)
 		return c.timerFactory(t)
 	}
 
@@ -50,19 +51,24 @@ func (c *ControlTimer) Run(init time.Duration) {
 		select {
 		case <-timer:
 			c.tickCh <- struct{}{}
-			c.set = false
+			atomic.StoreInt32(&c.set, 0)
 		case t:= <-c.resetCh:
 			timer = setTimer(t)
 		case <-c.stopCh:
 			timer = nil
-			c.set = false
+			atomic.StoreInt32(&c.set, 0)
 		case <-c.shutdownCh:
-			c.set = false
+			atomic.StoreInt32(&c.set, 0)
 			return
 		}
 	}
 }
 
+// isSet returns true while the timer runs
+func (c *ControlTimer) isSet() bool {
+	return atomic.LoadInt32(&c.set) == 1
+}
+
 func (c *ControlTimer) Shutdown() {
 	close(c.shutdownCh)
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/Fantom-foundation/go-lachesis/src/crypto"
 	"github.com/Fantom-foundation/go-lachesis/src/log"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
 	"github.com/Fantom-foundation/go-lachesis/src/peers"
 	"github.com/Fantom-foundation/go-lachesis/src/poset"
 )
@@ -28,13 +29,38 @@ type Core struct {
 	head         string
 	Seq          int64
 
-	transactionPool         [][]byte
+	transactionPool [][]byte
+	// transactionPoolBytes is the size of the transactions of the pool
+	transactionPoolBytes    int64
 	internalTransactionPool []poset.InternalTransaction
 	blockSignaturePool      []poset.BlockSignature
+	// journal persists the pools, nil when disabled, see OpenJournal
+	journal *poolJournal
+	// batching is set between BeginBatch and EndBatch, which the checkpoints
+	// of the journal are deferred to while checkpointPending
+	batching          bool
+	checkpointPending bool
 
 	logger *logrus.Entry
 
 	maxTransactionsInEvent int
+	maxEventBytes          int
+
+	// observers receive gossip but never create events nor sign blocks
+	observer bool
+
+	// onSelfEvent is called with the self-events carrying transactions
+	onSelfEvent func(event poset.Event)
+
+	// dedup remembers the transactions seen recently, nil when duplicates
+	// are not dropped
+	dedup *txWindow
+
+	// ordering decides the lanes of the pool, nil when it is FIFO
+	ordering OrderingPolicy
+
+	// now is the clock of the timestamps of the self-events
+	now func() time.Time
 }
 
 func NewCore(id int64, key *ecdsa.PrivateKey, participants *peers.Peers,
@@ -52,7 +78,9 @@ func NewCore(id int64, key *ecdsa.PrivateKey, participants *peers.Peers,
 		inDegrees[pubKey] = 0
 	}
 
-	p2 := poset.NewPoset(participants, store, commitCh, logEntry)
+	p2 := poset.NewPoset(participants, store, commitCh,
+		logEntry.WithField(lachesis_log.ModuleField, "poset"))
+	logEntry = logEntry.WithField(lachesis_log.ModuleField, "core")
 	core := &Core{
 		id:                      id,
 		key:                     key,
@@ -67,8 +95,9 @@ func NewCore(id int64, key *ecdsa.PrivateKey, participants *peers.Peers,
 		Seq:                     -1,
 		// MaxReceiveMessageSize limitation in grpc: https://github.com/grpc/grpc-go/blob/master/clientconn.go#L96
 		// default value is 4 * 1024 * 1024 bytes
-		// we use transactions of 120 bytes in tester, thus rounding it down to 16384
-		maxTransactionsInEvent: 16384,
+		maxTransactionsInEvent: DefaultMaxEventTxs,
+		maxEventBytes:          DefaultMaxEventBytes,
+		now:                    time.Now,
 	}
 
 	p2.SetCore(core)
@@ -95,14 +124,73 @@ func (c *Core) HexID() string {
 	return c.hexID
 }
 
+// IsObserver returns true if the core does not take part in consensus
+func (c *Core) IsObserver() bool {
+	return c.observer
+}
+
 func (c *Core) Head() string {
 	return c.head
 }
 
+// PeerExchange returns the signed digest of the peers known to this core
+func (c *Core) PeerExchange() (*peers.PeerExchange, error) {
+	return peers.NewPeerExchange(c.participants.ToPeerSlice(), c.key)
+}
+
+// PeerExchangeProof returns the signed digest of the peers known to this
+// core, signed along with the nonce of a discovery probe
+func (c *Core) PeerExchangeProof(nonce []byte) (*peers.PeerExchange, error) {
+	pe, err := peers.NewPeerExchange(c.participants.ToPeerSlice(), c.key)
+	if err != nil {
+		return nil, err
+	}
+	pe.Nonce = nonce
+	if err := pe.Sign(c.key); err != nil {
+		return nil, err
+	}
+	return pe, nil
+}
+
+// VerifyPeerExchange checks that a digest was signed by the participant it
+// was received from
+func (c *Core) VerifyPeerExchange(fromID int64, pe *peers.PeerExchange) error {
+	c.participants.RLock()
+	sender, ok := c.participants.ById[fromID]
+	c.participants.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown peer exchange sender %d", fromID)
+	}
+
+	valid, err := pe.Verify(sender.PubKeyHex)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return fmt.Errorf("invalid peer exchange signature from %d", fromID)
+	}
+	return nil
+}
+
+// MergePeers merges peer records, whose keys were verified, into the local
+// peer set: the unknown peers as ephemeral ones, and the addresses of the
+// known peers from their own records only, the ones of signer
+func (c *Core) MergePeers(records []*peers.Peer, signer string) error {
+	added, updated, err := c.participants.Merge(&peers.PeerExchange{Peers: records}, signer)
+	if len(added) > 0 || len(updated) > 0 {
+		c.logger.WithFields(logrus.Fields{
+			"added":   len(added),
+			"updated": len(updated),
+		}).Debug("MergePeers()")
+	}
+	return err
+}
+
 // Heights returns map with heights for each participants
 func (c *Core) Heights() map[string]uint64 {
 	heights := make(map[string]uint64)
-	for pubKey := range c.participants.ByPubKey {
+	for _, peer := range c.participants.ToPeerSlice() {
+		pubKey := peer.PubKeyHex
 		participantEvents, err := c.poset.Store.ParticipantEvents(pubKey, -1)
 		if err == nil {
 			heights[pubKey] = uint64(len(participantEvents))
@@ -164,13 +252,16 @@ func (c *Core) Bootstrap() error {
 }
 
 func (c *Core) bootstrapInDegrees() {
-	for pubKey := range c.participants.ByPubKey {
+	participants := c.participants.ToPeerSlice()
+	for _, peer := range participants {
+		pubKey := peer.PubKeyHex
 		c.inDegrees[pubKey] = 0
 		eventHash, _, err := c.poset.Store.LastEventFrom(pubKey)
 		if err != nil {
 			continue
 		}
-		for otherPubKey := range c.participants.ByPubKey {
+		for _, other := range participants {
+			otherPubKey := other.PubKeyHex
 			if otherPubKey == pubKey {
 				continue
 			}
@@ -194,6 +285,11 @@ func (c *Core) bootstrapInDegrees() {
 // ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
 
 func (c *Core) SignAndInsertSelfEvent(event poset.Event) error {
+	// the creation time, signed with the event, of which the consensus
+	// time of the blocks is the median
+	if event.Message.Body.Timestamp == 0 {
+		event.Message.Body.Timestamp = c.now().UnixNano()
+	}
 	if err := c.poset.SetWireInfoAndSign(&event, c.key); err != nil {
 		return err
 	}
@@ -218,8 +314,13 @@ func (c *Core) InsertEvent(event poset.Event, setWireInfo bool) error {
 	if event.Creator() == c.HexID() {
 		c.head = event.Hex()
 		c.Seq = event.Index()
+		if c.onSelfEvent != nil && len(event.Transactions()) > 0 {
+			c.onSelfEvent(event)
+		}
 	}
 
+	c.dedupEvent(event)
+
 	c.inDegrees[event.Creator()] = 0
 
 	if otherEvent, err := c.poset.Store.GetEvent(event.OtherParent()); err == nil {
@@ -228,6 +329,26 @@ func (c *Core) InsertEvent(event poset.Event, setWireInfo bool) error {
 	return nil
 }
 
+// BeginBatch groups the writes to the store in a single transaction until
+// EndBatch
+func (c *Core) BeginBatch() {
+	c.poset.BeginBatch()
+	c.batching = true
+}
+
+// EndBatch commits the writes grouped since BeginBatch, then checkpoints the
+// journal if the events of the batch took items of the pools
+func (c *Core) EndBatch() error {
+	c.batching = false
+	if err := c.poset.EndBatch(); err != nil {
+		return err
+	}
+	if c.checkpointPending {
+		c.journalCheckpoint()
+	}
+	return nil
+}
+
 func (c *Core) KnownEvents() map[int64]int64 {
 	return c.poset.Store.KnownEvents()
 }
@@ -235,6 +356,9 @@ func (c *Core) KnownEvents() map[int64]int64 {
 // ++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
 
 func (c *Core) SignBlock(block poset.Block) (poset.BlockSignature, error) {
+	if c.observer {
+		return poset.BlockSignature{}, fmt.Errorf("observers do not sign blocks")
+	}
 	sig, err := block.Sign(c.key)
 	if err != nil {
 		return poset.BlockSignature{}, err
@@ -272,7 +396,7 @@ func (c *Core) EventDiff(known map[int64]int64) (events []poset.Event, err error
 	// compare this to our view of events and fill unknown with events that we know of
 	// and the other doesn't
 	for id, ct := range known {
-		peer := c.participants.ById[id]
+		peer, _ := c.participants.PeerByID(id)
 		if peer == nil {
 			// unknown peer detected.
 			// TODO: we should handle this nicely
@@ -304,6 +428,37 @@ func (c *Core) EventDiff(known map[int64]int64) (events []poset.Event, err error
 }
 
 func (c *Core) Sync(unknownEvents []poset.WireEvent) error {
+	otherHead, err := c.insertWire(unknownEvents)
+	if err != nil {
+		return err
+	}
+
+	if c.observer {
+		return nil
+	}
+
+	// create new event with self head and other head only if there are pending
+	// loaded events or the pools are not empty
+	if c.poset.GetPendingLoadedEvents() > 0 ||
+		len(c.transactionPool) > 0 ||
+		len(c.internalTransactionPool) > 0 ||
+		len(c.blockSignaturePool) > 0 {
+		return c.AddSelfEventBlock(otherHead)
+	}
+	return nil
+}
+
+// SyncChunk inserts the events of a chunk of a sync which is not the last
+// one: unlike Sync it does not create a self-event, the last chunk creating
+// one for the whole sync
+func (c *Core) SyncChunk(unknownEvents []poset.WireEvent) error {
+	_, err := c.insertWire(unknownEvents)
+	return err
+}
+
+// insertWire inserts the events unknown to c and returns the hash of the
+// last one, the other-parent of the next self-event
+func (c *Core) insertWire(unknownEvents []poset.WireEvent) (string, error) {
 
 	c.logger.WithFields(logrus.Fields{
 		"unknown_events":              len(unknownEvents),
@@ -323,7 +478,7 @@ func (c *Core) Sync(unknownEvents []poset.WireEvent) error {
 		ev, err := c.poset.ReadWireInfo(we)
 		if err != nil {
 			c.logger.WithField("EventBlock", we).Errorf("c.poset.ReadEventBlockInfo(we)")
-			return err
+			return "", err
 
 		}
 		if ev.Index() > myKnownEvents[ev.CreatorID()] {
@@ -331,7 +486,7 @@ func (c *Core) Sync(unknownEvents []poset.WireEvent) error {
 			ev.Message.Round = poset.RoundNIL
 			ev.Message.RoundReceived = poset.RoundNIL
 			if err := c.InsertEvent(*ev, false); err != nil {
-				return err
+				return "", err
 			}
 		}
 
@@ -340,19 +495,12 @@ func (c *Core) Sync(unknownEvents []poset.WireEvent) error {
 			otherHead = ev.Hex()
 		}
 	}
-
-	// create new event with self head and other head only if there are pending
-	// loaded events or the pools are not empty
-	if c.poset.PendingLoadedEvents > 0 ||
-		len(c.transactionPool) > 0 ||
-		len(c.internalTransactionPool) > 0 ||
-		len(c.blockSignaturePool) > 0 {
-		return c.AddSelfEventBlock(otherHead)
-	}
-	return nil
+	return otherHead, nil
 }
 
-func (c *Core) FastForward(peer string, block poset.Block, frame poset.Frame) error {
+// CheckAnchor checks that block is signed by enough participants and that
+// frame is the one it was built from
+func (c *Core) CheckAnchor(block poset.Block, frame poset.Frame) error {
 
 	// Check Block Signatures
 	err := c.poset.CheckBlock(block)
@@ -368,6 +516,15 @@ func (c *Core) FastForward(peer string, block poset.Block, frame poset.Frame) er
 	if !reflect.DeepEqual(block.GetFrameHash(), frameHash) {
 		return fmt.Errorf("invalid Frame Hash")
 	}
+	return nil
+}
+
+func (c *Core) FastForward(peer string, block poset.Block, frame poset.Frame) error {
+
+	err := c.CheckAnchor(block, frame)
+	if err != nil {
+		return err
+	}
 
 	err = c.poset.Reset(block, frame)
 	if err != nil {
@@ -387,14 +544,48 @@ func (c *Core) FastForward(peer string, block poset.Block, frame poset.Frame) er
 	return nil
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// maxSplitEvents is the number of self-events a sync may add to carry the
+// transactions a first self-event could not hold
+const maxSplitEvents = 8
+
+// SetClock makes the core timestamp its self-events with now instead of the
+// local time, e.g. with the virtual clock of a simulation
+func (c *Core) SetClock(now func() time.Time) {
+	c.now = now
+}
+
+// SetEventLimits bounds the number and the size of the transactions of a
+// self-event
+func (c *Core) SetEventLimits(maxTxs, maxBytes int) {
+	c.maxTransactionsInEvent = maxTxs
+	c.maxEventBytes = maxBytes
+}
+
+// PoolBytes returns the size of the transactions of the pool
+func (c *Core) PoolBytes() int64 {
+	return c.transactionPoolBytes
+}
+
+// eventTransactions returns the number of transactions at the head of the
+// pool which fit in a self-event, at least one, and their size
+func (c *Core) eventTransactions() (int, int64) {
+	var size int64
+	n := 0
+	for n < len(c.transactionPool) && n < c.maxTransactionsInEvent {
+		txSize := int64(len(c.transactionPool[n]))
+		if n > 0 && size+txSize > int64(c.maxEventBytes) {
+			break
+		}
+		size += txSize
+		n++
 	}
-	return b
+	return n, size
 }
 
 func (c *Core) AddSelfEventBlock(otherHead string) error {
+	if c.observer {
+		return fmt.Errorf("observers do not create events")
+	}
 
 	// Get flag tables from parents
 	parentEvent, errSelf := c.poset.Store.GetEvent(c.head)
@@ -430,7 +621,7 @@ func (c *Core) AddSelfEventBlock(otherHead string) error {
 	// create new event with self head and empty other parent
 	// empty transaction pool in its payload
 	var batch [][]byte
-	nTxs := min(len(c.transactionPool), c.maxTransactionsInEvent)
+	nTxs, size := c.eventTransactions()
 	batch = c.transactionPool[0:nTxs:nTxs]
 	newHead := poset.NewEvent(batch,
 		c.internalTransactionPool,
@@ -446,17 +637,63 @@ func (c *Core) AddSelfEventBlock(otherHead string) error {
 		"block_signatures":      len(c.blockSignaturePool),
 	}).Debug("newHead := poset.NewEventBlock")
 
+	taken := nTxs > 0 || len(c.internalTransactionPool) > 0 || len(c.blockSignaturePool) > 0
 	c.transactionPool = c.transactionPool[nTxs:] //[][]byte{}
+	c.transactionPoolBytes -= size
 	c.internalTransactionPool = []poset.InternalTransaction{}
 	// retain c.blockSignaturePool until c.transactionPool is empty
 	// FIXIT: is there any better strategy?
 	if len(c.transactionPool) == 0 {
 		c.blockSignaturePool = []poset.BlockSignature{}
 	}
+	if taken {
+		c.journalCheckpoint()
+	}
+
+	// the transactions left by an oversized pool are split across further
+	// self-events
+	for i := 0; i < maxSplitEvents && len(c.transactionPool) > 0; i++ {
+		if err := c.addSplitEvent(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// addSplitEvent adds a self-event, without other-parent, carrying the
+// transactions at the head of the pool
+func (c *Core) addSplitEvent() error {
+	parentEvent, err := c.poset.Store.GetEvent(c.head)
+	if err != nil {
+		return fmt.Errorf("failed to get parent: %s", err)
+	}
+	flagTable, err := parentEvent.GetFlagTable()
+	if err != nil {
+		return fmt.Errorf("failed to get self flag table: %s", err)
+	}
+
+	nTxs, size := c.eventTransactions()
+	newHead := poset.NewEvent(c.transactionPool[0:nTxs:nTxs], nil,
+		nil, []string{c.head, ""}, c.PubKey(), c.Seq+1, flagTable)
+	if err := c.SignAndInsertSelfEvent(newHead); err != nil {
+		return fmt.Errorf("newHead := poset.NewEventBlock: %s", err)
+	}
+	c.logger.WithFields(logrus.Fields{
+		"transactions": nTxs,
+		"bytes":        size,
+	}).Debug("split transaction pool")
+
+	c.transactionPool = c.transactionPool[nTxs:]
+	c.transactionPoolBytes -= size
+	if len(c.transactionPool) == 0 {
+		c.blockSignaturePool = []poset.BlockSignature{}
+	}
+	metrics.IncrCounter("node.events.split", 1)
+	c.journalCheckpoint()
+	return nil
+}
+
 func (c *Core) FromWire(wireEvents []poset.WireEvent) ([]poset.Event, error) {
 	events := make([]poset.Event, len(wireEvents), len(wireEvents))
 	for i, w := range wireEvents {
@@ -528,14 +765,57 @@ func (c *Core) RunConsensus() error {
 }
 
 func (c *Core) AddTransactions(txs [][]byte) {
-	c.transactionPool = append(c.transactionPool, txs...)
+	if c.journal != nil {
+		records := make([]journalRecord, len(txs))
+		for i, tx := range txs {
+			records[i].Tx = tx
+		}
+		c.journalAppend(records...)
+	}
+	for _, tx := range txs {
+		c.poolTransaction(tx)
+		c.transactionPoolBytes += int64(len(tx))
+		if c.dedup != nil {
+			c.dedup.add(crypto.SHA256(tx))
+		}
+	}
+}
+
+// RemoveTransactions removes the transactions of the pool for which drop
+// returns true, and returns them
+func (c *Core) RemoveTransactions(drop func(tx []byte) bool) [][]byte {
+	var kept, removed [][]byte
+	for _, tx := range c.transactionPool {
+		if drop(tx) {
+			removed = append(removed, tx)
+			c.transactionPoolBytes -= int64(len(tx))
+			if c.dedup != nil {
+				c.dedup.remove(crypto.SHA256(tx))
+			}
+		} else {
+			kept = append(kept, tx)
+		}
+	}
+	if len(removed) > 0 {
+		c.transactionPool = kept
+		c.journalCheckpoint()
+	}
+	return removed
 }
 
 func (c *Core) AddInternalTransactions(txs []poset.InternalTransaction) {
+	if c.journal != nil {
+		records := make([]journalRecord, len(txs))
+		for i := range txs {
+			records[i].Internal = &txs[i]
+		}
+		c.journalAppend(records...)
+	}
 	c.internalTransactionPool = append(c.internalTransactionPool, txs...)
 }
 
 func (c *Core) AddBlockSignature(bs poset.BlockSignature) {
+	c.journalAppend(journalRecord{Signature: &bs})
 	c.blockSignaturePool = append(c.blockSignaturePool, bs)
 }
 
@@ -566,11 +846,19 @@ func (c *Core) GetConsensusEventsCount() int64 {
 }
 
 func (c *Core) GetUndeterminedEvents() []string {
-	return c.poset.UndeterminedEvents
+	return c.poset.GetUndeterminedEvents()
+}
+
+// NeedGossip returns true when the node has something to gossip about:
+// transactions or block signatures in its pools, or pending loaded events
+func (c *Core) NeedGossip() bool {
+	return c.poset.GetPendingLoadedEvents() > 0 ||
+		len(c.transactionPool) > 0 ||
+		len(c.blockSignaturePool) > 0
 }
 
 func (c *Core) GetPendingLoadedEvents() int64 {
-	return c.poset.PendingLoadedEvents
+	return c.poset.GetPendingLoadedEvents()
 }
 
 func (c *Core) GetConsensusTransactions() ([][]byte, error) {
@@ -586,15 +874,15 @@ func (c *Core) GetConsensusTransactions() ([][]byte, error) {
 }
 
 func (c *Core) GetLastConsensusRoundIndex() *int64 {
-	return c.poset.LastConsensusRound
+	return c.poset.GetLastConsensusRound()
 }
 
 func (c *Core) GetConsensusTransactionsCount() uint64 {
-	return c.poset.ConsensusTransactions
+	return c.poset.GetConsensusTransactions()
 }
 
 func (c *Core) GetLastCommittedRoundEventsCount() int {
-	return c.poset.LastCommitedRoundEvents
+	return c.poset.GetLastCommitedRoundEvents()
 }
 
 func (c *Core) GetLastBlockIndex() int64 {
@@ -1039,3 +1039,62 @@ func getName(index map[string]string, hash string) string {
 	}
 	return fmt.Sprintf("%s not found", hash)
 }
+
+func TestEventSplit(t *testing.T) {
+	cores, _, _ := initCores(1, t)
+	core := cores[0]
+
+	// by count: 20 transactions, 3 per event
+	core.SetEventLimits(3, 1000)
+	var txs [][]byte
+	for i := 0; i < 20; i++ {
+		txs = append(txs, []byte(fmt.Sprintf("tx_%06d", i)))
+	}
+	core.AddTransactions(txs)
+	if core.PoolBytes() != 180 {
+		t.Fatalf("the pool should hold 180 bytes, not %d", core.PoolBytes())
+	}
+	seq := core.Seq
+	if err := core.AddSelfEventBlock(""); err != nil {
+		t.Fatal(err)
+	}
+	if n := core.Seq - seq; n != 7 {
+		t.Fatalf("20 transactions should be split across 7 events, not %d", n)
+	}
+	if len(core.transactionPool) != 0 || core.PoolBytes() != 0 {
+		t.Fatalf("the pool should be empty, %d transactions of %d bytes left",
+			len(core.transactionPool), core.PoolBytes())
+	}
+	var carried [][]byte
+	for i := seq + 1; i <= core.Seq; i++ {
+		hash, err := core.poset.Store.ParticipantEvent(core.HexID(), i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		evTxs, err := core.GetEventTransactions(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(evTxs) > 3 {
+			t.Fatalf("event %d should hold at most 3 transactions, not %d", i, len(evTxs))
+		}
+		carried = append(carried, evTxs...)
+	}
+	if !reflect.DeepEqual(carried, txs) {
+		t.Fatal("the events should carry the transactions in order")
+	}
+
+	// by size: 25 bytes per event, a larger transaction goes alone
+	core.SetEventLimits(100, 25)
+	core.AddTransactions([][]byte{
+		[]byte("0123456789"), []byte("0123456789"), []byte("0123456789"),
+		[]byte("a transaction of more than 25 bytes"), []byte("0123456789"),
+	})
+	seq = core.Seq
+	if err := core.AddSelfEventBlock(""); err != nil {
+		t.Fatal(err)
+	}
+	if n := core.Seq - seq; n != 4 {
+		t.Fatalf("the transactions should be split across 4 events, not %d", n)
+	}
+}
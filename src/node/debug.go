@@ -70,13 +70,14 @@ func (g *Graph) GetParticipantEventsLite() map[string]map[string]EventLite {
 
 		hash := event.Hex()
 
+		creator, _ := peers.PeerByPubKey(event.Creator())
 		lite_event := EventLite{
 			CreatorID: event.CreatorID(),
 			OtherParentCreatorID: event.OtherParentCreatorID(),
 			Message: EventMessageLite {
 				Body: EventBodyLite{
 					Parents: event.Message.Body.Parents,
-					Creator: peers.ByPubKey[event.Creator()].NetAddr,
+					Creator: creator.NetAddr,
 					Index: event.Message.Body.Index,
 					Transactions: event.Message.Body.Transactions,
 				},
@@ -0,0 +1,143 @@
+package node
+
+import (
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// A transaction submitted to several nodes would be committed once for each
+// of them. With a dedup window, a node remembers the hashes of the last
+// transactions it pooled or saw in events, and drops the ones submitted
+// again within the window. A transaction of its pool found in the event of
+// another node is removed from the pool, that event committing it. The
+// window is persisted in the store, so that it survives restarts.
+
+// txWindow is a sliding window of transaction hashes, oldest first
+type txWindow struct {
+	size   int
+	hashes [][]byte
+	seen   map[string]bool
+	dirty  bool
+}
+
+// newTxWindow returns a window of size hashes holding the last ones of
+// hashes
+func newTxWindow(size int, hashes [][]byte) *txWindow {
+	w := &txWindow{
+		size: size,
+		seen: make(map[string]bool),
+	}
+	if len(hashes) > size {
+		hashes = hashes[len(hashes)-size:]
+	}
+	for _, h := range hashes {
+		w.add(h)
+	}
+	w.dirty = false
+	return w
+}
+
+func (w *txWindow) contains(hash []byte) bool {
+	return w.seen[string(hash)]
+}
+
+// add slides the window over hash, returning false when it was already in
+// the window
+func (w *txWindow) add(hash []byte) bool {
+	if w.seen[string(hash)] {
+		return false
+	}
+	w.hashes = append(w.hashes, hash)
+	w.seen[string(hash)] = true
+	if len(w.hashes) > w.size {
+		delete(w.seen, string(w.hashes[0]))
+		w.hashes = w.hashes[1:]
+	}
+	w.dirty = true
+	return true
+}
+
+// remove forgets hash, for a transaction which left the pool without being
+// put into an event
+func (w *txWindow) remove(hash []byte) {
+	if !w.seen[string(hash)] {
+		return
+	}
+	delete(w.seen, string(hash))
+	for i, h := range w.hashes {
+		if string(h) == string(hash) {
+			w.hashes = append(w.hashes[:i:i], w.hashes[i+1:]...)
+			break
+		}
+	}
+	w.dirty = true
+}
+
+// SetTxWindow makes the core drop the transactions submitted again within
+// the last size transactions it saw, restoring the window persisted in the
+// store
+func (c *Core) SetTxWindow(size int) error {
+	hashes, err := c.poset.Store.TxWindow()
+	if err != nil {
+		return err
+	}
+	c.dedup = newTxWindow(size, hashes)
+	return nil
+}
+
+// DuplicateTransaction returns true when tx was pooled or put into an event
+// within the dedup window, false without window
+func (c *Core) DuplicateTransaction(tx []byte) bool {
+	return c.dedup != nil && c.dedup.contains(crypto.SHA256(tx))
+}
+
+// SaveTxWindow persists the dedup window when it changed
+func (c *Core) SaveTxWindow() error {
+	if c.dedup == nil || !c.dedup.dirty {
+		return nil
+	}
+	if err := c.poset.Store.SetTxWindow(c.dedup.hashes); err != nil {
+		return err
+	}
+	c.dedup.dirty = false
+	return nil
+}
+
+// dedupEvent slides the window over the transactions of an inserted event.
+// Those of another creator which were already in the window are removed
+// from the pool.
+func (c *Core) dedupEvent(event poset.Event) {
+	if c.dedup == nil {
+		return
+	}
+	var pooled map[string]bool
+	for _, tx := range event.Transactions() {
+		hash := crypto.SHA256(tx)
+		if !c.dedup.add(hash) && event.Creator() != c.HexID() {
+			if pooled == nil {
+				pooled = make(map[string]bool)
+			}
+			pooled[string(hash)] = true
+		}
+	}
+	if len(pooled) == 0 {
+		return
+	}
+
+	kept := c.transactionPool[:0:0]
+	removed := 0
+	for _, tx := range c.transactionPool {
+		if pooled[string(crypto.SHA256(tx))] {
+			c.transactionPoolBytes -= int64(len(tx))
+			removed++
+			continue
+		}
+		kept = append(kept, tx)
+	}
+	if removed > 0 {
+		c.transactionPool = kept
+		c.journalCheckpoint()
+		metrics.IncrCounter("node.transactions.duplicate", int64(removed))
+	}
+}
@@ -0,0 +1,75 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func TestTxWindow(t *testing.T) {
+	hash := func(i int) []byte {
+		return crypto.SHA256([]byte(fmt.Sprintf("tx%d", i)))
+	}
+	w := newTxWindow(3, nil)
+	for i := 0; i < 4; i++ {
+		if !w.add(hash(i)) {
+			t.Fatalf("tx%d should not be in the window", i)
+		}
+	}
+	if w.add(hash(3)) {
+		t.Fatal("tx3 should be in the window")
+	}
+	if w.contains(hash(0)) {
+		t.Fatal("tx0 should have slid out of the window")
+	}
+	w.remove(hash(2))
+	if w.contains(hash(2)) || len(w.hashes) != 2 {
+		t.Fatalf("tx2 should have been removed, got %d hashes", len(w.hashes))
+	}
+
+	restored := newTxWindow(1, w.hashes)
+	if !restored.contains(hash(3)) || restored.contains(hash(1)) {
+		t.Fatal("a smaller window should keep the last hashes")
+	}
+}
+
+func TestDedup(t *testing.T) {
+	cores, _, _ := initCores(2, t)
+	for _, c := range cores {
+		if err := c.SetTxWindow(10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tx := []byte("submitted twice")
+
+	// core 0 puts the transaction into an event while it is still in the
+	// pool of core 1
+	cores[1].AddTransactions([][]byte{tx})
+	if err := synchronizeCores(cores, 1, 0, [][]byte{tx}); err != nil {
+		t.Fatal(err)
+	}
+	if !cores[1].DuplicateTransaction(tx) {
+		t.Fatal("a pooled transaction should be a duplicate")
+	}
+	if err := synchronizeCores(cores, 0, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if l := len(cores[1].transactionPool); l != 0 {
+		t.Fatalf("the transaction in the event of core 0 should leave the pool of core 1, got %d", l)
+	}
+	if cores[1].transactionPoolBytes != 0 {
+		t.Fatalf("expected an empty pool, got %d bytes", cores[1].transactionPoolBytes)
+	}
+
+	// the window survives in the store
+	if err := cores[1].SaveTxWindow(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cores[1].SetTxWindow(10); err != nil {
+		t.Fatal(err)
+	}
+	if !cores[1].DuplicateTransaction(tx) {
+		t.Fatal("the restored window should hold the transaction")
+	}
+}
@@ -0,0 +1,173 @@
+package node
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// commitRetryDelay is the pause before the first retry of a block the
+// application failed to commit, doubled at every retry
+const commitRetryDelay = 100 * time.Millisecond
+
+// The blocks are delivered to the application at least once and in order:
+// the index of the last block the application acknowledged, by committing it
+// without error, is kept in the store, and every delivery first delivers the
+// blocks after it. A block which could not be delivered is retried with the
+// next one, and the blocks of a persistent store which were not acknowledged
+// before a restart are delivered when the node starts.
+
+// initDelivery loads the last acknowledged block, moved back to
+// Config.RedeliverFrom when the application asked for a redelivery
+func (n *Node) initDelivery() error {
+	acked, err := n.core.poset.Store.AckedBlock()
+	if err != nil {
+		return fmt.Errorf("reading the acknowledged block: %s", err)
+	}
+	atomic.StoreInt64(&n.ackedBlock, acked)
+	if n.conf.RedeliverFrom >= 0 && n.conf.RedeliverFrom <= acked {
+		n.logger.WithField("from", n.conf.RedeliverFrom).Info("Redelivering blocks")
+		return n.storeAck(n.conf.RedeliverFrom - 1)
+	}
+	return nil
+}
+
+// Redeliver delivers again the blocks from index from to the application
+func (n *Node) Redeliver(from int64) {
+	select {
+	case n.redeliverCh <- from:
+	case <-n.shutdownCh:
+	}
+}
+
+// redeliver moves the acknowledged block back before from and delivers the
+// blocks from there
+func (n *Node) redeliver(from int64) {
+	if from <= n.AckedBlock() {
+		n.coreLock.Lock()
+		err := n.storeAck(from - 1)
+		n.coreLock.Unlock()
+		if err != nil {
+			n.logger.WithError(err).Error("Moving the acknowledged block back")
+			return
+		}
+	}
+	if err := n.deliverPending(n.core.GetLastBlockIndex()); err != nil {
+		n.logger.WithError(err).Warn("Redelivering blocks")
+	}
+}
+
+// AckedBlock returns the index of the last block acknowledged by the
+// application, -1 when none was
+func (n *Node) AckedBlock() int64 {
+	return atomic.LoadInt64(&n.ackedBlock)
+}
+
+// deliver commits block to the application after the blocks it did not
+// acknowledge yet, and returns the state hash of the application after it.
+// A block already acknowledged, replayed by a bootstrap, is not delivered
+// again: its stored state hash is returned.
+func (n *Node) deliver(block poset.Block) ([]byte, error) {
+	if block.Index() <= n.AckedBlock() {
+		metrics.IncrCounter("node.blocks.redundant", 1)
+		n.coreLock.Lock()
+		defer n.coreLock.Unlock()
+		stored, err := n.core.poset.Store.GetBlock(block.Index())
+		if err != nil {
+			return nil, nil
+		}
+		return stored.StateHash, nil
+	}
+	if err := n.deliverPending(block.Index() - 1); err != nil {
+		return nil, err
+	}
+	return n.deliverBlock(block)
+}
+
+// deliverPending delivers the stored blocks from the one after the last
+// acknowledged block to block to. The blocks signed before the application
+// committed them get the state hash it reports.
+func (n *Node) deliverPending(to int64) error {
+	for index := n.AckedBlock() + 1; index <= to; index++ {
+		n.coreLock.Lock()
+		block, err := n.core.poset.Store.GetBlock(index)
+		n.coreLock.Unlock()
+		if err != nil {
+			return fmt.Errorf("reading block %d to deliver: %s", index, err)
+		}
+		metrics.IncrCounter("node.blocks.redelivered", 1)
+		stateHash, err := n.deliverBlock(block)
+		if err != nil {
+			return err
+		}
+		if err := n.recordStateHash(index, stateHash); err != nil {
+			return fmt.Errorf("recording the state hash of block %d: %s", index, err)
+		}
+	}
+	return nil
+}
+
+// recordStateHash sets the state hash of the stored block index when it has
+// none
+func (n *Node) recordStateHash(index int64, stateHash []byte) error {
+	if len(stateHash) == 0 {
+		return nil
+	}
+	n.coreLock.Lock()
+	defer n.coreLock.Unlock()
+	block, err := n.core.poset.Store.GetBlock(index)
+	if err != nil || len(block.StateHash) > 0 {
+		return err
+	}
+	block.StateHash = stateHash
+	return n.core.poset.Store.SetBlock(block)
+}
+
+// deliverBlock commits block to the application, retrying Config.CommitRetries
+// times, records its acknowledgement and returns the state hash the
+// application reported
+func (n *Node) deliverBlock(block poset.Block) ([]byte, error) {
+	delay := commitRetryDelay
+	var stateHash []byte
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		hash, err := n.proxy.CommitBlock(block)
+		metrics.MeasureSince("node.commit", start)
+		if err == nil {
+			stateHash = hash
+			break
+		}
+		metrics.IncrCounter("node.commit.errors", 1)
+		if attempt >= n.conf.CommitRetries {
+			return nil, fmt.Errorf("committing block %d: %s", block.Index(), err)
+		}
+		n.logger.WithFields(logrus.Fields{
+			"block":   block.Index(),
+			"attempt": attempt + 1,
+			"error":   err,
+		}).Debug("Retrying block commit")
+		select {
+		case <-time.After(delay):
+		case <-n.shutdownCh:
+			return nil, fmt.Errorf("committing block %d: shutdown", block.Index())
+		}
+		delay *= 2
+	}
+
+	n.coreLock.Lock()
+	defer n.coreLock.Unlock()
+	return stateHash, n.storeAck(block.Index())
+}
+
+// storeAck records index as the last acknowledged block. The caller holds
+// the coreLock.
+func (n *Node) storeAck(index int64) error {
+	atomic.StoreInt64(&n.ackedBlock, index)
+	metrics.SetGauge("node.blocks.acked", float64(index))
+	return n.core.poset.Store.SetAckedBlock(index)
+}
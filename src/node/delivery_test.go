@@ -0,0 +1,88 @@
+package node
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy"
+)
+
+// flakyProxy fails the first commits of every block
+type flakyProxy struct {
+	proxy.AppProxy
+	failures int
+
+	lock     sync.Mutex
+	attempts map[int64]int
+	// delivered are the indexes of the blocks committed without error
+	delivered []int64
+}
+
+func (p *flakyProxy) CommitBlock(block poset.Block) ([]byte, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.attempts[block.Index()]++
+	if p.attempts[block.Index()] <= p.failures {
+		return nil, fmt.Errorf("block %d refused", block.Index())
+	}
+	p.delivered = append(p.delivered, block.Index())
+	return p.AppProxy.CommitBlock(block)
+}
+
+func (p *flakyProxy) deliveries() []int64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]int64(nil), p.delivered...)
+}
+
+func TestBlockDelivery(t *testing.T) {
+	logger := common.NewTestLogger(t)
+
+	keys, ps := initPeers(4)
+	nodes := initNodes(keys, ps, 1000, 1000, "inmem", logger, t)
+	flaky := &flakyProxy{AppProxy: nodes[0].proxy, failures: 2, attempts: make(map[int64]int)}
+	nodes[0].proxy = flaky
+	// every block fails once more than it is retried
+	nodes[0].conf.CommitRetries = 1
+
+	if err := gossip(nodes, 10, false, 6*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	defer shutdownNodes(nodes)
+
+	// the blocks are delivered in order and without gap, each one once
+	delivered := flaky.deliveries()
+	for i, index := range delivered {
+		if index != int64(i) {
+			t.Fatalf("block %d should have been delivered in position %d, got %v", index, i, delivered)
+		}
+	}
+	acked := nodes[0].AckedBlock()
+	if acked != int64(len(delivered))-1 {
+		t.Fatalf("the acknowledged block should be %d, not %d", len(delivered)-1, acked)
+	}
+	if stored, _ := nodes[0].core.poset.Store.AckedBlock(); stored != acked {
+		t.Fatalf("the store should hold acknowledged block %d, not %d", acked, stored)
+	}
+
+	// the application asks for the blocks again
+	nodes[0].Redeliver(0)
+	timeout := time.After(3 * time.Second)
+	for {
+		again := flaky.deliveries()[len(delivered):]
+		for _, index := range again {
+			if index == 0 {
+				return
+			}
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("block 0 should be delivered again, got %v", again)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package node
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+// DefaultDiscoveryInterval is the time between two rounds of probes of the
+// discovered peer addresses
+const DefaultDiscoveryInterval = 10 * time.Second
+
+// discoveryBatch is the maximum number of candidates probed per round
+const discoveryBatch = 8
+
+// maxCandidates bounds the announced peer records awaiting a probe
+const maxCandidates = 256
+
+// candidates are the peer records announced by other nodes, not yet
+// verified. They are probed by runDiscovery before joining the peer set.
+type candidates struct {
+	sync.Mutex
+	// byKey are the records by public key, the last announcement winning
+	byKey map[string]*peers.Peer
+}
+
+// runDiscovery periodically probes the seeds and the peer records learned
+// through sync, adding the ones answering with the announced key to the peer
+// set
+func (n *Node) runDiscovery() {
+	if n.conf.DiscoveryInterval <= 0 {
+		return
+	}
+	n.probeSeeds()
+	ticker := time.NewTicker(n.conf.DiscoveryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			n.probeSeeds()
+			n.discover()
+		case <-n.shutdownCh:
+			return
+		}
+	}
+}
+
+// probeSeeds asks the seeds no known peer listens on for their key and
+// peer records. A seed is trusted to be the owner of the key it signs with,
+// not to be a validator: it joins the peer set as an ephemeral peer.
+func (n *Node) probeSeeds() {
+	known := make(map[string]bool)
+	for _, p := range n.core.participants.Snapshot().ToPeerSlice() {
+		known[p.NetAddr] = true
+	}
+	for _, seed := range n.conf.Seeds {
+		if seed == n.localAddr || known[seed] {
+			continue
+		}
+		resp, err := n.probePeer(seed, "")
+		if err != nil {
+			n.logger.WithFields(logrus.Fields{
+				"seed":  seed,
+				"error": err,
+			}).Debug("Probing seed")
+			continue
+		}
+		n.admitPeer(peers.NewPeer(resp.PubKeyHex, seed), resp)
+	}
+}
+
+// discover probes a batch of candidates
+func (n *Node) discover() {
+	n.candidates.Lock()
+	batch := make([]*peers.Peer, 0, discoveryBatch)
+	for key, record := range n.candidates.byKey {
+		if len(batch) == discoveryBatch {
+			break
+		}
+		batch = append(batch, record)
+		delete(n.candidates.byKey, key)
+	}
+	n.candidates.Unlock()
+
+	for _, record := range batch {
+		resp, err := n.probePeer(record.NetAddr, record.PubKeyHex)
+		if err != nil {
+			metrics.IncrCounter("node.discovery.rejected", 1)
+			n.logger.WithFields(logrus.Fields{
+				"peer":  record.NetAddr,
+				"error": err,
+			}).Debug("Rejected announced peer")
+			continue
+		}
+		n.admitPeer(record, resp)
+	}
+}
+
+// probePeer asks addr to sign its peer records along with a fresh nonce,
+// checking that the responder owns pubKey when it is not empty
+func (n *Node) probePeer(addr, pubKey string) (*net.PeersResponse, error) {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	metrics.IncrCounter("node.discovery.probes", 1)
+
+	args := net.PeersRequest{
+		FromID: n.id,
+		Nonce:  nonce,
+	}
+	var out net.PeersResponse
+	if err := n.trans.Peers(addr, &args, &out); err != nil {
+		return nil, err
+	}
+	if out.Peers == nil {
+		return nil, fmt.Errorf("no peer records")
+	}
+	if pubKey != "" && out.PubKeyHex != pubKey {
+		return nil, fmt.Errorf("announced key %s, answered by %s", pubKey, out.PubKeyHex)
+	}
+	if !bytes.Equal(out.Peers.Nonce, nonce) {
+		return nil, fmt.Errorf("peer records signed for another nonce")
+	}
+	valid, err := out.Peers.Verify(out.PubKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid peer records signature")
+	}
+	return &out, nil
+}
+
+// admitPeer merges a verified peer record, unless the peer set is full,
+// then queues the records it announced. A new peer is admitted as ephemeral,
+// see Core.MergePeers.
+func (n *Node) admitPeer(record *peers.Peer, resp *net.PeersResponse) {
+	if n.acceptsPeer(record, resp.PubKeyHex) {
+		// the membership hooks update the store, which the core reads
+		n.coreLock.Lock()
+		err := n.core.MergePeers([]*peers.Peer{record}, resp.PubKeyHex)
+		n.coreLock.Unlock()
+		if err != nil {
+			n.logger.WithFields(logrus.Fields{
+				"peer":  record.NetAddr,
+				"error": err,
+			}).Warn("Adding discovered peer")
+			return
+		}
+		metrics.IncrCounter("node.discovery.admitted", 1)
+	}
+	n.offerPeers(resp.Peers.Peers, resp.PubKeyHex)
+}
+
+// acceptsPeer tells whether a peer record signed by the owner of signer
+// would change the peer set: a new key while below MaxPeers, or a new
+// address of a known one in its own record
+func (n *Node) acceptsPeer(record *peers.Peer, signer string) bool {
+	if len(record.PubKeyHex) < 3 || record.PubKeyHex == n.core.HexID() {
+		return false
+	}
+	n.core.participants.RLock()
+	known, ok := n.core.participants.ByPubKey[record.PubKeyHex]
+	size := len(n.core.participants.ByPubKey)
+	n.core.participants.RUnlock()
+	if ok {
+		return record.PubKeyHex == signer && known.NetAddr != record.NetAddr
+	}
+	return n.conf.MaxPeers <= 0 || size < n.conf.MaxPeers
+}
+
+// offerPeers queues the records announced by the owner of signer which
+// would change the peer set, to be probed by runDiscovery. The tiers of the
+// records are dropped.
+func (n *Node) offerPeers(records []*peers.Peer, signer string) {
+	if n.conf.DiscoveryInterval <= 0 {
+		return
+	}
+	n.queueCandidates(records, signer)
+}
+
+// queueCandidates queues the records of offerPeers, whether or not
+// runDiscovery probes them
+func (n *Node) queueCandidates(records []*peers.Peer, signer string) {
+	n.candidates.Lock()
+	defer n.candidates.Unlock()
+	for _, record := range records {
+		if !n.acceptsPeer(record, signer) {
+			continue
+		}
+		if _, ok := n.candidates.byKey[record.PubKeyHex]; !ok && len(n.candidates.byKey) >= maxCandidates {
+			continue
+		}
+		n.candidates.byKey[record.PubKeyHex] = &peers.Peer{
+			NetAddr:   record.NetAddr,
+			PubKeyHex: record.PubKeyHex,
+		}
+	}
+}
+
+// advertise sets the address of the record of the node to its advertised
+// address, which its peer exchanges then announce
+func (n *Node) advertise() error {
+	if n.conf.AdvertiseAddr == "" {
+		return nil
+	}
+	for _, set := range []*peers.Peers{n.core.participants, n.peerSelector.Peers()} {
+		if _, err := set.UpdatePeerAddr(n.core.HexID(), n.conf.AdvertiseAddr); err != nil {
+			return fmt.Errorf("advertising %s: %s", n.conf.AdvertiseAddr, err)
+		}
+	}
+	return nil
+}
+
+// OfferAdvertisedAddr queues the address a peer advertised in a handshake,
+// where it proved its key, to be probed like the announced records before
+// it replaces the known one
+func (n *Node) OfferAdvertisedAddr(pubKey, addr string) {
+	n.core.participants.RLock()
+	known, ok := n.core.participants.ByPubKey[pubKey]
+	ok = ok && known.NetAddr != addr
+	n.core.participants.RUnlock()
+	if !ok {
+		return
+	}
+	metrics.IncrCounter("node.discovery.advertised", 1)
+	n.offerPeers([]*peers.Peer{{NetAddr: addr, PubKeyHex: pubKey}}, pubKey)
+}
+
+func (n *Node) processPeersRequest(rpc net.RPC, cmd *net.PeersRequest) {
+	n.logger.WithField("from_id", cmd.FromID).Debug("processPeersRequest(rpc net.RPC, cmd *net.PeersRequest)")
+
+	pe, err := n.core.PeerExchangeProof(cmd.Nonce)
+	if err != nil {
+		rpc.Respond(nil, err)
+		return
+	}
+	rpc.Respond(&net.PeersResponse{
+		FromID:    n.id,
+		PubKeyHex: n.core.HexID(),
+		Peers:     pe,
+	}, nil)
+}
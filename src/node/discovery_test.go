@@ -0,0 +1,109 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/dummy"
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/utils"
+)
+
+func TestDiscovery(t *testing.T) {
+	logger := common.NewTestLogger(t)
+	keys, ps := initPeers(3)
+	var all []*peers.Peer
+	for _, key := range keys {
+		all = append(all, ps.ByPubKey[fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey))])
+	}
+
+	var transports []net.Transport
+	for _, p := range all {
+		trans, err := net.NewTCPTransport(utils.GetUnusedNetAddr(t), nil, 2, time.Second, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer trans.Close()
+		trans.Listen()
+		p.NetAddr = trans.LocalAddr()
+		transports = append(transports, trans)
+	}
+
+	// node 0 and node 2 only know node 1, which knows everybody
+	known := [][]int{{0, 1}, {0, 1, 2}, {1, 2}}
+	var nodes []*Node
+	for i, key := range keys {
+		var list []*peers.Peer
+		for _, j := range known[i] {
+			list = append(list, peers.NewPeer(all[j].PubKeyHex, all[j].NetAddr))
+		}
+		participants := peers.NewPeersFromSlice(list)
+
+		conf := NewConfig(5*time.Millisecond, time.Second, 1000, 1000, logger)
+		// the test queues the candidates and runs the discovery itself
+		conf.DiscoveryInterval = 0
+		id := participants.ByPubKey[all[i].PubKeyHex].ID
+		node := NewNode(conf, id, key, participants,
+			poset.NewInmemStore(participants, conf.CacheSize),
+			transports[i], dummy.NewInmemDummyApp(logger))
+		if err := node.Init(); err != nil {
+			t.Fatal(err)
+		}
+		node.RunAsync(false)
+		defer node.Shutdown()
+		nodes = append(nodes, node)
+	}
+
+	// an announcement of the key of node 2 at the address of node 1 fails
+	// the probe
+	nodes[0].queueCandidates([]*peers.Peer{{PubKeyHex: all[2].PubKeyHex, NetAddr: all[1].NetAddr}}, all[1].PubKeyHex)
+	nodes[0].discover()
+	if _, ok := nodes[0].core.participants.PeerByPubKey(all[2].PubKeyHex); ok {
+		t.Fatal("a spoofed peer record should not be admitted")
+	}
+
+	// the records of node 1 announce node 2, which is probed and admitted
+	pe, err := nodes[1].core.PeerExchange()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes[0].queueCandidates(pe.Peers, all[1].PubKeyHex)
+	nodes[0].discover()
+	peer, ok := nodes[0].core.participants.PeerByPubKey(all[2].PubKeyHex)
+	if !ok || peer.NetAddr != all[2].NetAddr {
+		t.Fatalf("node 2 should have been discovered, got %+v", peer)
+	}
+	if !peer.IsEphemeral() {
+		t.Fatalf("a discovered peer should not be a validator, got %+v", peer)
+	}
+
+	// only node 2 can move itself
+	moved := []*peers.Peer{{PubKeyHex: all[2].PubKeyHex, NetAddr: all[1].NetAddr}}
+	nodes[0].queueCandidates(moved, all[1].PubKeyHex)
+	nodes[0].candidates.Lock()
+	_, queued := nodes[0].candidates.byKey[all[2].PubKeyHex]
+	nodes[0].candidates.Unlock()
+	if queued {
+		t.Fatal("an address relayed by another peer should not be probed")
+	}
+
+	// a full peer set does not take seeds
+	nodes[2].conf.Seeds = []string{all[0].NetAddr}
+	nodes[2].conf.MaxPeers = 2
+	nodes[2].probeSeeds()
+	if _, ok := nodes[2].core.participants.PeerByPubKey(all[0].PubKeyHex); ok {
+		t.Fatal("max_peers should bound the discovered peers")
+	}
+
+	nodes[2].conf.MaxPeers = 0
+	nodes[2].probeSeeds()
+	peer, ok = nodes[2].core.participants.PeerByPubKey(all[0].PubKeyHex)
+	if !ok || peer.NetAddr != all[0].NetAddr {
+		t.Fatalf("seed should have been added, got %+v", peer)
+	}
+}
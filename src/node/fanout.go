@@ -0,0 +1,180 @@
+package node
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// gossipTimes records when the node last gossiped with each peer, to rate
+// limit the gossips of a fan-out
+type gossipTimes struct {
+	sync.Mutex
+	last map[string]time.Time
+}
+
+// fanoutTargets selects the peers of the next gossip, dropping the banned
+// ones and the ones gossiped with less than GossipPeerInterval ago
+func (n *Node) fanoutTargets() []*peers.Peer {
+	interval := n.conf.GossipPeerInterval
+	if interval == 0 {
+		interval = n.heartbeatTimeout()
+	}
+
+	n.selectorLock.Lock()
+	selected := n.peerSelector.NextN(n.conf.GossipFanout)
+	n.selectorLock.Unlock()
+
+	now := time.Now()
+	n.gossipTimes.Lock()
+	defer n.gossipTimes.Unlock()
+	targets := make([]*peers.Peer, 0, len(selected))
+	for _, p := range selected {
+		if n.bans.IsPeerBanned(p) {
+			continue
+		}
+		if last, ok := n.gossipTimes.last[p.PubKeyHex]; ok && now.Sub(last) < interval {
+			metrics.IncrCounter("node.gossip.rate_limited", 1)
+			continue
+		}
+		n.gossipTimes.last[p.PubKeyHex] = now
+		targets = append(targets, p)
+	}
+	return targets
+}
+
+// forgetGossipTime drops the rate limit state of a removed peer
+func (n *Node) forgetGossipTime(pubKey string) {
+	n.gossipTimes.Lock()
+	delete(n.gossipTimes.last, pubKey)
+	n.gossipTimes.Unlock()
+}
+
+// gossipFanout gossips with several peers at once: it pulls from all of them
+// concurrently, inserts the events of all the responses with a single Sync,
+// which creates one event for the whole fan-out, and pushes to them
+// concurrently
+func (n *Node) gossipFanout(targets []*peers.Peer, parentReturnCh chan struct{}) error {
+	defer metrics.MeasureSince("node.gossip", time.Now())
+
+	n.coreLock.Lock()
+	knownEvents := n.core.KnownEvents()
+	n.coreLock.Unlock()
+
+	responses := make([]*net.SyncResponse, len(targets))
+	var wg sync.WaitGroup
+	for i, p := range targets {
+		wg.Add(1)
+		go func(i int, peerAddr string) {
+			defer wg.Done()
+			responses[i] = n.pullResponse(peerAddr, knownEvents)
+		}(i, p.NetAddr)
+	}
+	wg.Wait()
+
+	// concatenate the events the peers sent, once each
+	var events []poset.WireEvent
+	seen := make(map[[2]int64]bool)
+	syncLimit := false
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		if resp.SyncLimit {
+			syncLimit = true
+			continue
+		}
+		for _, we := range resp.Events {
+			key := [2]int64{we.Body.CreatorID, we.Body.Index}
+			if !seen[key] {
+				seen[key] = true
+				events = append(events, we)
+			}
+		}
+	}
+	if syncLimit {
+		n.logger.WithField("peers", len(targets)).Debug("SyncLimit")
+		metrics.IncrCounter("node.sync.limit", 1)
+		n.setState(CatchingUp)
+		parentReturnCh <- struct{}{}
+		return nil
+	}
+
+	n.coreLock.Lock()
+	err := n.sync(events)
+	n.coreLock.Unlock()
+	if err != nil {
+		// the faulty response is unknown, none of the peers is blamed
+		n.logger.WithField("error", err).Error("n.sync(events)")
+		metrics.IncrCounter("node.sync.errors", 1)
+		return err
+	}
+	metrics.IncrCounter("node.sync.events", int64(len(events)))
+
+	for i, p := range targets {
+		if responses[i] == nil || responses[i].Known == nil {
+			continue
+		}
+		n.recordBehaviour(p.PubKeyHex, Responsive)
+		wg.Add(1)
+		go func(peerAddr string, known map[int64]int64) {
+			defer wg.Done()
+			n.push(peerAddr, known)
+		}(p.NetAddr, responses[i].Known)
+	}
+	wg.Wait()
+
+	n.selectorLock.Lock()
+	n.peerSelector.UpdateLast(targets[0].NetAddr)
+	n.selectorLock.Unlock()
+
+	n.logger.WithFields(logrus.Fields{
+		"peers":  len(targets),
+		"events": len(events),
+	}).Debug("Gossip fan-out")
+	return nil
+}
+
+// pullResponse sends a SyncRequest to a peer of a fan-out and handles the
+// parts of the response which do not touch the poset, nil on failure. A
+// response over the sync limit has SyncLimit set, or with SyncChunked only
+// holds the first chunk of the diff, the next gossips pulling the rest.
+func (n *Node) pullResponse(peerAddr string, knownEvents map[int64]int64) *net.SyncResponse {
+	start := time.Now()
+	resp, err := n.requestSync(peerAddr, knownEvents)
+	end := time.Now()
+	metrics.MeasureSince("node.sync.request", start)
+	if lerrors.Is(err, lerrors.TooFar) {
+		n.logger.WithField("error", err).Debug("n.requestSync(peerAddr, knownEvents)")
+		return &net.SyncResponse{SyncLimit: true}
+	}
+	if err != nil {
+		n.logger.WithFields(logrus.Fields{
+			"peer":  peerAddr,
+			"error": err,
+		}).Error("n.requestSync(peerAddr, knownEvents)")
+		metrics.IncrCounter("node.sync.errors", 1)
+		n.recordBehaviour(n.peerPubKeyByAddr(peerAddr), SyncFailure)
+		return nil
+	}
+	if resp.Time != 0 {
+		n.clock.AddPeerSample(n.peerPubKeyByAddr(peerAddr), time.Unix(0, resp.Time), start, end)
+	}
+	n.recordSync(peerAddr, end.Sub(start), knownEvents, resp.Known)
+	if resp.Peers != nil {
+		if err := n.core.VerifyPeerExchange(resp.FromID, resp.Peers); err != nil {
+			n.logger.WithField("error", err).Warn("n.core.VerifyPeerExchange(resp.FromID, resp.Peers)")
+			n.recordBehaviour(n.peerPubKey(resp.FromID), ProtocolViolation)
+		} else {
+			n.offerPeers(resp.Peers.Peers, n.peerPubKey(resp.FromID))
+		}
+	}
+	return &resp
+}
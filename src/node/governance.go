@@ -0,0 +1,317 @@
+package node
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// Network parameters which PARAM_CHANGE internal transactions can set
+const (
+	// ParamSyncLimit is the maximum number of events per sync
+	ParamSyncLimit = "sync_limit"
+	// ParamMaxEventTransactions is the maximum number of transactions per
+	// event, which bounds the size of blocks
+	ParamMaxEventTransactions = "max_event_transactions"
+	// ParamHeartbeat is the time between gossips, a Go duration
+	ParamHeartbeat = "heartbeat"
+)
+
+// Bounds of the heartbeat parameter
+const (
+	MinHeartbeat = time.Millisecond
+	MaxHeartbeat = time.Minute
+)
+
+// ParseParam validates the value of a network parameter
+func ParseParam(name, value string) (interface{}, error) {
+	switch name {
+	case ParamSyncLimit, ParamMaxEventTransactions:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || v < 1 {
+			return nil, fmt.Errorf("%s must be a positive integer, got %q", name, value)
+		}
+		return v, nil
+	case ParamHeartbeat:
+		d, err := time.ParseDuration(value)
+		if err != nil || d < MinHeartbeat || d > MaxHeartbeat {
+			return nil, fmt.Errorf("%s must be a duration between %s and %s, got %q", name, MinHeartbeat, MaxHeartbeat, value)
+		}
+		return d, nil
+	}
+	return nil, fmt.Errorf("unknown network parameter %q", name)
+}
+
+// ProposeParamChange submits a PARAM_CHANGE internal transaction setting
+// name to value from activationRound on. Once the same change is proposed by
+// a supermajority of the validators in earlier rounds, every node applies it
+// when consensus reaches activationRound.
+func (n *Node) ProposeParamChange(name, value string, activationRound int64) error {
+	if _, err := ParseParam(name, value); err != nil {
+		return err
+	}
+	if last := n.core.GetLastConsensusRoundIndex(); last != nil && activationRound <= *last {
+		return fmt.Errorf("activation round %d is already decided", activationRound)
+	}
+	n.addInternalTransaction(poset.InternalTransaction{
+		Type: poset.TransactionType_PARAM_CHANGE,
+		Param: &poset.ParamChange{
+			Name:            name,
+			Value:           value,
+			ActivationRound: activationRound,
+		},
+	})
+	return nil
+}
+
+// GetParams returns the current values of the network parameters
+func (n *Node) GetParams() map[string]string {
+	n.coreLock.Lock()
+	maxEventTxs := n.core.maxTransactionsInEvent
+	n.coreLock.Unlock()
+	return map[string]string{
+		ParamSyncLimit:            strconv.FormatInt(atomic.LoadInt64(&n.syncLimit.Max), 10),
+		ParamMaxEventTransactions: strconv.Itoa(maxEventTxs),
+		ParamHeartbeat:            n.heartbeatTimeout().String(),
+	}
+}
+
+// GetPendingParamChanges returns the changes proposed by a supermajority
+// waiting for their activation round
+func (n *Node) GetPendingParamChanges() []poset.ParamChange {
+	n.paramsLock.Lock()
+	defer n.paramsLock.Unlock()
+	return append([]poset.ParamChange{}, n.params.Pending...)
+}
+
+// networkParams returns a copy of the governance state
+func (n *Node) networkParams() *poset.NetworkParams {
+	n.paramsLock.Lock()
+	defer n.paramsLock.Unlock()
+	return n.params.Copy()
+}
+
+// loadParams restores the governance state persisted in the store and sets
+// the parameters it holds
+func (n *Node) loadParams() error {
+	params, err := n.core.poset.Store.NetworkParams()
+	if err != nil || params == nil {
+		return err
+	}
+	n.paramsLock.Lock()
+	defer n.paramsLock.Unlock()
+	n.params = params
+	if n.params.Values == nil {
+		n.params.Values = make(map[string]string)
+	}
+	for name, value := range n.params.Values {
+		if _, err := ParseParam(name, value); err != nil {
+			return err
+		}
+		n.setParam(name, value)
+	}
+	return nil
+}
+
+// resetParams makes the governance state follow a reset of the poset to
+// round, the rounds before it being gone: params, the state of the node the
+// poset was reset from, is adopted when it covers them. Otherwise the
+// changes proposed before round are lost.
+func (n *Node) resetParams(round int64, params *poset.NetworkParams) error {
+	n.paramsLock.Lock()
+	defer n.paramsLock.Unlock()
+	if n.params.Round >= round {
+		return nil
+	}
+	if params != nil && params.Round >= round {
+		for name, value := range params.Values {
+			if _, err := ParseParam(name, value); err != nil {
+				return err
+			}
+		}
+		n.params = params.Copy()
+		for name, value := range n.params.Values {
+			n.setParam(name, value)
+		}
+	} else {
+		n.logger.WithFields(logrus.Fields{
+			"from":  n.params.Round + 1,
+			"round": round,
+		}).Warn("Skipping the parameter changes of the rounds before the reset")
+		n.params.Round = round
+	}
+	return n.core.poset.Store.SetNetworkParams(n.params)
+}
+
+// processParamChanges processes the PARAM_CHANGE transactions of the rounds
+// decided up to last which were not yet, in round order on every node: the
+// proposals of a round are counted, those reaching a supermajority of the
+// validators are scheduled, and the changes activated by the round are
+// applied. A round whose frame cannot be read is retried with the next call.
+// It runs with the core lock held.
+func (n *Node) processParamChanges(last int64) {
+	n.paramsLock.Lock()
+	defer n.paramsLock.Unlock()
+
+	from := n.params.Round
+	for round := from + 1; round <= last; round++ {
+		if err := n.processParamRound(round); err != nil {
+			n.logger.WithFields(logrus.Fields{
+				"round": round,
+				"error": err,
+			}).Error("Reading frame for parameter changes")
+			break
+		}
+	}
+	if n.params.Round == from {
+		return
+	}
+	if err := n.core.poset.Store.SetNetworkParams(n.params); err != nil {
+		n.logger.WithError(err).Error("Saving the network parameters")
+	}
+}
+
+// processParamRound processes the PARAM_CHANGE transactions of a decided
+// round
+func (n *Node) processParamRound(round int64) error {
+	frame, err := n.core.poset.GetFrame(round)
+	if err != nil {
+		return err
+	}
+	snapshot := n.core.participants.Snapshot()
+
+	for _, ev := range frame.Events {
+		creator := fmt.Sprintf("0x%X", ev.Body.Creator)
+		for _, tx := range ev.Body.InternalTransactions {
+			if tx.Type != poset.TransactionType_PARAM_CHANGE || tx.Param == nil {
+				continue
+			}
+			change := *tx.Param
+			logger := n.logger.WithFields(logrus.Fields{
+				"param":      change.Name,
+				"value":      change.Value,
+				"activation": change.ActivationRound,
+				"round":      round,
+				"proposer":   creator,
+			})
+			// every node drops the same changes, from the same frame
+			if _, err := ParseParam(change.Name, change.Value); err != nil {
+				logger.WithError(err).Warn("Ignoring invalid parameter change")
+				continue
+			}
+			if change.ActivationRound <= round {
+				logger.Warn("Ignoring parameter change committed after its activation round")
+				continue
+			}
+			if n.countProposal(change, creator, snapshot) {
+				logger.Info("Scheduled parameter change")
+			}
+		}
+	}
+
+	// the proposals which did not gather a supermajority in time expire
+	proposals := n.params.Proposals[:0]
+	for _, proposal := range n.params.Proposals {
+		if proposal.Change.ActivationRound > round {
+			proposals = append(proposals, proposal)
+		}
+	}
+	n.params.Proposals = proposals
+
+	// stable, so that the first change to reach a supermajority is applied
+	// first
+	sort.SliceStable(n.params.Pending, func(i, j int) bool {
+		return n.params.Pending[i].ActivationRound < n.params.Pending[j].ActivationRound
+	})
+	applied := 0
+	for _, change := range n.params.Pending {
+		if change.ActivationRound > round {
+			break
+		}
+		n.applyParam(change.Name, change.Value)
+		applied++
+	}
+	n.params.Pending = n.params.Pending[applied:]
+	n.params.Round = round
+	return nil
+}
+
+// countProposal adds the proposal of a change by creator, and schedules the
+// change once the validators which proposed it hold a supermajority of the
+// weight of snapshot. It returns true when the change was scheduled.
+func (n *Node) countProposal(change poset.ParamChange, creator string, snapshot *peers.Snapshot) bool {
+	for _, pending := range n.params.Pending {
+		if sameParamChange(pending, change) {
+			return false
+		}
+	}
+	i := 0
+	for ; i < len(n.params.Proposals); i++ {
+		if sameParamChange(n.params.Proposals[i].Change, change) {
+			break
+		}
+	}
+	if i == len(n.params.Proposals) {
+		n.params.Proposals = append(n.params.Proposals, poset.ParamProposal{Change: change})
+	}
+	proposal := &n.params.Proposals[i]
+	var weight uint64
+	for _, proposer := range proposal.Proposers {
+		if proposer == creator {
+			return false
+		}
+		weight += snapshot.Weight(proposer)
+	}
+	proposal.Proposers = append(proposal.Proposers, creator)
+	weight += snapshot.Weight(creator)
+	if weight < snapshot.SuperMajorityWeight() {
+		return false
+	}
+	n.params.Proposals = append(n.params.Proposals[:i], n.params.Proposals[i+1:]...)
+	n.params.Pending = append(n.params.Pending, change)
+	return true
+}
+
+// sameParamChange tells whether two changes set the same value from the same
+// round
+func sameParamChange(a, b poset.ParamChange) bool {
+	return a.Name == b.Name && a.Value == b.Value && a.ActivationRound == b.ActivationRound
+}
+
+// applyParam sets a validated network parameter and records it in the
+// governance state
+func (n *Node) applyParam(name, value string) {
+	n.setParam(name, value)
+	n.params.Values[name] = value
+	metrics.IncrCounter("node.params.applied", 1)
+	n.logger.WithFields(logrus.Fields{
+		"param": name,
+		"value": value,
+	}).Info("Applied parameter change")
+}
+
+// setParam sets a validated network parameter
+func (n *Node) setParam(name, value string) {
+	v, _ := ParseParam(name, value)
+	switch name {
+	case ParamSyncLimit:
+		n.syncLimit.SetMax(v.(int64))
+	case ParamMaxEventTransactions:
+		n.core.maxTransactionsInEvent = int(v.(int64))
+	case ParamHeartbeat:
+		atomic.StoreInt64(&n.heartbeat, int64(v.(time.Duration)))
+	}
+}
+
+// heartbeatTimeout returns the time between gossips
+func (n *Node) heartbeatTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&n.heartbeat))
+}
@@ -0,0 +1,150 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+func TestParseParam(t *testing.T) {
+	v, err := ParseParam(ParamSyncLimit, "500")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 500, v)
+
+	v, err = ParseParam(ParamHeartbeat, "250ms")
+	assert.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, v)
+
+	for _, bad := range [][2]string{
+		{ParamSyncLimit, "0"},
+		{ParamMaxEventTransactions, "many"},
+		{ParamHeartbeat, "1h"},
+		{"unknown", "1"},
+	} {
+		_, err := ParseParam(bad[0], bad[1])
+		assert.Error(t, err, "%s=%s", bad[0], bad[1])
+	}
+}
+
+func TestParamChangeMarshal(t *testing.T) {
+	tx := poset.InternalTransaction{
+		Type: poset.TransactionType_PARAM_CHANGE,
+		Param: &poset.ParamChange{
+			Name:            ParamHeartbeat,
+			Value:           "50ms",
+			ActivationRound: 42,
+		},
+	}
+	body := poset.EventBody{InternalTransactions: []*poset.InternalTransaction{&tx}}
+	raw, err := body.ProtoMarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded poset.EventBody
+	if err := decoded.ProtoUnmarshal(raw); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, decoded.InternalTransactions, 1) {
+		assert.Equal(t, poset.TransactionType_PARAM_CHANGE, decoded.InternalTransactions[0].Type)
+		assert.Equal(t, *tx.Param, *decoded.InternalTransactions[0].Param)
+	}
+}
+
+func TestParamProposalSupermajority(t *testing.T) {
+	n := &Node{
+		logger: logrus.New().WithField("test", t.Name()),
+		params: poset.NewNetworkParams(),
+	}
+	participants := peers.NewPeers()
+	for _, key := range []string{"0xAA", "0xBB", "0xCC", "0xDD"} {
+		participants.AddPeer(peers.NewPeer(key, ""))
+	}
+	snapshot := participants.Snapshot()
+	change := poset.ParamChange{Name: ParamHeartbeat, Value: "50ms", ActivationRound: 10}
+
+	// a validator counts once, and 3 out of 4 are needed
+	for _, proposer := range []string{"0xAA", "0xAA", "0xBB"} {
+		if n.countProposal(change, proposer, snapshot) {
+			t.Fatalf("change scheduled after the proposal of %s", proposer)
+		}
+	}
+	if !n.countProposal(change, "0xCC", snapshot) {
+		t.Fatal("change proposed by a supermajority should be scheduled")
+	}
+	if n.countProposal(change, "0xDD", snapshot) {
+		t.Fatal("change should be scheduled once")
+	}
+	assert.Equal(t, []poset.ParamChange{change}, n.params.Pending)
+	assert.Empty(t, n.params.Proposals)
+}
+
+func TestParamChangesPersisted(t *testing.T) {
+	cores, _, _ := initCores(1, t)
+	logger := common.NewTestLogger(t).WithField("test", t.Name())
+	n := &Node{
+		core:   cores[0],
+		logger: logger,
+		params: poset.NewNetworkParams(),
+	}
+	n.params.Round = 4
+
+	// a frame which cannot be read is retried
+	n.processParamChanges(6)
+	if n.params.Round != 4 {
+		t.Fatalf("round 5 should be retried, processed up to %d", n.params.Round)
+	}
+
+	tx := poset.InternalTransaction{
+		Type:  poset.TransactionType_PARAM_CHANGE,
+		Param: &poset.ParamChange{Name: ParamHeartbeat, Value: "50ms", ActivationRound: 6},
+	}
+	creator := cores[0].PubKey()
+	frames := []poset.Frame{
+		{Round: 5, Events: []*poset.EventMessage{{
+			Body: &poset.EventBody{Creator: creator, InternalTransactions: []*poset.InternalTransaction{&tx}},
+		}}},
+		{Round: 6},
+	}
+	for _, frame := range frames {
+		if err := cores[0].poset.Store.SetFrame(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n.processParamChanges(6)
+	assert.EqualValues(t, 6, n.params.Round)
+	assert.Equal(t, 50*time.Millisecond, n.heartbeatTimeout())
+
+	// the parameters survive a restart
+	restarted := &Node{
+		core:   cores[0],
+		logger: logger,
+		params: poset.NewNetworkParams(),
+	}
+	if err := restarted.loadParams(); err != nil {
+		t.Fatal(err)
+	}
+	assert.EqualValues(t, 6, restarted.params.Round)
+	assert.Equal(t, 50*time.Millisecond, restarted.heartbeatTimeout())
+
+	// and are taken from the peer a reset comes from when it processed the
+	// skipped rounds
+	peerParams := poset.NewNetworkParams()
+	peerParams.Round = 12
+	peerParams.Values[ParamHeartbeat] = "20ms"
+	if err := restarted.resetParams(10, peerParams); err != nil {
+		t.Fatal(err)
+	}
+	assert.EqualValues(t, 12, restarted.params.Round)
+	assert.Equal(t, 20*time.Millisecond, restarted.heartbeatTimeout())
+	if err := restarted.resetParams(20, nil); err != nil {
+		t.Fatal(err)
+	}
+	assert.EqualValues(t, 20, restarted.params.Round)
+	assert.Equal(t, 20*time.Millisecond, restarted.heartbeatTimeout())
+}
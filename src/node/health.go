@@ -0,0 +1,226 @@
+package node
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// DefaultReadyWindow is the default of Config.ReadyWindow
+const DefaultReadyWindow = time.Minute
+
+const (
+	// watchdogInterval is the period at which the watchdog acquires the
+	// core lock and probes the store
+	watchdogInterval = 5 * time.Second
+	// watchdogStalls is the number of periods without the watchdog getting
+	// the core lock after which a deadlock is reported
+	watchdogStalls = 3
+)
+
+// Readiness tells whether a node takes part in the consensus, so that it can
+// serve traffic: it bootstrapped, syncs with peers holding a super-majority
+// of the weight with its own, and decided a consensus round within the
+// ready window. Reasons explains why it is not ready.
+type Readiness struct {
+	Ready        bool
+	Bootstrapped bool
+	State        string
+	// ConnectedPeers are the peers synced with within the ready window
+	ConnectedPeers int
+	// ConnectedWeight is the weight of the connected peers and of the node,
+	// QuorumWeight the super-majority it must reach
+	ConnectedWeight    uint64
+	QuorumWeight       uint64
+	LastConsensusRound int64
+	// LastRoundAge is the time since the last consensus round was decided,
+	// or since the node started when none was
+	LastRoundAge string
+	Reasons      []string `json:",omitempty"`
+}
+
+// Health tells whether a node runs without failure: its store answers and
+// its core is not deadlocked. Reasons explains why it is not healthy.
+type Health struct {
+	Healthy    bool
+	StoreError string `json:",omitempty"`
+	// WatchdogAge is the time since the watchdog last acquired the core lock
+	WatchdogAge string
+	Reasons     []string `json:",omitempty"`
+}
+
+// healthState is what the node observed for its readiness and health
+type healthState struct {
+	sync.Mutex
+	bootstrapped bool
+	lastRound    int64
+	lastRoundAt  time.Time
+	// contacts are the times of the last successful exchange with the
+	// peers, by public key
+	contacts map[string]time.Time
+	storeErr error
+	// beat is the last time the watchdog acquired the core lock
+	beat time.Time
+}
+
+func (h *healthState) setBootstrapped() {
+	h.Lock()
+	h.bootstrapped = true
+	h.Unlock()
+}
+
+func (h *healthState) roundDecided(round int64) {
+	h.Lock()
+	defer h.Unlock()
+	if round > h.lastRound || h.lastRoundAt.IsZero() {
+		h.lastRound = round
+		h.lastRoundAt = time.Now()
+	}
+}
+
+func (h *healthState) contact(pubKey string) {
+	if pubKey == "" {
+		return
+	}
+	h.Lock()
+	if h.contacts == nil {
+		h.contacts = make(map[string]time.Time)
+	}
+	h.contacts[pubKey] = time.Now()
+	h.Unlock()
+}
+
+func (h *healthState) setStoreError(err error) {
+	h.Lock()
+	h.storeErr = err
+	h.Unlock()
+}
+
+func (h *healthState) setBeat(t time.Time) {
+	h.Lock()
+	h.beat = t
+	h.Unlock()
+}
+
+// recordStoreError keeps err for the health when it is a failure of the
+// store, until the watchdog probes the store successfully
+func (n *Node) recordStoreError(err error) {
+	if lerrors.Is(err, lerrors.StoreCorrupt) {
+		n.health.setStoreError(err)
+	}
+}
+
+// runWatchdog acquires the core lock and probes the store every
+// watchdogInterval, so that Health reports a core stuck holding the lock
+func (n *Node) runWatchdog() {
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+	for {
+		n.probe()
+		select {
+		case <-ticker.C:
+		case <-n.shutdownCh:
+			return
+		}
+	}
+}
+
+// probe reads the last block of the store under the core lock
+func (n *Node) probe() {
+	n.coreLock.Lock()
+	var err error
+	if last := n.core.GetLastBlockIndex(); last >= 0 {
+		_, err = n.core.poset.Store.GetBlock(last)
+		if lerrors.Is(err, lerrors.KeyNotFound) || lerrors.Is(err, lerrors.TooFar) {
+			err = nil
+		}
+	}
+	n.coreLock.Unlock()
+
+	if err != nil {
+		n.logger.WithError(err).Error("Probing store")
+		metrics.IncrCounter("node.health.store_errors", 1)
+	}
+	n.health.setStoreError(err)
+	n.health.setBeat(time.Now())
+}
+
+// GetReadiness returns the readiness of the node
+func (n *Node) GetReadiness() Readiness {
+	now := time.Now()
+	snapshot := n.core.participants.Snapshot()
+	state := n.getState()
+
+	n.health.Lock()
+	res := Readiness{
+		Bootstrapped:       n.health.bootstrapped,
+		State:              state.String(),
+		LastConsensusRound: -1,
+		QuorumWeight:       snapshot.SuperMajorityWeight(),
+		ConnectedWeight:    snapshot.Weight(n.core.HexID()),
+	}
+	for pubKey, t := range n.health.contacts {
+		if n.conf.ReadyWindow > 0 && now.Sub(t) > n.conf.ReadyWindow {
+			continue
+		}
+		if _, ok := snapshot.ByPubKey(pubKey); ok {
+			res.ConnectedPeers++
+			res.ConnectedWeight += snapshot.Weight(pubKey)
+		}
+	}
+	lastRoundAt := n.start
+	if !n.health.lastRoundAt.IsZero() {
+		res.LastConsensusRound = n.health.lastRound
+		lastRoundAt = n.health.lastRoundAt
+	}
+	n.health.Unlock()
+
+	age := now.Sub(lastRoundAt)
+	res.LastRoundAge = age.String()
+	switch {
+	case state == Shutdown:
+		res.Reasons = append(res.Reasons, "the node is shutting down")
+	case state == CatchingUp:
+		res.Reasons = append(res.Reasons, "the node is catching up")
+	}
+	if !res.Bootstrapped {
+		res.Reasons = append(res.Reasons, "the node did not bootstrap")
+	}
+	if res.ConnectedWeight < res.QuorumWeight {
+		res.Reasons = append(res.Reasons, fmt.Sprintf("connected to a weight of %d, %d needed", res.ConnectedWeight, res.QuorumWeight))
+	}
+	if n.conf.ReadyWindow > 0 && age > n.conf.ReadyWindow {
+		res.Reasons = append(res.Reasons, fmt.Sprintf("no consensus round decided for %s", age.Round(time.Second)))
+	}
+	res.Ready = len(res.Reasons) == 0
+	return res
+}
+
+// GetHealth returns the health of the node
+func (n *Node) GetHealth() Health {
+	n.health.Lock()
+	storeErr := n.health.storeErr
+	beat := n.health.beat
+	n.health.Unlock()
+	if beat.IsZero() {
+		beat = n.start
+	}
+
+	age := time.Since(beat)
+	res := Health{WatchdogAge: age.String()}
+	if n.getState() == Shutdown {
+		res.Reasons = append(res.Reasons, "the node is shutting down")
+	}
+	if storeErr != nil {
+		res.StoreError = storeErr.Error()
+		res.Reasons = append(res.Reasons, "the store failed")
+	}
+	if age > watchdogStalls*watchdogInterval {
+		res.Reasons = append(res.Reasons, fmt.Sprintf("the core lock was not acquired for %s, deadlock suspected", age.Round(time.Second)))
+	}
+	res.Healthy = len(res.Reasons) == 0
+	return res
+}
@@ -0,0 +1,96 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+)
+
+func TestReadiness(t *testing.T) {
+	cores, _, _ := initCores(4, t)
+	n := &Node{
+		conf:   TestConfig(t),
+		logger: common.NewTestLogger(t).WithField("this_id", 0),
+		core:   cores[0],
+		start:  time.Now(),
+	}
+
+	r := n.GetReadiness()
+	if r.Ready || r.Bootstrapped {
+		t.Fatalf("a node which did not bootstrap should not be ready: %+v", r)
+	}
+	if r.ConnectedWeight != 1 || r.QuorumWeight != 3 {
+		t.Fatalf("the node should have a weight of 1 of the 3 needed, got %+v", r)
+	}
+
+	n.health.setBootstrapped()
+	n.health.contact(cores[1].HexID())
+	if r := n.GetReadiness(); r.Ready {
+		t.Fatalf("a node connected to a single peer of 3 should not be ready: %+v", r)
+	}
+	n.health.contact(cores[2].HexID())
+	r = n.GetReadiness()
+	if !r.Ready || r.ConnectedPeers != 2 || r.ConnectedWeight != 3 {
+		t.Fatalf("a node connected to a super-majority should be ready: %+v", r)
+	}
+
+	// contacts older than the ready window are not counted
+	n.health.Lock()
+	n.health.contacts[cores[2].HexID()] = time.Now().Add(-2 * n.conf.ReadyWindow)
+	n.health.Unlock()
+	if r := n.GetReadiness(); r.Ready || r.ConnectedPeers != 1 {
+		t.Fatalf("a stale peer should not count as connected: %+v", r)
+	}
+	n.health.contact(cores[3].HexID())
+
+	n.health.roundDecided(4)
+	r = n.GetReadiness()
+	if !r.Ready || r.LastConsensusRound != 4 {
+		t.Fatalf("the node should be ready at round 4: %+v", r)
+	}
+
+	n.health.Lock()
+	n.health.lastRoundAt = time.Now().Add(-2 * n.conf.ReadyWindow)
+	n.health.Unlock()
+	if r := n.GetReadiness(); r.Ready || len(r.Reasons) != 1 {
+		t.Fatalf("a node without consensus round for 2 windows should not be ready: %+v", r)
+	}
+
+	n.conf.ReadyWindow = 0
+	if r := n.GetReadiness(); !r.Ready {
+		t.Fatalf("the window should not be checked when 0: %+v", r)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	cores, _, _ := initCores(1, t)
+	n := &Node{
+		conf:   TestConfig(t),
+		logger: common.NewTestLogger(t).WithField("this_id", 0),
+		core:   cores[0],
+		start:  time.Now(),
+	}
+
+	n.probe()
+	if h := n.GetHealth(); !h.Healthy {
+		t.Fatalf("the node should be healthy: %+v", h)
+	}
+
+	n.health.setBeat(time.Now().Add(-(watchdogStalls + 1) * watchdogInterval))
+	if h := n.GetHealth(); h.Healthy {
+		t.Fatalf("a stalled watchdog should make the node unhealthy: %+v", h)
+	}
+
+	n.probe()
+	n.health.setStoreError(fmt.Errorf("disk failure"))
+	h := n.GetHealth()
+	if h.Healthy || h.StoreError != "disk failure" {
+		t.Fatalf("a store error should make the node unhealthy: %+v", h)
+	}
+	n.probe()
+	if h := n.GetHealth(); !h.Healthy {
+		t.Fatalf("a successful probe should clear the store error: %+v", h)
+	}
+}
@@ -0,0 +1,70 @@
+package node
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// The heartbeat adapts to the load: while the pools hold transactions or
+// block signatures, or loaded events are pending, the node gossips every
+// min-heartbeat. When it has nothing to say, and its last gossips brought
+// no new events, the time between gossips doubles up to max-heartbeat. A
+// new event brings it back to the heartbeat.
+
+// DefaultMaxHeartbeat is the time between the gossips of an idle node
+const DefaultMaxHeartbeat = time.Second
+
+// adaptiveHeartbeat is the backoff of the heartbeat of an idle node
+type adaptiveHeartbeat struct {
+	sync.Mutex
+	idle  time.Duration
+	known int64 // the number of known events at the last heartbeat
+}
+
+// next returns the time until the next gossip, base being the heartbeat,
+// min and max its bounds, busy whether the node has something to gossip
+// and known the number of events it knows of
+func (h *adaptiveHeartbeat) next(base, min, max time.Duration, busy bool, known int64) time.Duration {
+	h.Lock()
+	defer h.Unlock()
+	if min <= 0 || min > base {
+		min = base
+	}
+	if max < base {
+		max = base
+	}
+	newEvents := known != h.known
+	h.known = known
+	switch {
+	case busy:
+		h.idle = 0
+		return min
+	case newEvents || h.idle == 0:
+		h.idle = base
+	default:
+		h.idle *= 2
+		if h.idle > max {
+			h.idle = max
+		}
+	}
+	return h.idle
+}
+
+// nextHeartbeat returns the time until the next gossip, see
+// adaptiveHeartbeat
+func (n *Node) nextHeartbeat() time.Duration {
+	n.coreLock.Lock()
+	knownEvents := n.core.KnownEvents()
+	busy := n.core.NeedGossip()
+	n.coreLock.Unlock()
+	known := int64(0)
+	for _, index := range knownEvents {
+		known += index + 1
+	}
+	ts := n.adaptiveHeartbeat.next(n.heartbeatTimeout(), n.conf.MinHeartbeat,
+		n.conf.MaxHeartbeat, busy, known)
+	metrics.SetGauge("node.heartbeat", ts.Seconds())
+	return ts
+}
@@ -0,0 +1,49 @@
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveHeartbeat(t *testing.T) {
+	const (
+		base = 100 * time.Millisecond
+		min  = 10 * time.Millisecond
+		max  = 350 * time.Millisecond
+	)
+	var h adaptiveHeartbeat
+	steps := []struct {
+		busy  bool
+		known int64
+		want  time.Duration
+	}{
+		{false, 0, base},
+		// idle without new events: backs off up to max
+		{false, 0, 2 * base},
+		{false, 0, max},
+		{false, 0, max},
+		// a new event brings it back to the heartbeat
+		{false, 1, base},
+		{false, 1, 2 * base},
+		// under load: min, then the heartbeat when idle again
+		{true, 1, min},
+		{false, 1, base},
+	}
+	for i, s := range steps {
+		if got := h.next(base, min, max, s.busy, s.known); got != s.want {
+			t.Fatalf("step %d: heartbeat should be %v, not %v", i, s.want, got)
+		}
+	}
+
+	// bounds outside the heartbeat fall back to it
+	h = adaptiveHeartbeat{}
+	if got := h.next(base, 0, 0, true, 0); got != base {
+		t.Fatalf("busy heartbeat without min should be %v, not %v", base, got)
+	}
+	if got := h.next(base, 0, 0, false, 0); got != base {
+		t.Fatalf("idle heartbeat below the heartbeat should be %v, not %v", base, got)
+	}
+	if got := h.next(base, 0, 0, false, 0); got != base {
+		t.Fatalf("idle heartbeat below the heartbeat should stay %v, not %v", base, got)
+	}
+}
@@ -0,0 +1,210 @@
+package node
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// poolJournal is a write-ahead journal of the pools of a Core. Items are
+// appended and synced before they enter the pools, and the journal is
+// rewritten with what is left in the pools once a self-event took some of
+// them. A crash between the creation of an event and the rewrite replays the
+// items of that event: the journal delivers them at least once.
+type poolJournal struct {
+	path string
+	file *os.File
+	w    *bufio.Writer
+}
+
+// journalRecord is a line of the journal, holding one pool item
+type journalRecord struct {
+	Tx        []byte                     `json:",omitempty"`
+	Internal  *poset.InternalTransaction `json:",omitempty"`
+	Signature *poset.BlockSignature      `json:",omitempty"`
+}
+
+// openPoolJournal reads the records left at path and opens the journal for
+// appending
+func openPoolJournal(path string) (*poolJournal, []journalRecord, error) {
+	var records []journalRecord
+	f, err := os.Open(path)
+	switch {
+	case err == nil:
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, 64<<20)
+		for scanner.Scan() {
+			var r journalRecord
+			if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+				// a torn last line, written during a crash, was never
+				// acknowledged
+				break
+			}
+			records = append(records, r)
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading pool journal %s: %s", path, err)
+		}
+	case !os.IsNotExist(err):
+		return nil, nil, err
+	}
+
+	j := &poolJournal{path: path}
+	// compact, dropping a torn line
+	if err := j.rewrite(records); err != nil {
+		return nil, nil, err
+	}
+	return j, records, nil
+}
+
+// append persists records before their items enter the pools
+func (j *poolJournal) append(records ...journalRecord) error {
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		j.w.Write(data)
+		j.w.WriteByte('\n')
+	}
+	if err := j.w.Flush(); err != nil {
+		return err
+	}
+	metrics.IncrCounter("node.journal.records", int64(len(records)))
+	return j.file.Sync()
+}
+
+// rewrite replaces the journal with records, atomically
+func (j *poolJournal) rewrite(records []journalRecord) error {
+	tmp := j.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		w.Write(data)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return err
+	}
+
+	if j.file != nil {
+		j.file.Close()
+	}
+	j.file, err = os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	j.w = bufio.NewWriter(j.file)
+	return nil
+}
+
+func (j *poolJournal) close() error {
+	return j.file.Close()
+}
+
+// OpenJournal makes the core keep its pools in the journal at path, and adds
+// the transactions and block signatures a previous run left in it to the
+// pools
+func (c *Core) OpenJournal(path string) error {
+	j, records, err := openPoolJournal(path)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		switch {
+		case r.Tx != nil:
+			c.poolTransaction(r.Tx)
+			c.transactionPoolBytes += int64(len(r.Tx))
+		case r.Internal != nil:
+			c.internalTransactionPool = append(c.internalTransactionPool, *r.Internal)
+		case r.Signature != nil:
+			c.blockSignaturePool = append(c.blockSignaturePool, *r.Signature)
+		}
+	}
+	c.journal = j
+	if len(records) > 0 {
+		c.logger.WithFields(logrus.Fields{
+			"path":    path,
+			"records": len(records),
+		}).Info("Replayed pool journal")
+	}
+	return nil
+}
+
+// CloseJournal closes the journal of the pools, if any
+func (c *Core) CloseJournal() error {
+	if c.journal == nil {
+		return nil
+	}
+	err := c.journal.close()
+	c.journal = nil
+	return err
+}
+
+// journalAppend persists pool items. A failure is logged: the items are
+// still accepted, only not crash-safe.
+func (c *Core) journalAppend(records ...journalRecord) {
+	if c.journal == nil {
+		return
+	}
+	if err := c.journal.append(records...); err != nil {
+		metrics.IncrCounter("node.journal.errors", 1)
+		c.logger.WithError(err).Error("Appending to the pool journal")
+	}
+}
+
+// journalCheckpoint rewrites the journal with the current pools. Within a
+// batch it is deferred to EndBatch: until the batch is committed, the items
+// the self-events took are in the journal only.
+func (c *Core) journalCheckpoint() {
+	if c.journal == nil {
+		return
+	}
+	if c.batching {
+		c.checkpointPending = true
+		return
+	}
+	c.checkpointPending = false
+	records := make([]journalRecord, 0,
+		len(c.transactionPool)+len(c.internalTransactionPool)+len(c.blockSignaturePool))
+	for _, tx := range c.transactionPool {
+		records = append(records, journalRecord{Tx: tx})
+	}
+	for i := range c.internalTransactionPool {
+		records = append(records, journalRecord{Internal: &c.internalTransactionPool[i]})
+	}
+	for i := range c.blockSignaturePool {
+		records = append(records, journalRecord{Signature: &c.blockSignaturePool[i]})
+	}
+	if err := c.journal.rewrite(records); err != nil {
+		metrics.IncrCounter("node.journal.errors", 1)
+		c.logger.WithError(err).Error("Rewriting the pool journal")
+	}
+}
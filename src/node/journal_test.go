@@ -0,0 +1,78 @@
+package node
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+func TestPoolJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lachesis-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "pool.wal")
+
+	cores, _, _ := initCores(2, t)
+	core := cores[0]
+	if err := core.OpenJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	txs := [][]byte{[]byte("tx1"), []byte("tx2")}
+	sig := poset.BlockSignature{Validator: core.PubKey(), Index: 3, Signature: "sig"}
+	core.AddTransactions(txs)
+	core.AddBlockSignature(sig)
+
+	// a crash leaves the journal open, with a torn last line
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"Tx":"dHgz`)
+	f.Close()
+
+	restarted := cores[1]
+	if err := restarted.OpenJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restarted.transactionPool, txs) {
+		t.Fatalf("expected transactions %q, got %q", txs, restarted.transactionPool)
+	}
+	if len(restarted.blockSignaturePool) != 1 || restarted.blockSignaturePool[0].Index != sig.Index {
+		t.Fatalf("expected block signature %v, got %v", sig, restarted.blockSignaturePool)
+	}
+
+	// once an event took them, the items are no longer replayed, but not
+	// before the batch of the event is committed
+	restarted.BeginBatch()
+	if err := restarted.AddSelfEventBlock(""); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(data, []byte("\n")); n != len(txs)+1 {
+		t.Fatalf("expected the %d items in the journal until the batch is committed, got %d", len(txs)+1, n)
+	}
+	if err := restarted.EndBatch(); err != nil {
+		t.Fatal(err)
+	}
+	if err := restarted.CloseJournal(); err != nil {
+		t.Fatal(err)
+	}
+	j, records, err := openPoolJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.close()
+	if len(records) != 0 {
+		t.Fatalf("expected an empty journal, got %d records", len(records))
+	}
+}
@@ -0,0 +1,122 @@
+package node
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// DefaultLatencyWindow is the number of the last committed transactions over
+// which the percentiles of the commit latency are computed
+const DefaultLatencyWindow = 1000
+
+// latencyPercentiles are the percentiles of the commit latency reported in
+// the stats and the metrics
+var latencyPercentiles = []int{50, 95, 99}
+
+// latencyWindow keeps the commit latencies of the last transactions
+// submitted to the node, the lock of the txTracker guarding it
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+// newLatencyWindow returns a window of size latencies, DefaultLatencyWindow
+// when 0
+func newLatencyWindow(size int) *latencyWindow {
+	if size <= 0 {
+		size = DefaultLatencyWindow
+	}
+	return &latencyWindow{samples: make([]time.Duration, size)}
+}
+
+// add records a latency, replacing the oldest one of a full window
+func (w *latencyWindow) add(d time.Duration) {
+	w.samples[w.next] = d
+	w.next++
+	if w.next == len(w.samples) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+// percentiles returns the latencies of latencyPercentiles by the nearest
+// rank, nil when the window is empty
+func (w *latencyWindow) percentiles() []time.Duration {
+	n := w.next
+	if w.full {
+		n = len(w.samples)
+	}
+	if n == 0 {
+		return nil
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, w.samples[:n])
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	res := make([]time.Duration, len(latencyPercentiles))
+	for i, p := range latencyPercentiles {
+		rank := int(math.Ceil(float64(p) / 100 * float64(n)))
+		if rank < 1 {
+			rank = 1
+		}
+		res[i] = sorted[rank-1]
+	}
+	return res
+}
+
+// trackSubmitted records the submission time of a pooled transaction
+func (n *Node) trackSubmitted(hash string, at time.Time) {
+	n.txs.Lock()
+	n.txs.submitted.Add(hash, at)
+	n.txs.Unlock()
+}
+
+// trackLatency records the commit latency of a transaction submitted to the
+// node, logging it above Config.SlowTxThreshold. The lock of the txTracker
+// must be held.
+func (n *Node) trackLatency(hash string, block int64, now time.Time) {
+	at, ok := n.txs.submitted.Get(hash)
+	if !ok {
+		return
+	}
+	n.txs.submitted.Remove(hash)
+	latency := now.Sub(at.(time.Time))
+	n.txs.latencies.add(latency)
+	metrics.AddSample("node.tx.commit_latency", float64(latency)/float64(time.Millisecond))
+	if n.conf.SlowTxThreshold > 0 && latency > n.conf.SlowTxThreshold {
+		metrics.IncrCounter("node.tx.slow", 1)
+		n.logger.WithFields(logrus.Fields{
+			"tx":      hash,
+			"block":   block,
+			"latency": latency,
+		}).Warn("Slow transaction")
+	}
+}
+
+// reportLatency sets the gauges of the percentiles of the commit latency.
+// The lock of the txTracker must be held.
+func (n *Node) reportLatency() {
+	for i, l := range n.txs.latencies.percentiles() {
+		metrics.SetGauge("node.tx.commit_latency_p"+strconv.Itoa(latencyPercentiles[i]),
+			float64(l)/float64(time.Millisecond))
+	}
+}
+
+// latencyStats returns the percentiles of the commit latency, in seconds,
+// for GetStats
+func (n *Node) latencyStats() map[string]string {
+	n.txs.Lock()
+	defer n.txs.Unlock()
+	s := make(map[string]string)
+	for i, l := range n.txs.latencies.percentiles() {
+		s["commit_latency_p"+strconv.Itoa(latencyPercentiles[i])] = strconv.FormatFloat(l.Seconds(), 'f', 3, 64)
+	}
+	return s
+}
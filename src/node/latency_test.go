@@ -0,0 +1,60 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/memory"
+)
+
+func TestLatencyWindow(t *testing.T) {
+	w := newLatencyWindow(100)
+	if p := w.percentiles(); p != nil {
+		t.Fatalf("an empty window should have no percentiles, got %v", p)
+	}
+	// 1ms to 150ms: the window keeps 51ms to 150ms
+	for i := 1; i <= 150; i++ {
+		w.add(time.Duration(i) * time.Millisecond)
+	}
+	expected := []time.Duration{100 * time.Millisecond, 145 * time.Millisecond, 149 * time.Millisecond}
+	for i, p := range w.percentiles() {
+		if p != expected[i] {
+			t.Errorf("p%d: expected %v, got %v", latencyPercentiles[i], expected[i], p)
+		}
+	}
+}
+
+func TestCommitLatency(t *testing.T) {
+	cores, _, _ := initCores(1, t)
+	n := &Node{
+		conf:   TestConfig(t),
+		logger: common.NewTestLogger(t).WithField("this_id", 0),
+		core:   cores[0],
+		budget: memory.NewBudget(0),
+		txs:    newTxTracker(),
+	}
+	n.conf.SlowTxThreshold = time.Millisecond
+
+	tx := []byte("timed")
+	if err := n.addTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+	// a transaction of another node has no latency
+	n.trackCommitted(1, [][]byte{[]byte("foreign")})
+	if s := n.latencyStats(); len(s) != 0 {
+		t.Fatalf("no latency should be recorded yet, got %v", s)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	n.trackCommitted(2, [][]byte{tx})
+	s := n.latencyStats()
+	for _, key := range []string{"commit_latency_p50", "commit_latency_p95", "commit_latency_p99"} {
+		if s[key] == "" {
+			t.Errorf("%s should be reported, got %v", key, s)
+		}
+	}
+	if n.txs.submitted.Contains(TxHash(tx)) {
+		t.Fatal("the submission time of a committed transaction should be forgotten")
+	}
+}
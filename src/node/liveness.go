@@ -0,0 +1,220 @@
+package node
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+const (
+	// DefaultSuspectMisses is the default of Config.SuspectMisses
+	DefaultSuspectMisses = 3
+	// DefaultDownMisses is the default of Config.DownMisses
+	DefaultDownMisses = 6
+	// DefaultDownProbeInterval is the default of Config.DownProbeInterval
+	DefaultDownProbeInterval = 30 * time.Second
+)
+
+// Liveness is the state the failure detector gives a peer
+type Liveness int
+
+const (
+	// PeerAlive is the state of the peers which answered the last syncs
+	PeerAlive Liveness = iota
+	// PeerSuspect is the state of the peers which missed Config.SuspectMisses
+	// syncs in a row
+	PeerSuspect
+	// PeerDown is the state of the peers which missed Config.DownMisses
+	// syncs in a row. They are not selected for gossip but once every
+	// Config.DownProbeInterval.
+	PeerDown
+)
+
+func (l Liveness) String() string {
+	switch l {
+	case PeerAlive:
+		return "alive"
+	case PeerSuspect:
+		return "suspect"
+	case PeerDown:
+		return "down"
+	}
+	return "unknown"
+}
+
+// PeerLiveness is what the failure detector knows of a peer
+type PeerLiveness struct {
+	State string
+	// Misses is the number of syncs in a row the peer missed
+	Misses int
+	// LastSeen is the time of the last exchange with the peer, zero when
+	// there was none
+	LastSeen time.Time
+}
+
+// livenessTable is the failure detector: it counts the syncs each peer
+// missed in a row since its last successful exchange
+type livenessTable struct {
+	sync.Mutex
+	suspectMisses int
+	downMisses    int
+	probeInterval time.Duration
+	peers         map[string]*peerLiveness
+}
+
+type peerLiveness struct {
+	state    Liveness
+	misses   int
+	lastSeen time.Time
+	// lastTry is the time the peer was last selected or missed a sync
+	lastTry time.Time
+}
+
+func newLivenessTable(suspectMisses, downMisses int, probeInterval time.Duration) *livenessTable {
+	return &livenessTable{
+		suspectMisses: suspectMisses,
+		downMisses:    downMisses,
+		probeInterval: probeInterval,
+		peers:         make(map[string]*peerLiveness),
+	}
+}
+
+func (t *livenessTable) get(pubKey string) *peerLiveness {
+	l, ok := t.peers[pubKey]
+	if !ok {
+		l = &peerLiveness{}
+		t.peers[pubKey] = l
+	}
+	return l
+}
+
+// seen records a successful exchange with a peer and returns its previous
+// state
+func (t *livenessTable) seen(pubKey string, now time.Time) Liveness {
+	t.Lock()
+	defer t.Unlock()
+	l := t.get(pubKey)
+	prev := l.state
+	l.state = PeerAlive
+	l.misses = 0
+	l.lastSeen = now
+	return prev
+}
+
+// missed records a failed sync with a peer and returns its previous and new
+// states
+func (t *livenessTable) missed(pubKey string, now time.Time) (Liveness, Liveness) {
+	t.Lock()
+	defer t.Unlock()
+	l := t.get(pubKey)
+	prev := l.state
+	l.misses++
+	l.lastTry = now
+	switch {
+	case t.downMisses > 0 && l.misses >= t.downMisses:
+		l.state = PeerDown
+	case t.suspectMisses > 0 && l.misses >= t.suspectMisses:
+		l.state = PeerSuspect
+	}
+	return prev, l.state
+}
+
+// selectable tells whether a peer may be selected for gossip: it is not
+// down, or it was last tried more than the probe interval ago
+func (t *livenessTable) selectable(pubKey string, now time.Time) bool {
+	t.Lock()
+	defer t.Unlock()
+	l, ok := t.peers[pubKey]
+	return !ok || l.state != PeerDown || now.Sub(l.lastTry) >= t.probeInterval
+}
+
+// tried records the selection of a down peer as a probe, so that it is not
+// selected again before the probe interval
+func (t *livenessTable) tried(pubKey string, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	if l, ok := t.peers[pubKey]; ok && l.state == PeerDown {
+		l.lastTry = now
+	}
+}
+
+func (t *livenessTable) forget(pubKey string) {
+	t.Lock()
+	delete(t.peers, pubKey)
+	t.Unlock()
+}
+
+// counts returns the number of peers in each state
+func (t *livenessTable) counts() map[Liveness]int {
+	t.Lock()
+	defer t.Unlock()
+	res := make(map[Liveness]int)
+	for _, l := range t.peers {
+		res[l.state]++
+	}
+	return res
+}
+
+// recordLiveness passes the outcome of an exchange with a peer to the
+// failure detector, logging the changes of state
+func (n *Node) recordLiveness(pubKey string, ok bool) {
+	if n.liveness == nil {
+		return
+	}
+	now := time.Now()
+	if ok {
+		if prev := n.liveness.seen(pubKey, now); prev != PeerAlive {
+			n.logger.WithFields(logrus.Fields{
+				"peer": pubKey,
+				"was":  prev.String(),
+			}).Info("Peer is alive")
+		}
+		return
+	}
+	prev, state := n.liveness.missed(pubKey, now)
+	if state != prev {
+		metrics.IncrCounter("node.peers."+state.String(), 1)
+		n.logger.WithFields(logrus.Fields{
+			"peer":  pubKey,
+			"state": state.String(),
+		}).Warn("Peer liveness changed")
+	}
+}
+
+// GetPeerLiveness returns what the failure detector knows of every known
+// peer, by public key
+func (n *Node) GetPeerLiveness() map[string]PeerLiveness {
+	known := n.peerSelector.Peers().ToPeerSlice()
+	self := n.core.HexID()
+	n.liveness.Lock()
+	defer n.liveness.Unlock()
+	res := make(map[string]PeerLiveness)
+	for _, p := range known {
+		if p.PubKeyHex == self {
+			continue
+		}
+		info := PeerLiveness{State: PeerAlive.String()}
+		if l, ok := n.liveness.peers[p.PubKeyHex]; ok {
+			info = PeerLiveness{
+				State:    l.state.String(),
+				Misses:   l.misses,
+				LastSeen: l.lastSeen,
+			}
+		}
+		res[p.PubKeyHex] = info
+	}
+	return res
+}
+
+// livenessStats returns the number of suspect and down peers for GetStats
+func (n *Node) livenessStats() map[string]string {
+	counts := n.liveness.counts()
+	return map[string]string{
+		"peers_suspect": strconv.Itoa(counts[PeerSuspect]),
+		"peers_down":    strconv.Itoa(counts[PeerDown]),
+	}
+}
@@ -0,0 +1,66 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+func TestLivenessTable(t *testing.T) {
+	table := newLivenessTable(2, 4, time.Minute)
+	now := time.Now()
+
+	expected := []Liveness{PeerAlive, PeerSuspect, PeerSuspect, PeerDown}
+	for i, state := range expected {
+		if _, s := table.missed("0x01", now); s != state {
+			t.Fatalf("after %d misses the peer should be %s, got %s", i+1, state, s)
+		}
+	}
+	if table.selectable("0x01", now.Add(time.Second)) {
+		t.Fatal("a down peer should not be selected before the probe interval")
+	}
+	if !table.selectable("0x01", now.Add(time.Minute)) {
+		t.Fatal("a down peer should be probed after the probe interval")
+	}
+	table.tried("0x01", now.Add(time.Minute))
+	if table.selectable("0x01", now.Add(time.Minute+time.Second)) {
+		t.Fatal("a probed peer should wait for the next probe interval")
+	}
+
+	if prev := table.seen("0x01", now); prev != PeerDown {
+		t.Fatalf("the peer should have been down, got %s", prev)
+	}
+	if _, s := table.missed("0x01", now); s != PeerAlive {
+		t.Fatalf("a peer seen again should start over, got %s", s)
+	}
+}
+
+func TestSmartPeerSelectorLiveness(t *testing.T) {
+	participants := peers.NewPeers()
+	for i := 0; i < 5; i++ {
+		participants.AddPeer(&peers.Peer{
+			ID:        int64(i + 1),
+			NetAddr:   fmt.Sprintf("addr%d", i),
+			PubKeyHex: fmt.Sprintf("0x%02d", i),
+		})
+	}
+
+	ps := NewSmartPeerSelector(participants, "0x00", nil, nil,
+		func() (map[string]int64, error) {
+			return nil, fmt.Errorf("no flag table")
+		})
+	ps.liveness = newLivenessTable(1, 2, time.Hour)
+	for i := 0; i < 2; i++ {
+		ps.liveness.missed("0x03", time.Now())
+	}
+
+	for i := 0; i < 30; i++ {
+		for _, p := range ps.NextN(2) {
+			if p.PubKeyHex == "0x03" {
+				t.Fatal("a down peer should not be selected")
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package node
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/log"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// Settings which Reload changes at runtime, named after their flags
+const (
+	SettingHeartbeat = "heartbeat"
+	SettingSyncLimit = "sync-limit"
+	SettingCacheSize = "cache-size"
+	SettingLogLevel  = "log-level"
+)
+
+// Maintenance is the state of a node for its operators
+type Maintenance struct {
+	Paused bool
+	// PendingBlocks is the number of blocks not yet acknowledged by the
+	// application
+	PendingBlocks int64
+	Settings      map[string]string
+}
+
+// Pause stops the node from gossiping: it no longer initiates syncs nor
+// accepts the events pushed by its peers, so that no new block is decided.
+// It still answers the syncs of the peers and the application.
+func (n *Node) Pause() {
+	if atomic.CompareAndSwapInt32(&n.paused, 0, 1) {
+		metrics.SetGauge("node.paused", 1)
+		n.logger.Info("Gossip paused")
+	}
+}
+
+// Resume restarts the gossip stopped by Pause
+func (n *Node) Resume() {
+	if atomic.CompareAndSwapInt32(&n.paused, 1, 0) {
+		metrics.SetGauge("node.paused", 0)
+		n.logger.Info("Gossip resumed")
+	}
+}
+
+// Paused returns true between Pause and Resume
+func (n *Node) Paused() bool {
+	return atomic.LoadInt32(&n.paused) == 1
+}
+
+// DrainCommits waits until the application acknowledged all the decided
+// blocks, or timeout elapsed. It is meant to be called on a paused node,
+// which decides no new block.
+func (n *Node) DrainCommits(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		pending := n.pendingBlocks()
+		if pending == 0 {
+			return nil
+		}
+		if n.getState() == Shutdown || time.Now().After(deadline) {
+			return fmt.Errorf("%d blocks still pending", pending)
+		}
+		time.Sleep(drainPoll)
+	}
+}
+
+// pendingBlocks counts the blocks waiting in the commit channel and queue or
+// not yet acknowledged by the application
+func (n *Node) pendingBlocks() int64 {
+	pending := n.core.GetLastBlockIndex() - n.AckedBlock()
+	queued, _ := n.commits.depth()
+	if queued := int64(len(n.commitCh) + queued); queued > pending {
+		pending = queued
+	}
+	return pending
+}
+
+// CompactStore garbage collects the value log of the store of the node,
+// rewriting the files holding at least discardRatio of stale values
+func (n *Node) CompactStore(discardRatio float64) (poset.CompactionStats, error) {
+	stats, err := n.core.poset.CompactStore(discardRatio)
+	if err != nil {
+		return stats, err
+	}
+	n.logger.WithFields(logrus.Fields{
+		"rewritten":   stats.Rewritten,
+		"vlog_before": stats.VlogBefore,
+		"vlog_after":  stats.VlogAfter,
+		"duration":    stats.Duration,
+	}).Info("Store compacted")
+	return stats, nil
+}
+
+// GetMaintenance returns the maintenance state of the node
+func (n *Node) GetMaintenance() Maintenance {
+	return Maintenance{
+		Paused:        n.Paused(),
+		PendingBlocks: n.pendingBlocks(),
+		Settings:      n.GetSettings(),
+	}
+}
+
+// GetSettings returns the current values of the settings Reload changes
+func (n *Node) GetSettings() map[string]string {
+	return map[string]string{
+		SettingHeartbeat: n.heartbeatTimeout().String(),
+		SettingSyncLimit: strconv.FormatInt(atomic.LoadInt64(&n.syncLimit.Max), 10),
+		SettingCacheSize: strconv.Itoa(n.cacheSize()),
+		SettingLogLevel:  lachesis_log.GetLevel(n.conf.Logger).String(),
+	}
+}
+
+// cacheSize returns the maximum size of the caches
+func (n *Node) cacheSize() int {
+	if len(n.caches) == 0 {
+		return n.conf.CacheSize
+	}
+	return n.caches[0].MaxSize()
+}
+
+// Reload changes settings at runtime. All the values are validated before
+// any is applied. The caches shrink or grow at once to a new cache size.
+func (n *Node) Reload(settings map[string]string) error {
+	apply := make([]func(), 0, len(settings))
+	for name, value := range settings {
+		switch name {
+		case SettingHeartbeat:
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("%s must be a positive duration, got %q", name, value)
+			}
+			apply = append(apply, func() {
+				atomic.StoreInt64(&n.heartbeat, int64(d))
+			})
+		case SettingSyncLimit:
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || v <= 0 {
+				return fmt.Errorf("%s must be a positive integer, got %q", name, value)
+			}
+			apply = append(apply, func() {
+				n.syncLimit.SetMax(v)
+			})
+		case SettingCacheSize:
+			v, err := strconv.Atoi(value)
+			if err != nil || v <= 0 {
+				return fmt.Errorf("%s must be a positive integer, got %q", name, value)
+			}
+			apply = append(apply, func() {
+				for _, c := range n.caches {
+					c.SetMaxItems(v)
+				}
+			})
+		case SettingLogLevel:
+			level, err := logrus.ParseLevel(value)
+			if err != nil {
+				return fmt.Errorf("%s: %s", name, err)
+			}
+			apply = append(apply, func() {
+				lachesis_log.SetLevel(n.conf.Logger, level)
+			})
+		default:
+			return fmt.Errorf("%s cannot be reloaded", name)
+		}
+	}
+	for _, fn := range apply {
+		fn()
+	}
+	metrics.IncrCounter("node.reloads", 1)
+	n.logger.WithField("settings", settings).Info("Reloaded settings")
+	return nil
+}
@@ -0,0 +1,84 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+func TestMaintenance(t *testing.T) {
+	cores, _, _ := initCores(1, t)
+	n := &Node{
+		conf:      TestConfig(t),
+		logger:    common.NewTestLogger(t).WithField("this_id", 0),
+		core:      cores[0],
+		commitCh:  make(chan poset.Block, 10),
+		heartbeat: int64(time.Second),
+		txs:       newTxTracker(),
+	}
+	n.initMemoryBudget()
+
+	// an invalid value cancels the whole reload
+	err := n.Reload(map[string]string{
+		SettingHeartbeat: "50ms",
+		SettingSyncLimit: "-1",
+	})
+	if err == nil {
+		t.Fatal("a negative sync limit should be refused")
+	}
+	if n.heartbeatTimeout() != time.Second {
+		t.Fatalf("the heartbeat should not change, got %s", n.heartbeatTimeout())
+	}
+	if err := n.Reload(map[string]string{"unknown": "1"}); err == nil {
+		t.Fatal("unknown settings should be refused")
+	}
+
+	err = n.Reload(map[string]string{
+		SettingHeartbeat: "50ms",
+		SettingSyncLimit: "20",
+		SettingCacheSize: "50",
+		SettingLogLevel:  "warn",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		SettingHeartbeat: "50ms",
+		SettingSyncLimit: "20",
+		SettingCacheSize: "50",
+		SettingLogLevel:  "warning",
+	}
+	settings := n.GetSettings()
+	for name, value := range expected {
+		if settings[name] != value {
+			t.Errorf("%s should be %s, got %s", name, value, settings[name])
+		}
+	}
+	if n.syncLimit.Value() != 20 {
+		t.Fatalf("the sync limit should be 20, got %d", n.syncLimit.Value())
+	}
+	if n.conf.Logger.GetLevel() != logrus.WarnLevel {
+		t.Fatalf("the log level should be warn, got %s", n.conf.Logger.GetLevel())
+	}
+
+	n.Pause()
+	if !n.GetMaintenance().Paused {
+		t.Fatal("the node should be paused")
+	}
+	n.commitCh <- poset.Block{}
+	if err := n.DrainCommits(2 * drainPoll); err == nil {
+		t.Fatal("a queued block should keep the commits from draining")
+	}
+	<-n.commitCh
+	if err := n.DrainCommits(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	n.Resume()
+	if n.Paused() {
+		t.Fatal("the node should be resumed")
+	}
+}
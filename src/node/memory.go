@@ -0,0 +1,98 @@
+package node
+
+import (
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/memory"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/hashicorp/golang-lru"
+)
+
+// memoryCheckInterval is the time between two rebalances of the budget
+const memoryCheckInterval = 5 * time.Second
+
+// Shedding priorities: the caches go first, then the sync buffers. The
+// mempool is never shrunk; new transactions are refused instead.
+const (
+	memoryPriorityCaches  = 100
+	memoryPrioritySync    = 200
+	memoryPriorityMempool = 300
+)
+
+// eventSize estimates the weight of an event in a sync buffer, in bytes
+const eventSize = 1500
+
+// cacheItemSizes estimates the weight of an item of each cache, in bytes,
+// listed in shedding order: the ancestor caches are the cheapest to
+// recompute, the event cache the most expensive.
+var cacheItemSizes = []struct {
+	name     string
+	itemSize int64
+}{
+	{"poset.ancestor", 200},
+	{"poset.selfAncestor", 200},
+	{"poset.stronglySee", 200},
+	{"poset.timestamp", 150},
+	{"poset.round", 300},
+	{"store.rounds", 2000},
+	{"store.frames", 8000},
+	{"store.blocks", 4000},
+	{"store.events", eventSize},
+}
+
+// initMemoryBudget registers the caches, the sync limit and the mempool of
+// the node with a budget of conf.MemoryBudget bytes
+func (n *Node) initMemoryBudget() {
+	n.budget = memory.NewBudget(n.conf.MemoryBudget)
+
+	minItems := n.conf.CacheSize / 10
+	n.caches = nil
+	for i, c := range cacheItemSizes {
+		name := c.name
+		cache := func() *lru.Cache {
+			n.coreLock.Lock()
+			defer n.coreLock.Unlock()
+			return n.core.poset.Caches()[name]
+		}
+		component := memory.NewLRU(cache, c.itemSize, minItems, n.conf.CacheSize)
+		n.caches = append(n.caches, component)
+		n.budget.Register(name, memoryPriorityCaches+i, component)
+	}
+
+	minSync := n.conf.SyncLimit / 10
+	if minSync < 1 {
+		minSync = 1
+	}
+	n.syncLimit = memory.NewLimit(eventSize, minSync, n.conf.SyncLimit)
+	n.budget.Register("node.sync", memoryPrioritySync, n.syncLimit)
+
+	n.budget.Register("node.mempool", memoryPriorityMempool, memory.Gauge(func() int64 {
+		n.coreLock.Lock()
+		defer n.coreLock.Unlock()
+		return n.core.PoolBytes()
+	}))
+}
+
+// runMemoryBudget rebalances the budget until the node shuts down
+func (n *Node) runMemoryBudget() {
+	ticker := time.NewTicker(memoryCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			total := n.budget.Rebalance()
+			metrics.SetGauge("memory.total", float64(total))
+			if n.budget.Exceeded() {
+				n.logger.WithField("bytes", total).Warn("Memory budget exceeded")
+			}
+		case <-n.shutdownCh:
+			return
+		}
+	}
+}
+
+// GetMemoryUsage reports the estimated allocation of every component of the
+// memory budget
+func (n *Node) GetMemoryUsage() []memory.Usage {
+	return n.budget.Report()
+}
@@ -10,10 +10,17 @@ import (
 
 	"strconv"
 
+	"github.com/Fantom-foundation/go-lachesis/src/chaos"
+	"github.com/Fantom-foundation/go-lachesis/src/clock"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/log"
+	"github.com/Fantom-foundation/go-lachesis/src/memory"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
 	"github.com/Fantom-foundation/go-lachesis/src/net"
 	"github.com/Fantom-foundation/go-lachesis/src/peers"
 	"github.com/Fantom-foundation/go-lachesis/src/poset"
 	"github.com/Fantom-foundation/go-lachesis/src/proxy"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy/proto"
 )
 
 type Node struct {
@@ -29,26 +36,97 @@ type Node struct {
 	localAddr string
 
 	peerSelector PeerSelector
+	reputation   *Reputation
+	bans         *peers.BanList
+	// liveness is the failure detector of the peers
+	liveness *livenessTable
+	// conns are the outbound connections of the transport, see
+	// SetConnections
+	conns net.Connections
 	selectorLock sync.Mutex
 
+	budget    *memory.Budget
+	syncLimit *memory.Limit
+	// caches are the components of the budget managing the LRU caches
+	caches []*memory.LRU
+
+	clock *clock.Monitor
+
 	trans net.Transport
 	netCh <-chan net.RPC
 
 	proxy            proxy.AppProxy
 	submitCh         chan []byte
 	submitInternalCh chan poset.InternalTransaction
+	// submitExpiringCh is nil when the proxy does not implement
+	// proxy.ExpiringAppProxy
+	submitExpiringCh chan proto.ExpiringTx
+	// submitCheckedCh is nil when the proxy does not implement
+	// proxy.CheckedAppProxy
+	submitCheckedCh chan proto.CheckedTx
+	// submitTxCh receives the transactions of SubmitTx
+	submitTxCh chan proto.CheckedTx
+	txs        *txTracker
 
 	commitCh chan poset.Block
+	// commits holds the blocks of commitCh until the application committed
+	// them, see queueCommits
+	commits *commitQueue
+	// ackedBlock is the last block acknowledged by the application, see
+	// deliver, and redeliverCh receives the requests of Redeliver
+	ackedBlock  int64
+	redeliverCh chan int64
+
+	snapshots snapshotCache
+
+	// subscribers receive every committed block, see SubscribeCommits
+	subscribers     []chan<- poset.Block
+	subscribersLock sync.RWMutex
+
+	// plugins receive the consensus events, see RegisterPlugin
+	plugins     []*pluginRunner
+	pluginsLock sync.RWMutex
 
 	shutdownCh chan struct{}
+	// shutdownHooks run during Shutdown, see OnShutdown
+	shutdownHooks     []func()
+	shutdownHooksLock sync.Mutex
+	shutdownOnce      sync.Once
+	// shutdownErr is the first error of the flushes of Shutdown
+	shutdownErr error
+	// draining is set by Drain to refuse new transactions
+	draining int32
+	// paused is set by Pause to stop gossiping, see maintenance.go
+	paused int32
+
+	// heartbeat is the time between gossips in nanoseconds, see ParamHeartbeat
+	heartbeat int64
+	// adaptiveHeartbeat backs the heartbeat off while the node is idle, see
+	// nextHeartbeat
+	adaptiveHeartbeat adaptiveHeartbeat
+	// params is the governance state, persisted in the store, see
+	// processParamChanges
+	params     *poset.NetworkParams
+	paramsLock sync.Mutex
+
+	// backfilled is the index below which all blocks are stored, see
+	// runBackfill
+	backfilled int64
+
+	// candidates are the announced peers awaiting a probe, see runDiscovery
+	candidates candidates
+	// gossipTimes rate limits the gossips of a fan-out, see fanoutTargets
+	gossipTimes gossipTimes
 
 	controlTimer *ControlTimer
 
-	start        time.Time
-	syncRequests int
-	syncErrors   int
+	start time.Time
+	// syncs is the accounting of the syncs, see GetStats
+	syncs *syncAccounting
 
 	needBoostrap bool
+	// health is what GetReadiness and GetHealth report, see health.go
+	health       healthState
 	gossipJobs   count64
 	rpcJobs      count64
 }
@@ -62,43 +140,103 @@ func NewNode(conf *Config,
 	proxy proxy.AppProxy) *Node {
 
 	localAddr := trans.LocalAddr()
+	// the peers know the node by its advertised address
+	if conf.AdvertiseAddr != "" {
+		localAddr = conf.AdvertiseAddr
+	}
 
 	pmap, _ := store.Participants()
 
 	commitCh := make(chan poset.Block, 400)
 	core := NewCore(id, key, pmap, store, commitCh, conf.Logger)
+	core.observer = conf.Observer
+	core.SetEventLimits(conf.MaxEventTxs, conf.MaxEventBytes)
+	core.SetOrderingPolicy(conf.orderingPolicy())
 
 	pubKey := core.HexID()
 
-//	peerSelector := NewRandomPeerSelector(participants, localAddr)
-	peerSelector := NewSmartPeerSelector(participants, pubKey,
-		core.poset.GetFlagTableOfRandomUndeterminedEvent)
+	reputation := NewReputation()
+	bans := peers.NewBanList()
+	selectorCtx := PeerSelectorContext{
+		Participants: participants,
+		LocalID:      pubKey,
+		Reputation:   reputation,
+		Bans:         bans,
+		GetFlagTable: core.poset.GetFlagTableOfRandomUndeterminedEvent,
+	}
+	peerSelector, err := newPeerSelector(conf.peerSelector(), selectorCtx)
+	if err != nil {
+		conf.Logger.WithError(err).Error("Falling back to the smart peer selector")
+		peerSelector, _ = newPeerSelector(PeerSelectorSmart, selectorCtx)
+	}
+	liveness := newLivenessTable(conf.SuspectMisses, conf.DownMisses, conf.DownProbeInterval)
+	if ps, ok := peerSelector.(*SmartPeerSelector); ok {
+		ps.liveness = liveness
+	}
 
 	node := Node{
 		id:               id,
 		conf:             conf,
 		core:             core,
 		localAddr:        localAddr,
-		logger:           conf.Logger.WithField("this_id", id),
+		logger:           conf.Logger.WithField("this_id", id).WithField(lachesis_log.ModuleField, "node"),
 		peerSelector:     peerSelector,
+		reputation:       reputation,
+		liveness:         liveness,
+		bans:             bans,
 		trans:            trans,
 		netCh:            trans.Consumer(),
 		proxy:            proxy,
 		submitCh:         proxy.SubmitCh(),
 		submitInternalCh: proxy.SubmitInternalCh(),
 		commitCh:         commitCh,
+		commits:          newCommitQueue(conf.CommitBuffer),
+		ackedBlock:       -1,
+		redeliverCh:      make(chan int64),
 		shutdownCh:       make(chan struct{}),
 		controlTimer:     NewRandomControlTimer(),
+		clock:            clock.NewMonitor(conf.MaxClockDrift),
+		heartbeat:        int64(conf.HeartbeatTimeout),
+		params:           poset.NewNetworkParams(),
+		submitExpiringCh: submitExpiringCh(proxy),
+		submitCheckedCh:  submitCheckedCh(proxy),
+		submitTxCh:       make(chan proto.CheckedTx),
+		txs:              newTxTracker(),
+		syncs:            newSyncAccounting(),
+		candidates:       candidates{byKey: make(map[string]*peers.Peer)},
+		gossipTimes:      gossipTimes{last: make(map[string]time.Time)},
 		start:            time.Now(),
 		gossipJobs:       0,
 		rpcJobs:          0,
 	}
 
+	participants.OnPeerRemoved(peers.PriorityNode, func(peer *peers.Peer) error {
+		reputation.Forget(peer.PubKeyHex)
+		node.clock.Forget(peer.PubKeyHex)
+		node.forgetGossipTime(peer.PubKeyHex)
+		node.liveness.forget(peer.PubKeyHex)
+		node.syncs.forget(peer.PubKeyHex)
+		return nil
+	})
+	node.watchMembership(participants)
+	core.onSelfEvent = node.trackEvent
+	node.txs.latencies = newLatencyWindow(conf.LatencyWindow)
+
 	node.logger.WithField("peers", pmap).Debug("pmap")
 	node.logger.WithField("pubKey", pubKey).Debug("pubKey")
 
 	node.needBoostrap = store.NeedBoostrap()
 
+	if conf.TxIndex {
+		if txIndex, ok := store.(poset.TxIndexStore); ok {
+			txIndex.EnableTxIndex()
+		} else {
+			node.logger.Warn("The store does not index transactions, tx-index ignored")
+		}
+	}
+
+	node.initMemoryBudget()
+
 	// Initialize
 	node.setState(Gossiping)
 
@@ -112,6 +250,13 @@ func (n *Node) Init() error {
 	}
 	n.logger.WithField("peers", peerAddresses).Debug("Initialize Node")
 
+	n.core.poset.SetPruneDepth(n.conf.PruneDepth)
+	n.core.poset.SetCheckpointInterval(n.conf.CheckpointInterval)
+	n.core.poset.SetConsensusWorkers(n.conf.ConsensusWorkers)
+	n.core.poset.SetNetworkID(n.conf.NetworkID)
+	if err := n.advertise(); err != nil {
+		return err
+	}
 	if n.needBoostrap {
 		n.logger.Debug("Bootstrap")
 		if err := n.core.Bootstrap(); err != nil {
@@ -120,7 +265,31 @@ func (n *Node) Init() error {
 	}
 	n.Register()
 
-	return n.core.SetHeadAndSeq()
+	if err := n.loadParams(); err != nil {
+		return fmt.Errorf("loading the network parameters: %s", err)
+	}
+
+	if n.conf.DedupWindow > 0 {
+		if err := n.core.SetTxWindow(n.conf.DedupWindow); err != nil {
+			return fmt.Errorf("loading the transaction window: %s", err)
+		}
+	}
+
+	if err := n.initDelivery(); err != nil {
+		return err
+	}
+
+	if n.conf.PoolJournal != "" {
+		if err := n.core.OpenJournal(n.conf.PoolJournal); err != nil {
+			return fmt.Errorf("opening pool journal: %s", err)
+		}
+	}
+
+	if err := n.core.SetHeadAndSeq(); err != nil {
+		return err
+	}
+	n.health.setBootstrapped()
+	return nil
 }
 
 func (n *Node) RunAsync(gossip bool) {
@@ -135,9 +304,31 @@ func (n *Node) Run(gossip bool) {
 	go n.controlTimer.Run(n.conf.HeartbeatTimeout)
 
 	// Execute some background work regardless of the state of the node.
-	// Process SubmitTx and CommitBlock requests
+	// Process SubmitTx requests
 	go n.doBackgroundWork()
 
+	// Queue the decided blocks and deliver them to the application
+	go n.queueCommits()
+	go n.deliverCommits()
+
+	// Keep the caches and buffers within the memory budget
+	go n.runMemoryBudget()
+
+	// Watch the drift of the local clock
+	go n.runClockCheck()
+
+	// Fetch the blocks missing from the local history
+	go n.runBackfill()
+
+	// Drop the transactions which expired in the pool
+	go n.runTxExpiry()
+
+	// Probe the seeds and the announced peers
+	go n.runDiscovery()
+
+	// Watch the core lock and the store for the health
+	go n.runWatchdog()
+
 	// pause before gossiping test transactions to allow all nodes come up
 	time.Sleep(time.Duration(n.conf.TestDelay) * time.Second)
 
@@ -151,7 +342,14 @@ func (n *Node) Run(gossip bool) {
 		case Gossiping:
 			n.lachesis(gossip)
 		case CatchingUp:
-			n.fastForward()
+			if err := n.fastForward(); err != nil {
+				metrics.IncrCounter("node.fast_forward.errors", 1)
+				// retry, with the next peer, after a pause
+				select {
+				case <-time.After(fastForwardRetryDelay):
+				case <-n.shutdownCh:
+				}
+			}
 		case Shutdown:
 			return
 		}
@@ -159,38 +357,43 @@ func (n *Node) Run(gossip bool) {
 }
 
 func (n *Node) resetTimer() {
-	if !n.controlTimer.set {
-		ts := n.conf.HeartbeatTimeout
-		//Slow gossip if nothing interesting to say
-		if n.core.poset.PendingLoadedEvents == 0 &&
-			len(n.core.transactionPool) == 0 &&
-			len(n.core.blockSignaturePool) == 0 {
-			ts = time.Duration(time.Second)
-		}
-		n.controlTimer.resetCh <- ts
+	if !n.controlTimer.isSet() {
+		n.controlTimer.resetCh <- n.nextHeartbeat()
 	}
 }
 
 func (n *Node) doBackgroundWork() {
+	submitExpiringCh := n.submitExpiringCh
+	submitCheckedCh := n.submitCheckedCh
 	for {
 		select {
 		case t := <-n.submitCh:
 			n.logger.Debug("Adding Transactions to Transaction Pool")
-			n.addTransaction(t)
+			if err := n.addTransaction(t); err != nil {
+				n.logger.WithField("error", err).Warn("n.addTransaction(t)")
+			}
+			n.resetTimer()
+		case t, ok := <-submitCheckedCh:
+			if !ok {
+				submitCheckedCh = nil
+				continue
+			}
+			n.addCheckedTransaction(t)
+		case t := <-n.submitTxCh:
+			n.addCheckedTransaction(t)
+		case t, ok := <-submitExpiringCh:
+			if !ok {
+				submitExpiringCh = nil
+				continue
+			}
+			if err := n.addExpiringTransaction(t); err != nil {
+				n.logger.WithField("error", err).Warn("n.addExpiringTransaction(t)")
+			}
 			n.resetTimer()
 		case t := <-n.submitInternalCh:
 			n.logger.Debug("Adding Internal Transaction")
 			n.addInternalTransaction(t)
 			n.resetTimer()
-		case block := <-n.commitCh:
-			n.logger.WithFields(logrus.Fields{
-				"index":          block.Index(),
-				"round_received": block.RoundReceived(),
-				"transactions":   len(block.Transactions()),
-			}).Debug("Adding EventBlock")
-			if err := n.commit(block); err != nil {
-				n.logger.WithField("error", err).Error("Adding EventBlock")
-			}
 		case <-n.shutdownCh:
 			return
 		}
@@ -214,8 +417,27 @@ func (n *Node) lachesis(gossip bool) {
 				n.rpcJobs.decrement()
 			})
 		case <-n.controlTimer.tickCh:
-			if gossip && n.gossipJobs.get() < 1 {
+			if !gossip || n.Paused() {
+				// no gossip, see Pause
+			} else if n.gossipJobs.get() < 1 && n.conf.GossipFanout > 1 {
+				targets := n.fanoutTargets()
+				if len(targets) == 0 {
+					n.resetTimer()
+					continue
+				}
+				n.goFunc(func() {
+					n.gossipJobs.increment()
+					n.gossipFanout(targets, returnCh)
+					n.gossipJobs.decrement()
+				})
+				n.logger.WithField("peers", len(targets)).Debug("Gossip")
+			} else if n.gossipJobs.get() < 1 {
 				peer := n.peerSelector.Next()
+				if n.bans.IsPeerBanned(peer) {
+					n.logger.WithField("peer", peer.NetAddr).Debug("Skip gossip with banned peer")
+					n.resetTimer()
+					continue
+				}
 				n.goFunc(func() {
 					n.gossipJobs.increment()
 					n.gossip(peer.NetAddr, returnCh)
@@ -234,6 +456,42 @@ func (n *Node) lachesis(gossip bool) {
 }
 
 func (n *Node) processRPC(rpc net.RPC) {
+	if err := n.authenticate(rpc); err != nil {
+		n.logger.WithFields(logrus.Fields{
+			"identity": rpc.Identity,
+			"error":    err,
+		}).Debug("Refused unauthenticated RPC")
+		metrics.IncrCounter("node.rpc.unauthenticated", 1)
+		rpc.Respond(nil, err)
+		return
+	}
+	if id, ok := rpcFromID(rpc.Command); ok && n.bans.IsBanned(n.peerPubKey(id)) {
+		n.logger.WithField("from_id", id).Debug("Refused RPC from banned peer")
+		rpc.Respond(nil, fmt.Errorf("peer %d is banned", id))
+		return
+	}
+	if id, ok := rpcFromID(rpc.Command); ok && rpc.Identity != "" {
+		// the connection proved its key, which must be the one of FromID
+		if pubKey := n.peerPubKey(id); pubKey != "" && pubKey != rpc.Identity {
+			n.logger.WithFields(logrus.Fields{
+				"from_id":  id,
+				"identity": rpc.Identity,
+			}).Warn("Refused RPC impersonating another peer")
+			metrics.IncrCounter("node.rpc.impersonation", 1)
+			n.recordBehaviour(rpc.Identity, ProtocolViolation)
+			rpc.Respond(nil, fmt.Errorf("connection of %s cannot speak for peer %d", rpc.Identity, id))
+			return
+		}
+	}
+
+	if id, ok := rpcFromID(rpc.Command); ok {
+		pubKey := n.peerPubKey(id)
+		n.health.contact(pubKey)
+		if pubKey != "" {
+			n.recordLiveness(pubKey, true)
+		}
+	}
+
 	switch cmd := rpc.Command.(type) {
 	case *net.SyncRequest:
 		n.processSyncRequest(rpc, cmd)
@@ -241,12 +499,40 @@ func (n *Node) processRPC(rpc net.RPC) {
 		n.processEagerSyncRequest(rpc, cmd)
 	case *net.FastForwardRequest:
 		n.processFastForwardRequest(rpc, cmd)
+	case *net.SnapshotChunkRequest:
+		n.processSnapshotChunkRequest(rpc, cmd)
+	case *net.BlocksRequest:
+		n.processBlocksRequest(rpc, cmd)
+	case *net.PeersRequest:
+		n.processPeersRequest(rpc, cmd)
+	case *net.SignatureGossipRequest:
+		n.processSignatureGossipRequest(rpc, cmd)
 	default:
 		n.logger.WithField("cmd", rpc.Command).Error("Unexpected RPC command")
 		rpc.Respond(nil, fmt.Errorf("unexpected command"))
 	}
 }
 
+func rpcFromID(cmd interface{}) (int64, bool) {
+	switch cmd := cmd.(type) {
+	case *net.SyncRequest:
+		return cmd.FromID, true
+	case *net.EagerSyncRequest:
+		return cmd.FromID, true
+	case *net.FastForwardRequest:
+		return cmd.FromID, true
+	case *net.SnapshotChunkRequest:
+		return cmd.FromID, true
+	case *net.BlocksRequest:
+		return cmd.FromID, true
+	case *net.PeersRequest:
+		return cmd.FromID, true
+	case *net.SignatureGossipRequest:
+		return cmd.FromID, true
+	}
+	return 0, false
+}
+
 func (n *Node) processSyncRequest(rpc net.RPC, cmd *net.SyncRequest) {
 	n.logger.WithFields(logrus.Fields{
 		"from_id": cmd.FromID,
@@ -255,15 +541,16 @@ func (n *Node) processSyncRequest(rpc net.RPC, cmd *net.SyncRequest) {
 
 	resp := &net.SyncResponse{
 		FromID: n.id,
+		Time:   time.Now().UnixNano(),
 	}
 	var respErr error
 
 	// Check sync limit
 	n.coreLock.Lock()
-	overSyncLimit := n.core.OverSyncLimit(cmd.Known, n.conf.SyncLimit)
+	overSyncLimit := n.core.OverSyncLimit(cmd.Known, n.syncLimit.Value())
 	n.coreLock.Unlock()
-	if overSyncLimit {
-		n.logger.Debug("n.core.OverSyncLimit(cmd.Known, n.conf.SyncLimit)")
+	if overSyncLimit && !cmd.Chunked {
+		n.logger.Debug("n.core.OverSyncLimit(cmd.Known, n.syncLimit.Value())")
 		resp.SyncLimit = true
 	} else {
 		// Compute Diff
@@ -278,6 +565,11 @@ func (n *Node) processSyncRequest(rpc net.RPC, cmd *net.SyncRequest) {
 			respErr = err
 		}
 
+		// Only the first chunk of a diff over the limit is sent
+		if overSyncLimit {
+			eventDiff, resp.More = firstChunk(eventDiff, n.syncLimit.Value())
+		}
+
 		// Convert to WireEvents
 		wireEvents, err := n.core.ToWire(eventDiff)
 		if err != nil {
@@ -294,10 +586,19 @@ func (n *Node) processSyncRequest(rpc net.RPC, cmd *net.SyncRequest) {
 	n.coreLock.Unlock()
 	resp.Known = knownEvents
 
+	// Attach signed peer digest
+	peerExchange, err := n.core.PeerExchange()
+	if err != nil {
+		n.logger.WithField("error", err).Error("n.core.PeerExchange()")
+	} else {
+		resp.Peers = peerExchange
+	}
+
 	n.logger.WithFields(logrus.Fields{
 		"events":     len(resp.Events),
 		"known":      resp.Known,
 		"sync_limit": resp.SyncLimit,
+		"more":       resp.More,
 		"error":      respErr,
 	}).Debug("SyncRequest Received")
 
@@ -311,12 +612,19 @@ func (n *Node) processEagerSyncRequest(rpc net.RPC, cmd *net.EagerSyncRequest) {
 	}).Debug("processEagerSyncRequest(rpc net.RPC, cmd *net.EagerSyncRequest)")
 
 	success := true
-	n.coreLock.Lock()
-	err := n.sync(cmd.Events)
-	n.coreLock.Unlock()
-	if err != nil {
-		n.logger.WithField("error", err).Error("n.sync(cmd.Events)")
+	var err error
+	if n.Paused() {
+		// the pushed events are dropped, the peer sends them again
 		success = false
+	} else {
+		n.coreLock.Lock()
+		err = n.syncChunk(cmd.Events, cmd.More)
+		n.coreLock.Unlock()
+		if err != nil {
+			n.logger.WithField("error", err).Error("n.sync(cmd.Events)")
+			n.recordBehaviour(n.peerPubKey(cmd.FromID), InvalidEvent)
+			success = false
+		}
 	}
 
 	resp := &net.EagerSyncResponse{
@@ -346,14 +654,16 @@ func (n *Node) processFastForwardRequest(rpc net.RPC, cmd *net.FastForwardReques
 	} else {
 		resp.Block = block
 		resp.Frame = frame
+		resp.Params = n.networkParams()
 
-		// Get snapshot
-		snapshot, err := n.proxy.GetSnapshot(block.Index())
+		// Get snapshot, large ones are fetched in chunks
+		snapshot, err := n.snapshot(block.Index())
 		if err != nil {
-			n.logger.WithField("error", err).Error("n.proxy.GetSnapshot(block.Index())")
+			n.logger.WithField("error", err).Error("n.snapshot(block.Index())")
 			respErr = err
+		} else {
+			n.describeSnapshot(resp, snapshot)
 		}
-		resp.Snapshot = snapshot
 	}
 
 	n.logger.WithFields(logrus.Fields{
@@ -367,6 +677,7 @@ func (n *Node) processFastForwardRequest(rpc net.RPC, cmd *net.FastForwardReques
 // calling routine (usually the lachesis routine) when it is time to exit the
 // Gossiping state and return.
 func (n *Node) gossip(peerAddr string, parentReturnCh chan struct{}) error {
+	defer metrics.MeasureSince("node.gossip", time.Now())
 
 	// pull
 	syncLimit, otherKnownEvents, err := n.pull(peerAddr)
@@ -405,16 +716,30 @@ func (n *Node) pull(peerAddr string) (syncLimit bool, otherKnownEvents map[int64
 	// Send SyncRequest
 	start := time.Now()
 	resp, err := n.requestSync(peerAddr, knownEvents)
-	elapsed := time.Since(start)
+	end := time.Now()
+	elapsed := end.Sub(start)
+	metrics.MeasureSince("node.sync.request", start)
 	n.logger.WithField("Duration", elapsed.Nanoseconds()).Debug("n.requestSync(peerAddr, knownEvents)")
 	// FIXIT: should we catch io.EOF error here and how we process it?
 	//	if err == io.EOF {
 	//		return false, nil, nil
 	//	}
+	if lerrors.Is(err, lerrors.TooFar) {
+		// The peer no longer holds the events we miss, catch up instead
+		n.logger.WithField("error", err).Debug("n.requestSync(peerAddr, knownEvents)")
+		metrics.IncrCounter("node.sync.limit", 1)
+		return true, nil, nil
+	}
 	if err != nil {
 		n.logger.WithField("Error", err).Error("n.requestSync(peerAddr, knownEvents)")
+		metrics.IncrCounter("node.sync.errors", 1)
+		n.recordBehaviour(n.peerPubKeyByAddr(peerAddr), SyncFailure)
 		return false, nil, err
 	}
+	if resp.Time != 0 {
+		n.clock.AddPeerSample(n.peerPubKeyByAddr(peerAddr), time.Unix(0, resp.Time), start, end)
+	}
+	n.recordSync(peerAddr, elapsed, knownEvents, resp.Known)
 	n.logger.WithFields(logrus.Fields{
 		"from_id":     resp.FromID,
 		"sync_limit":  resp.SyncLimit,
@@ -423,19 +748,43 @@ func (n *Node) pull(peerAddr string) (syncLimit bool, otherKnownEvents map[int64
 		"knownEvents": knownEvents,
 	}).Debug("SyncResponse")
 
+	// Learn about new peers and updated addresses, probed before use
+	if resp.Peers != nil {
+		if err := n.core.VerifyPeerExchange(resp.FromID, resp.Peers); err != nil {
+			n.logger.WithField("error", err).Warn("n.core.VerifyPeerExchange(resp.FromID, resp.Peers)")
+			n.recordBehaviour(n.peerPubKey(resp.FromID), ProtocolViolation)
+		} else {
+			n.offerPeers(resp.Peers.Peers, n.peerPubKey(resp.FromID))
+		}
+	}
+
 	if resp.SyncLimit {
+		metrics.IncrCounter("node.sync.limit", 1)
 		return true, nil, nil
 	}
 
 	// Add Events to poset and create new Head if necessary
 	n.coreLock.Lock()
-	err = n.sync(resp.Events)
+	err = n.syncChunk(resp.Events, resp.More)
 	n.coreLock.Unlock()
 	if err != nil {
-		n.logger.WithField("error", err).Error("n.sync(resp.Events)")
+		n.logger.WithField("error", err).Error("n.syncChunk(resp.Events, resp.More)")
+		metrics.IncrCounter("node.sync.errors", 1)
+		n.recordBehaviour(n.peerPubKey(resp.FromID), InvalidEvent)
 		return false, nil, err
 	}
 
+	n.recordBehaviour(n.peerPubKey(resp.FromID), Responsive)
+	metrics.IncrCounter("node.sync.events", int64(len(resp.Events)))
+
+	if resp.More {
+		known, err := n.pullChunks(peerAddr)
+		if err != nil {
+			return false, nil, err
+		}
+		return false, known, nil
+	}
+
 	return false, resp.Known, nil
 }
 
@@ -443,10 +792,10 @@ func (n *Node) push(peerAddr string, knownEvents map[int64]int64) error {
 
 	// Check SyncLimit
 	n.coreLock.Lock()
-	overSyncLimit := n.core.OverSyncLimit(knownEvents, n.conf.SyncLimit)
+	overSyncLimit := n.core.OverSyncLimit(knownEvents, n.syncLimit.Value())
 	n.coreLock.Unlock()
-	if overSyncLimit {
-		n.logger.Debug("n.core.OverSyncLimit(knownEvents, n.conf.SyncLimit)")
+	if overSyncLimit && !n.conf.SyncChunked {
+		n.logger.Debug("n.core.OverSyncLimit(knownEvents, n.syncLimit.Value())")
 		return nil
 	}
 
@@ -470,6 +819,10 @@ func (n *Node) push(peerAddr string, knownEvents map[int64]int64) error {
 			return err
 		}
 
+		if overSyncLimit {
+			return n.pushChunks(peerAddr, wireEvents)
+		}
+
 		// Create and Send EagerSyncRequest
 		start = time.Now()
 		n.logger.WithField("wireEvents", wireEvents).Debug("Sending n.requestEagerSync.wireEvents")
@@ -478,6 +831,7 @@ func (n *Node) push(peerAddr string, knownEvents map[int64]int64) error {
 		n.logger.WithField("Duration", elapsed.Nanoseconds()).Debug("n.requestEagerSync(peerAddr, wireEvents)")
 		if err != nil {
 			n.logger.WithField("Error", err).Error("n.requestEagerSync(peerAddr, wireEvents)")
+			n.recordBehaviour(n.peerPubKeyByAddr(peerAddr), SyncFailure)
 			return err
 		}
 		n.logger.WithFields(logrus.Fields{
@@ -497,6 +851,9 @@ func (n *Node) fastForward() error {
 
 	// fastForwardRequest
 	peer := n.peerSelector.Next()
+	if n.bans.IsPeerBanned(peer) {
+		return fmt.Errorf("peer %s is banned", peer.NetAddr)
+	}
 	start := time.Now()
 	resp, err := n.requestFastForward(peer.NetAddr)
 	elapsed := time.Since(start)
@@ -511,22 +868,20 @@ func (n *Node) fastForward() error {
 		"block_round_received": resp.Block.RoundReceived(),
 		"frame_events":         len(resp.Frame.Events),
 		"frame_roots":          resp.Frame.Roots,
-		"snapshot":             resp.Snapshot,
+		"snapshot_chunks":      len(resp.SnapshotChunks),
 	}).Debug("FastForwardResponse")
 
-	// prepare core. ie: fresh poset
-	n.coreLock.Lock()
-	err = n.core.FastForward(peer.PubKeyHex, resp.Block, resp.Frame)
-	n.coreLock.Unlock()
+	// fetch the application state before touching the poset, so that a
+	// failure leaves the node as it was
+	snapshot, err := n.fetchSnapshot(peer, &resp)
 	if err != nil {
-		n.logger.WithField("Error", err).Error("n.core.FastForward(peer.PubKeyHex, resp.Block, resp.Frame)")
+		n.logger.WithField("Error", err).Error("n.fetchSnapshot(peer, &resp)")
 		return err
 	}
 
-	// update app from snapshot
-	err = n.proxy.Restore(resp.Snapshot)
+	err = n.restartFromSnapshot(resp.Block, resp.Frame, snapshot, resp.Params)
 	if err != nil {
-		n.logger.WithField("Error", err).Error("n.proxy.Restore(resp.Snapshot)")
+		n.logger.WithField("Error", err).Error("n.RestartFromSnapshot(resp.Block, resp.Frame, snapshot)")
 		return err
 	}
 
@@ -538,12 +893,15 @@ func (n *Node) fastForward() error {
 func (n *Node) requestSync(target string, known map[int64]int64) (net.SyncResponse, error) {
 
 	args := net.SyncRequest{
-		FromID: n.id,
-		Known:  known,
+		FromID:  n.id,
+		Known:   known,
+		Chunked: n.conf.SyncChunked,
 	}
 
 	var out net.SyncResponse
+	start := time.Now()
 	err := n.trans.Sync(target, &args, &out)
+	n.recordSyncRequest(target, time.Since(start), len(out.Events), err)
 	//n.logger.WithField("out", out).Debug("requestSync(target string, known map[int]int)")
 	return out, err
 }
@@ -559,6 +917,9 @@ func (n *Node) requestEagerSync(target string, events []poset.WireEvent) (net.Ea
 		"target": target,
 	}).Debug("requestEagerSync(target string, events []poset.WireEvent)")
 	err := n.trans.EagerSync(target, &args, &out)
+	if err == nil {
+		n.recordEventsPushed(target, len(events))
+	}
 
 	return out, err
 }
@@ -579,9 +940,41 @@ func (n *Node) requestFastForward(target string) (net.FastForwardResponse, error
 }
 
 func (n *Node) sync(events []poset.WireEvent) error {
+	return n.syncChunk(events, false)
+}
+
+// syncChunk inserts the events of a chunk of a sync, see Config.SyncChunked,
+// more being set when other chunks follow
+func (n *Node) syncChunk(events []poset.WireEvent, more bool) error {
+	if n.conf.CheckSyncedTxs {
+		if err := n.checkEventTxs(events); err != nil {
+			return err
+		}
+	}
+	// the writes of the sync and of its consensus go to the store in one
+	// transaction
+	n.core.BeginBatch()
+	err := n.syncEvents(events, more)
+	if flushErr := n.core.EndBatch(); flushErr != nil {
+		n.logger.WithField("error", flushErr).Error("n.core.EndBatch()")
+		n.health.setStoreError(flushErr)
+		if err == nil {
+			err = flushErr
+		}
+	}
+	n.recordStoreError(err)
+	return err
+}
+
+func (n *Node) syncEvents(events []poset.WireEvent, more bool) error {
 	// Insert Events in Poset and create new Head if necessary
 	start := time.Now()
-	err := n.core.Sync(events)
+	var err error
+	if more {
+		err = n.core.SyncChunk(events)
+	} else {
+		err = n.core.Sync(events)
+	}
 	elapsed := time.Since(start)
 	n.logger.WithField("Duration", elapsed.Nanoseconds()).Debug("n.core.Sync(events)")
 	if err != nil {
@@ -589,6 +982,11 @@ func (n *Node) sync(events []poset.WireEvent) error {
 	}
 
 	// Run consensus methods
+	prevRound := n.core.GetLastConsensusRoundIndex()
+	if prevRound != nil {
+		r := *prevRound
+		prevRound = &r
+	}
 	start = time.Now()
 	err = n.core.RunConsensus()
 	elapsed = time.Since(start)
@@ -596,67 +994,154 @@ func (n *Node) sync(events []poset.WireEvent) error {
 	if err != nil {
 		return err
 	}
+	n.processDecidedRounds(prevRound)
 
 	return nil
 }
 
-func (n *Node) commit(block poset.Block) error {
+// processDecidedRounds applies the parameter changes of the decided rounds
+// and notifies the plugins of the rounds decided after prev
+func (n *Node) processDecidedRounds(prev *int64) {
+	last := n.core.GetLastConsensusRoundIndex()
+	if last == nil {
+		return
+	}
+	from := int64(0)
+	if prev != nil {
+		from = *prev + 1
+	}
+	if *last >= from {
+		n.health.roundDecided(*last)
+	}
+	n.processParamChanges(*last)
+	for round := from; round <= *last; round++ {
+		round := round
+		n.notifyPlugins("round", func(p Plugin) error { return p.OnRoundDecided(round) })
+	}
+}
 
-	stateHash := []byte{0, 1, 2}
-	_, err := n.proxy.CommitBlock(block)
+func (n *Node) commit(block poset.Block) error {
+	chaos.Point(chaos.PointCommit)
+	// a block the application failed to commit is delivered again with the
+	// next one
+	stateHash, err := n.deliver(block)
+	metrics.IncrCounter("node.blocks.committed", 1)
+	metrics.IncrCounter("node.transactions.committed", int64(len(block.Transactions())))
 	if err != nil {
-		n.logger.WithError(err).Debug("commit(block poset.Block)")
+		n.logger.WithError(err).Warn("commit(block poset.Block)")
+	}
+	n.trackCommitted(block.Index(), block.Transactions())
+	n.coreLock.Lock()
+	if err := n.core.SaveTxWindow(); err != nil {
+		n.logger.WithError(err).Warn("Saving the transaction window")
 	}
+	n.coreLock.Unlock()
+	n.publishCommit(block)
+	n.notifyPlugins("block", func(p Plugin) error { return p.OnBlockCommit(block) })
 
 	n.logger.WithFields(logrus.Fields{
 		"block":      block.Index(),
 		"state_hash": fmt.Sprintf("%X", stateHash),
-		// "err":        err,
 	}).Debug("commit(eventBlock poset.EventBlock)")
 
-	// XXX what do we do in case of error. Retry? This has to do with the
-	// Lachesis <-> App interface. Think about it.
+	// Observers don't sign blocks
+	if n.core.IsObserver() {
+		return nil
+	}
 
-	// An error here could be that the endpoint is not configured, not all
-	// nodes will be sending blocks to clients, in these cases -no_client can be
-	// used, alternatively should check for the error here and handle it
-	// appropriately
+	// The signatures do not cover the state hash of the application, which
+	// the nodes fast-forwarding to the block check the restored application
+	// against. A block the application failed to commit has none.
+	block.StateHash = stateHash
+	n.coreLock.Lock()
+	defer n.coreLock.Unlock()
+	sig, err := n.core.SignBlock(block)
+	if err != nil {
+		return err
+	}
+	n.core.AddBlockSignature(sig)
+	if n.conf.SignatureGossip {
+		n.goFunc(func() { n.gossipSignature(sig) })
+	}
 
-	// There is no point in using the stateHash if we know it is wrong
-	// if err == nil {
-	if true {
-		// inmem statehash would be different than proxy statehash
-		// inmem is simply the hash of transactions
-		// this requires a 1:1 relationship with nodes and clients
-		// multiple nodes can't read from the same client
+	return nil
+}
 
-		block.StateHash = stateHash
-		n.coreLock.Lock()
-		defer n.coreLock.Unlock()
-		sig, err := n.core.SignBlock(block)
-		if err != nil {
-			return err
-		}
-		n.core.AddBlockSignature(sig)
+// submitCheckedCh returns the channel of the transactions whose submitter
+// waits for their acceptance, nil when the proxy does not support them
+func submitCheckedCh(p proxy.AppProxy) chan proto.CheckedTx {
+	if p, ok := p.(proxy.CheckedAppProxy); ok {
+		return p.SubmitCheckedCh()
 	}
-
 	return nil
 }
 
-func (n *Node) addTransaction(tx []byte) {
+// addCheckedTransaction adds a transaction to the pool and reports the
+// outcome to its submitter
+func (n *Node) addCheckedTransaction(tx proto.CheckedTx) {
+	err := n.addTransaction(tx.Data)
+	tx.Result <- err
+	if err != nil {
+		n.logger.WithField("error", err).Debug("n.addTransaction(tx)")
+	}
+	n.resetTimer()
+}
+
+// addTransaction adds a transaction to the pool, unless the node is
+// draining, the transaction is too large or invalid, or the pool is full
+func (n *Node) addTransaction(tx []byte) error {
+	if n.core.IsObserver() {
+		return fmt.Errorf("observers do not accept transactions")
+	}
+	if n.Draining() {
+		metrics.IncrCounter("node.transactions.dropped", 1)
+		return lerrors.New(lerrors.MempoolFull, "node is shutting down, dropping transaction")
+	}
+	if n.budget.Exceeded() {
+		metrics.IncrCounter("node.transactions.dropped", 1)
+		return lerrors.New(lerrors.MempoolFull, "memory budget exceeded, dropping transaction")
+	}
+	if n.conf.MaxTxSize > 0 && len(tx) > n.conf.MaxTxSize {
+		metrics.IncrCounter("node.transactions.dropped", 1)
+		return lerrors.New(lerrors.TxTooLarge, "transaction of %d bytes exceeds max-tx-size %d", len(tx), n.conf.MaxTxSize)
+	}
+	if err := n.checkTx(tx); err != nil {
+		metrics.IncrCounter("node.transactions.invalid", 1)
+		return err
+	}
 	n.coreLock.Lock()
-	defer n.coreLock.Unlock()
+	if n.core.DuplicateTransaction(tx) {
+		n.coreLock.Unlock()
+		metrics.IncrCounter("node.transactions.duplicate", 1)
+		return nil
+	}
+	if n.conf.MaxPoolBytes > 0 && n.core.PoolBytes()+int64(len(tx)) > n.conf.MaxPoolBytes {
+		n.coreLock.Unlock()
+		metrics.IncrCounter("node.transactions.dropped", 1)
+		return lerrors.New(lerrors.MempoolFull, "transaction pool exceeds max-pool-bytes %d, dropping transaction", n.conf.MaxPoolBytes)
+	}
 	n.core.AddTransactions([][]byte{tx})
+	n.coreLock.Unlock()
+	hash := TxHash(tx)
+	n.txs.statuses.Add(hash, TxPending)
+	n.trackSubmitted(hash, time.Now())
+	return nil
 }
 
 func (n *Node) addInternalTransaction(tx poset.InternalTransaction) {
+	if n.core.IsObserver() {
+		n.logger.Warn("Observers do not accept internal transactions")
+		return
+	}
 	n.coreLock.Lock()
 	defer n.coreLock.Unlock()
 	n.core.AddInternalTransactions([]poset.InternalTransaction{tx})
 }
 
+// Shutdown stops the node, flushes its store and pool journal and closes its
+// transport. Concurrent calls return once the first one completed.
 func (n *Node) Shutdown() {
-	if n.getState() != Shutdown {
+	n.shutdownOnce.Do(func() {
 		// n.mqtt.FireEvent("Shutdown()", "/mq/lachesis/node")
 		n.logger.Debug("Shutdown()")
 
@@ -667,15 +1152,42 @@ func (n *Node) Shutdown() {
 		close(n.shutdownCh)
 		n.waitRoutines()
 
+		if err := n.core.SaveTxWindow(); err != nil {
+			n.logger.WithError(err).Error("Saving the transaction window")
+		}
+
 		// For some reason this needs to be called after closing the shutdownCh
 		// Not entirely sure why...
 		n.controlTimer.Shutdown()
 
 		// transport and store should only be closed once all concurrent operations
-		// are finished otherwise they will panic trying to use close objects
+		// are finished otherwise they will panic trying to use close objects.
+		// Closing the store flushes it, then the services reading the node
+		// stop before the transport closes.
+		if err := n.core.poset.Store.Close(); err != nil {
+			n.logger.WithError(err).Error("Flushing store")
+			n.shutdownErr = fmt.Errorf("flushing store: %s", err)
+		}
+		if err := n.core.CloseJournal(); err != nil {
+			n.logger.WithError(err).Error("Closing pool journal")
+			if n.shutdownErr == nil {
+				n.shutdownErr = fmt.Errorf("closing pool journal: %s", err)
+			}
+		}
+		n.runShutdownHooks()
 		n.trans.Close()
-		n.core.poset.Store.Close()
-	}
+	})
+}
+
+// ShutdownError returns the first error of the flushes of Shutdown, nil after
+// a clean shutdown. It must be called once Shutdown returned.
+func (n *Node) ShutdownError() error {
+	return n.shutdownErr
+}
+
+// GetState returns the state of the node
+func (n *Node) GetState() NodeState {
+	return n.getState()
 }
 
 func (n *Node) GetStats() map[string]string {
@@ -699,10 +1211,15 @@ func (n *Node) GetStats() map[string]string {
 		consensusRoundsPerSecond = float64(*lastConsensusRound) / timeElapsed.Seconds()
 	}
 
+	// the pool is appended to by the RPC handlers under the core lock
+	n.coreLock.Lock()
+	transactionPool := len(n.core.transactionPool)
+	n.coreLock.Unlock()
+
 	s := map[string]string{
 		"last_consensus_round":    toString(lastConsensusRound),
 		"time_elapsed":            strconv.FormatFloat(timeElapsed.Seconds(), 'f', 2, 64),
-		"heartbeat":               strconv.FormatFloat(n.conf.HeartbeatTimeout.Seconds(), 'f', 2, 64),
+		"heartbeat":               strconv.FormatFloat(n.heartbeatTimeout().Seconds(), 'f', 2, 64),
 		"node_current":            strconv.FormatInt(time.Now().Unix(), 10),
 		"node_start":              strconv.FormatInt(n.start.Unix(), 10),
 		"last_block_index":        strconv.FormatInt(n.core.GetLastBlockIndex(), 10),
@@ -710,7 +1227,7 @@ func (n *Node) GetStats() map[string]string {
 		"sync_limit":              strconv.FormatInt(n.conf.SyncLimit, 10),
 		"consensus_transactions":  strconv.FormatUint(consensusTransactions, 10),
 		"undetermined_events":     strconv.Itoa(len(n.core.GetUndeterminedEvents())),
-		"transaction_pool":        strconv.Itoa(len(n.core.transactionPool)),
+		"transaction_pool":        strconv.Itoa(transactionPool),
 		"num_peers":               strconv.Itoa(n.peerSelector.Peers().Len()),
 		"sync_rate":               strconv.FormatFloat(n.SyncRate(), 'f', 2, 64),
 		"transactions_per_second": strconv.FormatFloat(transactionsPerSecond, 'f', 2, 64),
@@ -720,6 +1237,31 @@ func (n *Node) GetStats() map[string]string {
 		"id":                      strconv.FormatInt(n.id, 10),
 		"state":                   n.getState().String(),
 	}
+	if offset, ok := n.clock.Offset(); ok {
+		s["clock_offset"] = offset.String()
+	}
+	for k, v := range n.latencyStats() {
+		s[k] = v
+	}
+	for k, v := range n.livenessStats() {
+		s[k] = v
+	}
+	for k, v := range n.commitQueueStats() {
+		s[k] = v
+	}
+	for k, v := range n.syncStats() {
+		s[k] = v
+	}
+	if n.Paused() {
+		s["paused"] = "true"
+	}
+	n.selectorLock.Lock()
+	if ps, ok := n.peerSelector.(*SmartPeerSelector); ok {
+		for k, v := range ps.Stats() {
+			s[k] = v
+		}
+	}
+	n.selectorLock.Unlock()
 	// n.mqtt.FireEvent(s, "/mq/lachesis/stats")
 	return s
 }
@@ -749,12 +1291,71 @@ func (n *Node) logStats() {
 	}).Warn("logStats()")
 }
 
+// SyncRate returns the share of the SyncRequests which succeeded, 1 before
+// the first one
 func (n *Node) SyncRate() float64 {
-	var syncErrorRate float64
-	if n.syncRequests != 0 {
-		syncErrorRate = float64(n.syncErrors) / float64(n.syncRequests)
+	return n.syncs.rate()
+}
+
+// GetPeerScores returns the reputation score of every known peer
+func (n *Node) GetPeerScores() map[string]int64 {
+	scores := n.reputation.Scores()
+	for _, p := range n.peerSelector.Peers().ToPeerSlice() {
+		if _, ok := scores[p.PubKeyHex]; !ok {
+			scores[p.PubKeyHex] = 0
+		}
 	}
-	return 1 - syncErrorRate
+	return scores
+}
+
+// Ban bans a public key or an address for the given duration
+func (n *Node) Ban(key string, duration time.Duration, reason string) peers.Ban {
+	return n.bans.Ban(key, duration, reason)
+}
+
+// Unban lifts the ban on a public key or an address
+func (n *Node) Unban(key string) bool {
+	return n.bans.Unban(key)
+}
+
+// GetBans returns the active bans
+func (n *Node) GetBans() []peers.Ban {
+	return n.bans.ToSlice()
+}
+
+// BanList returns the ban list of the node, to be enforced by the transport
+func (n *Node) BanList() *peers.BanList {
+	return n.bans
+}
+
+// SetConnections gives the node the outbound connections of its transport,
+// to report and reset their failures
+func (n *Node) SetConnections(conns net.Connections) {
+	n.conns = conns
+}
+
+// GetConnStates returns the state of the outbound connections by address,
+// none when the transport does not track them
+func (n *Node) GetConnStates() []net.ConnState {
+	if n.conns == nil {
+		return []net.ConnState{}
+	}
+	return n.conns.ConnStates()
+}
+
+// ResetConn forgets the connection failures of an address and lifts its
+// ban, it returns false when there was neither
+func (n *Node) ResetConn(target string) bool {
+	if n.conns == nil {
+		return n.bans.Unban(target)
+	}
+	return n.conns.ResetConn(target)
+}
+
+// GetRemovalCandidates returns the public keys of the peers whose reputation
+// is low enough to propose their removal
+func (n *Node) GetRemovalCandidates() []string {
+	return n.reputation.RemovalCandidates()
 }
 
 func (n *Node) GetParticipants() (*peers.Peers, error) {
@@ -798,6 +1399,12 @@ func (n *Node) GetLastRound() int64 {
 	return n.core.poset.Store.LastRound()
 }
 
+// GetLastConsensusRound returns the last round whose fame is decided, nil
+// before the first one
+func (n *Node) GetLastConsensusRound() *int64 {
+	return n.core.GetLastConsensusRoundIndex()
+}
+
 func (n *Node) GetRoundWitnesses(roundIndex int64) []string {
 	return n.core.poset.Store.RoundWitnesses(roundIndex)
 }
@@ -810,10 +1417,145 @@ func (n *Node) GetRoot(rootIndex string) (poset.Root, error) {
 	return n.core.poset.Store.GetRoot(rootIndex)
 }
 
+// SubscribeCommits sends every block committed from now on to ch. Blocks are
+// dropped when ch is full, so that subscribers never hold up consensus; they
+// can recover the missing ones with GetBlock.
+func (n *Node) SubscribeCommits(ch chan<- poset.Block) {
+	n.subscribersLock.Lock()
+	defer n.subscribersLock.Unlock()
+	n.subscribers = append(n.subscribers, ch)
+}
+
+// UnsubscribeCommits stops sending blocks to a channel of SubscribeCommits
+func (n *Node) UnsubscribeCommits(ch chan<- poset.Block) {
+	n.subscribersLock.Lock()
+	defer n.subscribersLock.Unlock()
+	for i, sub := range n.subscribers {
+		if sub == ch {
+			n.subscribers = append(n.subscribers[:i], n.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (n *Node) publishCommit(block poset.Block) {
+	n.subscribersLock.RLock()
+	defer n.subscribersLock.RUnlock()
+	for _, ch := range n.subscribers {
+		select {
+		case ch <- block:
+		default:
+			metrics.IncrCounter("node.subscribers.dropped", 1)
+		}
+	}
+}
+
 func (n *Node) GetBlock(blockIndex int64) (poset.Block, error) {
 	return n.core.poset.Store.GetBlock(blockIndex)
 }
 
+// GetCheckpoint returns the checkpoint of the given index
+func (n *Node) GetCheckpoint(index int64) (poset.Checkpoint, error) {
+	return n.core.poset.Store.GetCheckpoint(index)
+}
+
+// GetDAG returns the event DAG of the store from round fromRound
+func (n *Node) GetDAG(fromRound int64) (*poset.DAG, error) {
+	n.coreLock.Lock()
+	defer n.coreLock.Unlock()
+	return poset.BuildDAG(n.core.poset.Store, fromRound)
+}
+
+// GetLastCheckpointIndex returns the index of the last checkpoint, -1 when
+// there is none
+func (n *Node) GetLastCheckpointIndex() int64 {
+	return n.core.poset.Store.LastCheckpointIndex()
+}
+
 func (n *Node) ID() int64 {
 	return n.id
 }
+
+func (n *Node) peerPubKey(id int64) string {
+	participants := n.peerSelector.Peers()
+	participants.RLock()
+	defer participants.RUnlock()
+	if p, ok := participants.ById[id]; ok {
+		return p.PubKeyHex
+	}
+	return ""
+}
+
+func (n *Node) peerPubKeyByAddr(addr string) string {
+	participants := n.peerSelector.Peers()
+	participants.RLock()
+	defer participants.RUnlock()
+	for _, p := range participants.Sorted {
+		if p.NetAddr == addr {
+			return p.PubKeyHex
+		}
+	}
+	return ""
+}
+
+// recordSync passes the round-trip time of a SyncRequest to peerAddr and the
+// KnownEvents exchanged to the peer selector, when it weighs the peers
+func (n *Node) recordSync(peerAddr string, rtt time.Duration, ours, theirs map[int64]int64) {
+	if theirs == nil {
+		return
+	}
+	pubKey := n.peerPubKeyByAddr(peerAddr)
+	n.selectorLock.Lock()
+	defer n.selectorLock.Unlock()
+	if ps, ok := n.peerSelector.(*SmartPeerSelector); ok {
+		ps.RecordSync(pubKey, rtt, ours, theirs)
+	}
+}
+
+func (n *Node) recordBehaviour(pubKey string, b Behaviour) {
+	if pubKey == "" {
+		return
+	}
+	switch b {
+	case Responsive:
+		n.health.contact(pubKey)
+		n.recordLiveness(pubKey, true)
+	case SyncFailure:
+		n.recordLiveness(pubKey, false)
+	}
+	prev := n.reputation.Score(pubKey)
+	score := n.reputation.Record(pubKey, b)
+	if b == ProtocolViolation && n.reputation.Violations(pubKey) >= maxProtocolViolations {
+		n.reputation.ResetViolations(pubKey)
+		n.banPeer(pubKey, fmt.Sprintf("%d protocol violations", maxProtocolViolations))
+	}
+	if score < RemovalScore && prev >= RemovalScore {
+		n.logger.WithFields(logrus.Fields{
+			"peer":      pubKey,
+			"score":     score,
+			"behaviour": b,
+		}).Warn("Peer reputation fell under removal threshold")
+	}
+}
+
+// banPeer bans both the public key and the host of the address of a peer
+func (n *Node) banPeer(pubKey string, reason string) {
+	if n.conf.BanDuration <= 0 {
+		return
+	}
+	n.bans.Ban(pubKey, n.conf.BanDuration, reason)
+
+	participants := n.peerSelector.Peers()
+	participants.RLock()
+	peer, ok := participants.ByPubKey[pubKey]
+	participants.RUnlock()
+	if ok && peer.NetAddr != "" {
+		n.bans.BanHost(peer.NetAddr, n.conf.BanDuration, reason)
+	}
+
+	n.logger.WithFields(logrus.Fields{
+		"peer":     pubKey,
+		"duration": n.conf.BanDuration,
+		"reason":   reason,
+	}).Warn("Peer banned")
+}
@@ -7,6 +7,8 @@ import (
 	"math/rand"
 	"os"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -52,6 +54,7 @@ func TestProcessSync(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 	defer peer0Trans.Close()
+	peer0Trans.Listen()
 
 	node0 := NewNode(config, ps[0].ID, keys[0], p,
 		poset.NewInmemStore(p, config.CacheSize),
@@ -68,6 +71,7 @@ func TestProcessSync(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 	defer peer1Trans.Close()
+	peer1Trans.Listen()
 
 	node1 := NewNode(config, ps[1].ID, keys[1], p,
 		poset.NewInmemStore(p, config.CacheSize),
@@ -153,6 +157,7 @@ func TestProcessEagerSync(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 	defer peer0Trans.Close()
+	peer0Trans.Listen()
 
 	node0 := NewNode(config, ps[0].ID, keys[0], p,
 		poset.NewInmemStore(p, config.CacheSize),
@@ -169,6 +174,7 @@ func TestProcessEagerSync(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 	defer peer1Trans.Close()
+	peer1Trans.Listen()
 
 	node1 := NewNode(config, ps[1].ID, keys[1], p,
 		poset.NewInmemStore(p, config.CacheSize),
@@ -233,6 +239,7 @@ func TestAddTransaction(t *testing.T) {
 	}
 	peer0Proxy := dummy.NewInmemDummyApp(testLogger)
 	defer peer0Trans.Close()
+	peer0Trans.Listen()
 
 	node0 := NewNode(TestConfig(t), ps[0].ID, keys[0], p,
 		poset.NewInmemStore(p, config.CacheSize),
@@ -250,6 +257,7 @@ func TestAddTransaction(t *testing.T) {
 	}
 	peer1Proxy := dummy.NewInmemDummyApp(testLogger)
 	defer peer1Trans.Close()
+	peer1Trans.Listen()
 
 	node1 := NewNode(TestConfig(t), ps[1].ID, keys[1], p,
 		poset.NewInmemStore(p, config.CacheSize),
@@ -329,6 +337,7 @@ func initNodes(keys []*ecdsa.PrivateKey,
 		if err != nil {
 			t.Fatalf("failed to create transport for peer %d: %s", id, err)
 		}
+		trans.Listen()
 
 		peer.NetAddr = trans.LocalAddr()
 
@@ -395,6 +404,7 @@ func recycleNode(oldNode *Node, logger *logrus.Logger, t *testing.T) *Node {
 	if err != nil {
 		t.Fatal(err)
 	}
+	trans.Listen()
 	prox := dummy.NewInmemDummyApp(logger)
 
 	newNode := NewNode(conf, id, key, ps, store, trans, prox)
@@ -438,6 +448,91 @@ func TestGossip(t *testing.T) {
 	checkGossip(nodes, 0, t)
 }
 
+func TestGossipConsensusWorkers(t *testing.T) {
+	logger := common.NewTestLogger(t)
+
+	keys, ps := initPeers(4)
+	nodes := initNodes(keys, ps, 1000, 1000, "inmem", logger, t)
+	for _, n := range nodes {
+		n.core.poset.SetConsensusWorkers(4)
+	}
+
+	if err := gossip(nodes, 50, true, 3*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	checkGossip(nodes, 0, t)
+}
+
+func TestGossipFanout(t *testing.T) {
+	logger := common.NewTestLogger(t)
+
+	keys, ps := initPeers(4)
+	nodes := initNodes(keys, ps, 1000, 1000, "inmem", logger, t)
+	for _, n := range nodes {
+		n.conf.GossipFanout = 2
+	}
+
+	if err := gossip(nodes, 50, true, 3*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	checkGossip(nodes, 0, t)
+}
+
+// TestGossipStats polls the stats while the nodes gossip and run the
+// consensus, for the race detector
+func TestGossipStats(t *testing.T) {
+	logger := common.NewTestLogger(t)
+
+	keys, ps := initPeers(4)
+	nodes := initNodes(keys, ps, 1000, 1000, "inmem", logger, t)
+
+	quit := make(chan struct{})
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		for {
+			select {
+			case <-quit:
+				return
+			default:
+			}
+			for _, n := range nodes {
+				n.GetStats()
+				n.GetPendingLoadedEvents()
+				n.GetLastConsensusRound()
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	err := gossip(nodes, 20, true, 3*time.Second)
+	close(quit)
+	<-polled
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkGossip(nodes, 0, t)
+}
+
+func TestGossipWeighted(t *testing.T) {
+	logger := common.NewTestLogger(t)
+
+	keys, ps := initPeers(4)
+	for i, p := range ps.ToPeerSlice() {
+		p.Stake = uint64(10 * (i + 1))
+	}
+	nodes := initNodes(keys, ps, 1000, 1000, "inmem", logger, t)
+
+	if err := gossip(nodes, 20, true, 3*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	checkGossip(nodes, 0, t)
+}
+
 func TestMissingNodeGossip(t *testing.T) {
 
 	logger := common.NewTestLogger(t)
@@ -497,6 +592,49 @@ func TestSyncLimit(t *testing.T) {
 	}
 }
 
+func TestSyncChunked(t *testing.T) {
+	logger := common.NewTestLogger(t)
+
+	keys, ps := initPeers(4)
+	nodes := initNodes(keys, ps, 1000, 3, "inmem", logger, t)
+	for _, n := range nodes {
+		n.conf.SyncChunked = true
+	}
+
+	// diffs over the sync limit are exchanged in chunks instead of making
+	// the nodes catch up
+	err := gossip(nodes, 10, false, 3*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdownNodes(nodes)
+	checkGossip(nodes, 0, t)
+
+	node0KnownEvents := nodes[0].core.KnownEvents()
+	for k := range node0KnownEvents {
+		node0KnownEvents[k] = 0
+	}
+	args := net.SyncRequest{
+		FromID:  nodes[0].id,
+		Known:   node0KnownEvents,
+		Chunked: true,
+	}
+	var out net.SyncResponse
+	if err := nodes[0].trans.Sync(nodes[1].localAddr, &args, &out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.SyncLimit {
+		t.Fatal("SyncResponse.SyncLimit should be false for a chunked sync")
+	}
+	if !out.More {
+		t.Fatal("SyncResponse.More should be true")
+	}
+	if l := int64(len(out.Events)); l == 0 || l > nodes[1].syncLimit.Value() {
+		t.Fatalf("SyncResponse.Events should hold a chunk of at most %d events, not %d",
+			nodes[1].syncLimit.Value(), l)
+	}
+}
+
 func TestFastForward(t *testing.T) {
 
 	logger := common.NewTestLogger(t)
@@ -662,6 +800,42 @@ func TestShutdown(t *testing.T) {
 	nodes[1].Shutdown()
 }
 
+func TestConcurrentShutdown(t *testing.T) {
+	logger := common.NewTestLogger(t)
+
+	keys, ps := initPeers(2)
+	nodes := initNodes(keys, ps, 1000, 1000, "inmem", logger, t)
+	defer shutdownNodes(nodes)
+	runNodes(nodes, false)
+
+	var hooks int32
+	nodes[0].OnShutdown(func() {
+		time.Sleep(100 * time.Millisecond)
+		atomic.AddInt32(&hooks, 1)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nodes[0].Shutdown()
+			// every call returns once the shutdown completed
+			if atomic.LoadInt32(&hooks) != 1 {
+				t.Error("Shutdown returned before the shutdown hooks ran")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&hooks); n != 1 {
+		t.Fatalf("the shutdown hooks should run once, not %d times", n)
+	}
+	if err := nodes[0].ShutdownError(); err != nil {
+		t.Fatalf("the shutdown should be clean: %s", err)
+	}
+}
+
 func TestBootstrapAllNodes(t *testing.T) {
 	logger := common.NewTestLogger(t)
 
@@ -13,6 +13,8 @@ type PeerSelector interface {
 	Peers() *peers.Peers
 	UpdateLast(peer string)
 	Next() *peers.Peer
+	// NextN selects up to n distinct peers to gossip with concurrently
+	NextN(n int) []*peers.Peer
 }
 
 //+++++++++++++++++++++++++++++++++++++++
@@ -56,3 +58,83 @@ func (ps *RandomPeerSelector) Next() *peers.Peer {
 
 	return peer
 }
+
+func (ps *RandomPeerSelector) NextN(n int) []*peers.Peer {
+	first := ps.Next()
+	res := []*peers.Peer{first}
+	_, others := peers.ExcludePeer(ps.peers.ToPeerSlice(), ps.localAddr)
+	_, others = peers.ExcludePeer(others, first.PubKeyHex)
+	for _, i := range rand.Perm(len(others)) {
+		if len(res) >= n {
+			break
+		}
+		res = append(res, others[i])
+	}
+	return res
+}
+
+//+++++++++++++++++++++++++++++++++++++++
+//FAIR ROUND ROBIN
+
+// RoundRobinPeerSelector selects the peers in turn, in the order of their
+// IDs, so that every peer is gossiped with as often as the others
+type RoundRobinPeerSelector struct {
+	peers   *peers.Peers
+	localID string
+	last    string
+	// cursor is the ID of the last peer selected, the next selection
+	// starting after it
+	cursor int64
+	// started is false until the first selection
+	started bool
+}
+
+// NewRoundRobinPeerSelector creates a RoundRobinPeerSelector of the
+// participants but localID, the public key or address of the node
+func NewRoundRobinPeerSelector(participants *peers.Peers, localID string) *RoundRobinPeerSelector {
+	return &RoundRobinPeerSelector{
+		peers:   participants,
+		localID: localID,
+	}
+}
+
+func (ps *RoundRobinPeerSelector) Peers() *peers.Peers {
+	return ps.peers
+}
+
+func (ps *RoundRobinPeerSelector) UpdateLast(peer string) {
+	ps.last = peer
+}
+
+func (ps *RoundRobinPeerSelector) Next() *peers.Peer {
+	return ps.NextN(1)[0]
+}
+
+// NextN selects the n peers after the last one selected, wrapping around
+// the peers sorted by ID
+func (ps *RoundRobinPeerSelector) NextN(n int) []*peers.Peer {
+	all := ps.peers.ToPeerSlice()
+	_, others := peers.ExcludePeer(all, ps.localID)
+	if len(others) == 0 {
+		others = all
+	}
+	start := 0
+	if ps.started {
+		for start < len(others) && others[start].ID <= ps.cursor {
+			start++
+		}
+		if start == len(others) {
+			start = 0
+		}
+	}
+	if n > len(others) {
+		n = len(others)
+	}
+	res := make([]*peers.Peer, 0, n)
+	for i := 0; i < n; i++ {
+		res = append(res, others[(start+i)%len(others)])
+	}
+	ps.cursor = res[len(res)-1].ID
+	ps.started = true
+	return res
+}
@@ -2,36 +2,44 @@ package node
 
 import (
 	"math/rand"
+	"sort"
+	"time"
 
 	"github.com/Fantom-foundation/go-lachesis/src/peers"
 )
 
-// PeerSelector provides an interface for the lachesis node to 
-// update the last peer it gossiped with and select the next peer
-// to gossip with 
-//type PeerSelector interface {
-//	Peers() *peers.Peers
-//	UpdateLast(peer string)
-//	Next() *peers.Peer
-//}
-
 //+++++++++++++++++++++++++++++++++++++++
 //Selection based on FlagTable of a randomly chosen undermined event
 
+// persistentInterval is the maximum number of selections between two
+// gossips with the same persistent peer
+const persistentInterval = 5
+
 type SmartPeerSelector struct {
 	peers        *peers.Peers
 	localAddr    string
 	last         string
+	reputation   *Reputation
+	bans         *peers.BanList
+	liveness     *livenessTable
+	missed       map[string]int
+	stats        map[string]*peerStats
 	GetFlagTable func() (map[string]int64, error)
 }
 
 func NewSmartPeerSelector(participants *peers.Peers,
 	localAddr string,
+	reputation *Reputation,
+	bans *peers.BanList,
 	GetFlagTable func() (map[string]int64, error)) *SmartPeerSelector {
 
 	return &SmartPeerSelector{
-		localAddr: localAddr,
-		peers:     participants,
+		localAddr:    localAddr,
+		peers:        participants,
+		reputation:   reputation,
+		bans:         bans,
+		missed:       make(map[string]int),
+		stats:        make(map[string]*peerStats),
 		GetFlagTable: GetFlagTable,
 	}
 }
@@ -45,15 +53,24 @@ func (ps *SmartPeerSelector) UpdateLast(peer string) {
 }
 
 func (ps *SmartPeerSelector) Next() *peers.Peer {
+	if p := ps.nextPersistent(); p != nil {
+		p.MarkUsed()
+		return p
+	}
 	selectablePeers := ps.peers.ToPeerByUsedSlice()[1:]
 	if len(selectablePeers) > 1 {
 		_, selectablePeers = peers.ExcludePeer(selectablePeers, ps.localAddr)
 		if len(selectablePeers) > 1 {
 			_, selectablePeers = peers.ExcludePeer(selectablePeers, ps.last)
-			if len(selectablePeers) > 1 {
+			selectablePeers = ps.excludeBanned(selectablePeers)
+			selectablePeers = ps.excludeDown(selectablePeers)
+			selectablePeers = ps.excludeLowScore(selectablePeers)
+			selectablePeers = excludeEphemeral(selectablePeers)
+			// the peers are weighted by usage when there are statistics
+			if len(selectablePeers) > 1 && len(ps.stats) == 0 {
 				var k int64
-				minUsed := selectablePeers[len(selectablePeers) - 1].Used
-				for k = 0; selectablePeers[k].Used > minUsed; k++ {}
+				minUsed := selectablePeers[len(selectablePeers) - 1].UsedCount()
+				for k = 0; selectablePeers[k].UsedCount() > minUsed; k++ {}
 				selectablePeers = selectablePeers[k:]
 				if ft, err := ps.GetFlagTable(); err == nil {
 					for id, flag := range ft {
@@ -65,8 +82,133 @@ func (ps *SmartPeerSelector) Next() *peers.Peer {
 			}
 		}
 	}
-	i := rand.Intn(len(selectablePeers))
-	selectablePeers[i].Used++;
+	i := ps.pick(selectablePeers)
+	selectablePeers[i].MarkUsed()
+	delete(ps.missed, selectablePeers[i].PubKeyHex)
+	ps.tried(selectablePeers[i])
 	return selectablePeers[i]
 }
 
+// NextN selects up to n distinct peers: the one of Next, then the least
+// used of the other selectable peers
+func (ps *SmartPeerSelector) NextN(n int) []*peers.Peer {
+	first := ps.Next()
+	res := []*peers.Peer{first}
+	if n <= 1 {
+		return res
+	}
+
+	_, others := peers.ExcludePeer(ps.peers.ToPeerSlice(), ps.localAddr)
+	_, others = peers.ExcludePeer(others, first.PubKeyHex)
+	if len(others) == 0 {
+		return res
+	}
+	others = ps.excludeBanned(others)
+	others = ps.excludeDown(others)
+	others = ps.excludeLowScore(others)
+	others = excludeEphemeral(others)
+
+	rand.Shuffle(len(others), func(i, j int) {
+		others[i], others[j] = others[j], others[i]
+	})
+	sort.SliceStable(others, func(i, j int) bool {
+		return others[i].UsedCount() < others[j].UsedCount()
+	})
+	for _, p := range others {
+		if len(res) >= n {
+			break
+		}
+		p.MarkUsed()
+		delete(ps.missed, p.PubKeyHex)
+		ps.tried(p)
+		res = append(res, p)
+	}
+	return res
+}
+
+// nextPersistent returns a persistent peer which was not selected during
+// the last persistentInterval selections, if any
+func (ps *SmartPeerSelector) nextPersistent() *peers.Peer {
+	var res *peers.Peer
+	for _, p := range ps.peers.ToTierSlice(peers.TierPersistent) {
+		if p.PubKeyHex == ps.localAddr || p.NetAddr == ps.localAddr {
+			continue
+		}
+		if ps.bans != nil && ps.bans.IsPeerBanned(p) {
+			continue
+		}
+		ps.missed[p.PubKeyHex]++
+		if res == nil && ps.missed[p.PubKeyHex] > persistentInterval {
+			res = p
+		}
+	}
+	if res != nil {
+		delete(ps.missed, res.PubKeyHex)
+	}
+	return res
+}
+
+// excludeBanned removes banned peers from the list, unless it would leave
+// no peer to select
+func (ps *SmartPeerSelector) excludeBanned(selectablePeers []*peers.Peer) []*peers.Peer {
+	if ps.bans == nil {
+		return selectablePeers
+	}
+	return filterPeers(selectablePeers, func(p *peers.Peer) bool {
+		return !ps.bans.IsPeerBanned(p)
+	})
+}
+
+// excludeDown removes the peers the failure detector found down from the
+// list, but for those due for a probe, unless it would leave no peer to
+// select
+func (ps *SmartPeerSelector) excludeDown(selectablePeers []*peers.Peer) []*peers.Peer {
+	if ps.liveness == nil {
+		return selectablePeers
+	}
+	now := time.Now()
+	return filterPeers(selectablePeers, func(p *peers.Peer) bool {
+		return ps.liveness.selectable(p.PubKeyHex, now)
+	})
+}
+
+// tried records the selection of a peer with the failure detector
+func (ps *SmartPeerSelector) tried(p *peers.Peer) {
+	if ps.liveness != nil {
+		ps.liveness.tried(p.PubKeyHex, time.Now())
+	}
+}
+
+// excludeEphemeral removes observers and relays from the list, unless it
+// would leave no peer to select
+func excludeEphemeral(selectablePeers []*peers.Peer) []*peers.Peer {
+	return filterPeers(selectablePeers, func(p *peers.Peer) bool {
+		return !p.IsEphemeral()
+	})
+}
+
+// excludeLowScore removes the peers with a low reputation from the list,
+// unless it would leave no peer to select
+func (ps *SmartPeerSelector) excludeLowScore(selectablePeers []*peers.Peer) []*peers.Peer {
+	if ps.reputation == nil {
+		return selectablePeers
+	}
+	return filterPeers(selectablePeers, func(p *peers.Peer) bool {
+		return !ps.reputation.IsLow(p.PubKeyHex)
+	})
+}
+
+// filterPeers returns the peers for which keep returns true, or the whole
+// list if none does
+func filterPeers(selectablePeers []*peers.Peer, keep func(*peers.Peer) bool) []*peers.Peer {
+	res := make([]*peers.Peer, 0, len(selectablePeers))
+	for _, p := range selectablePeers {
+		if keep(p) {
+			res = append(res, p)
+		}
+	}
+	if len(res) == 0 {
+		return selectablePeers
+	}
+	return res
+}
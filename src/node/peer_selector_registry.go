@@ -0,0 +1,102 @@
+package node
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+// The names of the built-in peer selectors, see Config.PeerSelector
+const (
+	PeerSelectorSmart      = "smart"
+	PeerSelectorRandom     = "random"
+	PeerSelectorRoundRobin = "fair-round-robin"
+)
+
+// PeerSelectorContext is what the node gives a PeerSelectorFactory
+type PeerSelectorContext struct {
+	Participants *peers.Peers
+	// LocalID is the public key of the node, which must not be selected
+	LocalID    string
+	Reputation *Reputation
+	Bans       *peers.BanList
+	// GetFlagTable returns the flag table of a random undetermined event
+	GetFlagTable func() (map[string]int64, error)
+}
+
+// PeerSelectorFactory creates the PeerSelector of a node
+type PeerSelectorFactory func(ctx PeerSelectorContext) PeerSelector
+
+var peerSelectors = struct {
+	sync.Mutex
+	factories map[string]PeerSelectorFactory
+}{
+	factories: map[string]PeerSelectorFactory{
+		PeerSelectorSmart: func(ctx PeerSelectorContext) PeerSelector {
+			return NewSmartPeerSelector(ctx.Participants, ctx.LocalID, ctx.Reputation, ctx.Bans, ctx.GetFlagTable)
+		},
+		PeerSelectorRandom: func(ctx PeerSelectorContext) PeerSelector {
+			return NewRandomPeerSelector(ctx.Participants, ctx.LocalID)
+		},
+		PeerSelectorRoundRobin: func(ctx PeerSelectorContext) PeerSelector {
+			return NewRoundRobinPeerSelector(ctx.Participants, ctx.LocalID)
+		},
+	},
+}
+
+// RegisterPeerSelector makes a peer selector available under name to
+// Config.PeerSelector, typically from the init function of the package of
+// an embedder. It panics if the name is taken or factory is nil.
+func RegisterPeerSelector(name string, factory PeerSelectorFactory) {
+	peerSelectors.Lock()
+	defer peerSelectors.Unlock()
+	if factory == nil {
+		panic("node: RegisterPeerSelector factory is nil")
+	}
+	if _, ok := peerSelectors.factories[name]; ok {
+		panic("node: RegisterPeerSelector called twice for " + name)
+	}
+	peerSelectors.factories[name] = factory
+}
+
+// PeerSelectors returns the names of the registered peer selectors, sorted
+func PeerSelectors() []string {
+	peerSelectors.Lock()
+	defer peerSelectors.Unlock()
+	names := make([]string, 0, len(peerSelectors.factories))
+	for name := range peerSelectors.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isPeerSelector tells whether a peer selector is registered under name
+func isPeerSelector(name string) bool {
+	peerSelectors.Lock()
+	defer peerSelectors.Unlock()
+	_, ok := peerSelectors.factories[name]
+	return ok
+}
+
+// newPeerSelector creates the peer selector registered under name
+func newPeerSelector(name string, ctx PeerSelectorContext) (PeerSelector, error) {
+	peerSelectors.Lock()
+	factory, ok := peerSelectors.factories[name]
+	peerSelectors.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown peer selector %q, registered: %v", name, PeerSelectors())
+	}
+	return factory(ctx), nil
+}
+
+// peerSelector returns the name of the peer selector of conf, smart when
+// unset
+func (conf *Config) peerSelector() string {
+	if conf.PeerSelector == "" {
+		return PeerSelectorSmart
+	}
+	return conf.PeerSelector
+}
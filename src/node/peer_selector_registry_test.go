@@ -0,0 +1,69 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+func TestRoundRobinPeerSelector(t *testing.T) {
+	participants := peers.NewPeers()
+	for i := 0; i < 4; i++ {
+		participants.AddPeer(&peers.Peer{
+			ID:        int64(i + 1),
+			NetAddr:   fmt.Sprintf("addr%d", i),
+			PubKeyHex: fmt.Sprintf("0x%02d", i),
+		})
+	}
+	ps := NewRoundRobinPeerSelector(participants, "0x00")
+
+	// every other peer in turn, then around again
+	var selected []string
+	for i := 0; i < 4; i++ {
+		selected = append(selected, ps.Next().PubKeyHex)
+	}
+	if fmt.Sprint(selected) != "[0x01 0x02 0x03 0x01]" {
+		t.Fatalf("unexpected selections %v", selected)
+	}
+	selected = nil
+	for _, p := range ps.NextN(5) {
+		selected = append(selected, p.PubKeyHex)
+	}
+	if fmt.Sprint(selected) != "[0x02 0x03 0x01]" {
+		t.Fatalf("unexpected selections %v", selected)
+	}
+}
+
+func TestRegisterPeerSelector(t *testing.T) {
+	RegisterPeerSelector("test-first", func(ctx PeerSelectorContext) PeerSelector {
+		return NewRandomPeerSelector(ctx.Participants, ctx.LocalID)
+	})
+	ps, err := newPeerSelector("test-first", PeerSelectorContext{Participants: peers.NewPeers()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ps.(*RandomPeerSelector); !ok {
+		t.Fatalf("expected the registered selector, got %T", ps)
+	}
+	if _, err := newPeerSelector("unknown", PeerSelectorContext{}); err == nil {
+		t.Fatal("an unknown selector should be refused")
+	}
+
+	conf := TestConfig(t)
+	conf.PeerSelector = "test-first"
+	if err := conf.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	conf.PeerSelector = "unknown"
+	if err := conf.Validate(); err == nil {
+		t.Fatal("an unknown selector should be invalid")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering a name twice should panic")
+		}
+	}()
+	RegisterPeerSelector(PeerSelectorSmart, func(ctx PeerSelectorContext) PeerSelector { return nil })
+}
@@ -0,0 +1,124 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+func TestSmartPeerSelectorTiers(t *testing.T) {
+	participants := peers.NewPeers()
+	tiers := []string{"", "", "", peers.TierEphemeral, peers.TierEphemeral, peers.TierPersistent}
+	for i, tier := range tiers {
+		participants.AddPeer(&peers.Peer{
+			ID:        int64(i + 1),
+			NetAddr:   fmt.Sprintf("addr%d", i),
+			PubKeyHex: fmt.Sprintf("0x%02d", i),
+			Tier:      tier,
+		})
+	}
+
+	ps := NewSmartPeerSelector(participants, "0x00", nil, nil,
+		func() (map[string]int64, error) {
+			return nil, fmt.Errorf("no flag table")
+		})
+
+	lastPersistent := 0
+	for i := 1; i <= 50; i++ {
+		p := ps.Next()
+		if p.IsEphemeral() {
+			t.Fatalf("ephemeral peer %s should not be selected", p.PubKeyHex)
+		}
+		if p.IsPersistent() {
+			lastPersistent = i
+		}
+		if i-lastPersistent > persistentInterval+1 {
+			t.Fatalf("persistent peer not selected since selection %d", lastPersistent)
+		}
+		ps.UpdateLast(p.NetAddr)
+	}
+}
+
+func TestSmartPeerSelectorNextN(t *testing.T) {
+	participants := peers.NewPeers()
+	for i := 0; i < 6; i++ {
+		participants.AddPeer(&peers.Peer{
+			ID:        int64(i + 1),
+			NetAddr:   fmt.Sprintf("addr%d", i),
+			PubKeyHex: fmt.Sprintf("0x%02d", i),
+		})
+	}
+
+	ps := NewSmartPeerSelector(participants, "0x00", nil, nil,
+		func() (map[string]int64, error) {
+			return nil, fmt.Errorf("no flag table")
+		})
+
+	for i := 0; i < 20; i++ {
+		selected := ps.NextN(3)
+		if len(selected) != 3 {
+			t.Fatalf("expected 3 peers, got %d", len(selected))
+		}
+		seen := make(map[string]bool)
+		for _, p := range selected {
+			if p.PubKeyHex == "0x00" {
+				t.Fatal("the local peer should not be selected")
+			}
+			if seen[p.PubKeyHex] {
+				t.Fatalf("peer %s selected twice", p.PubKeyHex)
+			}
+			seen[p.PubKeyHex] = true
+		}
+		ps.UpdateLast(selected[0].NetAddr)
+	}
+
+	// there are only 5 other peers
+	if selected := ps.NextN(10); len(selected) != 5 {
+		t.Fatalf("expected the 5 other peers, got %d", len(selected))
+	}
+}
+
+func TestSmartPeerSelectorWeights(t *testing.T) {
+	participants := peers.NewPeers()
+	for i := 0; i < 4; i++ {
+		participants.AddPeer(&peers.Peer{
+			ID:        int64(i + 1),
+			NetAddr:   fmt.Sprintf("addr%d", i),
+			PubKeyHex: fmt.Sprintf("0x%02d", i),
+		})
+	}
+
+	ps := NewSmartPeerSelector(participants, "0x00", nil, nil,
+		func() (map[string]int64, error) {
+			return nil, fmt.Errorf("no flag table")
+		})
+
+	ours := map[int64]int64{1: 10, 2: 10, 3: 10, 4: 10}
+	// 0x01 is slow and up to date, 0x02 and 0x03 are fast and ahead of us
+	ps.RecordSync("0x01", 500*time.Millisecond, ours, ours)
+	ps.RecordSync("0x02", 5*time.Millisecond, ours, map[int64]int64{1: 20, 2: 20, 3: 10, 4: 10})
+	ps.RecordSync("0x03", 5*time.Millisecond, ours, map[int64]int64{1: 20, 2: 20, 3: 10, 4: 10})
+
+	counts := make(map[string]int)
+	for i := 0; i < 200; i++ {
+		p := ps.Next()
+		counts[p.PubKeyHex]++
+		ps.UpdateLast(p.NetAddr)
+	}
+	if counts["0x01"] > counts["0x02"] || counts["0x01"] > counts["0x03"] {
+		t.Fatalf("the slow and up to date peer should be selected less: %v", counts)
+	}
+
+	stats := ps.Stats()
+	if stats["selector_peers"] != "3" {
+		t.Fatalf("expected statistics of 3 peers, got %s", stats["selector_peers"])
+	}
+	if stats["selector_stale_events"] != "40" {
+		t.Fatalf("expected 40 stale events, got %s", stats["selector_stale_events"])
+	}
+	if stats["selector_rtt_ms_2"] != "500.00" {
+		t.Fatalf("expected a 500ms round-trip time, got %s", stats["selector_rtt_ms_2"])
+	}
+}
@@ -0,0 +1,132 @@
+package node
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+// rttSmoothing is the weight of a new round-trip time sample in the moving
+// average of a peer
+const rttSmoothing = 0.2
+
+// peerStats is what the SmartPeerSelector observed of the syncs with a peer
+type peerStats struct {
+	// rtt is the moving average of the round-trip time of the SyncRequests
+	rtt time.Duration
+	// stale is the number of events the peer knew and we did not at the
+	// last sync
+	stale int64
+	// last is the time of the last sync
+	last time.Time
+}
+
+// RecordSync records a sync with the peer pubKey which took rtt, ours and
+// theirs being the KnownEvents sent and received
+func (ps *SmartPeerSelector) RecordSync(pubKey string, rtt time.Duration, ours, theirs map[int64]int64) {
+	if pubKey == "" {
+		return
+	}
+	var stale int64
+	for id, index := range theirs {
+		if diff := index - ours[id]; diff > 0 {
+			stale += diff
+		}
+	}
+	s, ok := ps.stats[pubKey]
+	if !ok {
+		s = &peerStats{rtt: rtt}
+		ps.stats[pubKey] = s
+	}
+	s.rtt += time.Duration(rttSmoothing * float64(rtt-s.rtt))
+	s.stale = stale
+	s.last = time.Now()
+}
+
+// weight is the preference for a sync with the peer: the more events it
+// had ahead of us, the longer ago we synced with it and the faster it
+// answers, the higher
+func (s *peerStats) weight(now time.Time) float64 {
+	rtt := s.rtt.Seconds()
+	if rtt <= 0 {
+		rtt = time.Millisecond.Seconds()
+	}
+	return float64(1+s.stale) * (1 + now.Sub(s.last).Seconds()) / rtt
+}
+
+// pick selects one of the peers at random, weighted by their statistics and
+// divided by how much more they were used than the least used one. The peers
+// we never synced with get the weight of the best peer, so that they are
+// tried.
+func (ps *SmartPeerSelector) pick(selectablePeers []*peers.Peer) int {
+	now := time.Now()
+	weights := make([]float64, len(selectablePeers))
+	best := 0.0
+	minUsed := selectablePeers[0].UsedCount()
+	for i, p := range selectablePeers {
+		if s, ok := ps.stats[p.PubKeyHex]; ok {
+			weights[i] = s.weight(now)
+			if weights[i] > best {
+				best = weights[i]
+			}
+		}
+		if used := p.UsedCount(); used < minUsed {
+			minUsed = used
+		}
+	}
+	if best == 0 {
+		return rand.Intn(len(selectablePeers))
+	}
+	total := 0.0
+	for i, p := range selectablePeers {
+		if _, ok := ps.stats[p.PubKeyHex]; !ok {
+			weights[i] = best
+		}
+		weights[i] /= float64(1 + p.UsedCount() - minUsed)
+		total += weights[i]
+	}
+	r := rand.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	return len(selectablePeers) - 1
+}
+
+// Stats returns the sync statistics the selection is based on: the number
+// of peers with statistics, their average round-trip time in milliseconds
+// and the number of events they had ahead of us, and the same per peer ID.
+// The statistics of the peers which left are dropped.
+func (ps *SmartPeerSelector) Stats() map[string]string {
+	res := make(map[string]string)
+	var rtt time.Duration
+	var stale int64
+	ps.peers.RLock()
+	for pubKey, s := range ps.stats {
+		p, ok := ps.peers.ByPubKey[pubKey]
+		if !ok {
+			delete(ps.stats, pubKey)
+			continue
+		}
+		rtt += s.rtt
+		stale += s.stale
+		id := strconv.FormatInt(p.ID, 10)
+		res["selector_rtt_ms_"+id] = formatMillis(s.rtt)
+		res["selector_stale_events_"+id] = strconv.FormatInt(s.stale, 10)
+	}
+	ps.peers.RUnlock()
+	res["selector_peers"] = strconv.Itoa(len(ps.stats))
+	if len(ps.stats) > 0 {
+		res["selector_rtt_ms"] = formatMillis(rtt / time.Duration(len(ps.stats)))
+	}
+	res["selector_stale_events"] = strconv.FormatInt(stale, 10)
+	return res
+}
+
+func formatMillis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 2, 64)
+}
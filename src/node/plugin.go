@@ -0,0 +1,143 @@
+package node
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// pluginQueueSize is the number of callbacks queued for a plugin before new
+// ones are dropped
+const pluginQueueSize = 1000
+
+// PluginSymbol is the symbol a Go plugin exports to be loaded by LoadPlugin:
+// either a Plugin or a func() (Plugin, error)
+const PluginSymbol = "Plugin"
+
+// Plugin receives the consensus events of a node, to add side effects such
+// as webhooks, secondary indexes or alerts. Callbacks run on a goroutine of
+// the plugin, in order, and never hold up consensus: they are dropped when
+// the plugin falls pluginQueueSize callbacks behind. Embed NopPlugin to
+// implement only some of them.
+type Plugin interface {
+	// Name identifies the plugin in logs and metrics
+	Name() string
+	// OnBlockCommit is called after a block was committed to the application
+	OnBlockCommit(block poset.Block) error
+	// OnRoundDecided is called after the frame of a round was processed
+	OnRoundDecided(round int64) error
+	// OnPeerAdded is called after a peer joined the validators
+	OnPeerAdded(peer *peers.Peer) error
+	// OnPeerRemoved is called after a peer left the validators
+	OnPeerRemoved(peer *peers.Peer) error
+}
+
+// NopPlugin implements the callbacks of Plugin with no-ops
+type NopPlugin struct{}
+
+// OnBlockCommit implements Plugin
+func (NopPlugin) OnBlockCommit(poset.Block) error { return nil }
+
+// OnRoundDecided implements Plugin
+func (NopPlugin) OnRoundDecided(int64) error { return nil }
+
+// OnPeerAdded implements Plugin
+func (NopPlugin) OnPeerAdded(*peers.Peer) error { return nil }
+
+// OnPeerRemoved implements Plugin
+func (NopPlugin) OnPeerRemoved(*peers.Peer) error { return nil }
+
+// LoadPlugin opens the Go plugin at path and returns its PluginSymbol
+func LoadPlugin(path string) (Plugin, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening plugin %s: %s", path, err)
+	}
+	sym, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %s", path, err)
+	}
+	switch s := sym.(type) {
+	case Plugin:
+		return s, nil
+	case *Plugin:
+		return *s, nil
+	case func() (Plugin, error):
+		return s()
+	case *func() (Plugin, error):
+		return (*s)()
+	}
+	return nil, fmt.Errorf("plugin %s: %s is a %T, not a node.Plugin", path, PluginSymbol, sym)
+}
+
+// pluginCall is a queued callback and its name for logs
+type pluginCall struct {
+	event string
+	fn    func(Plugin) error
+}
+
+type pluginRunner struct {
+	plugin Plugin
+	calls  chan pluginCall
+}
+
+// RegisterPlugin makes p receive the events of the node from now on
+func (n *Node) RegisterPlugin(p Plugin) {
+	r := &pluginRunner{
+		plugin: p,
+		calls:  make(chan pluginCall, pluginQueueSize),
+	}
+	n.pluginsLock.Lock()
+	n.plugins = append(n.plugins, r)
+	n.pluginsLock.Unlock()
+
+	logger := n.logger.WithField("plugin", p.Name())
+	logger.Info("Registered plugin")
+	go func() {
+		for {
+			select {
+			case call := <-r.calls:
+				if err := call.fn(p); err != nil {
+					metrics.IncrCounter("node.plugins."+p.Name()+".errors", 1)
+					logger.WithFields(logrus.Fields{
+						"event": call.event,
+						"error": err,
+					}).Error("Plugin callback")
+				}
+			case <-n.shutdownCh:
+				return
+			}
+		}
+	}()
+}
+
+// notifyPlugins queues a callback for every plugin
+func (n *Node) notifyPlugins(event string, fn func(Plugin) error) {
+	n.pluginsLock.RLock()
+	defer n.pluginsLock.RUnlock()
+	for _, r := range n.plugins {
+		select {
+		case r.calls <- pluginCall{event, fn}:
+		default:
+			metrics.IncrCounter("node.plugins."+r.plugin.Name()+".dropped", 1)
+		}
+	}
+}
+
+// watchMembership forwards the membership changes of participants to the
+// plugins
+func (n *Node) watchMembership(participants *peers.Peers) {
+	participants.OnPeerAdded(peers.PriorityNode, func(peer *peers.Peer) error {
+		n.notifyPlugins("peer_added", func(p Plugin) error { return p.OnPeerAdded(peer) })
+		return nil
+	})
+	participants.OnPeerRemoved(peers.PriorityNode, func(peer *peers.Peer) error {
+		n.notifyPlugins("peer_removed", func(p Plugin) error { return p.OnPeerRemoved(peer) })
+		return nil
+	})
+}
@@ -0,0 +1,75 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+type recordingPlugin struct {
+	NopPlugin
+	events chan string
+}
+
+func (p *recordingPlugin) Name() string { return "recording" }
+
+func (p *recordingPlugin) OnBlockCommit(block poset.Block) error {
+	p.events <- "block"
+	return nil
+}
+
+func (p *recordingPlugin) OnPeerAdded(peer *peers.Peer) error {
+	p.events <- "added " + peer.NetAddr
+	return nil
+}
+
+func (p *recordingPlugin) OnPeerRemoved(peer *peers.Peer) error {
+	p.events <- "removed " + peer.NetAddr
+	return nil
+}
+
+func TestPlugins(t *testing.T) {
+	n := &Node{
+		logger:     logrus.New().WithField("test", t.Name()),
+		shutdownCh: make(chan struct{}),
+	}
+	defer close(n.shutdownCh)
+
+	participants := peers.NewPeers()
+	n.watchMembership(participants)
+
+	p := &recordingPlugin{events: make(chan string, 10)}
+	n.RegisterPlugin(p)
+
+	block := poset.NewBlock(0, 1, []byte("frame"), [][]byte{[]byte("tx")})
+	n.notifyPlugins("block", func(p Plugin) error { return p.OnBlockCommit(block) })
+
+	peer := peers.NewPeer("0xAA", "addr1")
+	if err := participants.AddPeer(peer); err != nil {
+		t.Fatal(err)
+	}
+	if err := participants.RemovePeer(peer); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"block", "added addr1", "removed addr1"} {
+		select {
+		case event := <-p.events:
+			if event != expected {
+				t.Fatalf("expected event %q, got %q", expected, event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for event %q", expected)
+		}
+	}
+}
+
+func TestLoadPluginMissing(t *testing.T) {
+	if _, err := LoadPlugin("/nonexistent/plugin.so"); err == nil {
+		t.Fatal("loading a missing plugin should fail")
+	}
+}
@@ -0,0 +1,85 @@
+package node
+
+import "bytes"
+
+// The transaction pool is split in lanes: the transactions of a higher lane
+// go into the self-events before the ones of the lower lanes, each lane
+// keeping the order of submission. The internal transactions and the block
+// signatures travel in their own fields of the events and always go first.
+const (
+	// LaneNormal is the lane of the transactions by default
+	LaneNormal = 0
+	// LaneHigh is the lane of the high-priority transactions
+	LaneHigh = 1
+)
+
+// OrderingPolicy decides the lane of the transactions added to the pool of
+// a Core, the higher lanes going first. The policy only orders the
+// transactions of this node: each node may run its own.
+type OrderingPolicy interface {
+	// Lane returns the lane of tx; it must always return the same lane for
+	// the same transaction
+	Lane(tx []byte) int
+}
+
+// FIFOPolicy puts every transaction in LaneNormal, ordering the pool by
+// submission
+type FIFOPolicy struct{}
+
+// Lane implements OrderingPolicy
+func (FIFOPolicy) Lane(tx []byte) int {
+	return LaneNormal
+}
+
+// PrefixPolicy puts the transactions starting with Prefix in LaneHigh
+type PrefixPolicy struct {
+	Prefix []byte
+}
+
+// Lane implements OrderingPolicy
+func (p PrefixPolicy) Lane(tx []byte) int {
+	if len(p.Prefix) > 0 && bytes.HasPrefix(tx, p.Prefix) {
+		return LaneHigh
+	}
+	return LaneNormal
+}
+
+// orderingPolicy returns the policy of conf: its OrderingPolicy, else a
+// PrefixPolicy of its HighPriorityPrefix, else nil for FIFO
+func (conf *Config) orderingPolicy() OrderingPolicy {
+	switch {
+	case conf.OrderingPolicy != nil:
+		return conf.OrderingPolicy
+	case conf.HighPriorityPrefix != "":
+		return PrefixPolicy{Prefix: []byte(conf.HighPriorityPrefix)}
+	}
+	return nil
+}
+
+// SetOrderingPolicy makes the core order its pool with policy, nil being
+// FIFO, and reorders the transactions already pooled
+func (c *Core) SetOrderingPolicy(policy OrderingPolicy) {
+	c.ordering = policy
+	pool := c.transactionPool
+	c.transactionPool = make([][]byte, 0, len(pool))
+	for _, tx := range pool {
+		c.poolTransaction(tx)
+	}
+}
+
+// poolTransaction adds tx to the pool after the transactions of its lane
+// and of the higher ones
+func (c *Core) poolTransaction(tx []byte) {
+	if c.ordering == nil {
+		c.transactionPool = append(c.transactionPool, tx)
+		return
+	}
+	lane := c.ordering.Lane(tx)
+	i := len(c.transactionPool)
+	for i > 0 && c.ordering.Lane(c.transactionPool[i-1]) < lane {
+		i--
+	}
+	c.transactionPool = append(c.transactionPool, nil)
+	copy(c.transactionPool[i+1:], c.transactionPool[i:])
+	c.transactionPool[i] = tx
+}
@@ -0,0 +1,34 @@
+package node
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrderingPolicy(t *testing.T) {
+	cores, _, _ := initCores(1, t)
+	core := cores[0]
+
+	txs := func(s ...string) [][]byte {
+		res := make([][]byte, len(s))
+		for i := range s {
+			res[i] = []byte(s[i])
+		}
+		return res
+	}
+	core.AddTransactions(txs("a1", "!b1", "a2"))
+	if !reflect.DeepEqual(core.transactionPool, txs("a1", "!b1", "a2")) {
+		t.Fatalf("the pool should be FIFO without policy, got %q", core.transactionPool)
+	}
+
+	// the pooled transactions are reordered, and the new ones go after the
+	// transactions of their lane
+	core.SetOrderingPolicy(PrefixPolicy{Prefix: []byte("!")})
+	core.AddTransactions(txs("a3", "!b2"))
+	if !reflect.DeepEqual(core.transactionPool, txs("!b1", "!b2", "a1", "a2", "a3")) {
+		t.Fatalf("the high lane should go first, got %q", core.transactionPool)
+	}
+	if size := core.PoolBytes(); size != 12 {
+		t.Fatalf("the pool should hold 12 bytes, got %d", size)
+	}
+}
@@ -0,0 +1,73 @@
+package node
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+func TestPruneStore(t *testing.T) {
+	logger := common.NewTestLogger(t)
+
+	keys, ps := initPeers(4)
+	nodes := initNodes(keys, ps, 1000, 1000, "badger", logger, t)
+	for _, n := range nodes {
+		n.core.poset.SetPruneDepth(2)
+		defer os.RemoveAll(n.core.poset.Store.StorePath())
+	}
+
+	if err := gossip(nodes, 10, true, 6*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := poset.LoadBadgerStore(1000, nodes[0].core.poset.Store.StorePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	info, pruned, err := store.PruneInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pruned || info.Round < 1 {
+		t.Fatalf("expected the store to be pruned, got %v %+v", pruned, info)
+	}
+	if _, err := store.GetRound(info.Round - 1); err == nil {
+		t.Fatalf("round %d should be pruned", info.Round-1)
+	}
+	if _, err := store.GetRound(info.Round); err != nil {
+		t.Fatalf("round %d should be kept: %s", info.Round, err)
+	}
+
+	// the anchor block and its frame, the base of a bootstrap, are kept
+	block, err := store.GetBlock(info.Block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetFrame(block.RoundReceived()); err != nil {
+		t.Fatal(err)
+	}
+
+	// an offline pruning goes on from the last anchor block
+	anchor, err := store.LastAnchorBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anchor.Index() < info.Block {
+		t.Fatalf("last anchor block %d is before the pruning base %d", anchor.Index(), info.Block)
+	}
+	if _, err := store.Prune(anchor, 0); err != nil {
+		t.Fatal(err)
+	}
+	next, _, err := store.PruneInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Block != anchor.Index() || next.Round != anchor.RoundReceived() {
+		t.Fatalf("expected pruning at block %d round %d, got %+v", anchor.Index(), anchor.RoundReceived(), next)
+	}
+}
@@ -0,0 +1,158 @@
+package node
+
+import (
+	"sync"
+)
+
+// Behaviour is an observed action of a peer which affects its reputation
+type Behaviour int
+
+const (
+	// Responsive is recorded for every successful exchange with a peer
+	Responsive Behaviour = iota
+	// SyncFailure is recorded when a sync with a peer fails
+	SyncFailure
+	// InvalidEvent is recorded when a peer submits events which can not be
+	// inserted into the poset
+	InvalidEvent
+	// ProtocolViolation is recorded when a peer sends malformed or
+	// unverifiable messages
+	ProtocolViolation
+)
+
+const (
+	// MaxScore is the highest reputation a peer can reach
+	MaxScore int64 = 100
+	// MinScore is the lowest reputation a peer can reach
+	MinScore int64 = -100
+	// LowScore is the threshold under which a peer is deprioritized
+	LowScore int64 = -20
+	// RemovalScore is the threshold under which a peer becomes a candidate
+	// for removal from the peer set
+	RemovalScore int64 = -80
+)
+
+// maxProtocolViolations is the number of protocol violations after which a
+// peer is temporarily banned
+const maxProtocolViolations = 3
+
+var behaviourWeights = map[Behaviour]int64{
+	Responsive:        1,
+	SyncFailure:       -5,
+	InvalidEvent:      -20,
+	ProtocolViolation: -40,
+}
+
+func (b Behaviour) String() string {
+	switch b {
+	case Responsive:
+		return "Responsive"
+	case SyncFailure:
+		return "SyncFailure"
+	case InvalidEvent:
+		return "InvalidEvent"
+	case ProtocolViolation:
+		return "ProtocolViolation"
+	default:
+		return "Unknown"
+	}
+}
+
+// Reputation keeps a score per peer, indexed by public key
+type Reputation struct {
+	sync.RWMutex
+	scores     map[string]int64
+	violations map[string]int
+}
+
+// NewReputation creates an empty reputation table. Unknown peers have a
+// score of zero.
+func NewReputation() *Reputation {
+	return &Reputation{
+		scores:     make(map[string]int64),
+		violations: make(map[string]int),
+	}
+}
+
+// Record applies the weight of a behaviour to the score of a peer and
+// returns the new score
+func (r *Reputation) Record(pubKey string, b Behaviour) int64 {
+	r.Lock()
+	defer r.Unlock()
+
+	score := r.scores[pubKey] + behaviourWeights[b]
+	if score > MaxScore {
+		score = MaxScore
+	}
+	if score < MinScore {
+		score = MinScore
+	}
+	r.scores[pubKey] = score
+	if b == ProtocolViolation {
+		r.violations[pubKey]++
+	}
+
+	return score
+}
+
+// Score returns the current score of a peer
+func (r *Reputation) Score(pubKey string) int64 {
+	r.RLock()
+	defer r.RUnlock()
+	return r.scores[pubKey]
+}
+
+// Violations returns the number of protocol violations recorded for a peer
+// since the last call to ResetViolations
+func (r *Reputation) Violations(pubKey string) int {
+	r.RLock()
+	defer r.RUnlock()
+	return r.violations[pubKey]
+}
+
+// ResetViolations clears the protocol violations count of a peer
+func (r *Reputation) ResetViolations(pubKey string) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.violations, pubKey)
+}
+
+// Forget drops the score and violations of a peer
+func (r *Reputation) Forget(pubKey string) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.scores, pubKey)
+	delete(r.violations, pubKey)
+}
+
+// IsLow returns true if the peer should be deprioritized in selection
+func (r *Reputation) IsLow(pubKey string) bool {
+	return r.Score(pubKey) < LowScore
+}
+
+// Scores returns a copy of all recorded scores
+func (r *Reputation) Scores() map[string]int64 {
+	r.RLock()
+	defer r.RUnlock()
+
+	res := make(map[string]int64, len(r.scores))
+	for pubKey, score := range r.scores {
+		res[pubKey] = score
+	}
+	return res
+}
+
+// RemovalCandidates returns the public keys of the peers whose score fell
+// under RemovalScore
+func (r *Reputation) RemovalCandidates() []string {
+	r.RLock()
+	defer r.RUnlock()
+
+	var res []string
+	for pubKey, score := range r.scores {
+		if score < RemovalScore {
+			res = append(res, pubKey)
+		}
+	}
+	return res
+}
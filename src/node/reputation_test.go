@@ -0,0 +1,66 @@
+package node
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+func TestReputationRecord(t *testing.T) {
+	r := NewReputation()
+
+	if s := r.Score("a"); s != 0 {
+		t.Fatalf("unknown peer should have score 0, not %d", s)
+	}
+
+	r.Record("a", Responsive)
+	r.Record("a", Responsive)
+	if s := r.Score("a"); s != 2 {
+		t.Fatalf("score should be 2, not %d", s)
+	}
+
+	for i := 0; i < 10; i++ {
+		r.Record("b", ProtocolViolation)
+	}
+	if s := r.Score("b"); s != MinScore {
+		t.Fatalf("score should be clamped to %d, not %d", MinScore, s)
+	}
+	if !r.IsLow("b") {
+		t.Fatal("b should have a low score")
+	}
+
+	candidates := r.RemovalCandidates()
+	if len(candidates) != 1 || candidates[0] != "b" {
+		t.Fatalf("removal candidates should be [b], not %v", candidates)
+	}
+}
+
+func TestSmartPeerSelectorReputation(t *testing.T) {
+	participants := peers.NewPeers()
+	for i := 0; i < 5; i++ {
+		participants.AddPeer(&peers.Peer{
+			ID:        int64(i + 1),
+			NetAddr:   fmt.Sprintf("addr%d", i),
+			PubKeyHex: fmt.Sprintf("0x%02d", i),
+		})
+	}
+
+	r := NewReputation()
+	for i := 0; i < 2; i++ {
+		r.Record("0x01", InvalidEvent)
+		r.Record("0x02", InvalidEvent)
+	}
+
+	ps := NewSmartPeerSelector(participants, "0x00", r, nil,
+		func() (map[string]int64, error) {
+			return nil, fmt.Errorf("no flag table")
+		})
+
+	for i := 0; i < 20; i++ {
+		p := ps.Next()
+		if r.IsLow(p.PubKeyHex) {
+			t.Fatalf("peer %s with low score should not be selected", p.PubKeyHex)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package node
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// drainPoll is the interval at which Drain checks the pools
+const drainPoll = 100 * time.Millisecond
+
+// Drain stops accepting transactions and waits, gossiping as usual, until
+// the pending ones reached consensus or timeout elapsed
+func (n *Node) Drain(timeout time.Duration) error {
+	atomic.StoreInt32(&n.draining, 1)
+	n.logger.WithField("timeout", timeout).Info("Draining transactions")
+
+	deadline := time.Now().Add(timeout)
+	for {
+		pending := n.pendingTransactions()
+		if pending == 0 {
+			return nil
+		}
+		if n.getState() == Shutdown || time.Now().After(deadline) {
+			return fmt.Errorf("%d transactions still pending", pending)
+		}
+		time.Sleep(drainPoll)
+	}
+}
+
+// Draining returns true once Drain was called
+func (n *Node) Draining() bool {
+	return atomic.LoadInt32(&n.draining) == 1
+}
+
+// pendingTransactions counts the transactions not yet in an event and the
+// events not yet in a block
+func (n *Node) pendingTransactions() int64 {
+	n.coreLock.Lock()
+	defer n.coreLock.Unlock()
+	return int64(len(n.core.transactionPool)) + n.core.poset.GetPendingLoadedEvents()
+}
+
+// OnShutdown registers fn to run during Shutdown, after the store was
+// flushed and before the transport is closed
+func (n *Node) OnShutdown(fn func()) {
+	n.shutdownHooksLock.Lock()
+	defer n.shutdownHooksLock.Unlock()
+	n.shutdownHooks = append(n.shutdownHooks, fn)
+}
+
+func (n *Node) runShutdownHooks() {
+	n.shutdownHooksLock.Lock()
+	hooks := n.shutdownHooks
+	n.shutdownHooksLock.Unlock()
+	for _, fn := range hooks {
+		fn()
+	}
+}
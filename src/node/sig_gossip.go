@@ -0,0 +1,77 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// maxGossipedSignatures bounds the signatures of a SignatureGossip request
+const maxGossipedSignatures = 100
+
+// gossipSignature pushes the signature of a block to every peer, see
+// Config.SignatureGossip. The peers which miss it still get it with the next
+// event of the node.
+func (n *Node) gossipSignature(sig poset.BlockSignature) {
+	self := n.core.HexID()
+	for _, p := range n.peerSelector.Peers().ToPeerSlice() {
+		if p.PubKeyHex == self || n.bans.IsPeerBanned(p) {
+			continue
+		}
+		args := net.SignatureGossipRequest{
+			FromID:     n.id,
+			Signatures: []poset.BlockSignature{sig},
+		}
+		var out net.SignatureGossipResponse
+		if err := n.trans.SignatureGossip(p.NetAddr, &args, &out); err != nil {
+			metrics.IncrCounter("node.sig_gossip.errors", 1)
+			n.logger.WithFields(logrus.Fields{
+				"peer":  p.NetAddr,
+				"block": sig.Index,
+				"error": err,
+			}).Debug("Gossiping block signature")
+			continue
+		}
+		metrics.IncrCounter("node.sig_gossip.sent", 1)
+	}
+}
+
+func (n *Node) processSignatureGossipRequest(rpc net.RPC, cmd *net.SignatureGossipRequest) {
+	n.logger.WithFields(logrus.Fields{
+		"from_id":    cmd.FromID,
+		"signatures": len(cmd.Signatures),
+	}).Debug("processSignatureGossipRequest(rpc net.RPC, cmd *net.SignatureGossipRequest)")
+
+	if len(cmd.Signatures) > maxGossipedSignatures {
+		rpc.Respond(nil, fmt.Errorf("%d signatures, at most %d accepted", len(cmd.Signatures), maxGossipedSignatures))
+		return
+	}
+
+	n.coreLock.Lock()
+	accepted, err := n.core.AddSignatures(cmd.Signatures)
+	n.coreLock.Unlock()
+	if err != nil {
+		n.logger.WithError(err).Error("Processing gossiped block signatures")
+	}
+	metrics.IncrCounter("node.sig_gossip.accepted", int64(accepted))
+
+	rpc.Respond(&net.SignatureGossipResponse{
+		FromID:   n.id,
+		Accepted: accepted,
+	}, nil)
+}
+
+// AddSignatures adds gossiped block signatures to the sig pool of the poset
+// and processes it, so that a block reaching the trust count becomes the
+// anchor without waiting for the next consensus run
+func (c *Core) AddSignatures(sigs []poset.BlockSignature) (int, error) {
+	accepted := c.poset.AddSignatures(sigs)
+	if accepted == 0 {
+		return 0, nil
+	}
+	return accepted, c.poset.ProcessSigPool()
+}
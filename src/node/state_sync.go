@@ -0,0 +1,241 @@
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy"
+)
+
+// fastForwardRetryDelay is the pause before a failed fast-forward is tried
+// again with another peer
+const fastForwardRetryDelay = time.Second
+
+// snapshotCache keeps the last snapshot served to fast-forwarding peers, so
+// that the application does not rebuild it for every chunk request
+type snapshotCache struct {
+	sync.Mutex
+	block int64
+	data  []byte
+}
+
+func (n *Node) snapshotChunkSize() int {
+	if n.conf.SnapshotChunkSize > 0 {
+		return n.conf.SnapshotChunkSize
+	}
+	return DefaultSnapshotChunkSize
+}
+
+// snapshot returns the application snapshot of a block
+func (n *Node) snapshot(blockIndex int64) ([]byte, error) {
+	n.snapshots.Lock()
+	defer n.snapshots.Unlock()
+	if n.snapshots.data != nil && n.snapshots.block == blockIndex {
+		return n.snapshots.data, nil
+	}
+	data, err := n.proxy.GetSnapshot(blockIndex)
+	if err != nil {
+		return nil, err
+	}
+	n.snapshots.block, n.snapshots.data = blockIndex, data
+	return data, nil
+}
+
+// splitChunks cuts data in chunks of size bytes, the last one being shorter
+func splitChunks(data []byte, size int) [][]byte {
+	var chunks [][]byte
+	for len(data) > size {
+		chunks = append(chunks, data[:size])
+		data = data[size:]
+	}
+	return append(chunks, data)
+}
+
+// describeSnapshot fills the snapshot fields of a FastForwardResponse. The
+// snapshot is sent inline when it fits in a single chunk.
+func (n *Node) describeSnapshot(resp *net.FastForwardResponse, snapshot []byte) {
+	resp.SnapshotHash = crypto.SHA256(snapshot)
+	chunks := splitChunks(snapshot, n.snapshotChunkSize())
+	if len(chunks) == 1 {
+		resp.Snapshot = snapshot
+		return
+	}
+	resp.SnapshotChunks = make([][]byte, len(chunks))
+	for i, chunk := range chunks {
+		resp.SnapshotChunks[i] = crypto.SHA256(chunk)
+	}
+}
+
+func (n *Node) processSnapshotChunkRequest(rpc net.RPC, cmd *net.SnapshotChunkRequest) {
+	n.logger.WithFields(logrus.Fields{
+		"from":  cmd.FromID,
+		"block": cmd.BlockIndex,
+		"chunk": cmd.Chunk,
+	}).Debug("processSnapshotChunkRequest(rpc net.RPC, cmd *net.SnapshotChunkRequest)")
+
+	snapshot, err := n.snapshot(cmd.BlockIndex)
+	if err != nil {
+		rpc.Respond(nil, err)
+		return
+	}
+	chunks := splitChunks(snapshot, n.snapshotChunkSize())
+	if cmd.Chunk < 0 || cmd.Chunk >= len(chunks) {
+		rpc.Respond(nil, fmt.Errorf("snapshot of block %d has %d chunks, %d requested", cmd.BlockIndex, len(chunks), cmd.Chunk))
+		return
+	}
+	metrics.IncrCounter("node.snapshot.chunks.served", 1)
+	rpc.Respond(&net.SnapshotChunkResponse{
+		FromID: n.id,
+		Chunk:  cmd.Chunk,
+		Data:   chunks[cmd.Chunk],
+	}, nil)
+}
+
+// RestartFromSnapshot resets the node to the state after block: the poset is
+// rebuilt from frame, the head and sequence of the node are reloaded from it,
+// and the application is restored from appSnapshot, see restoreApp. Both the
+// block and the frame are checked before anything is changed, and the
+// application is restored before the poset so that a snapshot it refuses
+// leaves the poset as it was. Catching up, restoring from a local snapshot and
+// embedders all go through it.
+func (n *Node) RestartFromSnapshot(block poset.Block, frame poset.Frame, appSnapshot []byte) error {
+	return n.restartFromSnapshot(block, frame, appSnapshot, nil)
+}
+
+// restartFromSnapshot is RestartFromSnapshot, also resetting the governance
+// state to params when the node has not processed the rounds up to the block,
+// see resetParams
+func (n *Node) restartFromSnapshot(block poset.Block, frame poset.Frame, appSnapshot []byte, params *poset.NetworkParams) error {
+	// no gossip may insert events while the poset is replaced
+	n.waitRoutines()
+
+	n.coreLock.Lock()
+	defer n.coreLock.Unlock()
+
+	if err := n.core.CheckAnchor(block, frame); err != nil {
+		return fmt.Errorf("checking block %d: %s", block.Index(), err)
+	}
+	if err := n.restoreApp(block, appSnapshot); err != nil {
+		return fmt.Errorf("restoring the application to block %d: %s", block.Index(), err)
+	}
+	if err := n.core.FastForward("", block, frame); err != nil {
+		return fmt.Errorf("resetting the poset to block %d: %s", block.Index(), err)
+	}
+	// the restored application holds the blocks up to this one
+	if err := n.storeAck(block.Index()); err != nil {
+		return fmt.Errorf("acknowledging block %d: %s", block.Index(), err)
+	}
+	if err := n.resetParams(block.RoundReceived(), params); err != nil {
+		return fmt.Errorf("resetting the network parameters to round %d: %s", block.RoundReceived(), err)
+	}
+
+	metrics.IncrCounter("node.restarts", 1)
+	n.logger.WithFields(logrus.Fields{
+		"block":          block.Index(),
+		"round_received": block.RoundReceived(),
+		"frame_events":   len(frame.Events),
+	}).Info("Restarted from snapshot")
+	return nil
+}
+
+// restoreApp restores the application from snapshot. When the block carries
+// a state hash, the application must report the state hash it was restored
+// to, see proxy.RestoringAppProxy, and it must match the one of the block.
+func (n *Node) restoreApp(block poset.Block, snapshot []byte) error {
+	if len(block.StateHash) == 0 {
+		return n.proxy.Restore(snapshot)
+	}
+	restorer, ok := n.proxy.(proxy.RestoringAppProxy)
+	if !ok {
+		return fmt.Errorf("the application does not report the state hash to check the snapshot against")
+	}
+	stateHash, err := restorer.RestoreState(snapshot)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(stateHash, block.StateHash) {
+		return fmt.Errorf("restored state hash %X does not match the state hash %X of the block", stateHash, block.StateHash)
+	}
+	return nil
+}
+
+// fetchSnapshot returns the application snapshot announced by a
+// FastForwardResponse of anchor. Chunks are requested from all the peers in
+// turn, starting with anchor, and every chunk is checked against its hash
+// before the whole snapshot is checked against SnapshotHash. The response
+// being untrusted, the block must carry the state hash the application is
+// then checked to be restored to, see restoreApp.
+func (n *Node) fetchSnapshot(anchor *peers.Peer, resp *net.FastForwardResponse) ([]byte, error) {
+	if len(resp.SnapshotHash) == 0 {
+		return nil, fmt.Errorf("snapshot of block %d has no hash", resp.Block.Index())
+	}
+	if len(resp.Block.StateHash) == 0 {
+		return nil, fmt.Errorf("block %d has no state hash to check its snapshot against", resp.Block.Index())
+	}
+	if len(resp.SnapshotChunks) == 0 {
+		if !bytes.Equal(crypto.SHA256(resp.Snapshot), resp.SnapshotHash) {
+			return nil, fmt.Errorf("snapshot of block %d does not match its hash", resp.Block.Index())
+		}
+		return resp.Snapshot, nil
+	}
+
+	_, others := peers.ExcludePeer(n.peerSelector.Peers().ToPeerSlice(), n.localAddr)
+	_, others = peers.ExcludePeer(others, anchor.NetAddr)
+	sources := append([]*peers.Peer{anchor}, others...)
+
+	var snapshot []byte
+	for i, hash := range resp.SnapshotChunks {
+		chunk, err := n.fetchChunk(sources, i, resp.Block.Index(), hash)
+		if err != nil {
+			return nil, err
+		}
+		snapshot = append(snapshot, chunk...)
+	}
+
+	if !bytes.Equal(crypto.SHA256(snapshot), resp.SnapshotHash) {
+		return nil, fmt.Errorf("snapshot of block %d does not match its hash", resp.Block.Index())
+	}
+	return snapshot, nil
+}
+
+// fetchChunk requests a snapshot chunk from the sources, starting with the
+// one of index chunk modulo their number, until one returns the expected data
+func (n *Node) fetchChunk(sources []*peers.Peer, chunk int, blockIndex int64, hash []byte) ([]byte, error) {
+	for j := range sources {
+		peer := sources[(chunk+j)%len(sources)]
+		args := net.SnapshotChunkRequest{
+			FromID:     n.id,
+			BlockIndex: blockIndex,
+			Chunk:      chunk,
+		}
+		var out net.SnapshotChunkResponse
+		if err := n.trans.SnapshotChunk(peer.NetAddr, &args, &out); err != nil {
+			n.logger.WithFields(logrus.Fields{
+				"peer":  peer.NetAddr,
+				"chunk": chunk,
+				"error": err,
+			}).Debug("Requesting snapshot chunk")
+			continue
+		}
+		if !bytes.Equal(crypto.SHA256(out.Data), hash) {
+			metrics.IncrCounter("node.snapshot.chunks.invalid", 1)
+			n.logger.WithFields(logrus.Fields{
+				"peer":  peer.NetAddr,
+				"chunk": chunk,
+			}).Warn("Snapshot chunk does not match its hash")
+			continue
+		}
+		metrics.IncrCounter("node.snapshot.chunks.fetched", 1)
+		return out.Data, nil
+	}
+	return nil, fmt.Errorf("no peer served chunk %d of the snapshot of block %d", chunk, blockIndex)
+}
@@ -0,0 +1,258 @@
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/dummy"
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// serveChunks answers SnapshotChunk requests with the chunks of snapshot,
+// corrupting the ones listed in bad
+func serveChunks(trans net.Transport, snapshot []byte, size int, bad map[int]bool, stop chan struct{}) {
+	chunks := splitChunks(snapshot, size)
+	for {
+		select {
+		case rpc := <-trans.Consumer():
+			cmd := rpc.Command.(*net.SnapshotChunkRequest)
+			data := append([]byte{}, chunks[cmd.Chunk]...)
+			if bad[cmd.Chunk] {
+				data[0] ^= 0xff
+			}
+			rpc.Respond(&net.SnapshotChunkResponse{Chunk: cmd.Chunk, Data: data}, nil)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func TestFetchSnapshot(t *testing.T) {
+	snapshot := bytes.Repeat([]byte("lachesis"), 100)
+	conf := TestConfig(t)
+	conf.SnapshotChunkSize = 64
+
+	localAddr, local := net.NewInmemTransport("")
+	anchorAddr, anchor := net.NewInmemTransport("")
+	goodAddr, good := net.NewInmemTransport("")
+	defer local.Close()
+	defer anchor.Close()
+	defer good.Close()
+
+	participants := peers.NewPeers()
+	participants.AddPeer(peers.NewPeer("0xLOCAL", localAddr))
+	participants.AddPeer(peers.NewPeer("0xANCHOR", anchorAddr))
+	participants.AddPeer(peers.NewPeer("0xGOOD", goodAddr))
+
+	n := &Node{
+		conf:         conf,
+		logger:       common.NewTestLogger(t).WithField("this_id", 0),
+		localAddr:    localAddr,
+		trans:        local,
+		peerSelector: NewRandomPeerSelector(participants, localAddr),
+	}
+
+	resp := net.FastForwardResponse{Block: poset.NewBlock(3, 4, []byte("frame"), nil)}
+	resp.Block.StateHash = []byte("state")
+	n.describeSnapshot(&resp, snapshot)
+	if resp.Snapshot != nil || len(resp.SnapshotChunks) != 13 {
+		t.Fatalf("expected 13 chunks and no inline snapshot, got %d", len(resp.SnapshotChunks))
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	// the anchor corrupts a chunk, which is then fetched from the other peer
+	go serveChunks(anchor, snapshot, conf.SnapshotChunkSize, map[int]bool{2: true}, stop)
+	go serveChunks(good, snapshot, conf.SnapshotChunkSize, nil, stop)
+
+	res, err := n.fetchSnapshot(participants.ByPubKey["0xANCHOR"], &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(res, snapshot) {
+		t.Fatal("reassembled snapshot differs")
+	}
+
+	// a snapshot that matches no hash is refused
+	resp.SnapshotHash = []byte("wrong")
+	if _, err := n.fetchSnapshot(participants.ByPubKey["0xANCHOR"], &resp); err == nil {
+		t.Fatal("snapshot with a wrong hash should be refused")
+	}
+	// as are the responses which cannot be checked
+	resp.SnapshotHash = nil
+	if _, err := n.fetchSnapshot(participants.ByPubKey["0xANCHOR"], &resp); err == nil {
+		t.Fatal("snapshot without hash should be refused")
+	}
+	n.describeSnapshot(&resp, snapshot)
+	resp.Block.StateHash = nil
+	if _, err := n.fetchSnapshot(participants.ByPubKey["0xANCHOR"], &resp); err == nil {
+		t.Fatal("snapshot of a block without state hash should be refused")
+	}
+}
+
+func TestSmallSnapshotInline(t *testing.T) {
+	n := &Node{conf: TestConfig(t)}
+	var resp net.FastForwardResponse
+	resp.Block.StateHash = []byte("state")
+	n.describeSnapshot(&resp, []byte("state"))
+	if string(resp.Snapshot) != "state" || resp.SnapshotChunks != nil {
+		t.Fatalf("small snapshots should be sent inline, got %#v", resp)
+	}
+	res, err := n.fetchSnapshot(nil, &resp)
+	if err != nil || string(res) != "state" {
+		t.Fatalf("unexpected %q, %v", res, err)
+	}
+}
+
+func TestRestartFromSnapshot(t *testing.T) {
+	cores, _, _ := initCores(4, t)
+	initFFPoset(cores, t)
+
+	block0, err := cores[1].poset.Store.GetBlock(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := poset.NewBlock(block0.Index(), block0.RoundReceived(), block0.GetFrameHash(), block0.Transactions())
+	for _, c := range cores[1:] {
+		sig, err := c.SignBlock(block0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block0.SetSignature(sig)
+	}
+	if err := cores[1].poset.Store.SetBlock(block0); err != nil {
+		t.Fatal(err)
+	}
+	cores[1].poset.AnchorBlock = new(int64)
+	block, frame, err := cores[1].GetAnchorBlockWithFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := common.NewTestLogger(t)
+	n := &Node{
+		core:   cores[0],
+		proxy:  dummy.NewInmemDummyApp(logger),
+		logger: logger.WithField("this_id", 0),
+		params: poset.NewNetworkParams(),
+	}
+	tx := []byte("tx")
+	stateHash := crypto.SimpleHashFromTwoHashes([]byte{}, crypto.SHA256(tx))
+	snapshot, err := json.Marshal(dummy.Snapshot{
+		BlockIndex:   block.Index(),
+		StateHash:    stateHash,
+		Transactions: [][]byte{tx},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := cores[0].KnownEvents()
+
+	// neither a block lacking signatures nor a snapshot refused by the
+	// application change the node
+	if err := n.RestartFromSnapshot(unsigned, frame, snapshot); err == nil {
+		t.Fatal("restarting from an unsigned block should fail")
+	}
+	if err := n.RestartFromSnapshot(block, frame, []byte("garbage")); err == nil {
+		t.Fatal("restarting from an invalid snapshot should fail")
+	}
+	block.StateHash = []byte("other state")
+	if err := n.RestartFromSnapshot(block, frame, snapshot); err == nil {
+		t.Fatal("restarting from the snapshot of another state should fail")
+	}
+	if !reflect.DeepEqual(cores[0].KnownEvents(), before) {
+		t.Fatalf("failed restarts changed the known events: %v", cores[0].KnownEvents())
+	}
+
+	block.StateHash = stateHash
+	if err := n.RestartFromSnapshot(block, frame, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(cores[0].KnownEvents(), before) {
+		t.Fatal("the poset was not reset to the anchor block")
+	}
+	// the parameter changes of the rounds before the block are skipped
+	if round := n.networkParams().Round; round != block.RoundReceived() {
+		t.Fatalf("the parameter changes should be processed from round %d, got %d", block.RoundReceived()+1, round+1)
+	}
+}
+
+func TestCommitFastForward(t *testing.T) {
+	cores, _, _ := initCores(4, t)
+	initFFPoset(cores, t)
+
+	block0, err := cores[1].poset.Store.GetBlock(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the validators commit the block to their application and sign it with
+	// the state hash the application reports
+	logger := common.NewTestLogger(t)
+	var nodes []*Node
+	for i, c := range cores {
+		nodes = append(nodes, &Node{
+			conf:       TestConfig(t),
+			core:       c,
+			proxy:      dummy.NewInmemDummyApp(logger),
+			logger:     logger.WithField("this_id", i),
+			ackedBlock: -1,
+			shutdownCh: make(chan struct{}),
+			params:     poset.NewNetworkParams(),
+			txs:        newTxTracker(),
+		})
+	}
+	for _, n := range nodes[1:] {
+		if err := n.commit(block0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	anchor, err := cores[1].poset.Store.GetBlock(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anchor.StateHash) == 0 {
+		t.Fatal("the signed block should carry the state hash of the application")
+	}
+	for _, c := range cores[2:] {
+		signed, err := c.poset.Store.GetBlock(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(signed.StateHash, anchor.StateHash) {
+			t.Fatalf("the applications report different state hashes, %X and %X", signed.StateHash, anchor.StateHash)
+		}
+		for validator, sig := range signed.Signatures {
+			anchor.Signatures[validator] = sig
+		}
+	}
+	if err := cores[1].poset.Store.SetBlock(anchor); err != nil {
+		t.Fatal(err)
+	}
+	cores[1].poset.AnchorBlock = new(int64)
+
+	// the last node fast-forwards to the block with the snapshot of another
+	respCh := make(chan net.RPCResponse, 1)
+	nodes[1].processFastForwardRequest(net.RPC{RespChan: respCh}, &net.FastForwardRequest{FromID: 0})
+	out := <-respCh
+	if out.Error != nil {
+		t.Fatal(out.Error)
+	}
+	resp := out.Response.(*net.FastForwardResponse)
+	snapshot, err := nodes[0].fetchSnapshot(peers.NewPeer(cores[1].HexID(), ""), resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nodes[0].restartFromSnapshot(resp.Block, resp.Frame, snapshot, resp.Params); err != nil {
+		t.Fatal(err)
+	}
+	if nodes[0].AckedBlock() != 0 {
+		t.Fatalf("the restored application should hold block 0, got %d", nodes[0].AckedBlock())
+	}
+}
@@ -0,0 +1,138 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// With Config.SyncChunked a diff larger than the sync limit is not refused
+// with SyncLimit but exchanged in chunks of sync-limit events, so that no
+// RPC carries more than the limit and times out. The diffs are in
+// topological order, so every chunk only depends on the events of the
+// previous ones.
+//
+// A pull gets the first chunk in the SyncResponse, with More set, and asks
+// for the next ones with new SyncRequests whose Known acknowledges the
+// events inserted so far. A push sends the chunks in EagerSyncRequests one
+// after the other, waiting for the success of each before sending the next.
+// Only the last chunk of a sync creates a self-event, as a sync in one piece
+// would.
+
+// firstChunk returns the first limit events of events, and whether there
+// are more
+func firstChunk(events []poset.Event, limit int64) ([]poset.Event, bool) {
+	if limit < 1 || int64(len(events)) <= limit {
+		return events, false
+	}
+	return events[:limit], true
+}
+
+// pullChunks pulls the chunks of a diff following the first one, until the
+// peer has sent all of it, and returns the known events of the peer
+func (n *Node) pullChunks(peerAddr string) (map[int64]int64, error) {
+	for chunk := 2; ; chunk++ {
+		if n.Paused() || n.getState() == Shutdown {
+			return nil, fmt.Errorf("chunked sync with %s interrupted", peerAddr)
+		}
+
+		n.coreLock.Lock()
+		knownEvents := n.core.KnownEvents()
+		n.coreLock.Unlock()
+
+		start := time.Now()
+		resp, err := n.requestSync(peerAddr, knownEvents)
+		if err != nil {
+			n.logger.WithFields(logrus.Fields{
+				"peer":  peerAddr,
+				"chunk": chunk,
+				"error": err,
+			}).Error("n.requestSync(peerAddr, knownEvents)")
+			metrics.IncrCounter("node.sync.errors", 1)
+			n.recordBehaviour(n.peerPubKeyByAddr(peerAddr), SyncFailure)
+			return nil, err
+		}
+		n.recordSync(peerAddr, time.Since(start), knownEvents, resp.Known)
+		if resp.SyncLimit {
+			return nil, fmt.Errorf("peer %s stopped the chunked sync", peerAddr)
+		}
+
+		n.coreLock.Lock()
+		err = n.syncChunk(resp.Events, resp.More)
+		n.coreLock.Unlock()
+		if err != nil {
+			n.logger.WithField("error", err).Error("n.syncChunk(resp.Events, resp.More)")
+			metrics.IncrCounter("node.sync.errors", 1)
+			n.recordBehaviour(n.peerPubKey(resp.FromID), InvalidEvent)
+			return nil, err
+		}
+		metrics.IncrCounter("node.sync.events", int64(len(resp.Events)))
+		metrics.IncrCounter("node.sync.chunks", 1)
+
+		n.logger.WithFields(logrus.Fields{
+			"peer":   peerAddr,
+			"chunk":  chunk,
+			"events": len(resp.Events),
+			"more":   resp.More,
+		}).Debug("Pulled sync chunk")
+
+		// a chunk without events would never end the sync
+		if !resp.More || len(resp.Events) == 0 {
+			return resp.Known, nil
+		}
+	}
+}
+
+// pushChunks pushes events to a peer in chunks of sync-limit events. It
+// stops at the first chunk the peer does not accept, the rest being pushed
+// at a later gossip.
+func (n *Node) pushChunks(peerAddr string, events []poset.WireEvent) error {
+	limit := int(n.syncLimit.Value())
+	if limit < 1 {
+		limit = len(events)
+	}
+	for from, chunk := 0, 1; from < len(events); from, chunk = from+limit, chunk+1 {
+		to := from + limit
+		if to > len(events) {
+			to = len(events)
+		}
+
+		args := net.EagerSyncRequest{
+			FromID: n.id,
+			Events: events[from:to],
+			More:   to < len(events),
+		}
+		var resp net.EagerSyncResponse
+		start := time.Now()
+		err := n.trans.EagerSync(peerAddr, &args, &resp)
+		if err != nil {
+			n.logger.WithFields(logrus.Fields{
+				"peer":  peerAddr,
+				"chunk": chunk,
+				"error": err,
+			}).Error("n.trans.EagerSync(peerAddr, &args, &resp)")
+			n.recordBehaviour(n.peerPubKeyByAddr(peerAddr), SyncFailure)
+			return err
+		}
+		n.recordEventsPushed(peerAddr, to-from)
+		metrics.IncrCounter("node.sync.chunks", 1)
+
+		n.logger.WithFields(logrus.Fields{
+			"peer":     peerAddr,
+			"chunk":    chunk,
+			"events":   to - from,
+			"success":  resp.Success,
+			"Duration": time.Since(start).Nanoseconds(),
+		}).Debug("Pushed sync chunk")
+
+		if !resp.Success {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package node
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// syncCounts is the accounting of the SyncRequests sent to a peer, or to all
+// of them
+type syncCounts struct {
+	attempts  int64
+	successes int64
+	// received and sent are the events pulled with the SyncRequests and
+	// pushed with the EagerSyncRequests
+	received int64
+	sent     int64
+	// latency is the exponential moving average of the round-trip time of
+	// the successful SyncRequests, weighted by rttSmoothing
+	latency time.Duration
+}
+
+func (c *syncCounts) record(latency time.Duration, events int, ok bool) {
+	c.attempts++
+	if !ok {
+		return
+	}
+	if c.successes == 0 {
+		c.latency = latency
+	} else {
+		c.latency += time.Duration(rttSmoothing * float64(latency-c.latency))
+	}
+	c.successes++
+	c.received += int64(events)
+}
+
+// rate returns the share of the SyncRequests which succeeded, 1 before the
+// first one
+func (c *syncCounts) rate() float64 {
+	if c.attempts == 0 {
+		return 1
+	}
+	return float64(c.successes) / float64(c.attempts)
+}
+
+// eventsPerSync returns the average number of events pulled by a successful
+// SyncRequest
+func (c *syncCounts) eventsPerSync() float64 {
+	if c.successes == 0 {
+		return 0
+	}
+	return float64(c.received) / float64(c.successes)
+}
+
+// syncAccounting is the accounting of the syncs of the node, in total and by
+// peer public key
+type syncAccounting struct {
+	sync.Mutex
+	total syncCounts
+	peers map[string]*syncCounts
+}
+
+func newSyncAccounting() *syncAccounting {
+	return &syncAccounting{peers: make(map[string]*syncCounts)}
+}
+
+func (s *syncAccounting) peer(pubKey string) *syncCounts {
+	c, ok := s.peers[pubKey]
+	if !ok {
+		c = &syncCounts{}
+		s.peers[pubKey] = c
+	}
+	return c
+}
+
+// request records a SyncRequest to the peer pubKey, unknown when empty,
+// which took latency and pulled events when ok
+func (s *syncAccounting) request(pubKey string, latency time.Duration, events int, ok bool) {
+	s.Lock()
+	defer s.Unlock()
+	s.total.record(latency, events, ok)
+	if pubKey != "" {
+		s.peer(pubKey).record(latency, events, ok)
+	}
+}
+
+// pushed records events pushed to the peer pubKey
+func (s *syncAccounting) pushed(pubKey string, events int) {
+	s.Lock()
+	defer s.Unlock()
+	s.total.sent += int64(events)
+	if pubKey != "" {
+		s.peer(pubKey).sent += int64(events)
+	}
+}
+
+// forget drops the accounting of a removed peer, the totals keeping it
+func (s *syncAccounting) forget(pubKey string) {
+	s.Lock()
+	delete(s.peers, pubKey)
+	s.Unlock()
+}
+
+// rate returns the share of all the SyncRequests which succeeded
+func (s *syncAccounting) rate() float64 {
+	s.Lock()
+	defer s.Unlock()
+	return s.total.rate()
+}
+
+// recordSyncRequest accounts for a SyncRequest to peerAddr. A TooFar error
+// is an answer of the peer and counts as a success.
+func (n *Node) recordSyncRequest(peerAddr string, latency time.Duration, events int, err error) {
+	ok := err == nil || lerrors.Is(err, lerrors.TooFar)
+	n.syncs.request(n.peerPubKeyByAddr(peerAddr), latency, events, ok)
+
+	metrics.IncrCounter("node.sync.attempts", 1)
+	if !ok {
+		return
+	}
+	metrics.IncrCounter("node.sync.successes", 1)
+	metrics.AddSample("node.sync.events_per_sync", float64(events))
+	n.syncs.Lock()
+	ema := n.syncs.total.latency
+	n.syncs.Unlock()
+	metrics.SetGauge("node.sync.latency_ema_ms", float64(ema)/float64(time.Millisecond))
+}
+
+// recordEventsPushed accounts for the events pushed to peerAddr
+func (n *Node) recordEventsPushed(peerAddr string, events int) {
+	n.syncs.pushed(n.peerPubKeyByAddr(peerAddr), events)
+	metrics.IncrCounter("node.sync.events_sent", int64(events))
+}
+
+// syncStats returns the accounting of the syncs for GetStats: the
+// SyncRequests attempted and succeeded, the events pulled and pushed, the
+// events per sync and the moving average of the sync latency, in total and
+// per peer ID, and the bytes of the transport
+func (n *Node) syncStats() map[string]string {
+	res := make(map[string]string)
+	put := func(suffix string, c *syncCounts) {
+		res["sync_attempts"+suffix] = strconv.FormatInt(c.attempts, 10)
+		res["sync_successes"+suffix] = strconv.FormatInt(c.successes, 10)
+		res["sync_events_received"+suffix] = strconv.FormatInt(c.received, 10)
+		res["sync_events_sent"+suffix] = strconv.FormatInt(c.sent, 10)
+		res["sync_events_per_sync"+suffix] = strconv.FormatFloat(c.eventsPerSync(), 'f', 2, 64)
+		res["sync_latency_ms"+suffix] = formatMillis(c.latency)
+	}
+
+	participants := n.peerSelector.Peers()
+	n.syncs.Lock()
+	put("", &n.syncs.total)
+	participants.RLock()
+	for pubKey, c := range n.syncs.peers {
+		if p, ok := participants.ByPubKey[pubKey]; ok {
+			put("_"+strconv.FormatInt(p.ID, 10), c)
+		}
+	}
+	participants.RUnlock()
+	n.syncs.Unlock()
+
+	if t, ok := n.trans.(interface {
+		Traffic() (sent, received uint64)
+	}); ok {
+		sent, received := t.Traffic()
+		res["bytes_sent"] = strconv.FormatUint(sent, 10)
+		res["bytes_received"] = strconv.FormatUint(received, 10)
+	}
+	return res
+}
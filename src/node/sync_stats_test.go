@@ -0,0 +1,45 @@
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSyncAccounting(t *testing.T) {
+	s := newSyncAccounting()
+	if rate := s.rate(); rate != 1 {
+		t.Fatalf("the sync rate should be 1 before the first sync, not %v", rate)
+	}
+
+	s.request("a", 100*time.Millisecond, 10, true)
+	s.request("a", 200*time.Millisecond, 20, true)
+	s.request("a", time.Second, 0, false)
+	s.request("b", 50*time.Millisecond, 3, true)
+	s.pushed("a", 7)
+
+	a := s.peers["a"]
+	if a.attempts != 3 || a.successes != 2 || a.received != 30 || a.sent != 7 {
+		t.Fatalf("unexpected accounting of a: %+v", *a)
+	}
+	// the failed sync is not in the moving average
+	if expected := 100*time.Millisecond + time.Duration(rttSmoothing*float64(100*time.Millisecond)); a.latency != expected {
+		t.Fatalf("the sync latency of a should be %v, not %v", expected, a.latency)
+	}
+	if eps := a.eventsPerSync(); eps != 15 {
+		t.Fatalf("a should send 15 events per sync, not %v", eps)
+	}
+	if s.total.attempts != 4 || s.total.successes != 3 || s.total.received != 33 {
+		t.Fatalf("unexpected total accounting: %+v", s.total)
+	}
+	if rate := s.rate(); rate != 0.75 {
+		t.Fatalf("the sync rate should be 0.75, not %v", rate)
+	}
+
+	s.forget("a")
+	if _, ok := s.peers["a"]; ok {
+		t.Fatal("the accounting of a removed peer should be dropped")
+	}
+	if s.total.attempts != 4 {
+		t.Fatalf("the totals should keep the syncs of a removed peer, got %d", s.total.attempts)
+	}
+}
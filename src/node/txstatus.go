@@ -0,0 +1,332 @@
+package node
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy/proto"
+)
+
+// TxStatus is the state of a transaction submitted to the node
+type TxStatus string
+
+const (
+	// TxUnknown is the status of transactions not submitted to this node,
+	// or submitted too long ago to be remembered
+	TxUnknown TxStatus = "unknown"
+	// TxPending is the status of transactions not yet committed in a block
+	TxPending TxStatus = "pending"
+	// TxCommitted is the status of transactions committed in a block
+	TxCommitted TxStatus = "committed"
+	// TxExpired is the status of transactions dropped from the transaction
+	// pool at their expiry
+	TxExpired TxStatus = "expired"
+)
+
+// txStatusCacheSize is the number of transaction statuses remembered
+const txStatusCacheSize = 10000
+
+// txExpiryInterval is the time between two evictions of expired
+// transactions
+const txExpiryInterval = 100 * time.Millisecond
+
+// TxHash returns the hash identifying a transaction in GetTxStatus
+func TxHash(tx []byte) string {
+	return fmt.Sprintf("0x%X", crypto.SHA256(tx))
+}
+
+// TxReceipt tells where a transaction submitted to the node was put
+type TxReceipt struct {
+	Hash   string
+	Status TxStatus
+	// Event is the hash of the event of the node carrying the transaction,
+	// empty until it is created
+	Event string `json:",omitempty"`
+	// Block is the index of the block committing the transaction, -1 until
+	// it is committed
+	Block int64
+}
+
+// txLocation is the event and the block of a tracked transaction
+type txLocation struct {
+	event string
+	block int64
+}
+
+// txTracker follows the transactions submitted to the node
+type txTracker struct {
+	sync.Mutex
+	statuses *lru.Cache
+	// locations of the transactions, by hash
+	locations *lru.Cache
+	// expiries of the pending transactions submitted with one
+	expiries map[string]time.Time
+	// waiters are closed when their transaction leaves the pending status
+	waiters map[string][]chan struct{}
+	// submitted are the submission times of the pending transactions, by
+	// hash, and latencies the commit latencies of the last ones committed
+	submitted *lru.Cache
+	latencies *latencyWindow
+}
+
+func newTxTracker() *txTracker {
+	statuses, _ := lru.New(txStatusCacheSize)
+	locations, _ := lru.New(txStatusCacheSize)
+	submitted, _ := lru.New(txStatusCacheSize)
+	return &txTracker{
+		statuses:  statuses,
+		locations: locations,
+		expiries:  make(map[string]time.Time),
+		waiters:   make(map[string][]chan struct{}),
+		submitted: submitted,
+		latencies: newLatencyWindow(DefaultLatencyWindow),
+	}
+}
+
+// location returns the location of a transaction, the lock must be held
+func (t *txTracker) location(hash string) txLocation {
+	if loc, ok := t.locations.Get(hash); ok {
+		return loc.(txLocation)
+	}
+	return txLocation{block: -1}
+}
+
+// wake releases the waiters of a transaction, the lock must be held
+func (t *txTracker) wake(hash string) {
+	for _, ch := range t.waiters[hash] {
+		close(ch)
+	}
+	delete(t.waiters, hash)
+}
+
+// submitExpiringCh returns the channel of the transactions submitted with an
+// expiry, nil when the proxy does not support them
+func submitExpiringCh(p proxy.AppProxy) chan proto.ExpiringTx {
+	if p, ok := p.(proxy.ExpiringAppProxy); ok {
+		return p.SubmitExpiringCh()
+	}
+	return nil
+}
+
+// GetTxStatus returns the status of the transaction of hash TxHash(tx)
+func (n *Node) GetTxStatus(hash string) TxStatus {
+	if status, ok := n.txs.statuses.Get(hash); ok {
+		return status.(TxStatus)
+	}
+	return TxUnknown
+}
+
+// GetTxProof returns the proof that the transaction of hash TxHash(tx) is
+// part of the block of index block, or, when block is negative, of the block
+// which committed it if it was submitted to this node
+func (n *Node) GetTxProof(hash string, block int64) (*poset.TxProof, error) {
+	txHash, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(hash, "0x"), "0X"))
+	if err != nil || len(txHash) != sha256.Size {
+		return nil, lerrors.New(lerrors.InvalidTx, "invalid transaction hash %q", hash)
+	}
+	if block < 0 {
+		n.txs.Lock()
+		block = n.txs.location(fmt.Sprintf("0x%X", txHash)).block
+		n.txs.Unlock()
+		if block < 0 {
+			return nil, lerrors.New(lerrors.KeyNotFound, "no known block of transaction %s", hash)
+		}
+	}
+	return n.core.poset.TxProof(block, txHash)
+}
+
+// GetTxLocation returns the block, the position in the block and the event
+// of a committed transaction of hash TxHash(tx), from the transaction index
+// of the store
+func (n *Node) GetTxLocation(hash string) (poset.TxLocation, error) {
+	txHash, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(hash, "0x"), "0X"))
+	if err != nil || len(txHash) != sha256.Size {
+		return poset.TxLocation{}, lerrors.New(lerrors.InvalidTx, "invalid transaction hash %q", hash)
+	}
+	store, ok := n.core.poset.Store.(poset.TxIndexStore)
+	if !n.conf.TxIndex || !ok {
+		return poset.TxLocation{}, lerrors.New(lerrors.KeyNotFound, "transaction index disabled, see tx-index")
+	}
+	return store.GetTxLocation(fmt.Sprintf("0x%X", txHash))
+}
+
+// GetTxReceipt returns the status, the event and the block of the
+// transaction of hash TxHash(tx)
+func (n *Node) GetTxReceipt(hash string) TxReceipt {
+	n.txs.Lock()
+	defer n.txs.Unlock()
+	loc := n.txs.location(hash)
+	return TxReceipt{
+		Hash:   hash,
+		Status: n.GetTxStatus(hash),
+		Event:  loc.event,
+		Block:  loc.block,
+	}
+}
+
+// SubmitTx adds a transaction to the pool like the ones submitted by the
+// application, and returns the error of the node when it refuses it
+func (n *Node) SubmitTx(tx []byte) error {
+	result := make(chan error, 1)
+	select {
+	case n.submitTxCh <- proto.CheckedTx{Data: tx, Result: result}:
+	case <-n.shutdownCh:
+		return lerrors.New(lerrors.MempoolFull, "node is shut down, dropping transaction")
+	}
+	return <-result
+}
+
+// WaitTx waits until the transaction of hash TxHash(tx) is no longer
+// pending, ctx is done or the node shuts down, and returns its receipt
+func (n *Node) WaitTx(ctx context.Context, hash string) TxReceipt {
+	n.txs.Lock()
+	if n.GetTxStatus(hash) != TxPending {
+		n.txs.Unlock()
+		return n.GetTxReceipt(hash)
+	}
+	ch := make(chan struct{})
+	n.txs.waiters[hash] = append(n.txs.waiters[hash], ch)
+	n.txs.Unlock()
+
+	select {
+	case <-ch:
+	case <-ctx.Done():
+	case <-n.shutdownCh:
+	}
+
+	n.txs.Lock()
+	waiters := n.txs.waiters[hash]
+	for i, other := range waiters {
+		if other == ch {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(n.txs.waiters, hash)
+	} else {
+		n.txs.waiters[hash] = waiters
+	}
+	n.txs.Unlock()
+	return n.GetTxReceipt(hash)
+}
+
+// trackEvent records the self-event carrying tracked transactions
+func (n *Node) trackEvent(event poset.Event) {
+	hash := event.Hex()
+	n.txs.Lock()
+	defer n.txs.Unlock()
+	for _, tx := range event.Transactions() {
+		txHash := TxHash(tx)
+		if n.txs.statuses.Contains(txHash) {
+			n.txs.locations.Add(txHash, txLocation{event: hash, block: -1})
+		}
+	}
+}
+
+// addExpiringTransaction adds a transaction to the pool until its expiry
+func (n *Node) addExpiringTransaction(tx proto.ExpiringTx) error {
+	hash := TxHash(tx.Data)
+	if !tx.Expiry.After(time.Now()) {
+		metrics.IncrCounter("node.transactions.expired", 1)
+		n.txs.statuses.Add(hash, TxExpired)
+		return fmt.Errorf("transaction %s expired before its submission", hash)
+	}
+	if err := n.addTransaction(tx.Data); err != nil {
+		return err
+	}
+	n.txs.Lock()
+	n.txs.expiries[hash] = tx.Expiry
+	n.txs.Unlock()
+	return nil
+}
+
+// trackCommitted marks the transactions of the committed block of index
+// block
+func (n *Node) trackCommitted(block int64, txs [][]byte) {
+	now := time.Now()
+	n.txs.Lock()
+	defer n.txs.Unlock()
+	tracked := false
+	for _, tx := range txs {
+		hash := TxHash(tx)
+		if n.txs.statuses.Contains(hash) {
+			n.txs.statuses.Add(hash, TxCommitted)
+			loc := n.txs.location(hash)
+			loc.block = block
+			n.txs.locations.Add(hash, loc)
+			n.txs.wake(hash)
+			n.trackLatency(hash, block, now)
+			tracked = true
+		}
+		delete(n.txs.expiries, hash)
+	}
+	if tracked {
+		n.reportLatency()
+	}
+}
+
+// runTxExpiry periodically evicts the expired transactions
+func (n *Node) runTxExpiry() {
+	ticker := time.NewTicker(txExpiryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			n.evictExpired(now)
+		case <-n.shutdownCh:
+			return
+		}
+	}
+}
+
+// evictExpired drops the transactions which are still in the pool at their
+// expiry. The ones already added to an event stay pending until committed.
+func (n *Node) evictExpired(now time.Time) {
+	n.txs.Lock()
+	expired := make(map[string]bool)
+	for hash, expiry := range n.txs.expiries {
+		if !expiry.After(now) {
+			expired[hash] = true
+			delete(n.txs.expiries, hash)
+		}
+	}
+	n.txs.Unlock()
+	if len(expired) == 0 {
+		return
+	}
+
+	n.coreLock.Lock()
+	evicted := n.core.RemoveTransactions(func(tx []byte) bool {
+		return expired[TxHash(tx)]
+	})
+	n.coreLock.Unlock()
+
+	n.txs.Lock()
+	for _, tx := range evicted {
+		hash := TxHash(tx)
+		n.txs.statuses.Add(hash, TxExpired)
+		n.txs.submitted.Remove(hash)
+		n.txs.wake(hash)
+	}
+	n.txs.Unlock()
+	if len(evicted) > 0 {
+		metrics.IncrCounter("node.transactions.expired", int64(len(evicted)))
+		n.logger.WithFields(logrus.Fields{
+			"evicted": len(evicted),
+		}).Debug("Evicted expired transactions")
+	}
+}
@@ -0,0 +1,151 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/memory"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy/proto"
+)
+
+func TestTxExpiry(t *testing.T) {
+	cores, _, _ := initCores(1, t)
+	n := &Node{
+		conf:   TestConfig(t),
+		logger: common.NewTestLogger(t).WithField("this_id", 0),
+		core:   cores[0],
+		budget: memory.NewBudget(0),
+		txs:    newTxTracker(),
+	}
+
+	now := time.Now()
+	short := proto.ExpiringTx{Data: []byte("short"), Expiry: now.Add(time.Second)}
+	long := proto.ExpiringTx{Data: []byte("long"), Expiry: now.Add(time.Hour)}
+	for _, tx := range []proto.ExpiringTx{short, long} {
+		if err := n.addExpiringTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := n.addTransaction([]byte("forever")); err != nil {
+		t.Fatal(err)
+	}
+	late := proto.ExpiringTx{Data: []byte("late"), Expiry: now.Add(-time.Second)}
+	if err := n.addExpiringTransaction(late); err == nil {
+		t.Fatal("transactions submitted after their expiry should be refused")
+	}
+
+	n.evictExpired(now.Add(2 * time.Second))
+
+	if l := len(n.core.transactionPool); l != 2 {
+		t.Fatalf("expected 2 transactions left in the pool, got %d", l)
+	}
+	for tx, expected := range map[string]TxStatus{
+		"short":   TxExpired,
+		"late":    TxExpired,
+		"long":    TxPending,
+		"forever": TxPending,
+		"unknown": TxUnknown,
+	} {
+		if status := n.GetTxStatus(TxHash([]byte(tx))); status != expected {
+			t.Errorf("%s: expected status %s, got %s", tx, expected, status)
+		}
+	}
+
+	n.trackCommitted(3, [][]byte{[]byte("long")})
+	if status := n.GetTxStatus(TxHash([]byte("long"))); status != TxCommitted {
+		t.Fatalf("expected committed, got %s", status)
+	}
+	if len(n.txs.expiries) != 0 {
+		t.Fatal("committed transactions should no longer expire")
+	}
+}
+
+func TestTransactionLimits(t *testing.T) {
+	cores, _, _ := initCores(1, t)
+	conf := TestConfig(t)
+	conf.MaxTxSize = 10
+	conf.MaxPoolBytes = 25
+	n := &Node{
+		conf:   conf,
+		logger: common.NewTestLogger(t).WithField("this_id", 0),
+		core:   cores[0],
+		budget: memory.NewBudget(0),
+		txs:    newTxTracker(),
+	}
+
+	if err := n.addTransaction([]byte("more than 10 bytes")); !lerrors.Is(err, lerrors.TxTooLarge) {
+		t.Fatalf("expected a TxTooLarge error, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := n.addTransaction([]byte("0123456789")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := n.addTransaction([]byte("0123456789")); !lerrors.Is(err, lerrors.MempoolFull) {
+		t.Fatalf("expected a MempoolFull error, got %v", err)
+	}
+
+	// the pool accepts transactions again once it is drained
+	if err := n.core.AddSelfEventBlock(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.addTransaction([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTxReceipt(t *testing.T) {
+	cores, _, _ := initCores(1, t)
+	n := &Node{
+		conf:       TestConfig(t),
+		logger:     common.NewTestLogger(t).WithField("this_id", 0),
+		core:       cores[0],
+		budget:     memory.NewBudget(0),
+		txs:        newTxTracker(),
+		shutdownCh: make(chan struct{}),
+	}
+	n.core.onSelfEvent = n.trackEvent
+
+	tx := []byte("receipt")
+	hash := TxHash(tx)
+	if err := n.addTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+	if r := n.GetTxReceipt(hash); r.Status != TxPending || r.Event != "" || r.Block != -1 {
+		t.Fatalf("unexpected receipt of a pooled transaction %+v", r)
+	}
+	if err := n.core.AddSelfEventBlock(""); err != nil {
+		t.Fatal(err)
+	}
+	if r := n.GetTxReceipt(hash); r.Event != n.core.Head() {
+		t.Fatalf("the transaction should be in event %s, got %+v", n.core.Head(), r)
+	}
+
+	// a timed out wait returns the pending receipt
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if r := n.WaitTx(ctx, hash); r.Status != TxPending {
+		t.Fatalf("the transaction should still be pending, got %+v", r)
+	}
+
+	done := make(chan TxReceipt)
+	go func() {
+		done <- n.WaitTx(context.Background(), hash)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	n.trackCommitted(5, [][]byte{tx})
+	select {
+	case r := <-done:
+		if r.Status != TxCommitted || r.Block != 5 || r.Event != n.core.Head() {
+			t.Fatalf("unexpected receipt of a committed transaction %+v", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the commit should release the waiter")
+	}
+	if len(n.txs.waiters) != 0 {
+		t.Fatalf("the waiters should be released, %d left", len(n.txs.waiters))
+	}
+}
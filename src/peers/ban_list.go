@@ -0,0 +1,121 @@
+package peers
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Ban is an entry of the BanList. Key is either the public key or the
+// network address (host or host:port) of the banned peer.
+type Ban struct {
+	Key     string
+	Reason  string
+	Expires time.Time
+}
+
+// BanList keeps temporary bans of peers, indexed by public key or address.
+// Expired bans are dropped lazily.
+type BanList struct {
+	sync.RWMutex
+	bans map[string]Ban
+}
+
+func NewBanList() *BanList {
+	return &BanList{
+		bans: make(map[string]Ban),
+	}
+}
+
+// Ban bans key for the given duration. Banning an already banned key
+// replaces the previous entry.
+func (b *BanList) Ban(key string, duration time.Duration, reason string) Ban {
+	b.Lock()
+	defer b.Unlock()
+
+	ban := Ban{
+		Key:     key,
+		Reason:  reason,
+		Expires: time.Now().Add(duration),
+	}
+	b.bans[key] = ban
+
+	return ban
+}
+
+// BanHost bans the host of the address addr, so that all its ports are
+// banned: those of the connections it opens are not the one it listens on
+func (b *BanList) BanHost(addr string, duration time.Duration, reason string) Ban {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return b.Ban(addr, duration, reason)
+}
+
+// Unban lifts the ban on key. It returns false if key was not banned.
+func (b *BanList) Unban(key string) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	if _, ok := b.bans[key]; !ok {
+		return false
+	}
+	delete(b.bans, key)
+
+	return true
+}
+
+// IsBanned returns true if key is banned. When key is a host:port address,
+// a ban on the host alone also applies.
+func (b *BanList) IsBanned(key string) bool {
+	if b.isBanned(key) {
+		return true
+	}
+	if host, _, err := net.SplitHostPort(key); err == nil {
+		return b.isBanned(host)
+	}
+	return false
+}
+
+// IsPeerBanned returns true if either the public key or the address of the
+// peer is banned
+func (b *BanList) IsPeerBanned(peer *Peer) bool {
+	return b.IsBanned(peer.PubKeyHex) || b.IsBanned(peer.NetAddr)
+}
+
+func (b *BanList) isBanned(key string) bool {
+	b.RLock()
+	ban, ok := b.bans[key]
+	b.RUnlock()
+
+	if !ok {
+		return false
+	}
+	if time.Now().After(ban.Expires) {
+		b.Unban(key)
+		return false
+	}
+	return true
+}
+
+// ToSlice returns the active bans, sorted by key
+func (b *BanList) ToSlice() []Ban {
+	b.Lock()
+	defer b.Unlock()
+
+	now := time.Now()
+	res := []Ban{}
+	for key, ban := range b.bans {
+		if now.After(ban.Expires) {
+			delete(b.bans, key)
+			continue
+		}
+		res = append(res, ban)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Key < res[j].Key
+	})
+
+	return res
+}
@@ -0,0 +1,56 @@
+package peers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBanList(t *testing.T) {
+	bans := NewBanList()
+
+	bans.Ban("0xABCD", time.Hour, "test")
+	bans.Ban("10.0.0.1", time.Hour, "test")
+	bans.Ban("expired", -time.Second, "test")
+
+	if !bans.IsBanned("0xABCD") {
+		t.Fatal("0xABCD should be banned")
+	}
+	if !bans.IsBanned("10.0.0.1:1337") {
+		t.Fatal("a ban on a host should apply to all its ports")
+	}
+	if bans.IsBanned("10.0.0.2:1337") {
+		t.Fatal("10.0.0.2 should not be banned")
+	}
+	if bans.IsBanned("expired") {
+		t.Fatal("expired ban should not apply")
+	}
+
+	peer := &Peer{PubKeyHex: "0x1234", NetAddr: "10.0.0.1:1338"}
+	if !bans.IsPeerBanned(peer) {
+		t.Fatal("peer with banned address should be banned")
+	}
+
+	if l := len(bans.ToSlice()); l != 2 {
+		t.Fatalf("expected 2 active bans, got %d", l)
+	}
+
+	// the inbound connections of a peer come from other ports than the one
+	// it listens on
+	bans.BanHost("10.0.0.3:1337", time.Hour, "test")
+	if !bans.IsBanned("10.0.0.3") || !bans.IsBanned("10.0.0.3:52011") {
+		t.Fatal("BanHost should ban the host of the address")
+	}
+	if !bans.Unban("10.0.0.3") {
+		t.Fatal("BanHost should ban the host only")
+	}
+
+	if !bans.Unban("0xABCD") {
+		t.Fatal("Unban should return true for a banned key")
+	}
+	if bans.IsBanned("0xABCD") {
+		t.Fatal("0xABCD should not be banned anymore")
+	}
+	if bans.Unban("0xABCD") {
+		t.Fatal("Unban should return false for a key which is not banned")
+	}
+}
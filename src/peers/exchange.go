@@ -0,0 +1,124 @@
+package peers
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"fmt"
+	"sort"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+// PeerExchange is a signed digest of the peer records known to a node. It is
+// piggybacked on sync responses so that newly admitted peers and updated
+// addresses propagate through gossip.
+type PeerExchange struct {
+	Peers []*Peer
+	// Nonce is the challenge of a discovery probe, signed along with the
+	// records to prove the signer answers at the probed address
+	Nonce     []byte `json:",omitempty"`
+	Signature string
+}
+
+// NewPeerExchange builds a PeerExchange from a list of peers and signs it
+// with the given key.
+func NewPeerExchange(source []*Peer, key *ecdsa.PrivateKey) (*PeerExchange, error) {
+	records := make([]*Peer, len(source))
+	for i, p := range source {
+		records[i] = &Peer{
+			ID:        p.ID,
+			NetAddr:   p.NetAddr,
+			PubKeyHex: p.PubKeyHex,
+			Tier:      p.Tier,
+		}
+	}
+	sort.Sort(ByID(records))
+
+	pe := &PeerExchange{
+		Peers: records,
+	}
+	if err := pe.Sign(key); err != nil {
+		return nil, err
+	}
+	return pe, nil
+}
+
+// Hash returns the digest of the peer records. The Used counter is local
+// selection state and is not part of the digest.
+func (pe *PeerExchange) Hash() []byte {
+	var buf bytes.Buffer
+	for _, p := range pe.Peers {
+		fmt.Fprintf(&buf, "%d|%s|%s|%s\n", p.ID, p.PubKeyHex, p.NetAddr, p.Tier)
+	}
+	if len(pe.Nonce) > 0 {
+		fmt.Fprintf(&buf, "nonce|%x\n", pe.Nonce)
+	}
+	return crypto.SHA256(buf.Bytes())
+}
+
+// Sign signs the digest with the given key.
+func (pe *PeerExchange) Sign(key *ecdsa.PrivateKey) error {
+	r, s, err := crypto.Sign(key, pe.Hash())
+	if err != nil {
+		return err
+	}
+	pe.Signature = crypto.EncodeSignature(r, s)
+	return nil
+}
+
+// Verify checks that the digest was signed by the owner of pubKeyHex.
+func (pe *PeerExchange) Verify(pubKeyHex string) (bool, error) {
+	signer := &Peer{PubKeyHex: pubKeyHex}
+	pubBytes, err := signer.PubKeyBytes()
+	if err != nil {
+		return false, err
+	}
+	pubKey := crypto.ToECDSAPub(pubBytes)
+	if pubKey == nil {
+		return false, fmt.Errorf("invalid public key %s", pubKeyHex)
+	}
+
+	r, s, err := crypto.DecodeSignature(pe.Signature)
+	if err != nil {
+		return false, err
+	}
+	return crypto.Verify(pubKey, pe.Hash(), r, s), nil
+}
+
+// Merge applies the records of a verified PeerExchange, signed by the owner
+// of signer, to the peer set. Unknown peers are added as ephemeral: a record
+// only makes them known, and never a consensus member whatever its tier. A
+// known peer gets its address updated only from its own record, signed with
+// its own key. It returns the peers that were added and the peers whose
+// address changed, stopping at the first error returned by a membership hook.
+func (p *Peers) Merge(pe *PeerExchange, signer string) (added []*Peer, updated []*Peer, err error) {
+	for _, record := range pe.Peers {
+		if len(record.PubKeyHex) < 3 {
+			continue
+		}
+
+		known, ok := p.PeerByPubKey(record.PubKeyHex)
+
+		if !ok {
+			peer := NewPeer(record.PubKeyHex, record.NetAddr)
+			peer.Tier = TierEphemeral
+			if err := p.AddPeer(peer); err != nil {
+				return added, updated, err
+			}
+			added = append(added, peer)
+			continue
+		}
+
+		if record.PubKeyHex != signer {
+			continue
+		}
+		changed, err := p.UpdatePeerAddr(known.PubKeyHex, record.NetAddr)
+		if changed {
+			updated = append(updated, known)
+		}
+		if err != nil {
+			return added, updated, err
+		}
+	}
+	return added, updated, nil
+}
@@ -0,0 +1,103 @@
+package peers
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"testing"
+
+	scrypto "github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func TestPeerExchange(t *testing.T) {
+	local := NewPeers()
+	remote := NewPeers()
+	var keys []*ecdsa.PrivateKey
+	for i := 0; i < 3; i++ {
+		key, _ := scrypto.GenerateECDSAKey()
+		keys = append(keys, key)
+		pubKeyHex := fmt.Sprintf("0x%X", scrypto.FromECDSAPub(&key.PublicKey))
+		remote.AddPeer(NewPeer(pubKeyHex, fmt.Sprintf("addr%d", i)))
+		if i < 2 {
+			local.AddPeer(NewPeer(pubKeyHex, fmt.Sprintf("old%d", i)))
+		}
+	}
+
+	signerKey, _ := scrypto.GenerateECDSAKey()
+	signerPubKey := fmt.Sprintf("0x%X", scrypto.FromECDSAPub(&signerKey.PublicKey))
+
+	pe, err := NewPeerExchange(remote.ToPeerSlice(), signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid, err := pe.Verify(signerPubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid {
+		t.Fatal("peer exchange signature should be valid")
+	}
+
+	// Tampering with a record must invalidate the signature
+	pe.Peers[0].NetAddr = "evil"
+	valid, err = pe.Verify(signerPubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid {
+		t.Fatal("tampered peer exchange should not verify")
+	}
+
+	pe, err = NewPeerExchange(remote.ToPeerSlice(), signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a third party makes the unknown peer known as ephemeral, and does not
+	// move the known ones
+	added, updated, err := local.Merge(pe, signerPubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 1 || !added[0].IsEphemeral() {
+		t.Fatalf("expected 1 added ephemeral peer, got %v", added)
+	}
+	if len(updated) != 0 {
+		t.Fatalf("expected no updated peer, got %d", len(updated))
+	}
+	if local.Len() != 3 {
+		t.Fatalf("expected 3 peers, got %d", local.Len())
+	}
+
+	// a known peer moves itself
+	pe, err = NewPeerExchange(remote.ToPeerSlice(), keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := fmt.Sprintf("0x%X", scrypto.FromECDSAPub(&keys[0].PublicKey))
+	added, updated, err = local.Merge(pe, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 0 || len(updated) != 1 || updated[0].PubKeyHex != owner {
+		t.Fatalf("expected only the signer to be updated, got %v added and %v updated", added, updated)
+	}
+	for _, peer := range remote.ToPeerSlice() {
+		addr := local.ByPubKey[peer.PubKeyHex].NetAddr
+		if peer.PubKeyHex == owner && addr != peer.NetAddr {
+			t.Fatalf("peer %d NetAddr should be %s, not %s", peer.ID, peer.NetAddr, addr)
+		}
+		if peer.PubKeyHex != owner && addr == peer.NetAddr && local.ByPubKey[peer.PubKeyHex].IsValidator() {
+			t.Fatalf("peer %d NetAddr should not be changed by another peer", peer.ID)
+		}
+	}
+
+	// Merging the same digest again is a no-op
+	added, updated, err = local.Merge(pe, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 0 || len(updated) != 0 {
+		t.Fatalf("second merge should not change anything")
+	}
+}
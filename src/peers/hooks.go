@@ -0,0 +1,98 @@
+package peers
+
+import (
+	"sort"
+)
+
+// Hook priorities. Hooks run in ascending priority so that components
+// depending on others observe membership changes after them.
+const (
+	// PriorityStore is used by the poset stores (roots, caches)
+	PriorityStore = 100
+	// PriorityPoset is used by the poset (supermajority, trust count)
+	PriorityPoset = 200
+	// PriorityNode is used by the node (peer selector, reputation)
+	PriorityNode = 300
+	// PriorityTransport is used by the transports (connection pools)
+	PriorityTransport = 400
+	// PriorityService is used by the http service and persistence
+	PriorityService = 500
+)
+
+// PeerHook is called on membership changes. A returned error stops the
+// propagation of the change to the remaining hooks.
+type PeerHook func(*Peer) error
+
+type peerHook struct {
+	priority int
+	fn       PeerHook
+}
+
+type hookList []peerHook
+
+// add returns a new sorted list, leaving l untouched for concurrent emitters
+func (l hookList) add(priority int, fn PeerHook) hookList {
+	res := make(hookList, len(l), len(l)+1)
+	copy(res, l)
+	res = append(res, peerHook{priority, fn})
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].priority < res[j].priority
+	})
+	return res
+}
+
+// PeerHooks holds the ordered hooks of a peer set
+type PeerHooks struct {
+	added   hookList
+	removed hookList
+	updated hookList
+}
+
+// OnPeerAdded registers a hook called after a peer joined the set
+func (p *Peers) OnPeerAdded(priority int, fn PeerHook) {
+	p.hooksLock.Lock()
+	defer p.hooksLock.Unlock()
+	p.hooks.added = p.hooks.added.add(priority, fn)
+}
+
+// OnPeerRemoved registers a hook called after a peer left the set
+func (p *Peers) OnPeerRemoved(priority int, fn PeerHook) {
+	p.hooksLock.Lock()
+	defer p.hooksLock.Unlock()
+	p.hooks.removed = p.hooks.removed.add(priority, fn)
+}
+
+// OnPeerUpdated registers a hook called after the record of a peer changed
+func (p *Peers) OnPeerUpdated(priority int, fn PeerHook) {
+	p.hooksLock.Lock()
+	defer p.hooksLock.Unlock()
+	p.hooks.updated = p.hooks.updated.add(priority, fn)
+}
+
+func (p *Peers) emit(hooks func(*PeerHooks) hookList, peer *Peer) error {
+	p.hooksLock.RLock()
+	list := hooks(&p.hooks)
+	p.hooksLock.RUnlock()
+
+	for _, h := range list {
+		if err := h.fn(peer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EmitPeerAdded runs the OnPeerAdded hooks
+func (p *Peers) EmitPeerAdded(peer *Peer) error {
+	return p.emit(func(h *PeerHooks) hookList { return h.added }, peer)
+}
+
+// EmitPeerRemoved runs the OnPeerRemoved hooks
+func (p *Peers) EmitPeerRemoved(peer *Peer) error {
+	return p.emit(func(h *PeerHooks) hookList { return h.removed }, peer)
+}
+
+// EmitPeerUpdated runs the OnPeerUpdated hooks
+func (p *Peers) EmitPeerUpdated(peer *Peer) error {
+	return p.emit(func(h *PeerHooks) hookList { return h.updated }, peer)
+}
@@ -0,0 +1,55 @@
+package peers
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPeerHooksOrder(t *testing.T) {
+	participants := NewPeers()
+
+	var calls []string
+	hook := func(name string, err error) PeerHook {
+		return func(*Peer) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	participants.OnPeerAdded(PriorityService, hook("service", nil))
+	participants.OnPeerAdded(PriorityPoset, hook("poset", nil))
+	participants.OnPeerAdded(PriorityStore, hook("store", nil))
+	participants.OnPeerAdded(PriorityPoset, hook("poset2", nil))
+
+	if err := participants.AddPeer(&Peer{ID: 1, PubKeyHex: "0x01", NetAddr: "addr1"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "[store poset poset2 service]"
+	if got := fmt.Sprint(calls); got != expected {
+		t.Fatalf("hooks should run as %s, not %s", expected, got)
+	}
+
+	calls = nil
+	participants.OnPeerUpdated(PriorityNode, hook("node", fmt.Errorf("failure")))
+	participants.OnPeerUpdated(PriorityTransport, hook("transport", nil))
+
+	changed, err := participants.UpdatePeerAddr("0x01", "addr2")
+	if !changed {
+		t.Fatal("address should have changed")
+	}
+	if err == nil {
+		t.Fatal("hook error should be returned")
+	}
+	if got := fmt.Sprint(calls); got != "[node]" {
+		t.Fatalf("propagation should stop at the failing hook, got %s", got)
+	}
+
+	calls = nil
+	participants.OnPeerRemoved(PriorityPoset, hook("poset", nil))
+	if err := participants.RemovePeerByPubKey("0x01"); err != nil {
+		t.Fatal(err)
+	}
+	if participants.Len() != 0 || fmt.Sprint(calls) != "[poset]" {
+		t.Fatalf("peer should be removed and hooks called, got %s", fmt.Sprint(calls))
+	}
+}
@@ -3,6 +3,7 @@ package peers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -14,6 +15,8 @@ import (
 type JSONPeers struct {
 	l    sync.Mutex
 	path string
+	// signed is the content of a signed file, see SignedPeerSet
+	signed *SignedPeerSet
 }
 
 // NewJSONPeers creates a new JSONPeers store.
@@ -44,6 +47,25 @@ func (j *JSONPeers) Peers() (*Peers, error) {
 		f.Close()
 	}
 
+	// A signed peer set is only used once verified
+	if trimmed := bytes.TrimSpace(buf); len(trimmed) > 0 && trimmed[0] == '{' {
+		var signed SignedPeerSet
+		if err := json.Unmarshal(trimmed, &signed); err != nil {
+			return nil, err
+		}
+		if err := signed.Verify(); err != nil {
+			return nil, fmt.Errorf("%s: %s", j.path, err)
+		}
+		// the addresses are signed as they are
+		for _, p := range signed.Peers {
+			if _, err := NormalizeNetAddr(p.NetAddr); err != nil {
+				return nil, fmt.Errorf("%s: peer %s: %s", j.path, p.PubKeyHex, err)
+			}
+		}
+		j.signed = &signed
+		return NewPeersFromSlice(signed.Peers), nil
+	}
+
 	// Decode the peers
 	peerSet := make([]*Peer, len(buf))
 	if len(buf) > 0 {
@@ -52,15 +74,79 @@ func (j *JSONPeers) Peers() (*Peers, error) {
 			return nil, err
 		}
 	}
+	for _, p := range peerSet {
+		addr, err := NormalizeNetAddr(p.NetAddr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: peer %s: %s", j.path, p.PubKeyHex, err)
+		}
+		p.NetAddr = addr
+	}
+	if err := ValidateWeights(peerSet); err != nil {
+		return nil, fmt.Errorf("%s: %s", j.path, err)
+	}
 
 	return NewPeersFromSlice(peerSet), nil
 }
 
-// SetPeers implements the PeerStore interface.
+// Signed returns the verified peer set of a signed file, nil when the file
+// is a plain list or was not read yet
+func (j *JSONPeers) Signed() *SignedPeerSet {
+	j.l.Lock()
+	defer j.l.Unlock()
+	return j.signed
+}
+
+// ReadSigned reads the file as a signed peer set without verifying it, to
+// collect signatures. A plain list is returned unsigned.
+func (j *JSONPeers) ReadSigned() (*SignedPeerSet, error) {
+	j.l.Lock()
+	defer j.l.Unlock()
+
+	buf, err := ioutil.ReadFile(j.path)
+	if err != nil {
+		return nil, err
+	}
+	buf = bytes.TrimSpace(buf)
+	if len(buf) > 0 && buf[0] == '{' {
+		var signed SignedPeerSet
+		if err := json.Unmarshal(buf, &signed); err != nil {
+			return nil, err
+		}
+		return &signed, nil
+	}
+	var list []*Peer
+	if err := json.Unmarshal(buf, &list); err != nil {
+		return nil, err
+	}
+	return NewSignedPeerSet(list), nil
+}
+
+// SetSigned writes a signed peer set
+func (j *JSONPeers) SetSigned(signed *SignedPeerSet) error {
+	j.l.Lock()
+	defer j.l.Unlock()
+
+	buf, err := json.MarshalIndent(signed, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(j.path, buf, 0640); err != nil {
+		return err
+	}
+	j.signed = signed
+	return nil
+}
+
+// SetPeers implements the PeerStore interface. A signed file is the genesis
+// peer set and is not overwritten.
 func (j *JSONPeers) SetPeers(peers []*Peer) error {
 	j.l.Lock()
 	defer j.l.Unlock()
 
+	if j.signed != nil {
+		return fmt.Errorf("%s is signed and cannot be changed", j.path)
+	}
+
 	var buf bytes.Buffer
 	enc := json.NewEncoder(&buf)
 	if err := enc.Encode(peers); err != nil {
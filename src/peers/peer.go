@@ -2,14 +2,30 @@ package peers
 
 import (
 	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
 
 	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
 )
 
 const (
 	jsonPeerPath = "peers.json"
 )
 
+// Peer tiers. A peer without tier is a validator.
+const (
+	// TierValidator peers are consensus members
+	TierValidator = "validator"
+	// TierPersistent peers are validators the node always keeps connected to
+	TierPersistent = "persistent"
+	// TierEphemeral peers are observers or relays which do not take part
+	// in consensus: they neither create events nor sign blocks
+	TierEphemeral = "ephemeral"
+)
+
 func NewPeer(pubKeyHex, netAddr string) *Peer {
 	peer := &Peer{
 		PubKeyHex: pubKeyHex,
@@ -28,6 +44,94 @@ func (this *Peer) Equals(that *Peer) bool {
 		this.PubKeyHex == that.PubKeyHex
 }
 
+// MarkUsed counts a gossip with the peer. The peer selectors of the nodes
+// of a process may share the peer, so the counter is updated atomically.
+func (p *Peer) MarkUsed() {
+	atomic.AddInt64(&p.Used, 1)
+}
+
+// UsedCount returns the number of gossips with the peer
+func (p *Peer) UsedCount() int64 {
+	return atomic.LoadInt64(&p.Used)
+}
+
+// TierOrDefault returns the tier of the peer, defaulting to TierValidator
+func (p *Peer) TierOrDefault() string {
+	if p.Tier == "" {
+		return TierValidator
+	}
+	return p.Tier
+}
+
+// IsPersistent returns true if the node should always keep connected to the peer
+func (p *Peer) IsPersistent() bool {
+	return p.Tier == TierPersistent
+}
+
+// IsEphemeral returns true if the peer is an observer or a relay
+func (p *Peer) IsEphemeral() bool {
+	return p.Tier == TierEphemeral
+}
+
+// IsValidator returns true if the peer is a consensus member
+func (p *Peer) IsValidator() bool {
+	return !p.IsEphemeral()
+}
+
+// Weight returns the weight of the peer in consensus: its stake, 1 when it
+// has none, and 0 for the peers which are not validators
+func (p *Peer) Weight() uint64 {
+	switch {
+	case !p.IsValidator():
+		return 0
+	case p.Stake == 0:
+		return 1
+	default:
+		return p.Stake
+	}
+}
+
+// CheckKeyType returns an error when the peer is tagged with another key type
+// than t, the one of the network, or its public key is not a key of type t.
+// The peers without tag have keys of the type of the network.
+func (p *Peer) CheckKeyType(t crypto.KeyType) error {
+	if p.KeyType != "" {
+		tagged, err := crypto.ParseKeyType(p.KeyType)
+		if err != nil {
+			return fmt.Errorf("peer %s: %s", p.PubKeyHex, err)
+		}
+		if tagged != t {
+			return fmt.Errorf("peer %s has a %s key, the network uses %s keys", p.PubKeyHex, tagged, t)
+		}
+	}
+	pubKey, err := p.PubKeyBytes()
+	if err != nil {
+		return fmt.Errorf("peer %s: %s", p.PubKeyHex, err)
+	}
+	if key := crypto.ToPublicKey(t, pubKey); key == nil || key.X == nil {
+		return fmt.Errorf("the public key of peer %s is not a %s key", p.PubKeyHex, t)
+	}
+	return nil
+}
+
+// NormalizeNetAddr returns a peer address with its IPv6 host in canonical
+// form, e.g. [2001:db8::1]:1337, so that the addresses of a peer compare
+// equal. An IPv6 address without brackets is refused, its port being
+// ambiguous; the other addresses are returned as they are.
+func NormalizeNetAddr(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		if strings.Count(addr, ":") > 1 && !strings.HasPrefix(addr, "[") {
+			return "", fmt.Errorf("the IPv6 address %q must be in brackets, e.g. [2001:db8::1]:1337", addr)
+		}
+		return addr, nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		return net.JoinHostPort(ip.String(), port), nil
+	}
+	return addr, nil
+}
+
 func (p *Peer) PubKeyBytes() ([]byte, error) {
 	return hex.DecodeString(p.PubKeyHex[2:])
 }
@@ -32,6 +32,9 @@ type Peer struct {
 	NetAddr   string `protobuf:"bytes,2,opt,name=NetAddr,json=netAddr" json:"NetAddr,omitempty"`
 	PubKeyHex string `protobuf:"bytes,3,opt,name=PubKeyHex,json=pubKeyHex" json:"PubKeyHex,omitempty"`
 	Used      int64  `protobuf:"varint,4,opt,name=used" json:"used,omitempty"`
+	Tier      string `protobuf:"bytes,5,opt,name=Tier,json=tier" json:"Tier,omitempty"`
+	KeyType   string `protobuf:"bytes,6,opt,name=KeyType,json=keyType" json:"KeyType,omitempty"`
+	Stake     uint64 `protobuf:"varint,7,opt,name=Stake,json=stake" json:"Stake,omitempty"`
 }
 
 func (m *Peer) Reset()                    { *m = Peer{} }
@@ -67,6 +70,27 @@ func (m *Peer) GetUsed() int64 {
 	return 0
 }
 
+func (m *Peer) GetTier() string {
+	if m != nil {
+		return m.Tier
+	}
+	return ""
+}
+
+func (m *Peer) GetKeyType() string {
+	if m != nil {
+		return m.KeyType
+	}
+	return ""
+}
+
+func (m *Peer) GetStake() uint64 {
+	if m != nil {
+		return m.Stake
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Peer)(nil), "peers.Peer")
 }
@@ -74,13 +98,16 @@ func init() {
 func init() { proto.RegisterFile("peer.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 126 bytes of a gzipped FileDescriptorProto
+	// 175 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x2a, 0x48, 0x4d, 0x2d,
-	0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x05, 0xb1, 0x8b, 0x95, 0x92, 0xb8, 0x58, 0x02,
-	0x52, 0x53, 0x8b, 0x84, 0xf8, 0xb8, 0x98, 0x3c, 0x5d, 0x24, 0x18, 0x15, 0x18, 0x35, 0x98, 0x83,
-	0x98, 0x32, 0x5d, 0x84, 0x24, 0xb8, 0xd8, 0xfd, 0x52, 0x4b, 0x1c, 0x53, 0x52, 0x8a, 0x24, 0x98,
-	0x14, 0x18, 0x35, 0x38, 0x83, 0xd8, 0xf3, 0x20, 0x5c, 0x21, 0x19, 0x2e, 0xce, 0x80, 0xd2, 0x24,
-	0xef, 0xd4, 0x4a, 0x8f, 0xd4, 0x0a, 0x09, 0x66, 0xb0, 0x1c, 0x67, 0x01, 0x4c, 0x40, 0x48, 0x88,
-	0x8b, 0xa5, 0xb4, 0x38, 0x35, 0x45, 0x82, 0x05, 0x6c, 0x12, 0x98, 0x9d, 0xc4, 0x06, 0xb6, 0xd1,
-	0x18, 0x10, 0x00, 0x00, 0xff, 0xff, 0xf1, 0x92, 0x1c, 0x0d, 0x7f, 0x00, 0x00, 0x00,
+	0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x05, 0xb1, 0x8b, 0x95, 0x96, 0x31, 0x72, 0xb1,
+	0x04, 0xa4, 0xa6, 0x16, 0x09, 0xf1, 0x71, 0x31, 0x79, 0xba, 0x48, 0x30, 0x2a, 0x30, 0x6a, 0x30,
+	0x07, 0x31, 0x65, 0xba, 0x08, 0x49, 0x70, 0xb1, 0xfb, 0xa5, 0x96, 0x38, 0xa6, 0xa4, 0x14, 0x49,
+	0x30, 0x29, 0x30, 0x6a, 0x70, 0x06, 0xb1, 0xe7, 0x41, 0xb8, 0x42, 0x32, 0x5c, 0x9c, 0x01, 0xa5,
+	0x49, 0xde, 0xa9, 0x95, 0x1e, 0xa9, 0x15, 0x12, 0xcc, 0x60, 0x39, 0xce, 0x02, 0x98, 0x80, 0x90,
+	0x10, 0x17, 0x4b, 0x69, 0x71, 0x6a, 0x8a, 0x04, 0x0b, 0xd8, 0x24, 0x30, 0x1b, 0x24, 0x16, 0x92,
+	0x99, 0x5a, 0x24, 0xc1, 0x0a, 0x56, 0xcc, 0x52, 0x92, 0x99, 0x5a, 0x04, 0x32, 0xdf, 0x3b, 0xb5,
+	0x32, 0xa4, 0xb2, 0x20, 0x55, 0x82, 0x0d, 0x62, 0x7e, 0x36, 0x84, 0x2b, 0x24, 0xc2, 0xc5, 0x1a,
+	0x5c, 0x92, 0x98, 0x9d, 0x2a, 0xc1, 0xae, 0xc0, 0xa8, 0xc1, 0x12, 0xc4, 0x5a, 0x0c, 0xe2, 0x24,
+	0xb1, 0x81, 0x9d, 0x6d, 0x0c, 0x18, 0x00, 0x39, 0xcc, 0xb2, 0x4b, 0xc4, 0x00, 0x00, 0x00,
 }
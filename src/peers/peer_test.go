@@ -81,3 +81,43 @@ func TestJSONPeers(t *testing.T) {
 		}
 	}
 }
+
+func TestPeerKeyType(t *testing.T) {
+	key, err := scrypto.GenerateKey(scrypto.KeyEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer := NewPeer(fmt.Sprintf("0x%X", scrypto.FromECDSAPub(&key.PublicKey)), "127.0.0.1:1337")
+
+	if err := peer.CheckKeyType(scrypto.KeyEd25519); err != nil {
+		t.Fatal(err)
+	}
+	if err := peer.CheckKeyType(scrypto.KeyP256); err == nil {
+		t.Fatal("an ed25519 key should not be a p256 key")
+	}
+	peer.KeyType = string(scrypto.KeySecp256k1)
+	if err := peer.CheckKeyType(scrypto.KeyEd25519); err == nil {
+		t.Fatal("a peer tagged secp256k1 should not be in an ed25519 network")
+	}
+}
+
+func TestNormalizeNetAddr(t *testing.T) {
+	for addr, expected := range map[string]string{
+		"127.0.0.1:1337":               "127.0.0.1:1337",
+		"node0.example.com:1337":       "node0.example.com:1337",
+		"[::1]:1337":                   "[::1]:1337",
+		"[2001:DB8:0:0::1]:1337":       "[2001:db8::1]:1337",
+		"[::ffff:10.0.0.1]:1337":       "[::ffff:10.0.0.1]:1337",
+		"addr0":                        "addr0",
+		"[fe80::1%eth0]:1337":          "[fe80::1%eth0]:1337",
+		"[2001:db8:0:0:0:0:0:2]:12000": "[2001:db8::2]:12000",
+	} {
+		res, err := NormalizeNetAddr(addr)
+		if err != nil || res != expected {
+			t.Fatalf("%s should be normalized to %s, got %s %v", addr, expected, res, err)
+		}
+	}
+	if _, err := NormalizeNetAddr("2001:db8::1:1337"); err == nil {
+		t.Fatal("an IPv6 address without brackets should be refused")
+	}
+}
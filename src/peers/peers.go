@@ -7,14 +7,15 @@ import (
 
 type PubKeyPeers map[string]*Peer
 type IdPeers map[int64]*Peer
-type Listener func(*Peer)
 
 type Peers struct {
 	sync.RWMutex
-	Sorted    []*Peer
-	ByPubKey  PubKeyPeers
-	ById      IdPeers
-	Listeners []Listener
+	Sorted   []*Peer
+	ByPubKey PubKeyPeers
+	ById     IdPeers
+
+	hooks     PeerHooks
+	hooksLock sync.RWMutex
 }
 
 /* Constructors */
@@ -53,12 +54,13 @@ func (p *Peers) addPeerRaw(peer *Peer) {
 	p.ById[peer.ID] = peer
 }
 
-func (p *Peers) AddPeer(peer *Peer) {
+// AddPeer adds a peer to the set and runs the OnPeerAdded hooks
+func (p *Peers) AddPeer(peer *Peer) error {
 	p.Lock()
 	p.addPeerRaw(peer)
 	p.internalSort()
 	p.Unlock()
- 	p.EmitNewPeer(peer)
+	return p.EmitPeerAdded(peer)
 }
 
 func (p *Peers) internalSort() {
@@ -73,37 +75,110 @@ func (p *Peers) internalSort() {
 	p.Sorted = res
 }
 
+// UpdatePeerAddr changes the network address of a known peer. It returns
+// true if the peer exists and its address was changed, in which case the
+// OnPeerUpdated hooks are run.
+func (p *Peers) UpdatePeerAddr(pubKey, netAddr string) (bool, error) {
+	p.Lock()
+	peer, ok := p.ByPubKey[pubKey]
+	if !ok || netAddr == "" || peer.NetAddr == netAddr {
+		p.Unlock()
+		return false, nil
+	}
+	peer.NetAddr = netAddr
+	p.Unlock()
+
+	return true, p.EmitPeerUpdated(peer)
+}
+
 /* Remove Methods */
 
-func (p *Peers) RemovePeer(peer *Peer) {
+// RemovePeer removes a peer from the set and runs the OnPeerRemoved hooks
+func (p *Peers) RemovePeer(peer *Peer) error {
 	p.Lock()
-	defer p.Unlock()
-
+	if peer == nil {
+		p.Unlock()
+		return nil
+	}
 	if _, ok := p.ByPubKey[peer.PubKeyHex]; !ok {
-		return
+		p.Unlock()
+		return nil
 	}
 
 	delete(p.ByPubKey, peer.PubKeyHex)
 	delete(p.ById, peer.ID)
 
 	p.internalSort()
+	p.Unlock()
+
+	return p.EmitPeerRemoved(peer)
+}
+
+func (p *Peers) RemovePeerByPubKey(pubKey string) error {
+	p.RLock()
+	peer := p.ByPubKey[pubKey]
+	p.RUnlock()
+	return p.RemovePeer(peer)
+}
+
+func (p *Peers) RemovePeerById(id int64) error {
+	p.RLock()
+	peer := p.ById[id]
+	p.RUnlock()
+	return p.RemovePeer(peer)
+}
+
+/* Lookup Methods */
+
+// PeerByPubKey returns the peer with the given public key. The peers being
+// added and removed at runtime, the readers which do not hold the lock use
+// it rather than the ByPubKey map.
+func (p *Peers) PeerByPubKey(pubKey string) (*Peer, bool) {
+	p.RLock()
+	defer p.RUnlock()
+
+	peer, ok := p.ByPubKey[pubKey]
+	return peer, ok
 }
 
-func (p *Peers) RemovePeerByPubKey(pubKey string) {
-	p.RemovePeer(p.ByPubKey[pubKey])
+// PeerByID returns the peer with the given ID, see PeerByPubKey
+func (p *Peers) PeerByID(id int64) (*Peer, bool) {
+	p.RLock()
+	defer p.RUnlock()
+
+	peer, ok := p.ById[id]
+	return peer, ok
 }
 
-func (p *Peers) RemovePeerById(id int64) {
-	p.RemovePeer(p.ById[id])
+// PeerByAddr returns the peer with the given network address, see
+// PeerByPubKey
+func (p *Peers) PeerByAddr(addr string) (*Peer, bool) {
+	p.RLock()
+	defer p.RUnlock()
+
+	for _, peer := range p.Sorted {
+		if peer.NetAddr == addr {
+			return peer, true
+		}
+	}
+	return nil, false
 }
 
 /* ToSlice Methods */
 
+// ToPeerSlice returns the peers sorted by ID. The slice is replaced, never
+// modified, when the set changes.
 func (p *Peers) ToPeerSlice() []*Peer {
+	p.RLock()
+	defer p.RUnlock()
+
 	return p.Sorted
 }
 
 func (p *Peers) ToPeerByUsedSlice() []*Peer {
+	p.RLock()
+	defer p.RUnlock()
+
 	res := []*Peer{}
 
 	for _, p := range p.ByPubKey {
@@ -140,17 +215,21 @@ func (p *Peers) ToIDSlice() []int64 {
 	return res
 }
 
-/* EventListener */
+// ToTierSlice returns the peers of the given tier
+func (p *Peers) ToTierSlice(tier string) []*Peer {
+	p.RLock()
+	defer p.RUnlock()
 
-func (p *Peers) OnNewPeer(cb func(*Peer)) {
-	p.Listeners = append(p.Listeners, cb)
-}
-func (p *Peers) EmitNewPeer(peer *Peer) {
-	for _, listener := range p.Listeners {
-		listener(peer)
+	res := []*Peer{}
+
+	for _, peer := range p.Sorted {
+		if peer.TierOrDefault() == tier {
+			res = append(res, peer)
+		}
 	}
-}
 
+	return res
+}
 
 /* Utilities */
 
@@ -188,7 +267,7 @@ type ByUsed []*Peer
 func (a ByUsed) Len() int      { return len(a) }
 func (a ByUsed) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a ByUsed) Less(i, j int) bool {
-	ai := a[i].Used
-	aj := a[j].Used
+	ai := a[i].UsedCount()
+	aj := a[j].UsedCount()
 	return ai > aj
 }
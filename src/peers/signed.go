@@ -0,0 +1,135 @@
+package peers
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+// PeerSetSignature is the signature of a peer set hash by one of its
+// participants
+type PeerSetSignature struct {
+	PubKeyHex string
+	Signature string
+}
+
+// SignedPeerSet is the signed form of peers.json: the genesis peer set, its
+// hash and the signatures of its participants. It is trusted once a
+// supermajority of its validators signed it.
+type SignedPeerSet struct {
+	Peers      []*Peer
+	Hash       string
+	Signatures []PeerSetSignature
+}
+
+// PeerSetHash returns the digest of a peer set: the public keys and tiers of
+// its peers, in public key order. Addresses are left out, so that nodes
+// listing their peers at different addresses share the same hash.
+func PeerSetHash(list []*Peer) []byte {
+	sorted := make([]*Peer, len(list))
+	copy(sorted, list)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].PubKeyHex < sorted[j].PubKeyHex
+	})
+	var buf bytes.Buffer
+	for _, p := range sorted {
+		// the stake is only hashed when set, for the hashes of the peer
+		// sets without stakes not to change
+		if p.Stake != 0 {
+			fmt.Fprintf(&buf, "%s|%s|%d\n", p.PubKeyHex, p.Tier, p.Stake)
+			continue
+		}
+		fmt.Fprintf(&buf, "%s|%s\n", p.PubKeyHex, p.Tier)
+	}
+	return crypto.SHA256(buf.Bytes())
+}
+
+// NewSignedPeerSet returns the unsigned form of a peer set
+func NewSignedPeerSet(list []*Peer) *SignedPeerSet {
+	return &SignedPeerSet{
+		Peers: list,
+		Hash:  fmt.Sprintf("0x%X", PeerSetHash(list)),
+	}
+}
+
+// Sign adds the signature of key, replacing a previous one of the same key
+func (sp *SignedPeerSet) Sign(key *ecdsa.PrivateKey) error {
+	hash, err := sp.hash()
+	if err != nil {
+		return err
+	}
+	r, s, err := crypto.Sign(key, hash)
+	if err != nil {
+		return err
+	}
+	sig := PeerSetSignature{
+		PubKeyHex: fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey)),
+		Signature: crypto.EncodeSignature(r, s),
+	}
+	for i, other := range sp.Signatures {
+		if other.PubKeyHex == sig.PubKeyHex {
+			sp.Signatures[i] = sig
+			return nil
+		}
+	}
+	sp.Signatures = append(sp.Signatures, sig)
+	return nil
+}
+
+// Verify checks that Hash is the hash of the peers and that it is signed by
+// a supermajority of their validators
+func (sp *SignedPeerSet) Verify() error {
+	hash, err := sp.hash()
+	if err != nil {
+		return err
+	}
+	if err := ValidateWeights(sp.Peers); err != nil {
+		return err
+	}
+	snapshot := NewPeersFromSlice(sp.Peers).Snapshot()
+
+	signed := make(map[string]bool)
+	var weight uint64
+	for _, sig := range sp.Signatures {
+		signer, ok := snapshot.ByPubKey(sig.PubKeyHex)
+		if !ok || !signer.IsValidator() || signed[sig.PubKeyHex] {
+			continue
+		}
+		pubBytes, err := signer.PubKeyBytes()
+		if err != nil {
+			continue
+		}
+		pubKey := crypto.ToECDSAPub(pubBytes)
+		r, s, err := crypto.DecodeSignature(sig.Signature)
+		if pubKey == nil || err != nil || !crypto.Verify(pubKey, hash, r, s) {
+			continue
+		}
+		signed[sig.PubKeyHex] = true
+		weight += signer.Weight()
+	}
+	if weight < snapshot.SuperMajorityWeight() {
+		return fmt.Errorf("peer set signed by %d validators of weight %d, %d required",
+			len(signed), weight, snapshot.SuperMajorityWeight())
+	}
+	return nil
+}
+
+// hash checks Hash against the peers and returns it decoded
+func (sp *SignedPeerSet) hash() ([]byte, error) {
+	expected := PeerSetHash(sp.Peers)
+	if len(sp.Hash) < 2 {
+		return nil, fmt.Errorf("peer set hash is missing")
+	}
+	hash, err := hex.DecodeString(sp.Hash[2:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid peer set hash %s: %s", sp.Hash, err)
+	}
+	if !bytes.Equal(hash, expected) {
+		return nil, fmt.Errorf("peer set hash %s does not match the peers, expected 0x%X", sp.Hash, expected)
+	}
+	return hash, nil
+}
@@ -0,0 +1,93 @@
+package peers
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func TestSignedPeerSet(t *testing.T) {
+	var keys []*ecdsa.PrivateKey
+	var list []*Peer
+	for i := 0; i < 4; i++ {
+		key, _ := crypto.GenerateECDSAKey()
+		keys = append(keys, key)
+		list = append(list, NewPeer(fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey)), fmt.Sprintf("addr%d", i)))
+	}
+
+	signed := NewSignedPeerSet(list)
+	for _, key := range keys[:2] {
+		if err := signed.Sign(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// signing twice with the same key does not count twice
+	signed.Sign(keys[0])
+	if err := signed.Verify(); err == nil {
+		t.Fatal("2 signatures out of 4 validators should not be enough")
+	}
+	signed.Sign(keys[2])
+	if err := signed.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the addresses are not part of the hash, the members are
+	moved := []*Peer{NewPeer(list[1].PubKeyHex, "elsewhere"), list[0], list[2], list[3]}
+	if fmt.Sprintf("0x%X", PeerSetHash(moved)) != signed.Hash {
+		t.Fatal("the hash should not depend on the addresses or the order of the peers")
+	}
+	signed.Peers = signed.Peers[:3]
+	if err := signed.Verify(); err == nil {
+		t.Fatal("a peer set not matching its hash should be rejected")
+	}
+}
+
+func TestJSONSignedPeers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signed-peers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var list []*Peer
+	var keys []*ecdsa.PrivateKey
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateECDSAKey()
+		keys = append(keys, key)
+		list = append(list, NewPeer(fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey)), fmt.Sprintf("addr%d", i)))
+	}
+	store := NewJSONPeers(dir)
+	if err := store.SetPeers(list); err != nil {
+		t.Fatal(err)
+	}
+
+	// collect the signatures in the file
+	for _, key := range keys {
+		signed, err := store.ReadSigned()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := signed.Sign(key); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetSigned(signed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader := NewJSONPeers(dir)
+	ps, err := reader.Peers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ps.Len() != 3 || reader.Signed() == nil {
+		t.Fatalf("expected the 3 peers of a signed file, got %d", ps.Len())
+	}
+	if err := reader.SetPeers(list[:2]); err == nil {
+		t.Fatal("a signed peers.json should not be overwritten")
+	}
+}
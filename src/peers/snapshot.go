@@ -0,0 +1,174 @@
+package peers
+
+import (
+	"fmt"
+	"math"
+)
+
+// MaxTotalWeight bounds the total weight of a peer set. It leaves room to
+// add up the weights of the validators, and to derive the supermajority
+// from the total, without overflowing.
+const MaxTotalWeight = math.MaxUint64 / 2
+
+// ValidateWeights returns an error if the total weight of the peers exceeds
+// MaxTotalWeight
+func ValidateWeights(peers []*Peer) error {
+	var total uint64
+	for _, p := range peers {
+		w := p.Weight()
+		if w > MaxTotalWeight-total {
+			return fmt.Errorf("the total weight of the validators exceeds %d", uint64(MaxTotalWeight))
+		}
+		total += w
+	}
+	return nil
+}
+
+// Snapshot is an immutable view of a peer set. Consensus code holds a
+// Snapshot while processing a round so that concurrent membership changes
+// don't alter the validator set, and thus the supermajority, underneath it.
+type Snapshot struct {
+	sorted     []*Peer
+	byPubKey   map[string]*Peer
+	byID       map[int64]*Peer
+	validators int
+	weight     uint64
+}
+
+// Snapshot returns an immutable copy of the current peer set
+func (p *Peers) Snapshot() *Snapshot {
+	p.RLock()
+	defer p.RUnlock()
+
+	s := &Snapshot{
+		sorted:   make([]*Peer, 0, len(p.Sorted)),
+		byPubKey: make(map[string]*Peer, len(p.Sorted)),
+		byID:     make(map[int64]*Peer, len(p.Sorted)),
+	}
+	for _, peer := range p.Sorted {
+		cp := &Peer{
+			ID:        peer.ID,
+			NetAddr:   peer.NetAddr,
+			PubKeyHex: peer.PubKeyHex,
+			Tier:      peer.Tier,
+			Stake:     peer.Stake,
+		}
+		s.sorted = append(s.sorted, cp)
+		s.byPubKey[cp.PubKeyHex] = cp
+		s.byID[cp.ID] = cp
+		if cp.IsValidator() {
+			s.validators++
+		}
+		// the peer sets are validated on load, the total saturates in case
+		// one is not
+		if w := cp.Weight(); w > MaxTotalWeight-s.weight {
+			s.weight = MaxTotalWeight
+		} else {
+			s.weight += w
+		}
+	}
+
+	return s
+}
+
+// Len returns the number of peers in the snapshot
+func (s *Snapshot) Len() int {
+	return len(s.sorted)
+}
+
+// ToPeerSlice returns the peers sorted by ID. The peers must not be modified.
+func (s *Snapshot) ToPeerSlice() []*Peer {
+	res := make([]*Peer, len(s.sorted))
+	copy(res, s.sorted)
+	return res
+}
+
+// ToPubKeySlice returns the public keys of the peers, sorted by ID
+func (s *Snapshot) ToPubKeySlice() []string {
+	res := make([]string, len(s.sorted))
+	for i, peer := range s.sorted {
+		res[i] = peer.PubKeyHex
+	}
+	return res
+}
+
+// ByPubKey returns the peer with the given public key
+func (s *Snapshot) ByPubKey(pubKey string) (*Peer, bool) {
+	peer, ok := s.byPubKey[pubKey]
+	return peer, ok
+}
+
+// ByID returns the peer with the given ID
+func (s *Snapshot) ByID(id int64) (*Peer, bool) {
+	peer, ok := s.byID[id]
+	return peer, ok
+}
+
+// Validators returns the number of consensus members in the snapshot.
+// Observers are not counted.
+func (s *Snapshot) Validators() int {
+	return s.validators
+}
+
+// IsValidator returns true if the public key belongs to a consensus member
+func (s *Snapshot) IsValidator(pubKey string) bool {
+	peer, ok := s.byPubKey[pubKey]
+	return ok && peer.IsValidator()
+}
+
+// SuperMajority returns the number of validators forming a supermajority
+// (more than 2/3) of the snapshot
+func (s *Snapshot) SuperMajority() int {
+	return 2*s.validators/3 + 1
+}
+
+// TrustCount returns the number of validators (at least 1/3) among which at
+// least one is honest
+func (s *Snapshot) TrustCount() int {
+	return int(math.Ceil(float64(s.validators) / float64(3)))
+}
+
+// Weight returns the weight of the peer with the given public key, 0 when it
+// is not a validator of the snapshot
+func (s *Snapshot) Weight(pubKey string) uint64 {
+	peer, ok := s.byPubKey[pubKey]
+	if !ok {
+		return 0
+	}
+	return peer.Weight()
+}
+
+// WeightByID returns the weight of the peer with the given ID, see Weight
+func (s *Snapshot) WeightByID(id int64) uint64 {
+	peer, ok := s.byID[id]
+	if !ok {
+		return 0
+	}
+	return peer.Weight()
+}
+
+// TotalWeight returns the sum of the weights of the validators
+func (s *Snapshot) TotalWeight() uint64 {
+	return s.weight
+}
+
+// Weighted returns true if some validators have a stake, the weights of the
+// validators being 1 otherwise
+func (s *Snapshot) Weighted() bool {
+	return s.weight != uint64(s.validators)
+}
+
+// SuperMajorityWeight returns the weight of the validators forming a
+// supermajority (more than 2/3 of the total weight) of the snapshot. Without
+// stakes it is SuperMajority. It is computed without overflowing, whatever
+// the stakes.
+func (s *Snapshot) SuperMajorityWeight() uint64 {
+	return s.weight/3*2 + s.weight%3*2/3 + 1
+}
+
+// TrustWeight returns the weight (at least 1/3 of the total) of the
+// validators among which at least one is honest. Without stakes it is
+// TrustCount.
+func (s *Snapshot) TrustWeight() uint64 {
+	return s.weight/3 + (s.weight%3+2)/3
+}
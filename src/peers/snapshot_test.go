@@ -0,0 +1,132 @@
+package peers
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestSnapshotIsImmutable(t *testing.T) {
+	participants := NewPeers()
+	for i := 0; i < 4; i++ {
+		participants.AddPeer(&Peer{
+			ID:        int64(i + 1),
+			NetAddr:   fmt.Sprintf("addr%d", i),
+			PubKeyHex: fmt.Sprintf("0x%02d", i),
+		})
+	}
+
+	snapshot := participants.Snapshot()
+	if snapshot.Len() != 4 || snapshot.SuperMajority() != 3 || snapshot.TrustCount() != 2 {
+		t.Fatalf("unexpected snapshot: len %d, superMajority %d, trustCount %d",
+			snapshot.Len(), snapshot.SuperMajority(), snapshot.TrustCount())
+	}
+
+	participants.AddPeer(&Peer{ID: 5, NetAddr: "addr4", PubKeyHex: "0x04"})
+	participants.UpdatePeerAddr("0x00", "changed")
+
+	if snapshot.Len() != 4 || snapshot.SuperMajority() != 3 {
+		t.Fatal("snapshot should not see membership changes")
+	}
+	if p, _ := snapshot.ByPubKey("0x00"); p.NetAddr != "addr0" {
+		t.Fatalf("snapshot should not see address changes, got %s", p.NetAddr)
+	}
+	if _, ok := snapshot.ByID(5); ok {
+		t.Fatal("snapshot should not contain the new peer")
+	}
+	if participants.Snapshot().SuperMajority() != 4 {
+		t.Fatal("new snapshot should include the new peer")
+	}
+}
+
+func TestSnapshotObservers(t *testing.T) {
+	participants := NewPeers()
+	tiers := []string{"", TierPersistent, TierValidator, "", TierEphemeral, TierEphemeral}
+	for i, tier := range tiers {
+		participants.AddPeer(&Peer{
+			ID:        int64(i + 1),
+			NetAddr:   fmt.Sprintf("addr%d", i),
+			PubKeyHex: fmt.Sprintf("0x%02d", i),
+			Tier:      tier,
+		})
+	}
+
+	snapshot := participants.Snapshot()
+	if snapshot.Len() != 6 || snapshot.Validators() != 4 {
+		t.Fatalf("expected 6 peers and 4 validators, got %d and %d",
+			snapshot.Len(), snapshot.Validators())
+	}
+	if snapshot.SuperMajority() != 3 || snapshot.TrustCount() != 2 {
+		t.Fatalf("observers should not count in thresholds: superMajority %d, trustCount %d",
+			snapshot.SuperMajority(), snapshot.TrustCount())
+	}
+	if snapshot.IsValidator("0x04") || !snapshot.IsValidator("0x01") || snapshot.IsValidator("0x99") {
+		t.Fatal("wrong validator status")
+	}
+}
+
+func TestSnapshotWeights(t *testing.T) {
+	participants := NewPeers()
+	stakes := []uint64{0, 0, 0, 0}
+	for i, stake := range stakes {
+		participants.AddPeer(&Peer{
+			ID:        int64(i + 1),
+			PubKeyHex: fmt.Sprintf("0x%02d", i),
+			Stake:     stake,
+		})
+	}
+	snapshot := participants.Snapshot()
+	if snapshot.Weighted() || snapshot.TotalWeight() != 4 {
+		t.Fatalf("peers without stakes should weigh 1 each, got a total of %d", snapshot.TotalWeight())
+	}
+	if int(snapshot.SuperMajorityWeight()) != snapshot.SuperMajority() ||
+		int(snapshot.TrustWeight()) != snapshot.TrustCount() {
+		t.Fatal("without stakes, the weighted thresholds should be the counts")
+	}
+
+	participants.AddPeer(&Peer{ID: 5, PubKeyHex: "0x04", Stake: 10})
+	participants.AddPeer(&Peer{ID: 6, PubKeyHex: "0x05", Stake: 100, Tier: TierEphemeral})
+	snapshot = participants.Snapshot()
+	if !snapshot.Weighted() || snapshot.TotalWeight() != 14 {
+		t.Fatalf("expected a weighted total of 14, got %d", snapshot.TotalWeight())
+	}
+	if snapshot.SuperMajorityWeight() != 10 || snapshot.TrustWeight() != 5 {
+		t.Fatalf("expected weighted thresholds 10 and 5, got %d and %d",
+			snapshot.SuperMajorityWeight(), snapshot.TrustWeight())
+	}
+	if snapshot.Weight("0x04") != 10 || snapshot.WeightByID(1) != 1 ||
+		snapshot.Weight("0x05") != 0 || snapshot.Weight("0x99") != 0 {
+		t.Fatal("wrong peer weights")
+	}
+
+	// the total of large stakes saturates instead of wrapping around, and
+	// its thresholds do not overflow
+	participants = NewPeers()
+	participants.AddPeer(&Peer{ID: 1, PubKeyHex: "0x00", Stake: 1 << 63})
+	participants.AddPeer(&Peer{ID: 2, PubKeyHex: "0x01", Stake: 1 << 63})
+	snapshot = participants.Snapshot()
+	if snapshot.TotalWeight() != MaxTotalWeight {
+		t.Fatalf("expected the total to saturate at %d, got %d", uint64(MaxTotalWeight), snapshot.TotalWeight())
+	}
+	if snapshot.SuperMajorityWeight() != MaxTotalWeight/3*2+1 || snapshot.TrustWeight() != MaxTotalWeight/3+1 {
+		t.Fatalf("wrong thresholds of the maximal weight, got %d and %d",
+			snapshot.SuperMajorityWeight(), snapshot.TrustWeight())
+	}
+}
+
+func TestValidateWeights(t *testing.T) {
+	ps := []*Peer{{PubKeyHex: "0x00", Stake: MaxTotalWeight - 1}, {PubKeyHex: "0x01"}}
+	if err := ValidateWeights(ps); err != nil {
+		t.Fatalf("a total of MaxTotalWeight should be accepted: %v", err)
+	}
+
+	ps = append(ps, &Peer{PubKeyHex: "0x02", Tier: TierEphemeral, Stake: math.MaxUint64})
+	if err := ValidateWeights(ps); err != nil {
+		t.Fatalf("observers should not count in the total: %v", err)
+	}
+
+	ps = []*Peer{{PubKeyHex: "0x00", Stake: 1 << 63}, {PubKeyHex: "0x01", Stake: 1 << 63}}
+	if err := ValidateWeights(ps); err == nil {
+		t.Fatal("a total wrapping around should be refused")
+	}
+}
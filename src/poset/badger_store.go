@@ -1,14 +1,21 @@
 package poset
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
+	"sync"
+	"time"
 
+	"github.com/Fantom-foundation/go-lachesis/src/chaos"
 	cm "github.com/Fantom-foundation/go-lachesis/src/common"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
 	"github.com/Fantom-foundation/go-lachesis/src/peers"
 	"github.com/dgraph-io/badger"
 	"github.com/golang/protobuf/proto"
+	"github.com/hashicorp/golang-lru"
 )
 
 const (
@@ -18,14 +25,31 @@ const (
 	topoPrefix        = "topo"
 	blockPrefix       = "block"
 	framePrefix       = "frame"
+	checkpointPrefix  = "checkpoint"
 )
 
+// ackedBlockKey holds the index of the last block acknowledged by the
+// application
+const ackedBlockKey = "acked_block"
+
+// txWindowKey holds the hashes of the transactions recently seen by the
+// node, in JSON
+const txWindowKey = "tx_window"
+
 type BadgerStore struct {
 	participants *peers.Peers
 	inmemStore   *InmemStore
 	db           *badger.DB
 	path         string
 	needBoostrap bool
+	// compression of the event, block and frame values written from now on
+	compression Compression
+	// batch is the transaction of the writes between BeginBatch and EndBatch
+	batch       *badger.Txn
+	batchWrites int
+	batchLock   sync.Mutex
+	// gc is the value log GC started by StartGC
+	gc gc
 }
 
 //NewBadgerStore creates a brand new Store with a new database
@@ -45,6 +69,9 @@ func NewBadgerStore(participants *peers.Peers, cacheSize int, path string) (*Bad
 		db:           handle,
 		path:         path,
 	}
+	if err := dbSetSchemaVersion(handle, SchemaVersion); err != nil {
+		return nil, err
+	}
 	if err := store.dbSetParticipants(participants); err != nil {
 		return nil, err
 	}
@@ -57,7 +84,8 @@ func NewBadgerStore(participants *peers.Peers, cacheSize int, path string) (*Bad
 	return store, nil
 }
 
-//LoadBadgerStore creates a Store from an existing database
+//LoadBadgerStore creates a Store from an existing database, migrating it to
+//SchemaVersion first
 func LoadBadgerStore(cacheSize int, path string) (*BadgerStore, error) {
 
 	if _, err := os.Stat(path); err != nil {
@@ -78,6 +106,11 @@ func LoadBadgerStore(cacheSize int, path string) (*BadgerStore, error) {
 		needBoostrap: true,
 	}
 
+	if _, err := migrateDB(handle, path, false); err != nil {
+		handle.Close()
+		return nil, err
+	}
+
 	participants, err := store.dbGetParticipants()
 	if err != nil {
 		return nil, err
@@ -108,6 +141,10 @@ func LoadBadgerStore(cacheSize int, path string) (*BadgerStore, error) {
 func LoadOrCreateBadgerStore(participants *peers.Peers, cacheSize int, path string) (*BadgerStore, error) {
 	store, err := LoadBadgerStore(cacheSize, path)
 
+	if _, ok := err.(*MigrationError); ok {
+		// the database exists, creating a new one over it would lose it
+		return nil, err
+	}
 	if err != nil {
 		fmt.Println("Could not load store - creating new")
 		store, err = NewBadgerStore(participants, cacheSize, path)
@@ -120,6 +157,12 @@ func LoadOrCreateBadgerStore(participants *peers.Peers, cacheSize int, path stri
 	return store, nil
 }
 
+// SetCompression sets the codec of the event, block and frame values written
+// from now on. Values are read whatever their codec.
+func (s *BadgerStore) SetCompression(c Compression) {
+	s.compression = c
+}
+
 //==============================================================================
 //Keys
 
@@ -151,6 +194,10 @@ func frameKey(index int64) []byte {
 	return []byte(fmt.Sprintf("%s_%09d", framePrefix, index))
 }
 
+func checkpointKey(index int64) []byte {
+	return []byte(fmt.Sprintf("%s_%09d", checkpointPrefix, index))
+}
+
 //==============================================================================
 //Implement the Store interface
 
@@ -158,6 +205,11 @@ func (s *BadgerStore) CacheSize() int {
 	return s.inmemStore.CacheSize()
 }
 
+// Caches returns the LRU caches of the underlying InmemStore
+func (s *BadgerStore) Caches() map[string]*lru.Cache {
+	return s.inmemStore.Caches()
+}
+
 func (s *BadgerStore) Participants() (*peers.Peers, error) {
 	return s.participants, nil
 }
@@ -171,7 +223,10 @@ func (s *BadgerStore) GetEvent(key string) (event Event, err error) {
 	event, err = s.inmemStore.GetEvent(key)
 	//if not in cache, try to get it from db
 	if err != nil {
+		metrics.IncrCounter("store.events.cache_miss", 1)
+		start := time.Now()
 		event, err = s.dbGetEvent(key)
+		metrics.MeasureSince("store.events.read", start)
 	}
 	return event, mapError(err, "Event", key)
 }
@@ -182,6 +237,8 @@ func (s *BadgerStore) SetEvent(event Event) error {
 		return err
 	}
 	//try to add it to the db
+	chaos.Point(chaos.PointStoreWrite)
+	defer metrics.MeasureSince("store.events.write", time.Now())
 	return s.dbSetEvents([]Event{event})
 }
 
@@ -211,7 +268,8 @@ func (s *BadgerStore) LastConsensusEventFrom(participant string) (last string, i
 
 func (s *BadgerStore) KnownEvents() map[int64]int64 {
 	known := make(map[int64]int64)
-	for p, pid := range s.participants.ByPubKey {
+	for _, pid := range s.participants.ToPeerSlice() {
+		p := pid.PubKeyHex
 		index := int64(-1)
 		last, isRoot, err := s.LastEventFrom(p)
 		if err == nil {
@@ -292,7 +350,9 @@ func (s *BadgerStore) GetRoot(participant string) (Root, error) {
 func (s *BadgerStore) GetBlock(rr int64) (Block, error) {
 	res, err := s.inmemStore.GetBlock(rr)
 	if err != nil {
+		start := time.Now()
 		res, err = s.dbGetBlock(rr)
+		metrics.MeasureSince("store.blocks.read", start)
 	}
 	return res, mapError(err, "Block", string(blockKey(rr)))
 }
@@ -301,7 +361,11 @@ func (s *BadgerStore) SetBlock(block Block) error {
 	if err := s.inmemStore.SetBlock(block); err != nil {
 		return err
 	}
-	return s.dbSetBlock(block)
+	defer metrics.MeasureSince("store.blocks.write", time.Now())
+	if err := s.dbSetBlock(block); err != nil {
+		return err
+	}
+	return s.dbSetTxLocations(s.inmemStore.blockTxLocations(block))
 }
 
 func (s *BadgerStore) LastBlockIndex() int64 {
@@ -311,7 +375,9 @@ func (s *BadgerStore) LastBlockIndex() int64 {
 func (s *BadgerStore) GetFrame(rr int64) (Frame, error) {
 	res, err := s.inmemStore.GetFrame(rr)
 	if err != nil {
+		start := time.Now()
 		res, err = s.dbGetFrame(rr)
+		metrics.MeasureSince("store.frames.read", start)
 	}
 	return res, mapError(err, "Frame", string(frameKey(rr)))
 }
@@ -320,14 +386,149 @@ func (s *BadgerStore) SetFrame(frame Frame) error {
 	if err := s.inmemStore.SetFrame(frame); err != nil {
 		return err
 	}
+	defer metrics.MeasureSince("store.frames.write", time.Now())
 	return s.dbSetFrame(frame)
 }
 
+func (s *BadgerStore) GetCheckpoint(index int64) (Checkpoint, error) {
+	res, err := s.inmemStore.GetCheckpoint(index)
+	if err != nil {
+		res, err = s.dbGetCheckpoint(index)
+	}
+	return res, mapError(err, "Checkpoint", string(checkpointKey(index)))
+}
+
+func (s *BadgerStore) SetCheckpoint(checkpoint Checkpoint) error {
+	if err := s.inmemStore.SetCheckpoint(checkpoint); err != nil {
+		return err
+	}
+	return s.dbSetCheckpoint(checkpoint)
+}
+
+func (s *BadgerStore) LastCheckpointIndex() int64 {
+	return s.inmemStore.LastCheckpointIndex()
+}
+
+// AckedBlock returns the index of the last block acknowledged by the
+// application, which survives restarts, -1 when none was
+func (s *BadgerStore) AckedBlock() (int64, error) {
+	var data []byte
+	err := s.view(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(ackedBlockKey))
+		if err != nil {
+			return err
+		}
+		data, err = item.Value()
+		return err
+	})
+	if err != nil {
+		if isDBKeyNotFound(err) {
+			return -1, nil
+		}
+		return -1, err
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
+
+// SetAckedBlock records the index of the last block acknowledged by the
+// application
+func (s *BadgerStore) SetAckedBlock(index int64) error {
+	if err := s.inmemStore.SetAckedBlock(index); err != nil {
+		return err
+	}
+	return s.update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(ackedBlockKey), []byte(strconv.FormatInt(index, 10)))
+	})
+}
+
+// TxWindow returns the hashes of the transactions recently seen by the
+// node, oldest first, which survive restarts
+func (s *BadgerStore) TxWindow() ([][]byte, error) {
+	var data []byte
+	err := s.view(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(txWindowKey))
+		if err != nil {
+			return err
+		}
+		data, err = item.Value()
+		return err
+	})
+	if err != nil {
+		if isDBKeyNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var hashes [][]byte
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// SetTxWindow records the hashes of the transactions recently seen by the
+// node
+func (s *BadgerStore) SetTxWindow(hashes [][]byte) error {
+	if err := s.inmemStore.SetTxWindow(hashes); err != nil {
+		return err
+	}
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		return err
+	}
+	return s.update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(txWindowKey), data)
+	})
+}
+
+// NetworkParams returns the governance state of the node, which survives
+// restarts, nil before the first one is recorded
+func (s *BadgerStore) NetworkParams() (*NetworkParams, error) {
+	var data []byte
+	err := s.view(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(networkParamsKey))
+		if err != nil {
+			return err
+		}
+		data, err = item.Value()
+		return err
+	})
+	if err != nil {
+		if isDBKeyNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	params := new(NetworkParams)
+	if err := json.Unmarshal(data, params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// SetNetworkParams records the governance state of the node
+func (s *BadgerStore) SetNetworkParams(params *NetworkParams) error {
+	if err := s.inmemStore.SetNetworkParams(params); err != nil {
+		return err
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(networkParamsKey), data)
+	})
+}
+
 func (s *BadgerStore) Reset(roots map[string]Root) error {
 	return s.inmemStore.Reset(roots)
 }
 
 func (s *BadgerStore) Close() error {
+	s.stopGC()
+	if err := s.EndBatch(); err != nil {
+		return err
+	}
 	if err := s.inmemStore.Close(); err != nil {
 		return err
 	}
@@ -347,7 +548,7 @@ func (s *BadgerStore) StorePath() string {
 
 func (s *BadgerStore) dbGetEvent(key string) (Event, error) {
 	var eventBytes []byte
-	err := s.db.View(func(txn *badger.Txn) error {
+	err := s.view(func(txn *badger.Txn) error {
 		item, err := txn.Get([]byte(key))
 		if err != nil {
 			return err
@@ -360,97 +561,73 @@ func (s *BadgerStore) dbGetEvent(key string) (Event, error) {
 		return Event{}, err
 	}
 
+	eventBytes, err = decodeRecord(eventBytes)
+	if err != nil {
+		return Event{}, lerrors.Wrap(lerrors.StoreCorrupt, "BadgerStore.GetEvent", err)
+	}
 	event := new(Event)
 	if err := event.ProtoUnmarshal(eventBytes); err != nil {
-		return Event{}, err
+		return Event{}, lerrors.Wrap(lerrors.StoreCorrupt, "BadgerStore.GetEvent", err)
 	}
 
 	return *event, nil
 }
 
 func (s *BadgerStore) dbSetEvents(events []Event) error {
-	tx := s.db.NewTransaction(true)
-	defer tx.Discard()
-
-	for _, event := range events {
-		eventHex := event.Hex()
-		val, err := event.ProtoMarshal()
-		if err != nil {
-			return err
-		}
-		//check if it already exists
-		existent := false
-		_, err = tx.Get([]byte(eventHex))
-		if err != nil && isDBKeyNotFound(err) {
-			existent = true
-		}
-		//insert [event hash] => [event bytes]
-		if err := tx.Set([]byte(eventHex), val); err != nil {
-			return err
-		}
-
-		if existent {
-			//insert [topo_index] => [event hash]
-			topoKey := topologicalEventKey(event.Message.TopologicalIndex)
-			if err := tx.Set(topoKey, []byte(eventHex)); err != nil {
-				return err
-			}
-			//insert [participant_index] => [event hash]
-			peKey := participantEventKey(event.Creator(), event.Index())
-			if err := tx.Set(peKey, []byte(eventHex)); err != nil {
-				return err
-			}
-		}
-	}
-	return tx.Commit(nil)
-}
-
-func (s *BadgerStore) dbTopologicalEvents() ([]Event, error) {
-	var res []Event
-	t := int64(-1)
-	err := s.db.View(func(txn *badger.Txn) error {
-		key := topologicalEventKey(t)
-		item, errr := txn.Get(key)
-		for errr == nil {
-			v, errrr := item.Value()
-			if errrr != nil {
-				break
-			}
-
-			evKey := string(v)
-			eventItem, err := txn.Get([]byte(evKey))
+	// the events which were new on the first run of the update, which
+	// runs again after committing part of them when the batch is too big
+	isNew := make(map[string]bool)
+	return s.update(func(tx *badger.Txn) error {
+		for _, event := range events {
+			eventHex := event.Hex()
+			val, err := event.ProtoMarshal()
 			if err != nil {
 				return err
 			}
-			eventBytes, err := eventItem.Value()
-			if err != nil {
-				return err
+			val = encodeRecord(s.compression, val)
+			//check if it already exists
+			existent, checked := isNew[eventHex]
+			if !checked {
+				_, err = tx.Get([]byte(eventHex))
+				existent = err != nil && isDBKeyNotFound(err)
+				isNew[eventHex] = existent
 			}
-
-			event := new(Event)
-			if err := event.ProtoUnmarshal(eventBytes); err != nil {
+			//insert [event hash] => [event bytes]
+			if err := tx.Set([]byte(eventHex), val); err != nil {
 				return err
 			}
-			res = append(res, *event)
 
-			t++
-			key = topologicalEventKey(t)
-			item, errr = txn.Get(key)
-		}
-
-		if !isDBKeyNotFound(errr) {
-			return errr
+			if existent {
+				//insert [topo_index] => [event hash]
+				topoKey := topologicalEventKey(event.Message.TopologicalIndex)
+				if err := tx.Set(topoKey, []byte(eventHex)); err != nil {
+					return err
+				}
+				//insert [participant_index] => [event hash]
+				peKey := participantEventKey(event.Creator(), event.Index())
+				if err := tx.Set(peKey, []byte(eventHex)); err != nil {
+					return err
+				}
+			}
 		}
-
 		return nil
 	})
+}
 
+// dbTopologicalEvents returns the stored events in topological order. The
+// events removed by Prune leave gaps in the order.
+func (s *BadgerStore) dbTopologicalEvents() ([]Event, error) {
+	var res []Event
+	err := s.IterateTopologicalEvents(func(event Event) error {
+		res = append(res, event)
+		return nil
+	})
 	return res, err
 }
 
 func (s *BadgerStore) dbParticipantEvents(participant string, skip int64) ([]string, error) {
 	var res []string
-	err := s.db.View(func(txn *badger.Txn) error {
+	err := s.view(func(txn *badger.Txn) error {
 		i := skip + 1
 		key := participantEventKey(participant, i)
 		item, errr := txn.Get(key)
@@ -478,7 +655,7 @@ func (s *BadgerStore) dbParticipantEvents(participant string, skip int64) ([]str
 func (s *BadgerStore) dbParticipantEvent(participant string, index int64) (string, error) {
 	var data []byte
 	key := participantEventKey(participant, index)
-	err := s.db.View(func(txn *badger.Txn) error {
+	err := s.view(func(txn *badger.Txn) error {
 		item, err := txn.Get(key)
 		if err != nil {
 			return err
@@ -544,7 +721,7 @@ func (s *BadgerStore) dbSetRootEvents(roots map[string]Root) error {
 func (s *BadgerStore) dbGetRoot(participant string) (Root, error) {
 	var rootBytes []byte
 	key := participantRootKey(participant)
-	err := s.db.View(func(txn *badger.Txn) error {
+	err := s.view(func(txn *badger.Txn) error {
 		item, err := txn.Get(key)
 		if err != nil {
 			return err
@@ -559,7 +736,7 @@ func (s *BadgerStore) dbGetRoot(participant string) (Root, error) {
 
 	root := new(Root)
 	if err := root.ProtoUnmarshal(rootBytes); err != nil {
-		return Root{}, err
+		return Root{}, lerrors.Wrap(lerrors.StoreCorrupt, "BadgerStore.GetRoot", err)
 	}
 
 	return *root, nil
@@ -568,7 +745,7 @@ func (s *BadgerStore) dbGetRoot(participant string) (Root, error) {
 func (s *BadgerStore) dbGetRound(index int64) (RoundInfo, error) {
 	var roundBytes []byte
 	key := roundKey(index)
-	err := s.db.View(func(txn *badger.Txn) error {
+	err := s.view(func(txn *badger.Txn) error {
 		item, err := txn.Get(key)
 		if err != nil {
 			return err
@@ -583,34 +760,29 @@ func (s *BadgerStore) dbGetRound(index int64) (RoundInfo, error) {
 
 	roundInfo := new(RoundInfo)
 	if err := roundInfo.ProtoUnmarshal(roundBytes); err != nil {
-		return *NewRoundInfo(), err
+		return *NewRoundInfo(), lerrors.Wrap(lerrors.StoreCorrupt, "BadgerStore.GetRound", err)
 	}
 
 	return *roundInfo, nil
 }
 
 func (s *BadgerStore) dbSetRound(index int64, round RoundInfo) error {
-	tx := s.db.NewTransaction(true)
-	defer tx.Discard()
-
 	key := roundKey(index)
 	val, err := round.ProtoMarshal()
 	if err != nil {
 		return err
 	}
 
-	//insert [round_index] => [round bytes]
-	if err := tx.Set(key, val); err != nil {
-		return err
-	}
-
-	return tx.Commit(nil)
+	return s.update(func(tx *badger.Txn) error {
+		//insert [round_index] => [round bytes]
+		return tx.Set(key, val)
+	})
 }
 
 func (s *BadgerStore) dbGetParticipants() (*peers.Peers, error) {
 	res := peers.NewPeers()
 
-	err := s.db.View(func(txn *badger.Txn) error {
+	err := s.view(func(txn *badger.Txn) error {
 		it := txn.NewIterator(badger.DefaultIteratorOptions)
 		defer it.Close()
 		prefix := []byte(participantPrefix)
@@ -634,7 +806,8 @@ func (s *BadgerStore) dbSetParticipants(participants *peers.Peers) error {
 	tx := s.db.NewTransaction(true)
 	defer tx.Discard()
 
-	for participant, id := range participants.ByPubKey {
+	for _, id := range participants.ToPeerSlice() {
+		participant := id.PubKeyHex
 		key := participantKey(participant)
 		val := []byte(strconv.FormatInt(id.ID, 10))
 		//insert [participant_participant] => [id]
@@ -648,7 +821,7 @@ func (s *BadgerStore) dbSetParticipants(participants *peers.Peers) error {
 func (s *BadgerStore) dbGetBlock(index int64) (Block, error) {
 	var blockBytes []byte
 	key := blockKey(index)
-	err := s.db.View(func(txn *badger.Txn) error {
+	err := s.view(func(txn *badger.Txn) error {
 		item, err := txn.Get(key)
 		if err != nil {
 			return err
@@ -661,36 +834,36 @@ func (s *BadgerStore) dbGetBlock(index int64) (Block, error) {
 		return Block{}, err
 	}
 
+	blockBytes, err = decodeRecord(blockBytes)
+	if err != nil {
+		return Block{}, lerrors.Wrap(lerrors.StoreCorrupt, "BadgerStore.GetBlock", err)
+	}
 	block := new(Block)
 	if err := block.ProtoUnmarshal(blockBytes); err != nil {
-		return Block{}, err
+		return Block{}, lerrors.Wrap(lerrors.StoreCorrupt, "BadgerStore.GetBlock", err)
 	}
 
 	return *block, nil
 }
 
 func (s *BadgerStore) dbSetBlock(block Block) error {
-	tx := s.db.NewTransaction(true)
-	defer tx.Discard()
-
 	key := blockKey(block.Index())
 	val, err := block.ProtoMarshal()
 	if err != nil {
 		return err
 	}
+	val = encodeRecord(s.compression, val)
 
-	//insert [index] => [block bytes]
-	if err := tx.Set(key, val); err != nil {
-		return err
-	}
-
-	return tx.Commit(nil)
+	return s.update(func(tx *badger.Txn) error {
+		//insert [index] => [block bytes]
+		return tx.Set(key, val)
+	})
 }
 
 func (s *BadgerStore) dbGetFrame(index int64) (Frame, error) {
 	var frameBytes []byte
 	key := frameKey(index)
-	err := s.db.View(func(txn *badger.Txn) error {
+	err := s.view(func(txn *badger.Txn) error {
 		item, err := txn.Get(key)
 		if err != nil {
 			return err
@@ -703,30 +876,62 @@ func (s *BadgerStore) dbGetFrame(index int64) (Frame, error) {
 		return Frame{}, err
 	}
 
+	frameBytes, err = decodeRecord(frameBytes)
+	if err != nil {
+		return Frame{}, lerrors.Wrap(lerrors.StoreCorrupt, "BadgerStore.GetFrame", err)
+	}
 	frame := new(Frame)
 	if err := frame.ProtoUnmarshal(frameBytes); err != nil {
-		return Frame{}, err
+		return Frame{}, lerrors.Wrap(lerrors.StoreCorrupt, "BadgerStore.GetFrame", err)
 	}
 
 	return *frame, nil
 }
 
 func (s *BadgerStore) dbSetFrame(frame Frame) error {
-	tx := s.db.NewTransaction(true)
-	defer tx.Discard()
-
 	key := frameKey(frame.Round)
 	val, err := frame.ProtoMarshal()
 	if err != nil {
 		return err
 	}
+	val = encodeRecord(s.compression, val)
+
+	return s.update(func(tx *badger.Txn) error {
+		//insert [index] => [block bytes]
+		return tx.Set(key, val)
+	})
+}
 
-	//insert [index] => [block bytes]
-	if err := tx.Set(key, val); err != nil {
+func (s *BadgerStore) dbGetCheckpoint(index int64) (Checkpoint, error) {
+	var data []byte
+	err := s.view(func(txn *badger.Txn) error {
+		item, err := txn.Get(checkpointKey(index))
+		if err != nil {
+			return err
+		}
+		data, err = item.Value()
 		return err
+	})
+	if err != nil {
+		return Checkpoint{}, err
 	}
 
-	return tx.Commit(nil)
+	var checkpoint Checkpoint
+	if err := checkpoint.Unmarshal(data); err != nil {
+		return Checkpoint{}, lerrors.Wrap(lerrors.StoreCorrupt, "BadgerStore.GetCheckpoint", err)
+	}
+	return checkpoint, nil
+}
+
+func (s *BadgerStore) dbSetCheckpoint(checkpoint Checkpoint) error {
+	val, err := checkpoint.Marshal()
+	if err != nil {
+		return err
+	}
+	return s.update(func(tx *badger.Txn) error {
+		//insert [index] => [checkpoint]
+		return tx.Set(checkpointKey(checkpoint.Index), val)
+	})
 }
 
 //++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
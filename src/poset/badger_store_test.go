@@ -10,9 +10,10 @@ import (
 
 	"github.com/Fantom-foundation/go-lachesis/src/crypto"
 	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/dgraph-io/badger"
 )
 
-func initBadgerStore(cacheSize int, t *testing.T) (*BadgerStore, []pub) {
+func initBadgerStore(cacheSize int, t testing.TB) (*BadgerStore, []pub) {
 	n := 3
 	var participantPubs []pub
 	participants := peers.NewPeers()
@@ -40,7 +41,7 @@ func initBadgerStore(cacheSize int, t *testing.T) (*BadgerStore, []pub) {
 	return store, participantPubs
 }
 
-func removeBadgerStore(store *BadgerStore, t *testing.T) {
+func removeBadgerStore(store *BadgerStore, t testing.TB) {
 	if err := store.Close(); err != nil {
 		t.Fatal(err)
 	}
@@ -695,3 +696,113 @@ func TestBadgerFrames(t *testing.T) {
 		}
 	})
 }
+
+func TestBadgerBatch(t *testing.T) {
+	store, participants := initBadgerStore(10, t)
+	defer removeBadgerStore(store, t)
+
+	store.BeginBatch()
+	var events []Event
+	for k := int64(0); k < 50; k++ {
+		event := NewEvent([][]byte{[]byte(fmt.Sprintf("batch_%d", k))},
+			nil, nil, []string{"", ""}, participants[0].pubKey, k, nil)
+		if err := store.SetEvent(event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	if err := store.SetRound(0, *NewRoundInfo()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get([]byte(events[0].Hex()))
+		return err
+	}); err == nil {
+		t.Fatal("the writes of the batch should not be committed before the flush")
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if !store.Batching() {
+		t.Fatal("the batch should stay open after a flush")
+	}
+	if err := store.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get([]byte(events[0].Hex()))
+		return err
+	}); err != nil {
+		t.Fatalf("the writes of the batch should be committed by the flush: %s", err)
+	}
+
+	// a read past the cache commits the pending writes of the batch first
+	event := NewEvent([][]byte{[]byte("batch_read")},
+		nil, nil, []string{"", ""}, participants[1].pubKey, 0, nil)
+	if err := store.SetEvent(event); err != nil {
+		t.Fatal(err)
+	}
+	events = append(events, event)
+	if _, err := store.dbGetEvent(event.Hex()); err != nil {
+		t.Fatalf("event %s of the batch should be readable: %s", event.Hex(), err)
+	}
+	if !store.Batching() {
+		t.Fatal("the batch should stay open after a read")
+	}
+	if err := store.EndBatch(); err != nil {
+		t.Fatal(err)
+	}
+	if store.Batching() {
+		t.Fatal("the batch should be closed")
+	}
+	pEvents, err := store.dbParticipantEvents(participants[0].hex, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pEvents) != len(events)-1 {
+		t.Fatalf("expected %d events of the participant, got %d", len(events)-1, len(pEvents))
+	}
+}
+
+// benchmarkSetEvents inserts b.N events, per batches of batchSize if batchSize
+// is positive
+func benchmarkSetEvents(b *testing.B, batchSize int) {
+	store, participants := initBadgerStore(100, b)
+	defer removeBadgerStore(store, b)
+
+	events := make([]Event, b.N)
+	for k := range events {
+		p := participants[k%len(participants)]
+		events[k] = NewEvent([][]byte{[]byte(fmt.Sprintf("tx_%d", k))},
+			nil, nil, []string{"", ""}, p.pubKey, int64(k/len(participants)), nil)
+		events[k].Message.TopologicalIndex = int64(k)
+	}
+
+	b.ResetTimer()
+	for k, event := range events {
+		if batchSize > 0 && k%batchSize == 0 {
+			store.BeginBatch()
+		}
+		if err := store.SetEvent(event); err != nil {
+			b.Fatal(err)
+		}
+		if batchSize > 0 && (k+1)%batchSize == 0 {
+			if err := store.EndBatch(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	if err := store.EndBatch(); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkBadgerSetEvents(b *testing.B) {
+	benchmarkSetEvents(b, 0)
+}
+
+func BenchmarkBadgerSetEventsBatch100(b *testing.B) {
+	benchmarkSetEvents(b, 100)
+}
+
+func BenchmarkBadgerSetEventsBatch1000(b *testing.B) {
+	benchmarkSetEvents(b, 1000)
+}
@@ -0,0 +1,139 @@
+package poset
+
+import (
+	"time"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// BatchStore is a Store which can group its writes in batches: the writes
+// between BeginBatch and EndBatch are committed together, by Flush or by
+// EndBatch, instead of one by one
+type BatchStore interface {
+	BeginBatch()
+	Flush() error
+	EndBatch() error
+}
+
+// BeginBatch makes the writes of the store go to a single badger transaction
+// until EndBatch. The reads of the store see the writes of the batch.
+func (s *BadgerStore) BeginBatch() {
+	s.batchLock.Lock()
+	defer s.batchLock.Unlock()
+	if s.batch == nil {
+		s.batch = s.db.NewTransaction(true)
+		s.batchWrites = 0
+	}
+}
+
+// Flush commits the writes of the current batch, which stays open
+func (s *BadgerStore) Flush() error {
+	s.batchLock.Lock()
+	defer s.batchLock.Unlock()
+	if s.batch == nil {
+		return nil
+	}
+	return s.commitBatch(true)
+}
+
+// EndBatch commits the writes of the current batch and returns to committing
+// every write on its own
+func (s *BadgerStore) EndBatch() error {
+	s.batchLock.Lock()
+	defer s.batchLock.Unlock()
+	if s.batch == nil {
+		return nil
+	}
+	return s.commitBatch(false)
+}
+
+// Batching tells whether the writes of the store are batched
+func (s *BadgerStore) Batching() bool {
+	s.batchLock.Lock()
+	defer s.batchLock.Unlock()
+	return s.batch != nil
+}
+
+// commitBatch commits the batch transaction and opens a new one if reopen.
+// The batch lock must be held.
+func (s *BadgerStore) commitBatch(reopen bool) error {
+	start := time.Now()
+	err := s.batch.Commit(nil)
+	s.batch.Discard()
+	metrics.MeasureSince("store.batch.commit", start)
+	metrics.IncrCounter("store.batch.writes", int64(s.batchWrites))
+
+	s.batch = nil
+	s.batchWrites = 0
+	if reopen {
+		s.batch = s.db.NewTransaction(true)
+	}
+	return err
+}
+
+// update runs fn in the transaction of the current batch, or in a
+// transaction of its own outside batches. A batch too big for badger is
+// committed, with the writes fn made before it failed, and fn runs again in
+// a new one: fn must write all its keys again when it is run twice.
+func (s *BadgerStore) update(fn func(txn *badger.Txn) error) error {
+	s.batchLock.Lock()
+	defer s.batchLock.Unlock()
+	if s.batch == nil {
+		return s.db.Update(fn)
+	}
+	err := fn(s.batch)
+	if err == badger.ErrTxnTooBig {
+		if err := s.commitBatch(true); err != nil {
+			return err
+		}
+		err = fn(s.batch)
+	}
+	if err == nil {
+		s.batchWrites++
+	}
+	return err
+}
+
+// view runs fn in a read-only transaction, without holding the batch lock
+// so that reads do not wait for the writes. A badger transaction cannot be
+// read while it is written to, so the pending writes of the current batch
+// are committed first for fn to see them.
+func (s *BadgerStore) view(fn func(txn *badger.Txn) error) error {
+	s.batchLock.Lock()
+	var err error
+	if s.batch != nil && s.batchWrites > 0 {
+		err = s.commitBatch(true)
+	}
+	s.batchLock.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.db.View(fn)
+}
+
+// BeginBatch batches the writes to the store until EndBatch, if it supports
+// it
+func (p *Poset) BeginBatch() {
+	if store, ok := p.Store.(BatchStore); ok {
+		store.BeginBatch()
+	}
+}
+
+// FlushBatch commits the writes of the current batch of the store
+func (p *Poset) FlushBatch() error {
+	if store, ok := p.Store.(BatchStore); ok {
+		return store.Flush()
+	}
+	return nil
+}
+
+// EndBatch commits the writes of the current batch of the store and stops
+// batching
+func (p *Poset) EndBatch() error {
+	if store, ok := p.Store.(BatchStore); ok {
+		return store.EndBatch()
+	}
+	return nil
+}
@@ -81,6 +81,7 @@ func NewBlock(blockIndex, roundReceived int64, frameHash []byte, txs [][]byte) B
 		Index:         blockIndex,
 		RoundReceived: roundReceived,
 		Transactions:  txs,
+		TxRoot:        TxRoot(txs),
 	}
 	return Block{
 		Body:       &body,
@@ -101,6 +102,12 @@ func (b *Block) RoundReceived() int64 {
 	return b.Body.RoundReceived
 }
 
+// Timestamp returns the consensus time of the block, in unix nanoseconds, 0
+// when none of the witnesses of its round had a timestamp
+func (b *Block) Timestamp() int64 {
+	return b.Body.Timestamp
+}
+
 func (b *Block) BlockHash() ([]byte, error) {
 	hashBytes, err := b.ProtoMarshal()
 	if err != nil {
@@ -145,6 +152,7 @@ func (b *Block) GetSignature(validator string) (res BlockSignature, err error) {
 
 func (b *Block) AppendTransactions(txs [][]byte) {
 	b.Body.Transactions = append(b.Body.Transactions, txs...)
+	b.Body.TxRoot = TxRoot(b.Body.Transactions)
 }
 
 func (b *Block) ProtoMarshal() ([]byte, error) {
@@ -215,7 +223,10 @@ func ListBytesEquals(this [][]byte, that [][]byte) bool {
 func (this *BlockBody) Equals(that *BlockBody) bool {
 	return this.Index == that.Index &&
 		this.RoundReceived == that.RoundReceived &&
-		ListBytesEquals(this.Transactions, that.Transactions)
+		ListBytesEquals(this.Transactions, that.Transactions) &&
+		BytesEquals(this.CheckpointRoot, that.CheckpointRoot) &&
+		this.Timestamp == that.Timestamp &&
+		BytesEquals(this.TxRoot, that.TxRoot)
 }
 
 func (this *WireBlockSignature) Equals(that *WireBlockSignature) bool {
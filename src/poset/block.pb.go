@@ -45,9 +45,13 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type BlockBody struct {
-	Index         int64    `protobuf:"varint,1,opt,name=Index,json=index" json:"Index,omitempty"`
-	RoundReceived int64    `protobuf:"varint,2,opt,name=RoundReceived,json=roundReceived" json:"RoundReceived,omitempty"`
-	Transactions  [][]byte `protobuf:"bytes,5,rep,name=Transactions,json=transactions,proto3" json:"Transactions,omitempty"`
+	Index          int64    `protobuf:"varint,1,opt,name=Index,json=index" json:"Index,omitempty"`
+	RoundReceived  int64    `protobuf:"varint,2,opt,name=RoundReceived,json=roundReceived" json:"RoundReceived,omitempty"`
+	Transactions   [][]byte `protobuf:"bytes,5,rep,name=Transactions,json=transactions,proto3" json:"Transactions,omitempty"`
+	CheckpointRoot []byte   `protobuf:"bytes,6,opt,name=CheckpointRoot,json=checkpointRoot,proto3" json:"CheckpointRoot,omitempty"`
+	NetworkID      string   `protobuf:"bytes,7,opt,name=NetworkID,json=networkID" json:"NetworkID,omitempty"`
+	Timestamp      int64    `protobuf:"varint,8,opt,name=Timestamp,json=timestamp" json:"Timestamp,omitempty"`
+	TxRoot         []byte   `protobuf:"bytes,9,opt,name=TxRoot,json=txRoot,proto3" json:"TxRoot,omitempty"`
 }
 
 func (m *BlockBody) Reset()                    { *m = BlockBody{} }
@@ -76,6 +80,34 @@ func (m *BlockBody) GetTransactions() [][]byte {
 	return nil
 }
 
+func (m *BlockBody) GetCheckpointRoot() []byte {
+	if m != nil {
+		return m.CheckpointRoot
+	}
+	return nil
+}
+
+func (m *BlockBody) GetNetworkID() string {
+	if m != nil {
+		return m.NetworkID
+	}
+	return ""
+}
+
+func (m *BlockBody) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *BlockBody) GetTxRoot() []byte {
+	if m != nil {
+		return m.TxRoot
+	}
+	return nil
+}
+
 type WireBlockSignature struct {
 	Index     int64  `protobuf:"varint,1,opt,name=Index,json=index" json:"Index,omitempty"`
 	Signature string `protobuf:"bytes,2,opt,name=Signature,json=signature" json:"Signature,omitempty"`
@@ -165,24 +197,28 @@ func init() {
 func init() { proto.RegisterFile("block.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 299 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x51, 0x3d, 0x6b, 0xc3, 0x30,
-	0x14, 0xc4, 0x1f, 0x0a, 0xe8, 0xc5, 0xa1, 0x41, 0x74, 0x10, 0x25, 0x83, 0x31, 0x19, 0x3c, 0x79,
-	0x48, 0x97, 0x52, 0xda, 0x25, 0xd0, 0x92, 0xae, 0x6a, 0xa1, 0xb3, 0x62, 0xab, 0xb5, 0x71, 0x2a,
-	0x05, 0x49, 0x0e, 0xc9, 0x7f, 0xe8, 0x8f, 0x2e, 0x92, 0xb1, 0x6b, 0x0a, 0xdd, 0x9e, 0xee, 0xce,
-	0xef, 0xee, 0x9e, 0x61, 0xbe, 0x3f, 0xa8, 0xb2, 0x2d, 0x8e, 0x5a, 0x59, 0x45, 0xd0, 0x51, 0x19,
-	0x61, 0xb3, 0x16, 0xf0, 0xd6, 0xa1, 0x5b, 0x55, 0x5d, 0xc8, 0x35, 0xa0, 0x17, 0x59, 0x89, 0x33,
-	0x0d, 0xd2, 0x20, 0x8f, 0x18, 0x6a, 0xdc, 0x83, 0xac, 0x61, 0xc1, 0x54, 0x27, 0x2b, 0x26, 0x4a,
-	0xd1, 0x9c, 0x44, 0x45, 0x43, 0xcf, 0x2e, 0xf4, 0x14, 0x24, 0x19, 0x24, 0x6f, 0x9a, 0x4b, 0xc3,
-	0x4b, 0xdb, 0x28, 0x69, 0x28, 0x4a, 0xa3, 0x3c, 0x61, 0x89, 0x9d, 0x60, 0xd9, 0x0e, 0xc8, 0x7b,
-	0xa3, 0x85, 0x37, 0x7c, 0x6d, 0x3e, 0x25, 0xb7, 0x9d, 0x16, 0xff, 0xb8, 0xae, 0x00, 0x8f, 0x12,
-	0xef, 0x88, 0x19, 0x36, 0x03, 0x90, 0x7d, 0x87, 0x80, 0xfc, 0x1a, 0xb2, 0x86, 0xd8, 0x65, 0xf7,
-	0x1f, 0xcf, 0x37, 0xcb, 0xc2, 0xd7, 0x2a, 0xc6, 0x4e, 0x2c, 0xde, 0xbb, 0x66, 0x0f, 0x00, 0xe3,
-	0x36, 0x43, 0xc3, 0x34, 0xca, 0xe7, 0x9b, 0xd5, 0x54, 0x5b, 0xfc, 0xd2, 0x4f, 0xd2, 0xea, 0x0b,
-	0x83, 0xd1, 0xcc, 0x10, 0x02, 0x71, 0xcd, 0x4d, 0x4d, 0xa3, 0x34, 0xc8, 0x13, 0xe6, 0x67, 0xb2,
-	0x84, 0xa8, 0x16, 0x67, 0x1a, 0xfb, 0x64, 0x6e, 0xf4, 0x89, 0x2d, 0xb7, 0x62, 0xe7, 0xa4, 0xc8,
-	0x4b, 0xb1, 0x19, 0x00, 0xc7, 0x3e, 0x6b, 0xfe, 0xd5, 0xb3, 0xb3, 0x9e, 0xfd, 0x18, 0x80, 0x9b,
-	0x47, 0xb8, 0xfa, 0x13, 0xc0, 0x19, 0xb4, 0xa2, 0xef, 0x85, 0x99, 0x1b, 0xdd, 0xa1, 0x4e, 0xfc,
-	0xd0, 0x0d, 0xe7, 0xe8, 0x1f, 0xf7, 0xe1, 0x5d, 0xb0, 0x9f, 0xf9, 0x7f, 0x7a, 0xfb, 0x13, 0x00,
-	0x00, 0xff, 0xff, 0x7a, 0x56, 0xd2, 0xdc, 0xe2, 0x01, 0x00, 0x00,
+	// 365 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x75, 0x52, 0xcb, 0x4e, 0xc3, 0x30,
+	0x10, 0x54, 0x9b, 0x07, 0x78, 0x9b, 0x96, 0xca, 0x42, 0x28, 0x42, 0x3d, 0x54, 0x11, 0x42, 0x3d,
+	0xe5, 0x00, 0x17, 0x84, 0xe0, 0x52, 0x1e, 0x2a, 0x17, 0x0e, 0xa6, 0x12, 0xe7, 0x34, 0x31, 0x24,
+	0x4a, 0x6b, 0x47, 0xb6, 0x5b, 0xda, 0x7f, 0xe0, 0x3f, 0xf9, 0x0d, 0x6c, 0x47, 0x09, 0x05, 0x89,
+	0xdb, 0xee, 0xec, 0x78, 0x66, 0x77, 0x64, 0xe8, 0x2d, 0x96, 0x3c, 0x2d, 0xe3, 0x4a, 0x70, 0xc5,
+	0xb1, 0x57, 0x71, 0x49, 0x55, 0xf4, 0xd5, 0x01, 0x34, 0x35, 0xf0, 0x94, 0x67, 0x3b, 0x7c, 0x0c,
+	0xde, 0x13, 0xcb, 0xe8, 0x36, 0xec, 0x8c, 0x3b, 0x13, 0x87, 0x78, 0x85, 0x69, 0xf0, 0x19, 0xf4,
+	0x09, 0x5f, 0xb3, 0x8c, 0xd0, 0x94, 0x16, 0x1b, 0x9a, 0x85, 0x5d, 0x3b, 0xed, 0x8b, 0x7d, 0x10,
+	0x47, 0x10, 0xcc, 0x45, 0xc2, 0x64, 0x92, 0xaa, 0x82, 0x33, 0x19, 0x7a, 0x63, 0x67, 0x12, 0x90,
+	0x40, 0xed, 0x61, 0xf8, 0x1c, 0x06, 0x77, 0x39, 0x4d, 0xcb, 0x8a, 0x17, 0x4c, 0x11, 0xce, 0x55,
+	0xe8, 0x6b, 0xa9, 0x80, 0x0c, 0xd2, 0x5f, 0x28, 0x1e, 0x01, 0x7a, 0xa6, 0xea, 0x83, 0x8b, 0xf2,
+	0xe9, 0x3e, 0x3c, 0xd0, 0x14, 0x44, 0x10, 0x6b, 0x00, 0x33, 0x9d, 0x17, 0x2b, 0x2a, 0x55, 0xb2,
+	0xaa, 0xc2, 0x43, 0xbb, 0x0b, 0x52, 0x0d, 0x80, 0x4f, 0xc0, 0x9f, 0x6f, 0xad, 0x36, 0xb2, 0xda,
+	0xbe, 0xb2, 0x5d, 0x34, 0x03, 0xfc, 0x5a, 0x08, 0x6a, 0x8f, 0x7d, 0x29, 0xde, 0x59, 0xa2, 0xd6,
+	0x82, 0xfe, 0x73, 0xb1, 0x76, 0x68, 0x29, 0xf6, 0x5a, 0xed, 0x2f, 0x1b, 0x20, 0xfa, 0xec, 0x82,
+	0x67, 0x65, 0x74, 0x32, 0xae, 0xc9, 0xcd, 0x3e, 0xee, 0x5d, 0x0c, 0x63, 0x9b, 0x69, 0xdc, 0xe6,
+	0x49, 0xdc, 0x85, 0x49, 0xf5, 0x06, 0xa0, 0x55, 0x93, 0x5a, 0xce, 0xd1, 0xdc, 0xd1, 0x3e, 0x37,
+	0xfe, 0x19, 0x3f, 0x30, 0x25, 0x76, 0x04, 0x5a, 0x33, 0x89, 0x31, 0xb8, 0x79, 0x22, 0xf3, 0xd0,
+	0xb1, 0xd7, 0xd8, 0x1a, 0x0f, 0xc1, 0xc9, 0xf5, 0xce, 0xae, 0xdd, 0xcc, 0x94, 0x76, 0x63, 0x95,
+	0x28, 0x3a, 0x33, 0x54, 0xcf, 0x52, 0x91, 0x6c, 0x00, 0x33, 0x7d, 0x14, 0xc9, 0xaa, 0x9e, 0xd6,
+	0x91, 0xa3, 0xb7, 0x06, 0x38, 0xbd, 0x85, 0xa3, 0x3f, 0x0b, 0x18, 0x83, 0x92, 0xd6, 0x77, 0x69,
+	0x03, 0x5d, 0x9a, 0xa0, 0x36, 0xc9, 0x72, 0xdd, 0xc4, 0x51, 0x37, 0xd7, 0xdd, 0xab, 0xce, 0xc2,
+	0xb7, 0x1f, 0xea, 0xf2, 0x1b, 0x76, 0x90, 0x25, 0x1f, 0x5f, 0x02, 0x00, 0x00,
 }
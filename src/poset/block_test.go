@@ -68,3 +68,50 @@ func TestAppendSignature(t *testing.T) {
 	}
 
 }
+
+func TestCheckpointBlock(t *testing.T) {
+	var frames []Frame
+	for r := int64(0); r < 5; r++ {
+		frames = append(frames, Frame{Round: r, Events: []*EventMessage{
+			{Body: &EventBody{Transactions: [][]byte{[]byte(fmt.Sprintf("tx%d", r))}}},
+		}})
+	}
+	root, err := FrameRoot(frames)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := NewBlock(3, 4, []byte("framehash"), nil)
+	if block.IsCheckpoint() {
+		t.Fatal("a block without a root should not be a checkpoint")
+	}
+	block.Body.CheckpointRoot = root
+	data, err := block.ProtoMarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rblock Block
+	if err := rblock.ProtoUnmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	if !rblock.IsCheckpoint() || !rblock.Body.Equals(block.Body) {
+		t.Fatalf("the checkpoint block should be %#v, not %#v", block.Body, rblock.Body)
+	}
+
+	for _, frame := range frames {
+		proof, err := FrameProof(frames, frame.Round)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash, err := frame.ConsensusHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !proof.Verify(rblock.CheckpointRoot(), hash) {
+			t.Fatalf("the frame of round %d should be proved by the checkpoint root", frame.Round)
+		}
+	}
+	if _, err := FrameProof(frames, 7); err == nil {
+		t.Fatal("there should be no proof of a frame outside the checkpoint")
+	}
+}
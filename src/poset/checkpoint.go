@@ -0,0 +1,174 @@
+package poset
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// Checkpoint commits to the frames of the consensus rounds FromRound to
+// ToRound with the Merkle root of their hashes. The root is also carried by
+// the checkpoint block, so that it is signed by the validators.
+type Checkpoint struct {
+	Index     int64
+	FromRound int64
+	ToRound   int64
+	Root      []byte
+	Block     int64
+}
+
+// Marshal encodes a checkpoint for the stores
+func (c *Checkpoint) Marshal() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+// Unmarshal decodes a checkpoint written by Marshal
+func (c *Checkpoint) Unmarshal(data []byte) error {
+	return json.Unmarshal(data, c)
+}
+
+// IsCheckpoint returns true if the block is a checkpoint block
+func (b *Block) IsCheckpoint() bool {
+	return len(b.Body.CheckpointRoot) > 0
+}
+
+// CheckpointRoot returns the Merkle root of the frames committed by a
+// checkpoint block
+func (b *Block) CheckpointRoot() []byte {
+	return b.Body.CheckpointRoot
+}
+
+// ConsensusHash returns the hash of the round and of the bodies of the events
+// of a frame, in consensus order. Unlike Hash, it leaves out the fields each
+// node sets on its own copy of the events, so it is the same on every node.
+func (f *Frame) ConsensusHash() ([]byte, error) {
+	data := make([]byte, 8, 8+32*len(f.Events))
+	binary.BigEndian.PutUint64(data, uint64(f.Round))
+	for _, e := range f.Events {
+		hash, err := e.Body.Hash()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, hash...)
+	}
+	return crypto.SHA256(data), nil
+}
+
+// FrameRoot returns the Merkle root of the consensus hashes of frames, the
+// one of a checkpoint over them
+func FrameRoot(frames []Frame) ([]byte, error) {
+	hashes := make([][]byte, len(frames))
+	for i, frame := range frames {
+		hash, err := frame.ConsensusHash()
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = hash
+	}
+	return crypto.SimpleHashFromHashes(hashes), nil
+}
+
+// FrameProof returns the proof that the frame of round is part of the root of
+// a checkpoint over frames
+func FrameProof(frames []Frame, round int64) (*crypto.SimpleProof, error) {
+	hashes := make([][]byte, len(frames))
+	index := -1
+	for i, frame := range frames {
+		hash, err := frame.ConsensusHash()
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = hash
+		if frame.Round == round {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("no frame of round %d", round)
+	}
+	return crypto.NewSimpleProof(hashes, index), nil
+}
+
+// SetCheckpointInterval makes the poset commit a checkpoint block every
+// interval consensus rounds, 0 disabling it. It must be the same on every
+// node, the checkpoint blocks being part of the chain.
+func (p *Poset) SetCheckpointInterval(interval int64) {
+	p.checkpointInterval = interval
+}
+
+// checkpoint commits a checkpoint block after the round r when it closes an
+// interval, at the consensus time of the round
+func (p *Poset) checkpoint(r int64, round RoundInfo) error {
+	if p.checkpointInterval <= 0 || (r+1)%p.checkpointInterval != 0 {
+		return nil
+	}
+	checkpoint := Checkpoint{
+		Index:     (r+1)/p.checkpointInterval - 1,
+		FromRound: r + 1 - p.checkpointInterval,
+		ToRound:   r,
+	}
+
+	var frames []Frame
+	for i := checkpoint.FromRound; i <= checkpoint.ToRound; i++ {
+		frame, err := p.GetFrame(i)
+		if err != nil {
+			// the rounds before a Reset are unknown
+			p.logger.WithFields(logrus.Fields{
+				"checkpoint": checkpoint.Index,
+				"round":      i,
+				"error":      err,
+			}).Warn("Missing frame, the checkpoint has no root")
+			frames = nil
+			break
+		}
+		frames = append(frames, frame)
+	}
+	var frameHash []byte
+	if len(frames) > 0 {
+		root, err := FrameRoot(frames)
+		if err != nil {
+			return err
+		}
+		checkpoint.Root = root
+		frameHash, err = frames[len(frames)-1].Hash()
+		if err != nil {
+			return err
+		}
+	}
+
+	block := NewBlock(p.Store.LastBlockIndex()+1, r, frameHash, nil)
+	block.Body.CheckpointRoot = checkpoint.Root
+	block.Body.NetworkID = p.networkID
+	timestamp, err := p.consensusTimestamp(r, round)
+	if err != nil {
+		return err
+	}
+	block.Body.Timestamp = timestamp
+	checkpoint.Block = block.Index()
+	if err := p.Store.SetBlock(block); err != nil {
+		return err
+	}
+	if err := p.Store.SetCheckpoint(checkpoint); err != nil {
+		return err
+	}
+	metrics.IncrCounter("poset.checkpoints", 1)
+	p.logger.WithFields(logrus.Fields{
+		"checkpoint": checkpoint.Index,
+		"block":      checkpoint.Block,
+		"from_round": checkpoint.FromRound,
+		"to_round":   checkpoint.ToRound,
+	}).Debug("Checkpoint")
+
+	if err := p.FlushBatch(); err != nil {
+		return err
+	}
+	if p.commitCh != nil {
+		p.commitCh <- block
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package poset
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression is the codec of the event, block and frame values written by
+// the BadgerStore
+type Compression string
+
+const (
+	// CompressionNone writes values as is
+	CompressionNone Compression = "none"
+	// CompressionSnappy favours speed
+	CompressionSnappy Compression = "snappy"
+	// CompressionZstd favours size
+	CompressionZstd Compression = "zstd"
+)
+
+// recordMagic starts the header of compressed values. No protobuf message
+// starts with it, its wire type 7 being invalid, so values written without
+// compression are still read as is.
+const recordMagic byte = 0xff
+
+// codecs identify the compression of a value in its header
+const (
+	codecSnappy byte = iota + 1
+	codecZstd
+)
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+// zstdCodec returns the shared zstd encoder and decoder, which are safe for
+// concurrent EncodeAll and DecodeAll calls
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder) {
+	zstdOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil)
+		zstdDecoder, _ = zstd.NewReader(nil)
+	})
+	return zstdEncoder, zstdDecoder
+}
+
+// ParseCompression validates the name of a Compression, empty meaning none
+func ParseCompression(name string) (Compression, error) {
+	switch c := Compression(name); c {
+	case "":
+		return CompressionNone, nil
+	case CompressionNone, CompressionSnappy, CompressionZstd:
+		return c, nil
+	}
+	return "", fmt.Errorf("compression must be none, snappy or zstd, got %q", name)
+}
+
+// encodeRecord compresses val with c behind a header naming the codec
+func encodeRecord(c Compression, val []byte) []byte {
+	switch c {
+	case CompressionSnappy:
+		out := make([]byte, 2+snappy.MaxEncodedLen(len(val)))
+		out[0], out[1] = recordMagic, codecSnappy
+		return out[:2+len(snappy.Encode(out[2:], val))]
+	case CompressionZstd:
+		enc, _ := zstdCodec()
+		return enc.EncodeAll(val, []byte{recordMagic, codecZstd})
+	}
+	return val
+}
+
+// decodeRecord returns the value of a record written by encodeRecord with
+// any compression
+func decodeRecord(data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != recordMagic {
+		return data, nil
+	}
+	if len(data) < 2 {
+		return nil, fmt.Errorf("truncated record header")
+	}
+	switch data[1] {
+	case codecSnappy:
+		return snappy.Decode(nil, data[2:])
+	case codecZstd:
+		_, dec := zstdCodec()
+		return dec.DecodeAll(data[2:], nil)
+	}
+	return nil, fmt.Errorf("unknown record codec %d", data[1])
+}
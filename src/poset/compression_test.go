@@ -0,0 +1,40 @@
+package poset
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecordCompression(t *testing.T) {
+	val := bytes.Repeat([]byte("transaction payload "), 100)
+	for _, c := range []Compression{CompressionNone, CompressionSnappy, CompressionZstd} {
+		record := encodeRecord(c, val)
+		if c != CompressionNone && len(record) >= len(val) {
+			t.Errorf("%s: record of %d bytes is not smaller than the value of %d bytes", c, len(record), len(val))
+		}
+		res, err := decodeRecord(record)
+		if err != nil {
+			t.Fatalf("%s: %v", c, err)
+		}
+		if !bytes.Equal(res, val) {
+			t.Fatalf("%s: decoded value differs", c)
+		}
+	}
+
+	// values written without compression are read as is
+	event := NewEvent([][]byte{[]byte("tx")}, nil, nil, []string{"a", "b"}, []byte("creator"), 1, nil)
+	raw, err := event.ProtoMarshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := decodeRecord(raw); err != nil || !bytes.Equal(res, raw) {
+		t.Fatalf("uncompressed record altered: %v", err)
+	}
+
+	if _, err := decodeRecord([]byte{recordMagic, 42, 1}); err == nil {
+		t.Fatal("unknown codecs should be refused")
+	}
+	if _, err := ParseCompression("lz4"); err == nil {
+		t.Fatal("unknown compressions should be refused")
+	}
+}
@@ -0,0 +1,239 @@
+package poset
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// DAG formats
+const (
+	DAGFormatDOT  = "dot"
+	DAGFormatJSON = "json"
+)
+
+// DAG is the event graph of a store with what the consensus decided about
+// its events, to visualize it
+type DAG struct {
+	Participants []DAGParticipant
+	Events       []DAGEvent
+}
+
+// DAGParticipant is the creator of a lane of events
+type DAGParticipant struct {
+	ID        int64
+	PubKeyHex string
+}
+
+// DAGEvent is an event of a DAG. Round and RoundReceived are -1 until they
+// are decided, and ConsensusIndex is the position of the event in the
+// consensus order, -1 when it has not reached consensus.
+type DAGEvent struct {
+	Hash             string
+	CreatorID        int64
+	Index            int64
+	SelfParent       string
+	OtherParent      string `json:",omitempty"`
+	Round            int64
+	LamportTimestamp int64
+	Witness          bool
+	// Famous is "true", "false" or "undecided" for the witnesses
+	Famous         string `json:",omitempty"`
+	RoundReceived  int64
+	ConsensusIndex int64
+	Transactions   int
+}
+
+// BuildDAG reads the events of a store from round fromRound, with the events
+// without a round yet
+func BuildDAG(store Store, fromRound int64) (*DAG, error) {
+	participants, err := store.Participants()
+	if err != nil {
+		return nil, err
+	}
+	dag := &DAG{}
+	for _, p := range participants.ToPeerSlice() {
+		dag.Participants = append(dag.Participants, DAGParticipant{ID: p.ID, PubKeyHex: p.PubKeyHex})
+	}
+
+	var events []Event
+	err = store.IterateTopologicalEvents(func(ev Event) error {
+		if ev.GetRound() == RoundNIL || ev.GetRound() >= fromRound {
+			events = append(events, ev)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the consensus order sorts the events by round received, then by
+	// Lamport timestamp as the frames do
+	var decided []Event
+	for _, ev := range events {
+		if ev.Message.RoundReceived >= 0 {
+			decided = append(decided, ev)
+		}
+	}
+	sort.SliceStable(decided, func(i, j int) bool {
+		ri, rj := decided[i].Message.RoundReceived, decided[j].Message.RoundReceived
+		if ri != rj {
+			return ri < rj
+		}
+		return ByLamportTimestamp(decided).Less(i, j)
+	})
+	order := make(map[string]int64, len(decided))
+	for i, ev := range decided {
+		order[ev.Hex()] = int64(i)
+	}
+
+	rounds := make(map[int64]*RoundInfo)
+	for _, ev := range events {
+		de := DAGEvent{
+			Hash:             ev.Hex(),
+			CreatorID:        ev.CreatorID(),
+			Index:            ev.Index(),
+			SelfParent:       ev.SelfParent(),
+			OtherParent:      ev.OtherParent(),
+			Round:            ev.GetRound(),
+			LamportTimestamp: ev.Message.LamportTimestamp,
+			RoundReceived:    ev.Message.RoundReceived,
+			ConsensusIndex:   -1,
+			Transactions:     len(ev.Transactions()),
+		}
+		if de.RoundReceived < 0 {
+			de.RoundReceived = RoundNIL
+		}
+		if i, ok := order[de.Hash]; ok {
+			de.ConsensusIndex = i
+		}
+		if de.Round != RoundNIL {
+			round, ok := rounds[de.Round]
+			if !ok {
+				if info, err := store.GetRound(de.Round); err == nil {
+					round = &info
+				}
+				rounds[de.Round] = round
+			}
+			if round != nil {
+				if re, ok := round.Message.Events[de.Hash]; ok && re.Witness {
+					de.Witness = true
+					switch re.Famous {
+					case Trilean_TRUE:
+						de.Famous = "true"
+					case Trilean_FALSE:
+						de.Famous = "false"
+					default:
+						de.Famous = "undecided"
+					}
+				}
+			}
+		}
+		dag.Events = append(dag.Events, de)
+	}
+	return dag, nil
+}
+
+// Write writes the DAG in format, DAGFormatDOT or DAGFormatJSON
+func (d *DAG) Write(w io.Writer, format string) error {
+	switch format {
+	case DAGFormatDOT:
+		return d.WriteDOT(w)
+	case DAGFormatJSON:
+		return d.WriteJSON(w)
+	default:
+		return fmt.Errorf("unknown DAG format %q, want %s or %s", format, DAGFormatDOT, DAGFormatJSON)
+	}
+}
+
+// WriteJSON writes the DAG in JSON
+func (d *DAG) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// WriteDOT writes the DAG in the DOT language of GraphViz: a lane per
+// creator, the self-parents in solid edges and the other-parents in dashed
+// ones. The witnesses are boxes, filled when famous, and the events which
+// reached consensus are labeled with their position in the consensus order.
+func (d *DAG) WriteDOT(w io.Writer) error {
+	bw := &dotWriter{w: w}
+	bw.printf("digraph DAG {\n")
+	bw.printf("\trankdir=BT;\n\tnewrank=true;\n\tnode [shape=ellipse, fontsize=10];\n")
+
+	lanes := make(map[int64][]DAGEvent)
+	for _, e := range d.Events {
+		lanes[e.CreatorID] = append(lanes[e.CreatorID], e)
+	}
+	known := make(map[string]bool, len(d.Events))
+	for _, e := range d.Events {
+		known[e.Hash] = true
+	}
+
+	for _, p := range d.Participants {
+		bw.printf("\tsubgraph cluster_%d {\n", p.ID)
+		bw.printf("\t\tlabel=%q;\n\t\tstyle=dashed;\n", fmt.Sprintf("%d %s", p.ID, shortHex(p.PubKeyHex)))
+		for _, e := range lanes[p.ID] {
+			bw.printf("\t\t%q [label=%q%s];\n", e.Hash, dotLabel(e), dotStyle(e))
+		}
+		bw.printf("\t}\n")
+	}
+
+	for _, e := range d.Events {
+		if known[e.SelfParent] {
+			bw.printf("\t%q -> %q;\n", e.Hash, e.SelfParent)
+		}
+		if e.OtherParent != "" && known[e.OtherParent] {
+			bw.printf("\t%q -> %q [style=dashed];\n", e.Hash, e.OtherParent)
+		}
+	}
+
+	bw.printf("}\n")
+	return bw.err
+}
+
+// dotLabel returns the label of an event: its index, round, and position in
+// the consensus order
+func dotLabel(e DAGEvent) string {
+	label := fmt.Sprintf("%d r%d", e.Index, e.Round)
+	if e.ConsensusIndex >= 0 {
+		label += fmt.Sprintf("\n#%d rr%d", e.ConsensusIndex, e.RoundReceived)
+	}
+	return label
+}
+
+// dotStyle returns the attributes marking the witnesses and their fame
+func dotStyle(e DAGEvent) string {
+	if !e.Witness {
+		return ""
+	}
+	switch e.Famous {
+	case "true":
+		return ", shape=box, style=filled, fillcolor=gold"
+	case "false":
+		return ", shape=box, style=filled, fillcolor=lightgrey"
+	default:
+		return ", shape=box"
+	}
+}
+
+func shortHex(s string) string {
+	if len(s) > 12 {
+		return s[:12] + "..."
+	}
+	return s
+}
+
+// dotWriter keeps the first error of its writes
+type dotWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (d *dotWriter) printf(format string, args ...interface{}) {
+	if d.err == nil {
+		_, d.err = fmt.Fprintf(d.w, format, args...)
+	}
+}
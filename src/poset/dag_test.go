@@ -0,0 +1,96 @@
+package poset
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildDAG(t *testing.T) {
+	p, index := initConsensusPoset(false, t)
+
+	p.DivideRounds()
+	p.DecideFame()
+	p.DecideRoundReceived()
+	if err := p.ProcessDecidedRounds(); err != nil {
+		t.Fatal(err)
+	}
+
+	dag, err := BuildDAG(p.Store, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := 0
+	for _, hash := range index {
+		if _, err := p.Store.GetEvent(hash); err == nil {
+			stored++
+		}
+	}
+	if l := len(dag.Events); l != stored {
+		t.Fatalf("DAG should have %d events, not %d", stored, l)
+	}
+	if l := len(dag.Participants); l != p.Participants.Len() {
+		t.Fatalf("DAG should have %d participants, not %d", p.Participants.Len(), l)
+	}
+
+	events := make(map[string]DAGEvent)
+	witnesses := 0
+	for _, e := range dag.Events {
+		events[e.Hash] = e
+		if e.Witness {
+			witnesses++
+			if e.Famous == "" {
+				t.Fatalf("witness %s should have a fame", getName(index, e.Hash))
+			}
+		}
+	}
+	if witnesses == 0 {
+		t.Fatal("DAG should have witnesses")
+	}
+
+	for i, hash := range p.Store.ConsensusEvents() {
+		if ci := events[hash].ConsensusIndex; ci != int64(i) {
+			t.Fatalf("consensus index of %s should be %d, not %d", getName(index, hash), i, ci)
+		}
+	}
+
+	var dot bytes.Buffer
+	if err := dag.Write(&dot, DAGFormatDOT); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"digraph DAG {", "subgraph cluster_", "style=dashed", "fillcolor=gold"} {
+		if !strings.Contains(dot.String(), want) {
+			t.Fatalf("DOT should contain %q:\n%s", want, dot.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := dag.Write(&js, DAGFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var decoded DAG
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Events) != len(dag.Events) {
+		t.Fatalf("JSON should have %d events, not %d", len(dag.Events), len(decoded.Events))
+	}
+
+	if err := dag.Write(&js, "svg"); err == nil {
+		t.Fatal("writing an unknown format should fail")
+	}
+
+	later, err := BuildDAG(p.Store, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range later.Events {
+		if e.Round != RoundNIL && e.Round < 2 {
+			t.Fatalf("DAG from round 2 should not have %s of round %d", getName(index, e.Hash), e.Round)
+		}
+	}
+	if len(later.Events) >= len(dag.Events) {
+		t.Fatalf("DAG from round 2 should have fewer than %d events, not %d", len(dag.Events), len(later.Events))
+	}
+}
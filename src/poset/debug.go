@@ -10,8 +10,9 @@ import (
 func (p *Poset) PrintStat(logger *logrus.Entry) {
 	logger.Warn("****Known events:");
 	for pid_id, index := range p.Store.KnownEvents() {
-		logger.Warn("    index=", index, " peer=", p.Participants.ById[int64(pid_id)].NetAddr,
-			" pubKeyHex=", p.Participants.ById[int64(pid_id)].PubKeyHex)
+		peer, _ := p.Participants.PeerByID(int64(pid_id))
+		logger.Warn("    index=", index, " peer=", peer.NetAddr,
+			" pubKeyHex=", peer.PubKeyHex)
 	}
 }
 
@@ -19,6 +20,10 @@ func (s *BadgerStore) TopologicalEvents() ([]Event, error) {
 	return s.dbTopologicalEvents()
 }
 
+func (s *LevelDBStore) TopologicalEvents() ([]Event, error) {
+	return s.dbTopologicalEvents()
+}
+
 // This is just a stub
 func (s *InmemStore) TopologicalEvents() ([]Event, error) {
 	return []Event{}, nil
@@ -89,7 +89,9 @@ func (this *EventBody) Equals(that *EventBody) bool {
 		reflect.DeepEqual(this.Parents, that.Parents) &&
 		reflect.DeepEqual(this.Creator, that.Creator) &&
 		this.Index == that.Index &&
-		BlockSignatureListEquals(this.BlockSignatures, that.BlockSignatures)
+		BlockSignatureListEquals(this.BlockSignatures, that.BlockSignatures) &&
+		this.NetworkID == that.NetworkID &&
+		this.Timestamp == that.Timestamp
 }
 
 func (e *EventBody) ProtoMarshal() ([]byte, error) {
@@ -207,6 +209,12 @@ func (e *Event) Index() int64 {
 	return e.Message.Body.Index
 }
 
+// Timestamp returns the time the creator created the event at, in unix
+// nanoseconds, 0 for the events without timestamp
+func (e *Event) Timestamp() int64 {
+	return e.Message.Body.Timestamp
+}
+
 func (e *Event) BlockSignatures() []*BlockSignature {
 	return e.Message.Body.BlockSignatures
 }
@@ -337,10 +345,12 @@ func (e *Event) ToWire() WireEvent {
 			CreatorID:            e.Message.CreatorID,
 			Index:                e.Message.Body.Index,
 			BlockSignatures:      e.WireBlockSignatures(),
+			Timestamp:            e.Message.Body.Timestamp,
 		},
 		Signature:    e.Message.Signature,
 		FlagTable:    e.Message.FlagTable,
 		WitnessProof: e.Message.WitnessProof,
+		Hash:         e.Hex(),
 	}
 }
 
@@ -434,6 +444,8 @@ type WireBody struct {
 	CreatorID            int64
 
 	Index int64
+	// Timestamp is the creation time of the event, see EventBody
+	Timestamp int64
 }
 
 type WireEvent struct {
@@ -441,6 +453,9 @@ type WireEvent struct {
 	Signature    string
 	FlagTable    []byte
 	WitnessProof []string
+	// Hash is the hex hash of the event, which the receiver checks against
+	// the body it rebuilds; empty from the nodes which do not send it
+	Hash string
 }
 
 func (we *WireEvent) BlockSignatures(validator []byte) []BlockSignature {
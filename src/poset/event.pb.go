@@ -16,17 +16,20 @@ var _ = math.Inf
 type TransactionType int32
 
 const (
-	TransactionType_PEER_ADD    TransactionType = 0
-	TransactionType_PEER_REMOVE TransactionType = 1
+	TransactionType_PEER_ADD     TransactionType = 0
+	TransactionType_PEER_REMOVE  TransactionType = 1
+	TransactionType_PARAM_CHANGE TransactionType = 2
 )
 
 var TransactionType_name = map[int32]string{
 	0: "PEER_ADD",
 	1: "PEER_REMOVE",
+	2: "PARAM_CHANGE",
 }
 var TransactionType_value = map[string]int32{
-	"PEER_ADD":    0,
-	"PEER_REMOVE": 1,
+	"PEER_ADD":     0,
+	"PEER_REMOVE":  1,
+	"PARAM_CHANGE": 2,
 }
 
 func (x TransactionType) String() string {
@@ -35,8 +38,9 @@ func (x TransactionType) String() string {
 func (TransactionType) EnumDescriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
 
 type InternalTransaction struct {
-	Type TransactionType `protobuf:"varint,1,opt,name=Type,json=type,enum=poset.TransactionType" json:"Type,omitempty"`
-	Peer *peers.Peer     `protobuf:"bytes,2,opt,name=peer" json:"peer,omitempty"`
+	Type  TransactionType `protobuf:"varint,1,opt,name=Type,json=type,enum=poset.TransactionType" json:"Type,omitempty"`
+	Peer  *peers.Peer     `protobuf:"bytes,2,opt,name=peer" json:"peer,omitempty"`
+	Param *ParamChange    `protobuf:"bytes,3,opt,name=param" json:"param,omitempty"`
 }
 
 func (m *InternalTransaction) Reset()                    { *m = InternalTransaction{} }
@@ -58,6 +62,46 @@ func (m *InternalTransaction) GetPeer() *peers.Peer {
 	return nil
 }
 
+func (m *InternalTransaction) GetParam() *ParamChange {
+	if m != nil {
+		return m.Param
+	}
+	return nil
+}
+
+// ParamChange sets a network parameter from ActivationRound on
+type ParamChange struct {
+	Name            string `protobuf:"bytes,1,opt,name=Name,json=name" json:"Name,omitempty"`
+	Value           string `protobuf:"bytes,2,opt,name=Value,json=value" json:"Value,omitempty"`
+	ActivationRound int64  `protobuf:"varint,3,opt,name=ActivationRound,json=activationRound" json:"ActivationRound,omitempty"`
+}
+
+func (m *ParamChange) Reset()                    { *m = ParamChange{} }
+func (m *ParamChange) String() string            { return proto.CompactTextString(m) }
+func (*ParamChange) ProtoMessage()               {}
+func (*ParamChange) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+
+func (m *ParamChange) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ParamChange) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *ParamChange) GetActivationRound() int64 {
+	if m != nil {
+		return m.ActivationRound
+	}
+	return 0
+}
+
 type BlockSignature struct {
 	Validator []byte `protobuf:"bytes,1,opt,name=Validator,json=validator,proto3" json:"Validator,omitempty"`
 	Index     int64  `protobuf:"varint,2,opt,name=Index,json=index" json:"Index,omitempty"`
@@ -67,7 +111,7 @@ type BlockSignature struct {
 func (m *BlockSignature) Reset()                    { *m = BlockSignature{} }
 func (m *BlockSignature) String() string            { return proto.CompactTextString(m) }
 func (*BlockSignature) ProtoMessage()               {}
-func (*BlockSignature) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+func (*BlockSignature) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
 
 func (m *BlockSignature) GetValidator() []byte {
 	if m != nil {
@@ -97,12 +141,14 @@ type EventBody struct {
 	Creator              []byte                 `protobuf:"bytes,4,opt,name=Creator,json=creator,proto3" json:"Creator,omitempty"`
 	Index                int64                  `protobuf:"varint,5,opt,name=Index,json=index" json:"Index,omitempty"`
 	BlockSignatures      []*BlockSignature      `protobuf:"bytes,6,rep,name=BlockSignatures,json=blockSignatures" json:"BlockSignatures,omitempty"`
+	NetworkID            string                 `protobuf:"bytes,7,opt,name=NetworkID,json=networkID" json:"NetworkID,omitempty"`
+	Timestamp            int64                  `protobuf:"varint,8,opt,name=Timestamp,json=timestamp" json:"Timestamp,omitempty"`
 }
 
 func (m *EventBody) Reset()                    { *m = EventBody{} }
 func (m *EventBody) String() string            { return proto.CompactTextString(m) }
 func (*EventBody) ProtoMessage()               {}
-func (*EventBody) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+func (*EventBody) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
 
 func (m *EventBody) GetTransactions() [][]byte {
 	if m != nil {
@@ -146,6 +192,20 @@ func (m *EventBody) GetBlockSignatures() []*BlockSignature {
 	return nil
 }
 
+func (m *EventBody) GetNetworkID() string {
+	if m != nil {
+		return m.NetworkID
+	}
+	return ""
+}
+
+func (m *EventBody) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
 type EventMessage struct {
 	Body                 *EventBody `protobuf:"bytes,1,opt,name=Body,json=body" json:"Body,omitempty"`
 	Signature            string     `protobuf:"bytes,2,opt,name=Signature,json=signature" json:"Signature,omitempty"`
@@ -167,7 +227,7 @@ type EventMessage struct {
 func (m *EventMessage) Reset()                    { *m = EventMessage{} }
 func (m *EventMessage) String() string            { return proto.CompactTextString(m) }
 func (*EventMessage) ProtoMessage()               {}
-func (*EventMessage) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+func (*EventMessage) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{4} }
 
 func (m *EventMessage) GetBody() *EventBody {
 	if m != nil {
@@ -276,6 +336,7 @@ func (m *EventMessage) GetHash() []byte {
 
 func init() {
 	proto.RegisterType((*InternalTransaction)(nil), "poset.InternalTransaction")
+	proto.RegisterType((*ParamChange)(nil), "poset.ParamChange")
 	proto.RegisterType((*BlockSignature)(nil), "poset.BlockSignature")
 	proto.RegisterType((*EventBody)(nil), "poset.EventBody")
 	proto.RegisterType((*EventMessage)(nil), "poset.EventMessage")
@@ -285,43 +346,49 @@ func init() {
 func init() { proto.RegisterFile("event.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 601 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x94, 0xd1, 0x6b, 0xdb, 0x3e,
-	0x10, 0xc7, 0x7f, 0x89, 0x9d, 0xa6, 0x3e, 0xbb, 0x75, 0xd0, 0x2f, 0x1b, 0xa2, 0x0c, 0x16, 0x42,
-	0x1f, 0x4c, 0xa1, 0xc9, 0xc8, 0x9e, 0xc7, 0x58, 0xd7, 0x94, 0x16, 0xd6, 0x35, 0xa8, 0xa1, 0x7b,
-	0x1c, 0xb2, 0x73, 0xb1, 0xcd, 0x1c, 0xcb, 0x48, 0x6a, 0xd7, 0xfe, 0x0b, 0x7b, 0xda, 0x9f, 0x3c,
-	0x24, 0xa7, 0xad, 0x13, 0xf2, 0x12, 0xb8, 0xef, 0xdd, 0x7d, 0x4f, 0xf7, 0x91, 0x15, 0xf0, 0xf1,
-	0x01, 0x4b, 0x3d, 0xaa, 0xa4, 0xd0, 0x82, 0x74, 0x2a, 0xa1, 0x50, 0x1f, 0x7d, 0x4a, 0x73, 0x9d,
-	0xdd, 0xc7, 0xa3, 0x44, 0xac, 0xc6, 0x17, 0xbc, 0xd4, 0x62, 0x75, 0xba, 0x14, 0xf7, 0xe5, 0x82,
-	0xeb, 0x5c, 0x94, 0xe3, 0x54, 0x9c, 0x16, 0x3c, 0xc9, 0x50, 0xe5, 0x6a, 0xac, 0x64, 0x32, 0xae,
-	0x10, 0xa5, 0xb2, 0xbf, 0xb5, 0xcb, 0x30, 0x86, 0xff, 0xaf, 0x4a, 0x8d, 0xb2, 0xe4, 0xc5, 0x5c,
-	0xf2, 0x52, 0xf1, 0xc4, 0xf4, 0x91, 0x13, 0x70, 0xe7, 0x4f, 0x15, 0xd2, 0xd6, 0xa0, 0x15, 0x1d,
-	0x4e, 0xde, 0x8e, 0xec, 0xac, 0x51, 0xa3, 0xc2, 0x64, 0x99, 0xab, 0x9f, 0x2a, 0x24, 0xef, 0xc1,
-	0x35, 0x86, 0xb4, 0x3d, 0x68, 0x45, 0xfe, 0xc4, 0x1f, 0xd9, 0x19, 0xa3, 0x19, 0xa2, 0x64, 0x36,
-	0x31, 0x8c, 0xe1, 0xf0, 0xac, 0x10, 0xc9, 0xaf, 0xdb, 0x3c, 0x2d, 0xb9, 0xbe, 0x97, 0x48, 0xde,
-	0x81, 0x77, 0xc7, 0x8b, 0x7c, 0xc1, 0xb5, 0x90, 0x76, 0x46, 0xc0, 0xbc, 0x87, 0x67, 0x81, 0xf4,
-	0xa1, 0x73, 0x55, 0x2e, 0xf0, 0xd1, 0x3a, 0x3a, 0xac, 0x93, 0x9b, 0xc0, 0xf4, 0xbc, 0x18, 0x50,
-	0x67, 0xd0, 0x8a, 0x3c, 0xe6, 0xa9, 0x67, 0x61, 0xf8, 0xa7, 0x0d, 0xde, 0xd4, 0xd0, 0x39, 0x13,
-	0x8b, 0x27, 0x32, 0x84, 0xa0, 0x71, 0x56, 0x45, 0x5b, 0x03, 0x27, 0x0a, 0x58, 0xa0, 0x1b, 0x1a,
-	0xf9, 0x0e, 0xfd, 0x1d, 0x9b, 0x2b, 0xda, 0x1e, 0x38, 0x91, 0x3f, 0x39, 0x5a, 0xaf, 0xbc, 0xa3,
-	0x84, 0xf5, 0xf3, 0x1d, 0x7d, 0x84, 0x42, 0x77, 0xc6, 0x25, 0x96, 0x5a, 0x51, 0x67, 0xe0, 0x44,
-	0x1e, 0xeb, 0x56, 0x75, 0x68, 0x32, 0x5f, 0x25, 0xda, 0x5d, 0x5d, 0xbb, 0x6b, 0x37, 0xa9, 0xc3,
-	0xd7, 0x4d, 0x3b, 0xcd, 0x4d, 0x3f, 0x43, 0xb8, 0xc9, 0x4b, 0xd1, 0x3d, 0x7b, 0xa8, 0x37, 0xeb,
-	0x43, 0x6d, 0x66, 0x59, 0x18, 0x6f, 0x56, 0x0f, 0xff, 0xba, 0x10, 0x58, 0x18, 0xd7, 0xa8, 0x14,
-	0x4f, 0x91, 0x1c, 0x83, 0x6b, 0xb8, 0x58, 0xd4, 0xfe, 0xa4, 0xb7, 0xb6, 0x79, 0xe1, 0xc5, 0xdc,
-	0xd8, 0x50, 0xdb, 0x20, 0xdc, 0xde, 0x22, 0x6c, 0xb2, 0x17, 0x05, 0x4f, 0xe7, 0x3c, 0x2e, 0x6a,
-	0xfe, 0x01, 0xf3, 0x96, 0xcf, 0x82, 0x21, 0xfe, 0x23, 0xd7, 0x25, 0x2a, 0x35, 0x93, 0x42, 0x2c,
-	0xa9, 0x6b, 0x11, 0x04, 0xbf, 0x1b, 0x1a, 0x89, 0x20, 0xbc, 0xc5, 0x62, 0x59, 0x53, 0x6a, 0xee,
-	0x1d, 0xaa, 0x4d, 0x99, 0x4c, 0xa0, 0x7f, 0xa3, 0x33, 0x94, 0xb5, 0xb6, 0x86, 0x77, 0x75, 0x4e,
-	0xf7, 0x6c, 0x79, 0x5f, 0xec, 0xc8, 0x91, 0x13, 0xe8, 0x35, 0x7a, 0x6a, 0xfb, 0xae, 0xad, 0xef,
-	0x89, 0x2d, 0xdd, 0xec, 0xf2, 0x6a, 0xba, 0x6f, 0x8b, 0xbc, 0xa4, 0xe9, 0x34, 0x17, 0x95, 0x28,
-	0x44, 0x9a, 0x27, 0xbc, 0xa8, 0x9d, 0xbc, 0xda, 0x49, 0x6f, 0xe9, 0xa4, 0x07, 0xce, 0x25, 0x3e,
-	0x52, 0xb0, 0xb4, 0x9c, 0x0c, 0x1f, 0x4d, 0xf7, 0x37, 0xbe, 0xaa, 0x84, 0xd4, 0xf3, 0x7c, 0x85,
-	0x4a, 0xf3, 0x55, 0x45, 0xfd, 0xba, 0xbb, 0xd8, 0xd2, 0xcd, 0xfd, 0x33, 0xf3, 0x58, 0x69, 0x50,
-	0xdf, 0xbf, 0x34, 0x01, 0x39, 0x86, 0x03, 0xab, 0x32, 0x4c, 0x30, 0x7f, 0xc0, 0x05, 0x3d, 0xb0,
-	0xd9, 0x03, 0xd9, 0x14, 0x9b, 0x5f, 0xd5, 0xa1, 0x9d, 0xfe, 0xf2, 0x55, 0x11, 0x70, 0x2f, 0xb9,
-	0xca, 0x68, 0x68, 0x2f, 0xc9, 0xcd, 0xb8, 0xca, 0x4e, 0x3e, 0x40, 0xb8, 0xf5, 0x7a, 0x49, 0x00,
-	0xfb, 0xb3, 0xe9, 0x94, 0xfd, 0xfc, 0x72, 0x7e, 0xde, 0xfb, 0x8f, 0x84, 0xe0, 0xdb, 0x88, 0x4d,
-	0xaf, 0x6f, 0xee, 0xa6, 0xbd, 0x56, 0xbc, 0x67, 0xff, 0x20, 0x3e, 0xfe, 0x0b, 0x00, 0x00, 0xff,
-	0xff, 0xd4, 0xc4, 0xee, 0xd8, 0x75, 0x04, 0x00, 0x00,
+	// 694 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x6d, 0x54, 0x5d, 0x6f, 0xda, 0x30,
+	0x14, 0x1d, 0x24, 0x94, 0xc6, 0xa4, 0x4d, 0xe4, 0xb1, 0x29, 0xaa, 0x26, 0x6d, 0x42, 0x7b, 0xa8,
+	0x2a, 0x15, 0x24, 0xfa, 0x3c, 0x4d, 0xb4, 0xa5, 0x6b, 0xa5, 0x95, 0x22, 0x17, 0x75, 0x8f, 0x95,
+	0x09, 0x86, 0x44, 0x0d, 0x71, 0x64, 0x1b, 0xba, 0xfe, 0x87, 0x3d, 0xec, 0x57, 0xed, 0x77, 0xed,
+	0xda, 0x86, 0x36, 0x20, 0x5e, 0xac, 0xdc, 0x73, 0x3f, 0xce, 0xbd, 0xe7, 0x3a, 0x46, 0x0d, 0xb6,
+	0x64, 0xb9, 0x6a, 0x17, 0x82, 0x2b, 0x8e, 0x6b, 0x05, 0x97, 0x4c, 0x1d, 0x7d, 0x9b, 0xa5, 0x2a,
+	0x59, 0x8c, 0xdb, 0x31, 0x9f, 0x77, 0xae, 0x68, 0xae, 0xf8, 0xfc, 0x74, 0xca, 0x17, 0xf9, 0x84,
+	0xaa, 0x94, 0xe7, 0x9d, 0x19, 0x3f, 0xcd, 0x68, 0x9c, 0x30, 0x99, 0xca, 0x8e, 0x14, 0x71, 0xa7,
+	0x60, 0x4c, 0x48, 0x73, 0xda, 0x2a, 0xad, 0x3f, 0x15, 0xf4, 0xfe, 0x26, 0x57, 0x4c, 0xe4, 0x34,
+	0x1b, 0x09, 0x9a, 0x4b, 0x1a, 0xeb, 0x44, 0x7c, 0x82, 0xdc, 0xd1, 0x4b, 0xc1, 0xa2, 0xca, 0x97,
+	0xca, 0xf1, 0x61, 0xf7, 0x63, 0xdb, 0x90, 0xb5, 0x4b, 0x11, 0xda, 0x4b, 0x5c, 0x05, 0x27, 0xfe,
+	0x8c, 0x5c, 0x5d, 0x31, 0xaa, 0x42, 0x6c, 0xa3, 0xdb, 0x68, 0x1b, 0x92, 0xf6, 0x10, 0x4e, 0x62,
+	0x1c, 0xf8, 0x18, 0xd5, 0x0a, 0x2a, 0xe8, 0x3c, 0x72, 0x4c, 0x04, 0x5e, 0x55, 0x1b, 0x6a, 0xec,
+	0x22, 0xa1, 0xf9, 0x8c, 0x11, 0x1b, 0xd0, 0xa2, 0xa8, 0x51, 0x42, 0x31, 0x46, 0xee, 0x80, 0xce,
+	0x6d, 0x17, 0x1e, 0x71, 0x73, 0xf8, 0xc6, 0x4d, 0x54, 0x7b, 0xa0, 0xd9, 0x82, 0x19, 0x3a, 0x8f,
+	0xd4, 0x96, 0xda, 0x00, 0x8a, 0xa0, 0x07, 0x7d, 0x2d, 0xcd, 0xd8, 0x44, 0x0b, 0x60, 0xc8, 0x1c,
+	0x12, 0xd0, 0x4d, 0xb8, 0x35, 0x46, 0x87, 0xe7, 0x19, 0x8f, 0x9f, 0xee, 0xd3, 0x59, 0x4e, 0xd5,
+	0x42, 0x30, 0xfc, 0x09, 0x79, 0x50, 0x31, 0x05, 0xc9, 0xb8, 0x30, 0x54, 0x3e, 0xf1, 0x96, 0x6b,
+	0x40, 0xf3, 0xdd, 0xe4, 0x13, 0xf6, 0xdb, 0xf0, 0x39, 0xa4, 0x96, 0x6a, 0x43, 0xe7, 0xbc, 0x16,
+	0x30, 0x4c, 0x1e, 0xf1, 0xe4, 0x1a, 0x68, 0xfd, 0xab, 0x22, 0xaf, 0xaf, 0x77, 0x75, 0xce, 0x27,
+	0x2f, 0xb8, 0x85, 0xfc, 0x92, 0x70, 0x12, 0x28, 0x1c, 0xa0, 0xf0, 0x55, 0x09, 0xc3, 0x03, 0xd4,
+	0xdc, 0xb1, 0x06, 0x09, 0xa4, 0x0e, 0x28, 0x76, 0xb4, 0x52, 0x6c, 0x47, 0x08, 0x69, 0xa6, 0x3b,
+	0xf2, 0x70, 0x84, 0xea, 0x20, 0x24, 0x74, 0x20, 0xa1, 0x3b, 0x07, 0xba, 0xab, 0x17, 0xd6, 0xd4,
+	0x9e, 0x0b, 0xc1, 0xcc, 0xac, 0xae, 0x99, 0xb5, 0x1e, 0x5b, 0xf3, 0x6d, 0xd2, 0x5a, 0x79, 0xd2,
+	0xef, 0x28, 0xd8, 0xd4, 0x4b, 0x46, 0x7b, 0xa6, 0xa9, 0x0f, 0xab, 0xa6, 0x36, 0xbd, 0x24, 0x18,
+	0x6f, 0x46, 0x6b, 0xa9, 0x06, 0x4c, 0x3d, 0x73, 0xf1, 0x74, 0x73, 0x19, 0xd5, 0xad, 0x54, 0xf9,
+	0x1a, 0xd0, 0xde, 0x51, 0x3a, 0x67, 0x52, 0xd1, 0x79, 0x11, 0xed, 0x1b, 0x62, 0x4f, 0xad, 0x81,
+	0xd6, 0x5f, 0x17, 0xf9, 0x46, 0xc8, 0x5b, 0x26, 0x25, 0x85, 0x1b, 0xf1, 0x15, 0xb9, 0x5a, 0x53,
+	0xb3, 0xa6, 0x46, 0x37, 0x5c, 0xb5, 0xf0, 0xaa, 0x35, 0x71, 0xc7, 0x5a, 0xf1, 0x8d, 0xed, 0x54,
+	0xb7, 0xb6, 0xa3, 0xbd, 0x57, 0x19, 0x9d, 0x8d, 0xe8, 0x38, 0xb3, 0xbb, 0x83, 0x7d, 0x4f, 0xd7,
+	0x80, 0xde, 0xd6, 0xaf, 0x54, 0xe5, 0xc0, 0x37, 0x14, 0x9c, 0x4f, 0x41, 0x24, 0x2d, 0x9f, 0xff,
+	0x5c, 0xc2, 0xf4, 0x6d, 0xbb, 0x67, 0xd9, 0xd4, 0x2a, 0x5c, 0xd6, 0x2c, 0x90, 0x9b, 0x30, 0xee,
+	0xa2, 0xe6, 0x9d, 0x4a, 0x98, 0xb0, 0xd8, 0x4a, 0x78, 0xd0, 0x61, 0xcf, 0x84, 0x37, 0xf9, 0x0e,
+	0x1f, 0xfc, 0x7b, 0x61, 0x29, 0xc7, 0x96, 0xaf, 0x9b, 0xf8, 0x90, 0x6f, 0xe1, 0x7a, 0x96, 0xb7,
+	0xa2, 0x2b, 0xf9, 0xe2, 0x72, 0xa5, 0x11, 0x2f, 0x78, 0xc6, 0x67, 0x69, 0x4c, 0x33, 0x5b, 0xc9,
+	0xb3, 0x95, 0xd4, 0x16, 0x8e, 0x43, 0xe4, 0x5c, 0x83, 0x1b, 0x19, 0xb5, 0x9c, 0x04, 0x10, 0xc8,
+	0xfe, 0x09, 0x4b, 0xe0, 0x42, 0xbd, 0x6d, 0xa8, 0x61, 0xb3, 0xb3, 0x2d, 0x5c, 0xdf, 0x1d, 0xfb,
+	0xd7, 0xf9, 0xf6, 0xee, 0x08, 0x6d, 0xc0, 0xb6, 0x0e, 0x0c, 0x4a, 0x58, 0xcc, 0xd2, 0x25, 0x9b,
+	0x44, 0x07, 0xc6, 0x7b, 0x20, 0xca, 0x60, 0xf9, 0x46, 0x1e, 0x1a, 0xf6, 0xd7, 0x1b, 0x09, 0xff,
+	0xff, 0x35, 0x95, 0x49, 0x14, 0x98, 0x25, 0xb9, 0x09, 0x7c, 0x9f, 0x9c, 0xa3, 0x60, 0xeb, 0x19,
+	0xc2, 0x3e, 0xda, 0x1f, 0xf6, 0xfb, 0xe4, 0xb1, 0x77, 0x79, 0x19, 0xbe, 0xc3, 0x01, 0xbc, 0x21,
+	0xda, 0x22, 0xfd, 0xdb, 0xbb, 0x87, 0x7e, 0x58, 0x81, 0xc9, 0xfc, 0x61, 0x8f, 0xf4, 0x6e, 0x1f,
+	0x2f, 0xae, 0x7b, 0x83, 0x1f, 0xfd, 0xb0, 0x3a, 0xde, 0x33, 0x8f, 0xdf, 0xd9, 0x7f, 0x35, 0x4b,
+	0x34, 0xf9, 0x51, 0x05, 0x00, 0x00,
 }
@@ -76,6 +76,36 @@ func TestSignEvent(t *testing.T) {
 	}
 }
 
+func TestSignEventKeyTypes(t *testing.T) {
+	defer crypto.SetKeyType(crypto.KeyP256)
+	for _, keyType := range []crypto.KeyType{crypto.KeySecp256k1, crypto.KeyEd25519} {
+		crypto.SetKeyType(keyType)
+		privateKey, err := crypto.GenerateECDSAKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body := createDummyEventBody()
+		body.Creator = crypto.FromECDSAPub(&privateKey.PublicKey)
+		event := Event{Message: EventMessage{Body: &body}}
+		if err := event.Sign(privateKey); err != nil {
+			t.Fatalf("Error signing Event with a %s key: %s", keyType, err)
+		}
+		if res, err := event.Verify(); err != nil || !res {
+			t.Fatalf("the %s signature of the event should verify: %v", keyType, err)
+		}
+
+		block := NewBlock(0, 1, []byte("framehash"), [][]byte{[]byte("abc")})
+		sig, err := block.Sign(privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res, err := block.Verify(sig); err != nil || !res {
+			t.Fatalf("the %s signature of the block should verify: %v", keyType, err)
+		}
+	}
+}
+
 func TestMarshallEvent(t *testing.T) {
 	privateKey, _ := crypto.GenerateECDSAKey()
 	publicKeyBytes := crypto.FromECDSAPub(&privateKey.PublicKey)
@@ -133,6 +163,7 @@ func TestWireEvent(t *testing.T) {
 			BlockSignatures:      event.WireBlockSignatures(),
 		},
 		Signature: event.Message.Signature,
+		Hash:      event.Hex(),
 	}
 
 	wireEvent := event.ToWire()
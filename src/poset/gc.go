@@ -0,0 +1,139 @@
+package poset
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// Badger appends the values to its value log and never reclaims the space of
+// the overwritten and deleted ones, by pruning in particular, until the log
+// is garbage collected: the files mostly made of stale values are rewritten
+// and deleted.
+
+const (
+	// DefaultGCInterval is the period of the value log GC of a badger store
+	DefaultGCInterval = 10 * time.Minute
+	// DefaultGCDiscardRatio is the share of stale values a value log file
+	// must hold to be rewritten
+	DefaultGCDiscardRatio = 0.5
+)
+
+// CompactionStats reports a garbage collection of the value log
+type CompactionStats struct {
+	// LSMBefore and VlogBefore are the sizes in bytes of the LSM tree and of
+	// the value log before the collection, LSMAfter and VlogAfter after it
+	LSMBefore  int64
+	VlogBefore int64
+	LSMAfter   int64
+	VlogAfter  int64
+	// Rewritten is the number of value log files rewritten
+	Rewritten int
+	Duration  time.Duration
+}
+
+// CompactStore is a Store whose database can be compacted, a BadgerStore or
+// a HybridStore
+type CompactStore interface {
+	Compact(discardRatio float64) (CompactionStats, error)
+}
+
+// gc schedules the value log GC of a BadgerStore
+type gc struct {
+	sync.Mutex // serialises the collections
+	stopCh     chan struct{}
+	doneCh     chan struct{}
+}
+
+// Compact garbage collects the value log, rewriting the files holding at
+// least discardRatio of stale values until none is left
+func (s *BadgerStore) Compact(discardRatio float64) (CompactionStats, error) {
+	if discardRatio <= 0 || discardRatio >= 1 {
+		return CompactionStats{}, fmt.Errorf("discard ratio must be between 0 and 1, got %v", discardRatio)
+	}
+	s.gc.Lock()
+	defer s.gc.Unlock()
+
+	start := time.Now()
+	var stats CompactionStats
+	stats.LSMBefore, stats.VlogBefore = s.db.Size()
+	var err error
+	for {
+		if err = s.db.RunValueLogGC(discardRatio); err != nil {
+			break
+		}
+		stats.Rewritten++
+	}
+	if err == badger.ErrNoRewrite {
+		err = nil
+	}
+	stats.LSMAfter, stats.VlogAfter = s.db.Size()
+	stats.Duration = time.Since(start)
+
+	metrics.SetGauge("store.lsm_size", float64(stats.LSMAfter))
+	metrics.SetGauge("store.vlog_size", float64(stats.VlogAfter))
+	metrics.IncrCounter("store.gc.rewritten", int64(stats.Rewritten))
+	metrics.AddSample("store.gc.reclaimed", float64(stats.VlogBefore-stats.VlogAfter))
+	metrics.MeasureSince("store.gc.duration", start)
+	return stats, err
+}
+
+// StartGC garbage collects the value log every interval with discardRatio,
+// until Close. It does nothing if interval is not positive or the GC is
+// already running.
+func (s *BadgerStore) StartGC(interval time.Duration, discardRatio float64, logger *logrus.Entry) {
+	if interval <= 0 || s.gc.stopCh != nil {
+		return
+	}
+	s.gc.stopCh = make(chan struct{})
+	s.gc.doneCh = make(chan struct{})
+	go func() {
+		defer close(s.gc.doneCh)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				stats, err := s.Compact(discardRatio)
+				if err != nil {
+					metrics.IncrCounter("store.gc.errors", 1)
+					logger.WithError(err).Warn("Value log GC")
+					continue
+				}
+				logger.WithFields(logrus.Fields{
+					"rewritten":   stats.Rewritten,
+					"vlog_before": stats.VlogBefore,
+					"vlog_after":  stats.VlogAfter,
+					"duration":    stats.Duration,
+				}).Debug("Value log GC")
+			case <-s.gc.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// stopGC stops the GC started by StartGC and waits for it
+func (s *BadgerStore) stopGC() {
+	if s.gc.stopCh == nil {
+		return
+	}
+	close(s.gc.stopCh)
+	<-s.gc.doneCh
+	s.gc.stopCh = nil
+}
+
+// CompactStore garbage collects the value log of the store, if it supports
+// it
+func (p *Poset) CompactStore(discardRatio float64) (CompactionStats, error) {
+	store, ok := p.Store.(CompactStore)
+	if !ok {
+		return CompactionStats{}, fmt.Errorf("the store does not support compaction")
+	}
+	return store.Compact(discardRatio)
+}
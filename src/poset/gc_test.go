@@ -0,0 +1,41 @@
+package poset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestBadgerStoreCompact(t *testing.T) {
+	store, participants := initBadgerStore(10, t)
+	defer removeBadgerStore(store, t)
+
+	// overwrite the rounds so that the value log holds stale values
+	for i := 0; i < 100; i++ {
+		round := NewRoundInfo()
+		for _, p := range participants {
+			round.AddEvent(p.hex, true)
+		}
+		if err := store.dbSetRound(0, *round); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, ratio := range []float64{0, 1, -0.5} {
+		if _, err := store.Compact(ratio); err == nil {
+			t.Fatalf("discard ratio %v should be refused", ratio)
+		}
+	}
+	stats, err := store.Compact(DefaultGCDiscardRatio)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.VlogAfter > stats.VlogBefore {
+		t.Fatalf("the value log grew from %d to %d bytes", stats.VlogBefore, stats.VlogAfter)
+	}
+
+	// Close stops the scheduled GC
+	store.StartGC(time.Millisecond, DefaultGCDiscardRatio, logrus.NewEntry(logrus.New()))
+	time.Sleep(10 * time.Millisecond)
+}
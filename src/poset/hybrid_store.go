@@ -0,0 +1,286 @@
+package poset
+
+import (
+	"sync"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+// HybridStore is a BadgerStore which keeps the events and rounds it is
+// given in its LRU caches, like an InmemStore, and writes them to badger
+// only when they leave the caches:
+//
+//   - an event or round evicted from its cache is spilled to badger, and is
+//     read from there like any item missing from the caches of a BadgerStore
+//   - the events are written at the end of every batch, see BatchStore, and
+//     immediately outside batches, so that the DAG survives a crash
+//   - the rounds, which are rewritten by every event inserted and which
+//     Bootstrap recomputes from the events, are written when evicted and by
+//     Close only
+//
+// Blocks, frames and checkpoints are written through, like in a
+// BadgerStore. The database is a badger database of the same layout.
+type HybridStore struct {
+	*BadgerStore
+	dirtyLock   sync.Mutex
+	dirtyEvents map[string]bool // hex of the cached events not yet written
+	dirtyRounds map[int64]bool  // the cached rounds not yet written
+	spillErr    error           // the first error spilling an evicted item
+}
+
+// NewHybridStore creates a brand new HybridStore with a new database
+func NewHybridStore(participants *peers.Peers, cacheSize int, path string) (*HybridStore, error) {
+	store, err := NewBadgerStore(participants, cacheSize, path)
+	if err != nil {
+		return nil, err
+	}
+	return newHybridStore(store)
+}
+
+// LoadHybridStore creates a HybridStore from an existing database
+func LoadHybridStore(cacheSize int, path string) (*HybridStore, error) {
+	store, err := LoadBadgerStore(cacheSize, path)
+	if err != nil {
+		return nil, err
+	}
+	return newHybridStore(store)
+}
+
+// LoadOrCreateHybridStore loads the database at path, or creates it
+func LoadOrCreateHybridStore(participants *peers.Peers, cacheSize int, path string) (*HybridStore, error) {
+	store, err := LoadOrCreateBadgerStore(participants, cacheSize, path)
+	if err != nil {
+		return nil, err
+	}
+	return newHybridStore(store)
+}
+
+func newHybridStore(store *BadgerStore) (*HybridStore, error) {
+	s := &HybridStore{
+		BadgerStore: store,
+		dirtyEvents: make(map[string]bool),
+		dirtyRounds: make(map[int64]bool),
+	}
+	if err := store.inmemStore.setEvictHooks(s.spillEvent, s.spillRound); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// spillEvent writes an event evicted from the cache, unless it was written
+func (s *HybridStore) spillEvent(key, value interface{}) {
+	s.dirtyLock.Lock()
+	dirty := s.dirtyEvents[key.(string)]
+	delete(s.dirtyEvents, key.(string))
+	s.dirtyLock.Unlock()
+	if !dirty {
+		return
+	}
+	metrics.IncrCounter("store.events.spilled", 1)
+	s.setSpillErr(s.dbSetEvents([]Event{value.(Event)}))
+}
+
+// spillRound writes a round evicted from the cache, unless it was written
+func (s *HybridStore) spillRound(key, value interface{}) {
+	s.dirtyLock.Lock()
+	dirty := s.dirtyRounds[key.(int64)]
+	delete(s.dirtyRounds, key.(int64))
+	s.dirtyLock.Unlock()
+	if !dirty {
+		return
+	}
+	metrics.IncrCounter("store.rounds.spilled", 1)
+	s.setSpillErr(s.dbSetRound(key.(int64), value.(RoundInfo)))
+}
+
+// setSpillErr records the first error of the evictions, which have no caller
+// to return it to, for the next write
+func (s *HybridStore) setSpillErr(err error) {
+	if err == nil {
+		return
+	}
+	s.dirtyLock.Lock()
+	defer s.dirtyLock.Unlock()
+	if s.spillErr == nil {
+		s.spillErr = err
+	}
+}
+
+// takeSpillErr returns and clears the recorded error of the evictions
+func (s *HybridStore) takeSpillErr() error {
+	s.dirtyLock.Lock()
+	defer s.dirtyLock.Unlock()
+	err := s.spillErr
+	s.spillErr = nil
+	return err
+}
+
+// flushEvents writes the cached events not yet written
+func (s *HybridStore) flushEvents() error {
+	s.dirtyLock.Lock()
+	dirty := s.dirtyEvents
+	s.dirtyEvents = make(map[string]bool)
+	s.dirtyLock.Unlock()
+	if len(dirty) == 0 {
+		return s.takeSpillErr()
+	}
+
+	events := make([]Event, 0, len(dirty))
+	for key := range dirty {
+		if event, ok := s.inmemStore.eventCache.Peek(key); ok {
+			events = append(events, event.(Event))
+		}
+	}
+	if err := s.dbSetEvents(events); err != nil {
+		s.dirtyLock.Lock()
+		for key := range dirty {
+			s.dirtyEvents[key] = true
+		}
+		s.dirtyLock.Unlock()
+		return err
+	}
+	return s.takeSpillErr()
+}
+
+// flushRounds writes the cached rounds not yet written
+func (s *HybridStore) flushRounds() error {
+	s.dirtyLock.Lock()
+	dirty := s.dirtyRounds
+	s.dirtyRounds = make(map[int64]bool)
+	s.dirtyLock.Unlock()
+
+	for r := range dirty {
+		round, ok := s.inmemStore.roundCache.Peek(r)
+		if !ok {
+			continue
+		}
+		if err := s.dbSetRound(r, round.(RoundInfo)); err != nil {
+			return err
+		}
+	}
+	return s.takeSpillErr()
+}
+
+// SetEvent caches the event, which is written at the end of the batch, or
+// now outside batches
+func (s *HybridStore) SetEvent(event Event) error {
+	// marked before being cached, so that an eviction of the cache does
+	// not miss it
+	s.dirtyLock.Lock()
+	s.dirtyEvents[event.Hex()] = true
+	s.dirtyLock.Unlock()
+	if err := s.inmemStore.SetEvent(event); err != nil {
+		return err
+	}
+	if !s.Batching() {
+		return s.flushEvents()
+	}
+	return s.takeSpillErr()
+}
+
+// ParticipantEvents returns the hashes of the events of participant after
+// skip, from badger when they left the cache
+func (s *HybridStore) ParticipantEvents(participant string, skip int64) ([]string, error) {
+	res, err := s.inmemStore.ParticipantEvents(participant, skip)
+	if err != nil {
+		if err := s.flushEvents(); err != nil {
+			return nil, err
+		}
+		res, err = s.dbParticipantEvents(participant, skip)
+	}
+	return res, err
+}
+
+// ParticipantEvent returns the hash of the event of participant at index,
+// from badger when it left the cache
+func (s *HybridStore) ParticipantEvent(participant string, index int64) (string, error) {
+	result, err := s.inmemStore.ParticipantEvent(participant, index)
+	if err != nil {
+		if err := s.flushEvents(); err != nil {
+			return "", err
+		}
+		result, err = s.dbParticipantEvent(participant, index)
+	}
+	return result, mapError(err, "ParticipantEvent", string(participantEventKey(participant, index)))
+}
+
+// SetRound caches the round, which is written when evicted
+func (s *HybridStore) SetRound(r int64, round RoundInfo) error {
+	s.dirtyLock.Lock()
+	s.dirtyRounds[r] = true
+	s.dirtyLock.Unlock()
+	if err := s.inmemStore.SetRound(r, round); err != nil {
+		return err
+	}
+	return s.takeSpillErr()
+}
+
+// IterateTopologicalEvents calls fn with the stored events in topological
+// order, the cached ones being written first
+func (s *HybridStore) IterateTopologicalEvents(fn func(Event) error) error {
+	if err := s.flushEvents(); err != nil {
+		return err
+	}
+	return s.BadgerStore.IterateTopologicalEvents(fn)
+}
+
+// IterateParticipantEvents calls fn with the stored events of participant
+// from index from, the cached ones being written first
+func (s *HybridStore) IterateParticipantEvents(participant string, from int64, fn func(Event) error) error {
+	if err := s.flushEvents(); err != nil {
+		return err
+	}
+	return s.BadgerStore.IterateParticipantEvents(participant, from, fn)
+}
+
+// Flush writes the cached events and commits the writes of the current
+// batch, which stays open
+func (s *HybridStore) Flush() error {
+	if err := s.flushEvents(); err != nil {
+		return err
+	}
+	return s.BadgerStore.Flush()
+}
+
+// EndBatch writes the cached events, commits the writes of the current batch
+// and returns to writing every event on its own
+func (s *HybridStore) EndBatch() error {
+	if err := s.flushEvents(); err != nil {
+		return err
+	}
+	return s.BadgerStore.EndBatch()
+}
+
+// Prune writes the cached events, so that none of the pruned ones is spilled
+// back later, and prunes the database like a BadgerStore
+func (s *HybridStore) Prune(anchor Block, depth int64) (int, error) {
+	if err := s.flushEvents(); err != nil {
+		return 0, err
+	}
+	return s.BadgerStore.Prune(anchor, depth)
+}
+
+// Reset writes the cached events and rounds, which the caches recreated by
+// the reset no longer hold
+func (s *HybridStore) Reset(roots map[string]Root) error {
+	if err := s.flushEvents(); err != nil {
+		return err
+	}
+	if err := s.flushRounds(); err != nil {
+		return err
+	}
+	return s.BadgerStore.Reset(roots)
+}
+
+// Close writes the cached events and rounds and closes the database
+func (s *HybridStore) Close() error {
+	if err := s.flushEvents(); err != nil {
+		return err
+	}
+	if err := s.flushRounds(); err != nil {
+		return err
+	}
+	return s.BadgerStore.Close()
+}
@@ -0,0 +1,131 @@
+package poset
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestHybridStoreSpill checks that the events and rounds evicted from the
+// caches of a HybridStore are read from badger, and that the ones still
+// cached are written on Close
+func TestHybridStoreSpill(t *testing.T) {
+	const cacheSize = 10
+	const testSize = int64(10)
+
+	store, pubs := initBadgerStore(cacheSize, t)
+	dir := store.path
+	defer os.RemoveAll(dir)
+	hybrid, err := newHybridStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hybrid.BeginBatch()
+	var events []Event
+	topo := int64(0)
+	for k := int64(0); k < testSize; k++ {
+		for _, p := range pubs {
+			event := NewEvent([][]byte{[]byte(fmt.Sprintf("%s_%d", p.hex[:5], k))},
+				nil, nil, []string{"", ""}, p.pubKey, k, nil)
+			event.Message.TopologicalIndex = topo
+			topo++
+			if err := hybrid.SetEvent(event); err != nil {
+				t.Fatal(err)
+			}
+			events = append(events, event)
+		}
+	}
+	for r := int64(0); r < 3*cacheSize; r++ {
+		round := NewRoundInfo()
+		round.AddEvent(events[r].Hex(), true)
+		if err := hybrid.SetRound(r, *round); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the first events were evicted by the last ones, in the batch
+	if _, ok := hybrid.inmemStore.eventCache.Peek(events[0].Hex()); ok {
+		t.Fatal("the first event should have been evicted")
+	}
+	if _, err := hybrid.dbGetEvent(events[len(events)-1].Hex()); err == nil {
+		t.Fatal("the last event should not be written before the end of the batch")
+	}
+	for i, ev := range events {
+		rev, err := hybrid.GetEvent(ev.Hex())
+		if err != nil {
+			t.Fatalf("event %d: %s", i, err)
+		}
+		if !ev.Message.Body.Equals(rev.Message.Body) {
+			t.Fatalf("event %d should be %#v, not %#v", i, ev, rev)
+		}
+	}
+	if err := hybrid.EndBatch(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hybrid.dbGetEvent(events[len(events)-1].Hex()); err != nil {
+		t.Fatalf("the last event should be written at the end of the batch: %s", err)
+	}
+
+	n := 0
+	if err := hybrid.IterateTopologicalEvents(func(Event) error { n++; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	// the root events of the participants come first, sharing the
+	// topological index -1 and so a single key
+	if n != len(events)+1 {
+		t.Fatalf("there should be %d events in topological order, not %d", len(events)+1, n)
+	}
+	if _, err := hybrid.dbGetRound(0); err != nil {
+		t.Fatalf("the first round should be spilled when evicted: %s", err)
+	}
+	if _, err := hybrid.dbGetRound(3*cacheSize - 1); err == nil {
+		t.Fatal("the last round should not be written before Close")
+	}
+
+	if err := hybrid.Close(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadHybridStore(cacheSize, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loaded.Close()
+	for r := int64(0); r < 3*cacheSize; r++ {
+		round, err := loaded.GetRound(r)
+		if err != nil {
+			t.Fatalf("round %d: %s", r, err)
+		}
+		if w := round.Witnesses(); len(w) != 1 || w[0] != events[r].Hex() {
+			t.Fatalf("round %d should have the witness %s, not %v", r, events[r].Hex(), w)
+		}
+	}
+	for i, ev := range events {
+		if _, err := loaded.GetEvent(ev.Hex()); err != nil {
+			t.Fatalf("event %d: %s", i, err)
+		}
+	}
+}
+
+// TestHybridStoreWriteThrough checks that the events are written
+// immediately outside batches
+func TestHybridStoreWriteThrough(t *testing.T) {
+	store, pubs := initBadgerStore(10, t)
+	defer os.RemoveAll(store.path)
+	hybrid, err := newHybridStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hybrid.Close()
+
+	event := NewEvent([][]byte{[]byte("tx")}, nil, nil, []string{"", ""}, pubs[0].pubKey, 0, nil)
+	if err := hybrid.SetEvent(event); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hybrid.dbGetEvent(event.Hex()); err != nil {
+		t.Fatalf("the event should be written outside batches: %s", err)
+	}
+	if hash, err := hybrid.dbParticipantEvent(pubs[0].hex, 0); err != nil || hash != event.Hex() {
+		t.Fatalf("the event should be indexed, got %s %v", hash, err)
+	}
+}
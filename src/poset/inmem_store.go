@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"sync"
+	"sync/atomic"
 
 	cm "github.com/Fantom-foundation/go-lachesis/src/common"
 	"github.com/Fantom-foundation/go-lachesis/src/peers"
@@ -17,14 +19,27 @@ type InmemStore struct {
 	roundCache             *lru.Cache
 	blockCache             *lru.Cache
 	frameCache             *lru.Cache
+	checkpointCache        *lru.Cache
+	checkpointErr          error //error creating checkpointCache
 	consensusCache         *cm.RollingIndex
 	totConsensusEvents     int64
 	participantEventsCache *ParticipantEventsCache
 	rootsByParticipant     map[string]Root //[participant] => Root
 	rootsBySelfParent      map[string]Root //[Root.SelfParent.Hash] => Root
+	rootsLock              sync.Mutex      //guards the lazy building of rootsBySelfParent
 	lastRound              int64
 	lastConsensusEvents    map[string]string //[participant] => hex() of last consensus event
 	lastBlock              int64
+	lastCheckpoint         int64
+	ackedBlock             int64
+	txWindow               [][]byte
+	networkParams          *NetworkParams
+	// txIndex indexes the committed transactions, nil until EnableTxIndex
+	txIndex *txIndex
+	// the callbacks of the event and round caches with the items they
+	// evict, see setEvictHooks
+	onEvictEvent func(key, value interface{})
+	onEvictRound func(key, value interface{})
 }
 
 func NewInmemStore(participants *peers.Peers, cacheSize int) *InmemStore {
@@ -55,6 +70,9 @@ func NewInmemStore(participants *peers.Peers, cacheSize int) *InmemStore {
 		fmt.Println("Unable to init InmemStore.frameCache:", err)
 		os.Exit(34)
 	}
+	// an error creating the checkpoint cache is returned by the checkpoint
+	// methods, the store works without checkpoints
+	checkpointCache, checkpointErr := lru.New(cacheSize)
 
 	store := &InmemStore{
 		cacheSize:              cacheSize,
@@ -63,15 +81,19 @@ func NewInmemStore(participants *peers.Peers, cacheSize int) *InmemStore {
 		roundCache:             roundCache,
 		blockCache:             blockCache,
 		frameCache:             frameCache,
+		checkpointCache:        checkpointCache,
+		checkpointErr:          checkpointErr,
 		consensusCache:         cm.NewRollingIndex("ConsensusCache", cacheSize),
 		participantEventsCache: NewParticipantEventsCache(cacheSize, participants),
 		rootsByParticipant:     rootsByParticipant,
 		lastRound:              -1,
 		lastBlock:              -1,
+		lastCheckpoint:         -1,
+		ackedBlock:             -1,
 		lastConsensusEvents:    map[string]string{},
 	}
 
-	participants.OnNewPeer(func(peer *peers.Peer) {
+	participants.OnPeerAdded(peers.PriorityStore, func(peer *peers.Peer) error {
 		root := NewBaseRoot(peer.ID)
 		store.rootsByParticipant[peer.PubKeyHex] = root
 		store.rootsBySelfParent = nil
@@ -79,6 +101,7 @@ func NewInmemStore(participants *peers.Peers, cacheSize int) *InmemStore {
  		old := store.participantEventsCache
 		store.participantEventsCache = NewParticipantEventsCache(cacheSize, participants)
 		store.participantEventsCache.Import(old)
+		return nil
 	})
  	return store
 }
@@ -87,11 +110,23 @@ func (s *InmemStore) CacheSize() int {
 	return s.cacheSize
 }
 
+// Caches returns the LRU caches of the store by name
+func (s *InmemStore) Caches() map[string]*lru.Cache {
+	return map[string]*lru.Cache{
+		"store.events": s.eventCache,
+		"store.rounds": s.roundCache,
+		"store.blocks": s.blockCache,
+		"store.frames": s.frameCache,
+	}
+}
+
 func (s *InmemStore) Participants() (*peers.Peers, error) {
 	return s.participants, nil
 }
 
 func (s *InmemStore) RootsBySelfParent() (map[string]Root, error) {
+	s.rootsLock.Lock()
+	defer s.rootsLock.Unlock()
 	if s.rootsBySelfParent == nil {
 		s.rootsBySelfParent = make(map[string]Root)
 		for _, root := range s.rootsByParticipant {
@@ -187,7 +222,8 @@ func (s *InmemStore) LastConsensusEventFrom(participant string) (last string, is
 
 func (s *InmemStore) KnownEvents() map[int64]int64 {
 	known := s.participantEventsCache.Known()
-	for p, pid := range s.participants.ByPubKey {
+	for _, pid := range s.participants.ToPeerSlice() {
+		p := pid.PubKeyHex
 		if known[pid.ID] == -1 {
 			root, ok := s.rootsByParticipant[p]
 			if ok {
@@ -208,13 +244,16 @@ func (s *InmemStore) ConsensusEvents() []string {
 }
 
 func (s *InmemStore) ConsensusEventsCount() int64 {
-	return s.totConsensusEvents
+	return atomic.LoadInt64(&s.totConsensusEvents)
 }
 
 func (s *InmemStore) AddConsensusEvent(event Event) error {
-	s.consensusCache.Set(event.Hex(), s.totConsensusEvents)
-	s.totConsensusEvents++
+	s.consensusCache.Set(event.Hex(), atomic.LoadInt64(&s.totConsensusEvents))
+	atomic.AddInt64(&s.totConsensusEvents, 1)
 	s.lastConsensusEvents[event.Creator()] = event.Hex()
+	if s.txIndex != nil {
+		s.txIndex.addEvent(event)
+	}
 	return nil
 }
 
@@ -228,14 +267,14 @@ func (s *InmemStore) GetRound(r int64) (RoundInfo, error) {
 
 func (s *InmemStore) SetRound(r int64, round RoundInfo) error {
 	s.roundCache.Add(r, round)
-	if r > s.lastRound {
-		s.lastRound = r
+	if r > atomic.LoadInt64(&s.lastRound) {
+		atomic.StoreInt64(&s.lastRound, r)
 	}
 	return nil
 }
 
 func (s *InmemStore) LastRound() int64 {
-	return s.lastRound
+	return atomic.LoadInt64(&s.lastRound)
 }
 
 func (s *InmemStore) RoundWitnesses(r int64) []string {
@@ -277,14 +316,17 @@ func (s *InmemStore) SetBlock(block Block) error {
 		return err
 	}
 	s.blockCache.Add(index, block)
-	if index > s.lastBlock {
-		s.lastBlock = index
+	if index > atomic.LoadInt64(&s.lastBlock) {
+		atomic.StoreInt64(&s.lastBlock, index)
+	}
+	if s.txIndex != nil {
+		s.txIndex.addBlock(block)
 	}
 	return nil
 }
 
 func (s *InmemStore) LastBlockIndex() int64 {
-	return s.lastBlock
+	return atomic.LoadInt64(&s.lastBlock)
 }
 
 func (s *InmemStore) GetFrame(index int64) (Frame, error) {
@@ -305,13 +347,99 @@ func (s *InmemStore) SetFrame(frame Frame) error {
 	return nil
 }
 
+func (s *InmemStore) GetCheckpoint(index int64) (Checkpoint, error) {
+	if s.checkpointErr != nil {
+		return Checkpoint{}, s.checkpointErr
+	}
+	res, ok := s.checkpointCache.Get(index)
+	if !ok {
+		return Checkpoint{}, cm.NewStoreErr("CheckpointCache", cm.KeyNotFound, strconv.FormatInt(index, 10))
+	}
+	return res.(Checkpoint), nil
+}
+
+func (s *InmemStore) SetCheckpoint(checkpoint Checkpoint) error {
+	if s.checkpointErr != nil {
+		return s.checkpointErr
+	}
+	s.checkpointCache.Add(checkpoint.Index, checkpoint)
+	if checkpoint.Index > s.lastCheckpoint {
+		s.lastCheckpoint = checkpoint.Index
+	}
+	return nil
+}
+
+func (s *InmemStore) LastCheckpointIndex() int64 {
+	return s.lastCheckpoint
+}
+
+// AckedBlock returns the index of the last block acknowledged by the
+// application, -1 when none was
+func (s *InmemStore) AckedBlock() (int64, error) {
+	return s.ackedBlock, nil
+}
+
+// SetAckedBlock records the index of the last block acknowledged by the
+// application
+func (s *InmemStore) SetAckedBlock(index int64) error {
+	s.ackedBlock = index
+	return nil
+}
+
+// TxWindow returns the hashes of the transactions recently seen by the
+// node, oldest first, which it drops when they are submitted again
+func (s *InmemStore) TxWindow() ([][]byte, error) {
+	return s.txWindow, nil
+}
+
+// SetTxWindow records the hashes of the transactions recently seen by the
+// node
+func (s *InmemStore) SetTxWindow(hashes [][]byte) error {
+	s.txWindow = hashes
+	return nil
+}
+
+// NetworkParams returns the governance state of the node, nil before the
+// first one is recorded
+func (s *InmemStore) NetworkParams() (*NetworkParams, error) {
+	if s.networkParams == nil {
+		return nil, nil
+	}
+	return s.networkParams.Copy(), nil
+}
+
+// SetNetworkParams records the governance state of the node
+func (s *InmemStore) SetNetworkParams(params *NetworkParams) error {
+	s.networkParams = params.Copy()
+	return nil
+}
+
+// setEvictHooks makes the event and round caches call onEvent and onRound
+// with the items they evict, also after Reset. The caches are recreated
+// empty, so it is called before the store is written.
+func (s *InmemStore) setEvictHooks(onEvent, onRound func(key, value interface{})) error {
+	eventCache, err := lru.NewWithEvict(s.cacheSize, onEvent)
+	if err != nil {
+		return err
+	}
+	roundCache, err := lru.NewWithEvict(s.cacheSize, onRound)
+	if err != nil {
+		return err
+	}
+	s.onEvictEvent = onEvent
+	s.onEvictRound = onRound
+	s.eventCache = eventCache
+	s.roundCache = roundCache
+	return nil
+}
+
 func (s *InmemStore) Reset(roots map[string]Root) error {
-	eventCache, errr :=  lru.New(s.cacheSize)
+	eventCache, errr :=  lru.NewWithEvict(s.cacheSize, s.onEvictEvent)
 	if errr != nil {
 		fmt.Println("Unable to reset InmemStore.eventCache:", errr)
 		os.Exit(41)
 	}
-	roundCache, errr :=  lru.New(s.cacheSize)
+	roundCache, errr :=  lru.NewWithEvict(s.cacheSize, s.onEvictRound)
 	if errr != nil {
 		fmt.Println("Unable to reset InmemStore.roundCache:", errr)
 		os.Exit(42)
@@ -324,8 +452,8 @@ func (s *InmemStore) Reset(roots map[string]Root) error {
 	s.roundCache = roundCache
 	s.consensusCache = cm.NewRollingIndex("ConsensusCache", s.cacheSize)
 	err := s.participantEventsCache.Reset()
-	s.lastRound = -1
-	s.lastBlock = -1
+	atomic.StoreInt64(&s.lastRound, -1)
+	atomic.StoreInt64(&s.lastBlock, -1)
 
 	if _, err := s.RootsBySelfParent(); err != nil {
 		return err
@@ -0,0 +1,281 @@
+package poset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/badger"
+
+	cm "github.com/Fantom-foundation/go-lachesis/src/common"
+)
+
+// The kinds of the keys of a badger store, see KeyKind
+const (
+	KeyKindEvent            = "event"
+	KeyKindTopo             = "topo"
+	KeyKindParticipant      = "participant"
+	KeyKindParticipantEvent = "participant_event"
+	KeyKindRoot             = "root"
+	KeyKindRound            = "round"
+	KeyKindBlock            = "block"
+	KeyKindFrame            = "frame"
+	KeyKindCheckpoint       = "checkpoint"
+	KeyKindTxIndex          = "txindex"
+	KeyKindMeta             = "meta"
+	KeyKindOther            = "other"
+)
+
+// metaKeys are the single keys of the state of the node
+var metaKeys = map[string]bool{
+	ackedBlockKey:    true,
+	txWindowKey:      true,
+	networkParamsKey: true,
+	pruneKey:         true,
+	schemaVersionKey: true,
+}
+
+// KeyKind returns the kind of a key of a badger store, after the key layout
+// of the BadgerStore
+func KeyKind(key []byte) string {
+	k := string(key)
+	switch {
+	case metaKeys[k]:
+		return KeyKindMeta
+	case strings.HasPrefix(k, participantPrefix+"_"):
+		return KeyKindParticipant
+	case strings.Contains(k, "__event_"):
+		return KeyKindParticipantEvent
+	case strings.HasSuffix(k, "_"+rootSuffix):
+		return KeyKindRoot
+	case strings.HasPrefix(k, topoPrefix+"_"):
+		return KeyKindTopo
+	case strings.HasPrefix(k, roundPrefix+"_"):
+		return KeyKindRound
+	case strings.HasPrefix(k, blockPrefix+"_"):
+		return KeyKindBlock
+	case strings.HasPrefix(k, framePrefix+"_"):
+		return KeyKindFrame
+	case strings.HasPrefix(k, checkpointPrefix+"_"):
+		return KeyKindCheckpoint
+	case strings.HasPrefix(k, txIndexPrefix+"_"):
+		return KeyKindTxIndex
+	case strings.HasPrefix(k, "0x"):
+		return KeyKindEvent
+	}
+	return KeyKindOther
+}
+
+// KeyStats counts the keys of a kind and their size, keys and values
+type KeyStats struct {
+	Kind  string
+	Keys  int64
+	Bytes int64
+}
+
+// DBInspector reads the keys of a badger store opened read-only, so that
+// the store of a stopped node can be examined without changing it
+type DBInspector struct {
+	db *badger.DB
+}
+
+// OpenDBInspector opens the badger store at path read-only. The store is
+// not migrated: its values are read as this release writes them.
+func OpenDBInspector(path string) (*DBInspector, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	opts := badger.DefaultOptions
+	opts.Dir = path
+	opts.ValueDir = path
+	opts.ReadOnly = true
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &DBInspector{db: db}, nil
+}
+
+// Close closes the store
+func (i *DBInspector) Close() error {
+	return i.db.Close()
+}
+
+// Stats counts the keys of the store by kind, sorted by kind
+func (i *DBInspector) Stats() ([]KeyStats, error) {
+	counts := make(map[string]*KeyStats)
+	err := i.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			kind := KeyKind(item.Key())
+			stats, ok := counts[kind]
+			if !ok {
+				stats = &KeyStats{Kind: kind}
+				counts[kind] = stats
+			}
+			stats.Keys++
+			stats.Bytes += item.EstimatedSize()
+		}
+		return nil
+	})
+	res := make([]KeyStats, 0, len(counts))
+	for _, stats := range counts {
+		res = append(res, *stats)
+	}
+	sort.Slice(res, func(a, b int) bool { return res[a].Kind < res[b].Kind })
+	return res, err
+}
+
+// Get returns the decoded value of a key: the hash of an event or of an
+// indexed transaction, the public key of the participant of a root, or the
+// index of a round, block, frame or checkpoint. Other keys are looked up as
+// they are and returned raw.
+func (i *DBInspector) Get(kind, key string) (interface{}, error) {
+	var dbKey []byte
+	switch kind {
+	case KeyKindEvent:
+		dbKey = []byte(key)
+	case KeyKindRoot:
+		dbKey = participantRootKey(key)
+	case KeyKindTxIndex:
+		dbKey = txIndexKey(key)
+	case KeyKindRound, KeyKindBlock, KeyKindFrame, KeyKindCheckpoint:
+		index, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("the key of a %s is its index, got %q", kind, key)
+		}
+		dbKey = indexKey(kind, index)
+	default:
+		dbKey = []byte(key)
+	}
+
+	var res interface{}
+	err := i.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(dbKey)
+		if err != nil {
+			return err
+		}
+		data, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		res, err = decodeValue(kind, data)
+		return err
+	})
+	if err != nil && isDBKeyNotFound(err) {
+		return nil, cm.NewStoreErr(kind, cm.KeyNotFound, string(dbKey))
+	}
+	return res, err
+}
+
+// Dump calls fn with the decoded values of kind from index from to index
+// to, both included, in order: the rounds, blocks, frames or checkpoints by
+// index, or the events by topological index
+func (i *DBInspector) Dump(kind string, from, to int64, fn func(interface{}) error) error {
+	valueKind := kind
+	switch kind {
+	case KeyKindEvent:
+		kind = KeyKindTopo
+	case KeyKindRound, KeyKindBlock, KeyKindFrame, KeyKindCheckpoint:
+	default:
+		return fmt.Errorf("only the events, rounds, blocks, frames and checkpoints are dumped, not %q", kind)
+	}
+	prefix := []byte(kind + "_")
+	limit := indexKey(kind, to+1)
+
+	return i.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Seek(indexKey(kind, from)); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+			if bytes.Compare(item.Key(), limit) >= 0 {
+				return nil
+			}
+			data, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if kind == KeyKindTopo {
+				// the topological index holds the hash of the event
+				eventItem, err := txn.Get(data)
+				if err != nil && isDBKeyNotFound(err) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				if data, err = eventItem.ValueCopy(nil); err != nil {
+					return err
+				}
+			}
+			value, err := decodeValue(valueKind, data)
+			if err != nil {
+				return err
+			}
+			if err := fn(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// indexKey returns the key of the item of kind at index
+func indexKey(kind string, index int64) []byte {
+	return []byte(fmt.Sprintf("%s_%09d", kind, index))
+}
+
+// decodeValue decodes a value of the store of kind, and returns the values
+// of the other kinds as strings
+func decodeValue(kind string, data []byte) (interface{}, error) {
+	op := "DBInspector.Get"
+	switch kind {
+	case KeyKindEvent:
+		return decodeEvent(data, op)
+	case KeyKindRoot:
+		root := new(Root)
+		if err := root.ProtoUnmarshal(data); err != nil {
+			return nil, err
+		}
+		return root, nil
+	case KeyKindRound:
+		round := new(RoundInfo)
+		if err := round.ProtoUnmarshal(data); err != nil {
+			return nil, err
+		}
+		return round, nil
+	case KeyKindBlock:
+		return decodeBlock(data, op)
+	case KeyKindFrame:
+		data, err := decodeRecord(data)
+		if err != nil {
+			return nil, err
+		}
+		frame := new(Frame)
+		if err := frame.ProtoUnmarshal(data); err != nil {
+			return nil, err
+		}
+		return frame, nil
+	case KeyKindCheckpoint:
+		var checkpoint Checkpoint
+		if err := checkpoint.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		return checkpoint, nil
+	case KeyKindTxIndex:
+		var loc TxLocation
+		if err := json.Unmarshal(data, &loc); err != nil {
+			return nil, err
+		}
+		return loc, nil
+	}
+	return string(data), nil
+}
@@ -0,0 +1,93 @@
+package poset
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+func TestDBInspector(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inspect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the keys of the participants are in the upper case hex of the root
+	// events
+	participants := peers.NewPeersFromSlice([]*peers.Peer{
+		peers.NewPeer("0xAA", ""),
+		peers.NewPeer("0xBB", ""),
+		peers.NewPeer("0xCC", ""),
+	})
+	store, err := NewBadgerStore(participants, 100, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(0); i < 5; i++ {
+		block := NewBlock(i, i, nil, [][]byte{[]byte(fmt.Sprintf("tx%d", i))})
+		if err := store.SetBlock(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := OpenDBInspector(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	stats, err := db.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int64)
+	for _, s := range stats {
+		counts[s.Kind] = s.Keys
+	}
+	expected := map[string]int64{
+		KeyKindBlock:       5,
+		KeyKindParticipant: 3,
+		KeyKindRoot:        3,
+		KeyKindMeta:        1,
+	}
+	for kind, n := range expected {
+		if counts[kind] != n {
+			t.Errorf("expected %d %s keys, got %d", n, kind, counts[kind])
+		}
+	}
+
+	value, err := db.Get(KeyKindBlock, "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block, ok := value.(Block); !ok || block.Index() != 3 {
+		t.Fatalf("expected block 3, got %#v", value)
+	}
+	if _, err := db.Get(KeyKindBlock, "7"); !lerrors.Is(err, lerrors.KeyNotFound) {
+		t.Fatalf("a missing block should not be found, got %v", err)
+	}
+
+	var dumped []int64
+	err = db.Dump(KeyKindBlock, 1, 3, func(value interface{}) error {
+		block, ok := value.(Block)
+		if !ok {
+			return fmt.Errorf("expected a block, got %#v", value)
+		}
+		dumped = append(dumped, block.Index())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(dumped) != "[1 2 3]" {
+		t.Fatalf("expected the blocks 1 to 3, got %v", dumped)
+	}
+}
@@ -0,0 +1,309 @@
+package poset
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/dgraph-io/badger"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	cm "github.com/Fantom-foundation/go-lachesis/src/common"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+)
+
+// The iterators call fn with the items of a range in order, stopping at the
+// first error of fn, which they return. The items the store no longer holds,
+// evicted from the caches of an InmemStore or pruned, are skipped. fn may use
+// the store.
+
+// iteratePage is the number of items a BadgerStore reads, and prefetches,
+// in a transaction before it passes them to the function of an iterator
+const iteratePage = 100
+
+// IterateBlocks calls fn with the blocks from index from to index to
+func (s *InmemStore) IterateBlocks(from, to int64, fn func(Block) error) error {
+	for index := from; index <= to; index++ {
+		block, err := s.GetBlock(index)
+		if cm.Is(err, cm.KeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IterateTopologicalEvents calls fn with the cached events in topological
+// order
+func (s *InmemStore) IterateTopologicalEvents(fn func(Event) error) error {
+	var events []Event
+	for _, key := range s.eventCache.Keys() {
+		if event, ok := s.eventCache.Peek(key); ok {
+			events = append(events, event.(Event))
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Message.TopologicalIndex < events[j].Message.TopologicalIndex
+	})
+	for _, event := range events {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IterateParticipantEvents calls fn with the events of participant from
+// index from
+func (s *InmemStore) IterateParticipantEvents(participant string, from int64, fn func(Event) error) error {
+	hashes, err := s.ParticipantEvents(participant, from-1)
+	if err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		event, err := s.GetEvent(hash)
+		if cm.Is(err, cm.KeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IterateBlocks calls fn with the blocks from index from to index to
+func (s *BadgerStore) IterateBlocks(from, to int64, fn func(Block) error) error {
+	if from > to {
+		return nil
+	}
+	end := blockKey(to)
+	return s.dbIterate([]byte(blockPrefix+"_"), blockKey(from), func(page []dbItem) error {
+		for _, item := range page {
+			if bytes.Compare(item.key, end) > 0 {
+				return errEndOfRange
+			}
+			block, err := decodeBlock(item.val, "BadgerStore.IterateBlocks")
+			if err != nil {
+				return err
+			}
+			if err := fn(block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// IterateTopologicalEvents calls fn with the stored events in topological
+// order, the root events of the participants first
+func (s *BadgerStore) IterateTopologicalEvents(fn func(Event) error) error {
+	prefix := []byte(topoPrefix + "_")
+	return s.dbIterate(prefix, prefix, func(page []dbItem) error {
+		events, err := s.dbPageEvents(page, "BadgerStore.IterateTopologicalEvents")
+		if err != nil {
+			return err
+		}
+		for i, event := range events {
+			if event == nil {
+				continue
+			}
+			//the index of the key, as Bootstrap may have renumbered the event
+			fmt.Sscanf(string(page[i].key[len(prefix):]), "%d", &event.Message.TopologicalIndex)
+			if err := fn(*event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// IterateParticipantEvents calls fn with the stored events of participant
+// from index from
+func (s *BadgerStore) IterateParticipantEvents(participant string, from int64, fn func(Event) error) error {
+	prefix := []byte(participant + "__event_")
+	return s.dbIterate(prefix, participantEventKey(participant, from), func(page []dbItem) error {
+		events, err := s.dbPageEvents(page, "BadgerStore.IterateParticipantEvents")
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if event == nil {
+				continue
+			}
+			if err := fn(*event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// dbItem is a key and its value read by dbIterate
+type dbItem struct {
+	key []byte
+	val []byte
+}
+
+// errEndOfRange stops dbIterate without error
+var errEndOfRange = fmt.Errorf("end of range")
+
+// dbIterate calls fn with the items of the keys with prefix from start, in
+// key order, by pages of iteratePage items. Every page is read in its own
+// transaction, which is closed when fn runs.
+func (s *BadgerStore) dbIterate(prefix, start []byte, fn func(page []dbItem) error) error {
+	for {
+		var page []dbItem
+		err := s.view(func(txn *badger.Txn) error {
+			opts := badger.DefaultIteratorOptions
+			opts.PrefetchSize = iteratePage
+			it := txn.NewIterator(opts)
+			defer it.Close()
+			for it.Seek(start); it.ValidForPrefix(prefix) && len(page) < iteratePage; it.Next() {
+				val, err := it.Item().ValueCopy(nil)
+				if err != nil {
+					return err
+				}
+				page = append(page, dbItem{key: it.Item().KeyCopy(nil), val: val})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := fn(page); err != nil {
+			if err == errEndOfRange {
+				return nil
+			}
+			return err
+		}
+		if len(page) < iteratePage {
+			return nil
+		}
+		// the smallest key after the last one of the page
+		start = append(page[len(page)-1].key, 0)
+	}
+}
+
+// dbPageEvents reads the events whose hashes are the values of page, nil
+// for the ones which were pruned
+func (s *BadgerStore) dbPageEvents(page []dbItem, op string) ([]*Event, error) {
+	events := make([]*Event, len(page))
+	err := s.view(func(txn *badger.Txn) error {
+		for i, item := range page {
+			eventItem, err := txn.Get(item.val)
+			if err != nil {
+				if isDBKeyNotFound(err) {
+					continue
+				}
+				return err
+			}
+			data, err := eventItem.Value()
+			if err != nil {
+				return err
+			}
+			event, err := decodeEvent(data, op)
+			if err != nil {
+				return err
+			}
+			events[i] = &event
+		}
+		return nil
+	})
+	return events, err
+}
+
+// IterateBlocks calls fn with the blocks from index from to index to
+func (s *LevelDBStore) IterateBlocks(from, to int64, fn func(Block) error) error {
+	if from > to {
+		return nil
+	}
+	it := s.db.NewIterator(&util.Range{Start: blockKey(from), Limit: blockKey(to + 1)}, nil)
+	defer it.Release()
+	for it.Next() {
+		block, err := decodeBlock(it.Value(), "LevelDBStore.IterateBlocks")
+		if err != nil {
+			return err
+		}
+		if err := fn(block); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// IterateTopologicalEvents calls fn with the stored events in topological
+// order, the root events of the participants first
+func (s *LevelDBStore) IterateTopologicalEvents(fn func(Event) error) error {
+	prefix := []byte(topoPrefix + "_")
+	it := s.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer it.Release()
+	for it.Next() {
+		data, err := s.db.Get(it.Value(), nil)
+		if err == leveldb.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		event, err := decodeEvent(data, "LevelDBStore.IterateTopologicalEvents")
+		if err != nil {
+			return err
+		}
+		//the index of the key, as Bootstrap may have renumbered the event
+		fmt.Sscanf(string(it.Key()[len(prefix):]), "%d", &event.Message.TopologicalIndex)
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// IterateParticipantEvents calls fn with the stored events of participant
+// from index from
+func (s *LevelDBStore) IterateParticipantEvents(participant string, from int64, fn func(Event) error) error {
+	it := s.db.NewIterator(&util.Range{
+		Start: participantEventKey(participant, from),
+		Limit: util.BytesPrefix([]byte(participant + "__event_")).Limit,
+	}, nil)
+	defer it.Release()
+	for it.Next() {
+		data, err := s.db.Get(it.Value(), nil)
+		if err == leveldb.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		event, err := decodeEvent(data, "LevelDBStore.IterateParticipantEvents")
+		if err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// decodeBlock decodes a block value of the database stores
+func decodeBlock(data []byte, op string) (Block, error) {
+	data, err := decodeRecord(data)
+	if err != nil {
+		return Block{}, lerrors.Wrap(lerrors.StoreCorrupt, op, err)
+	}
+	block := new(Block)
+	if err := block.ProtoUnmarshal(data); err != nil {
+		return Block{}, lerrors.Wrap(lerrors.StoreCorrupt, op, err)
+	}
+	return *block, nil
+}
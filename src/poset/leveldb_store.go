@@ -0,0 +1,664 @@
+package poset
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hashicorp/golang-lru"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/Fantom-foundation/go-lachesis/src/chaos"
+	cm "github.com/Fantom-foundation/go-lachesis/src/common"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+// LevelDBStore is a Store persisted in a goleveldb database, with the key
+// layout of the BadgerStore and an InmemStore as cache. It is lighter than
+// badger for embedded targets.
+type LevelDBStore struct {
+	participants *peers.Peers
+	inmemStore   *InmemStore
+	db           *leveldb.DB
+	path         string
+	needBoostrap bool
+	// compression of the event, block and frame values written from now on
+	compression Compression
+}
+
+// NewLevelDBStore creates a brand new Store with a new database
+func NewLevelDBStore(participants *peers.Peers, cacheSize int, path string) (*LevelDBStore, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	store := &LevelDBStore{
+		participants: participants,
+		inmemStore:   NewInmemStore(participants, cacheSize),
+		db:           db,
+		path:         path,
+	}
+	if err := store.dbSetParticipants(participants); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := store.dbSetRoots(store.inmemStore.rootsByParticipant); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := store.dbSetRootEvents(store.inmemStore.rootsByParticipant); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// LoadLevelDBStore creates a Store from an existing database
+func LoadLevelDBStore(cacheSize int, path string) (*LevelDBStore, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	store := &LevelDBStore{
+		db:           db,
+		path:         path,
+		needBoostrap: true,
+	}
+
+	participants, err := store.dbGetParticipants()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	inmemStore := NewInmemStore(participants, cacheSize)
+	roots := make(map[string]Root)
+	for p := range participants.ByPubKey {
+		root, err := store.dbGetRoot(p)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		roots[p] = root
+	}
+	if err := inmemStore.Reset(roots); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	store.participants = participants
+	store.inmemStore = inmemStore
+	return store, nil
+}
+
+// LoadOrCreateLevelDBStore loads the database at path, or creates it if
+// there is none
+func LoadOrCreateLevelDBStore(participants *peers.Peers, cacheSize int, path string) (*LevelDBStore, error) {
+	if _, err := os.Stat(path); err == nil {
+		return LoadLevelDBStore(cacheSize, path)
+	}
+	return NewLevelDBStore(participants, cacheSize, path)
+}
+
+// SetCompression sets the codec of the event, block and frame values written
+// from now on. Values are read whatever their codec.
+func (s *LevelDBStore) SetCompression(c Compression) {
+	s.compression = c
+}
+
+//==============================================================================
+//Implement the Store interface
+
+func (s *LevelDBStore) CacheSize() int {
+	return s.inmemStore.CacheSize()
+}
+
+// Caches returns the LRU caches of the underlying InmemStore
+func (s *LevelDBStore) Caches() map[string]*lru.Cache {
+	return s.inmemStore.Caches()
+}
+
+func (s *LevelDBStore) Participants() (*peers.Peers, error) {
+	return s.participants, nil
+}
+
+func (s *LevelDBStore) RootsBySelfParent() (map[string]Root, error) {
+	return s.inmemStore.RootsBySelfParent()
+}
+
+func (s *LevelDBStore) GetEvent(key string) (Event, error) {
+	event, err := s.inmemStore.GetEvent(key)
+	if err != nil {
+		metrics.IncrCounter("store.events.cache_miss", 1)
+		start := time.Now()
+		event, err = s.dbGetEvent(key)
+		metrics.MeasureSince("store.events.read", start)
+	}
+	return event, mapLevelDBError(err, "Event", key)
+}
+
+func (s *LevelDBStore) SetEvent(event Event) error {
+	if err := s.inmemStore.SetEvent(event); err != nil {
+		return err
+	}
+	chaos.Point(chaos.PointStoreWrite)
+	defer metrics.MeasureSince("store.events.write", time.Now())
+	return s.dbSetEvents([]Event{event})
+}
+
+func (s *LevelDBStore) ParticipantEvents(participant string, skip int64) ([]string, error) {
+	res, err := s.inmemStore.ParticipantEvents(participant, skip)
+	if err != nil {
+		res, err = s.dbParticipantEvents(participant, skip)
+	}
+	return res, err
+}
+
+func (s *LevelDBStore) ParticipantEvent(participant string, index int64) (string, error) {
+	result, err := s.inmemStore.ParticipantEvent(participant, index)
+	if err != nil {
+		var data []byte
+		data, err = s.db.Get(participantEventKey(participant, index), nil)
+		result = string(data)
+	}
+	return result, mapLevelDBError(err, "ParticipantEvent", string(participantEventKey(participant, index)))
+}
+
+func (s *LevelDBStore) LastEventFrom(participant string) (string, bool, error) {
+	return s.inmemStore.LastEventFrom(participant)
+}
+
+func (s *LevelDBStore) LastConsensusEventFrom(participant string) (string, bool, error) {
+	return s.inmemStore.LastConsensusEventFrom(participant)
+}
+
+func (s *LevelDBStore) KnownEvents() map[int64]int64 {
+	known := make(map[int64]int64)
+	for _, pid := range s.participants.ToPeerSlice() {
+		p := pid.PubKeyHex
+		index := int64(-1)
+		last, isRoot, err := s.LastEventFrom(p)
+		if err == nil {
+			if isRoot {
+				root, err := s.GetRoot(p)
+				if err != nil {
+					last = root.SelfParent.Hash
+					index = root.SelfParent.Index
+				}
+			} else {
+				lastEvent, err := s.GetEvent(last)
+				if err == nil {
+					index = lastEvent.Index()
+				}
+			}
+		}
+		known[pid.ID] = index
+	}
+	return known
+}
+
+func (s *LevelDBStore) ConsensusEvents() []string {
+	return s.inmemStore.ConsensusEvents()
+}
+
+func (s *LevelDBStore) ConsensusEventsCount() int64 {
+	return s.inmemStore.ConsensusEventsCount()
+}
+
+func (s *LevelDBStore) AddConsensusEvent(event Event) error {
+	return s.inmemStore.AddConsensusEvent(event)
+}
+
+func (s *LevelDBStore) GetRound(r int64) (RoundInfo, error) {
+	res, err := s.inmemStore.GetRound(r)
+	if err != nil {
+		res, err = s.dbGetRound(r)
+	}
+	return res, mapLevelDBError(err, "Round", string(roundKey(r)))
+}
+
+func (s *LevelDBStore) SetRound(r int64, round RoundInfo) error {
+	if err := s.inmemStore.SetRound(r, round); err != nil {
+		return err
+	}
+	val, err := round.ProtoMarshal()
+	if err != nil {
+		return err
+	}
+	return s.db.Put(roundKey(r), val, nil)
+}
+
+func (s *LevelDBStore) LastRound() int64 {
+	return s.inmemStore.LastRound()
+}
+
+func (s *LevelDBStore) RoundWitnesses(r int64) []string {
+	round, err := s.GetRound(r)
+	if err != nil {
+		return []string{}
+	}
+	return round.Witnesses()
+}
+
+func (s *LevelDBStore) RoundEvents(r int64) int {
+	round, err := s.GetRound(r)
+	if err != nil {
+		return 0
+	}
+	return len(round.Message.Events)
+}
+
+func (s *LevelDBStore) GetRoot(participant string) (Root, error) {
+	root, err := s.inmemStore.GetRoot(participant)
+	if err != nil {
+		root, err = s.dbGetRoot(participant)
+	}
+	return root, mapLevelDBError(err, "Root", string(participantRootKey(participant)))
+}
+
+func (s *LevelDBStore) GetBlock(rr int64) (Block, error) {
+	res, err := s.inmemStore.GetBlock(rr)
+	if err != nil {
+		start := time.Now()
+		res, err = s.dbGetBlock(rr)
+		metrics.MeasureSince("store.blocks.read", start)
+	}
+	return res, mapLevelDBError(err, "Block", string(blockKey(rr)))
+}
+
+func (s *LevelDBStore) SetBlock(block Block) error {
+	if err := s.inmemStore.SetBlock(block); err != nil {
+		return err
+	}
+	defer metrics.MeasureSince("store.blocks.write", time.Now())
+	val, err := block.ProtoMarshal()
+	if err != nil {
+		return err
+	}
+	if err := s.db.Put(blockKey(block.Index()), encodeRecord(s.compression, val), nil); err != nil {
+		return err
+	}
+	return s.dbSetTxLocations(s.inmemStore.blockTxLocations(block))
+}
+
+func (s *LevelDBStore) LastBlockIndex() int64 {
+	return s.inmemStore.LastBlockIndex()
+}
+
+func (s *LevelDBStore) GetFrame(rr int64) (Frame, error) {
+	res, err := s.inmemStore.GetFrame(rr)
+	if err != nil {
+		start := time.Now()
+		res, err = s.dbGetFrame(rr)
+		metrics.MeasureSince("store.frames.read", start)
+	}
+	return res, mapLevelDBError(err, "Frame", string(frameKey(rr)))
+}
+
+func (s *LevelDBStore) SetFrame(frame Frame) error {
+	if err := s.inmemStore.SetFrame(frame); err != nil {
+		return err
+	}
+	defer metrics.MeasureSince("store.frames.write", time.Now())
+	val, err := frame.ProtoMarshal()
+	if err != nil {
+		return err
+	}
+	return s.db.Put(frameKey(frame.Round), encodeRecord(s.compression, val), nil)
+}
+
+func (s *LevelDBStore) GetCheckpoint(index int64) (Checkpoint, error) {
+	res, err := s.inmemStore.GetCheckpoint(index)
+	if err != nil {
+		res, err = s.dbGetCheckpoint(index)
+	}
+	return res, mapLevelDBError(err, "Checkpoint", string(checkpointKey(index)))
+}
+
+func (s *LevelDBStore) SetCheckpoint(checkpoint Checkpoint) error {
+	if err := s.inmemStore.SetCheckpoint(checkpoint); err != nil {
+		return err
+	}
+	val, err := checkpoint.Marshal()
+	if err != nil {
+		return err
+	}
+	return s.db.Put(checkpointKey(checkpoint.Index), val, nil)
+}
+
+func (s *LevelDBStore) LastCheckpointIndex() int64 {
+	return s.inmemStore.LastCheckpointIndex()
+}
+
+// AckedBlock returns the index of the last block acknowledged by the
+// application, which survives restarts, -1 when none was. It shares the key
+// of badger.
+func (s *LevelDBStore) AckedBlock() (int64, error) {
+	data, err := s.db.Get([]byte(ackedBlockKey), nil)
+	if err == leveldb.ErrNotFound {
+		return -1, nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
+
+// SetAckedBlock records the index of the last block acknowledged by the
+// application
+func (s *LevelDBStore) SetAckedBlock(index int64) error {
+	if err := s.inmemStore.SetAckedBlock(index); err != nil {
+		return err
+	}
+	return s.db.Put([]byte(ackedBlockKey), []byte(strconv.FormatInt(index, 10)), nil)
+}
+
+// TxWindow returns the hashes of the transactions recently seen by the
+// node, oldest first, which survive restarts. It shares the key of badger.
+func (s *LevelDBStore) TxWindow() ([][]byte, error) {
+	data, err := s.db.Get([]byte(txWindowKey), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var hashes [][]byte
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// SetTxWindow records the hashes of the transactions recently seen by the
+// node
+func (s *LevelDBStore) SetTxWindow(hashes [][]byte) error {
+	if err := s.inmemStore.SetTxWindow(hashes); err != nil {
+		return err
+	}
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		return err
+	}
+	return s.db.Put([]byte(txWindowKey), data, nil)
+}
+
+// NetworkParams returns the governance state of the node, which survives
+// restarts. It shares the key of badger.
+func (s *LevelDBStore) NetworkParams() (*NetworkParams, error) {
+	data, err := s.db.Get([]byte(networkParamsKey), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	params := new(NetworkParams)
+	if err := json.Unmarshal(data, params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// SetNetworkParams records the governance state of the node
+func (s *LevelDBStore) SetNetworkParams(params *NetworkParams) error {
+	if err := s.inmemStore.SetNetworkParams(params); err != nil {
+		return err
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.db.Put([]byte(networkParamsKey), data, nil)
+}
+
+func (s *LevelDBStore) Reset(roots map[string]Root) error {
+	return s.inmemStore.Reset(roots)
+}
+
+func (s *LevelDBStore) Close() error {
+	if err := s.inmemStore.Close(); err != nil {
+		return err
+	}
+	return s.db.Close()
+}
+
+func (s *LevelDBStore) NeedBoostrap() bool {
+	return s.needBoostrap
+}
+
+func (s *LevelDBStore) StorePath() string {
+	return s.path
+}
+
+// PruneInfo returns the last pruning of the store, false if it was never
+// pruned. LevelDB stores are not pruned, but share the key of badger.
+func (s *LevelDBStore) PruneInfo() (PruneInfo, bool, error) {
+	var info PruneInfo
+	data, err := s.db.Get([]byte(pruneKey), nil)
+	if err == leveldb.ErrNotFound {
+		return info, false, nil
+	}
+	if err != nil {
+		return info, false, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, false, fmt.Errorf("reading prune info: %s", err)
+	}
+	return info, true, nil
+}
+
+//++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+//DB Methods
+
+func (s *LevelDBStore) dbGetEvent(key string) (Event, error) {
+	data, err := s.db.Get([]byte(key), nil)
+	if err != nil {
+		return Event{}, err
+	}
+	return decodeEvent(data, "LevelDBStore.GetEvent")
+}
+
+func (s *LevelDBStore) dbSetEvents(events []Event) error {
+	batch := new(leveldb.Batch)
+	for _, event := range events {
+		eventHex := event.Hex()
+		val, err := event.ProtoMarshal()
+		if err != nil {
+			return err
+		}
+		exists, err := s.db.Has([]byte(eventHex), nil)
+		if err != nil {
+			return err
+		}
+		//insert [event hash] => [event bytes]
+		batch.Put([]byte(eventHex), encodeRecord(s.compression, val))
+		if !exists {
+			//insert [topo_index] => [event hash]
+			batch.Put(topologicalEventKey(event.Message.TopologicalIndex), []byte(eventHex))
+			//insert [participant_index] => [event hash]
+			batch.Put(participantEventKey(event.Creator(), event.Index()), []byte(eventHex))
+		}
+	}
+	return s.db.Write(batch, nil)
+}
+
+// dbTopologicalEvents returns the stored events in topological order
+func (s *LevelDBStore) dbTopologicalEvents() ([]Event, error) {
+	var res []Event
+	err := s.IterateTopologicalEvents(func(event Event) error {
+		res = append(res, event)
+		return nil
+	})
+	return res, err
+}
+
+func (s *LevelDBStore) dbParticipantEvents(participant string, skip int64) ([]string, error) {
+	var res []string
+	for i := skip + 1; ; i++ {
+		data, err := s.db.Get(participantEventKey(participant, i), nil)
+		if err == leveldb.ErrNotFound {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		res = append(res, string(data))
+	}
+}
+
+func (s *LevelDBStore) dbSetRoots(roots map[string]Root) error {
+	batch := new(leveldb.Batch)
+	for participant, root := range roots {
+		val, err := root.ProtoMarshal()
+		if err != nil {
+			return err
+		}
+		//insert [participant_root] => [root bytes]
+		batch.Put(participantRootKey(participant), val)
+	}
+	return s.db.Write(batch, nil)
+}
+
+func (s *LevelDBStore) dbSetRootEvents(roots map[string]Root) error {
+	for participant, root := range roots {
+		var creator []byte
+		fmt.Sscanf(participant, "0x%X", &creator)
+		ft, _ := proto.Marshal(&FlagTableWrapper{Body: map[string]int64{root.SelfParent.Hash: 1}})
+		event := Event{
+			Message: EventMessage{
+				Hex:       root.SelfParent.Hash,
+				CreatorID: root.SelfParent.CreatorID,
+				Body: &EventBody{
+					Creator: creator,
+					Index:   root.SelfParent.Index,
+					Parents: []string{"", ""},
+				},
+				TopologicalIndex: -1,
+				FlagTable:        ft,
+				WitnessProof:     []string{root.SelfParent.Hash},
+			},
+		}
+		if err := s.SetEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LevelDBStore) dbGetRoot(participant string) (Root, error) {
+	data, err := s.db.Get(participantRootKey(participant), nil)
+	if err != nil {
+		return Root{}, err
+	}
+	root := new(Root)
+	if err := root.ProtoUnmarshal(data); err != nil {
+		return Root{}, lerrors.Wrap(lerrors.StoreCorrupt, "LevelDBStore.GetRoot", err)
+	}
+	return *root, nil
+}
+
+func (s *LevelDBStore) dbGetRound(index int64) (RoundInfo, error) {
+	data, err := s.db.Get(roundKey(index), nil)
+	if err != nil {
+		return *NewRoundInfo(), err
+	}
+	roundInfo := new(RoundInfo)
+	if err := roundInfo.ProtoUnmarshal(data); err != nil {
+		return *NewRoundInfo(), lerrors.Wrap(lerrors.StoreCorrupt, "LevelDBStore.GetRound", err)
+	}
+	return *roundInfo, nil
+}
+
+func (s *LevelDBStore) dbGetParticipants() (*peers.Peers, error) {
+	res := peers.NewPeers()
+	it := s.db.NewIterator(util.BytesPrefix([]byte(participantPrefix)), nil)
+	defer it.Release()
+	for it.Next() {
+		pubKey := string(it.Key()[len(participantPrefix)+1:])
+		res.AddPeer(peers.NewPeer(pubKey, ""))
+	}
+	return res, it.Error()
+}
+
+func (s *LevelDBStore) dbSetParticipants(participants *peers.Peers) error {
+	batch := new(leveldb.Batch)
+	for _, id := range participants.ToPeerSlice() {
+		participant := id.PubKeyHex
+		//insert [participant_participant] => [id]
+		batch.Put(participantKey(participant), []byte(strconv.FormatInt(id.ID, 10)))
+	}
+	return s.db.Write(batch, nil)
+}
+
+func (s *LevelDBStore) dbGetBlock(index int64) (Block, error) {
+	data, err := s.db.Get(blockKey(index), nil)
+	if err != nil {
+		return Block{}, err
+	}
+	data, err = decodeRecord(data)
+	if err != nil {
+		return Block{}, lerrors.Wrap(lerrors.StoreCorrupt, "LevelDBStore.GetBlock", err)
+	}
+	block := new(Block)
+	if err := block.ProtoUnmarshal(data); err != nil {
+		return Block{}, lerrors.Wrap(lerrors.StoreCorrupt, "LevelDBStore.GetBlock", err)
+	}
+	return *block, nil
+}
+
+func (s *LevelDBStore) dbGetFrame(index int64) (Frame, error) {
+	data, err := s.db.Get(frameKey(index), nil)
+	if err != nil {
+		return Frame{}, err
+	}
+	data, err = decodeRecord(data)
+	if err != nil {
+		return Frame{}, lerrors.Wrap(lerrors.StoreCorrupt, "LevelDBStore.GetFrame", err)
+	}
+	frame := new(Frame)
+	if err := frame.ProtoUnmarshal(data); err != nil {
+		return Frame{}, lerrors.Wrap(lerrors.StoreCorrupt, "LevelDBStore.GetFrame", err)
+	}
+	return *frame, nil
+}
+
+func (s *LevelDBStore) dbGetCheckpoint(index int64) (Checkpoint, error) {
+	data, err := s.db.Get(checkpointKey(index), nil)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	var checkpoint Checkpoint
+	if err := checkpoint.Unmarshal(data); err != nil {
+		return Checkpoint{}, lerrors.Wrap(lerrors.StoreCorrupt, "LevelDBStore.GetCheckpoint", err)
+	}
+	return checkpoint, nil
+}
+
+// decodeEvent decodes a stored event value
+func decodeEvent(data []byte, op string) (Event, error) {
+	data, err := decodeRecord(data)
+	if err != nil {
+		return Event{}, lerrors.Wrap(lerrors.StoreCorrupt, op, err)
+	}
+	event := new(Event)
+	if err := event.ProtoUnmarshal(data); err != nil {
+		return Event{}, lerrors.Wrap(lerrors.StoreCorrupt, op, err)
+	}
+	return *event, nil
+}
+
+func mapLevelDBError(err error, name, key string) error {
+	if err == leveldb.ErrNotFound {
+		return cm.NewStoreErr(name, cm.KeyNotFound, key)
+	}
+	return err
+}
@@ -0,0 +1,189 @@
+package poset
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/dgraph-io/badger"
+)
+
+// The key layout and the encodings of a badger store are versioned by the
+// schemaVersionKey. A store of an older version is upgraded at load, after
+// a backup, by the registered migrations, one version at a time; a store of
+// a newer version is refused rather than misread.
+
+// SchemaVersion is the version of the badger stores written by this code
+const SchemaVersion = 1
+
+// schemaVersionKey holds the schema version of a badger store, the stores
+// created before it being of version 0
+const schemaVersionKey = "schema_version"
+
+// Migration upgrades a badger store from schema version From to From+1
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(db *badger.DB) error
+}
+
+// migrations are the registered migrations by the version they upgrade from
+var migrations = map[int]Migration{}
+
+func registerMigration(m Migration) {
+	if _, ok := migrations[m.From]; ok {
+		panic(fmt.Sprintf("migration from schema version %d registered twice", m.From))
+	}
+	migrations[m.From] = m
+}
+
+func init() {
+	registerMigration(Migration{
+		From:        0,
+		Description: "record the schema version of a store created before it",
+		Apply:       func(db *badger.DB) error { return nil },
+	})
+}
+
+// PendingMigrations returns the migrations upgrading a store of version
+// from to SchemaVersion, in order
+func PendingMigrations(from int) ([]Migration, error) {
+	if from > SchemaVersion {
+		return nil, fmt.Errorf("schema version %d is newer than the supported version %d", from, SchemaVersion)
+	}
+	var res []Migration
+	for v := from; v < SchemaVersion; v++ {
+		m, ok := migrations[v]
+		if !ok {
+			return nil, fmt.Errorf("no migration from schema version %d", v)
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+// MigrationError is a failure to bring a store to SchemaVersion, which must
+// not be mistaken for a missing store
+type MigrationError struct {
+	From, To int
+	Err      error
+}
+
+func (e *MigrationError) Error() string {
+	return fmt.Sprintf("migrating store from schema version %d to %d: %s", e.From, e.To, e.Err)
+}
+
+// MigrationReport describes the upgrade of a store
+type MigrationReport struct {
+	// From is the schema version the store was at, and Applied the
+	// migrations run, or to run in a dry run
+	From    int
+	Applied []Migration
+	// Backup is the file the store was backed up to before the migrations,
+	// empty when none ran
+	Backup string
+}
+
+// MigrateBadgerStore brings the badger store at path to SchemaVersion. With
+// dryRun, it only reports the pending migrations.
+func MigrateBadgerStore(path string, dryRun bool) (MigrationReport, error) {
+	if _, err := os.Stat(path); err != nil {
+		return MigrationReport{}, err
+	}
+	opts := badger.DefaultOptions
+	opts.Dir = path
+	opts.ValueDir = path
+	opts.SyncWrites = false
+	db, err := badger.Open(opts)
+	if err != nil {
+		return MigrationReport{}, err
+	}
+	defer db.Close()
+	return migrateDB(db, path, dryRun)
+}
+
+// migrateDB backs the database at path up next to it, then applies the
+// pending migrations, recording the new version after each one so that an
+// interrupted upgrade resumes where it stopped
+func migrateDB(db *badger.DB, path string, dryRun bool) (MigrationReport, error) {
+	from, err := dbSchemaVersion(db)
+	if err != nil {
+		return MigrationReport{}, err
+	}
+	report := MigrationReport{From: from}
+	pending, err := PendingMigrations(from)
+	if err != nil {
+		return report, &MigrationError{From: from, To: SchemaVersion, Err: err}
+	}
+	if dryRun || len(pending) == 0 {
+		report.Applied = pending
+		return report, nil
+	}
+
+	// the backup of an interrupted upgrade is of the same version, and kept
+	report.Backup = fmt.Sprintf("%s.schema%d.bak", path, from)
+	if _, err := os.Stat(report.Backup); os.IsNotExist(err) {
+		if err := backupDB(db, report.Backup); err != nil {
+			return report, &MigrationError{From: from, To: SchemaVersion, Err: fmt.Errorf("backup: %s", err)}
+		}
+	}
+	for _, m := range pending {
+		if err := m.Apply(db); err != nil {
+			return report, &MigrationError{From: m.From, To: m.From + 1, Err: err}
+		}
+		if err := dbSetSchemaVersion(db, m.From+1); err != nil {
+			return report, &MigrationError{From: m.From, To: m.From + 1, Err: err}
+		}
+		report.Applied = append(report.Applied, m)
+	}
+	return report, nil
+}
+
+// backupDB writes a full backup of db to file, which badger.DB.Load restores
+func backupDB(db *badger.DB, file string) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := db.Backup(f, 0); err != nil {
+		f.Close()
+		os.Remove(file)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// SchemaVersion returns the schema version of the store
+func (s *BadgerStore) SchemaVersion() (int, error) {
+	return dbSchemaVersion(s.db)
+}
+
+func dbSchemaVersion(db *badger.DB) (int, error) {
+	var version int
+	err := db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(schemaVersionKey))
+		if err != nil {
+			return err
+		}
+		data, err := item.Value()
+		if err != nil {
+			return err
+		}
+		version, err = strconv.Atoi(string(data))
+		return err
+	})
+	if err != nil && isDBKeyNotFound(err) {
+		return 0, nil
+	}
+	return version, err
+}
+
+func dbSetSchemaVersion(db *badger.DB, version int) error {
+	return db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(schemaVersionKey), []byte(strconv.Itoa(version)))
+	})
+}
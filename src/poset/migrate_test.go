@@ -0,0 +1,77 @@
+package poset
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dgraph-io/badger"
+)
+
+func TestMigrateBadgerStore(t *testing.T) {
+	store, _ := initBadgerStore(10, t)
+	path := store.path
+	defer os.RemoveAll(path)
+	defer os.Remove(path + ".schema0.bak")
+
+	if v, err := store.SchemaVersion(); err != nil || v != SchemaVersion {
+		t.Fatalf("a new store should be at schema version %d, got %d (%v)", SchemaVersion, v, err)
+	}
+
+	// a store created before the schema version
+	if err := store.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(schemaVersionKey))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	participants := store.participants
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := MigrateBadgerStore(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.From != 0 || len(report.Applied) != SchemaVersion || report.Backup != "" {
+		t.Fatalf("a dry run should report %d pending migrations from 0, got %+v", SchemaVersion, report)
+	}
+
+	loaded, err := LoadBadgerStore(10, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := loaded.SchemaVersion(); err != nil || v != SchemaVersion {
+		t.Fatalf("the loaded store should be migrated to %d, got %d (%v)", SchemaVersion, v, err)
+	}
+	if _, err := os.Stat(path + ".schema0.bak"); err != nil {
+		t.Fatalf("the store should be backed up before the migration: %v", err)
+	}
+
+	// a store of a newer release is refused, not recreated
+	if err := dbSetSchemaVersion(loaded.db, SchemaVersion+1); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrCreateBadgerStore(participants, 10, path); err == nil {
+		t.Fatal("a store of a newer schema version should be refused")
+	} else if _, ok := err.(*MigrationError); !ok {
+		t.Fatalf("expected a MigrationError, got %v", err)
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	pending, err := PendingMigrations(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range pending {
+		if m.From != i {
+			t.Fatalf("migration %d should upgrade from %d, not %d", i, i, m.From)
+		}
+	}
+	if pending, err := PendingMigrations(SchemaVersion); err != nil || len(pending) != 0 {
+		t.Fatalf("no migration should be pending at the current version, got %d (%v)", len(pending), err)
+	}
+}
@@ -0,0 +1,35 @@
+package poset
+
+import (
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+)
+
+// SetNetworkID binds the events and blocks of the poset to a network: the
+// ID is part of the hashed, and so signed, bodies of the self-events and of
+// the blocks, and the events and blocks of another network are refused. It
+// must be set before the first event is created; empty is the network of
+// the nodes without an ID.
+func (p *Poset) SetNetworkID(networkID string) {
+	p.networkID = networkID
+}
+
+// NetworkID returns the network the poset belongs to
+func (p *Poset) NetworkID() string {
+	return p.networkID
+}
+
+// checkNetwork refuses an event signed for another network
+func (p *Poset) checkNetwork(event Event) error {
+	if id := event.Message.Body.NetworkID; id != p.networkID {
+		return lerrors.New(lerrors.ProtocolMismatch, "event %s belongs to network %q, expected %q", event.Hex(), id, p.networkID)
+	}
+	return nil
+}
+
+// checkBlockNetwork refuses a block of another network
+func (p *Poset) checkBlockNetwork(block Block) error {
+	if id := block.Body.NetworkID; id != p.networkID {
+		return lerrors.New(lerrors.ProtocolMismatch, "block %d belongs to network %q, expected %q", block.Index(), id, p.networkID)
+	}
+	return nil
+}
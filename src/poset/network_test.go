@@ -0,0 +1,98 @@
+package poset
+
+import (
+	"testing"
+
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+// signedRootEvents returns the first event of every node, signed for the
+// network networkID
+func signedRootEvents(nodes []TestNode, participants *peers.Peers, networkID string) []Event {
+	events := make([]Event, len(nodes))
+	for i, peer := range participants.ToPeerSlice() {
+		root := rootSelfParent(peer.ID)
+		event := NewEvent(nil, nil, nil, []string{root, ""}, nodes[i].Pub, 0, map[string]int64{root: 1})
+		event.Message.Body.NetworkID = networkID
+		if err := event.Sign(nodes[i].Key); err != nil {
+			panic(err)
+		}
+		events[i] = event
+	}
+	return events
+}
+
+func TestInsertEventNetworkMismatch(t *testing.T) {
+	nodes, _, _, participants := initPosetNodes(2)
+
+	p := NewPoset(participants, NewInmemStore(participants, cacheSize), nil, testLogger(t))
+	p.SetNetworkID("net-b")
+	for _, ev := range signedRootEvents(nodes, participants, "net-a") {
+		if err := p.InsertEvent(ev, true); !lerrors.Is(err, lerrors.ProtocolMismatch) {
+			t.Fatalf("expected a ProtocolMismatch, got %v", err)
+		}
+	}
+
+	p = NewPoset(participants, NewInmemStore(participants, cacheSize), nil, testLogger(t))
+	p.SetNetworkID("net-a")
+	for i, ev := range signedRootEvents(nodes, participants, "net-a") {
+		if err := p.InsertEvent(ev, true); err != nil {
+			t.Fatalf("event %d: %s", i, err)
+		}
+	}
+}
+
+func TestReadWireInfoNetworkMismatch(t *testing.T) {
+	nodes, _, _, participants := initPosetNodes(2)
+
+	src := NewPoset(participants, NewInmemStore(participants, cacheSize), nil, testLogger(t))
+	src.SetNetworkID("net-a")
+	var wire []WireEvent
+	for _, ev := range signedRootEvents(nodes, participants, "net-a") {
+		if err := src.InsertEvent(ev, true); err != nil {
+			t.Fatal(err)
+		}
+		stored, err := src.Store.GetEvent(ev.Hex())
+		if err != nil {
+			t.Fatal(err)
+		}
+		wire = append(wire, stored.ToWire())
+	}
+
+	for _, network := range []string{"net-a", "net-b", ""} {
+		p := NewPoset(participants, NewInmemStore(participants, cacheSize), nil, testLogger(t))
+		p.SetNetworkID(network)
+		for i, w := range wire {
+			// the hash of an event of another network does not match the
+			// body rebuilt for this one
+			_, err := p.ReadWireInfo(w)
+			if expected := network == "net-a"; (err == nil) != expected {
+				t.Fatalf("network %q, event %d: expected read %v, got %v", network, i, expected, err)
+			}
+
+			// without the hash, the signature check catches it
+			w.Hash = ""
+			ev, err := p.ReadWireInfo(w)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok, _ := ev.Verify()
+			if expected := network == "net-a"; ok != expected {
+				t.Fatalf("network %q, event %d: expected verified %v, got %v", network, i, expected, ok)
+			}
+		}
+	}
+}
+
+func TestCheckBlockNetworkMismatch(t *testing.T) {
+	_, _, _, participants := initPosetNodes(2)
+	p := NewPoset(participants, NewInmemStore(participants, cacheSize), nil, testLogger(t))
+	p.SetNetworkID("net-b")
+
+	block := NewBlock(0, 1, []byte("frame"), [][]byte{[]byte("tx")})
+	block.Body.NetworkID = "net-a"
+	if err := p.CheckBlock(block); !lerrors.Is(err, lerrors.ProtocolMismatch) {
+		t.Fatalf("expected a ProtocolMismatch, got %v", err)
+	}
+}
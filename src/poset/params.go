@@ -0,0 +1,54 @@
+package poset
+
+// networkParamsKey holds the governance state of the node, in JSON
+const networkParamsKey = "network_params"
+
+// NetworkParams is the governance state of a node after a decided round,
+// which survives restarts in the store and is carried by the FastForward
+// responses: the network parameters set so far, the changes scheduled for
+// later rounds and the proposals short of a supermajority
+type NetworkParams struct {
+	// Round is the last decided round whose parameter changes were
+	// processed, -1 before the first one
+	Round int64
+	// Values are the parameters set by the applied changes, by name
+	Values map[string]string `json:",omitempty"`
+	// Pending are the changes scheduled for their activation round, in the
+	// order they are applied
+	Pending []ParamChange `json:",omitempty"`
+	// Proposals are the changes proposed by less than a supermajority
+	Proposals []ParamProposal `json:",omitempty"`
+}
+
+// ParamProposal is a parameter change and the validators which proposed it
+type ParamProposal struct {
+	Change    ParamChange
+	Proposers []string
+}
+
+// NewNetworkParams returns the governance state before the first round
+func NewNetworkParams() *NetworkParams {
+	return &NetworkParams{
+		Round:  -1,
+		Values: make(map[string]string),
+	}
+}
+
+// Copy returns a deep copy of the governance state
+func (p *NetworkParams) Copy() *NetworkParams {
+	cp := &NetworkParams{
+		Round:   p.Round,
+		Values:  make(map[string]string, len(p.Values)),
+		Pending: append([]ParamChange{}, p.Pending...),
+	}
+	for name, value := range p.Values {
+		cp.Values[name] = value
+	}
+	for _, proposal := range p.Proposals {
+		cp.Proposals = append(cp.Proposals, ParamProposal{
+			Change:    proposal.Change,
+			Proposers: append([]string{}, proposal.Proposers...),
+		})
+	}
+	return cp
+}
@@ -3,17 +3,19 @@ package poset
 import (
 	"crypto/ecdsa"
 	"encoding/hex"
-	"errors"
 	"fmt"
 	"math"
 	"math/rand"
 	"sort"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 	"github.com/hashicorp/golang-lru"
 
 	"github.com/Fantom-foundation/go-lachesis/src/common"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
 	"github.com/Fantom-foundation/go-lachesis/src/log"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
 	"github.com/Fantom-foundation/go-lachesis/src/peers"
 )
 
@@ -24,7 +26,8 @@ type Core interface {
 }
 
 //Poset is a DAG of Events. It also contains methods to extract a consensus
-//order of Events and map them onto a blockchain.
+//order of Events and map them onto a blockchain. Its consensus methods are
+//not safe for concurrent use; see state.go for the accessors which are.
 type Poset struct {
 	Participants            *peers.Peers     //[public key] => id
 	Store                   Store            //store of Events, Rounds, and Blocks
@@ -39,10 +42,20 @@ type Poset struct {
 	PendingLoadedEvents     int64            //number of loaded events that are not yet committed
 	commitCh                chan Block       //channel for committing Blocks
 	topologicalIndex        int64            //counter used to order events in topological order (only local)
-	superMajority           int
-	trustCount              int
+	pruneDepth              int64            //rounds kept before the AnchorBlock when pruning, 0 disables it
+	checkpointInterval      int64            //consensus rounds between checkpoint blocks, 0 disables them
+	workers                 int              //goroutines evaluating the strongly-see relations of DecideFame
+	networkID               string           //network the events and blocks are bound to
 	core                    Core
 
+	// stateLock guards the fields read by the accessors of state.go while
+	// the consensus methods update them
+	stateLock sync.RWMutex
+
+	peersLock   sync.RWMutex
+	peerSet     *peers.Snapshot //current view of Participants
+	pinnedPeers *peers.Snapshot //view held while processing rounds
+
 	ancestorCache     *lru.Cache
 	selfAncestorCache *lru.Cache
 	stronglySeeCache  *lru.Cache
@@ -62,9 +75,6 @@ func NewPoset(participants *peers.Peers, store Store, commitCh chan Block, logge
 		logger = logrus.NewEntry(log)
 	}
 
-	superMajority := 2*participants.Len()/3 + 1
-	trustCount := int(math.Ceil(float64(participants.Len()) / float64(3)))
-
 	cacheSize := store.CacheSize()
 	ancestorCache, err := lru.New(cacheSize)
 	if err != nil {
@@ -96,18 +106,109 @@ func NewPoset(participants *peers.Peers, store Store, commitCh chan Block, logge
 		roundCache:        roundCache,
 		timestampCache:    timestampCache,
 		logger:            logger,
-		superMajority:     superMajority,
-		trustCount:        trustCount,
+		peerSet:           participants.Snapshot(),
 	}
 
-	participants.OnNewPeer(func(peer *peers.Peer) {
-		poset.superMajority = 2*participants.Len()/3 + 1
-		poset.trustCount = int(math.Ceil(float64(participants.Len()) / float64(3)))
-	})
+	updatePeerSet := func(peer *peers.Peer) error {
+		snapshot := participants.Snapshot()
+		poset.peersLock.Lock()
+		poset.peerSet = snapshot
+		poset.peersLock.Unlock()
+		return nil
+	}
+	participants.OnPeerAdded(peers.PriorityPoset, updatePeerSet)
+	participants.OnPeerRemoved(peers.PriorityPoset, updatePeerSet)
 
 	return &poset
 }
 
+// peerSnapshot returns the view of the participants consensus computations
+// must use: the pinned one while processing rounds, the current one otherwise
+func (p *Poset) peerSnapshot() *peers.Snapshot {
+	p.peersLock.RLock()
+	defer p.peersLock.RUnlock()
+	if p.pinnedPeers != nil {
+		return p.pinnedPeers
+	}
+	return p.peerSet
+}
+
+// pinPeers freezes the view of the participants until the returned function
+// is called. Nested calls keep the outermost view.
+func (p *Poset) pinPeers() func() {
+	p.peersLock.Lock()
+	defer p.peersLock.Unlock()
+	if p.pinnedPeers != nil {
+		return func() {}
+	}
+	p.pinnedPeers = p.peerSet
+	return func() {
+		p.peersLock.Lock()
+		p.pinnedPeers = nil
+		p.peersLock.Unlock()
+	}
+}
+
+// participantID returns the ID of the participant with the given public key
+func (p *Poset) participantID(pubKey string) (int64, error) {
+	peer, ok := p.Participants.PeerByPubKey(pubKey)
+	if !ok {
+		return -1, lerrors.New(lerrors.UnknownParticipant, "unknown participant %s", pubKey)
+	}
+	return peer.ID, nil
+}
+
+// superMajority returns the weight of a supermajority of the validators,
+// more than 2/3 of them when they have no stakes
+func (p *Poset) superMajority() uint64 {
+	return p.peerSnapshot().SuperMajorityWeight()
+}
+
+// trustCount returns the weight of the validators among which at least one
+// is honest, 1/3 of them when they have no stakes
+func (p *Poset) trustCount() uint64 {
+	return p.peerSnapshot().TrustWeight()
+}
+
+// eventsWeight returns the weight of the creators of the events, their
+// number when the validators have no stakes
+func (p *Poset) eventsWeight(hashes ...string) uint64 {
+	snapshot := p.peerSnapshot()
+	if !snapshot.Weighted() {
+		return uint64(len(hashes))
+	}
+	var weight uint64
+	for _, h := range hashes {
+		ev, err := p.Store.GetEvent(h)
+		if err == nil {
+			weight += snapshot.WeightByID(ev.CreatorID())
+			continue
+		}
+		// the events before a reset are only known as roots
+		roots, err := p.Store.RootsBySelfParent()
+		if err != nil {
+			continue
+		}
+		if root, ok := roots[h]; ok {
+			weight += snapshot.WeightByID(root.SelfParent.CreatorID)
+		}
+	}
+	return weight
+}
+
+// signaturesWeight returns the weight of the validators which signed block,
+// the number of signatures when the validators have no stakes
+func signaturesWeight(snapshot *peers.Snapshot, block Block) uint64 {
+	if !snapshot.Weighted() {
+		return uint64(len(block.Signatures))
+	}
+	var weight uint64
+	for validator := range block.Signatures {
+		weight += snapshot.Weight(validator)
+	}
+	return weight
+}
+
 // SetCore sets a core for poset.
 func (p *Poset) SetCore(core Core) {
 	p.core = core
@@ -165,8 +266,8 @@ func (p *Poset) ancestor2(x, y string) (bool, error) {
 			return false, err2
 		}
 		if root, ok := roots[y]; ok {
-			yCreator := p.Participants.ById[root.SelfParent.CreatorID].PubKeyHex
-			if ex.Creator() == yCreator {
+			yCreator, _ := p.Participants.PeerByID(root.SelfParent.CreatorID)
+			if ex.Creator() == yCreator.PubKeyHex {
 				return ex.Index() >= root.SelfParent.Index, nil
 			}
 		} else {
@@ -232,8 +333,8 @@ func (p *Poset) selfAncestor2(x, y string) (bool, error) {
 			return false, err2
 		}
 		if root, ok := roots[y]; ok {
-			yCreator := p.Participants.ById[root.SelfParent.CreatorID].PubKeyHex
-			if ex.Creator() == yCreator {
+			yCreator, _ := p.Participants.PeerByID(root.SelfParent.CreatorID)
+			if ex.Creator() == yCreator.PubKeyHex {
 				return ex.Index() >= root.SelfParent.Index, nil
 			}
 		}
@@ -280,7 +381,15 @@ func (p *Poset) stronglySee2(x, y string) (bool, error) {
 		return false, err
 	}
 
-	return len(sentinels) >= p.superMajority, nil
+	snapshot := p.peerSnapshot()
+	if !snapshot.Weighted() {
+		return uint64(len(sentinels)) >= snapshot.SuperMajorityWeight(), nil
+	}
+	var weight uint64
+	for pubKey := range sentinels {
+		weight += snapshot.Weight(pubKey)
+	}
+	return weight >= snapshot.SuperMajorityWeight(), nil
 }
 
 // participants in x's ancestry that see y
@@ -303,7 +412,7 @@ func (p *Poset) MapSentinels(x, y string, sentinels map[string]bool) error {
 		}
 
 		if root, ok := roots[x]; ok {
-			creator := p.Participants.ById[root.SelfParent.CreatorID]
+			creator, _ := p.Participants.PeerByID(root.SelfParent.CreatorID)
 
 			sentinels[creator.PubKeyHex] = true
 
@@ -313,7 +422,7 @@ func (p *Poset) MapSentinels(x, y string, sentinels map[string]bool) error {
 		return err
 	}
 
-	creator := p.Participants.ById[ex.CreatorID()]
+	creator, _ := p.Participants.PeerByID(ex.CreatorID())
 	sentinels[creator.PubKeyHex] = true
 
 	if x == y {
@@ -397,7 +506,7 @@ func (p *Poset) round2(x string) (int64, error) {
 		if opRound > parentRound {
 			var (
 				found           bool
-				seeOpRoundRoots int64
+				seeOpRoundRoots uint64
 			)
 
 			// if in a flag table there are witnesses of the current round, then
@@ -416,13 +525,13 @@ func (p *Poset) round2(x string) (int64, error) {
 							if !found {
 								found = true
 							}
-							seeOpRoundRoots++
+							seeOpRoundRoots += p.eventsWeight(w)
 						}
 					}
 				}
 			}
 
-			if seeOpRoundRoots >= int64(p.superMajority) {
+			if seeOpRoundRoots >= p.superMajority() {
 				return opRound + 1, nil
 			}
 
@@ -452,32 +561,36 @@ func (p *Poset) round2(x string) (int64, error) {
 	}
 
 	// check wp
-	if len(ex.Message.WitnessProof) >= p.superMajority {
-		count := 0
+	if p.eventsWeight(ex.Message.WitnessProof...) >= p.superMajority() {
+		var weight uint64
 
 		for _, root := range ex.Message.WitnessProof {
 			if isSee(p, root, ws) {
-				count++
+				weight += p.eventsWeight(root)
 			}
 		}
 
-		if count >= p.superMajority {
+		if weight >= p.superMajority() {
 			return parentRound + 1, err
 		}
 	}
 
 	// check ft
 	ft, _ := ex.GetFlagTable()
-	if len(ft) >= p.superMajority {
-		count := 0
+	ftRoots := make([]string, 0, len(ft))
+	for root := range ft {
+		ftRoots = append(ftRoots, root)
+	}
+	if p.eventsWeight(ftRoots...) >= p.superMajority() {
+		var weight uint64
 
-		for root := range ft {
+		for _, root := range ftRoots {
 			if isSee(p, root, ws) {
-				count++
+				weight += p.eventsWeight(root)
 			}
 		}
 
-		if count >= p.superMajority {
+		if weight >= p.superMajority() {
 			return parentRound + 1, err
 		}
 	}
@@ -672,9 +785,13 @@ func (p *Poset) createSelfParentRootEvent(ev Event) (RootEvent, error) {
 	if err != nil {
 		return RootEvent{}, err
 	}
+	spCreatorID, err := p.participantID(ev.Creator())
+	if err != nil {
+		return RootEvent{}, err
+	}
 	selfParentRootEvent := RootEvent{
 		Hash:             sp,
-		CreatorID:        p.Participants.ByPubKey[ev.Creator()].ID,
+		CreatorID:        spCreatorID,
 		Index:            ev.Index() - 1,
 		LamportTimestamp: spLT,
 		Round:            spRound,
@@ -709,9 +826,13 @@ func (p *Poset) createOtherParentRootEvent(ev Event) (RootEvent, error) {
 	if err != nil {
 		return RootEvent{}, err
 	}
+	opCreatorID, err := p.participantID(otherParent.Creator())
+	if err != nil {
+		return RootEvent{}, err
+	}
 	otherParentRootEvent := RootEvent{
 		Hash:             op,
-		CreatorID:        p.Participants.ByPubKey[otherParent.Creator()].ID,
+		CreatorID:        opCreatorID,
 		Index:            otherParent.Index(),
 		LamportTimestamp: opLT,
 		Round:            opRound,
@@ -767,6 +888,7 @@ func (p *Poset) SetWireInfoAndSign(event *Event, privKey *ecdsa.PrivateKey) erro
 	if err := p.setWireInfo(event); err != nil {
 		return err
 	}
+	event.Message.Body.NetworkID = p.networkID
 	return event.Sign(privKey)
 }
 
@@ -804,15 +926,22 @@ func (p *Poset) setWireInfo(event *Event) error {
 			if err != nil {
 				return err
 			}
-			otherParentCreatorID = p.Participants.ByPubKey[otherParent.Creator()].ID
+			otherParentCreatorID, err = p.participantID(otherParent.Creator())
+			if err != nil {
+				return err
+			}
 			otherParentIndex = otherParent.Index()
 		}
 	}
 
+	creatorID, err := p.participantID(event.Creator())
+	if err != nil {
+		return err
+	}
 	event.SetWireInfo(selfParentIndex,
 		otherParentCreatorID,
 		otherParentIndex,
-		p.Participants.ByPubKey[event.Creator()].ID)
+		creatorID)
 
 	return nil
 }
@@ -833,7 +962,9 @@ func (p *Poset) removeProcessedSignatures(processedSignatures map[int64]bool) {
 			newSigPool = append(newSigPool, bs)
 		}
 	}
+	p.stateLock.Lock()
 	p.SigPool = newSigPool
+	p.stateLock.Unlock()
 }
 
 /*******************************************************************************
@@ -860,6 +991,15 @@ func (p *Poset) InsertEvent(event Event, setWireInfo bool) error {
 		return fmt.Errorf("invalid Event signature")
 	}
 
+	if err := p.checkNetwork(event); err != nil {
+		return err
+	}
+
+	//observers don't take part in consensus
+	if creator, ok := p.peerSnapshot().ByPubKey(event.Creator()); ok && creator.IsEphemeral() {
+		return fmt.Errorf("event created by observer %s", event.Creator())
+	}
+
 	if err := p.checkSelfParent(event); err != nil {
 		return fmt.Errorf("CheckSelfParent: %s", err)
 	}
@@ -878,20 +1018,23 @@ func (p *Poset) InsertEvent(event Event, setWireInfo bool) error {
 	}
 
 	if err := p.Store.SetEvent(event); err != nil {
-		return fmt.Errorf("SetEvent: %s", err)
-	}
-
-	p.UndeterminedEvents = append(p.UndeterminedEvents, event.Hex())
-
-	if event.IsLoaded() {
-		p.PendingLoadedEvents++
+		return fmt.Errorf("SetEvent: %w", err)
 	}
 
 	blockSignatures := make([]BlockSignature, len(event.BlockSignatures()))
 	for i, v := range event.BlockSignatures() {
 		blockSignatures[i] = *v
 	}
+
+	p.stateLock.Lock()
+	p.UndeterminedEvents = append(p.UndeterminedEvents, event.Hex())
+	if event.IsLoaded() {
+		p.PendingLoadedEvents++
+	}
 	p.SigPool = append(p.SigPool, blockSignatures...)
+	p.stateLock.Unlock()
+
+	metrics.IncrCounter("poset.events.inserted", 1)
 
 	return nil
 }
@@ -901,6 +1044,7 @@ DivideRounds assigns a Round and LamportTimestamp to Events, and flags them as
 witnesses if necessary. Pushes Rounds in the PendingRounds queue if necessary.
 */
 func (p *Poset) DivideRounds() error {
+	defer p.pinPeers()()
 
 	for _, hash := range p.UndeterminedEvents {
 
@@ -1019,6 +1163,7 @@ func (p *Poset) DivideRounds() error {
 
 //DecideFame decides if witnesses are famous
 func (p *Poset) DecideFame() error {
+	defer p.pinPeers()()
 
 	//Initialize the vote map
 	votes := make(map[string]map[string]bool) //[x][y]=>vote(x,y)
@@ -1032,6 +1177,10 @@ func (p *Poset) DecideFame() error {
 	decidedRounds := map[int64]int64{} // [round number] => index in p.PendingRounds
 	c := 3
 
+	// rounds whose strongly-see relations with the previous round were
+	// evaluated by the workers
+	warmed := make(map[int64]bool)
+
 	for pos, r := range p.PendingRounds {
 		roundIndex := r.Index
 		roundInfo, err := p.Store.GetRound(roundIndex)
@@ -1044,6 +1193,12 @@ func (p *Poset) DecideFame() error {
 			}
 		VOTE_LOOP:
 			for j := roundIndex + 1; j <= p.Store.LastRound(); j++ {
+				if p.workers > 1 && j-roundIndex > 1 && !warmed[j] {
+					if err := p.warmStronglySee(p.Store.RoundWitnesses(j), p.Store.RoundWitnesses(j-1)); err != nil {
+						return err
+					}
+					warmed[j] = true
+				}
 				for _, y := range p.Store.RoundWitnesses(j) {
 					diff := j - roundIndex
 					if diff == 1 {
@@ -1064,13 +1219,12 @@ func (p *Poset) DecideFame() error {
 								ssWitnesses = append(ssWitnesses, w)
 							}
 						}
-						yays := 0
-						nays := 0
+						var yays, nays uint64
 						for _, w := range ssWitnesses {
 							if votes[w][x] {
-								yays++
+								yays += p.eventsWeight(w)
 							} else {
-								nays++
+								nays += p.eventsWeight(w)
 							}
 						}
 						v := false
@@ -1082,7 +1236,7 @@ func (p *Poset) DecideFame() error {
 
 						//normal round
 						if math.Mod(float64(diff), float64(c)) > 0 {
-							if t >= p.superMajority {
+							if t >= p.superMajority() {
 								roundInfo.SetFame(x, v)
 								setVote(votes, y, x, v)
 								break VOTE_LOOP //break out of j loop
@@ -1090,7 +1244,7 @@ func (p *Poset) DecideFame() error {
 								setVote(votes, y, x, v)
 							}
 						} else { //coin round
-							if t >= p.superMajority {
+							if t >= p.superMajority() {
 								setVote(votes, y, x, v)
 							} else {
 								setVote(votes, y, x, middleBit(y)) //middle bit of y's hash
@@ -1119,6 +1273,7 @@ func (p *Poset) DecideFame() error {
 //DecideRoundReceived assigns a RoundReceived to undetermined events when they
 //reach consensus
 func (p *Poset) DecideRoundReceived() error {
+	defer p.pinPeers()()
 
 	var newUndeterminedEvents []string
 
@@ -1200,7 +1355,9 @@ func (p *Poset) DecideRoundReceived() error {
 		}
 	}
 
+	p.stateLock.Lock()
 	p.UndeterminedEvents = newUndeterminedEvents
+	p.stateLock.Unlock()
 
 	return nil
 }
@@ -1209,11 +1366,14 @@ func (p *Poset) DecideRoundReceived() error {
 //corresponding Frames, maps them into Blocks, and commits the Blocks via the
 //commit channel
 func (p *Poset) ProcessDecidedRounds() error {
+	defer p.pinPeers()()
 
 	//Defer removing processed Rounds from the PendingRounds Queue
 	processedIndex := 0
 	defer func() {
 		p.PendingRounds = p.PendingRounds[processedIndex:]
+		metrics.SetGauge("poset.rounds.pending", float64(len(p.PendingRounds)))
+		metrics.SetGauge("poset.sig_pool", float64(len(p.SigPool)))
 	}()
 
 	for _, r := range p.PendingRounds {
@@ -1258,22 +1418,36 @@ func (p *Poset) ProcessDecidedRounds() error {
 				if err != nil {
 					return err
 				}
+				p.stateLock.Lock()
 				p.ConsensusTransactions += uint64(len(ev.Transactions()))
 				if ev.IsLoaded() {
 					p.PendingLoadedEvents--
 				}
+				p.stateLock.Unlock()
 			}
+			metrics.IncrCounter("poset.events.consensus", int64(len(frame.Events)))
 
 			lastBlockIndex := p.Store.LastBlockIndex()
 			block, err := NewBlockFromFrame(lastBlockIndex+1, frame)
 			if err != nil {
 				return err
 			}
+			block.Body.NetworkID = p.networkID
+			block.Body.Timestamp, err = p.consensusTimestamp(r.Index, round)
+			if err != nil {
+				return err
+			}
 			if len(block.Transactions()) > 0 {
 				if err := p.Store.SetBlock(block); err != nil {
 					return err
 				}
+				metrics.IncrCounter("poset.blocks.created", 1)
 
+				// the block and its events are stored before the
+				// application sees it
+				if err := p.FlushBatch(); err != nil {
+					return err
+				}
 				if p.commitCh != nil {
 					p.commitCh <- block
 				}
@@ -1283,7 +1457,12 @@ func (p *Poset) ProcessDecidedRounds() error {
 			p.logger.Debugf("No Events to commit for ConsensusRound %d", r.Index)
 		}
 
+		if err := p.checkpoint(r.Index, round); err != nil {
+			return fmt.Errorf("checkpoint at round %d: %v", r.Index, err)
+		}
+
 		processedIndex++
+		metrics.IncrCounter("poset.rounds.decided", 1)
 
 		if p.LastConsensusRound == nil || r.Index > *p.LastConsensusRound {
 			p.setLastConsensusRound(r.Index)
@@ -1412,19 +1591,39 @@ func (p *Poset) GetFrame(roundReceived int64) (Frame, error) {
 //a known Block. If a Signature is found to be valid for a known Block, it is
 //appended to the block and removed from the SignaturePool
 func (p *Poset) ProcessSigPool() error {
+	defer p.pinPeers()()
 	processedSignatures := map[int64]bool{} //index in SigPool => Processed?
 	defer p.removeProcessedSignatures(processedSignatures)
+	anchor := int64(-1)
+	if p.AnchorBlock != nil {
+		anchor = *p.AnchorBlock
+	}
+	defer func() {
+		if p.AnchorBlock != nil && *p.AnchorBlock != anchor {
+			p.prune()
+		}
+	}()
 
 	for i, bs := range p.SigPool {
 		//check if validator belongs to list of participants
 		validatorHex := fmt.Sprintf("0x%X", bs.Validator)
-		if _, ok := p.Participants.ByPubKey[validatorHex]; !ok {
+		validator, ok := p.Participants.PeerByPubKey(validatorHex)
+		if !ok {
 			p.logger.WithFields(logrus.Fields{
 				"index":     bs.Index,
 				"validator": validatorHex,
 			}).Warning("Verifying Block signature. Unknown validator")
 			continue
 		}
+		//signatures of observers don't count
+		if !p.peerSnapshot().IsValidator(validatorHex) {
+			p.logger.WithFields(logrus.Fields{
+				"index":     bs.Index,
+				"validator": validatorHex,
+			}).Warning("Verifying Block signature. Signed by observer")
+			processedSignatures[int64(i)] = true
+			continue
+		}
 		//only check if bs is greater than AnchorBlock, otherwise simply remove
 		if p.AnchorBlock == nil ||
 			bs.Index > *p.AnchorBlock {
@@ -1447,7 +1646,7 @@ func (p *Poset) ProcessSigPool() error {
 			if !valid {
 				p.logger.WithFields(logrus.Fields{
 					"index":     bs.Index,
-					"validator": p.Participants.ByPubKey[validatorHex],
+					"validator": validator,
 					"block":     block,
 				}).Warning("Verifying Block signature. Invalid signature")
 				continue
@@ -1462,14 +1661,14 @@ func (p *Poset) ProcessSigPool() error {
 				}).Warning("Saving Block")
 			}
 
-			if len(block.Signatures) > p.trustCount &&
+			if signaturesWeight(p.peerSnapshot(), block) > p.trustCount() &&
 				(p.AnchorBlock == nil ||
 					block.Index() > *p.AnchorBlock) {
 				p.setAnchorBlock(block.Index())
 				p.logger.WithFields(logrus.Fields{
 					"block_index": block.Index(),
 					"signatures":  len(block.Signatures),
-					"trustCount":  p.trustCount,
+					"trustCount":  p.trustCount(),
 				}).Debug("Setting AnchorBlock")
 			}
 		}
@@ -1503,15 +1702,20 @@ func (p *Poset) GetAnchorBlockWithFrame() (Block, Frame, error) {
 
 //Reset clears the Poset and resets it from a new base.
 func (p *Poset) Reset(block Block, frame Frame) error {
+	if err := p.checkBlockNetwork(block); err != nil {
+		return err
+	}
 
 	//Clear all state
+	p.stateLock.Lock()
 	p.LastConsensusRound = nil
 	p.FirstConsensusRound = nil
 	p.AnchorBlock = nil
 
 	p.UndeterminedEvents = []string{}
-	p.PendingRounds = []*pendingRound{}
 	p.PendingLoadedEvents = 0
+	p.stateLock.Unlock()
+	p.PendingRounds = []*pendingRound{}
 	p.topologicalIndex = 0
 
 	cacheSize := p.Store.CacheSize()
@@ -1565,24 +1769,62 @@ func (p *Poset) Reset(block Block, frame Frame) error {
 	return nil
 }
 
+// dbStore is a Store backed by a database, which Bootstrap replays
+type dbStore interface {
+	PruneInfo() (PruneInfo, bool, error)
+	dbGetBlock(int64) (Block, error)
+	dbGetFrame(int64) (Frame, error)
+	dbTopologicalEvents() ([]Event, error)
+}
+
 //Bootstrap loads all Events from the Store's DB (if there is one) and feeds
 //them to the Poset (in topological order) for consensus ordering. After this
 //method call, the Poset should be in a state coherent with the 'tip' of the
 //Poset
 func (p *Poset) Bootstrap() error {
-	if badgerStore, ok := p.Store.(*BadgerStore); ok {
-		//Retreive the Events from the underlying DB. They come out in topological
-		//order
-		topologicalEvents, err := badgerStore.dbTopologicalEvents()
+	if store, ok := p.Store.(dbStore); ok {
+		//A pruned store no longer holds the first Events: start from the
+		//Frame of the Block it was pruned at, like a fast-forward
+		info, pruned, err := store.PruneInfo()
 		if err != nil {
 			return err
 		}
-
-		//Insert the Events in the Poset
-		for _, e := range topologicalEvents {
-			if err := p.InsertEvent(e, true); err != nil {
+		var known map[int64]int64
+		if pruned {
+			block, err := store.dbGetBlock(info.Block)
+			if err != nil {
+				return mapError(err, "Block", string(blockKey(info.Block)))
+			}
+			frame, err := store.dbGetFrame(block.RoundReceived())
+			if err != nil {
+				return mapError(err, "Frame", string(frameKey(block.RoundReceived())))
+			}
+			if err := p.Reset(block, frame); err != nil {
 				return err
 			}
+			known = p.Store.KnownEvents()
+		}
+
+		//Insert the Events of the underlying DB in the Poset. They come out
+		//in topological order
+		last := int64(-1)
+		err = p.Store.IterateTopologicalEvents(func(e Event) error {
+			last = e.Message.TopologicalIndex
+			if pruned {
+				//skip the Events of the Frame and before
+				creator, ok := p.Participants.PeerByPubKey(e.Creator())
+				if ok && e.Index() <= known[creator.ID] {
+					return nil
+				}
+			}
+			return p.InsertEvent(e, true)
+		})
+		if err != nil {
+			return err
+		}
+		if pruned && p.topologicalIndex <= last {
+			//new Events go after the stored ones, whose indexes have gaps
+			p.topologicalIndex = last + 1
 		}
 
 		//Compute the consensus order of Events
@@ -1609,14 +1851,27 @@ func (p *Poset) Bootstrap() error {
 //ReadWireInfo converts a WireEvent to an Event by replacing int IDs with the
 //corresponding public keys.
 func (p *Poset) ReadWireInfo(wevent WireEvent) (*Event, error) {
+	// an event already inserted, e.g. received from several peers during
+	// a sync storm, is not rebuilt nor hashed again: its hash was verified
+	// at insertion
+	if wevent.Hash != "" {
+		if known, err := p.Store.GetEvent(wevent.Hash); err == nil {
+			metrics.IncrCounter("poset.wire.known", 1)
+			known.Message.LamportTimestamp = LamportTimestampNIL
+			known.Message.Round = RoundNIL
+			known.Message.RoundReceived = RoundNIL
+			return &known, nil
+		}
+	}
+
 	selfParent := rootSelfParent(wevent.Body.CreatorID)
 	otherParent := ""
 	var err error
 
-	creator := p.Participants.ById[wevent.Body.CreatorID]
+	creator, _ := p.Participants.PeerByID(wevent.Body.CreatorID)
 	// FIXIT: creator can be nil when wevent.Body.CreatorID == 0
 	if creator == nil {
-		return nil, fmt.Errorf("unknown wevent.Body.CreatorID=%v", wevent.Body.CreatorID)
+		return nil, lerrors.New(lerrors.UnknownParticipant, "unknown wevent.Body.CreatorID=%v", wevent.Body.CreatorID)
 	}
 	creatorBytes, err := hex.DecodeString(creator.PubKeyHex[2:])
 	if err != nil {
@@ -1630,25 +1885,34 @@ func (p *Poset) ReadWireInfo(wevent WireEvent) (*Event, error) {
 		}
 	}
 	if wevent.Body.OtherParentIndex >= 0 {
-		otherParentCreator := p.Participants.ById[wevent.Body.OtherParentCreatorID]
+		otherParentCreator, _ := p.Participants.PeerByID(wevent.Body.OtherParentCreatorID)
 		if otherParentCreator != nil {
 			otherParent, err = p.Store.ParticipantEvent(otherParentCreator.PubKeyHex, wevent.Body.OtherParentIndex)
 			if err != nil {
-				//PROBLEM Check if other parent can be found in the root
-				//problem, we do not known the WireEvent's EventHash, and
-				//we do not know the creators of the roots RootEvents
+				// the other-parent is below the root of the creator,
+				// which references it in its Others by the hash of the
+				// event
 				root, err := p.Store.GetRoot(creator.PubKeyHex)
 				if err != nil {
 					return nil, err
 				}
-				//loop through others
 				found := false
-				for _, re := range root.Others {
-					if re.CreatorID == wevent.Body.OtherParentCreatorID &&
-						re.Index == wevent.Body.OtherParentIndex {
-						otherParent = re.Hash
-						found = true
-						break
+				if re, ok := root.Others[wevent.Hash]; ok && wevent.Hash != "" &&
+					re.CreatorID == wevent.Body.OtherParentCreatorID &&
+					re.Index == wevent.Body.OtherParentIndex {
+					otherParent = re.Hash
+					found = true
+				}
+				// the nodes which do not send the hash leave us to look
+				// for the other-parent by its creator and index
+				if !found {
+					for _, re := range root.Others {
+						if re.CreatorID == wevent.Body.OtherParentCreatorID &&
+							re.Index == wevent.Body.OtherParentIndex {
+							otherParent = re.Hash
+							found = true
+							break
+						}
 					}
 				}
 
@@ -1659,7 +1923,7 @@ func (p *Poset) ReadWireInfo(wevent WireEvent) (*Event, error) {
 		} else {
 			// unknown participant
 			// TODO: we should handle this nicely
-			return nil, errors.New("unknown participant")
+			return nil, lerrors.New(lerrors.UnknownParticipant, "unknown wevent.Body.OtherParentCreatorID=%v", wevent.Body.OtherParentCreatorID)
 		}
 	}
 
@@ -1685,6 +1949,10 @@ func (p *Poset) ReadWireInfo(wevent WireEvent) (*Event, error) {
 		Creator:              creatorBytes,
 		Index:                wevent.Body.Index,
 		BlockSignatures:      blockSignatures,
+		Timestamp:            wevent.Body.Timestamp,
+		// the network is not sent: an event signed for another one
+		// fails the signature check
+		NetworkID: p.networkID,
 	}
 
 	event := &Event{
@@ -1703,6 +1971,11 @@ func (p *Poset) ReadWireInfo(wevent WireEvent) (*Event, error) {
 		},
 	}
 
+	if wevent.Hash != "" && event.Hex() != wevent.Hash {
+		metrics.IncrCounter("poset.wire.hash_mismatch", 1)
+		return nil, fmt.Errorf("event hash %s does not match its body, hashed %s", wevent.Hash, event.Hex())
+	}
+
 	p.logger.WithFields(logrus.Fields{
 		"event.Signature":  event.Message.Signature,
 		"wevent.Signature": wevent.Signature,
@@ -1712,17 +1985,25 @@ func (p *Poset) ReadWireInfo(wevent WireEvent) (*Event, error) {
 }
 
 //CheckBlock returns an error if the Block does not contain valid signatures
-//from MORE than 1/3 of participants
+//from MORE than 1/3 of validators, by weight
 func (p *Poset) CheckBlock(block Block) error {
-	validSignatures := 0
+	if err := p.checkBlockNetwork(block); err != nil {
+		return err
+	}
+	snapshot := p.peerSnapshot()
+	var validSignatures uint64
 	for _, s := range block.GetBlockSignatures() {
+		validator := fmt.Sprintf("0x%X", s.Validator)
+		if !snapshot.IsValidator(validator) {
+			continue
+		}
 		ok, _ := block.Verify(s)
 		if ok {
-			validSignatures++
+			validSignatures += snapshot.Weight(validator)
 		}
 	}
-	if validSignatures <= p.trustCount {
-		return fmt.Errorf("not enough valid signatures: got %d, need %d", validSignatures, p.trustCount+1)
+	if validSignatures <= snapshot.TrustWeight() {
+		return fmt.Errorf("not enough valid signatures: got weight %d, need %d", validSignatures, snapshot.TrustWeight()+1)
 	}
 
 	p.logger.WithField("valid_signatures", validSignatures).Debug("CheckBlock")
@@ -1734,10 +2015,13 @@ Setters
 *******************************************************************************/
 
 func (p *Poset) setLastConsensusRound(i int64) {
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
 	if p.LastConsensusRound == nil {
 		p.LastConsensusRound = new(int64)
 	}
 	*p.LastConsensusRound = i
+	metrics.SetGauge("poset.rounds.last_consensus", float64(i))
 
 	if p.FirstConsensusRound == nil {
 		p.FirstConsensusRound = new(int64)
@@ -1746,6 +2030,8 @@ func (p *Poset) setLastConsensusRound(i int64) {
 }
 
 func (p *Poset) setAnchorBlock(i int64) {
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
 	if p.AnchorBlock == nil {
 		p.AnchorBlock = new(int64)
 	}
@@ -1755,11 +2041,32 @@ func (p *Poset) setAnchorBlock(i int64) {
 /*
 */
 
+// Caches returns the LRU caches of the poset, and of its store if it has
+// any, by name. The caches are replaced by Reset.
+func (p *Poset) Caches() map[string]*lru.Cache {
+	res := map[string]*lru.Cache{
+		"poset.ancestor":     p.ancestorCache,
+		"poset.selfAncestor": p.selfAncestorCache,
+		"poset.stronglySee":  p.stronglySeeCache,
+		"poset.round":        p.roundCache,
+		"poset.timestamp":    p.timestampCache,
+	}
+	if store, ok := p.Store.(interface {
+		Caches() map[string]*lru.Cache
+	}); ok {
+		for name, cache := range store.Caches() {
+			res[name] = cache
+		}
+	}
+	return res
+}
+
 func (p *Poset) GetFlagTableOfRandomUndeterminedEvent() (result map[string]int64, err error) {
-	// FIXME: possible data race: p.UndeterminedEvents can be modified by other goroutine
-	perm := rand.Perm(len(p.UndeterminedEvents))
+	// called by the peer selector, concurrently with the consensus
+	undetermined := p.GetUndeterminedEvents()
+	perm := rand.Perm(len(undetermined))
 	for i := 0; i < len(perm); i++ {
-		hash := p.UndeterminedEvents[perm[i]]
+		hash := undetermined[perm[i]]
 		ev, err := p.Store.GetEvent(hash)
 		if err != nil {
 			continue
@@ -1768,7 +2075,7 @@ func (p *Poset) GetFlagTableOfRandomUndeterminedEvent() (result map[string]int64
 		if err != nil {
 			continue
 		}
-		if len(ft) >= len(p.Participants.Sorted) {
+		if len(ft) >= p.Participants.Len() {
 			continue
 		}
 		return ft, nil
@@ -697,6 +697,35 @@ func TestReadWireInfo(t *testing.T) {
 	}
 }
 
+func TestReadWireInfoHash(t *testing.T) {
+	p, index, _ := initRoundPoset(t)
+	ev, err := p.Store.GetEvent(index[e21])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a known event is not rebuilt, and comes back as a received one
+	known, err := p.ReadWireInfo(ev.ToWire())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if known.Hex() != ev.Hex() || known.Message.Round != RoundNIL {
+		t.Fatalf("expected event %s without round, got %s round %d", ev.Hex(), known.Hex(), known.Message.Round)
+	}
+
+	// a body which does not match the hash is refused
+	other, err := p.Store.GetEvent(index[e10])
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := other.ToWire()
+	tampered.Hash = "0x00"
+	tampered.Body.Transactions = [][]byte{[]byte("forged")}
+	if _, err := p.ReadWireInfo(tampered); err == nil {
+		t.Fatal("an event whose body does not match its hash should be refused")
+	}
+}
+
 func TestStronglySee(t *testing.T) {
 	p, index, _ := initRoundPoset(t)
 
@@ -1346,8 +1375,9 @@ func TestInsertEventsWithBlockSignatures(t *testing.T) {
 	   [e0][e1][e2]
 		0   1    2
 */
-func initConsensusPoset(db bool, t testing.TB) (*Poset, map[string]string) {
-	plays := []play{
+// consensusPlays builds a DAG whose consensus decides several rounds
+func consensusPlays() []play {
+	return []play{
 		{1, 1, e1, e0, e10, nil, nil, []string{e0, e1}},
 		{2, 1, e2, e10, f2, [][]byte{[]byte(f2)}, nil, []string{e0, e1, e2}},
 		{2, 2, f2, "", f2b, nil, nil, []string{f2}},
@@ -1377,8 +1407,11 @@ func initConsensusPoset(db bool, t testing.TB) (*Poset, map[string]string) {
 		{0, 10, l0, l1, m0, nil, nil, []string{l1, l0, l2}},
 		{2, 9, l2, l1, m2, nil, nil, []string{l1, l0, l2}},
 	}
+}
 
-	poset, index, _, _ := initPosetFull(t, plays, db, n, testLogger(t))
+func initConsensusPoset(db bool, t testing.TB) (*Poset, map[string]string) {
+	poset, index, _, _ := initPosetFull(t, consensusPlays(), db, n,
+		testLogger(t))
 
 	return poset, index
 }
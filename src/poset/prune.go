@@ -0,0 +1,280 @@
+package poset
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// pruneKey holds the PruneInfo of a pruned store
+const pruneKey = "prune"
+
+// PruneInfo records the last pruning of a BadgerStore
+type PruneInfo struct {
+	// Block is the anchor block the store was pruned at. Bootstrap resets
+	// the poset from it and its frame.
+	Block int64
+	// Round is the first round whose events are still stored
+	Round int64
+}
+
+// PruneInfo returns the last pruning of the store, false if it was never
+// pruned
+func (s *BadgerStore) PruneInfo() (PruneInfo, bool, error) {
+	var info PruneInfo
+	var data []byte
+	err := s.view(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(pruneKey))
+		if err != nil {
+			return err
+		}
+		data, err = item.Value()
+		return err
+	})
+	if err != nil {
+		if isDBKeyNotFound(err) {
+			return info, false, nil
+		}
+		return info, false, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, false, fmt.Errorf("reading prune info: %s", err)
+	}
+	return info, true, nil
+}
+
+// SetPruneInfo records the base of a store, the next Bootstrap resetting the
+// poset from the block and frame of info. It also marks a store imported
+// from a snapshot, which holds no event before that block.
+func (s *BadgerStore) SetPruneInfo(info PruneInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return s.update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(pruneKey), data)
+	})
+}
+
+// Prune deletes the events and rounds more than depth rounds older than the
+// round of an anchor block. The blocks and frames are kept: the frame of the
+// anchor is the base Bootstrap resets the poset from, and the ones of the
+// retained history serve fast-forwarding peers. The events not covered by
+// the roots of that frame are kept whatever their round, so that Bootstrap
+// can replay them. It returns the number of deleted events.
+func (s *BadgerStore) Prune(anchor Block, depth int64) (int, error) {
+	if depth < 0 {
+		return 0, fmt.Errorf("prune depth must not be negative, got %d", depth)
+	}
+	// the pruning transactions write beside the batch, which is committed
+	// first and reopened after them
+	if s.Batching() {
+		if err := s.EndBatch(); err != nil {
+			return 0, err
+		}
+		defer s.BeginBatch()
+	}
+	info, _, err := s.PruneInfo()
+	if err != nil {
+		return 0, err
+	}
+	if anchor.Index() < info.Block {
+		return 0, fmt.Errorf("block %d is older than the pruning base %d", anchor.Index(), info.Block)
+	}
+	frame, err := s.dbGetFrame(anchor.RoundReceived())
+	if err != nil {
+		return 0, mapError(err, "Frame", string(frameKey(anchor.RoundReceived())))
+	}
+	keep := s.frameEvents(frame)
+
+	start := time.Now()
+	cutoff := anchor.RoundReceived() - depth
+	pruned := 0
+	for r := info.Round; r < cutoff; r++ {
+		n, err := s.dbPruneRound(r, keep)
+		if err != nil {
+			return pruned, fmt.Errorf("pruning round %d: %s", r, err)
+		}
+		pruned += n
+	}
+	if cutoff < info.Round {
+		cutoff = info.Round
+	}
+
+	if err := s.SetPruneInfo(PruneInfo{Block: anchor.Index(), Round: cutoff}); err != nil {
+		return pruned, err
+	}
+
+	metrics.IncrCounter("store.pruned_events", int64(pruned))
+	metrics.MeasureSince("store.prune", start)
+	return pruned, nil
+}
+
+// frameEvents returns a filter of the events needed to bootstrap from a
+// frame: its events, and the events after its roots
+func (s *BadgerStore) frameEvents(frame Frame) func(*Event) bool {
+	inFrame := make(map[string]bool, len(frame.Events))
+	for _, ev := range frame.Events {
+		e := ev.ToEvent()
+		inFrame[e.Hex()] = true
+	}
+	rootIndex := make(map[string]int64, len(frame.Roots))
+	for id, p := range s.participants.ToPeerSlice() {
+		if id < len(frame.Roots) && frame.Roots[id] != nil && frame.Roots[id].SelfParent != nil {
+			rootIndex[p.PubKeyHex] = frame.Roots[id].SelfParent.Index
+		}
+	}
+	return func(e *Event) bool {
+		index, ok := rootIndex[e.Creator()]
+		return inFrame[e.Hex()] || !ok || e.Index() > index
+	}
+}
+
+// dbPruneRound deletes a round and its events but the ones to keep, in a
+// transaction of its own
+func (s *BadgerStore) dbPruneRound(r int64, keep func(*Event) bool) (int, error) {
+	round, err := s.dbGetRound(r)
+	if err != nil {
+		if isDBKeyNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	pruned := 0
+	err = s.db.Update(func(txn *badger.Txn) error {
+		for hash := range round.Message.Events {
+			event, err := s.dbGetEvent(hash)
+			if err != nil {
+				if isDBKeyNotFound(err) {
+					continue
+				}
+				return err
+			}
+			if keep(&event) {
+				continue
+			}
+			if err := txn.Delete([]byte(hash)); err != nil {
+				return err
+			}
+			if err := txn.Delete(participantEventKey(event.Creator(), event.Index())); err != nil {
+				return err
+			}
+			// the index of an event replayed by Bootstrap may not be the one
+			// of its key: a dangling key is skipped when reading
+			topoKey := topologicalEventKey(event.Message.TopologicalIndex)
+			if item, err := txn.Get(topoKey); err == nil {
+				if v, err := item.Value(); err == nil && string(v) == hash {
+					if err := txn.Delete(topoKey); err != nil {
+						return err
+					}
+				}
+			}
+			pruned++
+		}
+		return txn.Delete(roundKey(r))
+	})
+	return pruned, err
+}
+
+// LastAnchorBlock returns the last stored block signed by enough validators
+// to be trusted, the base of an offline pruning
+func (s *BadgerStore) LastAnchorBlock() (Block, error) {
+	snapshot := s.participants.Snapshot()
+	trustCount := snapshot.TrustWeight()
+	var anchor *Block
+	err := s.view(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		prefix := []byte(blockPrefix + "_")
+
+		// the last key of the prefix, the indexes being zero-padded digits
+		for it.Seek(append(prefix, 0xff)); it.ValidForPrefix(prefix); it.Next() {
+			data, err := it.Item().Value()
+			if err != nil {
+				return err
+			}
+			if data, err = decodeRecord(data); err != nil {
+				return err
+			}
+			block := new(Block)
+			if err := block.ProtoUnmarshal(data); err != nil {
+				return err
+			}
+			if signaturesWeight(snapshot, *block) > trustCount {
+				anchor = block
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Block{}, err
+	}
+	if anchor == nil {
+		return Block{}, fmt.Errorf("no block with more than %d signatures", trustCount)
+	}
+	return *anchor, nil
+}
+
+// SetPruneDepth makes the poset prune the rounds more than depth rounds older
+// than every new anchor block from a badger store, 0 disabling it
+func (p *Poset) SetPruneDepth(depth int64) {
+	p.pruneDepth = depth
+}
+
+// pruneStore is a Store whose database can be pruned, a BadgerStore or a
+// HybridStore
+type pruneStore interface {
+	Prune(anchor Block, depth int64) (int, error)
+}
+
+// prune prunes the store at the anchor block, if enabled
+func (p *Poset) prune() {
+	store, ok := p.Store.(pruneStore)
+	if !ok || p.pruneDepth <= 0 || p.AnchorBlock == nil {
+		return
+	}
+	anchor := *p.AnchorBlock
+	// with checkpoints, only the history committed by a checkpoint is pruned
+	if p.checkpointInterval > 0 {
+		last := p.Store.LastCheckpointIndex()
+		if last < 0 {
+			return
+		}
+		checkpoint, err := p.Store.GetCheckpoint(last)
+		if err != nil {
+			p.logger.WithError(err).Error("Reading last checkpoint to prune")
+			return
+		}
+		if checkpoint.Block < anchor {
+			anchor = checkpoint.Block
+		}
+	}
+	block, err := p.Store.GetBlock(anchor)
+	if err != nil {
+		p.logger.WithError(err).Error("Reading anchor block to prune")
+		return
+	}
+	pruned, err := store.Prune(block, p.pruneDepth)
+	if err != nil {
+		p.logger.WithFields(logrus.Fields{
+			"block": block.Index(),
+			"error": err,
+		}).Error("Pruning store")
+		return
+	}
+	if pruned > 0 {
+		p.logger.WithFields(logrus.Fields{
+			"block":  block.Index(),
+			"events": pruned,
+		}).Debug("Pruned store")
+	}
+}
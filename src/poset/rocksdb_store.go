@@ -0,0 +1,851 @@
+//go:build rocksdb
+// +build rocksdb
+
+package poset
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hashicorp/golang-lru"
+	"github.com/tecbot/gorocksdb"
+
+	"github.com/Fantom-foundation/go-lachesis/src/chaos"
+	cm "github.com/Fantom-foundation/go-lachesis/src/common"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+// The column families of the RocksDBStore. The events family also holds the
+// topological and participant indexes of the events; the default one holds
+// the participants, their roots, the checkpoints and the node metadata.
+const (
+	rocksDefaultFamily = "default"
+	rocksEventsFamily  = "events"
+	rocksRoundsFamily  = "rounds"
+	rocksBlocksFamily  = "blocks"
+	rocksFramesFamily  = "frames"
+)
+
+var rocksFamilies = []string{
+	rocksDefaultFamily,
+	rocksEventsFamily,
+	rocksRoundsFamily,
+	rocksBlocksFamily,
+	rocksFramesFamily,
+}
+
+// rocksMemtableBudget is the memory of the memtables of the level style
+// compaction of each column family
+const rocksMemtableBudget = 64 << 20
+
+// errRocksDBNotFound is returned by the reads of missing keys, as RocksDB
+// returns an empty value instead
+var errRocksDBNotFound = errors.New("rocksdb: not found")
+
+// RocksDBStore is a Store persisted in a RocksDB database, built with the
+// rocksdb tag, with the key layout of the BadgerStore split in column
+// families and an InmemStore as cache. It is meant for write-heavy
+// deployments, RocksDB compacting in the background on all the cores.
+type RocksDBStore struct {
+	participants *peers.Peers
+	inmemStore   *InmemStore
+	db           *gorocksdb.DB
+	opts         *gorocksdb.Options
+	ro           *gorocksdb.ReadOptions
+	wo           *gorocksdb.WriteOptions
+	// families are the handles of the column families, by name
+	families     map[string]*gorocksdb.ColumnFamilyHandle
+	path         string
+	needBoostrap bool
+	// compression of the event, block and frame values written from now on
+	compression Compression
+}
+
+// openRocksDB opens or creates the database at path with its column
+// families
+func openRocksDB(path string) (*RocksDBStore, error) {
+	bbto := gorocksdb.NewDefaultBlockBasedTableOptions()
+	bbto.SetFilterPolicy(gorocksdb.NewBloomFilter(10))
+	opts := gorocksdb.NewDefaultOptions()
+	opts.SetBlockBasedTableFactory(bbto)
+	opts.SetCreateIfMissing(true)
+	opts.SetCreateIfMissingColumnFamilies(true)
+	opts.IncreaseParallelism(runtime.NumCPU())
+	opts.OptimizeLevelStyleCompaction(rocksMemtableBudget)
+
+	familyOpts := make([]*gorocksdb.Options, len(rocksFamilies))
+	for i := range familyOpts {
+		familyOpts[i] = opts
+	}
+	db, handles, err := gorocksdb.OpenDbColumnFamilies(opts, path, rocksFamilies, familyOpts)
+	if err != nil {
+		opts.Destroy()
+		return nil, err
+	}
+	families := make(map[string]*gorocksdb.ColumnFamilyHandle)
+	for i, name := range rocksFamilies {
+		families[name] = handles[i]
+	}
+	return &RocksDBStore{
+		db:       db,
+		opts:     opts,
+		ro:       gorocksdb.NewDefaultReadOptions(),
+		wo:       gorocksdb.NewDefaultWriteOptions(),
+		families: families,
+		path:     path,
+	}, nil
+}
+
+// NewRocksDBStore creates a brand new Store with a new database
+func NewRocksDBStore(participants *peers.Peers, cacheSize int, path string) (*RocksDBStore, error) {
+	store, err := openRocksDB(path)
+	if err != nil {
+		return nil, err
+	}
+	store.participants = participants
+	store.inmemStore = NewInmemStore(participants, cacheSize)
+	if err := store.dbSetParticipants(participants); err != nil {
+		store.closeDB()
+		return nil, err
+	}
+	if err := store.dbSetRoots(store.inmemStore.rootsByParticipant); err != nil {
+		store.closeDB()
+		return nil, err
+	}
+	if err := store.dbSetRootEvents(store.inmemStore.rootsByParticipant); err != nil {
+		store.closeDB()
+		return nil, err
+	}
+	return store, nil
+}
+
+// LoadRocksDBStore creates a Store from an existing database
+func LoadRocksDBStore(cacheSize int, path string) (*RocksDBStore, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	store, err := openRocksDB(path)
+	if err != nil {
+		return nil, err
+	}
+	store.needBoostrap = true
+
+	participants, err := store.dbGetParticipants()
+	if err != nil {
+		store.closeDB()
+		return nil, err
+	}
+	inmemStore := NewInmemStore(participants, cacheSize)
+	roots := make(map[string]Root)
+	for p := range participants.ByPubKey {
+		root, err := store.dbGetRoot(p)
+		if err != nil {
+			store.closeDB()
+			return nil, err
+		}
+		roots[p] = root
+	}
+	if err := inmemStore.Reset(roots); err != nil {
+		store.closeDB()
+		return nil, err
+	}
+
+	store.participants = participants
+	store.inmemStore = inmemStore
+	return store, nil
+}
+
+// LoadOrCreateRocksDBStore loads the database at path, or creates it if
+// there is none
+func LoadOrCreateRocksDBStore(participants *peers.Peers, cacheSize int, path string) (*RocksDBStore, error) {
+	if _, err := os.Stat(path); err == nil {
+		return LoadRocksDBStore(cacheSize, path)
+	}
+	return NewRocksDBStore(participants, cacheSize, path)
+}
+
+// SetCompression sets the codec of the event, block and frame values written
+// from now on. Values are read whatever their codec.
+func (s *RocksDBStore) SetCompression(c Compression) {
+	s.compression = c
+}
+
+//==============================================================================
+//Implement the Store interface
+
+func (s *RocksDBStore) CacheSize() int {
+	return s.inmemStore.CacheSize()
+}
+
+// Caches returns the LRU caches of the underlying InmemStore
+func (s *RocksDBStore) Caches() map[string]*lru.Cache {
+	return s.inmemStore.Caches()
+}
+
+func (s *RocksDBStore) Participants() (*peers.Peers, error) {
+	return s.participants, nil
+}
+
+func (s *RocksDBStore) RootsBySelfParent() (map[string]Root, error) {
+	return s.inmemStore.RootsBySelfParent()
+}
+
+func (s *RocksDBStore) GetEvent(key string) (Event, error) {
+	event, err := s.inmemStore.GetEvent(key)
+	if err != nil {
+		metrics.IncrCounter("store.events.cache_miss", 1)
+		start := time.Now()
+		event, err = s.dbGetEvent(key)
+		metrics.MeasureSince("store.events.read", start)
+	}
+	return event, mapRocksDBError(err, "Event", key)
+}
+
+func (s *RocksDBStore) SetEvent(event Event) error {
+	if err := s.inmemStore.SetEvent(event); err != nil {
+		return err
+	}
+	chaos.Point(chaos.PointStoreWrite)
+	defer metrics.MeasureSince("store.events.write", time.Now())
+	return s.dbSetEvents([]Event{event})
+}
+
+func (s *RocksDBStore) ParticipantEvents(participant string, skip int64) ([]string, error) {
+	res, err := s.inmemStore.ParticipantEvents(participant, skip)
+	if err != nil {
+		res, err = s.dbParticipantEvents(participant, skip)
+	}
+	return res, err
+}
+
+func (s *RocksDBStore) ParticipantEvent(participant string, index int64) (string, error) {
+	result, err := s.inmemStore.ParticipantEvent(participant, index)
+	if err != nil {
+		var data []byte
+		data, err = s.get(rocksEventsFamily, participantEventKey(participant, index))
+		result = string(data)
+	}
+	return result, mapRocksDBError(err, "ParticipantEvent", string(participantEventKey(participant, index)))
+}
+
+func (s *RocksDBStore) LastEventFrom(participant string) (string, bool, error) {
+	return s.inmemStore.LastEventFrom(participant)
+}
+
+func (s *RocksDBStore) LastConsensusEventFrom(participant string) (string, bool, error) {
+	return s.inmemStore.LastConsensusEventFrom(participant)
+}
+
+func (s *RocksDBStore) KnownEvents() map[int64]int64 {
+	known := make(map[int64]int64)
+	for _, pid := range s.participants.ToPeerSlice() {
+		p := pid.PubKeyHex
+		index := int64(-1)
+		last, isRoot, err := s.LastEventFrom(p)
+		if err == nil {
+			if isRoot {
+				root, err := s.GetRoot(p)
+				if err != nil {
+					last = root.SelfParent.Hash
+					index = root.SelfParent.Index
+				}
+			} else {
+				lastEvent, err := s.GetEvent(last)
+				if err == nil {
+					index = lastEvent.Index()
+				}
+			}
+		}
+		known[pid.ID] = index
+	}
+	return known
+}
+
+func (s *RocksDBStore) ConsensusEvents() []string {
+	return s.inmemStore.ConsensusEvents()
+}
+
+func (s *RocksDBStore) ConsensusEventsCount() int64 {
+	return s.inmemStore.ConsensusEventsCount()
+}
+
+func (s *RocksDBStore) AddConsensusEvent(event Event) error {
+	return s.inmemStore.AddConsensusEvent(event)
+}
+
+func (s *RocksDBStore) GetRound(r int64) (RoundInfo, error) {
+	res, err := s.inmemStore.GetRound(r)
+	if err != nil {
+		res, err = s.dbGetRound(r)
+	}
+	return res, mapRocksDBError(err, "Round", string(roundKey(r)))
+}
+
+func (s *RocksDBStore) SetRound(r int64, round RoundInfo) error {
+	if err := s.inmemStore.SetRound(r, round); err != nil {
+		return err
+	}
+	val, err := round.ProtoMarshal()
+	if err != nil {
+		return err
+	}
+	return s.put(rocksRoundsFamily, roundKey(r), val)
+}
+
+func (s *RocksDBStore) LastRound() int64 {
+	return s.inmemStore.LastRound()
+}
+
+func (s *RocksDBStore) RoundWitnesses(r int64) []string {
+	round, err := s.GetRound(r)
+	if err != nil {
+		return []string{}
+	}
+	return round.Witnesses()
+}
+
+func (s *RocksDBStore) RoundEvents(r int64) int {
+	round, err := s.GetRound(r)
+	if err != nil {
+		return 0
+	}
+	return len(round.Message.Events)
+}
+
+func (s *RocksDBStore) GetRoot(participant string) (Root, error) {
+	root, err := s.inmemStore.GetRoot(participant)
+	if err != nil {
+		root, err = s.dbGetRoot(participant)
+	}
+	return root, mapRocksDBError(err, "Root", string(participantRootKey(participant)))
+}
+
+func (s *RocksDBStore) GetBlock(rr int64) (Block, error) {
+	res, err := s.inmemStore.GetBlock(rr)
+	if err != nil {
+		start := time.Now()
+		res, err = s.dbGetBlock(rr)
+		metrics.MeasureSince("store.blocks.read", start)
+	}
+	return res, mapRocksDBError(err, "Block", string(blockKey(rr)))
+}
+
+func (s *RocksDBStore) SetBlock(block Block) error {
+	if err := s.inmemStore.SetBlock(block); err != nil {
+		return err
+	}
+	defer metrics.MeasureSince("store.blocks.write", time.Now())
+	val, err := block.ProtoMarshal()
+	if err != nil {
+		return err
+	}
+	if err := s.put(rocksBlocksFamily, blockKey(block.Index()), encodeRecord(s.compression, val)); err != nil {
+		return err
+	}
+	for _, loc := range s.inmemStore.blockTxLocations(block) {
+		val, err := json.Marshal(loc)
+		if err != nil {
+			return err
+		}
+		if err := s.put(rocksDefaultFamily, txIndexKey(loc.Hash), val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnableTxIndex makes the store index the committed transactions in its
+// database
+func (s *RocksDBStore) EnableTxIndex() {
+	s.inmemStore.EnableTxIndex()
+}
+
+// GetTxLocation returns the location of a committed transaction, from the
+// cache or the database
+func (s *RocksDBStore) GetTxLocation(hash string) (TxLocation, error) {
+	if loc, err := s.inmemStore.GetTxLocation(hash); err == nil {
+		return loc, nil
+	}
+	data, err := s.get(rocksDefaultFamily, txIndexKey(hash))
+	if err != nil {
+		return TxLocation{}, mapRocksDBError(err, "TxIndex", string(txIndexKey(hash)))
+	}
+	var loc TxLocation
+	err = json.Unmarshal(data, &loc)
+	return loc, err
+}
+
+func (s *RocksDBStore) LastBlockIndex() int64 {
+	return s.inmemStore.LastBlockIndex()
+}
+
+func (s *RocksDBStore) GetFrame(rr int64) (Frame, error) {
+	res, err := s.inmemStore.GetFrame(rr)
+	if err != nil {
+		start := time.Now()
+		res, err = s.dbGetFrame(rr)
+		metrics.MeasureSince("store.frames.read", start)
+	}
+	return res, mapRocksDBError(err, "Frame", string(frameKey(rr)))
+}
+
+func (s *RocksDBStore) SetFrame(frame Frame) error {
+	if err := s.inmemStore.SetFrame(frame); err != nil {
+		return err
+	}
+	defer metrics.MeasureSince("store.frames.write", time.Now())
+	val, err := frame.ProtoMarshal()
+	if err != nil {
+		return err
+	}
+	return s.put(rocksFramesFamily, frameKey(frame.Round), encodeRecord(s.compression, val))
+}
+
+func (s *RocksDBStore) GetCheckpoint(index int64) (Checkpoint, error) {
+	res, err := s.inmemStore.GetCheckpoint(index)
+	if err != nil {
+		res, err = s.dbGetCheckpoint(index)
+	}
+	return res, mapRocksDBError(err, "Checkpoint", string(checkpointKey(index)))
+}
+
+func (s *RocksDBStore) SetCheckpoint(checkpoint Checkpoint) error {
+	if err := s.inmemStore.SetCheckpoint(checkpoint); err != nil {
+		return err
+	}
+	val, err := checkpoint.Marshal()
+	if err != nil {
+		return err
+	}
+	return s.put(rocksDefaultFamily, checkpointKey(checkpoint.Index), val)
+}
+
+func (s *RocksDBStore) LastCheckpointIndex() int64 {
+	return s.inmemStore.LastCheckpointIndex()
+}
+
+// AckedBlock returns the index of the last block acknowledged by the
+// application, which survives restarts, -1 when none was. It shares the key
+// of badger.
+func (s *RocksDBStore) AckedBlock() (int64, error) {
+	data, err := s.get(rocksDefaultFamily, []byte(ackedBlockKey))
+	if err == errRocksDBNotFound {
+		return -1, nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
+
+// SetAckedBlock records the index of the last block acknowledged by the
+// application
+func (s *RocksDBStore) SetAckedBlock(index int64) error {
+	if err := s.inmemStore.SetAckedBlock(index); err != nil {
+		return err
+	}
+	return s.put(rocksDefaultFamily, []byte(ackedBlockKey), []byte(strconv.FormatInt(index, 10)))
+}
+
+// TxWindow returns the hashes of the transactions recently seen by the
+// node, oldest first, which survive restarts. It shares the key of badger.
+func (s *RocksDBStore) TxWindow() ([][]byte, error) {
+	data, err := s.get(rocksDefaultFamily, []byte(txWindowKey))
+	if err == errRocksDBNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var hashes [][]byte
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// SetTxWindow records the hashes of the transactions recently seen by the
+// node
+func (s *RocksDBStore) SetTxWindow(hashes [][]byte) error {
+	if err := s.inmemStore.SetTxWindow(hashes); err != nil {
+		return err
+	}
+	data, err := json.Marshal(hashes)
+	if err != nil {
+		return err
+	}
+	return s.put(rocksDefaultFamily, []byte(txWindowKey), data)
+}
+
+// NetworkParams returns the governance state of the node, which survives
+// restarts. It shares the key of badger.
+func (s *RocksDBStore) NetworkParams() (*NetworkParams, error) {
+	data, err := s.get(rocksDefaultFamily, []byte(networkParamsKey))
+	if err == errRocksDBNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	params := new(NetworkParams)
+	if err := json.Unmarshal(data, params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// SetNetworkParams records the governance state of the node
+func (s *RocksDBStore) SetNetworkParams(params *NetworkParams) error {
+	if err := s.inmemStore.SetNetworkParams(params); err != nil {
+		return err
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.put(rocksDefaultFamily, []byte(networkParamsKey), data)
+}
+
+func (s *RocksDBStore) Reset(roots map[string]Root) error {
+	return s.inmemStore.Reset(roots)
+}
+
+func (s *RocksDBStore) Close() error {
+	if err := s.inmemStore.Close(); err != nil {
+		return err
+	}
+	s.closeDB()
+	return nil
+}
+
+func (s *RocksDBStore) NeedBoostrap() bool {
+	return s.needBoostrap
+}
+
+func (s *RocksDBStore) StorePath() string {
+	return s.path
+}
+
+// PruneInfo returns the last pruning of the store, false if it was never
+// pruned. RocksDB stores are not pruned, but share the key of badger.
+func (s *RocksDBStore) PruneInfo() (PruneInfo, bool, error) {
+	var info PruneInfo
+	data, err := s.get(rocksDefaultFamily, []byte(pruneKey))
+	if err == errRocksDBNotFound {
+		return info, false, nil
+	}
+	if err != nil {
+		return info, false, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, false, fmt.Errorf("reading prune info: %s", err)
+	}
+	return info, true, nil
+}
+
+// IterateBlocks calls fn with the blocks from index from to index to
+func (s *RocksDBStore) IterateBlocks(from, to int64, fn func(Block) error) error {
+	if from > to {
+		return nil
+	}
+	limit := blockKey(to + 1)
+	return s.iterate(rocksBlocksFamily, []byte(blockPrefix+"_"), blockKey(from), func(key, val []byte) (bool, error) {
+		if bytes.Compare(key, limit) >= 0 {
+			return false, nil
+		}
+		block, err := decodeBlock(val, "RocksDBStore.IterateBlocks")
+		if err != nil {
+			return false, err
+		}
+		return true, fn(block)
+	})
+}
+
+// IterateTopologicalEvents calls fn with the stored events in topological
+// order, the root events of the participants first
+func (s *RocksDBStore) IterateTopologicalEvents(fn func(Event) error) error {
+	prefix := []byte(topoPrefix + "_")
+	return s.iterate(rocksEventsFamily, prefix, prefix, func(key, val []byte) (bool, error) {
+		data, err := s.get(rocksEventsFamily, val)
+		if err == errRocksDBNotFound {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		event, err := decodeEvent(data, "RocksDBStore.IterateTopologicalEvents")
+		if err != nil {
+			return false, err
+		}
+		//the index of the key, as Bootstrap may have renumbered the event
+		fmt.Sscanf(string(key[len(prefix):]), "%d", &event.Message.TopologicalIndex)
+		return true, fn(event)
+	})
+}
+
+// IterateParticipantEvents calls fn with the stored events of participant
+// from index from
+func (s *RocksDBStore) IterateParticipantEvents(participant string, from int64, fn func(Event) error) error {
+	prefix := []byte(participant + "__event_")
+	return s.iterate(rocksEventsFamily, prefix, participantEventKey(participant, from), func(key, val []byte) (bool, error) {
+		data, err := s.get(rocksEventsFamily, val)
+		if err == errRocksDBNotFound {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		event, err := decodeEvent(data, "RocksDBStore.IterateParticipantEvents")
+		if err != nil {
+			return false, err
+		}
+		return true, fn(event)
+	})
+}
+
+// TopologicalEvents returns the stored events in topological order
+func (s *RocksDBStore) TopologicalEvents() ([]Event, error) {
+	var res []Event
+	err := s.IterateTopologicalEvents(func(event Event) error {
+		res = append(res, event)
+		return nil
+	})
+	return res, err
+}
+
+//++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++++
+//DB Methods
+
+// get reads the value of key in the column family, errRocksDBNotFound when
+// there is none
+func (s *RocksDBStore) get(family string, key []byte) ([]byte, error) {
+	slice, err := s.db.GetCF(s.ro, s.families[family], key)
+	if err != nil {
+		return nil, err
+	}
+	defer slice.Free()
+	if !slice.Exists() {
+		return nil, errRocksDBNotFound
+	}
+	return append([]byte(nil), slice.Data()...), nil
+}
+
+func (s *RocksDBStore) put(family string, key, val []byte) error {
+	return s.db.PutCF(s.wo, s.families[family], key, val)
+}
+
+// iterate calls fn with the keys of the column family starting with prefix,
+// from start on, in order, until fn returns false or an error
+func (s *RocksDBStore) iterate(family string, prefix, start []byte, fn func(key, val []byte) (bool, error)) error {
+	it := s.db.NewIteratorCF(s.ro, s.families[family])
+	defer it.Close()
+	for it.Seek(start); it.ValidForPrefix(prefix); it.Next() {
+		key := it.Key()
+		val := it.Value()
+		more, err := fn(append([]byte(nil), key.Data()...), append([]byte(nil), val.Data()...))
+		key.Free()
+		val.Free()
+		if err != nil || !more {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+func (s *RocksDBStore) closeDB() {
+	for _, h := range s.families {
+		h.Destroy()
+	}
+	s.db.Close()
+	s.ro.Destroy()
+	s.wo.Destroy()
+	s.opts.Destroy()
+}
+
+func (s *RocksDBStore) dbGetEvent(key string) (Event, error) {
+	data, err := s.get(rocksEventsFamily, []byte(key))
+	if err != nil {
+		return Event{}, err
+	}
+	return decodeEvent(data, "RocksDBStore.GetEvent")
+}
+
+func (s *RocksDBStore) dbSetEvents(events []Event) error {
+	batch := gorocksdb.NewWriteBatch()
+	defer batch.Destroy()
+	family := s.families[rocksEventsFamily]
+	for _, event := range events {
+		eventHex := event.Hex()
+		val, err := event.ProtoMarshal()
+		if err != nil {
+			return err
+		}
+		_, err = s.get(rocksEventsFamily, []byte(eventHex))
+		if err != nil && err != errRocksDBNotFound {
+			return err
+		}
+		//insert [event hash] => [event bytes]
+		batch.PutCF(family, []byte(eventHex), encodeRecord(s.compression, val))
+		if err == errRocksDBNotFound {
+			//insert [topo_index] => [event hash]
+			batch.PutCF(family, topologicalEventKey(event.Message.TopologicalIndex), []byte(eventHex))
+			//insert [participant_index] => [event hash]
+			batch.PutCF(family, participantEventKey(event.Creator(), event.Index()), []byte(eventHex))
+		}
+	}
+	return s.db.Write(s.wo, batch)
+}
+
+func (s *RocksDBStore) dbParticipantEvents(participant string, skip int64) ([]string, error) {
+	var res []string
+	for i := skip + 1; ; i++ {
+		data, err := s.get(rocksEventsFamily, participantEventKey(participant, i))
+		if err == errRocksDBNotFound {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		res = append(res, string(data))
+	}
+}
+
+func (s *RocksDBStore) dbSetRoots(roots map[string]Root) error {
+	batch := gorocksdb.NewWriteBatch()
+	defer batch.Destroy()
+	family := s.families[rocksDefaultFamily]
+	for participant, root := range roots {
+		val, err := root.ProtoMarshal()
+		if err != nil {
+			return err
+		}
+		//insert [participant_root] => [root bytes]
+		batch.PutCF(family, participantRootKey(participant), val)
+	}
+	return s.db.Write(s.wo, batch)
+}
+
+func (s *RocksDBStore) dbSetRootEvents(roots map[string]Root) error {
+	for participant, root := range roots {
+		var creator []byte
+		fmt.Sscanf(participant, "0x%X", &creator)
+		ft, _ := proto.Marshal(&FlagTableWrapper{Body: map[string]int64{root.SelfParent.Hash: 1}})
+		event := Event{
+			Message: EventMessage{
+				Hex:       root.SelfParent.Hash,
+				CreatorID: root.SelfParent.CreatorID,
+				Body: &EventBody{
+					Creator: creator,
+					Index:   root.SelfParent.Index,
+					Parents: []string{"", ""},
+				},
+				TopologicalIndex: -1,
+				FlagTable:        ft,
+				WitnessProof:     []string{root.SelfParent.Hash},
+			},
+		}
+		if err := s.SetEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RocksDBStore) dbGetRoot(participant string) (Root, error) {
+	data, err := s.get(rocksDefaultFamily, participantRootKey(participant))
+	if err != nil {
+		return Root{}, err
+	}
+	root := new(Root)
+	if err := root.ProtoUnmarshal(data); err != nil {
+		return Root{}, lerrors.Wrap(lerrors.StoreCorrupt, "RocksDBStore.GetRoot", err)
+	}
+	return *root, nil
+}
+
+func (s *RocksDBStore) dbGetRound(index int64) (RoundInfo, error) {
+	data, err := s.get(rocksRoundsFamily, roundKey(index))
+	if err != nil {
+		return *NewRoundInfo(), err
+	}
+	roundInfo := new(RoundInfo)
+	if err := roundInfo.ProtoUnmarshal(data); err != nil {
+		return *NewRoundInfo(), lerrors.Wrap(lerrors.StoreCorrupt, "RocksDBStore.GetRound", err)
+	}
+	return *roundInfo, nil
+}
+
+func (s *RocksDBStore) dbGetParticipants() (*peers.Peers, error) {
+	res := peers.NewPeers()
+	prefix := []byte(participantPrefix)
+	err := s.iterate(rocksDefaultFamily, prefix, prefix, func(key, val []byte) (bool, error) {
+		pubKey := string(key[len(participantPrefix)+1:])
+		res.AddPeer(peers.NewPeer(pubKey, ""))
+		return true, nil
+	})
+	return res, err
+}
+
+func (s *RocksDBStore) dbSetParticipants(participants *peers.Peers) error {
+	batch := gorocksdb.NewWriteBatch()
+	defer batch.Destroy()
+	family := s.families[rocksDefaultFamily]
+	for _, id := range participants.ToPeerSlice() {
+		participant := id.PubKeyHex
+		//insert [participant_participant] => [id]
+		batch.PutCF(family, participantKey(participant), []byte(strconv.FormatInt(id.ID, 10)))
+	}
+	return s.db.Write(s.wo, batch)
+}
+
+func (s *RocksDBStore) dbGetBlock(index int64) (Block, error) {
+	data, err := s.get(rocksBlocksFamily, blockKey(index))
+	if err != nil {
+		return Block{}, err
+	}
+	return decodeBlock(data, "RocksDBStore.GetBlock")
+}
+
+func (s *RocksDBStore) dbGetFrame(index int64) (Frame, error) {
+	data, err := s.get(rocksFramesFamily, frameKey(index))
+	if err != nil {
+		return Frame{}, err
+	}
+	data, err = decodeRecord(data)
+	if err != nil {
+		return Frame{}, lerrors.Wrap(lerrors.StoreCorrupt, "RocksDBStore.GetFrame", err)
+	}
+	frame := new(Frame)
+	if err := frame.ProtoUnmarshal(data); err != nil {
+		return Frame{}, lerrors.Wrap(lerrors.StoreCorrupt, "RocksDBStore.GetFrame", err)
+	}
+	return *frame, nil
+}
+
+func (s *RocksDBStore) dbGetCheckpoint(index int64) (Checkpoint, error) {
+	data, err := s.get(rocksDefaultFamily, checkpointKey(index))
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	var checkpoint Checkpoint
+	if err := checkpoint.Unmarshal(data); err != nil {
+		return Checkpoint{}, lerrors.Wrap(lerrors.StoreCorrupt, "RocksDBStore.GetCheckpoint", err)
+	}
+	return checkpoint, nil
+}
+
+func mapRocksDBError(err error, name, key string) error {
+	if err == errRocksDBNotFound {
+		return cm.NewStoreErr(name, cm.KeyNotFound, key)
+	}
+	return err
+}
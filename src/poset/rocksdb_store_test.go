@@ -0,0 +1,116 @@
+//go:build rocksdb
+// +build rocksdb
+
+package poset
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+// the RocksDBStore runs the conformance tests when built with the rocksdb
+// tag
+func init() {
+	storeBackends = append(storeBackends, storeBackend{
+		name: "rocksdb",
+		create: func(participants *peers.Peers, cacheSize int, dir string) (Store, error) {
+			return NewRocksDBStore(participants, cacheSize, dir)
+		},
+		load: func(cacheSize int, dir string) (Store, error) {
+			return LoadRocksDBStore(cacheSize, dir)
+		},
+	})
+}
+
+// benchmarkStore creates a Store of the backend name in a temporary
+// directory, and calls fn with it and its participants
+func benchmarkStore(b *testing.B, name string, fn func(Store, []pub)) {
+	var backend storeBackend
+	for _, sb := range storeBackends {
+		if sb.name == name {
+			backend = sb
+		}
+	}
+	dir, err := ioutil.TempDir("", "bench-"+name)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var pubs []pub
+	participants := peers.NewPeers()
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateECDSAKey()
+		pubKey := crypto.FromECDSAPub(&key.PublicKey)
+		peer := peers.NewPeer(fmt.Sprintf("0x%X", pubKey), "")
+		participants.AddPeer(peer)
+		pubs = append(pubs, pub{peer.ID, key, pubKey, peer.PubKeyHex})
+	}
+	// a small cache, so that the reads hit the database
+	store, err := backend.create(participants, 10, filepath.Join(dir, "db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer store.Close()
+	fn(store, pubs)
+}
+
+func benchmarkStoreSetEvents(b *testing.B, name string) {
+	benchmarkStore(b, name, func(store Store, pubs []pub) {
+		events := make([]Event, b.N)
+		for k := range events {
+			p := pubs[k%len(pubs)]
+			events[k] = NewEvent([][]byte{[]byte(fmt.Sprintf("tx_%d", k))},
+				nil, nil, []string{"", ""}, p.pubKey, int64(k/len(pubs)), nil)
+			events[k].Message.TopologicalIndex = int64(k)
+		}
+
+		b.ResetTimer()
+		for _, event := range events {
+			if err := store.SetEvent(event); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func benchmarkStoreGetBlocks(b *testing.B, name string) {
+	benchmarkStore(b, name, func(store Store, pubs []pub) {
+		const blocks = 1000
+		for i := int64(0); i < blocks; i++ {
+			block := NewBlock(i, i, nil, [][]byte{[]byte(fmt.Sprintf("tx_%d", i))})
+			if err := store.SetBlock(block); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		b.ResetTimer()
+		for k := 0; k < b.N; k++ {
+			if _, err := store.GetBlock(int64(k % blocks)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkStoreSetEventsBadger(b *testing.B) {
+	benchmarkStoreSetEvents(b, "badger")
+}
+
+func BenchmarkStoreSetEventsRocksDB(b *testing.B) {
+	benchmarkStoreSetEvents(b, "rocksdb")
+}
+
+func BenchmarkStoreGetBlocksBadger(b *testing.B) {
+	benchmarkStoreGetBlocks(b, "badger")
+}
+
+func BenchmarkStoreGetBlocksRocksDB(b *testing.B) {
+	benchmarkStoreGetBlocks(b, "rocksdb")
+}
@@ -0,0 +1,57 @@
+package poset
+
+import "fmt"
+
+// AddSignatures adds to the SigPool the block signatures gossiped by a peer
+// outside of the events, for ProcessSigPool to count them. Only the valid
+// signatures of validators of the peer set, for decided blocks above the
+// AnchorBlock which do not hold them yet, are added, so that a peer cannot
+// fill the pool. It returns the number of signatures added.
+func (p *Poset) AddSignatures(sigs []BlockSignature) int {
+	defer p.pinPeers()()
+	anchor := int64(-1)
+	if a := p.GetAnchorBlock(); a != nil {
+		anchor = *a
+	}
+	last := p.Store.LastBlockIndex()
+
+	pending := make(map[string]bool)
+	for _, bs := range p.GetSigPool() {
+		pending[sigKey(bs)] = true
+	}
+
+	var added []BlockSignature
+	for _, bs := range sigs {
+		if bs.Index <= anchor || bs.Index > last || pending[sigKey(bs)] {
+			continue
+		}
+		validatorHex := bs.ValidatorHex()
+		if !p.peerSnapshot().IsValidator(validatorHex) {
+			continue
+		}
+		block, err := p.Store.GetBlock(bs.Index)
+		if err != nil {
+			continue
+		}
+		if _, ok := block.Signatures[validatorHex]; ok {
+			continue
+		}
+		if valid, err := block.Verify(bs); err != nil || !valid {
+			continue
+		}
+		pending[sigKey(bs)] = true
+		added = append(added, bs)
+	}
+
+	if len(added) > 0 {
+		p.stateLock.Lock()
+		p.SigPool = append(p.SigPool, added...)
+		p.stateLock.Unlock()
+	}
+	return len(added)
+}
+
+// sigKey identifies the signature of a block by a validator
+func sigKey(bs BlockSignature) string {
+	return fmt.Sprintf("%X/%d", bs.Validator, bs.Index)
+}
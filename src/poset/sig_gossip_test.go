@@ -0,0 +1,54 @@
+package poset
+
+import (
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func TestAddSignatures(t *testing.T) {
+	p, nodes, _ := initBlockPoset(t)
+
+	block, err := p.Store.GetBlock(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := make([]BlockSignature, len(nodes))
+	for i, node := range nodes {
+		if sigs[i], err = block.Sign(node.Key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a signature by another key, one of an unknown validator and one of a
+	// block not decided yet
+	forged := sigs[0]
+	forged.Signature = sigs[1].Signature
+	key, _ := crypto.GenerateECDSAKey()
+	unknown, err := block.Sign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	undecided := sigs[0]
+	undecided.Index = 1
+
+	if added := p.AddSignatures([]BlockSignature{forged, unknown, undecided}); added != 0 {
+		t.Fatalf("no invalid signature should be added, got %d", added)
+	}
+	if added := p.AddSignatures(sigs); added != len(sigs) {
+		t.Fatalf("%d signatures should be added, got %d", len(sigs), added)
+	}
+	if added := p.AddSignatures(sigs); added != 0 {
+		t.Fatalf("the signatures are already pending, got %d added", added)
+	}
+
+	if err := p.ProcessSigPool(); err != nil {
+		t.Fatal(err)
+	}
+	if a := p.GetAnchorBlock(); a == nil || *a != 0 {
+		t.Fatalf("block 0 should be the anchor block, got %v", a)
+	}
+	if added := p.AddSignatures(sigs); added != 0 {
+		t.Fatalf("the signatures of the anchor block should not be added, got %d", added)
+	}
+}
@@ -0,0 +1,75 @@
+package poset
+
+// The consensus methods of a Poset, InsertEvent, DivideRounds, DecideFame,
+// DecideRoundReceived, ProcessDecidedRounds, ProcessSigPool, Bootstrap and
+// Reset, must not run concurrently: the node serialises them with its core
+// lock. Only they read the exported fields of the Poset directly. Other
+// goroutines, serving stats, the explorer or the peer selector, go through
+// the accessors below, which are safe to call at any time and return copies
+// of the state, never slices shared with the consensus methods.
+
+// GetUndeterminedEvents returns a copy of the hashes of the events whose
+// consensus order is not yet determined
+func (p *Poset) GetUndeterminedEvents() []string {
+	p.stateLock.RLock()
+	defer p.stateLock.RUnlock()
+	res := make([]string, len(p.UndeterminedEvents))
+	copy(res, p.UndeterminedEvents)
+	return res
+}
+
+// GetSigPool returns a copy of the block signatures waiting to be processed
+func (p *Poset) GetSigPool() []BlockSignature {
+	p.stateLock.RLock()
+	defer p.stateLock.RUnlock()
+	res := make([]BlockSignature, len(p.SigPool))
+	copy(res, p.SigPool)
+	return res
+}
+
+// GetPendingLoadedEvents returns the number of loaded events not yet
+// committed
+func (p *Poset) GetPendingLoadedEvents() int64 {
+	p.stateLock.RLock()
+	defer p.stateLock.RUnlock()
+	return p.PendingLoadedEvents
+}
+
+// GetConsensusTransactions returns the number of transactions committed
+func (p *Poset) GetConsensusTransactions() uint64 {
+	p.stateLock.RLock()
+	defer p.stateLock.RUnlock()
+	return p.ConsensusTransactions
+}
+
+// GetLastConsensusRound returns a copy of the index of the last round whose
+// fame is decided, nil before the first one
+func (p *Poset) GetLastConsensusRound() *int64 {
+	p.stateLock.RLock()
+	defer p.stateLock.RUnlock()
+	return copyIndex(p.LastConsensusRound)
+}
+
+// GetAnchorBlock returns a copy of the index of the last block with enough
+// signatures, nil before the first one
+func (p *Poset) GetAnchorBlock() *int64 {
+	p.stateLock.RLock()
+	defer p.stateLock.RUnlock()
+	return copyIndex(p.AnchorBlock)
+}
+
+// GetLastCommitedRoundEvents returns the number of events in the round
+// before the last consensus round
+func (p *Poset) GetLastCommitedRoundEvents() int {
+	p.stateLock.RLock()
+	defer p.stateLock.RUnlock()
+	return p.LastCommitedRoundEvents
+}
+
+func copyIndex(i *int64) *int64 {
+	if i == nil {
+		return nil
+	}
+	res := *i
+	return &res
+}
@@ -0,0 +1,79 @@
+package poset
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestPosetConcurrentAccess reads the state through the accessors while the
+// events are inserted and the consensus runs. Run it with -race.
+func TestPosetConcurrentAccess(t *testing.T) {
+	p0, _, orderedEvents, _ := initPosetFull(t, consensusPlays(), false, n,
+		testLogger(t))
+	participants := p0.Participants
+	p := NewPoset(participants, NewInmemStore(participants, cacheSize), nil,
+		testLogger(t))
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				undetermined := p.GetUndeterminedEvents()
+				if len(undetermined) > 0 {
+					// the copy is ours
+					undetermined[0] = ""
+				}
+				p.GetSigPool()
+				p.GetPendingLoadedEvents()
+				p.GetConsensusTransactions()
+				p.GetLastConsensusRound()
+				p.GetAnchorBlock()
+				p.GetLastCommitedRoundEvents()
+				p.GetFlagTableOfRandomUndeterminedEvent()
+			}
+		}()
+	}
+
+	for i, ev := range *orderedEvents {
+		if err := p.InsertEvent(ev, true); err != nil {
+			t.Fatalf("failed to insert event %d: %s", i, err)
+		}
+		if i%4 != 3 {
+			continue
+		}
+		if err := p.DivideRounds(); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.DecideFame(); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.DecideRoundReceived(); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.ProcessDecidedRounds(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if p.GetLastConsensusRound() == nil {
+		t.Fatal("the consensus should have decided a round")
+	}
+	for _, hash := range p.GetUndeterminedEvents() {
+		if hash == "" {
+			t.Fatal("modifying a copy of the undetermined events changed the poset")
+		}
+	}
+	if l := p.GetLastConsensusRound(); l == p.LastConsensusRound {
+		t.Fatal("GetLastConsensusRound should return a copy")
+	}
+}
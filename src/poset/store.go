@@ -29,8 +29,20 @@ type Store interface {
 	GetBlock(int64) (Block, error)
 	SetBlock(Block) error
 	LastBlockIndex() int64
+	IterateBlocks(int64, int64, func(Block) error) error
+	IterateTopologicalEvents(func(Event) error) error
+	IterateParticipantEvents(string, int64, func(Event) error) error
 	GetFrame(int64) (Frame, error)
 	SetFrame(Frame) error
+	GetCheckpoint(int64) (Checkpoint, error)
+	SetCheckpoint(Checkpoint) error
+	LastCheckpointIndex() int64
+	AckedBlock() (int64, error)
+	SetAckedBlock(int64) error
+	TxWindow() ([][]byte, error)
+	SetTxWindow([][]byte) error
+	NetworkParams() (*NetworkParams, error)
+	SetNetworkParams(*NetworkParams) error
 	Reset(map[string]Root) error
 	Close() error
 	NeedBoostrap() bool // Was the store loaded from existing db
@@ -0,0 +1,398 @@
+package poset
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+// storeBackend creates a Store of a kind in dir, and reopens it when it is
+// persistent
+type storeBackend struct {
+	name   string
+	create func(participants *peers.Peers, cacheSize int, dir string) (Store, error)
+	load   func(cacheSize int, dir string) (Store, error)
+}
+
+var storeBackends = []storeBackend{
+	{
+		name: "inmem",
+		create: func(participants *peers.Peers, cacheSize int, dir string) (Store, error) {
+			return NewInmemStore(participants, cacheSize), nil
+		},
+	},
+	{
+		name: "badger",
+		create: func(participants *peers.Peers, cacheSize int, dir string) (Store, error) {
+			return NewBadgerStore(participants, cacheSize, dir)
+		},
+		load: func(cacheSize int, dir string) (Store, error) {
+			return LoadBadgerStore(cacheSize, dir)
+		},
+	},
+	{
+		name: "hybrid",
+		create: func(participants *peers.Peers, cacheSize int, dir string) (Store, error) {
+			return NewHybridStore(participants, cacheSize, dir)
+		},
+		load: func(cacheSize int, dir string) (Store, error) {
+			return LoadHybridStore(cacheSize, dir)
+		},
+	},
+	{
+		name: "leveldb",
+		create: func(participants *peers.Peers, cacheSize int, dir string) (Store, error) {
+			return NewLevelDBStore(participants, cacheSize, dir)
+		},
+		load: func(cacheSize int, dir string) (Store, error) {
+			return LoadLevelDBStore(cacheSize, dir)
+		},
+	},
+}
+
+// TestStoreConformance runs the same scenario against every Store, so that
+// they behave identically
+func TestStoreConformance(t *testing.T) {
+	for _, backend := range storeBackends {
+		backend := backend
+		t.Run(backend.name, func(t *testing.T) {
+			testStoreConformance(t, backend)
+		})
+	}
+}
+
+func testStoreConformance(t *testing.T, backend storeBackend) {
+	dir, err := ioutil.TempDir("", "store-"+backend.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dbDir := filepath.Join(dir, "db")
+
+	var pubs []pub
+	participants := peers.NewPeers()
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateECDSAKey()
+		pubKey := crypto.FromECDSAPub(&key.PublicKey)
+		peer := peers.NewPeer(fmt.Sprintf("0x%X", pubKey), "")
+		participants.AddPeer(peer)
+		pubs = append(pubs, pub{peer.ID, key, pubKey, peer.PubKeyHex})
+	}
+
+	const cacheSize = 100
+	const testSize = int64(20)
+	store, err := backend.create(participants, cacheSize, dbDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the initial state
+	for _, p := range pubs {
+		if _, err := store.GetRoot(p.hex); err != nil {
+			t.Fatalf("root of %s: %s", p.hex, err)
+		}
+		// the persistent stores also write the root events
+		if _, _, err := store.LastEventFrom(p.hex); err != nil {
+			t.Fatalf("last event of %s: %s", p.hex, err)
+		}
+	}
+	if _, err := store.GetEvent("0xABCD"); !lerrors.Is(err, lerrors.KeyNotFound) {
+		t.Fatalf("a missing event should not be found, got %v", err)
+	}
+
+	// events
+	events := make(map[string][]Event)
+	topo := int64(0)
+	for k := int64(0); k < testSize; k++ {
+		for _, p := range pubs {
+			event := NewEvent([][]byte{[]byte(fmt.Sprintf("%s_%d", p.hex[:5], k))},
+				nil, nil, []string{"", ""}, p.pubKey, k, nil)
+			event.Message.TopologicalIndex = topo
+			topo++
+			if err := store.SetEvent(event); err != nil {
+				t.Fatal(err)
+			}
+			events[p.hex] = append(events[p.hex], event)
+		}
+	}
+	checkEvents := func(store Store) {
+		for p, evs := range events {
+			for k, ev := range evs {
+				rev, err := store.GetEvent(ev.Hex())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !ev.Message.Body.Equals(rev.Message.Body) {
+					t.Fatalf("events[%s][%d] should be %#v, not %#v", p, k, ev, rev)
+				}
+				hash, err := store.ParticipantEvent(p, int64(k))
+				if err != nil || hash != ev.Hex() {
+					t.Fatalf("ParticipantEvent(%s, %d) should be %s, got %s %v", p, k, ev.Hex(), hash, err)
+				}
+			}
+		}
+	}
+	checkEvents(store)
+	for _, p := range pubs {
+		hashes, err := store.ParticipantEvents(p.hex, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(hashes)) != testSize-5 || hashes[0] != events[p.hex][5].Hex() {
+			t.Fatalf("ParticipantEvents(%s, 4) should start at the 5th event, got %d events", p.hex, len(hashes))
+		}
+		last, isRoot, err := store.LastEventFrom(p.hex)
+		if err != nil || isRoot || last != events[p.hex][testSize-1].Hex() {
+			t.Fatalf("the last event of %s should be %s, got %s %v %v", p.hex, events[p.hex][testSize-1].Hex(), last, isRoot, err)
+		}
+	}
+	expectedKnown := make(map[int64]int64)
+	for _, p := range pubs {
+		expectedKnown[p.id] = testSize - 1
+	}
+	if known := store.KnownEvents(); !reflect.DeepEqual(known, expectedKnown) {
+		t.Fatalf("known events should be %v, not %v", expectedKnown, known)
+	}
+	for _, ev := range events[pubs[0].hex][:3] {
+		if err := store.AddConsensusEvent(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := store.ConsensusEventsCount(); n != 3 {
+		t.Fatalf("there should be 3 consensus events, not %d", n)
+	}
+
+	// rounds
+	round := NewRoundInfo()
+	for _, p := range pubs {
+		round.AddEvent(events[p.hex][0].Hex(), true)
+	}
+	round.AddEvent(events[pubs[0].hex][1].Hex(), false)
+	if err := store.SetRound(0, *round); err != nil {
+		t.Fatal(err)
+	}
+	if store.LastRound() != 0 {
+		t.Fatalf("the last round should be 0, not %d", store.LastRound())
+	}
+	if n := len(store.RoundWitnesses(0)); n != len(pubs) {
+		t.Fatalf("round 0 should have %d witnesses, not %d", len(pubs), n)
+	}
+	if n := store.RoundEvents(0); n != len(pubs)+1 {
+		t.Fatalf("round 0 should have %d events, not %d", len(pubs)+1, n)
+	}
+
+	// blocks and frames
+	frame := Frame{Round: 0, Events: []*EventMessage{&events[pubs[0].hex][0].Message}}
+	if err := store.SetFrame(frame); err != nil {
+		t.Fatal(err)
+	}
+	block := NewBlock(0, 0, []byte("framehash"), [][]byte{[]byte("tx")})
+	if err := store.SetBlock(block); err != nil {
+		t.Fatal(err)
+	}
+	if store.LastBlockIndex() != 0 {
+		t.Fatalf("the last block should be 0, not %d", store.LastBlockIndex())
+	}
+	checkBlocks := func(store Store) {
+		rblock, err := store.GetBlock(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !rblock.Body.Equals(block.Body) {
+			t.Fatalf("block 0 should be %#v, not %#v", block.Body, rblock.Body)
+		}
+		rframe, err := store.GetFrame(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rframe.Events) != 1 || rframe.Events[0].Hex != frame.Events[0].Hex {
+			t.Fatalf("frame 0 should hold %s, got %v", frame.Events[0].Hex, rframe.Events)
+		}
+		if _, err := store.GetBlock(5); err == nil {
+			t.Fatal("a block which was never set should not be found")
+		}
+	}
+	checkBlocks(store)
+
+	// checkpoints
+	if store.LastCheckpointIndex() != -1 {
+		t.Fatalf("there should be no checkpoint, got %d", store.LastCheckpointIndex())
+	}
+	checkpoint := Checkpoint{Index: 0, FromRound: 0, ToRound: 0, Root: []byte("root"), Block: 0}
+	if err := store.SetCheckpoint(checkpoint); err != nil {
+		t.Fatal(err)
+	}
+	if store.LastCheckpointIndex() != 0 {
+		t.Fatalf("the last checkpoint should be 0, not %d", store.LastCheckpointIndex())
+	}
+	checkCheckpoints := func(store Store) {
+		rcheckpoint, err := store.GetCheckpoint(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(rcheckpoint, checkpoint) {
+			t.Fatalf("checkpoint 0 should be %#v, not %#v", checkpoint, rcheckpoint)
+		}
+		if _, err := store.GetCheckpoint(1); !lerrors.Is(err, lerrors.KeyNotFound) {
+			t.Fatalf("a missing checkpoint should not be found, got %v", err)
+		}
+	}
+	checkCheckpoints(store)
+
+	// the acknowledged block
+	if acked, err := store.AckedBlock(); err != nil || acked != -1 {
+		t.Fatalf("no block should be acknowledged, got %d %v", acked, err)
+	}
+	if err := store.SetAckedBlock(0); err != nil {
+		t.Fatal(err)
+	}
+	checkAcked := func(store Store) {
+		if acked, err := store.AckedBlock(); err != nil || acked != 0 {
+			t.Fatalf("block 0 should be acknowledged, got %d %v", acked, err)
+		}
+	}
+	checkAcked(store)
+
+	// the transaction window
+	if hashes, err := store.TxWindow(); err != nil || len(hashes) != 0 {
+		t.Fatalf("the transaction window should be empty, got %v %v", hashes, err)
+	}
+	window := [][]byte{crypto.SHA256([]byte("tx0")), crypto.SHA256([]byte("tx1"))}
+	if err := store.SetTxWindow(window); err != nil {
+		t.Fatal(err)
+	}
+	checkTxWindow := func(store Store) {
+		if hashes, err := store.TxWindow(); err != nil || !reflect.DeepEqual(hashes, window) {
+			t.Fatalf("the transaction window should be %v, got %v %v", window, hashes, err)
+		}
+	}
+	checkTxWindow(store)
+
+	// the governance state
+	if params, err := store.NetworkParams(); err != nil || params != nil {
+		t.Fatalf("there should be no governance state, got %v %v", params, err)
+	}
+	params := NewNetworkParams()
+	params.Round = 7
+	params.Values["heartbeat"] = "50ms"
+	params.Pending = []ParamChange{{Name: "sync_limit", Value: "500", ActivationRound: 9}}
+	params.Proposals = []ParamProposal{{
+		Change:    ParamChange{Name: "heartbeat", Value: "1s", ActivationRound: 12},
+		Proposers: []string{pubs[0].hex},
+	}}
+	if err := store.SetNetworkParams(params); err != nil {
+		t.Fatal(err)
+	}
+	checkNetworkParams := func(store Store) {
+		if loaded, err := store.NetworkParams(); err != nil || !reflect.DeepEqual(loaded, params) {
+			t.Fatalf("the governance state should be %v, got %v %v", params, loaded, err)
+		}
+	}
+	checkNetworkParams(store)
+
+	// iterators
+	for i := int64(1); i <= 3; i++ {
+		if err := store.SetBlock(NewBlock(i, i, []byte("framehash"), nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stop := fmt.Errorf("stop")
+	checkIterators := func(store Store) {
+		var indexes []int64
+		err := store.IterateBlocks(1, 2, func(b Block) error {
+			indexes = append(indexes, b.Index())
+			return nil
+		})
+		if err != nil || !reflect.DeepEqual(indexes, []int64{1, 2}) {
+			t.Fatalf("IterateBlocks(1, 2) should return blocks 1 and 2, got %v %v", indexes, err)
+		}
+		if err := store.IterateBlocks(0, 5, func(b Block) error { return stop }); err != stop {
+			t.Fatalf("IterateBlocks should return the error of the function, got %v", err)
+		}
+
+		p := pubs[1].hex
+		var hashes []string
+		err = store.IterateParticipantEvents(p, 15, func(e Event) error {
+			hashes = append(hashes, e.Hex())
+			return nil
+		})
+		if err != nil || int64(len(hashes)) != testSize-15 || hashes[0] != events[p][15].Hex() {
+			t.Fatalf("IterateParticipantEvents(%s, 15) should start at the 15th event, got %d events %v", p, len(hashes), err)
+		}
+
+		next := int64(0)
+		err = store.IterateTopologicalEvents(func(e Event) error {
+			// the root events of the persistent stores come first
+			if e.Index() < 0 {
+				return nil
+			}
+			if e.Message.TopologicalIndex != next {
+				return fmt.Errorf("expected topological index %d, got %d", next, e.Message.TopologicalIndex)
+			}
+			next++
+			return nil
+		})
+		if err != nil || next != topo {
+			t.Fatalf("IterateTopologicalEvents should return %d events in order, got %d %v", topo, next, err)
+		}
+	}
+	checkIterators(store)
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if backend.load == nil {
+		return
+	}
+
+	// a persistent store reads everything back from its database, with a
+	// cache too small to hold it
+	loaded, err := backend.load(1, dbDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loaded.Close()
+	if !loaded.NeedBoostrap() {
+		t.Fatal("a loaded store should need a bootstrap")
+	}
+	lparticipants, err := loaded.Participants()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lparticipants.Len() != participants.Len() {
+		t.Fatalf("the store should hold %d participants, not %d", participants.Len(), lparticipants.Len())
+	}
+	checkEvents(loaded)
+	checkBlocks(loaded)
+	checkCheckpoints(loaded)
+	checkAcked(loaded)
+	checkTxWindow(loaded)
+	checkNetworkParams(loaded)
+	checkIterators(loaded)
+	rround, err := loaded.GetRound(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rround.Message.Events) != len(round.Message.Events) {
+		t.Fatalf("round 0 should have %d events, not %d", len(round.Message.Events), len(rround.Message.Events))
+	}
+	topological, err := loaded.(dbStore).dbTopologicalEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the root events of the participants come first, without an index
+	if n := int64(len(topological)); n < topo {
+		t.Fatalf("there should be at least %d events in topological order, not %d", topo, n)
+	}
+	last := topological[len(topological)-1]
+	if last.Message.TopologicalIndex != topo-1 {
+		t.Fatalf("the last event should have topological index %d, not %d", topo-1, last.Message.TopologicalIndex)
+	}
+}
@@ -0,0 +1,65 @@
+package poset
+
+import (
+	"sort"
+)
+
+// The events carry the time their creator created them at, which is signed
+// with them. The consensus time of a block is the median of the timestamps
+// of the famous witnesses of its round received, weighted by the stakes of
+// their creators: validators holding less than half of the weight, with
+// skewed clocks, cannot move it outside the range of the honest ones.
+
+// weightedTimestamp is the timestamp of a famous witness and the weight of
+// its creator
+type weightedTimestamp struct {
+	time   int64
+	weight uint64
+}
+
+// consensusTimestamp returns the consensus time of a round, in unix
+// nanoseconds: the weighted median of the timestamps of its famous
+// witnesses, those without timestamp left out, 0 when none has one. Every
+// famous witness is required, so that all the nodes compute it from the
+// same events.
+func (p *Poset) consensusTimestamp(r int64, round RoundInfo) (int64, error) {
+	snapshot := p.peerSnapshot()
+	var timestamps []weightedTimestamp
+	for _, w := range round.FamousWitnesses() {
+		event, err := p.Store.GetEvent(w)
+		if err != nil {
+			return 0, err
+		}
+		if t := event.Timestamp(); t > 0 {
+			timestamps = append(timestamps, weightedTimestamp{
+				time:   t,
+				weight: snapshot.Weight(event.Creator()),
+			})
+		}
+	}
+	return medianTimestamp(timestamps), nil
+}
+
+// medianTimestamp returns the weighted median of timestamps: the earliest
+// time by which at least half of the weight is reached, which is the lower
+// one of the two middle ones for an even number of equal weights, 0 for no
+// weight
+func medianTimestamp(timestamps []weightedTimestamp) int64 {
+	var total uint64
+	for _, t := range timestamps {
+		total += t.weight
+	}
+	if total == 0 {
+		return 0
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i].time < timestamps[j].time })
+	var weight uint64
+	for _, t := range timestamps {
+		weight += t.weight
+		// weight >= total/2 without overflowing
+		if weight >= total-weight {
+			return t.time
+		}
+	}
+	return timestamps[len(timestamps)-1].time
+}
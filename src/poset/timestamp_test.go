@@ -0,0 +1,35 @@
+package poset
+
+import "testing"
+
+func TestMedianTimestamp(t *testing.T) {
+	equal := func(times ...int64) []weightedTimestamp {
+		res := make([]weightedTimestamp, len(times))
+		for i, t := range times {
+			res[i] = weightedTimestamp{time: t, weight: 1}
+		}
+		return res
+	}
+	cases := []struct {
+		timestamps []weightedTimestamp
+		median     int64
+	}{
+		{nil, 0},
+		{equal(7), 7},
+		{equal(30, 10, 20), 20},
+		{equal(40, 10, 30, 20), 20},
+		// a skewed minority does not move the median
+		{equal(100, 101, 102, 1<<60), 101},
+		// nor does a majority of validators without weight
+		{[]weightedTimestamp{{1 << 60, 0}, {1 << 61, 0}, {100, 3}, {200, 1}}, 100},
+		// a heavy validator outweighs the others
+		{[]weightedTimestamp{{100, 1}, {101, 1}, {300, 5}}, 300},
+		// the weights do not overflow
+		{[]weightedTimestamp{{100, 1 << 62}, {200, 1 << 62}, {300, 1}}, 200},
+	}
+	for _, c := range cases {
+		if m := medianTimestamp(c.timestamps); m != c.median {
+			t.Fatalf("median of %v should be %d, not %d", c.timestamps, c.median, m)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package poset
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dgraph-io/badger"
+	"github.com/hashicorp/golang-lru"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	cm "github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+// txIndexPrefix is the prefix of the keys of the transaction index in the
+// database stores, followed by the hash of the transaction
+const txIndexPrefix = "txindex"
+
+func txIndexKey(hash string) []byte {
+	return []byte(fmt.Sprintf("%s_%s", txIndexPrefix, hash))
+}
+
+// TxLocation is where a committed transaction is: the block which committed
+// it, its position in the transactions of the block, and the consensus
+// event which carried it
+type TxLocation struct {
+	Hash     string
+	Block    int64
+	Position int
+	// Event is empty when the event was not indexed, the index being
+	// enabled after the event reached consensus
+	Event string
+}
+
+// TxIndexStore is a Store which can index the committed transactions by
+// hash, the InmemStore in its caches and the database stores in their
+// database
+type TxIndexStore interface {
+	// EnableTxIndex makes the store index the transactions of the
+	// consensus events and blocks written from now on
+	EnableTxIndex()
+	// GetTxLocation returns the location of the transaction of hash
+	// TxIndexHash, a KeyNotFound error when it was not indexed
+	GetTxLocation(hash string) (TxLocation, error)
+}
+
+// TxIndexHash returns the hash of a transaction in the index, the SHA256 of
+// the transaction in upper case hex prefixed with 0x
+func TxIndexHash(tx []byte) string {
+	return fmt.Sprintf("0x%X", crypto.SHA256(tx))
+}
+
+// txIndex is the transaction index of an InmemStore, bounded like its
+// caches
+type txIndex struct {
+	// events are the hashes of the consensus events of the transactions
+	// which are not in a block yet, by transaction hash
+	events    *lru.Cache
+	locations *lru.Cache
+}
+
+func newTxIndex(size int) *txIndex {
+	events, _ := lru.New(size)
+	locations, _ := lru.New(size)
+	return &txIndex{
+		events:    events,
+		locations: locations,
+	}
+}
+
+// addEvent records the event of the transactions of a consensus event
+func (i *txIndex) addEvent(event Event) {
+	for _, tx := range event.Transactions() {
+		i.events.Add(TxIndexHash(tx), event.Hex())
+	}
+}
+
+// addBlock indexes the transactions of a block
+func (i *txIndex) addBlock(block Block) {
+	for position, tx := range block.Transactions() {
+		hash := TxIndexHash(tx)
+		loc := TxLocation{
+			Hash:     hash,
+			Block:    block.Index(),
+			Position: position,
+		}
+		if event, ok := i.events.Get(hash); ok {
+			loc.Event = event.(string)
+			i.events.Remove(hash)
+		}
+		i.locations.Add(hash, loc)
+	}
+}
+
+func (i *txIndex) get(hash string) (TxLocation, bool) {
+	loc, ok := i.locations.Get(hash)
+	if !ok {
+		return TxLocation{}, false
+	}
+	return loc.(TxLocation), true
+}
+
+// EnableTxIndex makes the store index the committed transactions, as many
+// as its cache size
+func (s *InmemStore) EnableTxIndex() {
+	if s.txIndex == nil {
+		s.txIndex = newTxIndex(s.cacheSize)
+	}
+}
+
+// GetTxLocation returns the location of a committed transaction
+func (s *InmemStore) GetTxLocation(hash string) (TxLocation, error) {
+	if s.txIndex != nil {
+		if loc, ok := s.txIndex.get(hash); ok {
+			return loc, nil
+		}
+	}
+	return TxLocation{}, cm.NewStoreErr("TxIndex", cm.KeyNotFound, hash)
+}
+
+// blockTxLocations returns the locations of the transactions of a block the
+// store indexed, none when the index is disabled
+func (s *InmemStore) blockTxLocations(block Block) []TxLocation {
+	if s.txIndex == nil {
+		return nil
+	}
+	var locations []TxLocation
+	for _, tx := range block.Transactions() {
+		if loc, ok := s.txIndex.get(TxIndexHash(tx)); ok {
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
+
+// EnableTxIndex makes the store index the committed transactions in its
+// database
+func (s *BadgerStore) EnableTxIndex() {
+	s.inmemStore.EnableTxIndex()
+}
+
+// GetTxLocation returns the location of a committed transaction, from the
+// cache or the database
+func (s *BadgerStore) GetTxLocation(hash string) (TxLocation, error) {
+	if loc, err := s.inmemStore.GetTxLocation(hash); err == nil {
+		return loc, nil
+	}
+	var data []byte
+	err := s.view(func(txn *badger.Txn) error {
+		item, err := txn.Get(txIndexKey(hash))
+		if err != nil {
+			return err
+		}
+		data, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		return TxLocation{}, mapError(err, "TxIndex", string(txIndexKey(hash)))
+	}
+	var loc TxLocation
+	err = json.Unmarshal(data, &loc)
+	return loc, err
+}
+
+// dbSetTxLocations writes the locations of the transactions of a block
+func (s *BadgerStore) dbSetTxLocations(locations []TxLocation) error {
+	if len(locations) == 0 {
+		return nil
+	}
+	return s.update(func(txn *badger.Txn) error {
+		for _, loc := range locations {
+			val, err := json.Marshal(loc)
+			if err != nil {
+				return err
+			}
+			if err := txn.Set(txIndexKey(loc.Hash), val); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// EnableTxIndex makes the store index the committed transactions in its
+// database
+func (s *LevelDBStore) EnableTxIndex() {
+	s.inmemStore.EnableTxIndex()
+}
+
+// GetTxLocation returns the location of a committed transaction, from the
+// cache or the database
+func (s *LevelDBStore) GetTxLocation(hash string) (TxLocation, error) {
+	if loc, err := s.inmemStore.GetTxLocation(hash); err == nil {
+		return loc, nil
+	}
+	data, err := s.db.Get(txIndexKey(hash), nil)
+	if err != nil {
+		return TxLocation{}, mapLevelDBError(err, "TxIndex", string(txIndexKey(hash)))
+	}
+	var loc TxLocation
+	err = json.Unmarshal(data, &loc)
+	return loc, err
+}
+
+// dbSetTxLocations writes the locations of the transactions of a block
+func (s *LevelDBStore) dbSetTxLocations(locations []TxLocation) error {
+	if len(locations) == 0 {
+		return nil
+	}
+	batch := new(leveldb.Batch)
+	for _, loc := range locations {
+		val, err := json.Marshal(loc)
+		if err != nil {
+			return err
+		}
+		batch.Put(txIndexKey(loc.Hash), val)
+	}
+	return s.db.Write(batch, nil)
+}
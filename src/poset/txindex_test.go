@@ -0,0 +1,105 @@
+package poset
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+)
+
+func TestStoreTxIndex(t *testing.T) {
+	for _, backend := range storeBackends {
+		backend := backend
+		t.Run(backend.name, func(t *testing.T) {
+			testStoreTxIndex(t, backend)
+		})
+	}
+}
+
+func testStoreTxIndex(t *testing.T, backend storeBackend) {
+	dir, err := ioutil.TempDir("", "txindex-"+backend.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dbDir := filepath.Join(dir, "db")
+
+	key, _ := crypto.GenerateECDSAKey()
+	pubKey := crypto.FromECDSAPub(&key.PublicKey)
+	participants := peers.NewPeers()
+	participants.AddPeer(peers.NewPeer(fmt.Sprintf("0x%X", pubKey), ""))
+
+	store, err := backend.create(participants, 100, dbDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txStore, ok := store.(TxIndexStore)
+	if !ok {
+		t.Fatalf("the %s store should index transactions", backend.name)
+	}
+
+	// transactions committed before the index is enabled are not indexed
+	if err := store.SetBlock(NewBlock(0, 0, []byte("framehash"), [][]byte{[]byte("tx0")})); err != nil {
+		t.Fatal(err)
+	}
+	txStore.EnableTxIndex()
+	if _, err := txStore.GetTxLocation(TxIndexHash([]byte("tx0"))); !lerrors.Is(err, lerrors.KeyNotFound) {
+		t.Fatalf("a transaction committed before the index should not be found, got %v", err)
+	}
+
+	event := NewEvent([][]byte{[]byte("tx1"), []byte("tx2")}, nil, nil, []string{"", ""}, pubKey, 0, nil)
+	if err := store.SetEvent(event); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddConsensusEvent(event); err != nil {
+		t.Fatal(err)
+	}
+	txs := [][]byte{[]byte("tx3"), []byte("tx1"), []byte("tx2")}
+	if err := store.SetBlock(NewBlock(1, 1, []byte("framehash"), txs)); err != nil {
+		t.Fatal(err)
+	}
+
+	checkLocations := func(store TxIndexStore) {
+		for position, tx := range txs {
+			loc, err := store.GetTxLocation(TxIndexHash(tx))
+			if err != nil {
+				t.Fatalf("transaction %s: %s", tx, err)
+			}
+			if loc.Block != 1 || loc.Position != position {
+				t.Fatalf("transaction %s should be at position %d of block 1, not %d of %d", tx, position, loc.Position, loc.Block)
+			}
+			expectedEvent := event.Hex()
+			if position == 0 {
+				// tx3 was not in a consensus event
+				expectedEvent = ""
+			}
+			if loc.Event != expectedEvent {
+				t.Fatalf("transaction %s should be in event %q, not %q", tx, expectedEvent, loc.Event)
+			}
+		}
+		if _, err := store.GetTxLocation(TxIndexHash([]byte("tx4"))); !lerrors.Is(err, lerrors.KeyNotFound) {
+			t.Fatalf("an unknown transaction should not be found, got %v", err)
+		}
+	}
+	checkLocations(txStore)
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if backend.load == nil {
+		return
+	}
+
+	// the persistent stores read the index back from their database
+	loaded, err := backend.load(1, dbDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loaded.Close()
+	checkLocations(loaded.(TxIndexStore))
+}
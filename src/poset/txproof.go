@@ -0,0 +1,92 @@
+package poset
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+)
+
+// The body of a block carries the Merkle root of the SHA256 hashes of its
+// transactions, signed with it by the validators. A TxProof proves that a
+// transaction is part of a block to a light client which only kept the
+// TxRoot of the blocks it verified.
+
+// TxProof proves that the transaction of hash TxHash is part of the block of
+// index Block
+type TxProof struct {
+	Block  int64
+	TxHash []byte
+	Proof  *crypto.SimpleProof
+}
+
+// TxRoot returns the Merkle root of the SHA256 hashes of txs, nil for none
+func TxRoot(txs [][]byte) []byte {
+	return crypto.SimpleHashFromHashes(txHashes(txs))
+}
+
+// TxRoot returns the Merkle root of the transactions of the block, nil for a
+// block without transactions
+func (b *Block) TxRoot() []byte {
+	return b.Body.TxRoot
+}
+
+// ProveTx returns the proof that the transaction of hash txHash is part of
+// the block
+func ProveTx(block Block, txHash []byte) (*TxProof, error) {
+	if len(block.Body.TxRoot) == 0 && len(block.Transactions()) > 0 {
+		return nil, fmt.Errorf("block %d has no transactions root", block.Index())
+	}
+	hashes := txHashes(block.Transactions())
+	for i, hash := range hashes {
+		if bytes.Equal(hash, txHash) {
+			return &TxProof{
+				Block:  block.Index(),
+				TxHash: txHash,
+				Proof:  crypto.NewSimpleProof(hashes, i),
+			}, nil
+		}
+	}
+	return nil, lerrors.New(lerrors.KeyNotFound, "transaction 0x%X not in block %d", txHash, block.Index())
+}
+
+// VerifyTxProof checks that proof proves the transaction tx to be part of the
+// block of the given TxRoot and number of transactions, without the other
+// transactions of the block. The number is checked as the leaves and the
+// nodes of the tree are hashed alike: a 64 bytes transaction could otherwise
+// be proven to be the node of two others.
+func VerifyTxProof(txRoot []byte, txCount int, tx []byte, proof *TxProof) error {
+	if proof == nil || proof.Proof == nil {
+		return fmt.Errorf("no proof")
+	}
+	if proof.Proof.Total != txCount {
+		return fmt.Errorf("proof over %d transactions, the block has %d", proof.Proof.Total, txCount)
+	}
+	hash := crypto.SHA256(tx)
+	if !bytes.Equal(hash, proof.TxHash) {
+		return fmt.Errorf("proof of transaction 0x%X, not 0x%X", proof.TxHash, hash)
+	}
+	if !proof.Proof.Verify(txRoot, hash) {
+		return fmt.Errorf("invalid proof of transaction 0x%X in block %d", hash, proof.Block)
+	}
+	return nil
+}
+
+// TxProof returns the proof that the transaction of hash txHash is part of
+// the block of the given index
+func (p *Poset) TxProof(blockIndex int64, txHash []byte) (*TxProof, error) {
+	block, err := p.Store.GetBlock(blockIndex)
+	if err != nil {
+		return nil, err
+	}
+	return ProveTx(block, txHash)
+}
+
+func txHashes(txs [][]byte) [][]byte {
+	hashes := make([][]byte, len(txs))
+	for i, tx := range txs {
+		hashes[i] = crypto.SHA256(tx)
+	}
+	return hashes
+}
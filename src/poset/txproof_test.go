@@ -0,0 +1,48 @@
+package poset
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func TestTxProof(t *testing.T) {
+	var txs [][]byte
+	for i := 0; i < 5; i++ {
+		txs = append(txs, []byte(fmt.Sprintf("tx%d", i)))
+	}
+	block := NewBlock(3, 7, []byte("frame"), txs)
+	if len(block.TxRoot()) == 0 {
+		t.Fatal("the block should have a transactions root")
+	}
+
+	for i, tx := range txs {
+		proof, err := ProveTx(block, crypto.SHA256(tx))
+		if err != nil {
+			t.Fatalf("tx%d: %s", i, err)
+		}
+		if proof.Block != 3 {
+			t.Fatalf("tx%d: proof of block %d, not 3", i, proof.Block)
+		}
+		if err := VerifyTxProof(block.TxRoot(), len(txs), tx, proof); err != nil {
+			t.Fatalf("tx%d: %s", i, err)
+		}
+		if err := VerifyTxProof(block.TxRoot(), len(txs), []byte("other"), proof); err == nil {
+			t.Fatalf("tx%d: the proof should not prove another transaction", i)
+		}
+		if err := VerifyTxProof(block.TxRoot(), len(txs)+1, tx, proof); err == nil {
+			t.Fatalf("tx%d: the proof should not hold for another number of transactions", i)
+		}
+	}
+
+	if _, err := ProveTx(block, crypto.SHA256([]byte("missing"))); err == nil {
+		t.Fatal("a transaction not in the block should not be proven")
+	}
+
+	// the root follows the appended transactions
+	block.AppendTransactions([][]byte{[]byte("tx5")})
+	if string(block.TxRoot()) != string(TxRoot(block.Transactions())) {
+		t.Fatal("the root should cover the appended transaction")
+	}
+}
@@ -0,0 +1,192 @@
+package poset
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	cm "github.com/Fantom-foundation/go-lachesis/src/common"
+)
+
+// Divergence is the first block, or frame, of a store which does not match
+// the one recomputed from its events
+type Divergence struct {
+	Block  int64
+	Round  int64
+	Reason string
+}
+
+func (d *Divergence) String() string {
+	if d.Block < 0 {
+		return fmt.Sprintf("frame of round %d: %s", d.Round, d.Reason)
+	}
+	return fmt.Sprintf("block %d (round %d): %s", d.Block, d.Round, d.Reason)
+}
+
+// VerifyReport is the result of VerifyStore. Divergence is nil when the
+// stored blocks and frames all match the recomputed ones.
+type VerifyReport struct {
+	Events     int
+	Rounds     int64
+	Blocks     int64
+	Frames     int64
+	Divergence *Divergence
+}
+
+// VerifyStore replays the events of a store, in topological order, through
+// a fresh Poset and checks that the stored blocks and frames are those the
+// consensus recomputes from them. checkpointInterval is the one the store
+// was written with, the checkpoint blocks being part of the chain. A pruned
+// store no longer holds the whole history and cannot be verified.
+func VerifyStore(store Store, checkpointInterval int64, logger *logrus.Entry) (*VerifyReport, error) {
+	if db, ok := store.(dbStore); ok {
+		info, pruned, err := db.PruneInfo()
+		if err != nil {
+			return nil, err
+		}
+		if pruned {
+			return nil, fmt.Errorf("store pruned at block %d, its history cannot be replayed", info.Block)
+		}
+	}
+	participants, err := store.Participants()
+	if err != nil {
+		return nil, err
+	}
+
+	// the replayed store holds the whole history: every event, round,
+	// block and frame fits in its caches
+	report := &VerifyReport{}
+	networkID := ""
+	err = store.IterateTopologicalEvents(func(e Event) error {
+		if report.Events == 0 {
+			networkID = e.Message.Body.NetworkID
+		}
+		report.Events++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	replay := NewPoset(participants, NewInmemStore(participants, report.Events+1), nil, logger)
+	replay.SetNetworkID(networkID)
+	replay.SetCheckpointInterval(checkpointInterval)
+
+	err = store.IterateTopologicalEvents(func(e Event) error {
+		e.Message.Round = RoundNIL
+		e.Message.RoundReceived = RoundNIL
+		e.Message.LamportTimestamp = LamportTimestampNIL
+		if err := replay.InsertEvent(e, true); err != nil {
+			return fmt.Errorf("replaying event %s: %s", e.Hex(), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := replay.DivideRounds(); err != nil {
+		return nil, err
+	}
+	if err := replay.DecideFame(); err != nil {
+		return nil, err
+	}
+	if err := replay.DecideRoundReceived(); err != nil {
+		return nil, err
+	}
+	if err := replay.ProcessDecidedRounds(); err != nil {
+		return nil, err
+	}
+	report.Rounds = replay.Store.LastRound() + 1
+
+	for index := int64(0); ; index++ {
+		stored, err := store.GetBlock(index)
+		if cm.Is(err, cm.KeyNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Blocks++
+		if d, err := compareBlocks(stored, replay.Store); d != nil || err != nil {
+			report.Divergence = d
+			return report, err
+		}
+	}
+
+	for round := int64(0); round < report.Rounds; round++ {
+		stored, err := store.GetFrame(round)
+		if cm.Is(err, cm.KeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		report.Frames++
+		if d, err := compareFrames(round, stored, replay.Store); d != nil || err != nil {
+			report.Divergence = d
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// compareBlocks returns how the stored block differs from the recomputed one
+func compareBlocks(stored Block, replayed Store) (*Divergence, error) {
+	d := &Divergence{Block: stored.Index(), Round: stored.RoundReceived()}
+	block, err := replayed.GetBlock(stored.Index())
+	if cm.Is(err, cm.KeyNotFound) {
+		d.Reason = "not recomputed from the events"
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if block.RoundReceived() != stored.RoundReceived() {
+		d.Reason = fmt.Sprintf("recomputed in round %d", block.RoundReceived())
+		return d, nil
+	}
+	storedHash, err := stored.Body.Hash()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := block.Body.Hash()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(storedHash, hash) {
+		d.Reason = fmt.Sprintf("body 0x%X, recomputed 0x%X", storedHash, hash)
+		return d, nil
+	}
+	if !bytes.Equal(stored.FrameHash, block.FrameHash) {
+		d.Reason = fmt.Sprintf("frame hash 0x%X, recomputed 0x%X", stored.FrameHash, block.FrameHash)
+		return d, nil
+	}
+	return nil, nil
+}
+
+// compareFrames returns how the stored frame of round differs from the
+// recomputed one
+func compareFrames(round int64, stored Frame, replayed Store) (*Divergence, error) {
+	d := &Divergence{Block: -1, Round: round}
+	frame, err := replayed.GetFrame(round)
+	if cm.Is(err, cm.KeyNotFound) {
+		d.Reason = "not recomputed from the events"
+		return d, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	storedHash, err := stored.Hash()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := frame.Hash()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(storedHash, hash) {
+		d.Reason = fmt.Sprintf("hash 0x%X, recomputed 0x%X, with %d events instead of %d", storedHash, hash, len(stored.Events), len(frame.Events))
+		return d, nil
+	}
+	return nil, nil
+}
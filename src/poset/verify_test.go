@@ -0,0 +1,44 @@
+package poset
+
+import (
+	"testing"
+)
+
+func TestVerifyStore(t *testing.T) {
+	p, _ := initConsensusPoset(false, t)
+	p.DivideRounds()
+	p.DecideFame()
+	p.DecideRoundReceived()
+	if err := p.ProcessDecidedRounds(); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := VerifyStore(p.Store, 0, testLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Divergence != nil {
+		t.Fatalf("unexpected divergence: %s", report.Divergence)
+	}
+	if report.Blocks == 0 || report.Frames == 0 {
+		t.Fatalf("expected blocks and frames to be verified, got %+v", report)
+	}
+
+	// a block whose transactions differ from its events
+	block, err := p.Store.GetBlock(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block.Body.Transactions = [][]byte{[]byte("forged")}
+	if err := p.Store.SetBlock(block); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err = VerifyStore(p.Store, 0, testLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := report.Divergence; d == nil || d.Block != 1 {
+		t.Fatalf("expected a divergence at block 1, got %v", d)
+	}
+}
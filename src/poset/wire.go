@@ -0,0 +1,108 @@
+package poset
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// ToMessage returns the protobuf form of a WireEvent
+func (we *WireEvent) ToMessage() *WireEventMessage {
+	body := &WireBodyMessage{
+		Transactions:         we.Body.Transactions,
+		SelfParentIndex:      we.Body.SelfParentIndex,
+		OtherParentCreatorID: we.Body.OtherParentCreatorID,
+		OtherParentIndex:     we.Body.OtherParentIndex,
+		CreatorID:            we.Body.CreatorID,
+		Index:                we.Body.Index,
+		Timestamp:            we.Body.Timestamp,
+	}
+	for i := range we.Body.InternalTransactions {
+		body.InternalTransactions = append(body.InternalTransactions, &we.Body.InternalTransactions[i])
+	}
+	for i := range we.Body.BlockSignatures {
+		body.BlockSignatures = append(body.BlockSignatures, &we.Body.BlockSignatures[i])
+	}
+	return &WireEventMessage{
+		Body:         body,
+		Signature:    we.Signature,
+		FlagTable:    we.FlagTable,
+		WitnessProof: we.WitnessProof,
+		Hash:         we.Hash,
+	}
+}
+
+// ToWireEvent returns the WireEvent of its protobuf form
+func (m *WireEventMessage) ToWireEvent() WireEvent {
+	body := m.GetBody()
+	we := WireEvent{
+		Body: WireBody{
+			Transactions:         body.GetTransactions(),
+			SelfParentIndex:      body.GetSelfParentIndex(),
+			OtherParentCreatorID: body.GetOtherParentCreatorID(),
+			OtherParentIndex:     body.GetOtherParentIndex(),
+			CreatorID:            body.GetCreatorID(),
+			Index:                body.GetIndex(),
+			Timestamp:            body.GetTimestamp(),
+		},
+		Signature:    m.Signature,
+		FlagTable:    m.FlagTable,
+		WitnessProof: m.WitnessProof,
+		Hash:         m.Hash,
+	}
+	for _, tx := range body.GetInternalTransactions() {
+		we.Body.InternalTransactions = append(we.Body.InternalTransactions, *tx)
+	}
+	for _, bs := range body.GetBlockSignatures() {
+		we.Body.BlockSignatures = append(we.Body.BlockSignatures, *bs)
+	}
+	return we
+}
+
+// NewWirePayload gathers the events, blocks and frame of an RPC payload,
+// any of them possibly empty
+func NewWirePayload(events []WireEvent, blocks []Block, frame *Frame) *WirePayload {
+	p := &WirePayload{Frame: frame}
+	for i := range events {
+		p.Events = append(p.Events, events[i].ToMessage())
+	}
+	for i := range blocks {
+		p.Blocks = append(p.Blocks, &blocks[i])
+	}
+	return p
+}
+
+// WireEvents returns the events of the payload
+func (p *WirePayload) WireEvents() []WireEvent {
+	if len(p.Events) == 0 {
+		return nil
+	}
+	events := make([]WireEvent, len(p.Events))
+	for i, m := range p.Events {
+		events[i] = m.ToWireEvent()
+	}
+	return events
+}
+
+// BlockList returns the blocks of the payload
+func (p *WirePayload) BlockList() []Block {
+	if len(p.Blocks) == 0 {
+		return nil
+	}
+	blocks := make([]Block, len(p.Blocks))
+	for i, b := range p.Blocks {
+		blocks[i] = *b
+	}
+	return blocks
+}
+
+func (p *WirePayload) ProtoMarshal() ([]byte, error) {
+	var bf proto.Buffer
+	bf.SetDeterministic(true)
+	if err := bf.Marshal(p); err != nil {
+		return nil, err
+	}
+	return bf.Bytes(), nil
+}
+
+func (p *WirePayload) ProtoUnmarshal(data []byte) error {
+	return proto.Unmarshal(data, p)
+}
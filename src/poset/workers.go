@@ -0,0 +1,60 @@
+package poset
+
+import (
+	"sync"
+)
+
+// SetConsensusWorkers sets the number of goroutines evaluating the
+// strongly-see relations counted by DecideFame, 1 evaluating them in line.
+// DivideRounds stays sequential: the round of an event depends on the ones
+// of its parents.
+func (p *Poset) SetConsensusWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	p.workers = n
+}
+
+// warmStronglySee evaluates in parallel whether the witnesses xs strongly
+// see the witnesses ys, filling the stronglySeeCache. The votes are then
+// counted in order from the cache, so the decisions do not depend on the
+// scheduling of the workers.
+func (p *Poset) warmStronglySee(xs, ys []string) error {
+	type pair struct{ x, y string }
+
+	workers := p.workers
+	if n := len(xs) * len(ys); n < workers {
+		workers = n
+	}
+	jobs := make(chan pair)
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if _, err := p.stronglySee(job.x, job.y); err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+				}
+			}
+		}()
+	}
+	for _, x := range xs {
+		for _, y := range ys {
+			jobs <- pair{x, y}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,198 @@
+// Package profile captures CPU, heap, block and mutex profiles of a running
+// node to files, so that performance incidents can be analysed with pprof
+// without restarting the node.
+package profile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultKeep is the number of profiles kept by default
+const DefaultKeep = 20
+
+// MaxDuration bounds the duration of a capture
+const MaxDuration = 5 * time.Minute
+
+// Kinds of profiles
+const (
+	CPU       = "cpu"
+	Heap      = "heap"
+	Block     = "block"
+	Mutex     = "mutex"
+	Goroutine = "goroutine"
+)
+
+// Profile describes a captured profile file
+type Profile struct {
+	Name string    `json:"name"`
+	Kind string    `json:"kind"`
+	Size int64     `json:"size"`
+	Time time.Time `json:"time"`
+}
+
+// Capturer writes profiles to a directory, keeping the Keep newest ones
+type Capturer struct {
+	dir string
+	// Keep is the number of profiles retained, older ones are deleted
+	Keep int
+
+	busy sync.Mutex
+}
+
+// New returns a capturer writing to dir, created if needed
+func New(dir string, keep int) (*Capturer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if keep < 1 {
+		keep = DefaultKeep
+	}
+	return &Capturer{dir: dir, Keep: keep}, nil
+}
+
+// Dir returns the directory of the profiles
+func (c *Capturer) Dir() string {
+	return c.dir
+}
+
+// Capture records a profile of kind over duration and returns it. Block and
+// mutex profiling are only enabled during the capture. Heap and goroutine
+// profiles are snapshots taken at the end of duration. Only one capture runs
+// at a time.
+func (c *Capturer) Capture(kind string, duration time.Duration) (Profile, error) {
+	if duration < 0 || duration > MaxDuration {
+		return Profile{}, fmt.Errorf("duration must be between 0 and %s, got %s", MaxDuration, duration)
+	}
+	switch kind {
+	case CPU, Heap, Block, Mutex, Goroutine:
+	default:
+		return Profile{}, fmt.Errorf("unknown profile kind %q", kind)
+	}
+	if kind == CPU && duration == 0 {
+		return Profile{}, fmt.Errorf("cpu profiles need a duration")
+	}
+
+	c.busy.Lock()
+	defer c.busy.Unlock()
+
+	start := time.Now()
+	name := fmt.Sprintf("%s-%s.pprof", kind, start.UTC().Format("20060102T150405.000"))
+	path := filepath.Join(c.dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return Profile{}, err
+	}
+	err = capture(f, kind, duration)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return Profile{}, err
+	}
+	if err := c.prune(); err != nil {
+		return Profile{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Profile{}, err
+	}
+	return Profile{Name: name, Kind: kind, Size: info.Size(), Time: start}, nil
+}
+
+func capture(f *os.File, kind string, duration time.Duration) error {
+	switch kind {
+	case CPU:
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		time.Sleep(duration)
+		pprof.StopCPUProfile()
+		return nil
+	case Block:
+		runtime.SetBlockProfileRate(1)
+		defer runtime.SetBlockProfileRate(0)
+	case Mutex:
+		prev := runtime.SetMutexProfileFraction(1)
+		defer runtime.SetMutexProfileFraction(prev)
+	}
+	time.Sleep(duration)
+	if kind == Heap {
+		runtime.GC()
+	}
+	return pprof.Lookup(kind).WriteTo(f, 0)
+}
+
+// List returns the profiles, newest first
+func (c *Capturer) List() ([]Profile, error) {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	var res []Profile
+	var stamps []string
+	for _, info := range files {
+		parts := strings.SplitN(strings.TrimSuffix(info.Name(), ".pprof"), "-", 2)
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".pprof") || len(parts) != 2 {
+			continue
+		}
+		res = append(res, Profile{
+			Name: info.Name(),
+			Kind: parts[0],
+			Size: info.Size(),
+			Time: info.ModTime(),
+		})
+		stamps = append(stamps, parts[1])
+	}
+	sort.Sort(byStamp{res, stamps})
+	return res, nil
+}
+
+// byStamp sorts profiles by the capture time in their names, newest first
+type byStamp struct {
+	profiles []Profile
+	stamps   []string
+}
+
+func (s byStamp) Len() int           { return len(s.profiles) }
+func (s byStamp) Less(i, j int) bool { return s.stamps[i] > s.stamps[j] }
+func (s byStamp) Swap(i, j int) {
+	s.profiles[i], s.profiles[j] = s.profiles[j], s.profiles[i]
+	s.stamps[i], s.stamps[j] = s.stamps[j], s.stamps[i]
+}
+
+// Path returns the path of the profile name, an error if it is not one
+func (c *Capturer) Path(name string) (string, error) {
+	if name != filepath.Base(name) || !strings.HasSuffix(name, ".pprof") {
+		return "", fmt.Errorf("invalid profile name %q", name)
+	}
+	path := filepath.Join(c.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// prune deletes the profiles beyond the Keep newest ones
+func (c *Capturer) prune() error {
+	profiles, err := c.List()
+	if err != nil {
+		return err
+	}
+	for i := c.Keep; i < len(profiles); i++ {
+		if err := os.Remove(filepath.Join(c.dir, profiles[i].Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
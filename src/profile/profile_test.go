@@ -0,0 +1,56 @@
+package profile
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCapture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := New(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Capture("disk", time.Second); err == nil {
+		t.Fatal("unknown kinds should be refused")
+	}
+	if _, err := c.Capture(CPU, 0); err == nil {
+		t.Fatal("cpu profiles without duration should be refused")
+	}
+
+	var names []string
+	for _, kind := range []string{CPU, Heap, Mutex} {
+		p, err := c.Capture(kind, 10*time.Millisecond)
+		if err != nil {
+			t.Fatalf("capturing %s: %s", kind, err)
+		}
+		if p.Kind != kind || p.Size == 0 {
+			t.Fatalf("unexpected %s profile %+v", kind, p)
+		}
+		names = append(names, p.Name)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	// only the 2 newest profiles are kept
+	profiles, err := c.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 || profiles[0].Name != names[2] || profiles[1].Name != names[1] {
+		t.Fatalf("expected profiles %v, got %+v", names[1:], profiles)
+	}
+	if _, err := c.Path(names[0]); err == nil {
+		t.Fatal("the oldest profile should be deleted")
+	}
+	if _, err := c.Path("../" + names[2]); err == nil {
+		t.Fatal("paths outside the directory should be refused")
+	}
+}
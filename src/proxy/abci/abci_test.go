@@ -0,0 +1,205 @@
+package abci
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/Fantom-foundation/go-lachesis/src/common"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// hashApp chains the hashes of its transactions, rejecting empty ones
+type hashApp struct {
+	state   []byte
+	pending []byte
+	height  int64
+}
+
+func (a *hashApp) Info() ResponseInfo {
+	return ResponseInfo{Data: "hash", LastBlockHeight: a.height, LastBlockAppHash: a.state}
+}
+
+func (a *hashApp) CheckTx(tx []byte) ResponseCheckTx {
+	if len(tx) == 0 {
+		return ResponseCheckTx{Code: 1, Log: "empty"}
+	}
+	return ResponseCheckTx{}
+}
+
+func (a *hashApp) BeginBlock(req RequestBeginBlock) {
+	a.height = req.Header.Height
+	a.pending = a.state
+}
+
+func (a *hashApp) DeliverTx(tx []byte) ResponseDeliverTx {
+	if len(tx) == 0 {
+		return ResponseDeliverTx{Code: 1}
+	}
+	h := sha256.Sum256(append(append([]byte{}, a.pending...), tx...))
+	a.pending = h[:]
+	return ResponseDeliverTx{}
+}
+
+func (a *hashApp) EndBlock(height int64) {}
+
+func (a *hashApp) Commit() ResponseCommit {
+	a.state = a.pending
+	return ResponseCommit{Data: a.state}
+}
+
+// serveABCI answers the ABCI socket protocol with app, decoding the fields
+// the socket client sends
+func serveABCI(t *testing.T, l net.Listener, app Application) {
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		size, err := binary.ReadUvarint(r)
+		if err != nil {
+			return
+		}
+		msg := make([]byte, size)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			return
+		}
+		var num protowire.Number
+		var body []byte
+		decodeFields(msg, func(n protowire.Number, _ uint64, b []byte) { num, body = n, b })
+
+		var resp []byte
+		switch num {
+		case reqFlush:
+		case reqInfo:
+			info := app.Info()
+			resp = appendBytesField(resp, 1, []byte(info.Data))
+			resp = appendVarintField(resp, 4, uint64(info.LastBlockHeight))
+			resp = appendBytesField(resp, 5, info.LastBlockAppHash)
+		case reqCheckTx, reqDeliverTx:
+			var tx []byte
+			decodeFields(body, func(n protowire.Number, _ uint64, b []byte) {
+				if n == 1 {
+					tx = b
+				}
+			})
+			if num == reqCheckTx {
+				res := app.CheckTx(tx)
+				resp = appendVarintField(resp, 1, uint64(res.Code))
+				resp = appendBytesField(resp, 3, []byte(res.Log))
+			} else {
+				resp = appendVarintField(resp, 1, uint64(app.DeliverTx(tx).Code))
+			}
+		case reqBeginBlock:
+			var req RequestBeginBlock
+			decodeFields(body, func(n protowire.Number, _ uint64, b []byte) {
+				if n == 2 {
+					decodeFields(b, func(n protowire.Number, v uint64, b []byte) {
+						if n == 3 {
+							req.Header.Height = int64(v)
+						}
+					})
+				}
+			})
+			app.BeginBlock(req)
+		case reqEndBlock:
+			app.EndBlock(0)
+		case reqCommit:
+			resp = appendBytesField(resp, 2, app.Commit().Data)
+		default:
+			t.Errorf("unexpected ABCI request %d", num)
+			return
+		}
+
+		out := appendMessageField(nil, num+1, resp)
+		var prefix [binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(prefix[:], uint64(len(out)))
+		conn.Write(append(prefix[:n], out...))
+	}
+}
+
+func TestSocketProxy(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	app := &hashApp{}
+	go serveABCI(t, l, app)
+
+	client, err := NewSocketClient("tcp://"+l.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewProxy(client, "test", common.NewTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	// CheckTx filters the submitted transactions
+	if err := p.SubmitTx(nil); err == nil {
+		t.Fatal("an empty transaction should be rejected by CheckTx")
+	}
+	go p.SubmitTx([]byte("tx"))
+	select {
+	case tx := <-p.SubmitCh():
+		if string(tx) != "tx" {
+			t.Fatalf("unexpected transaction %q", tx)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the accepted transaction was not submitted")
+	}
+
+	// the state hash of a block is the one of the Commit, a failing
+	// transaction being skipped by the application
+	txs := [][]byte{[]byte("a"), {}, []byte("b")}
+	stateHash, err := p.CommitBlock(poset.NewBlock(0, 1, []byte{}, txs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := &hashApp{}
+	local.BeginBlock(RequestBeginBlock{})
+	for _, tx := range txs {
+		local.DeliverTx(tx)
+	}
+	if expected := local.Commit().Data; !bytes.Equal(stateHash, expected) {
+		t.Fatalf("state hash %X, expected %X", stateHash, expected)
+	}
+	if app.height != 1 {
+		t.Fatalf("block 0 should be delivered at height 1, got %d", app.height)
+	}
+
+	// the next block chains on the state
+	next, err := p.CommitBlock(poset.NewBlock(1, 2, []byte{}, [][]byte{[]byte("c")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(next, stateHash) || app.height != 2 {
+		t.Fatalf("the second block was not applied: %X at height %d", next, app.height)
+	}
+}
+
+func TestLocalProxy(t *testing.T) {
+	app := &hashApp{}
+	p, err := NewProxy(NewLocalClient(app), "test", common.NewTestLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateHash, err := p.CommitBlock(poset.NewBlock(0, 1, []byte{}, [][]byte{[]byte("a")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stateHash, app.state) || len(stateHash) == 0 {
+		t.Fatalf("state hash %X, expected %X", stateHash, app.state)
+	}
+}
@@ -0,0 +1,69 @@
+package abci
+
+import (
+	"sync"
+)
+
+// Client calls an ABCI application, see NewSocketClient and NewLocalClient
+type Client interface {
+	Info() (ResponseInfo, error)
+	CheckTx(tx []byte) (ResponseCheckTx, error)
+	BeginBlock(req RequestBeginBlock) error
+	DeliverTx(tx []byte) (ResponseDeliverTx, error)
+	EndBlock(height int64) error
+	Commit() (ResponseCommit, error)
+	Close() error
+}
+
+// localClient calls an Application of the same process, one call at a time
+type localClient struct {
+	mtx sync.Mutex
+	app Application
+}
+
+// NewLocalClient returns a Client of an in-process Application
+func NewLocalClient(app Application) Client {
+	return &localClient{app: app}
+}
+
+func (c *localClient) Info() (ResponseInfo, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.app.Info(), nil
+}
+
+func (c *localClient) CheckTx(tx []byte) (ResponseCheckTx, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.app.CheckTx(tx), nil
+}
+
+func (c *localClient) BeginBlock(req RequestBeginBlock) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.app.BeginBlock(req)
+	return nil
+}
+
+func (c *localClient) DeliverTx(tx []byte) (ResponseDeliverTx, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.app.DeliverTx(tx), nil
+}
+
+func (c *localClient) EndBlock(height int64) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.app.EndBlock(height)
+	return nil
+}
+
+func (c *localClient) Commit() (ResponseCommit, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.app.Commit(), nil
+}
+
+func (c *localClient) Close() error {
+	return nil
+}
@@ -0,0 +1,159 @@
+package abci
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// Proxy implements the AppProxy interface on top of an ABCI application.
+// Every committed block is delivered as BeginBlock, one DeliverTx per
+// transaction, EndBlock and Commit, the hash returned by Commit becoming the
+// state hash of the block.
+type Proxy struct {
+	client           Client
+	logger           *logrus.Logger
+	chainID          string
+	submitCh         chan []byte
+	submitInternalCh chan poset.InternalTransaction
+	// appHash is the state hash of the last committed block
+	appHash []byte
+}
+
+// NewProxy returns a Proxy of the application behind client, whose chain
+// is named chainID in the block headers
+func NewProxy(client Client, chainID string, logger *logrus.Logger) (*Proxy, error) {
+	if logger == nil {
+		logger = logrus.New()
+		logger.Level = logrus.DebugLevel
+	}
+
+	info, err := client.Info()
+	if err != nil {
+		return nil, fmt.Errorf("ABCI Info: %s", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"data":     info.Data,
+		"version":  info.Version,
+		"height":   info.LastBlockHeight,
+		"app_hash": fmt.Sprintf("%X", info.LastBlockAppHash),
+	}).Info("Connected to ABCI application")
+
+	return &Proxy{
+		client:           client,
+		logger:           logger,
+		chainID:          chainID,
+		submitCh:         make(chan []byte),
+		submitInternalCh: make(chan poset.InternalTransaction),
+		appHash:          info.LastBlockAppHash,
+	}, nil
+}
+
+// SubmitCh implements AppProxy interface method
+func (p *Proxy) SubmitCh() chan []byte {
+	return p.submitCh
+}
+
+// SubmitInternalCh implements AppProxy interface method
+func (p *Proxy) SubmitInternalCh() chan poset.InternalTransaction {
+	return p.submitInternalCh
+}
+
+// CommitBlock implements AppProxy interface method. Heights start at 1,
+// for block 0, and blocks carry no time: the header time is the Unix epoch
+// for all nodes to deliver identical headers.
+func (p *Proxy) CommitBlock(block poset.Block) ([]byte, error) {
+	hash, err := block.BlockHash()
+	if err != nil {
+		return nil, err
+	}
+	height := block.Index() + 1
+	err = p.client.BeginBlock(RequestBeginBlock{
+		Hash: hash,
+		Header: Header{
+			ChainID: p.chainID,
+			Height:  height,
+			Time:    time.Unix(0, 0),
+			AppHash: p.appHash,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ABCI BeginBlock: %s", err)
+	}
+
+	failed := 0
+	for _, tx := range block.Transactions() {
+		res, err := p.client.DeliverTx(tx)
+		if err != nil {
+			return nil, fmt.Errorf("ABCI DeliverTx: %s", err)
+		}
+		if !res.IsOK() {
+			failed++
+			p.logger.WithFields(logrus.Fields{
+				"height": height,
+				"code":   res.Code,
+				"log":    res.Log,
+			}).Debug("ABCI DeliverTx failed")
+		}
+	}
+
+	if err := p.client.EndBlock(height); err != nil {
+		return nil, fmt.Errorf("ABCI EndBlock: %s", err)
+	}
+	res, err := p.client.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("ABCI Commit: %s", err)
+	}
+	p.appHash = res.Data
+
+	p.logger.WithFields(logrus.Fields{
+		"height":     height,
+		"txs":        len(block.Transactions()),
+		"failed":     failed,
+		"state_hash": fmt.Sprintf("%X", res.Data),
+	}).Debug("ABCI Proxy.CommitBlock")
+	return res.Data, nil
+}
+
+// GetSnapshot implements AppProxy interface method. ABCI applications are
+// not asked for snapshots, so a node running one cannot serve fast-forwards.
+func (p *Proxy) GetSnapshot(blockIndex int64) ([]byte, error) {
+	return nil, fmt.Errorf("ABCI applications do not provide snapshots")
+}
+
+// Restore implements AppProxy interface method, see GetSnapshot
+func (p *Proxy) Restore(snapshot []byte) error {
+	return fmt.Errorf("ABCI applications cannot be restored from a snapshot")
+}
+
+// CheckTx implements CheckingAppProxy with the CheckTx of the application
+func (p *Proxy) CheckTx(tx []byte) error {
+	res, err := p.client.CheckTx(tx)
+	if err != nil {
+		return fmt.Errorf("ABCI CheckTx: %s", err)
+	}
+	if !res.IsOK() {
+		return fmt.Errorf("transaction rejected by CheckTx with code %d: %s", res.Code, res.Log)
+	}
+	return nil
+}
+
+// SubmitTx submits a transaction to Lachesis if the CheckTx of the
+// application accepts it
+func (p *Proxy) SubmitTx(tx []byte) error {
+	if err := p.CheckTx(tx); err != nil {
+		return err
+	}
+	t := make([]byte, len(tx))
+	copy(t, tx)
+	p.submitCh <- t
+	return nil
+}
+
+// Close closes the connection to the application
+func (p *Proxy) Close() error {
+	return p.client.Close()
+}
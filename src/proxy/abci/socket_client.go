@@ -0,0 +1,311 @@
+package abci
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// maxMessageSize bounds the ABCI messages read from the application
+const maxMessageSize = 100 << 20
+
+// Field numbers of the oneof of the ABCI Request and Response messages. The
+// response to a request has the number of the request plus one.
+const (
+	reqFlush      protowire.Number = 2
+	reqInfo       protowire.Number = 3
+	reqBeginBlock protowire.Number = 7
+	reqCheckTx    protowire.Number = 8
+	reqDeliverTx  protowire.Number = 9
+	reqEndBlock   protowire.Number = 10
+	reqCommit     protowire.Number = 11
+
+	respException protowire.Number = 1
+)
+
+// socketClient speaks the ABCI socket protocol of Tendermint 0.34: every
+// message is a protobuf Request or Response prefixed by its length as an
+// unsigned varint. Calls are synchronous, each request being followed by a
+// flush.
+type socketClient struct {
+	mtx     sync.Mutex
+	conn    net.Conn
+	r       *bufio.Reader
+	w       *bufio.Writer
+	timeout time.Duration
+}
+
+// NewSocketClient connects to the ABCI application listening on addr,
+// tcp://host:port or unix:///path, a bare host:port meaning tcp. Calls fail
+// when the application does not answer within timeout, 0 for no limit.
+func NewSocketClient(addr string, timeout time.Duration) (Client, error) {
+	network, address := "tcp", addr
+	if i := strings.Index(addr, "://"); i >= 0 {
+		network, address = addr[:i], addr[i+3:]
+	}
+	if network != "tcp" && network != "unix" {
+		return nil, fmt.Errorf("unsupported ABCI address %s, expected tcp:// or unix://", addr)
+	}
+	conn, err := net.DialTimeout(network, address, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the ABCI application: %s", err)
+	}
+	return &socketClient{
+		conn:    conn,
+		r:       bufio.NewReader(conn),
+		w:       bufio.NewWriter(conn),
+		timeout: timeout,
+	}, nil
+}
+
+// call sends a request and returns the body of the matching response
+func (c *socketClient) call(num protowire.Number, req []byte) ([]byte, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.timeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+	if err := c.write(protowire.AppendBytes(protowire.AppendTag(nil, num, protowire.BytesType), req)); err != nil {
+		return nil, err
+	}
+	if err := c.write(protowire.AppendBytes(protowire.AppendTag(nil, reqFlush, protowire.BytesType), nil)); err != nil {
+		return nil, err
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	body, err := c.read(num + 1)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.read(reqFlush + 1); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (c *socketClient) write(msg []byte) error {
+	var size [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(size[:], uint64(len(msg)))
+	if _, err := c.w.Write(size[:n]); err != nil {
+		return err
+	}
+	_, err := c.w.Write(msg)
+	return err
+}
+
+// read reads a response, expected to be of the given oneof field
+func (c *socketClient) read(want protowire.Number) ([]byte, error) {
+	size, err := binary.ReadUvarint(c.r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxMessageSize {
+		return nil, fmt.Errorf("ABCI message of %d bytes exceeds %d", size, maxMessageSize)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(c.r, msg); err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	got := protowire.Number(0)
+	err = decodeFields(msg, func(num protowire.Number, _ uint64, b []byte) {
+		got, body = num, b
+	})
+	if err != nil {
+		return nil, err
+	}
+	if got == respException {
+		var reason string
+		decodeFields(body, func(num protowire.Number, _ uint64, b []byte) {
+			if num == 1 {
+				reason = string(b)
+			}
+		})
+		return nil, fmt.Errorf("ABCI application exception: %s", reason)
+	}
+	if got != want {
+		return nil, fmt.Errorf("unexpected ABCI response %d, expected %d", got, want)
+	}
+	return body, nil
+}
+
+func (c *socketClient) Info() (ResponseInfo, error) {
+	var info ResponseInfo
+	req := protowire.AppendTag(nil, 1, protowire.BytesType)
+	req = protowire.AppendString(req, "lachesis")
+	body, err := c.call(reqInfo, req)
+	if err != nil {
+		return info, err
+	}
+	err = decodeFields(body, func(num protowire.Number, v uint64, b []byte) {
+		switch num {
+		case 1:
+			info.Data = string(b)
+		case 2:
+			info.Version = string(b)
+		case 3:
+			info.AppVersion = v
+		case 4:
+			info.LastBlockHeight = int64(v)
+		case 5:
+			info.LastBlockAppHash = b
+		}
+	})
+	return info, err
+}
+
+func (c *socketClient) CheckTx(tx []byte) (ResponseCheckTx, error) {
+	var resp ResponseCheckTx
+	body, err := c.call(reqCheckTx, appendBytesField(nil, 1, tx))
+	if err != nil {
+		return resp, err
+	}
+	err = decodeTxResult(body, &resp.Code, &resp.Data, &resp.Log, &resp.Info, &resp.GasWanted, &resp.GasUsed, &resp.Codespace)
+	return resp, err
+}
+
+func (c *socketClient) BeginBlock(req RequestBeginBlock) error {
+	var header []byte
+	header = appendBytesField(header, 2, []byte(req.Header.ChainID))
+	header = appendVarintField(header, 3, uint64(req.Header.Height))
+	var ts []byte
+	ts = appendVarintField(ts, 1, uint64(req.Header.Time.Unix()))
+	ts = appendVarintField(ts, 2, uint64(req.Header.Time.Nanosecond()))
+	header = appendMessageField(header, 4, ts)
+	header = appendBytesField(header, 11, req.Header.AppHash)
+
+	var msg []byte
+	msg = appendBytesField(msg, 1, req.Hash)
+	msg = appendMessageField(msg, 2, header)
+	_, err := c.call(reqBeginBlock, msg)
+	return err
+}
+
+func (c *socketClient) DeliverTx(tx []byte) (ResponseDeliverTx, error) {
+	var resp ResponseDeliverTx
+	body, err := c.call(reqDeliverTx, appendBytesField(nil, 1, tx))
+	if err != nil {
+		return resp, err
+	}
+	err = decodeTxResult(body, &resp.Code, &resp.Data, &resp.Log, &resp.Info, &resp.GasWanted, &resp.GasUsed, &resp.Codespace)
+	return resp, err
+}
+
+func (c *socketClient) EndBlock(height int64) error {
+	_, err := c.call(reqEndBlock, appendVarintField(nil, 1, uint64(height)))
+	return err
+}
+
+func (c *socketClient) Commit() (ResponseCommit, error) {
+	var resp ResponseCommit
+	body, err := c.call(reqCommit, nil)
+	if err != nil {
+		return resp, err
+	}
+	err = decodeFields(body, func(num protowire.Number, v uint64, b []byte) {
+		switch num {
+		case 2:
+			resp.Data = b
+		case 3:
+			resp.RetainHeight = int64(v)
+		}
+	})
+	return resp, err
+}
+
+func (c *socketClient) Close() error {
+	return c.conn.Close()
+}
+
+// decodeTxResult decodes a ResponseCheckTx or a ResponseDeliverTx, whose
+// fields have the same numbers
+func decodeTxResult(body []byte, code *uint32, data *[]byte, log, info *string, gasWanted, gasUsed *int64, codespace *string) error {
+	return decodeFields(body, func(num protowire.Number, v uint64, b []byte) {
+		switch num {
+		case 1:
+			*code = uint32(v)
+		case 2:
+			*data = b
+		case 3:
+			*log = string(b)
+		case 4:
+			*info = string(b)
+		case 5:
+			*gasWanted = int64(v)
+		case 6:
+			*gasUsed = int64(v)
+		case 8:
+			*codespace = string(b)
+		}
+	})
+}
+
+// decodeFields calls field with the value of every varint field, or the
+// content of every length-delimited one, skipping the other types
+func decodeFields(msg []byte, field func(num protowire.Number, v uint64, b []byte)) error {
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		msg = msg[n:]
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(msg)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			field(num, v, nil)
+			msg = msg[n:]
+		case protowire.BytesType:
+			b, n := protowire.ConsumeBytes(msg)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			field(num, 0, b)
+			msg = msg[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, msg)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			msg = msg[n:]
+		}
+	}
+	return nil
+}
+
+// appendBytesField appends a bytes or string field, omitted when empty as
+// proto3 does
+func appendBytesField(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	return appendMessageField(b, num, v)
+}
+
+// appendMessageField appends an embedded message, even empty
+func appendMessageField(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// appendVarintField appends an integer field, omitted when zero
+func appendVarintField(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
@@ -0,0 +1,84 @@
+package abci
+
+import (
+	"time"
+)
+
+// CodeTypeOK is the code of an accepted transaction
+const CodeTypeOK uint32 = 0
+
+// Application is the interface of a Tendermint-style ABCI application. The
+// Client of an application running in another process, or in the same one,
+// drives it through these calls.
+type Application interface {
+	Info() ResponseInfo
+	CheckTx(tx []byte) ResponseCheckTx
+	BeginBlock(req RequestBeginBlock)
+	DeliverTx(tx []byte) ResponseDeliverTx
+	EndBlock(height int64)
+	Commit() ResponseCommit
+}
+
+// Header is the part of the Tendermint block header Lachesis fills
+type Header struct {
+	ChainID string
+	Height  int64
+	Time    time.Time
+	// AppHash is the state hash returned by the Commit of the previous block
+	AppHash []byte
+}
+
+// RequestBeginBlock opens the delivery of a block
+type RequestBeginBlock struct {
+	Hash   []byte
+	Header Header
+}
+
+// ResponseInfo describes the state of the application
+type ResponseInfo struct {
+	Data             string
+	Version          string
+	AppVersion       uint64
+	LastBlockHeight  int64
+	LastBlockAppHash []byte
+}
+
+// ResponseCheckTx is the verdict of the application on a submitted
+// transaction, which is dropped unless its code is CodeTypeOK
+type ResponseCheckTx struct {
+	Code      uint32
+	Data      []byte
+	Log       string
+	Info      string
+	GasWanted int64
+	GasUsed   int64
+	Codespace string
+}
+
+// IsOK tells whether the transaction was accepted
+func (r ResponseCheckTx) IsOK() bool {
+	return r.Code == CodeTypeOK
+}
+
+// ResponseDeliverTx is the result of a transaction of a block. A
+// transaction failing still belongs to the block.
+type ResponseDeliverTx struct {
+	Code      uint32
+	Data      []byte
+	Log       string
+	Info      string
+	GasWanted int64
+	GasUsed   int64
+	Codespace string
+}
+
+// IsOK tells whether the transaction was applied
+func (r ResponseDeliverTx) IsOK() bool {
+	return r.Code == CodeTypeOK
+}
+
+// ResponseCommit carries the state hash of the application after a block
+type ResponseCommit struct {
+	Data         []byte
+	RetainHeight int64
+}
@@ -18,8 +18,10 @@ import (
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
 	"github.com/Fantom-foundation/go-lachesis/src/poset"
 	"github.com/Fantom-foundation/go-lachesis/src/proxy/internal"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy/proto"
 )
 
 var ErrNoAnswers = errors.New("no answers")
@@ -37,8 +39,9 @@ type GrpcAppProxy struct {
 	askings      map[xid.ID]chan *internal.ToServer_Answer
 	askings_sync sync.RWMutex
 
-	event4server  chan []byte
-	event4clients chan *internal.ToClient
+	event4server    chan []byte
+	expiring4server chan proto.ExpiringTx
+	event4clients   chan *internal.ToClient
 }
 
 // NewGrpcAppProxy instantiates a joined AppProxy-interface listen to remote apps
@@ -55,9 +58,10 @@ func NewGrpcAppProxy(bind_addr string, timeout time.Duration, logger *logrus.Log
 		timeout:     timeout,
 		new_clients: make(chan ClientStream, 100),
 		// TODO: make chans buffered?
-		askings:       make(map[xid.ID]chan *internal.ToServer_Answer),
-		event4server:  make(chan []byte),
-		event4clients: make(chan *internal.ToClient),
+		askings:         make(map[xid.ID]chan *internal.ToServer_Answer),
+		event4server:    make(chan []byte),
+		expiring4server: make(chan proto.ExpiringTx),
+		event4clients:   make(chan *internal.ToClient),
 	}
 
 	p.listener, err = net.Listen("tcp", bind_addr)
@@ -80,6 +84,7 @@ func (p *GrpcAppProxy) Close() error {
 	p.server.Stop()
 	p.listener.Close()
 	close(p.event4server)
+	close(p.expiring4server)
 	close(p.event4clients)
 	return nil
 }
@@ -105,6 +110,13 @@ func (p *GrpcAppProxy) Connect(stream internal.LachesisNode_ConnectServer) error
 			return err
 		}
 		if tx := req.GetTx(); tx != nil {
+			if expiry := tx.GetExpiry(); expiry != 0 {
+				p.expiring4server <- proto.ExpiringTx{
+					Data:   tx.GetData(),
+					Expiry: time.Unix(0, expiry),
+				}
+				continue
+			}
 			p.event4server <- tx.GetData()
 			continue
 		}
@@ -152,6 +164,11 @@ func (p *GrpcAppProxy) SubmitCh() chan []byte {
 	return p.event4server
 }
 
+// SubmitExpiringCh implements ExpiringAppProxy
+func (p *GrpcAppProxy) SubmitExpiringCh() chan proto.ExpiringTx {
+	return p.expiring4server
+}
+
 // SubmitCh implements AppProxy interface method
 // TODO: Incorrect implementation, just adding to the interface so long
 func (p *GrpcAppProxy) SubmitInternalCh() chan poset.InternalTransaction {
@@ -170,7 +187,7 @@ func (p *GrpcAppProxy) CommitBlock(block poset.Block) ([]byte, error) {
 	}
 	err_msg := answer.GetError()
 	if err_msg != "" {
-		return nil, errors.New(err_msg)
+		return nil, lerrors.Parse(err_msg)
 	}
 	return answer.GetData(), nil
 }
@@ -183,22 +200,29 @@ func (p *GrpcAppProxy) GetSnapshot(blockIndex int64) ([]byte, error) {
 	}
 	err_msg := answer.GetError()
 	if err_msg != "" {
-		return nil, errors.New(err_msg)
+		return nil, lerrors.Parse(err_msg)
 	}
 	return answer.GetData(), nil
 }
 
 // Restore implements AppProxy interface method
 func (p *GrpcAppProxy) Restore(snapshot []byte) error {
+	_, err := p.RestoreState(snapshot)
+	return err
+}
+
+// RestoreState implements RestoringAppProxy, the application answering the
+// restore with its state hash
+func (p *GrpcAppProxy) RestoreState(snapshot []byte) ([]byte, error) {
 	answer, ok := <-p.push_restore(snapshot)
 	if !ok {
-		return ErrNoAnswers
+		return nil, ErrNoAnswers
 	}
 	err_msg := answer.GetError()
 	if err_msg != "" {
-		return errors.New(err_msg)
+		return nil, lerrors.Parse(err_msg)
 	}
-	return nil
+	return answer.GetData(), nil
 }
 
 /*
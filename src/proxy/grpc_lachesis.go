@@ -12,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
 	"github.com/Fantom-foundation/go-lachesis/src/poset"
 	"github.com/Fantom-foundation/go-lachesis/src/proxy/internal"
 	"github.com/Fantom-foundation/go-lachesis/src/proxy/proto"
@@ -109,6 +110,20 @@ func (p *GrpcLachesisProxy) SubmitTx(tx []byte) error {
 	return err
 }
 
+// SubmitTxWithExpiry submits a transaction which the node drops if it is
+// not added to an event before expiry
+func (p *GrpcLachesisProxy) SubmitTxWithExpiry(tx []byte, expiry time.Time) error {
+	r := &internal.ToServer{
+		Event: &internal.ToServer_Tx_{
+			Tx: &internal.ToServer_Tx{
+				Data:   tx,
+				Expiry: expiry.UnixNano(),
+			},
+		},
+	}
+	return p.sendToServer(r)
+}
+
 /*
  * network:
  */
@@ -293,7 +308,7 @@ func newAnswer(uuid []byte, data []byte, err error) *internal.ToServer {
 				Answer: &internal.ToServer_Answer{
 					Uid: uuid,
 					Payload: &internal.ToServer_Answer_Error{
-						Error: err.Error(),
+						Error: lerrors.Format(err),
 					},
 				},
 			},
@@ -44,6 +44,23 @@ func TestGrpcCalls(t *testing.T) {
 		}
 	})
 
+	t.Run("#1 Send tx with expiry", func(t *testing.T) {
+		asserter := assert.New(t)
+		gold := []byte("123456")
+		expiry := time.Unix(0, time.Now().Add(time.Minute).UnixNano())
+
+		err = c.SubmitTxWithExpiry(gold, expiry)
+		asserter.NoError(err)
+
+		select {
+		case tx := <-s.SubmitExpiringCh():
+			asserter.Equal(gold, tx.Data)
+			asserter.True(expiry.Equal(tx.Expiry))
+		case <-time.After(timeout):
+			asserter.Fail(errTimeout)
+		}
+	})
+
 	t.Run("#2 Receive block", func(t *testing.T) {
 		asserter := assert.New(t)
 		block := poset.Block{}
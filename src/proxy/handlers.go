@@ -1,6 +1,9 @@
 package proxy
 
 import (
+	"sync"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
 	"github.com/Fantom-foundation/go-lachesis/src/poset"
 )
 
@@ -13,16 +16,120 @@ application.
 // commiting, retreiving and restoring state and transactions, to and from 
 // the DAG
 type ProxyHandler interface {
+	CommitHandler
+	SnapshotHandler
+	RestoreHandler
+}
+
+// CommitHandler applies the committed blocks to the state of the application
+type CommitHandler interface {
 	//CommitHandler is called when Lachesis commits a block to the DAG. It
 	//returns the state hash resulting from applying the block's transactions to the
 	//state
 	CommitHandler(block poset.Block) (stateHash []byte, err error)
+}
 
+// SnapshotHandler provides the snapshots of the state of the application
+type SnapshotHandler interface {
 	//SnapshotHandler is called by Lachesis to retrieve a snapshot corresponding to a
 	//particular block
 	SnapshotHandler(blockIndex int64) (snapshot []byte, err error)
+}
 
+// RestoreHandler resets the state of the application to a snapshot
+type RestoreHandler interface {
 	//RestoreHandler is called by Lachesis to restore the application to a specific
 	//state
 	RestoreHandler(snapshot []byte) (stateHash []byte, err error)
 }
+
+// TxCheckHandler is optionally implemented by a ProxyHandler to reject the
+// invalid transactions before they reach the transaction pool
+type TxCheckHandler interface {
+	//CheckTxHandler is called by Lachesis before it accepts a transaction.
+	//It returns an error when the transaction is invalid
+	CheckTxHandler(tx []byte) error
+}
+
+// StateHashHandler is optionally implemented by a ProxyHandler to report the
+// current state hash of the application
+type StateHashHandler interface {
+	//StateHashHandler is called by Lachesis at startup to check the state of
+	//the application against the genesis
+	StateHashHandler() (stateHash []byte, err error)
+}
+
+// DefaultHandler is the ProxyHandler of an application without state: it
+// only chains the hashes of the committed transactions into the state hash,
+// which is also its snapshot. The zero value is ready to use.
+type DefaultHandler struct {
+	sync.Mutex
+	stateHash []byte
+}
+
+// CommitHandler implements ProxyHandler
+func (h *DefaultHandler) CommitHandler(block poset.Block) ([]byte, error) {
+	h.Lock()
+	defer h.Unlock()
+	for _, tx := range block.Transactions() {
+		h.stateHash = crypto.SimpleHashFromTwoHashes(h.stateHash, crypto.SHA256(tx))
+	}
+	return h.stateHash, nil
+}
+
+// SnapshotHandler implements ProxyHandler
+func (h *DefaultHandler) SnapshotHandler(blockIndex int64) ([]byte, error) {
+	h.Lock()
+	defer h.Unlock()
+	return h.stateHash, nil
+}
+
+// RestoreHandler implements ProxyHandler
+func (h *DefaultHandler) RestoreHandler(snapshot []byte) ([]byte, error) {
+	h.Lock()
+	defer h.Unlock()
+	h.stateHash = snapshot
+	return h.stateHash, nil
+}
+
+// StateHashHandler implements StateHashHandler
+func (h *DefaultHandler) StateHashHandler() ([]byte, error) {
+	h.Lock()
+	defer h.Unlock()
+	return h.stateHash, nil
+}
+
+// HandlerFuncs is a ProxyHandler calling functions, so that an application
+// can plug its callbacks without defining a type. The nil ones behave as
+// the DefaultHandler.
+type HandlerFuncs struct {
+	Commit   func(block poset.Block) (stateHash []byte, err error)
+	Snapshot func(blockIndex int64) (snapshot []byte, err error)
+	Restore  func(snapshot []byte) (stateHash []byte, err error)
+
+	def DefaultHandler
+}
+
+// CommitHandler implements ProxyHandler
+func (f *HandlerFuncs) CommitHandler(block poset.Block) ([]byte, error) {
+	if f.Commit != nil {
+		return f.Commit(block)
+	}
+	return f.def.CommitHandler(block)
+}
+
+// SnapshotHandler implements ProxyHandler
+func (f *HandlerFuncs) SnapshotHandler(blockIndex int64) ([]byte, error) {
+	if f.Snapshot != nil {
+		return f.Snapshot(blockIndex)
+	}
+	return f.def.SnapshotHandler(blockIndex)
+}
+
+// RestoreHandler implements ProxyHandler
+func (f *HandlerFuncs) RestoreHandler(snapshot []byte) ([]byte, error) {
+	if f.Restore != nil {
+		return f.Restore(snapshot)
+	}
+	return f.def.RestoreHandler(snapshot)
+}
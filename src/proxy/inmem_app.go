@@ -1,10 +1,14 @@
 package proxy
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/Fantom-foundation/go-lachesis/src/peers"
 	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/proxy/proto"
 )
 
 // InmemAppProxy implements the AppProxy interface natively
@@ -13,20 +17,28 @@ type InmemAppProxy struct {
 	handler          ProxyHandler
 	submitCh         chan []byte
 	submitInternalCh chan poset.InternalTransaction
+	submitExpiringCh chan proto.ExpiringTx
+	submitCheckedCh  chan proto.CheckedTx
 }
 
-// NewInmemAppProxy instantiates an InmemProxy from a set of handlers
+// NewInmemAppProxy instantiates an InmemProxy from a set of handlers, see
+// HandlerFuncs; a nil handler is a DefaultHandler
 func NewInmemAppProxy(handler ProxyHandler, logger *logrus.Logger) *InmemAppProxy {
 	if logger == nil {
 		logger = logrus.New()
 		logger.Level = logrus.DebugLevel
 	}
+	if handler == nil {
+		handler = &DefaultHandler{}
+	}
 
 	return &InmemAppProxy{
 		logger:           logger,
 		handler:          handler,
 		submitCh:         make(chan []byte),
 		submitInternalCh: make(chan poset.InternalTransaction),
+		submitExpiringCh: make(chan proto.ExpiringTx),
+		submitCheckedCh:  make(chan proto.CheckedTx),
 	}
 }
 
@@ -45,6 +57,16 @@ func (p *InmemAppProxy) ProposePeerRemove(peer peers.Peer) {
 	p.submitInternalCh <- poset.NewInternalTransaction(poset.TransactionType_PEER_REMOVE, peer)
 }
 
+// SubmitExpiringCh implements ExpiringAppProxy
+func (p *InmemAppProxy) SubmitExpiringCh() chan proto.ExpiringTx {
+	return p.submitExpiringCh
+}
+
+// SubmitCheckedCh implements CheckedAppProxy
+func (p *InmemAppProxy) SubmitCheckedCh() chan proto.CheckedTx {
+	return p.submitCheckedCh
+}
+
 //SubmitCh returns the channel of raw transactions
 func (p *InmemAppProxy) SubmitInternalCh() chan poset.InternalTransaction {
 	return p.submitInternalCh
@@ -75,12 +97,36 @@ func (p *InmemAppProxy) GetSnapshot(blockIndex int64) ([]byte, error) {
 
 // Restore implements AppProxy interface method, calls handler
 func (p *InmemAppProxy) Restore(snapshot []byte) error {
+	_, err := p.RestoreState(snapshot)
+	return err
+}
+
+// RestoreState implements RestoringAppProxy, calls handler
+func (p *InmemAppProxy) RestoreState(snapshot []byte) ([]byte, error) {
 	stateHash, err := p.handler.RestoreHandler(snapshot)
 	p.logger.WithFields(logrus.Fields{
 		"state_hash": stateHash,
 		"err":        err,
 	}).Debug("InmemAppProxy.Restore")
-	return err
+	return stateHash, err
+}
+
+// CheckTx implements CheckingAppProxy, calling the handler when it is a
+// TxCheckHandler
+func (p *InmemAppProxy) CheckTx(tx []byte) error {
+	if h, ok := p.handler.(TxCheckHandler); ok {
+		return h.CheckTxHandler(tx)
+	}
+	return nil
+}
+
+// StateHash implements StateAppProxy, calling the handler when it is a
+// StateHashHandler
+func (p *InmemAppProxy) StateHash() ([]byte, error) {
+	if h, ok := p.handler.(StateHashHandler); ok {
+		return h.StateHashHandler()
+	}
+	return nil, fmt.Errorf("the application does not report its state hash")
 }
 
 /*
@@ -95,3 +141,22 @@ func (p *InmemAppProxy) SubmitTx(tx []byte) {
 	copy(t, tx)
 	p.submitCh <- t
 }
+
+// TrySubmitTx submits a transaction and waits for the node to add it to its
+// pool. It returns the error of the node when it refuses it: a MempoolFull
+// or TxTooLarge error when the application should back off.
+func (p *InmemAppProxy) TrySubmitTx(tx []byte) error {
+	t := make([]byte, len(tx), len(tx))
+	copy(t, tx)
+	result := make(chan error, 1)
+	p.submitCheckedCh <- proto.CheckedTx{Data: t, Result: result}
+	return <-result
+}
+
+// SubmitTxWithExpiry submits a transaction which is dropped if it is not
+// added to an event before expiry
+func (p *InmemAppProxy) SubmitTxWithExpiry(tx []byte, expiry time.Time) {
+	t := make([]byte, len(tx), len(tx))
+	copy(t, tx)
+	p.submitExpiringCh <- proto.ExpiringTx{Data: t, Expiry: expiry}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/Fantom-foundation/go-lachesis/src/common"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
 	"github.com/Fantom-foundation/go-lachesis/src/poset"
 )
 
@@ -43,6 +44,22 @@ func TestInmemAppCalls(t *testing.T) {
 		proxy.SubmitTx(tx_origin)
 	})
 
+	t.Run("#1b Try submit tx", func(t *testing.T) {
+		asserter := assert.New(t)
+
+		go func() {
+			select {
+			case tx := <-proxy.SubmitCheckedCh():
+				tx.Result <- lerrors.New(lerrors.MempoolFull, "pool full")
+			case <-time.After(timeout):
+				asserter.Fail(errTimeout)
+			}
+		}()
+
+		err := proxy.TrySubmitTx([]byte("the refused transaction"))
+		asserter.True(lerrors.Is(err, lerrors.MempoolFull))
+	})
+
 	t.Run("#2 Commit block", func(t *testing.T) {
 		asserter := assert.New(t)
 
@@ -114,3 +131,39 @@ func goldStateHash() []byte {
 func goldSnapshot() []byte {
 	return []byte("snapshot")
 }
+
+func TestInmemAppHandlerFuncs(t *testing.T) {
+	asserter := assert.New(t)
+	logger := common.NewTestLogger(t)
+	block := poset.NewBlock(0, 1, []byte{}, [][]byte{[]byte("tx 1")})
+
+	// a nil handler only chains the transactions into the state hash
+	def := NewInmemAppProxy(nil, logger)
+	stateHash, err := def.CommitBlock(block)
+	if asserter.NoError(err) {
+		asserter.NotEmpty(stateHash)
+	}
+	snapshot, err := def.GetSnapshot(block.Index())
+	if asserter.NoError(err) {
+		asserter.Equal(stateHash, snapshot)
+	}
+
+	var committed [][]byte
+	funcs := NewInmemAppProxy(&HandlerFuncs{
+		Commit: func(block poset.Block) ([]byte, error) {
+			committed = append(committed, block.Transactions()...)
+			return goldStateHash(), nil
+		},
+	}, logger)
+	stateHash, err = funcs.CommitBlock(block)
+	if asserter.NoError(err) {
+		asserter.Equal(goldStateHash(), stateHash)
+		asserter.EqualValues(block.Transactions(), committed)
+	}
+	// the callbacks left nil behave as the default handler
+	asserter.NoError(funcs.Restore(goldSnapshot()))
+	snapshot, err = funcs.GetSnapshot(block.Index())
+	if asserter.NoError(err) {
+		asserter.Equal(goldSnapshot(), snapshot)
+	}
+}
@@ -170,6 +170,7 @@ func _ToServer_OneofSizer(msg proto.Message) (n int) {
 
 type ToServer_Tx struct {
 	Data                 []byte   `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Expiry               int64    `protobuf:"varint,2,opt,name=expiry,proto3" json:"expiry,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -207,6 +208,13 @@ func (m *ToServer_Tx) GetData() []byte {
 	return nil
 }
 
+func (m *ToServer_Tx) GetExpiry() int64 {
+	if m != nil {
+		return m.Expiry
+	}
+	return 0
+}
+
 type ToServer_Answer struct {
 	Uid []byte `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`
 	// Types that are valid to be assigned to Payload:
@@ -686,29 +694,30 @@ func init() {
 func init() { proto.RegisterFile("grpc.proto", fileDescriptor_bedfbfc9b54e5600) }
 
 var fileDescriptor_bedfbfc9b54e5600 = []byte{
-	// 351 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0xc1, 0x6e, 0xe2, 0x30,
-	0x10, 0x86, 0x93, 0xb0, 0x21, 0x30, 0x70, 0x58, 0x8d, 0x76, 0x57, 0xd9, 0x9c, 0x10, 0x97, 0x72,
-	0x69, 0x40, 0x20, 0xb5, 0xe7, 0xc2, 0xa1, 0x39, 0x54, 0x95, 0x1a, 0x78, 0x01, 0x93, 0x8c, 0xda,
-	0xa8, 0x91, 0x4d, 0x1d, 0x43, 0xc3, 0xdb, 0xf5, 0x51, 0xfa, 0x28, 0x95, 0x1d, 0x53, 0x21, 0x91,
-	0x43, 0x6f, 0xb6, 0xe6, 0xfb, 0xf3, 0xcf, 0xff, 0xc7, 0x00, 0xcf, 0x72, 0x97, 0xc5, 0x3b, 0x29,
-	0x94, 0xc0, 0x5e, 0xc1, 0x15, 0x49, 0xce, 0xca, 0xf1, 0xa7, 0x0b, 0xbd, 0x8d, 0x58, 0x93, 0x3c,
-	0x90, 0xc4, 0x2b, 0xf0, 0x54, 0x1d, 0xba, 0x23, 0x77, 0x32, 0x98, 0xff, 0x8d, 0x4f, 0x4c, 0x7c,
-	0x9a, 0xc7, 0x9b, 0x3a, 0x71, 0x52, 0x4f, 0xd5, 0xb8, 0x80, 0x2e, 0xe3, 0xd5, 0x3b, 0xc9, 0xd0,
-	0x33, 0xf0, 0xff, 0x16, 0xf8, 0xce, 0x00, 0x89, 0x93, 0x5a, 0x34, 0x0a, 0xc1, 0xdb, 0xd4, 0x88,
-	0xf0, 0x2b, 0x67, 0x8a, 0x19, 0x97, 0x61, 0x6a, 0xce, 0xd1, 0x1a, 0xba, 0x0d, 0x8d, 0xbf, 0xa1,
-	0xb3, 0x2f, 0x72, 0x3b, 0xd4, 0x47, 0xfc, 0x63, 0x79, 0x6d, 0x34, 0x4c, 0x9c, 0x46, 0x81, 0xff,
-	0xc0, 0x27, 0x29, 0x85, 0x0c, 0x3b, 0x23, 0x77, 0xd2, 0x4f, 0x9c, 0xb4, 0xb9, 0x2e, 0xfb, 0x10,
-	0xec, 0xd8, 0xb1, 0x14, 0x2c, 0x5f, 0x06, 0xe0, 0xd3, 0x81, 0xb8, 0x1a, 0x7f, 0x78, 0x3a, 0xe2,
-	0xaa, 0x2c, 0x88, 0x2b, 0x9c, 0x81, 0xbf, 0x2d, 0x45, 0xf6, 0x6a, 0x53, 0x86, 0xe7, 0x8b, 0x37,
-	0x48, 0xbc, 0xd4, 0x73, 0xfd, 0x49, 0x03, 0x6a, 0xc5, 0xdb, 0x9e, 0xe4, 0xd1, 0x46, 0x6d, 0x53,
-	0x3c, 0xe9, 0xb9, 0x56, 0x18, 0x10, 0x6f, 0x20, 0x90, 0x54, 0x29, 0x21, 0xc9, 0xac, 0x37, 0x98,
-	0x47, 0x2d, 0x9a, 0xb4, 0x21, 0x12, 0x27, 0x3d, 0xc1, 0xd1, 0x35, 0xf8, 0xc6, 0xbb, 0xa5, 0x05,
-	0x3c, 0x6f, 0xc1, 0xb6, 0x36, 0x05, 0xdf, 0x18, 0xb7, 0x96, 0xe6, 0x17, 0x3c, 0xa7, 0xda, 0xf0,
-	0x9d, 0xb4, 0xb9, 0x44, 0x53, 0x08, 0xac, 0xeb, 0xcf, 0x1c, 0xbe, 0x2b, 0x9c, 0xdf, 0xc3, 0xf0,
-	0x81, 0x65, 0x2f, 0x54, 0x15, 0xd5, 0xa3, 0xc8, 0x09, 0x6f, 0x21, 0x58, 0x09, 0xce, 0x29, 0x53,
-	0x88, 0x97, 0xbf, 0x3e, 0xc2, 0xcb, 0xbc, 0x63, 0x67, 0xe2, 0xce, 0xdc, 0x6d, 0xd7, 0xbc, 0xbf,
-	0xc5, 0x57, 0x00, 0x00, 0x00, 0xff, 0xff, 0x54, 0x51, 0x85, 0x10, 0x8d, 0x02, 0x00, 0x00,
+	// 356 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0x41, 0x6f, 0xe2, 0x30,
+	0x10, 0x85, 0x93, 0xb0, 0x49, 0x60, 0xe0, 0xb0, 0x1a, 0xed, 0xae, 0xb2, 0x39, 0x21, 0x2e, 0xcb,
+	0x65, 0x03, 0x02, 0xa9, 0x3d, 0x17, 0x0e, 0xcd, 0xa1, 0xaa, 0xd4, 0xc0, 0x1f, 0x08, 0xc9, 0xa8,
+	0x8d, 0x1a, 0xd9, 0xa9, 0x63, 0x68, 0xf8, 0x77, 0xfd, 0x59, 0x3d, 0x56, 0x76, 0x0c, 0x42, 0x22,
+	0x87, 0xde, 0x6c, 0xcd, 0xf7, 0xde, 0xf3, 0x3c, 0x19, 0xe0, 0x59, 0x54, 0x59, 0x54, 0x09, 0x2e,
+	0x39, 0xf6, 0x0b, 0x26, 0x49, 0xb0, 0xb4, 0x9c, 0x7c, 0xda, 0xd0, 0xdf, 0xf2, 0x0d, 0x89, 0x03,
+	0x09, 0xfc, 0x07, 0x8e, 0x6c, 0x02, 0x7b, 0x6c, 0x4f, 0x87, 0x8b, 0xdf, 0xd1, 0x89, 0x89, 0x4e,
+	0xf3, 0x68, 0xdb, 0xc4, 0x56, 0xe2, 0xc8, 0x06, 0x97, 0xe0, 0xa5, 0xac, 0x7e, 0x27, 0x11, 0x38,
+	0x1a, 0xfe, 0xdb, 0x01, 0xdf, 0x69, 0x20, 0xb6, 0x12, 0x83, 0x86, 0x73, 0x70, 0xb6, 0x0d, 0x22,
+	0xfc, 0xc8, 0x53, 0x99, 0xea, 0x94, 0x51, 0xa2, 0xcf, 0xf8, 0x07, 0x3c, 0x6a, 0xaa, 0x42, 0x1c,
+	0xb5, 0x5d, 0x2f, 0x31, 0xb7, 0x70, 0x03, 0x5e, 0xeb, 0x82, 0x3f, 0xa1, 0xb7, 0x2f, 0x72, 0x23,
+	0x52, 0x47, 0xfc, 0x65, 0x7c, 0x94, 0x62, 0x14, 0x5b, 0x67, 0x27, 0x97, 0x84, 0xe0, 0x22, 0xe8,
+	0x8d, 0xed, 0xe9, 0x20, 0xb6, 0x92, 0xf6, 0xba, 0x1a, 0x80, 0x5f, 0xa5, 0xc7, 0x92, 0xa7, 0xf9,
+	0xca, 0x07, 0x97, 0x0e, 0xc4, 0xe4, 0xe4, 0xc3, 0x51, 0xab, 0xaf, 0xcb, 0x82, 0x98, 0xc4, 0x39,
+	0xb8, 0xbb, 0x92, 0x67, 0xaf, 0x66, 0xfb, 0xe0, 0x72, 0xa1, 0x16, 0x89, 0x56, 0x6a, 0xae, 0x2c,
+	0x35, 0xa8, 0x14, 0x6f, 0x7b, 0x32, 0x6f, 0xee, 0x56, 0x3c, 0xa9, 0xb9, 0x52, 0x68, 0x10, 0x6f,
+	0xc0, 0x17, 0x54, 0x4b, 0x2e, 0x48, 0x3f, 0x6f, 0xb8, 0x08, 0x3b, 0x34, 0x49, 0x4b, 0xc4, 0x56,
+	0x72, 0x82, 0xc3, 0xff, 0xe0, 0xea, 0xec, 0x8e, 0x16, 0xf0, 0xb2, 0x85, 0xb6, 0x83, 0x70, 0x06,
+	0xae, 0x0e, 0xee, 0x2c, 0xcd, 0x2d, 0x58, 0x4e, 0x8d, 0xe9, 0xb9, 0xbd, 0x84, 0x33, 0xf0, 0x4d,
+	0xea, 0xf7, 0x12, 0xce, 0x15, 0x2e, 0xee, 0x61, 0xf4, 0x90, 0x66, 0x2f, 0x54, 0x17, 0xf5, 0x23,
+	0xcf, 0x09, 0x6f, 0xc1, 0x5f, 0x73, 0xc6, 0x28, 0x93, 0x88, 0xd7, 0x5f, 0x22, 0xc4, 0xeb, 0x7d,
+	0x27, 0xd6, 0xd4, 0x9e, 0xdb, 0x3b, 0x4f, 0xff, 0xcb, 0xe5, 0xd7, 0x00, 0x6a, 0xe9, 0x9d, 0xca,
+	0xa5, 0x02, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1,6 +1,10 @@
 package proto
 
-import "github.com/Fantom-foundation/go-lachesis/src/poset"
+import (
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
 
 type StateHash struct {
 	Hash []byte
@@ -52,3 +56,17 @@ type RestoreRequest struct {
 func (r *RestoreRequest) Respond(snapshot []byte, err error) {
 	r.RespChan <- RestoreResponse{snapshot, err}
 }
+//------------------------------------------------------------------------------
+// ExpiringTx is a transaction which is dropped if it is still waiting in the
+// transaction pool at Expiry
+type ExpiringTx struct {
+	Data   []byte
+	Expiry time.Time
+}
+//------------------------------------------------------------------------------
+// CheckedTx is a transaction whose submitter waits on Result for the node to
+// accept it, nil, or to refuse it, e.g. with a MempoolFull error
+type CheckedTx struct {
+	Data   []byte
+	Result chan<- error
+}
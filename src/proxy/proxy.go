@@ -15,6 +15,40 @@ type AppProxy interface {
 	Restore(snapshot []byte) error
 }
 
+// ExpiringAppProxy is implemented by the AppProxies whose application can
+// submit transactions with an expiry
+type ExpiringAppProxy interface {
+	SubmitExpiringCh() chan proto.ExpiringTx
+}
+
+// CheckedAppProxy is implemented by the AppProxies which report to the
+// application whether the node accepted each transaction
+type CheckedAppProxy interface {
+	SubmitCheckedCh() chan proto.CheckedTx
+}
+
+// CheckingAppProxy is implemented by the AppProxies whose application
+// validates transactions before the node adds them to its pool
+type CheckingAppProxy interface {
+	// CheckTx returns an error when the application rejects tx
+	CheckTx(tx []byte) error
+}
+
+// RestoringAppProxy is implemented by the AppProxies which report the state
+// hash of the application restored from a snapshot
+type RestoringAppProxy interface {
+	// RestoreState restores the application like Restore and returns its
+	// state hash
+	RestoreState(snapshot []byte) ([]byte, error)
+}
+
+// StateAppProxy is implemented by the AppProxies which report the current
+// state hash of the application
+type StateAppProxy interface {
+	// StateHash returns the state hash of the application
+	StateHash() ([]byte, error)
+}
+
 // LachesisProxy provides an interface for the application to
 // submit transactions to the lachesis node.
 type LachesisProxy interface {
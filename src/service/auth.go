@@ -0,0 +1,227 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+)
+
+// By default the service answers every request, with CORS headers allowing
+// any origin. SetCORSOrigins restricts the origins browsers may call it
+// from, SetAuth makes the requests changing the node authenticate, and
+// SetAdminAddress serves the admin endpoints on an address of their own, the
+// address of the service only serving the read-only ones.
+
+// Authentication schemes of SetAuth
+const (
+	AuthNone   = "none"
+	AuthBearer = "bearer"
+	AuthHMAC   = "hmac"
+)
+
+// hmacMaxSkew is the largest difference between the timestamp of an HMAC
+// authenticated request and the clock of the node, bounding replays
+const hmacMaxSkew = 5 * time.Minute
+
+// adminPaths are the endpoints administering the node, which the service
+// only answers on the admin address when it has one
+var adminPaths = []string{
+	"/bans", "/connections", "/maintenance", "/settings", "/params", "/chaos", "/profiles",
+}
+
+// SetCORSOrigins sets the origins of the browsers allowed to call the
+// service, "*" allowing any, none disabling the CORS headers. It must be
+// called before Serve.
+func (s *Service) SetCORSOrigins(origins []string) {
+	s.corsOrigins = origins
+}
+
+// SetAuth makes the requests changing the node, every method but GET, HEAD
+// and OPTIONS except the read-only JSON-RPC queries, authenticate with
+// scheme: AuthBearer, an "Authorization: Bearer <secret>" header, or
+// AuthHMAC, an "Authorization: HMAC <timestamp>:<signature>" header, see
+// HMACAuthorization. It must be called before Serve.
+func (s *Service) SetAuth(scheme, secret string) {
+	s.authScheme = scheme
+	s.authSecret = secret
+}
+
+// SetAdminAddress serves the admin endpoints and the requests changing the
+// node on addr, the bind address of the service refusing them. It must be
+// called before Serve.
+func (s *Service) SetAdminAddress(addr string) {
+	s.adminAddress = addr
+}
+
+// HMACAuthorization returns the Authorization header of a request
+// authenticated with AuthHMAC: the hex HMAC-SHA256, keyed with the secret,
+// of the method, the request URI, the unix timestamp and the body, separated
+// by newlines
+func HMACAuthorization(secret, method, uri string, timestamp int64, body []byte) string {
+	ts := strconv.FormatInt(timestamp, 10)
+	return "HMAC " + ts + ":" + hex.EncodeToString(hmacSignature(secret, method, uri, ts, body))
+}
+
+func hmacSignature(secret, method, uri, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n", method, uri, timestamp)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// handler serves mux with the CORS headers. The public address of a service
+// with an admin address refuses the admin and changing requests, and the
+// changing requests which do not authenticate are refused everywhere.
+func (s *Service) handler(mux http.Handler, public bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.setCORSHeaders(w, r)
+		if r.Method == http.MethodOptions {
+			return
+		}
+		path := apiPath(r.URL.Path)
+		changing := isChanging(r.Method, path)
+		if public && (changing || isAdminPath(path)) {
+			http.Error(w, "served on the admin address of the service", http.StatusForbidden)
+			return
+		}
+		if changing {
+			if err := s.authenticate(r); err != nil {
+				metrics.IncrCounter("service.auth.failures", 1)
+				s.logger.WithFields(logrus.Fields{
+					"path":   r.URL.Path,
+					"remote": r.RemoteAddr,
+					"error":  err,
+				}).Warn("Refused unauthenticated request")
+				w.Header().Set("WWW-Authenticate", s.authScheme)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// setCORSHeaders allows the origin of the request, when it is one of the
+// CORS origins, to read the response
+func (s *Service) setCORSHeaders(w http.ResponseWriter, r *http.Request) {
+	origin := s.allowedOrigin(r.Header.Get("Origin"))
+	if origin == "" {
+		return
+	}
+	if origin != "*" {
+		w.Header().Add("Vary", "Origin")
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers",
+		"Accept, Content-Type, Content-Length, Accept-Encoding, Authorization")
+}
+
+// allowedOrigin returns the Access-Control-Allow-Origin of origin, empty
+// when it is not allowed
+func (s *Service) allowedOrigin(origin string) string {
+	for _, o := range s.corsOrigins {
+		if o == "*" {
+			return "*"
+		}
+		if origin != "" && o == origin {
+			return origin
+		}
+	}
+	return ""
+}
+
+// checkWSOrigin allows the WebSocket connections of the CORS origins, and of
+// the clients which are not browsers
+func (s *Service) checkWSOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || s.allowedOrigin(origin) != ""
+}
+
+// authenticate checks the Authorization header of a changing request
+func (s *Service) authenticate(r *http.Request) error {
+	header := r.Header.Get("Authorization")
+	switch s.authScheme {
+	case AuthBearer:
+		token := strings.TrimPrefix(header, "Bearer ")
+		if token == header || subtle.ConstantTimeCompare([]byte(token), []byte(s.authSecret)) != 1 {
+			return fmt.Errorf("invalid bearer token")
+		}
+	case AuthHMAC:
+		if !strings.HasPrefix(header, "HMAC ") {
+			return fmt.Errorf("missing HMAC authorization")
+		}
+		parts := strings.SplitN(strings.TrimPrefix(header, "HMAC "), ":", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("malformed HMAC authorization")
+		}
+		timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("malformed HMAC timestamp")
+		}
+		skew := time.Since(time.Unix(timestamp, 0))
+		if skew > hmacMaxSkew || skew < -hmacMaxSkew {
+			return fmt.Errorf("HMAC timestamp more than %s off", hmacMaxSkew)
+		}
+		signature, err := hex.DecodeString(parts[1])
+		if err != nil {
+			return fmt.Errorf("malformed HMAC signature")
+		}
+		// the body is signed too, and read again by the handler
+		body, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, maxTxBody))
+		if err != nil {
+			return err
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		expected := hmacSignature(s.authSecret, r.Method, r.URL.RequestURI(), parts[0], body)
+		if !hmac.Equal(signature, expected) {
+			return fmt.Errorf("invalid HMAC signature")
+		}
+	}
+	return nil
+}
+
+// apiPath returns the path of the node API of a request, without the
+// /chains/<id> prefix of the additional chains
+func apiPath(path string) string {
+	if !strings.HasPrefix(path, "/chains/") {
+		return path
+	}
+	rest := strings.TrimPrefix(path, "/chains/")
+	if i := strings.Index(rest, "/"); i >= 0 {
+		return rest[i:]
+	}
+	return path
+}
+
+// isAdminPath tells whether path is one of the admin endpoints
+func isAdminPath(path string) bool {
+	for _, p := range adminPaths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isChanging tells whether a request may change the node: the JSON-RPC
+// queries are POSTed but only read
+func isChanging(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return path != "/rpc"
+}
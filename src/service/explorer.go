@@ -0,0 +1,94 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+const (
+	// defaultPageLimit is the number of blocks of a page without a limit
+	// parameter
+	defaultPageLimit = 20
+	// maxPageLimit bounds the number of blocks of a page
+	maxPageLimit = 100
+)
+
+// BlockPage is the body of a GET /blocks response. Next is the from
+// parameter of the next page, -1 when the page ends at the last block.
+type BlockPage struct {
+	Blocks []poset.Block
+	Next   int64
+}
+
+// Head is the body of a GET /head response
+type Head struct {
+	LastBlockIndex     int64
+	LastRound          int64
+	LastConsensusRound *int64
+}
+
+// GetBlocks returns a block (GET /blocks/<index>) or a page of consecutive
+// blocks (GET /blocks?from=<index>&limit=<count>), from block 0 and
+// defaultPageLimit blocks by default
+func (s *Service) GetBlocks(w http.ResponseWriter, r *http.Request) {
+	if len(r.URL.Path) > len("/blocks/") {
+		s.serveBlock(w, r.URL.Path[len("/blocks/"):])
+		return
+	}
+
+	from, err := queryInt(r, "from", 0)
+	if err != nil || from < 0 {
+		http.Error(w, fmt.Sprintf("invalid from parameter %q", r.URL.Query().Get("from")), http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(r, "limit", defaultPageLimit)
+	if err != nil || limit < 1 {
+		http.Error(w, fmt.Sprintf("invalid limit parameter %q", r.URL.Query().Get("limit")), http.StatusBadRequest)
+		return
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	last := s.node.GetLastBlockIndex()
+	page := BlockPage{Blocks: []poset.Block{}, Next: -1}
+	for i := from; i <= last && i < from+limit; i++ {
+		block, err := s.node.GetBlock(i)
+		if err != nil {
+			s.logger.WithError(err).Errorf("Retrieving block %d", i)
+			http.Error(w, err.Error(), errorStatus(err))
+			return
+		}
+		page.Blocks = append(page.Blocks, block)
+	}
+	if from+limit <= last {
+		page.Next = from + limit
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// GetHead returns the last block and rounds of the node, where explorers
+// stop indexing
+func (s *Service) GetHead(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Head{
+		LastBlockIndex:     s.node.GetLastBlockIndex(),
+		LastRound:          s.node.GetLastRound(),
+		LastConsensusRound: s.node.GetLastConsensusRound(),
+	})
+}
+
+// queryInt parses an integer query parameter, def when it is absent
+func queryInt(r *http.Request, name string, def int64) (int64, error) {
+	param := r.URL.Query().Get(name)
+	if param == "" {
+		return def, nil
+	}
+	return strconv.ParseInt(param, 10, 64)
+}
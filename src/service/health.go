@@ -0,0 +1,28 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// GetHealth answers 200 when the node runs without failure, 503 with the
+// reasons otherwise (GET /healthz), for liveness probes
+func (s *Service) GetHealth(w http.ResponseWriter, r *http.Request) {
+	health := s.node.GetHealth()
+	w.Header().Set("Content-Type", "application/json")
+	if !health.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(health)
+}
+
+// GetReadiness answers 200 when the node takes part in the consensus, 503
+// with the reasons otherwise (GET /readyz), for readiness probes
+func (s *Service) GetReadiness(w http.ResponseWriter, r *http.Request) {
+	readiness := s.node.GetReadiness()
+	w.Header().Set("Content-Type", "application/json")
+	if !readiness.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(readiness)
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/node"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/version"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000
+)
+
+const (
+	// maxRPCBody bounds the size of a JSON-RPC request
+	maxRPCBody = 1 << 20
+	// maxBlockHashes bounds the cache of the block indexes by hash
+	maxBlockHashes = 10000
+)
+
+// zeroHash is the parent hash of the first block
+var zeroHash = "0x" + strings.Repeat("0", 64)
+
+// The JSON-RPC endpoint answers a subset of the Ethereum queries, with the
+// quantities in hex, so that block explorers and dashboards can read the
+// chain of a node:
+//
+//	web3_clientVersion
+//	eth_blockNumber
+//	eth_getBlockByNumber(number|"earliest"|"latest", full)
+//	eth_getBlockByHash(hash, full)
+//	eth_getBlockTransactionCountByNumber(number|"earliest"|"latest")
+//	eth_getBlockTransactionCountByHash(hash)
+//
+// The hash of a block is the hash of its body, which unlike the hash of the
+// block does not change as the validators sign it.
+
+// RPCBlock is a block in the format of eth_getBlockByNumber. Transactions are
+// RPCTransactions when the full transactions are asked for, their hashes
+// otherwise. Timestamp is the consensus time of the block in unix seconds.
+type RPCBlock struct {
+	Number        string        `json:"number"`
+	Hash          string        `json:"hash"`
+	ParentHash    string        `json:"parentHash"`
+	StateRoot     string        `json:"stateRoot"`
+	FrameHash     string        `json:"frameHash"`
+	RoundReceived string        `json:"roundReceived"`
+	Timestamp     string        `json:"timestamp"`
+	Signatures    int           `json:"signatures"`
+	Transactions  []interface{} `json:"transactions"`
+}
+
+// RPCTransaction is a transaction of an RPCBlock
+type RPCTransaction struct {
+	Hash             string `json:"hash"`
+	BlockHash        string `json:"blockHash"`
+	BlockNumber      string `json:"blockNumber"`
+	TransactionIndex string `json:"transactionIndex"`
+	Input            string `json:"input"`
+}
+
+type rpcRequest struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func rpcErrorf(code int, format string, args ...interface{}) *rpcError {
+	return &rpcError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// EnableJSONRPC enables the /rpc JSON-RPC endpoint. It must be called before
+// Serve.
+func (s *Service) EnableJSONRPC() {
+	s.jsonRPC = true
+}
+
+// JSONRPC answers the JSON-RPC 2.0 requests, single or batched, POSTed to
+// /rpc
+func (s *Service) JSONRPC(w http.ResponseWriter, r *http.Request) {
+	if !s.jsonRPC {
+		http.Error(w, "JSON-RPC is disabled, see --jsonrpc", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "JSON-RPC requests are POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRPCBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '[' {
+		json.NewEncoder(w).Encode(s.rpcCall(body))
+		return
+	}
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		json.NewEncoder(w).Encode(rpcResponse{JSONRPC: "2.0", Error: rpcErrorf(rpcParseError, "%s", err)})
+		return
+	}
+	if len(batch) == 0 {
+		json.NewEncoder(w).Encode(rpcResponse{JSONRPC: "2.0", Error: rpcErrorf(rpcInvalidRequest, "empty batch")})
+		return
+	}
+	responses := make([]rpcResponse, len(batch))
+	for i, raw := range batch {
+		responses[i] = s.rpcCall(raw)
+	}
+	json.NewEncoder(w).Encode(responses)
+}
+
+// rpcCall answers a single request
+func (s *Service) rpcCall(raw []byte) rpcResponse {
+	res := rpcResponse{JSONRPC: "2.0"}
+	var req rpcRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		res.Error = rpcErrorf(rpcParseError, "%s", err)
+		return res
+	}
+	res.ID = req.ID
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		res.Error = rpcErrorf(rpcInvalidRequest, "not a JSON-RPC 2.0 request")
+		return res
+	}
+	result, rpcErr := s.rpcDispatch(req.Method, req.Params)
+	if rpcErr != nil {
+		res.Error = rpcErr
+		return res
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		res.Error = rpcErrorf(rpcServerError, "%s", err)
+		return res
+	}
+	res.Result = data
+	return res
+}
+
+// rpcDispatch runs a method, a nil result being a JSON null
+func (s *Service) rpcDispatch(method string, params []json.RawMessage) (interface{}, *rpcError) {
+	switch method {
+	case "web3_clientVersion":
+		return "lachesis/v" + version.Version, nil
+	case "eth_blockNumber":
+		last := s.node.GetLastBlockIndex()
+		if last < 0 {
+			last = 0
+		}
+		return hexInt(last), nil
+	case "eth_getBlockByNumber", "eth_getBlockTransactionCountByNumber":
+		if len(params) < 1 {
+			return nil, rpcErrorf(rpcInvalidParams, "%s needs a block number", method)
+		}
+		index, err := s.rpcBlockNumber(params[0])
+		if err != nil {
+			return nil, rpcErrorf(rpcInvalidParams, "%s", err)
+		}
+		block, err := s.node.GetBlock(index)
+		if err != nil {
+			return nil, nil
+		}
+		return s.rpcBlockResult(method, block, params)
+	case "eth_getBlockByHash", "eth_getBlockTransactionCountByHash":
+		var hash string
+		if len(params) < 1 || json.Unmarshal(params[0], &hash) != nil {
+			return nil, rpcErrorf(rpcInvalidParams, "%s needs a block hash", method)
+		}
+		block, ok := s.blockByHash(hash)
+		if !ok {
+			return nil, nil
+		}
+		return s.rpcBlockResult(method, block, params)
+	default:
+		return nil, rpcErrorf(rpcMethodNotFound, "method %s not found", method)
+	}
+}
+
+// rpcBlockResult returns the block, or its number of transactions, asked for
+// by method
+func (s *Service) rpcBlockResult(method string, block poset.Block, params []json.RawMessage) (interface{}, *rpcError) {
+	if strings.HasPrefix(method, "eth_getBlockTransactionCount") {
+		return hexInt(int64(len(block.Transactions()))), nil
+	}
+	var full bool
+	if len(params) > 1 {
+		if err := json.Unmarshal(params[1], &full); err != nil {
+			return nil, rpcErrorf(rpcInvalidParams, "invalid full transactions flag: %s", err)
+		}
+	}
+	res, err := s.rpcBlock(block, full)
+	if err != nil {
+		return nil, rpcErrorf(rpcServerError, "%s", err)
+	}
+	return res, nil
+}
+
+// rpcBlockNumber parses a block number: a hex quantity, "earliest", or
+// "latest" for the last block, like "pending", "safe" and "finalized" as the
+// blocks are final
+func (s *Service) rpcBlockNumber(param json.RawMessage) (int64, error) {
+	var tag string
+	if err := json.Unmarshal(param, &tag); err != nil {
+		var index int64
+		if err := json.Unmarshal(param, &index); err != nil {
+			return 0, fmt.Errorf("invalid block number %s", param)
+		}
+		return index, nil
+	}
+	switch tag {
+	case "earliest":
+		return 0, nil
+	case "latest", "pending", "safe", "finalized":
+		return s.node.GetLastBlockIndex(), nil
+	}
+	if !strings.HasPrefix(tag, "0x") {
+		return 0, fmt.Errorf("invalid block number %q", tag)
+	}
+	index, err := strconv.ParseInt(tag[2:], 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block number %q", tag)
+	}
+	return index, nil
+}
+
+// rpcBlock converts a block to the format of eth_getBlockByNumber
+func (s *Service) rpcBlock(block poset.Block, full bool) (*RPCBlock, error) {
+	hash, err := blockHash(block)
+	if err != nil {
+		return nil, err
+	}
+	parent := zeroHash
+	if block.Index() > 0 {
+		if prev, err := s.node.GetBlock(block.Index() - 1); err == nil {
+			if parent, err = blockHash(prev); err != nil {
+				return nil, err
+			}
+		}
+	}
+	res := &RPCBlock{
+		Number:        hexInt(block.Index()),
+		Hash:          hash,
+		ParentHash:    parent,
+		StateRoot:     hexBytes(block.StateHash),
+		FrameHash:     hexBytes(block.FrameHash),
+		RoundReceived: hexInt(block.RoundReceived()),
+		Timestamp:     hexInt(block.Timestamp() / int64(time.Second)),
+		Signatures:    len(block.Signatures),
+		Transactions:  make([]interface{}, len(block.Transactions())),
+	}
+	for i, tx := range block.Transactions() {
+		txHash := strings.ToLower(node.TxHash(tx))
+		if !full {
+			res.Transactions[i] = txHash
+			continue
+		}
+		res.Transactions[i] = RPCTransaction{
+			Hash:             txHash,
+			BlockHash:        hash,
+			BlockNumber:      res.Number,
+			TransactionIndex: hexInt(int64(i)),
+			Input:            hexBytes(tx),
+		}
+	}
+	return res, nil
+}
+
+// blockByHash returns the block whose body hash is hash, reading back the
+// blocks from the last one to find it. The indexes of the blocks read are
+// cached.
+func (s *Service) blockByHash(hash string) (poset.Block, bool) {
+	hash = strings.ToLower(hash)
+	s.blockHashesLock.Lock()
+	defer s.blockHashesLock.Unlock()
+	if index, ok := s.blockHashes[hash]; ok {
+		if block, err := s.node.GetBlock(index); err == nil {
+			return block, true
+		}
+	}
+	if s.blockHashes == nil || len(s.blockHashes) > maxBlockHashes {
+		s.blockHashes = make(map[string]int64)
+	}
+	for index := s.node.GetLastBlockIndex(); index >= 0; index-- {
+		block, err := s.node.GetBlock(index)
+		if err != nil {
+			// the blocks before are pruned
+			break
+		}
+		h, err := blockHash(block)
+		if err != nil {
+			continue
+		}
+		s.blockHashes[h] = index
+		if h == hash {
+			return block, true
+		}
+	}
+	return poset.Block{}, false
+}
+
+// blockHash returns the hash of the body of a block in lower case hex
+func blockHash(block poset.Block) (string, error) {
+	hash, err := block.Body.Hash()
+	if err != nil {
+		return "", err
+	}
+	return hexBytes(hash), nil
+}
+
+func hexInt(i int64) string {
+	return "0x" + strconv.FormatInt(i, 16)
+}
+
+func hexBytes(b []byte) string {
+	return fmt.Sprintf("0x%x", b)
+}
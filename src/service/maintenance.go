@@ -0,0 +1,98 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// defaultDrainTimeout is the time POST /maintenance/drain waits for the
+// blocks without a timeout parameter
+const defaultDrainTimeout = 30 * time.Second
+
+// Maintenance returns the maintenance state of the node (GET /maintenance),
+// pauses its gossip (POST /maintenance/pause), resumes it (POST
+// /maintenance/resume), waits until the application acknowledged the
+// decided blocks (POST /maintenance/drain?timeout=10s) or garbage collects
+// the value log of the store (POST /maintenance/compact?discard_ratio=0.5)
+func (s *Service) Maintenance(w http.ResponseWriter, r *http.Request) {
+	action := strings.Trim(strings.TrimPrefix(r.URL.Path, "/maintenance"), "/")
+	switch {
+	case r.Method == http.MethodGet && action == "":
+	case r.Method != http.MethodPost:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	case action == "pause":
+		s.node.Pause()
+	case action == "resume":
+		s.node.Resume()
+	case action == "drain":
+		timeout := defaultDrainTimeout
+		if param := r.URL.Query().Get("timeout"); param != "" {
+			var err error
+			if timeout, err = time.ParseDuration(param); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if err := s.node.DrainCommits(timeout); err != nil {
+			http.Error(w, err.Error(), http.StatusGatewayTimeout)
+			return
+		}
+	case action == "compact":
+		ratio := poset.DefaultGCDiscardRatio
+		if param := r.URL.Query().Get("discard_ratio"); param != "" {
+			var err error
+			if ratio, err = strconv.ParseFloat(param, 64); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		stats, err := s.node.CompactStore(ratio)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stats)
+		return
+	default:
+		http.Error(w, "unknown maintenance action "+action, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.node.GetMaintenance())
+}
+
+// Settings returns the settings which can be changed at runtime (GET
+// /settings) or changes them (POST /settings with a JSON object of names
+// and values, e.g. {"heartbeat": "50ms", "log-level": "info"})
+func (s *Service) Settings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.node.Reload(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.logger.WithFields(logrus.Fields{
+			"settings": req,
+		}).Info("Settings reloaded")
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.node.GetSettings())
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// ParamsResponse is the body of a GET /params response
+type ParamsResponse struct {
+	Params  map[string]string
+	Pending []poset.ParamChange
+}
+
+// Params returns the network parameters and the committed changes waiting
+// for their activation round (GET /params), or proposes a change (POST
+// /params with a poset.ParamChange body)
+func (s *Service) Params(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ParamsResponse{
+			Params:  s.node.GetParams(),
+			Pending: s.node.GetPendingParamChanges(),
+		})
+	case http.MethodPost:
+		var req poset.ParamChange
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.node.ProposeParamChange(req.Name, req.Value, req.ActivationRound); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.logger.WithFields(logrus.Fields{
+			"param":      req.Name,
+			"value":      req.Value,
+			"activation": req.ActivationRound,
+		}).Info("Parameter change proposed")
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/profile"
+)
+
+// ProfileRequest is the body of a POST /profiles request. Duration is
+// parsed with time.ParseDuration.
+type ProfileRequest struct {
+	Kind     string
+	Duration string
+}
+
+// SetProfiler enables the /profiles endpoints, capturing with p. It must be
+// called before Serve.
+func (s *Service) SetProfiler(p *profile.Capturer) {
+	s.profiler = p
+}
+
+// Profiles lists (GET /profiles), captures (POST /profiles) and downloads
+// (GET /profiles/<name>) runtime profiles. A capture answers once it is
+// complete. It is only available when the node runs with profiling enabled.
+func (s *Service) Profiles(w http.ResponseWriter, r *http.Request) {
+	if s.profiler == nil {
+		http.Error(w, "profiling is disabled", http.StatusForbidden)
+		return
+	}
+
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/profiles"), "/")
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		profiles, err := s.profiler.List()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(profiles)
+	case r.Method == http.MethodGet:
+		path, err := s.profiler.Path(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		http.ServeFile(w, r, path)
+	case r.Method == http.MethodPost && name == "":
+		var req ProfileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var duration time.Duration
+		if req.Duration != "" {
+			var err error
+			if duration, err = time.ParseDuration(req.Duration); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		p, err := s.profiler.Capture(req.Kind, duration)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.logger.WithFields(logrus.Fields{
+			"kind":     p.Kind,
+			"duration": duration,
+			"name":     p.Name,
+		}).Info("Profile captured")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
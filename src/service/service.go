@@ -1,11 +1,24 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
-
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/chaos"
+	lerrors "github.com/Fantom-foundation/go-lachesis/src/errors"
+	"github.com/Fantom-foundation/go-lachesis/src/log"
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
 	"github.com/Fantom-foundation/go-lachesis/src/node"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/Fantom-foundation/go-lachesis/src/profile"
 	"github.com/sirupsen/logrus"
 )
 
@@ -13,7 +26,27 @@ type Service struct {
 	bindAddress string
 	node        *node.Node
 	graph       *node.Graph
-	logger      *logrus.Logger
+	logger      *logrus.Entry
+	chains      map[string]*Service
+	profiler    *profile.Capturer
+	// peerSetHash is the hash of the signed peers.json of the node
+	peerSetHash string
+	// jsonRPC enables the /rpc endpoint, see EnableJSONRPC
+	jsonRPC bool
+	// blockHashes are the indexes of the blocks by body hash read by
+	// blockByHash
+	blockHashes     map[string]int64
+	blockHashesLock sync.Mutex
+	// corsOrigins, authScheme, authSecret and adminAddress control the
+	// access to the service, see auth.go
+	corsOrigins  []string
+	authScheme   string
+	authSecret   string
+	adminAddress string
+
+	server      *http.Server
+	adminServer *http.Server
+	serverLock  sync.Mutex
 }
 
 func NewService(bindAddress string, n *node.Node, logger *logrus.Logger) *Service {
@@ -21,54 +54,144 @@ func NewService(bindAddress string, n *node.Node, logger *logrus.Logger) *Servic
 		bindAddress: bindAddress,
 		node:        n,
 		graph:       node.NewGraph(n),
-		logger:      logger,
+		logger:      logger.WithField(lachesis_log.ModuleField, "service"),
+		corsOrigins: []string{"*"},
 	}
 
 	return &service
 }
 
+// AddChain serves the API of the node of an additional chain under
+// /chains/<id>/. It must be called before Serve.
+func (s *Service) AddChain(id string, n *node.Node) {
+	if s.chains == nil {
+		s.chains = make(map[string]*Service)
+	}
+	s.chains[id] = &Service{
+		node:   n,
+		graph:  node.NewGraph(n),
+		logger: s.logger,
+	}
+}
+
+// route registers the handlers of the node API on mux
+func (s *Service) route(mux *http.ServeMux) {
+	mux.HandleFunc("/stats", s.GetStats)
+	mux.HandleFunc("/stats/signed", s.GetSignedStats)
+	mux.HandleFunc("/participants", s.GetParticipants)
+	mux.HandleFunc("/participants/", s.GetParticipants)
+	mux.HandleFunc("/peers", s.GetPeers)
+	mux.HandleFunc("/peerset", s.GetPeerSet)
+	mux.HandleFunc("/liveness", s.GetLiveness)
+	mux.HandleFunc("/bans", s.Bans)
+	mux.HandleFunc("/bans/", s.Bans)
+	mux.HandleFunc("/connections", s.Connections)
+	mux.HandleFunc("/connections/", s.Connections)
+	mux.HandleFunc("/memory", s.GetMemory)
+	mux.HandleFunc("/healthz", s.GetHealth)
+	mux.HandleFunc("/readyz", s.GetReadiness)
+	mux.HandleFunc("/params", s.Params)
+	mux.HandleFunc("/maintenance", s.Maintenance)
+	mux.HandleFunc("/maintenance/", s.Maintenance)
+	mux.HandleFunc("/settings", s.Settings)
+	mux.HandleFunc("/event/", s.GetEvent)
+	mux.HandleFunc("/lasteventfrom/", s.GetLastEventFrom)
+	mux.HandleFunc("/events/", s.GetKnownEvents)
+	mux.HandleFunc("/consensusevents/", s.GetConsensusEvents)
+	mux.HandleFunc("/round/", s.GetRound)
+	mux.HandleFunc("/lastround/", s.GetLastRound)
+	mux.HandleFunc("/roundwitnesses/", s.GetRoundWitnesses)
+	mux.HandleFunc("/roundevents/", s.GetRoundEvents)
+	mux.HandleFunc("/root/", s.GetRoot)
+	mux.HandleFunc("/block/", s.GetBlock)
+	mux.HandleFunc("/blocks", s.GetBlocks)
+	mux.HandleFunc("/blocks/", s.GetBlocks)
+	mux.HandleFunc("/head", s.GetHead)
+	mux.HandleFunc("/txstatus/", s.GetTxStatus)
+	mux.HandleFunc("/proof/tx/", s.GetTxProof)
+	mux.HandleFunc("/tx", s.SubmitTx)
+	mux.HandleFunc("/tx/", s.GetTx)
+	mux.HandleFunc("/ws", s.WebSocket)
+	mux.HandleFunc("/frame/", s.GetFrame)
+	mux.HandleFunc("/checkpoint/", s.GetCheckpoint)
+	mux.HandleFunc("/graph", s.GetGraph)
+	mux.HandleFunc("/dag", s.GetDAG)
+	mux.HandleFunc("/rpc", s.JSONRPC)
+}
+
 func (s *Service) Serve() {
 	s.logger.WithField("bind_address", s.bindAddress).Debug("Service serving")
 	mux := http.NewServeMux()
-	mux.Handle("/stats", corsHandler(s.GetStats))
-	mux.Handle("/participants/", corsHandler(s.GetParticipants))
-	mux.Handle("/event/", corsHandler(s.GetEvent))
-	mux.Handle("/lasteventfrom/", corsHandler(s.GetLastEventFrom))
-	mux.Handle("/events/", corsHandler(s.GetKnownEvents))
-	mux.Handle("/consensusevents/", corsHandler(s.GetConsensusEvents))
-	mux.Handle("/round/", corsHandler(s.GetRound))
-	mux.Handle("/lastround/", corsHandler(s.GetLastRound))
-	mux.Handle("/roundwitnesses/", corsHandler(s.GetRoundWitnesses))
-	mux.Handle("/roundevents/", corsHandler(s.GetRoundEvents))
-	mux.Handle("/root/", corsHandler(s.GetRoot))
-	mux.Handle("/block/", corsHandler(s.GetBlock))
-	mux.Handle("/graph", corsHandler(s.GetGraph))
+	s.route(mux)
+	mux.HandleFunc("/chaos", s.Chaos)
+	mux.HandleFunc("/chaos/", s.Chaos)
+	mux.HandleFunc("/profiles", s.Profiles)
+	mux.HandleFunc("/profiles/", s.Profiles)
+	mux.HandleFunc("/chains", s.GetChains)
+	for id, chain := range s.chains {
+		chainMux := http.NewServeMux()
+		chain.jsonRPC = s.jsonRPC
+		chain.corsOrigins = s.corsOrigins
+		chain.route(chainMux)
+		prefix := "/chains/" + id
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, chainMux))
+	}
+	for path, h := range metrics.Handlers(metrics.Global()) {
+		mux.Handle(path, h)
+	}
 	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("src/service/static/"))))
-	err := http.ListenAndServe(s.bindAddress, mux)
-	if err != nil {
+	server := &http.Server{Addr: s.bindAddress, Handler: s.handler(mux, s.adminAddress != "")}
+	var admin *http.Server
+	if s.adminAddress != "" {
+		admin = &http.Server{Addr: s.adminAddress, Handler: s.handler(mux, false)}
+	}
+	s.serverLock.Lock()
+	s.server = server
+	s.adminServer = admin
+	s.serverLock.Unlock()
+	if admin != nil {
+		s.logger.WithField("admin_address", s.adminAddress).Debug("Service serving admin endpoints")
+		go func() {
+			err := admin.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				s.logger.WithField("error", err).Error("Admin service failed")
+			}
+		}()
+	}
+	err := server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
 		s.logger.WithField("error", err).Error("Service failed")
 	}
 }
 
-func corsHandler(h http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers",
-			"Accept, Content-Type, Content-Length, Accept-Encoding, Authorization")
-		if r.Method == "OPTIONS" {
-			/*w.Header().Set("Access-Control-Allow-Origin", "*")
-			    	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-						w.Header().Set("Access-Control-Allow-Headers",
-			        "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization")*/
-		} else {
-			/*w.Header().Set("Access-Control-Allow-Origin", "*")
-			    	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-						w.Header().Set("Access-Control-Allow-Headers",
-			        "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization")*/
-			h.ServeHTTP(w, r)
+// Close stops serving, waiting up to timeout for the pending requests
+func (s *Service) Close(timeout time.Duration) error {
+	s.serverLock.Lock()
+	server, admin := s.server, s.adminServer
+	s.serverLock.Unlock()
+	if server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if admin != nil {
+		if err := admin.Shutdown(ctx); err != nil {
+			return err
 		}
 	}
+	return server.Shutdown(ctx)
+}
+
+// GetChains lists the additional chains hosted by the node
+func (s *Service) GetChains(w http.ResponseWriter, r *http.Request) {
+	ids := make([]string, 0, len(s.chains))
+	for id := range s.chains {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ids)
 }
 
 func (s *Service) GetStats(w http.ResponseWriter, r *http.Request) {
@@ -80,6 +203,19 @@ func (s *Service) GetStats(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stats)
 }
 
+// GetSignedStats returns the stats signed by the key of the node, with the
+// nonce query parameter when given
+func (s *Service) GetSignedStats(w http.ResponseWriter, r *http.Request) {
+	attestation, err := s.node.AttestStats(r.URL.Query().Get("nonce"))
+	if err != nil {
+		s.logger.WithError(err).Error("Signing stats")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attestation)
+}
+
 func (s *Service) GetParticipants(w http.ResponseWriter, r *http.Request) {
 	participants, err := s.node.GetParticipants()
 	if err != nil {
@@ -88,15 +224,250 @@ func (s *Service) GetParticipants(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
+	participants.RLock()
+	defer participants.RUnlock()
 	json.NewEncoder(w).Encode(participants)
 }
 
+// PeerInfo describes a peer along with its consensus weight and reputation
+// score
+type PeerInfo struct {
+	ID        int64
+	NetAddr   string
+	PubKeyHex string
+	Tier      string
+	Weight    uint64
+	Score     int64
+}
+
+func (s *Service) GetPeers(w http.ResponseWriter, r *http.Request) {
+	participants, err := s.node.GetParticipants()
+	if err != nil {
+		s.logger.WithError(err).Errorf("Retrieving peers")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	scores := s.node.GetPeerScores()
+	res := []PeerInfo{}
+	for _, p := range participants.Snapshot().ToPeerSlice() {
+		res = append(res, PeerInfo{
+			ID:        p.ID,
+			NetAddr:   p.NetAddr,
+			PubKeyHex: p.PubKeyHex,
+			Tier:      p.TierOrDefault(),
+			Weight:    p.Weight(),
+			Score:     scores[p.PubKeyHex],
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// GetLiveness returns the state of every peer in the failure detector of
+// the node: alive, suspect or down, the syncs it missed in a row and when
+// it was last seen
+func (s *Service) GetLiveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.node.GetPeerLiveness())
+}
+
+// SetPeerSetHash sets the hash of the signed peer set the node started
+// from, reported by GetPeerSet
+func (s *Service) SetPeerSetHash(hash string) {
+	s.peerSetHash = hash
+}
+
+// PeerSetInfo is the body of a GET /peerset response. Hash is the hash of the
+// current peer set, Signed the one of the signed peers.json the node started
+// from, empty when it was not signed. Operators compare them across nodes.
+type PeerSetInfo struct {
+	Hash   string
+	Signed string
+}
+
+// GetPeerSet returns the hashes of the peer set of the node
+func (s *Service) GetPeerSet(w http.ResponseWriter, r *http.Request) {
+	participants, err := s.node.GetParticipants()
+	if err != nil {
+		s.logger.WithError(err).Errorf("Retrieving peers")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PeerSetInfo{
+		Hash:   fmt.Sprintf("0x%X", peers.PeerSetHash(participants.ToPeerSlice())),
+		Signed: s.peerSetHash,
+	})
+}
+
+// BanRequest is the body of a POST /bans request. Key is a public key or
+// an address, Duration is parsed with time.ParseDuration.
+type BanRequest struct {
+	Key      string
+	Duration string
+	Reason   string
+}
+
+// Bans lists (GET /bans), adds (POST /bans) and lifts (DELETE /bans/<key>)
+// temporary peer bans
+func (s *Service) Bans(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.node.GetBans())
+	case http.MethodPost:
+		var req BanRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil || req.Key == "" || duration <= 0 {
+			http.Error(w, "a key and a positive duration are required", http.StatusBadRequest)
+			return
+		}
+		ban := s.node.Ban(req.Key, duration, req.Reason)
+		s.logger.WithFields(logrus.Fields{
+			"key":      ban.Key,
+			"duration": duration,
+		}).Info("Ban added")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ban)
+	case http.MethodDelete:
+		key := strings.TrimPrefix(r.URL.Path, "/bans/")
+		if !s.node.Unban(key) {
+			http.Error(w, fmt.Sprintf("%s is not banned", key), http.StatusNotFound)
+			return
+		}
+		s.logger.WithField("key", key).Info("Ban lifted")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Connections returns the state of the outbound connections of the node
+// (GET /connections), or forgets the failures of an address and lifts its
+// ban (DELETE /connections/<address>)
+func (s *Service) Connections(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.node.GetConnStates())
+	case http.MethodDelete:
+		target := strings.TrimPrefix(r.URL.Path, "/connections/")
+		if !s.node.ResetConn(target) {
+			http.Error(w, fmt.Sprintf("%s has no failures nor ban", target), http.StatusNotFound)
+			return
+		}
+		s.logger.WithField("target", target).Info("Connection state reset")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetMemory reports the estimated allocation of the components sharing the
+// memory budget
+func (s *Service) GetMemory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.node.GetMemoryUsage())
+}
+
+// FaultRequest is the body of a POST /chaos request. Delay is parsed with
+// time.ParseDuration.
+type FaultRequest struct {
+	Point       string
+	Action      string
+	Probability float64
+	Delay       string
+	Count       int
+}
+
+// Chaos lists (GET /chaos), sets (POST /chaos) and clears (DELETE
+// /chaos/<point>, or DELETE /chaos for all) injected faults. It is only
+// available when the node runs with fault injection enabled.
+func (s *Service) Chaos(w http.ResponseWriter, r *http.Request) {
+	injector := chaos.Global()
+	if !injector.Enabled() {
+		http.Error(w, "fault injection is disabled", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(injector.Faults())
+	case http.MethodPost:
+		var req FaultRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fault := chaos.Fault{
+			Point:       req.Point,
+			Action:      req.Action,
+			Probability: req.Probability,
+			Count:       req.Count,
+		}
+		if req.Delay != "" {
+			delay, err := time.ParseDuration(req.Delay)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			fault.Delay = delay
+		}
+		if err := injector.Set(fault); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.logger.WithFields(logrus.Fields{
+			"point":  fault.Point,
+			"action": fault.Action,
+		}).Warn("Fault injected")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(fault)
+	case http.MethodDelete:
+		point := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/chaos"), "/")
+		if point == "" {
+			injector.Reset()
+		} else if !injector.Clear(point) {
+			http.Error(w, fmt.Sprintf("no fault at %s", point), http.StatusNotFound)
+			return
+		}
+		s.logger.WithField("point", point).Info("Fault cleared")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// errorStatus maps the kind of an error onto an HTTP status
+func errorStatus(err error) int {
+	switch lerrors.KindOf(err) {
+	case lerrors.KeyNotFound, lerrors.UnknownParticipant:
+		return http.StatusNotFound
+	case lerrors.TooFar:
+		return http.StatusGone
+	case lerrors.MempoolFull:
+		return http.StatusServiceUnavailable
+	case lerrors.TxTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case lerrors.InvalidTx:
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 func (s *Service) GetEvent(w http.ResponseWriter, r *http.Request) {
 	param := r.URL.Path[len("/event/"):]
 	event, err := s.node.GetEvent(param)
 	if err != nil {
 		s.logger.WithError(err).Errorf("Retrieving event %s", param)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -109,7 +480,7 @@ func (s *Service) GetLastEventFrom(w http.ResponseWriter, r *http.Request) {
 	event, _, err := s.node.GetLastEventFrom(param)
 	if err != nil {
 		s.logger.WithError(err).Errorf("Retrieving event %s", event)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -150,7 +521,7 @@ func (s *Service) GetRound(w http.ResponseWriter, r *http.Request) {
 	round, err := s.node.GetRound(roundIndex)
 	if err != nil {
 		s.logger.WithError(err).Errorf("Retrieving round %d", roundIndex)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -200,7 +571,7 @@ func (s *Service) GetRoot(w http.ResponseWriter, r *http.Request) {
 	root, err := s.node.GetRoot(param)
 	if err != nil {
 		s.logger.WithError(err).Errorf("Retrieving root %s", param)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
@@ -209,7 +580,11 @@ func (s *Service) GetRoot(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Service) GetBlock(w http.ResponseWriter, r *http.Request) {
-	param := r.URL.Path[len("/block/"):]
+	s.serveBlock(w, r.URL.Path[len("/block/"):])
+}
+
+// serveBlock writes the block whose index is param
+func (s *Service) serveBlock(w http.ResponseWriter, param string) {
 	blockIndex, err := strconv.ParseInt(param, 10, 64)
 	if err != nil {
 		s.logger.WithError(err).Errorf("Parsing block_index parameter %s", param)
@@ -220,10 +595,138 @@ func (s *Service) GetBlock(w http.ResponseWriter, r *http.Request) {
 	block, err := s.node.GetBlock(blockIndex)
 	if err != nil {
 		s.logger.WithError(err).Errorf("Retrieving block %d", blockIndex)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(block)
 }
+
+// GetTxStatus returns the status of a transaction submitted to the node,
+// identified by its node.TxHash
+func (s *Service) GetTxStatus(w http.ResponseWriter, r *http.Request) {
+	hash := r.URL.Path[len("/txstatus/"):]
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]node.TxStatus{
+		"Status": s.node.GetTxStatus(hash),
+	})
+}
+
+// GetTxProof returns the Merkle proof of a transaction in its block (GET
+// /proof/tx/<hash>?block=<index>), the block which committed it when it was
+// submitted to this node and no index is given
+func (s *Service) GetTxProof(w http.ResponseWriter, r *http.Request) {
+	hash := r.URL.Path[len("/proof/tx/"):]
+	block, err := queryInt(r, "block", -1)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid block parameter %q", r.URL.Query().Get("block")), http.StatusBadRequest)
+		return
+	}
+
+	proof, err := s.node.GetTxProof(hash, block)
+	if err != nil {
+		s.logger.WithError(err).Errorf("Proving transaction %s", hash)
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(proof)
+}
+
+// GetTx returns the block and the position in the block of a committed
+// transaction (GET /tx/<hash>), when the node indexes the transactions
+func (s *Service) GetTx(w http.ResponseWriter, r *http.Request) {
+	hash := r.URL.Path[len("/tx/"):]
+	loc, err := s.node.GetTxLocation(hash)
+	if err != nil {
+		s.logger.WithError(err).Debugf("Locating transaction %s", hash)
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(loc)
+}
+
+// GetFrame returns the frame of a round, which light clients check against
+// the FrameHash of the block
+// GetCheckpoint returns a checkpoint by index, or the last one with
+// /checkpoint/last
+func (s *Service) GetCheckpoint(w http.ResponseWriter, r *http.Request) {
+	param := r.URL.Path[len("/checkpoint/"):]
+	index := s.node.GetLastCheckpointIndex()
+	if param != "last" {
+		var err error
+		index, err = strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			s.logger.WithError(err).Errorf("Parsing checkpoint parameter %s", param)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	checkpoint, err := s.node.GetCheckpoint(index)
+	if err != nil {
+		s.logger.WithError(err).Errorf("Retrieving checkpoint %d", index)
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(checkpoint)
+}
+
+// GetDAG returns the event DAG (GET /dag?format=dot|json&from_round=<round>),
+// in JSON from round 0 by default
+func (s *Service) GetDAG(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = poset.DAGFormatJSON
+	}
+	if format != poset.DAGFormatDOT && format != poset.DAGFormatJSON {
+		http.Error(w, fmt.Sprintf("invalid format parameter %q", format), http.StatusBadRequest)
+		return
+	}
+	fromRound, err := queryInt(r, "from_round", 0)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid from_round parameter %q", r.URL.Query().Get("from_round")), http.StatusBadRequest)
+		return
+	}
+
+	dag, err := s.node.GetDAG(fromRound)
+	if err != nil {
+		s.logger.WithError(err).Error("Building DAG")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	if format == poset.DAGFormatDOT {
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	dag.Write(w, format)
+}
+
+func (s *Service) GetFrame(w http.ResponseWriter, r *http.Request) {
+	param := r.URL.Path[len("/frame/"):]
+	round, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		s.logger.WithError(err).Errorf("Parsing round parameter %s", param)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	frame, err := s.node.GetFrame(round)
+	if err != nil {
+		s.logger.WithError(err).Errorf("Retrieving frame %d", round)
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(frame)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/node"
+)
+
+// maxTxBody is the size above which the body of a POST /tx is refused
+const maxTxBody = 8 << 20
+
+// defaultTxWait is how long a POST /tx?wait=true waits for the commit of the
+// transaction when no timeout is given
+const defaultTxWait = 30 * time.Second
+
+// SubmitTx adds the body of the request to the transaction pool of the node
+// (POST /tx), decoded from base64 with ?encoding=base64. It answers with the
+// node.TxReceipt of the transaction, at once or, with ?wait=true, once it is
+// committed or ?timeout elapsed.
+func (s *Service) SubmitTx(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxTxBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	tx := body
+	switch query.Get("encoding") {
+	case "", "raw":
+	case "base64":
+		tx, err = base64.StdEncoding.DecodeString(string(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "encoding must be raw or base64", http.StatusBadRequest)
+		return
+	}
+	if len(tx) == 0 {
+		http.Error(w, "empty transaction", http.StatusBadRequest)
+		return
+	}
+	wait := query.Get("wait") == "true"
+	timeout := defaultTxWait
+	if param := query.Get("timeout"); param != "" {
+		timeout, err = time.ParseDuration(param)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := s.node.SubmitTx(tx); err != nil {
+		s.logger.WithError(err).Debug("Submitting transaction")
+		http.Error(w, err.Error(), errorStatus(err))
+		return
+	}
+
+	hash := node.TxHash(tx)
+	receipt := s.node.GetTxReceipt(hash)
+	if wait {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		receipt = s.node.WaitTx(ctx, hash)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if receipt.Status == node.TxPending {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(receipt)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Fantom-foundation/go-lachesis/src/metrics"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+)
+
+// wsWriteTimeout bounds the time spent sending a message to a subscriber
+const wsWriteTimeout = 10 * time.Second
+
+// WSMessage is a JSON frame of the /ws stream: a committed block or, with
+// events=true, one of its consensus events, sent before the block
+type WSMessage struct {
+	Type  string              `json:"type"`
+	Block *poset.Block        `json:"block,omitempty"`
+	Event *poset.EventMessage `json:"event,omitempty"`
+}
+
+// wsFilter selects the blocks of a subscription
+type wsFilter struct {
+	// from is the first block, the next committed one when -1
+	from int64
+	// to is the last block, none when -1
+	to int64
+	// prefix selects the blocks with a transaction starting with it
+	prefix []byte
+	// events sends the consensus events of the blocks
+	events bool
+}
+
+func parseWSFilter(q url.Values) (wsFilter, error) {
+	f := wsFilter{from: -1, to: -1, prefix: []byte(q.Get("prefix"))}
+	for _, p := range []struct {
+		name string
+		v    *int64
+	}{{"from", &f.from}, {"to", &f.to}} {
+		if s := q.Get(p.name); s != "" {
+			v, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || v < 0 {
+				return f, fmt.Errorf("%s must be a block index, got %q", p.name, s)
+			}
+			*p.v = v
+		}
+	}
+	if f.to >= 0 && f.from > f.to {
+		return f, fmt.Errorf("from %d is after to %d", f.from, f.to)
+	}
+	if e := q.Get("events"); e != "" {
+		v, err := strconv.ParseBool(e)
+		if err != nil {
+			return f, fmt.Errorf("events must be a boolean, got %q", e)
+		}
+		f.events = v
+	}
+	return f, nil
+}
+
+func (f *wsFilter) match(block *poset.Block) bool {
+	if len(f.prefix) == 0 {
+		return true
+	}
+	for _, tx := range block.Transactions() {
+		if bytes.HasPrefix(tx, f.prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebSocket streams the committed blocks as WSMessages. The query selects
+// them: from and to bound the block indexes, starting with the blocks already
+// committed when from is in the past; prefix keeps the blocks with a
+// transaction starting with it; events=true also sends the consensus events
+// of every block.
+func (s *Service) WebSocket(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseWSFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// the browsers of the CORS origins only, like the rest of the API
+	upgrader := websocket.Upgrader{CheckOrigin: s.checkWSOrigin}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader answered the request
+		return
+	}
+	defer conn.Close()
+
+	logger := s.logger.WithField("remote", r.RemoteAddr)
+	metrics.IncrCounter("service.ws.subscriptions", 1)
+
+	// commits only wake the subscriber up, the blocks are read back in order
+	// from the store, so dropped ones are not missed. Subscribe before
+	// reading the store, not to miss the next commit.
+	blocks := make(chan poset.Block, 1)
+	s.node.SubscribeCommits(blocks)
+	defer s.node.UnsubscribeCommits(blocks)
+
+	// the stream is one way: reading detects the client going away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	next := filter.from
+	if next < 0 {
+		next = s.node.GetLastBlockIndex() + 1
+	}
+	for {
+		// send the blocks up to the last committed one, from the store
+		for last := s.node.GetLastBlockIndex(); next <= last; next++ {
+			if filter.to >= 0 && next > filter.to {
+				conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, "last block sent"))
+				return
+			}
+			block, err := s.node.GetBlock(next)
+			if err != nil {
+				logger.WithFields(logrus.Fields{
+					"block": next,
+					"error": err,
+				}).Warn("Reading block for WebSocket subscriber")
+				continue
+			}
+			if err := s.sendBlock(conn, &filter, &block); err != nil {
+				logger.WithError(err).Debug("WebSocket subscriber gone")
+				return
+			}
+		}
+
+		select {
+		case <-blocks:
+		case <-closed:
+			return
+		}
+	}
+}
+
+// sendBlock sends a block, and its consensus events first, if it matches the
+// filter
+func (s *Service) sendBlock(conn *websocket.Conn, filter *wsFilter, block *poset.Block) error {
+	if !filter.match(block) {
+		return nil
+	}
+	if filter.events {
+		frame, err := s.node.GetFrame(block.RoundReceived())
+		if err != nil {
+			return err
+		}
+		for _, ev := range frame.Events {
+			if err := s.sendWS(conn, &WSMessage{Type: "event", Event: ev}); err != nil {
+				return err
+			}
+		}
+	}
+	return s.sendWS(conn, &WSMessage{Type: "block", Block: block})
+}
+
+func (s *Service) sendWS(conn *websocket.Conn, msg *WSMessage) error {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if err := conn.WriteJSON(msg); err != nil {
+		return err
+	}
+	metrics.IncrCounter("service.ws.messages", 1)
+	return nil
+}
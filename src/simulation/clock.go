@@ -0,0 +1,107 @@
+package simulation
+
+import (
+	"container/heap"
+	"time"
+)
+
+// Epoch is the virtual time a simulation starts at
+var Epoch = time.Unix(0, 0).UTC()
+
+// Clock is a virtual clock driving a queue of scheduled actions. Time only
+// moves when the next action runs, so a simulation takes as long as its
+// computations regardless of the simulated durations.
+type Clock struct {
+	now   time.Time
+	seq   uint64
+	queue actionQueue
+}
+
+// NewClock creates a Clock set at Epoch
+func NewClock() *Clock {
+	return &Clock{now: Epoch}
+}
+
+// Now returns the virtual time
+func (c *Clock) Now() time.Time {
+	return c.now
+}
+
+// Elapsed returns the virtual time elapsed since Epoch
+func (c *Clock) Elapsed() time.Duration {
+	return c.now.Sub(Epoch)
+}
+
+// After schedules fn to run once d has elapsed on the clock. Actions
+// scheduled for the same time run in the order they were scheduled.
+func (c *Clock) After(d time.Duration, fn func()) {
+	c.seq++
+	heap.Push(&c.queue, &action{
+		at:  c.now.Add(d),
+		seq: c.seq,
+		fn:  fn,
+	})
+}
+
+// Step runs the next action, advancing the clock to its time. It returns
+// false when nothing is scheduled.
+func (c *Clock) Step() bool {
+	if len(c.queue) == 0 {
+		return false
+	}
+	a := heap.Pop(&c.queue).(*action)
+	c.now = a.at
+	a.fn()
+	return true
+}
+
+// StepUntil runs the next action if it is scheduled up to Epoch + d. It
+// returns false otherwise.
+func (c *Clock) StepUntil(d time.Duration) bool {
+	if len(c.queue) == 0 || c.queue[0].at.After(Epoch.Add(d)) {
+		return false
+	}
+	return c.Step()
+}
+
+// RunUntil runs the actions scheduled up to Epoch + d
+func (c *Clock) RunUntil(d time.Duration) {
+	for c.StepUntil(d) {
+	}
+	if end := Epoch.Add(d); c.now.Before(end) {
+		c.now = end
+	}
+}
+
+type action struct {
+	at  time.Time
+	seq uint64
+	fn  func()
+}
+
+// actionQueue implements heap.Interface ordering actions by time, then by
+// scheduling order
+type actionQueue []*action
+
+func (q actionQueue) Len() int { return len(q) }
+
+func (q actionQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+
+func (q actionQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *actionQueue) Push(x interface{}) {
+	*q = append(*q, x.(*action))
+}
+
+func (q *actionQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	a := old[n-1]
+	*q = old[:n-1]
+	return a
+}
@@ -0,0 +1,322 @@
+// Package simulation runs a network of cores in a single goroutine, on a
+// virtual clock and with seeded randomness, so that the same Config always
+// produces the same block sequence. It is meant for regression testing of
+// consensus changes and for replaying rare interleavings.
+package simulation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+	"github.com/Fantom-foundation/go-lachesis/src/net"
+	"github.com/Fantom-foundation/go-lachesis/src/node"
+	"github.com/Fantom-foundation/go-lachesis/src/peers"
+	"github.com/Fantom-foundation/go-lachesis/src/poset"
+	"github.com/sirupsen/logrus"
+)
+
+// Config describes a simulated network
+type Config struct {
+	Nodes int
+	Seed  int64
+	// Duration is the simulated time to run for
+	Duration time.Duration
+	// Heartbeat is the mean time between two gossips of a node
+	Heartbeat time.Duration
+	// MaxLatency bounds the random delivery delay of every message
+	MaxLatency time.Duration
+	// TxInterval is the time between two transactions submitted to random
+	// nodes. 0 disables transactions.
+	TxInterval time.Duration
+	SyncLimit  int64
+	CacheSize  int
+	Logger     *logrus.Logger
+}
+
+// DefaultConfig returns a 4 nodes network running for 10 simulated seconds
+func DefaultConfig() Config {
+	return Config{
+		Nodes:      4,
+		Seed:       1,
+		Duration:   10 * time.Second,
+		Heartbeat:  10 * time.Millisecond,
+		MaxLatency: 5 * time.Millisecond,
+		TxInterval: 2 * time.Millisecond,
+		SyncLimit:  1000,
+		CacheSize:  10000,
+	}
+}
+
+type simNode struct {
+	core     *node.Core
+	commitCh chan poset.Block
+	blocks   []poset.Block
+	syncing  bool
+}
+
+// Simulation is a simulated network. It must only be used from one
+// goroutine.
+type Simulation struct {
+	conf         Config
+	clock        *Clock
+	rnd          *rand.Rand
+	participants *peers.Peers
+	nodes        []*simNode
+	txs          int
+	err          error
+}
+
+// deterministicSignatures makes the signatures deterministic, process wide,
+// until the returned function restores the previous setting
+func deterministicSignatures() func() {
+	prev := crypto.DeterministicSignatures()
+	crypto.SetDeterministicSignatures(true)
+	return func() { crypto.SetDeterministicSignatures(prev) }
+}
+
+// NewSimulation creates the nodes of the network. Their keys are derived
+// from the seed and, like in Run, signatures are deterministic so that events,
+// and thus their consensus order, are reproducible.
+func NewSimulation(conf Config) (*Simulation, error) {
+	if conf.Nodes < 2 {
+		return nil, fmt.Errorf("a simulation needs at least 2 nodes")
+	}
+	if conf.Heartbeat <= 0 {
+		return nil, fmt.Errorf("heartbeat must be positive")
+	}
+	if conf.Logger == nil {
+		conf.Logger = logrus.New()
+		conf.Logger.Level = logrus.ErrorLevel
+	}
+
+	defer deterministicSignatures()()
+
+	s := &Simulation{
+		conf:         conf,
+		clock:        NewClock(),
+		rnd:          rand.New(rand.NewSource(conf.Seed)),
+		participants: peers.NewPeers(),
+	}
+
+	keys := make(map[int64]*ecdsa.PrivateKey)
+	for i := 0; i < conf.Nodes; i++ {
+		key := s.newKey()
+		pubHex := fmt.Sprintf("0x%X", crypto.FromECDSAPub(&key.PublicKey))
+		peer := peers.NewPeer(pubHex, fmt.Sprintf("sim%d", i))
+		if err := s.participants.AddPeer(peer); err != nil {
+			return nil, err
+		}
+		keys[peer.ID] = key
+	}
+
+	for _, peer := range s.participants.ToPeerSlice() {
+		commitCh := make(chan poset.Block, 400)
+		core := node.NewCore(peer.ID, keys[peer.ID], s.participants,
+			poset.NewInmemStore(s.participants, conf.CacheSize), commitCh, conf.Logger)
+		// the timestamps of the events, signed with them, are virtual
+		core.SetClock(s.clock.Now)
+		if err := core.SetHeadAndSeq(); err != nil {
+			return nil, err
+		}
+		s.nodes = append(s.nodes, &simNode{
+			core:     core,
+			commitCh: commitCh,
+		})
+	}
+
+	return s, nil
+}
+
+// newKey derives a P256 key from the seeded source
+func (s *Simulation) newKey() *ecdsa.PrivateKey {
+	curve := elliptic.P256()
+	b := make([]byte, 32)
+	s.rnd.Read(b)
+	d := new(big.Int).SetBytes(b)
+	d.Mod(d, new(big.Int).Sub(curve.Params().N, big.NewInt(1)))
+	d.Add(d, big.NewInt(1))
+
+	key := &ecdsa.PrivateKey{D: d}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(d.Bytes())
+	return key
+}
+
+// Clock returns the virtual clock of the simulation
+func (s *Simulation) Clock() *Clock {
+	return s.clock
+}
+
+// Run simulates conf.Duration of network activity and returns the blocks
+// committed by every node
+func (s *Simulation) Run() (*Result, error) {
+	defer deterministicSignatures()()
+
+	for i := range s.nodes {
+		i := i
+		s.clock.After(s.jitter(s.conf.Heartbeat), func() { s.gossip(i) })
+	}
+	if s.conf.TxInterval > 0 {
+		s.clock.After(s.conf.TxInterval, s.submitTx)
+	}
+
+	for s.err == nil && s.clock.StepUntil(s.conf.Duration) {
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	res := &Result{
+		Seed:         s.conf.Seed,
+		Transactions: s.txs,
+	}
+	for _, n := range s.nodes {
+		res.Blocks = append(res.Blocks, n.blocks)
+	}
+	return res, nil
+}
+
+// jitter returns a random duration in [d/2, 3d/2)
+func (s *Simulation) jitter(d time.Duration) time.Duration {
+	return d/2 + time.Duration(s.rnd.Int63n(int64(d)))
+}
+
+func (s *Simulation) latency() time.Duration {
+	if s.conf.MaxLatency <= 0 {
+		return 0
+	}
+	return time.Duration(s.rnd.Int63n(int64(s.conf.MaxLatency)))
+}
+
+func (s *Simulation) submitTx() {
+	n := s.nodes[s.rnd.Intn(len(s.nodes))]
+	n.core.AddTransactions([][]byte{[]byte(fmt.Sprintf("sim-tx-%d", s.txs))})
+	s.txs++
+	s.clock.After(s.conf.TxInterval, s.submitTx)
+}
+
+// gossip pulls the events of a random peer, the way node.Node does,
+// through messages delivered after a random latency
+func (s *Simulation) gossip(from int) {
+	defer s.clock.After(s.jitter(s.conf.Heartbeat), func() { s.gossip(from) })
+
+	n := s.nodes[from]
+	if n.syncing {
+		return
+	}
+	to := s.rnd.Intn(len(s.nodes) - 1)
+	if to >= from {
+		to++
+	}
+	n.syncing = true
+
+	req := &net.SyncRequest{
+		FromID: n.core.ID(),
+		Known:  n.core.KnownEvents(),
+	}
+	s.clock.After(s.latency(), func() {
+		resp, err := s.processSyncRequest(to, req)
+		if err != nil {
+			s.err = err
+			return
+		}
+		s.clock.After(s.latency(), func() {
+			s.err = s.processSyncResponse(from, resp)
+		})
+	})
+}
+
+func (s *Simulation) processSyncRequest(to int, req *net.SyncRequest) (*net.SyncResponse, error) {
+	core := s.nodes[to].core
+	resp := &net.SyncResponse{
+		FromID: core.ID(),
+		Known:  core.KnownEvents(),
+	}
+	if core.OverSyncLimit(req.Known, s.conf.SyncLimit) {
+		resp.SyncLimit = true
+		return resp, nil
+	}
+	diff, err := core.EventDiff(req.Known)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Events, err = core.ToWire(diff); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Simulation) processSyncResponse(from int, resp *net.SyncResponse) error {
+	n := s.nodes[from]
+	n.syncing = false
+	if resp.SyncLimit {
+		return nil
+	}
+	if err := n.core.Sync(resp.Events); err != nil {
+		return fmt.Errorf("node %d: %v", from, err)
+	}
+	if err := n.core.RunConsensus(); err != nil {
+		return fmt.Errorf("node %d: %v", from, err)
+	}
+	for {
+		select {
+		case block := <-n.commitCh:
+			n.blocks = append(n.blocks, block)
+		default:
+			return nil
+		}
+	}
+}
+
+// Result holds the blocks committed by each node, in node order
+type Result struct {
+	Seed         int64
+	Transactions int
+	Blocks       [][]poset.Block
+}
+
+// Check verifies that all the nodes committed the same block sequence, up
+// to the length of the shortest one
+func (r *Result) Check() error {
+	longest := r.Longest()
+	for i, blocks := range r.Blocks {
+		for j, b := range blocks {
+			if !b.Equals(&longest[j]) {
+				return fmt.Errorf("node %d diverges at block %d", i, b.Index())
+			}
+		}
+	}
+	return nil
+}
+
+// Longest returns the longest committed block sequence
+func (r *Result) Longest() []poset.Block {
+	var longest []poset.Block
+	for _, blocks := range r.Blocks {
+		if len(blocks) > len(longest) {
+			longest = blocks
+		}
+	}
+	return longest
+}
+
+// Digest returns a hash of the longest committed block sequence. Two runs
+// with the same Config yield the same digest. Frame hashes are left out, the
+// digest covering what the application sees of the blocks.
+func (r *Result) Digest() string {
+	h := sha256.New()
+	for _, b := range r.Longest() {
+		fmt.Fprintf(h, "%d|%d|%d\n", b.Index(), b.RoundReceived(), len(b.Transactions()))
+		for _, tx := range b.Transactions() {
+			h.Write(tx)
+			h.Write([]byte{'\n'})
+		}
+	}
+	return fmt.Sprintf("0x%X", h.Sum(nil))
+}
@@ -0,0 +1,66 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/crypto"
+)
+
+func runSimulation(t *testing.T, seed int64) *Result {
+	conf := DefaultConfig()
+	conf.Seed = seed
+	conf.Duration = 2 * time.Second
+
+	sim, err := NewSimulation(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := sim.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Longest()) == 0 {
+		t.Fatal("no block committed")
+	}
+	return res
+}
+
+func TestSimulationIsReproducible(t *testing.T) {
+	res := runSimulation(t, 42)
+	if err := res.Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	first := res.Digest()
+	second := runSimulation(t, 42).Digest()
+	if first != second {
+		t.Fatalf("same seed produced different blocks: %s != %s", first, second)
+	}
+
+	other := runSimulation(t, 1).Digest()
+	if first == other {
+		t.Fatal("different seeds produced the same blocks")
+	}
+
+	// the signatures of the rest of the process are left as they were
+	if crypto.DeterministicSignatures() {
+		t.Fatal("the simulation left the signatures deterministic")
+	}
+}
+
+func TestClockOrder(t *testing.T) {
+	c := NewClock()
+	var order []int
+	c.After(2*time.Second, func() { order = append(order, 3) })
+	c.After(time.Second, func() { order = append(order, 1) })
+	c.After(time.Second, func() { order = append(order, 2) })
+	c.RunUntil(time.Second)
+
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Fatalf("unexpected order %v", order)
+	}
+	if c.Elapsed() != time.Second {
+		t.Fatalf("expected 1s elapsed, got %v", c.Elapsed())
+	}
+}
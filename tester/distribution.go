@@ -0,0 +1,99 @@
+package tester
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Distribution draws payload sizes, in bytes
+type Distribution interface {
+	Next(r *rand.Rand) int
+	String() string
+}
+
+// Fixed always returns the same size
+type Fixed int
+
+// Next implements Distribution
+func (f Fixed) Next(*rand.Rand) int {
+	return int(f)
+}
+
+func (f Fixed) String() string {
+	return fmt.Sprintf("fixed:%d", int(f))
+}
+
+// Uniform draws sizes uniformly within [Min, Max]
+type Uniform struct {
+	Min, Max int
+}
+
+// Next implements Distribution
+func (u Uniform) Next(r *rand.Rand) int {
+	return u.Min + r.Intn(u.Max-u.Min+1)
+}
+
+func (u Uniform) String() string {
+	return fmt.Sprintf("uniform:%d-%d", u.Min, u.Max)
+}
+
+// Normal draws sizes from a normal distribution, clamped to at least 1
+type Normal struct {
+	Mean, StdDev float64
+}
+
+// Next implements Distribution
+func (n Normal) Next(r *rand.Rand) int {
+	v := int(r.NormFloat64()*n.StdDev + n.Mean)
+	if v < 1 {
+		v = 1
+	}
+	return v
+}
+
+func (n Normal) String() string {
+	return fmt.Sprintf("normal:%g,%g", n.Mean, n.StdDev)
+}
+
+// ParseDistribution parses "fixed:N", "uniform:MIN-MAX" or
+// "normal:MEAN,STDDEV". A bare number is a fixed size.
+func ParseDistribution(s string) (Distribution, error) {
+	kind, args := "fixed", s
+	if i := strings.Index(s, ":"); i >= 0 {
+		kind, args = s[:i], s[i+1:]
+	}
+
+	switch kind {
+	case "fixed":
+		n, err := strconv.Atoi(args)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid fixed size %q", args)
+		}
+		return Fixed(n), nil
+	case "uniform":
+		bounds := strings.SplitN(args, "-", 2)
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("invalid uniform range %q, expected MIN-MAX", args)
+		}
+		min, err1 := strconv.Atoi(bounds[0])
+		max, err2 := strconv.Atoi(bounds[1])
+		if err1 != nil || err2 != nil || min < 1 || max < min {
+			return nil, fmt.Errorf("invalid uniform range %q", args)
+		}
+		return Uniform{Min: min, Max: max}, nil
+	case "normal":
+		params := strings.SplitN(args, ",", 2)
+		if len(params) != 2 {
+			return nil, fmt.Errorf("invalid normal parameters %q, expected MEAN,STDDEV", args)
+		}
+		mean, err1 := strconv.ParseFloat(params[0], 64)
+		stddev, err2 := strconv.ParseFloat(params[1], 64)
+		if err1 != nil || err2 != nil || mean <= 0 || stddev < 0 {
+			return nil, fmt.Errorf("invalid normal parameters %q", args)
+		}
+		return Normal{Mean: mean, StdDev: stddev}, nil
+	}
+	return nil, fmt.Errorf("unknown distribution %q", kind)
+}
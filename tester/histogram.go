@@ -0,0 +1,150 @@
+package tester
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LatencyHistogram records submission latencies
+type LatencyHistogram struct {
+	sync.Mutex
+	samples []time.Duration
+	sum     time.Duration
+}
+
+// Record adds a sample
+func (h *LatencyHistogram) Record(d time.Duration) {
+	h.Lock()
+	h.samples = append(h.samples, d)
+	h.sum += d
+	h.Unlock()
+}
+
+// LatencySummary is a snapshot of a LatencyHistogram
+type LatencySummary struct {
+	Count          int
+	Mean, Min, Max time.Duration
+	P50, P90, P99  time.Duration
+	Buckets        []Bucket
+}
+
+// Bucket counts the samples up to (and including) Le
+type Bucket struct {
+	Le    time.Duration
+	Count int
+}
+
+// latencyBuckets are the upper bounds of the reported buckets
+var latencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// Summary computes the percentiles and buckets
+func (h *LatencyHistogram) Summary() LatencySummary {
+	h.Lock()
+	samples := make([]time.Duration, len(h.samples))
+	copy(samples, h.samples)
+	sum := h.sum
+	h.Unlock()
+
+	res := LatencySummary{Count: len(samples)}
+	if len(samples) == 0 {
+		return res
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	percentile := func(p float64) time.Duration {
+		return samples[int(p*float64(len(samples)-1))]
+	}
+	res.Mean = sum / time.Duration(len(samples))
+	res.Min = samples[0]
+	res.Max = samples[len(samples)-1]
+	res.P50 = percentile(0.5)
+	res.P90 = percentile(0.9)
+	res.P99 = percentile(0.99)
+
+	i := 0
+	for _, le := range latencyBuckets {
+		for i < len(samples) && samples[i] <= le {
+			i++
+		}
+		res.Buckets = append(res.Buckets, Bucket{Le: le, Count: i})
+	}
+	return res
+}
+
+// ThroughputHistogram counts the transactions submitted per second
+type ThroughputHistogram struct {
+	sync.Mutex
+	start   time.Time
+	seconds []int
+}
+
+// NewThroughputHistogram starts counting at start
+func NewThroughputHistogram(start time.Time) *ThroughputHistogram {
+	return &ThroughputHistogram{start: start}
+}
+
+// Record counts one transaction at t
+func (h *ThroughputHistogram) Record(t time.Time) {
+	s := int(t.Sub(h.start) / time.Second)
+	if s < 0 {
+		return
+	}
+	h.Lock()
+	for len(h.seconds) <= s {
+		h.seconds = append(h.seconds, 0)
+	}
+	h.seconds[s]++
+	h.Unlock()
+}
+
+// PerSecond returns the number of transactions of every second
+func (h *ThroughputHistogram) PerSecond() []int {
+	h.Lock()
+	defer h.Unlock()
+	res := make([]int, len(h.seconds))
+	copy(res, h.seconds)
+	return res
+}
+
+// Report is the outcome of a load generation run
+type Report struct {
+	Sent       uint64
+	Failed     uint64
+	Bytes      uint64
+	Duration   time.Duration
+	Latency    LatencySummary
+	Throughput []int
+}
+
+// Rate returns the achieved rate of successful transactions per second
+func (r *Report) Rate() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Sent) / r.Duration.Seconds()
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "sent: %d, failed: %d, bytes: %d, duration: %v, rate: %.1f tx/s\n",
+		r.Sent, r.Failed, r.Bytes, r.Duration, r.Rate())
+	l := r.Latency
+	fmt.Fprintf(&b, "latency: mean %v, min %v, p50 %v, p90 %v, p99 %v, max %v\n",
+		l.Mean, l.Min, l.P50, l.P90, l.P99, l.Max)
+	for _, bucket := range l.Buckets {
+		fmt.Fprintf(&b, "  <= %-8v %d\n", bucket.Le, bucket.Count)
+	}
+	fmt.Fprintf(&b, "throughput (tx/s): %v\n", r.Throughput)
+	return b.String()
+}
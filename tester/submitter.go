@@ -0,0 +1,102 @@
+package tester
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Fantom-foundation/go-lachesis/src/proxy"
+	"github.com/sirupsen/logrus"
+)
+
+// Submitter sends transactions to a node
+type Submitter interface {
+	SubmitTx(tx []byte) error
+	Close() error
+	String() string
+}
+
+// ProxySubmitter submits through the gRPC proxy of a node
+type ProxySubmitter struct {
+	addr  string
+	proxy *proxy.GrpcLachesisProxy
+}
+
+// NewProxySubmitter connects to the proxy listening at addr
+func NewProxySubmitter(addr string, logger *logrus.Logger) (*ProxySubmitter, error) {
+	p, err := proxy.NewGrpcLachesisProxy(addr, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &ProxySubmitter{addr: addr, proxy: p}, nil
+}
+
+// SubmitTx implements Submitter
+func (s *ProxySubmitter) SubmitTx(tx []byte) error {
+	return s.proxy.SubmitTx(tx)
+}
+
+// Close implements Submitter
+func (s *ProxySubmitter) Close() error {
+	return s.proxy.Close()
+}
+
+func (s *ProxySubmitter) String() string {
+	return "grpc://" + s.addr
+}
+
+// HTTPSubmitter POSTs every transaction as the raw body of a request
+type HTTPSubmitter struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSubmitter submits to url
+func NewHTTPSubmitter(url string, timeout time.Duration) *HTTPSubmitter {
+	return &HTTPSubmitter{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// SubmitTx implements Submitter
+func (s *HTTPSubmitter) SubmitTx(tx []byte) error {
+	resp, err := s.client.Post(s.url, "application/octet-stream", bytes.NewReader(tx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+// Close implements Submitter
+func (s *HTTPSubmitter) Close() error {
+	return nil
+}
+
+func (s *HTTPSubmitter) String() string {
+	return s.url
+}
+
+// NewSubmitter creates a Submitter from an endpoint: grpc://host:port for a
+// node proxy, http(s)://... for an HTTP endpoint. A bare host:port is a
+// proxy.
+func NewSubmitter(endpoint string, logger *logrus.Logger) (Submitter, error) {
+	switch {
+	case strings.HasPrefix(endpoint, "http://"), strings.HasPrefix(endpoint, "https://"):
+		return NewHTTPSubmitter(endpoint, 10*time.Second), nil
+	case strings.HasPrefix(endpoint, "grpc://"):
+		return NewProxySubmitter(strings.TrimPrefix(endpoint, "grpc://"), logger)
+	case strings.Contains(endpoint, "://"):
+		return nil, fmt.Errorf("unsupported endpoint %q", endpoint)
+	}
+	return NewProxySubmitter(endpoint, logger)
+}
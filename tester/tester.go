@@ -1,74 +1,187 @@
+// Package tester generates transaction load against lachesis nodes and
+// reports latency and throughput.
 package tester
 
 import (
 	"fmt"
 	"math/rand"
-	"strconv"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	_ "os"
-	_ "sync"
-
-	"github.com/Fantom-foundation/go-lachesis/src/peers"
-	"github.com/Fantom-foundation/go-lachesis/src/proxy"
 	"github.com/sirupsen/logrus"
 )
 
-func PingNodesN(participants []*peers.Peer, p peers.PubKeyPeers, n uint64, delay uint64, logger *logrus.Logger, ProxyAddr string) {
-	// pause before shooting test transactions
-	time.Sleep(time.Duration(delay) * time.Second)
+// tick is the pacing granularity of the generator
+const tick = 10 * time.Millisecond
 
-	proxies := make(map[int64]*proxy.GrpcLachesisProxy)
-	for _, participant := range participants {
-		node := p[participant.PubKeyHex]
-		host_port := strings.Split(node.NetAddr, ":")
-		port, err := strconv.Atoi(host_port[1])
-		addr := fmt.Sprintf("%s:%d", host_port[0], port-3000 /*9000*/)
-		lachesisProxy, err := proxy.NewGrpcLachesisProxy(addr, logger)
-		if err != nil {
-			fmt.Printf("error:\t\t\t%s\n", err.Error())
-			fmt.Printf("Failed to create WebsocketLachesisProxy:\t\t\t%s (id=%d)\n", participant.NetAddr, node.ID)
-		}
-		proxies[node.ID] = lachesisProxy
+// Config describes the load to generate
+type Config struct {
+	// Rate is the target number of transactions per second
+	Rate float64
+	// RampUp is the time taken to linearly reach Rate
+	RampUp time.Duration
+	// Duration stops the run after that time, 0 meaning no limit
+	Duration time.Duration
+	// Count stops the run after that many transactions, 0 meaning no limit
+	Count uint64
+	// Payload is the distribution of the transaction sizes
+	Payload Distribution
+	// Concurrency is the number of transactions in flight
+	Concurrency int
+	// Label prefixes every payload, e.g. to tell generators apart
+	Label string
+	Seed  int64
+}
+
+// DefaultConfig returns 100 tx/s of 120 bytes transactions, the size of
+// typical Ethereum transactions
+func DefaultConfig() Config {
+	return Config{
+		Rate:        100,
+		Payload:     Fixed(120),
+		Concurrency: 4,
+		Seed:        time.Now().UnixNano(),
 	}
-	for iteration := uint64(0); iteration < n; iteration++ {
-		participant := participants[rand.Intn(len(participants))]
-		node := p[participant.PubKeyHex]
+}
 
-		_, err := transact(proxies[node.ID], ProxyAddr, iteration)
+// Generator submits transactions to its targets in round robin
+type Generator struct {
+	conf    Config
+	targets []Submitter
+	logger  *logrus.Logger
+}
 
-		if err != nil {
-			fmt.Printf("error:\t\t\t%s\n", err.Error())
-			fmt.Printf("Failed to ping:\t\t\t%s (id=%d)\n", participant.NetAddr, node.ID)
-			fmt.Printf("Failed to send transaction:\t%d\n\n", iteration)
-		} /*else {
-			fmt.Printf("Pinged:\t\t\t%s (id=%d)\n", participant.NetAddr, node)
-			fmt.Printf("Last transaction sent:\t%d\n\n", iteration)
-		}*/
+// NewGenerator creates a Generator
+func NewGenerator(conf Config, targets []Submitter, logger *logrus.Logger) (*Generator, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no target to submit transactions to")
 	}
+	if conf.Rate <= 0 {
+		return nil, fmt.Errorf("rate must be positive")
+	}
+	if conf.Payload == nil {
+		conf.Payload = Fixed(120)
+	}
+	if conf.Concurrency < 1 {
+		conf.Concurrency = 1
+	}
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &Generator{
+		conf:    conf,
+		targets: targets,
+		logger:  logger,
+	}, nil
+}
 
-	for _, lachesisProxy := range proxies {
-		lachesisProxy.Close()
+// due returns the number of transactions to have sent after elapsed,
+// following a linear ramp-up to the target rate
+func (g *Generator) due(elapsed time.Duration) uint64 {
+	rate, ramp, t := g.conf.Rate, g.conf.RampUp.Seconds(), elapsed.Seconds()
+	if t < ramp {
+		return uint64(rate * t * t / (2 * ramp))
 	}
-	fmt.Println("Pinging stopped after ", n, " iterations")
+	return uint64(rate * (t - ramp/2))
 }
 
-func transact(proxy *proxy.GrpcLachesisProxy, proxyAddr string, iteration uint64) (string, error) {
+type job struct {
+	target  Submitter
+	payload []byte
+}
 
-	// Ethereum txns are ~108 bytes. Bitcoin txns are ~250 bytes.
-	// A good assumption is to make txns 120 bytes in size.
-	// However, for speed, we're using 1 byte here. Modify accordingly.
-	//var msg [1]byte
-	for i := 0; i < 10; i++ {
-		// Send 10 txns to the server.
-		msg := fmt.Sprintf("%s.%d.%d", proxyAddr, iteration, i)
-		err := proxy.SubmitTx([]byte(msg))
-		if err != nil {
-			return "", err
+// Run generates the load until Duration or Count is reached, or stop is
+// closed, and reports the outcome
+func (g *Generator) Run(stop <-chan struct{}) *Report {
+	var (
+		sent, failed, bytes uint64
+		latency             LatencyHistogram
+		wg                  sync.WaitGroup
+	)
+	start := time.Now()
+	throughput := NewThroughputHistogram(start)
+	jobs := make(chan job, g.conf.Concurrency)
+
+	for i := 0; i < g.conf.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				begin := time.Now()
+				if err := j.target.SubmitTx(j.payload); err != nil {
+					atomic.AddUint64(&failed, 1)
+					g.logger.WithFields(logrus.Fields{
+						"target": j.target.String(),
+						"error":  err,
+					}).Debug("SubmitTx")
+					continue
+				}
+				end := time.Now()
+				latency.Record(end.Sub(begin))
+				throughput.Record(end)
+				atomic.AddUint64(&sent, 1)
+				atomic.AddUint64(&bytes, uint64(len(j.payload)))
+			}
+		}()
+	}
+
+	rnd := rand.New(rand.NewSource(g.conf.Seed))
+	ticker := time.NewTicker(tick)
+	var issued uint64
+loop:
+	for {
+		elapsed := time.Since(start)
+		if g.conf.Duration > 0 && elapsed >= g.conf.Duration {
+			break
+		}
+		due := g.due(elapsed)
+		if g.conf.Count > 0 && due > g.conf.Count {
+			due = g.conf.Count
+		}
+		for ; issued < due; issued++ {
+			select {
+			case jobs <- job{
+				target:  g.targets[issued%uint64(len(g.targets))],
+				payload: g.payload(rnd, issued),
+			}:
+			case <-stop:
+				break loop
+			}
+		}
+		if g.conf.Count > 0 && issued >= g.conf.Count {
+			break
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			break loop
 		}
 	}
-	// fmt.Println("Submitted tx, ack=", ack)  # `ack` is now `_`
+	ticker.Stop()
+	close(jobs)
+	wg.Wait()
 
-	return "", nil
+	return &Report{
+		Sent:       sent,
+		Failed:     failed,
+		Bytes:      bytes,
+		Duration:   time.Since(start),
+		Latency:    latency.Summary(),
+		Throughput: throughput.PerSecond(),
+	}
+}
+
+// payload builds a transaction identified by the label and its sequence
+// number, padded with random bytes to the drawn size
+func (g *Generator) payload(rnd *rand.Rand, seq uint64) []byte {
+	size := g.conf.Payload.Next(rnd)
+	id := []byte(fmt.Sprintf("%s.%d.", g.conf.Label, seq))
+	if len(id) >= size {
+		return id
+	}
+	tx := make([]byte, size)
+	copy(tx, id)
+	rnd.Read(tx[len(id):])
+	return tx
 }
@@ -0,0 +1,121 @@
+package tester
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSubmitter struct {
+	sync.Mutex
+	txs  [][]byte
+	fail bool
+}
+
+func (f *fakeSubmitter) SubmitTx(tx []byte) error {
+	f.Lock()
+	defer f.Unlock()
+	if f.fail {
+		return fmt.Errorf("unavailable")
+	}
+	f.txs = append(f.txs, tx)
+	return nil
+}
+
+func (f *fakeSubmitter) Close() error   { return nil }
+func (f *fakeSubmitter) String() string { return "fake" }
+
+func TestParseDistribution(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	d, err := ParseDistribution("120")
+	if err != nil || d.Next(r) != 120 {
+		t.Fatalf("bare number should be a fixed size: %v %v", d, err)
+	}
+
+	d, err = ParseDistribution("uniform:10-20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if n := d.Next(r); n < 10 || n > 20 {
+			t.Fatalf("%d out of [10, 20]", n)
+		}
+	}
+
+	if _, err := ParseDistribution("normal:200,50"); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"uniform:20-10", "normal:200", "zipf:1", "fixed:0"} {
+		if _, err := ParseDistribution(s); err == nil {
+			t.Fatalf("%q should be invalid", s)
+		}
+	}
+}
+
+func TestGeneratorCount(t *testing.T) {
+	a, b, c := &fakeSubmitter{}, &fakeSubmitter{}, &fakeSubmitter{fail: true}
+	conf := DefaultConfig()
+	conf.Rate = 2000
+	conf.Count = 30
+	conf.Payload = Uniform{Min: 50, Max: 60}
+	conf.Label = "test"
+
+	gen, err := NewGenerator(conf, []Submitter{a, b, c}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := gen.Run(nil)
+
+	if report.Sent != 20 || report.Failed != 10 {
+		t.Fatalf("expected 20 sent and 10 failed, got %d and %d", report.Sent, report.Failed)
+	}
+	if len(a.txs) != 10 || len(b.txs) != 10 {
+		t.Fatalf("transactions should be spread in round robin: %d %d", len(a.txs), len(b.txs))
+	}
+	for _, tx := range a.txs {
+		if len(tx) < 50 || len(tx) > 60 {
+			t.Fatalf("unexpected payload size %d", len(tx))
+		}
+	}
+	if report.Latency.Count != 20 || report.Latency.Buckets[len(report.Latency.Buckets)-1].Count != 20 {
+		t.Fatalf("unexpected latency summary %+v", report.Latency)
+	}
+}
+
+func TestGeneratorRampUp(t *testing.T) {
+	gen, err := NewGenerator(Config{Rate: 100, RampUp: 2 * time.Second}, []Submitter{&fakeSubmitter{}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := gen.due(time.Second); n != 25 {
+		t.Fatalf("expected 25 transactions after 1s of ramp up, got %d", n)
+	}
+	if n := gen.due(3 * time.Second); n != 200 {
+		t.Fatalf("expected 200 transactions after 3s, got %d", n)
+	}
+}
+
+func TestHTTPSubmitter(t *testing.T) {
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	s, err := NewSubmitter(server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SubmitTx([]byte("tx")); err != nil {
+		t.Fatal(err)
+	}
+	if string(received) != "tx" {
+		t.Fatalf("unexpected body %q", received)
+	}
+}